@@ -12,11 +12,21 @@ import (
 
 type IAiChatV1 interface {
 	AiChat(ctx context.Context, req *v1.AiChatReq) (res *v1.AiChatRes, err error)
+	ChatRegenerate(ctx context.Context, req *v1.ChatRegenerateReq) (res *v1.ChatRegenerateRes, err error)
+	ChatEdit(ctx context.Context, req *v1.ChatEditReq) (res *v1.ChatEditRes, err error)
+	ChatCancel(ctx context.Context, req *v1.ChatCancelReq) (res *v1.ChatCancelRes, err error)
+	ChatResume(ctx context.Context, req *v1.ChatResumeReq) (res *v1.ChatResumeRes, err error)
+	ChatApproval(ctx context.Context, req *v1.ChatApprovalReq) (res *v1.ChatApprovalRes, err error)
+	ChatApprovals(ctx context.Context, req *v1.ChatApprovalsReq) (res *v1.ChatApprovalsRes, err error)
 	UploadChatFile(ctx context.Context, req *v1.UploadChatFileReq) (res *v1.UploadChatFileRes, err error)
+	ChatFiles(ctx context.Context, req *v1.ChatFilesReq) (res *v1.ChatFilesRes, err error)
+	ChatFileDownload(ctx context.Context, req *v1.ChatFileDownloadReq) (res *v1.ChatFileDownloadRes, err error)
+	ChatFileDelete(ctx context.Context, req *v1.ChatFileDeleteReq) (res *v1.ChatFileDeleteRes, err error)
 	SaveSession(ctx context.Context, req *v1.SaveSessionReq) (res *v1.SaveSessionRes, err error)
 	GetHistory(ctx context.Context, req *v1.GetHistoryReq) (res *v1.GetHistoryRes, err error)
 	GetSession(ctx context.Context, req *v1.GetSessionReq) (res *v1.GetSessionRes, err error)
 	DeleteSession(ctx context.Context, req *v1.DeleteSessionReq) (res *v1.DeleteSessionRes, err error)
+	SwitchBranch(ctx context.Context, req *v1.SwitchBranchReq) (res *v1.SwitchBranchRes, err error)
 	PauseTaskPomodoro(ctx context.Context, req *v1.PauseTaskPomodoroReq) (res *v1.PauseTaskPomodoroRes, err error)
 	StopTaskPomodoro(ctx context.Context, req *v1.StopTaskPomodoroReq) (res *v1.StopTaskPomodoroRes, err error)
 }
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	v1rag "backend/api/rag/v1"
 	"encoding/json"
 
 	"github.com/gogf/gf/v2/frame/g"
@@ -16,28 +17,42 @@ type MessagePart struct {
 
 // GetMultiContent 安全解析 MultiContent，过滤无效数据
 func (r *AiChatReq) GetMultiContent() []MessagePart {
-	if len(r.MultiContentRaw) == 0 {
+	return parseMultiContent(r.MultiContentRaw)
+}
+
+func parseMultiContent(raw json.RawMessage) []MessagePart {
+	if len(raw) == 0 {
 		return nil
 	}
 	var parts []MessagePart
-	if err := json.Unmarshal(r.MultiContentRaw, &parts); err != nil {
+	if err := json.Unmarshal(raw, &parts); err != nil {
 		return nil
 	}
 	return parts
 }
 
+// ChatOptions 对话选项，发送、重新生成与编辑共用
+type ChatOptions struct {
+	KnowledgeName  string   `json:"knowledge_name"`
+	TopK           int      `json:"top_k"` // 默认为5
+	Score          float64  `json:"score"` // 默认为0.2
+	IsNetwork      bool     `json:"is_network"`
+	IsStudyMode    bool     `json:"is_study_mode"`
+	IsDeepThinking bool     `json:"is_deep_thinking"` // 深度思考（仅 NormalChat 生效）
+	UploadedFiles  []string `json:"uploaded_files"`   // 本轮已上传到会话工作目录的文件名列表，供 AI 用 read_file 读取
+
+	RetrievalProfile *v1rag.RetrievalProfile `json:"retrieval_profile"` // 检索配置覆盖项，未设置的字段沿用知识库配置
+}
+
 type AiChatReq struct {
 	g.Meta          `path:"/chat" method:"post"`
 	ID              string          `json:"id" v:"required"` // 会话id
+	MsgId           string          `json:"msg_id"`          // 本轮用户消息 ID，为空时由后端生成
+	ReplyMsgId      string          `json:"reply_msg_id"`    // 本轮回复消息 ID（同时作为轮次 ID），为空时由后端生成
+	ParentMsgId     string          `json:"parent_msg_id"`   // 接在哪条消息之后，为空时接在当前分支末尾
 	Question        string          `json:"question"`        // 纯文本问题（兼容旧版）
 	MultiContentRaw json.RawMessage `json:"multi_content"`   // 多模态内容原始数据
-	KnowledgeName   string          `json:"knowledge_name"`
-	TopK            int             `json:"top_k"` // 默认为5
-	Score           float64         `json:"score"` // 默认为0.2
-	IsNetwork       bool            `json:"is_network"`
-	IsStudyMode     bool            `json:"is_study_mode"`
-	IsDeepThinking  bool            `json:"is_deep_thinking"` // 深度思考（仅 NormalChat 生效）
-	UploadedFiles   []string        `json:"uploaded_files"`   // 本轮已上传到会话工作目录的文件名列表，供 AI 用 read_file 读取
+	ChatOptions
 }
 type AiChatRes struct {
 	g.Meta `mime:"text/event-stream"`
@@ -16,11 +16,23 @@ type SaveSessionReq struct {
 type ChatMessage struct {
 	Id               int64         `json:"id" description:"消息ID"`
 	MsgId            string        `json:"msg_id" description:"前端消息ID"`
+	ParentMsgId      string        `json:"parent_msg_id,omitempty" description:"父消息ID"`
 	Content          string        `json:"content" description:"消息内容"`
 	MultiContent     []MessagePart `json:"multi_content,omitempty" description:"多模态内容"`
 	IsUser           bool          `json:"isUser" description:"是否为用户发送"`
 	Timestamp        *gtime.Time   `json:"timestamp" description:"发送时间"`
 	ReasoningContent string        `json:"reasoningContent,omitempty" description:"思考过程（深度思考模式）"`
+	Siblings         []string      `json:"siblings,omitempty" description:"同级版本的消息ID（含自身，按创建顺序），仅存在多个版本时返回"`
+	Citations        []Citation    `json:"citations,omitempty" description:"回答中引用标记对应的来源"`
+}
+
+// Citation 回答中的引用标记 [index] 对应的来源切片
+type Citation struct {
+	Index         int    `json:"index" description:"引用序号"`
+	ChunkId       string `json:"chunk_id" description:"切片ID"`
+	DocumentName  string `json:"document_name" description:"文档名"`
+	KnowledgeName string `json:"knowledge_name,omitempty" description:"知识库名称"`
+	Source        string `json:"source,omitempty" description:"来源类型，web 为网络搜索"`
 }
 
 type SaveSessionRes struct {
@@ -57,11 +69,12 @@ type GetSessionReq struct {
 }
 
 type GetSessionRes struct {
-	Id        string        `json:"id" description:"会话ID"`
-	Title     string        `json:"title" description:"会话标题"`
-	Messages  []ChatMessage `json:"messages" description:"消息列表"`
-	CreatedAt *gtime.Time   `json:"createdAt" description:"创建时间"`
-	UpdatedAt *gtime.Time   `json:"updatedAt" description:"更新时间"`
+	Id          string        `json:"id" description:"会话ID"`
+	Title       string        `json:"title" description:"会话标题"`
+	ActiveMsgId string        `json:"active_msg_id" description:"当前分支末尾消息ID"`
+	Messages    []ChatMessage `json:"messages" description:"当前分支的消息列表"`
+	CreatedAt   *gtime.Time   `json:"createdAt" description:"创建时间"`
+	UpdatedAt   *gtime.Time   `json:"updatedAt" description:"更新时间"`
 }
 
 // DeleteSessionReq 删除会话请求
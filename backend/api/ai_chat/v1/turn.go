@@ -0,0 +1,115 @@
+package v1
+
+import (
+	"encoding/json"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// ChatRegenerateReq 重新生成回复，旧回复保留为同级版本
+type ChatRegenerateReq struct {
+	g.Meta     `path:"/chat/regenerate" method:"post" tags:"AI Chat" summary:"重新生成回复"`
+	ID         string `json:"id" v:"required" dc:"会话ID"`
+	MsgId      string `json:"msg_id" v:"required" dc:"要重新生成的回复消息ID（也可传其对应的用户消息ID）"`
+	ReplyMsgId string `json:"reply_msg_id" dc:"新回复消息ID（同时作为轮次ID），为空时由后端生成"`
+	ChatOptions
+}
+
+type ChatRegenerateRes struct {
+	g.Meta `mime:"text/event-stream"`
+}
+
+// ChatEditReq 编辑用户消息并在新分支上继续对话，原消息保留为同级版本
+type ChatEditReq struct {
+	g.Meta          `path:"/chat/edit" method:"post" tags:"AI Chat" summary:"编辑消息并重新发送"`
+	ID              string          `json:"id" v:"required" dc:"会话ID"`
+	MsgId           string          `json:"msg_id" v:"required" dc:"被编辑的用户消息ID"`
+	NewMsgId        string          `json:"new_msg_id" dc:"编辑后的用户消息ID，为空时由后端生成"`
+	ReplyMsgId      string          `json:"reply_msg_id" dc:"新回复消息ID（同时作为轮次ID），为空时由后端生成"`
+	Question        string          `json:"question" dc:"编辑后的问题"`
+	MultiContentRaw json.RawMessage `json:"multi_content" dc:"编辑后的多模态内容"`
+	ChatOptions
+}
+
+type ChatEditRes struct {
+	g.Meta `mime:"text/event-stream"`
+}
+
+// GetMultiContent 安全解析 MultiContent，过滤无效数据
+func (r *ChatEditReq) GetMultiContent() []MessagePart {
+	return parseMultiContent(r.MultiContentRaw)
+}
+
+// ChatCancelReq 停止进行中的生成
+type ChatCancelReq struct {
+	g.Meta `path:"/chat/cancel" method:"post" tags:"AI Chat" summary:"停止生成"`
+	ID     string `json:"id" v:"required" dc:"会话ID"`
+	TurnId string `json:"turn_id" dc:"轮次ID（即回复消息ID），为空时停止该会话全部进行中的生成"`
+}
+
+type ChatCancelRes struct {
+	Cancelled int `json:"cancelled" dc:"已停止的轮次数"`
+}
+
+// ChatResumeReq 断线续传：回放已缓冲的事件后继续实时推送，请求头 Last-Event-ID 存在时以其为准
+type ChatResumeReq struct {
+	g.Meta `path:"/chat/resume" method:"get" tags:"AI Chat" summary:"续传生成中的回复"`
+	ID     string `json:"id" v:"required" dc:"会话ID"`
+	TurnId string `json:"turn_id" dc:"轮次ID（即回复消息ID）"`
+	Offset int64  `json:"offset" v:"min:0" dc:"已收到的最后事件序号，0 为从头回放"`
+}
+
+type ChatResumeRes struct {
+	g.Meta `mime:"text/event-stream"`
+}
+
+// SwitchBranchReq 切换到某条消息所在的分支
+type SwitchBranchReq struct {
+	g.Meta `path:"/chat/session/branch" method:"post" tags:"AI Chat" summary:"切换消息版本"`
+	Id     string `json:"id" v:"required" dc:"会话ID"`
+	MsgId  string `json:"msg_id" v:"required" dc:"要切换到的消息ID，分支沿该消息最新的后续消息延伸"`
+}
+
+type SwitchBranchRes struct {
+	ActiveMsgId string `json:"active_msg_id" dc:"切换后的分支末尾消息ID"`
+}
+
+// ChatApprovalReq 答复工具审批：同意则执行工具并续写回复，拒绝则告知模型后续写，续写作为新轮次以 SSE 推送
+type ChatApprovalReq struct {
+	g.Meta     `path:"/chat/approval" method:"post" tags:"AI Chat" summary:"答复工具审批"`
+	ID         string `json:"id" v:"required" dc:"会话ID"`
+	ApprovalId string `json:"approval_id" v:"required" dc:"tool_approval_required 事件中的审批ID"`
+	Approved   bool   `json:"approved" dc:"是否同意执行"`
+	Reason     string `json:"reason" v:"max-length:500" dc:"拒绝原因，会告知模型"`
+	ReplyMsgId string `json:"reply_msg_id" dc:"续写回复的消息ID（同时作为轮次ID），为空时由后端生成"`
+}
+
+type ChatApprovalRes struct {
+	g.Meta `mime:"text/event-stream"`
+}
+
+// ChatApprovalsReq 查询会话中待答复的工具审批，供断线重连后重新展示
+type ChatApprovalsReq struct {
+	g.Meta `path:"/chat/approvals" method:"get" tags:"AI Chat" summary:"待答复的工具审批"`
+	ID     string `json:"id" v:"required" dc:"会话ID"`
+}
+
+type ChatApprovalsRes struct {
+	List []ToolApproval `json:"list" dc:"待答复的审批"`
+}
+
+// ToolApproval 一次工具审批请求，与 tool_approval_required 事件的数据一致
+type ToolApproval struct {
+	ApprovalId string             `json:"approval_id" dc:"审批ID"`
+	SessionId  string             `json:"session_id" dc:"会话ID"`
+	TurnId     string             `json:"turn_id" dc:"发起审批的轮次ID"`
+	Calls      []ToolApprovalCall `json:"calls" dc:"待确认的工具调用"`
+	ExpiresAt  int64              `json:"expires_at" dc:"过期时间（Unix 秒）"`
+}
+
+type ToolApprovalCall struct {
+	CallId    string `json:"call_id" dc:"工具调用ID"`
+	Tool      string `json:"tool" dc:"工具名"`
+	Name      string `json:"name" dc:"展示名"`
+	Arguments string `json:"arguments" dc:"调用参数（JSON）"`
+}
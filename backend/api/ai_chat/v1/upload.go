@@ -14,3 +14,43 @@ type UploadChatFileReq struct {
 type UploadChatFileRes struct {
 	FileNames []string `json:"file_names" dc:"已保存的文件名列表（相对 workdir）"`
 }
+
+// ChatFilesReq 列出会话工作目录中的文件（上传的附件与 write_file/execute 生成的文件）
+type ChatFilesReq struct {
+	g.Meta `path:"/chat/files" method:"get" tags:"AI Chat" summary:"列出会话工作目录文件"`
+	Id     string `json:"id" v:"required" dc:"会话ID"`
+}
+
+type ChatFilesRes struct {
+	Files      []WorkDirFile `json:"files" dc:"文件列表"`
+	TotalBytes int64         `json:"total_bytes" dc:"已用字节数"`
+	FileCount  int           `json:"file_count" dc:"文件数"`
+	MaxBytes   int64         `json:"max_bytes" dc:"字节上限，0 表示不限"`
+	MaxFiles   int           `json:"max_files" dc:"文件数上限，0 表示不限"`
+}
+
+type WorkDirFile struct {
+	Path      string `json:"path" dc:"相对工作目录的路径"`
+	Size      int64  `json:"size" dc:"字节数"`
+	UpdatedAt int64  `json:"updated_at" dc:"修改时间（Unix 秒）"`
+}
+
+// ChatFileDownloadReq 下载会话工作目录中的文件，成功时直接返回文件内容
+type ChatFileDownloadReq struct {
+	g.Meta `path:"/chat/files/download" method:"get" tags:"AI Chat" summary:"下载会话工作目录文件"`
+	Id     string `json:"id" v:"required" dc:"会话ID"`
+	Path   string `json:"path" v:"required" dc:"相对工作目录的路径"`
+}
+
+type ChatFileDownloadRes struct{}
+
+// ChatFileDeleteReq 删除会话工作目录中的文件或子目录
+type ChatFileDeleteReq struct {
+	g.Meta `path:"/chat/files" method:"delete" tags:"AI Chat" summary:"删除会话工作目录文件"`
+	Id     string `json:"id" v:"required" dc:"会话ID"`
+	Path   string `json:"path" v:"required" dc:"相对工作目录的路径"`
+}
+
+type ChatFileDeleteRes struct {
+	Path string `json:"path" dc:"被删除的路径"`
+}
@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// ApiKey API Key 信息，不含明文
+type ApiKey struct {
+	Id         int64       `json:"id"`
+	Name       string      `json:"name"`
+	KeyPrefix  string      `json:"key_prefix"`
+	LastUsedAt *gtime.Time `json:"last_used_at"`
+	CreatedAt  *gtime.Time `json:"created_at"`
+}
+
+type ApiKeyCreateReq struct {
+	g.Meta `path:"/v1/api_keys" method:"post" tags:"api_key" summary:"Create an API key for the OpenAI compatible API"`
+	Name   string `json:"name" v:"required|length:1,100" dc:"备注名称"`
+}
+
+type ApiKeyCreateRes struct {
+	g.Meta `mime:"application/json"`
+	ApiKey
+	Key string `json:"key" dc:"API Key 明文，只返回这一次"`
+}
+
+type ApiKeyListReq struct {
+	g.Meta `path:"/v1/api_keys" method:"get" tags:"api_key" summary:"List API keys of the current user"`
+}
+
+type ApiKeyListRes struct {
+	g.Meta `mime:"application/json"`
+	List   []ApiKey `json:"list"`
+}
+
+type ApiKeyDeleteReq struct {
+	g.Meta `path:"/v1/api_keys" method:"delete" tags:"api_key" summary:"Delete an API key"`
+	Id     int64 `json:"id" v:"required" dc:"API Key ID"`
+}
+
+type ApiKeyDeleteRes struct {
+	g.Meta `mime:"application/json"`
+}
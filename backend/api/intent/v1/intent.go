@@ -0,0 +1,63 @@
+package v1
+
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// IntentExample 意图路由的标注样例
+type IntentExample struct {
+	Id        int64       `json:"id"`
+	Intent    string      `json:"intent" dc:"emotion | task_study | plan_modify"`
+	Text      string      `json:"text"`
+	CreatedBy string      `json:"created_by" dc:"内置样例为空"`
+	CreatedAt *gtime.Time `json:"created_at"`
+}
+
+// IntentScore 问题与某一意图质心的余弦相似度
+type IntentScore struct {
+	Intent string  `json:"intent"`
+	Score  float64 `json:"score"`
+}
+
+type IntentExamplesReq struct {
+	g.Meta `path:"/v1/intent/examples" method:"get" tags:"intent" summary:"List labeled examples of the intent router (admin)"`
+	Intent string `p:"intent" v:"in:emotion,task_study,plan_modify" dc:"为空时返回全部"`
+}
+
+type IntentExamplesRes struct {
+	g.Meta `mime:"application/json"`
+	List   []IntentExample `json:"list"`
+}
+
+type IntentExampleCreateReq struct {
+	g.Meta `path:"/v1/intent/examples" method:"post" tags:"intent" summary:"Add a labeled example and retrain the intent router (admin)"`
+	Intent string `json:"intent" v:"required|in:emotion,task_study,plan_modify"`
+	Text   string `json:"text" v:"required|max-length:500" dc:"样例问题"`
+}
+
+type IntentExampleCreateRes struct {
+	g.Meta `mime:"application/json"`
+	IntentExample
+}
+
+type IntentExampleDeleteReq struct {
+	g.Meta `path:"/v1/intent/examples" method:"delete" tags:"intent" summary:"Delete a labeled example and retrain the intent router (admin)"`
+	Id     int64 `json:"id" v:"required" dc:"样例 ID"`
+}
+
+type IntentExampleDeleteRes struct {
+	g.Meta `mime:"application/json"`
+}
+
+type IntentClassifyReq struct {
+	g.Meta   `path:"/v1/intent/classify" method:"post" tags:"intent" summary:"Classify a question with the embedding router only, for tuning thresholds (admin)"`
+	Question string `json:"question" v:"required"`
+}
+
+type IntentClassifyRes struct {
+	g.Meta    `mime:"application/json"`
+	Intent    string        `json:"intent" dc:"向量分类的最佳意图，分类器不可用时为空"`
+	Confident bool          `json:"confident" dc:"是否达到 router.minScore 与 router.minMargin，未达到时对话会调用 LLM 判断"`
+	Scores    []IntentScore `json:"scores"`
+}
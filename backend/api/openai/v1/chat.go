@@ -0,0 +1,118 @@
+package v1
+
+import (
+	v1rag "backend/api/rag/v1"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// ChatMessage OpenAI 格式的消息；content 为字符串或 text / image_url 分片数组
+type ChatMessage struct {
+	Role    string `json:"role" v:"required|in:system,developer,user,assistant,tool"`
+	Content any    `json:"content"`
+	Name    string `json:"name,omitempty"`
+}
+
+// ContentPart content 数组中的分片
+type ContentPart struct {
+	Type     string    `json:"type"` // text | image_url
+	Text     string    `json:"text,omitempty"`
+	ImageURL *ImageURL `json:"image_url,omitempty"`
+}
+
+type ImageURL struct {
+	URL string `json:"url"` // http(s) 地址或 data:image/...;base64,...
+}
+
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// ChatCompletionsReq OpenAI 兼容的对话请求；knowledge_name 等为扩展字段，其余 OpenAI 参数（temperature 等）忽略
+type ChatCompletionsReq struct {
+	g.Meta        `path:"/v1/chat/completions" method:"post" tags:"openai" summary:"OpenAI compatible chat completions"`
+	Model         string         `json:"model" v:"required" dc:"studycoach / studycoach-thinking / studycoach-coach"`
+	Messages      []ChatMessage  `json:"messages" v:"required|foreach|required"`
+	Stream        bool           `json:"stream"`
+	StreamOptions *StreamOptions `json:"stream_options"`
+
+	KnowledgeName    string                  `json:"knowledge_name" dc:"扩展：检索的知识库名称"`
+	TopK             int                     `json:"top_k" d:"5" v:"min:1|max:20" dc:"扩展：检索条数"`
+	Score            float64                 `json:"score" d:"0.2" v:"min:0|max:1" dc:"扩展：检索分数阈值"`
+	IsNetwork        bool                    `json:"is_network" dc:"扩展：是否联网搜索"`
+	IsStudyMode      bool                    `json:"is_study_mode" dc:"扩展：使用学习教练（CoachChat），与 model 选 studycoach-coach 等效"`
+	RetrievalProfile *v1rag.RetrievalProfile `json:"retrieval_profile" dc:"扩展：检索配置覆盖项"`
+}
+
+// ChatCompletionsRes 非流式响应；stream 为 true 时由控制器直接写出 SSE，不返回该结构
+type ChatCompletionsRes struct {
+	g.Meta `mime:"application/json"`
+	ChatCompletion
+}
+
+// Usage token 用量
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// Message 回复消息；reasoning_content 为深度思考内容
+type Message struct {
+	Role             string `json:"role,omitempty"`
+	Content          string `json:"content"`
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+}
+
+// Delta 流式分片中的增量
+type Delta struct {
+	Role             string `json:"role,omitempty"`
+	Content          string `json:"content,omitempty"`
+	ReasoningContent string `json:"reasoning_content,omitempty"`
+}
+
+// Source 检索到的参考资料，index 对应回答中的 [n] 引用标记
+type Source struct {
+	Index         int     `json:"index"`
+	ChunkId       string  `json:"chunk_id"`
+	DocumentName  string  `json:"document_name"`
+	KnowledgeName string  `json:"knowledge_name,omitempty"`
+	Source        string  `json:"source,omitempty"` // web 表示网络搜索结果
+	Score         float64 `json:"score"`
+	Content       string  `json:"content"`
+}
+
+// Extension 厂商扩展字段：本次回答的参考资料与实际出现的引用序号
+type Extension struct {
+	Sources   []Source `json:"sources,omitempty"`
+	Citations []int    `json:"citations,omitempty"`
+}
+
+type Choice struct {
+	Index        int      `json:"index"`
+	Message      *Message `json:"message,omitempty"`
+	Delta        *Delta   `json:"delta,omitempty"`
+	FinishReason *string  `json:"finish_reason"`
+}
+
+// ChatCompletion 非流式响应（object 为 chat.completion）与流式分片（chat.completion.chunk）共用
+type ChatCompletion struct {
+	Id         string     `json:"id"`
+	Object     string     `json:"object"`
+	Created    int64      `json:"created"`
+	Model      string     `json:"model"`
+	Choices    []Choice   `json:"choices"`
+	Usage      *Usage     `json:"usage,omitempty"`
+	StudyCoach *Extension `json:"studycoach,omitempty"`
+}
+
+// ErrorBody OpenAI 格式的错误响应
+type ErrorBody struct {
+	Error ErrorDetail `json:"error"`
+}
+
+type ErrorDetail struct {
+	Message string  `json:"message"`
+	Type    string  `json:"type"`
+	Code    *string `json:"code"`
+}
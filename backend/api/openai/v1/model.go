@@ -0,0 +1,20 @@
+package v1
+
+import "github.com/gogf/gf/v2/frame/g"
+
+type Model struct {
+	Id      string `json:"id"`
+	Object  string `json:"object"`
+	Created int64  `json:"created"`
+	OwnedBy string `json:"owned_by"`
+}
+
+type ModelListReq struct {
+	g.Meta `path:"/v1/models" method:"get" tags:"openai" summary:"OpenAI compatible model list"`
+}
+
+type ModelListRes struct {
+	g.Meta `mime:"application/json"`
+	Object string  `json:"object"`
+	Data   []Model `json:"data"`
+}
@@ -0,0 +1,90 @@
+package v1
+
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// PromptScope 模板的作用范围：全局版本对所有请求生效，知识库与用户版本覆盖全局版本
+type PromptScope struct {
+	Scope   string `json:"scope" d:"global" v:"in:global,knowledge,user" dc:"global | knowledge | user"`
+	ScopeId string `json:"scope_id" dc:"知识库 ID 或用户 UUID，全局范围为空"`
+}
+
+// PromptVersion 模板的一个版本
+type PromptVersion struct {
+	Id        int64       `json:"id"`
+	Node      string      `json:"node"`
+	Scope     string      `json:"scope"`
+	ScopeId   string      `json:"scope_id"`
+	Version   int         `json:"version"`
+	Content   string      `json:"content"`
+	Remark    string      `json:"remark"`
+	IsActive  bool        `json:"is_active"`
+	CreatedBy string      `json:"created_by"`
+	CreatedAt *gtime.Time `json:"created_at"`
+}
+
+// PromptNode 可配置提示词的节点
+type PromptNode struct {
+	Node        string         `json:"node"`
+	Description string         `json:"description"`
+	Variables   []string       `json:"variables" dc:"模板可使用的变量，以 {name} 引用"`
+	Default     string         `json:"default" dc:"内置模板，没有生效版本时使用"`
+	Active      *PromptVersion `json:"active" dc:"全局生效版本，未设置时为 null"`
+}
+
+type PromptNodesReq struct {
+	g.Meta `path:"/v1/prompts" method:"get" tags:"prompt" summary:"List prompt nodes with variables and active global versions (admin)"`
+}
+
+type PromptNodesRes struct {
+	g.Meta `mime:"application/json"`
+	List   []PromptNode `json:"list"`
+}
+
+type PromptVersionsReq struct {
+	g.Meta  `path:"/v1/prompts/versions" method:"get" tags:"prompt" summary:"List versions of a prompt node in a scope (admin)"`
+	Node    string `p:"node" v:"required" dc:"节点名称"`
+	Scope   string `p:"scope" d:"global" v:"in:global,knowledge,user" dc:"global | knowledge | user"`
+	ScopeId string `p:"scope_id" dc:"知识库 ID 或用户 UUID"`
+}
+
+type PromptVersionsRes struct {
+	g.Meta `mime:"application/json"`
+	List   []PromptVersion `json:"list"`
+}
+
+type PromptCreateReq struct {
+	g.Meta `path:"/v1/prompts/versions" method:"post" tags:"prompt" summary:"Create a new version of a prompt node (admin)"`
+	Node   string `json:"node" v:"required" dc:"节点名称"`
+	PromptScope
+	Content  string `json:"content" v:"required|max-length:65535" dc:"系统提示词，变量以 {name} 引用，字面量花括号写作 {{ }}"`
+	Remark   string `json:"remark" v:"max-length:255" dc:"版本说明"`
+	Activate bool   `json:"activate" dc:"创建后立即生效"`
+}
+
+type PromptCreateRes struct {
+	g.Meta `mime:"application/json"`
+	PromptVersion
+}
+
+type PromptActivateReq struct {
+	g.Meta `path:"/v1/prompts/versions/activate" method:"put" tags:"prompt" summary:"Activate a prompt version, including rolling back to an older one (admin)"`
+	Id     int64 `json:"id" v:"required" dc:"版本 ID"`
+}
+
+type PromptActivateRes struct {
+	g.Meta `mime:"application/json"`
+	PromptVersion
+}
+
+type PromptDeactivateReq struct {
+	g.Meta `path:"/v1/prompts/active" method:"delete" tags:"prompt" summary:"Remove the active version of a scope so it falls back to the parent scope (admin)"`
+	Node   string `json:"node" v:"required" dc:"节点名称"`
+	PromptScope
+}
+
+type PromptDeactivateRes struct {
+	g.Meta `mime:"application/json"`
+}
@@ -17,7 +17,15 @@ type IRagV1 interface {
 	UpdateChunkContent(ctx context.Context, req *v1.UpdateChunkContentReq) (res *v1.UpdateChunkContentRes, err error)
 	DocumentsList(ctx context.Context, req *v1.DocumentsListReq) (res *v1.DocumentsListRes, err error)
 	DocumentsDelete(ctx context.Context, req *v1.DocumentsDeleteReq) (res *v1.DocumentsDeleteRes, err error)
+	EvalQuestionAdd(ctx context.Context, req *v1.EvalQuestionAddReq) (res *v1.EvalQuestionAddRes, err error)
+	EvalQuestionList(ctx context.Context, req *v1.EvalQuestionListReq) (res *v1.EvalQuestionListRes, err error)
+	EvalQuestionDelete(ctx context.Context, req *v1.EvalQuestionDeleteReq) (res *v1.EvalQuestionDeleteRes, err error)
+	EvalRun(ctx context.Context, req *v1.EvalRunReq) (res *v1.EvalRunRes, err error)
+	EvalRunGet(ctx context.Context, req *v1.EvalRunGetReq) (res *v1.EvalRunGetRes, err error)
+	EvalRunList(ctx context.Context, req *v1.EvalRunListReq) (res *v1.EvalRunListRes, err error)
 	Indexer(ctx context.Context, req *v1.IndexerReq) (res *v1.IndexerRes, err error)
+	IndexerJob(ctx context.Context, req *v1.IndexerJobReq) (res *v1.IndexerJobRes, err error)
+	IndexerJobList(ctx context.Context, req *v1.IndexerJobListReq) (res *v1.IndexerJobListRes, err error)
 	KBCreate(ctx context.Context, req *v1.KBCreateReq) (res *v1.KBCreateRes, err error)
 	KBUpdate(ctx context.Context, req *v1.KBUpdateReq) (res *v1.KBUpdateRes, err error)
 	KBDelete(ctx context.Context, req *v1.KBDeleteReq) (res *v1.KBDeleteRes, err error)
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"backend/internal/model/entity"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// 评测运行状态
+const (
+	EvalStatusRunning   = 0 // 执行中
+	EvalStatusSucceeded = 1 // 成功
+	EvalStatusFailed    = 2 // 失败
+)
+
+// EvalQuestion 评测问题：expected_chunk_ids 与 reference_answer 至少填写一项，同时填写时以 expected_chunk_ids 判定相关
+type EvalQuestion struct {
+	Question         string   `json:"question" v:"required" dc:"问题"`
+	ExpectedChunkIds []string `json:"expected_chunk_ids" dc:"期望命中的 chunk_id 列表"`
+	ReferenceAnswer  string   `json:"reference_answer" dc:"参考答案，chunk 覆盖参考答案一定比例的词即视为相关"`
+}
+
+type EvalQuestionAddReq struct {
+	g.Meta        `path:"/v1/eval/questions" method:"post" tags:"eval" summary:"Add evaluation questions to a knowledge base"`
+	KnowledgeName string         `json:"knowledge_name" v:"required" dc:"知识库名称"`
+	Questions     []EvalQuestion `json:"questions" v:"required|foreach|required" dc:"问题列表"`
+}
+
+type EvalQuestionAddRes struct {
+	g.Meta `mime:"application/json"`
+	Ids    []int64 `json:"ids"`
+}
+
+type EvalQuestionListReq struct {
+	g.Meta        `path:"/v1/eval/questions" method:"get" tags:"eval" summary:"List evaluation questions of a knowledge base"`
+	KnowledgeName string `p:"knowledge_name" v:"required" dc:"知识库名称"`
+	Page          int    `p:"page" dc:"page" v:"min:1" d:"1"`
+	Size          int    `p:"size" dc:"size" v:"min:1|max:100" d:"10"`
+}
+
+type EvalQuestionListRes struct {
+	g.Meta `mime:"application/json"`
+	Data   []entity.KnowledgeEvalQuestions `json:"data"`
+	Total  int                             `json:"total"`
+	Page   int                             `json:"page"`
+	Size   int                             `json:"size"`
+}
+
+type EvalQuestionDeleteReq struct {
+	g.Meta `path:"/v1/eval/questions" method:"delete" tags:"eval" summary:"Delete evaluation questions"`
+	Ids    []int64 `json:"ids" v:"required" dc:"问题 ID 列表"`
+}
+
+type EvalQuestionDeleteRes struct {
+	g.Meta `mime:"application/json"`
+}
+
+type EvalRunReq struct {
+	g.Meta           `path:"/v1/eval/run" method:"post" tags:"eval" summary:"Start an evaluation run"`
+	KnowledgeName    string            `json:"knowledge_name" v:"required" dc:"知识库名称"`
+	TopK             int               `json:"top_k" v:"min:0|max:50" dc:"评测的 k，默认 5"`
+	Score            float64           `json:"score" dc:"分数阈值，默认 0.2，与 /v1/retriever 一致"`
+	Mode             string            `json:"mode" v:"in:standard,corrective" dc:"检索模式，默认 standard"`
+	RetrievalProfile *RetrievalProfile `json:"retrieval_profile" dc:"检索配置覆盖项，用于对比不同配置"`
+	Note             string            `json:"note" v:"max-length:255" dc:"备注"`
+}
+
+type EvalRunRes struct {
+	g.Meta `mime:"application/json"`
+	RunId  string `json:"run_id" dc:"运行 ID，通过 /v1/eval/run 查询结果"`
+}
+
+type EvalRunGetReq struct {
+	g.Meta `path:"/v1/eval/run" method:"get" tags:"eval" summary:"Get an evaluation run"`
+	RunId  string `p:"run_id" v:"required" dc:"运行 ID"`
+}
+
+type EvalRunGetRes struct {
+	g.Meta `mime:"application/json"`
+	*entity.KnowledgeEvalRuns
+}
+
+type EvalRunListReq struct {
+	g.Meta        `path:"/v1/eval/runs" method:"get" tags:"eval" summary:"List evaluation runs"`
+	KnowledgeName string `p:"knowledge_name" dc:"知识库名称，为空时返回全部"`
+	Page          int    `p:"page" dc:"page" v:"min:1" d:"1"`
+	Size          int    `p:"size" dc:"size" v:"min:1|max:100" d:"10"`
+}
+
+type EvalRunListRes struct {
+	g.Meta `mime:"application/json"`
+	Data   []entity.KnowledgeEvalRuns `json:"data" dc:"不含 details，逐题结果通过 /v1/eval/run 查询"`
+	Total  int                        `json:"total"`
+	Page   int                        `json:"page"`
+	Size   int                        `json:"size"`
+}
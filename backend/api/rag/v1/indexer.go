@@ -1,18 +1,65 @@
 package v1
 
 import (
+	"backend/internal/model/entity"
+
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
 )
 
+// 索引任务状态
+const (
+	JobStatusQueued    = 0 // 排队中（含等待重试）
+	JobStatusRunning   = 1 // 执行中
+	JobStatusSucceeded = 2 // 成功
+	JobStatusFailed    = 3 // 失败（已用尽重试次数）
+)
+
+// 索引任务阶段
+const (
+	JobStageExtract = "extract" // PDF 解析为 Markdown
+	JobStageSplit   = "split"   // 加载与切分
+	JobStageEmbed   = "embed"   // 向量化
+	JobStageStore   = "store"   // 写入向量库与 chunks
+	JobStageQA      = "qa"      // 生成 QA 并写入 QA 向量
+	JobStageDone    = "done"    // 全部完成
+)
+
 type IndexerReq struct {
-	g.Meta        `path:"/v1/indexer" method:"post" mime:"multipart/form-data" tags:"rag"`
+	g.Meta        `path:"/v1/indexer" method:"post" mime:"multipart/form-data" tags:"rag" summary:"Submit an asynchronous indexing job"`
 	File          *ghttp.UploadFile `p:"file" type:"file" dc:"如果是本地文件，怎上传文件"`
 	URL           string            `p:"url" dc:"如果是网络文件则直接输入url即可"`
 	KnowledgeName string            `p:"knowledge_name" dc:"知识库名称" v:"required"`
 }
 
 type IndexerRes struct {
+	g.Meta      `mime:"application/json"`
+	JobId       string `json:"job_id" dc:"索引任务 ID，进度通过 WebSocket index_job_progress 推送，也可轮询 /v1/indexer/job"`
+	DocumentsId int64  `json:"documents_id"`
+}
+
+type IndexerJobReq struct {
+	g.Meta `path:"/v1/indexer/job" method:"get" tags:"rag" summary:"Get an indexing job"`
+	JobId  string `p:"job_id" dc:"索引任务 ID" v:"required"`
+}
+
+type IndexerJobRes struct {
+	g.Meta `mime:"application/json"`
+	*entity.KnowledgeIndexJobs
+}
+
+type IndexerJobListReq struct {
+	g.Meta        `path:"/v1/indexer/jobs" method:"get" tags:"rag" summary:"List indexing jobs of current user"`
+	KnowledgeName string `p:"knowledge_name" dc:"知识库名称，为空时返回全部"`
+	Status        *int   `p:"status" dc:"任务状态：0 排队，1 执行中，2 成功，3 失败"`
+	Page          int    `p:"page" dc:"page" v:"min:1" d:"1"`
+	Size          int    `p:"size" dc:"size" v:"min:1|max:100" d:"10"`
+}
+
+type IndexerJobListRes struct {
 	g.Meta `mime:"application/json"`
-	DocIDs []string `json:"doc_ids"`
+	Data   []entity.KnowledgeIndexJobs `json:"data"`
+	Total  int                         `json:"total"`
+	Page   int                         `json:"page"`
+	Size   int                         `json:"size"`
 }
@@ -14,11 +14,37 @@ const (
 	StatusDisabled Status = 2
 )
 
+// 检索向量
+const (
+	RetrievalFieldContent = "content" // 仅正文向量
+	RetrievalFieldQA      = "qa"      // 仅 QA 向量
+	RetrievalFieldBoth    = "both"    // 正文 + QA
+)
+
+// 分数归一化方式（作用于向量检索原始分数）
+const (
+	ScoreNormalizeNone   = "none"   // 保持引擎原始分数
+	ScoreNormalizeShift  = "shift"  // 大于 1 的分数减 1（ES cosine 的 1+cos 转为 0-1）
+	ScoreNormalizeMinMax = "minmax" // 按本次候选集最小/最大值线性缩放到 0-1
+)
+
+// RetrievalProfile 检索配置：保存在知识库上，可被单次请求覆盖；未设置的字段沿用上一级（请求 > 知识库 > 默认）
+type RetrievalProfile struct {
+	RewriteRounds  *int   `json:"rewrite_rounds,omitempty" v:"min:0|max:5" dc:"查询重写轮数，0 表示直接用原问题检索，默认 3"`
+	Fields         string `json:"fields,omitempty" v:"in:content,qa,both" dc:"检索向量：content/qa/both，默认 both"`
+	Rerank         *bool  `json:"rerank,omitempty" dc:"是否调用重排模型，默认 true；关闭时按向量分数排序"`
+	CandidateSize  int    `json:"candidate_size,omitempty" v:"min:0|max:500" dc:"每次向量检索的候选数，默认 50"`
+	ScoreNormalize string `json:"score_normalize,omitempty" v:"in:none,shift,minmax" dc:"分数归一化：none/shift/minmax，默认 shift"`
+	Hybrid         *bool  `json:"hybrid,omitempty" dc:"是否混合检索：向量 + 关键词（BM25）按 RRF 融合，默认 false"`
+}
+
 type KBCreateReq struct {
 	g.Meta      `path:"/v1/kb" method:"post" tags:"kb" summary:"Create kb"`
 	Name        string `v:"required|length:3,50" dc:"kb name"`
 	Description string `v:"required|length:3,200" dc:"kb description"`
 	Category    string `v:"length:3,50" dc:"kb category"`
+
+	RetrievalProfile *RetrievalProfile `json:"retrieval_profile" dc:"检索配置，为空时使用默认"`
 }
 
 type KBCreateRes struct {
@@ -32,6 +58,8 @@ type KBUpdateReq struct {
 	Description *string `v:"length:3,200" dc:"kb description"`
 	Category    *string `v:"length:3,50" dc:"kb category"`
 	Status      *Status `v:"in:1,2" dc:"kb status"`
+
+	RetrievalProfile *RetrievalProfile `json:"retrieval_profile" dc:"检索配置，传入时整体替换"`
 }
 type KBUpdateRes struct{}
 
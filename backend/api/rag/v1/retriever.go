@@ -11,11 +11,15 @@ type RetrieverReq struct {
 	TopK          int     `json:"top_k"` // 默认为5
 	Score         float64 `json:"score"` // 默认为0.2
 	KnowledgeName string  `json:"knowledge_name" v:"required"`
+	Mode          string  `json:"mode" v:"in:standard,corrective" dc:"检索模式：standard（默认）按重排分数过滤；corrective 逐条评估相关性，不足时重写查询或回退网络搜索"`
+
+	RetrievalProfile *RetrievalProfile `json:"retrieval_profile" dc:"检索配置覆盖项，未设置的字段沿用知识库配置"`
 }
 
 type RetrieverRes struct {
 	g.Meta   `mime:"application/json"`
 	Document []*schema.Document `json:"document"`
+	Source   string             `json:"source" dc:"上下文来源：knowledge 知识库 / rewrite 重写查询后的知识库 / web 网络搜索 / none 无"`
 }
 
 type RetrieverDifyReq struct {
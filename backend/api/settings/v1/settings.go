@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// ChatDefaults 默认对话选项（保存在 settings_json），请求未携带对应字段时使用
+type ChatDefaults struct {
+	TopK           *int     `json:"top_k,omitempty" v:"between:1,20"`
+	Score          *float64 `json:"score,omitempty" v:"between:0,1"`
+	IsStudyMode    *bool    `json:"is_study_mode,omitempty"`
+	IsNetwork      *bool    `json:"is_network,omitempty"`
+	IsDeepThinking *bool    `json:"is_deep_thinking,omitempty"`
+}
+
+// UserSettings 用户设置
+type UserSettings struct {
+	Theme               string       `json:"theme"`
+	Language            string       `json:"language" dc:"界面语言，同时决定模型回复语言：zh | en"`
+	NotificationEnabled bool         `json:"notification_enabled"`
+	AutoSaveSessions    bool         `json:"auto_save_sessions" dc:"关闭后新建的会话为临时会话：不写入数据库，消息只在 chat.ephemeralTTL 内暂存于 Redis"`
+	MaxSessions         int          `json:"max_sessions" dc:"保留的会话数量上限，超出时删除最早的会话；0 为不限"`
+	FontSize            string       `json:"font_size"`
+	ChatDefaults        ChatDefaults `json:"chat_defaults"`
+	UpdatedAt           *gtime.Time  `json:"updated_at"`
+}
+
+type SettingsGetReq struct {
+	g.Meta `path:"/v1/settings" method:"get" tags:"settings" summary:"Get settings of the current user"`
+}
+
+type SettingsGetRes struct {
+	g.Meta `mime:"application/json"`
+	UserSettings
+}
+
+// SettingsUpdateReq 未提供的字段保持不变；chat_defaults 整体替换
+type SettingsUpdateReq struct {
+	g.Meta              `path:"/v1/settings" method:"put" tags:"settings" summary:"Update settings of the current user"`
+	Theme               *string       `json:"theme" v:"length:0,50"`
+	Language            *string       `json:"language" v:"in:zh,en"`
+	NotificationEnabled *bool         `json:"notification_enabled"`
+	AutoSaveSessions    *bool         `json:"auto_save_sessions"`
+	MaxSessions         *int          `json:"max_sessions" v:"between:0,1000"`
+	FontSize            *string       `json:"font_size" v:"length:0,20"`
+	ChatDefaults        *ChatDefaults `json:"chat_defaults"`
+}
+
+type SettingsUpdateRes struct {
+	g.Meta `mime:"application/json"`
+	UserSettings
+}
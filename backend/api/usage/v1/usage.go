@@ -0,0 +1,62 @@
+package v1
+
+import "github.com/gogf/gf/v2/frame/g"
+
+// UsageStat 调用次数与 token 用量
+type UsageStat struct {
+	Calls            int64 `json:"calls"`
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+	TotalTokens      int64 `json:"total_tokens"`
+}
+
+// UsageDaily 某一天的用量
+type UsageDaily struct {
+	Date string `json:"date"`
+	UsageStat
+}
+
+// UsageGroup 按模型、来源或节点分组的用量
+type UsageGroup struct {
+	Name string `json:"name"`
+	UsageStat
+}
+
+// UsageReport 统计区间内的用量汇总
+type UsageReport struct {
+	Total    UsageStat    `json:"total"`
+	Daily    []UsageDaily `json:"daily"`
+	ByModel  []UsageGroup `json:"by_model"`
+	BySource []UsageGroup `json:"by_source" dc:"chat | index | cron | eval"`
+	ByNode   []UsageGroup `json:"by_node" dc:"图节点或调用名称"`
+}
+
+// QuotaStatus 某一周期的额度使用情况，limit 为 0 表示不限
+type QuotaStatus struct {
+	Period  string `json:"period" dc:"day | month"`
+	Used    int64  `json:"used"`
+	Limit   int64  `json:"limit"`
+	ResetAt string `json:"reset_at"`
+}
+
+type UserUsageReq struct {
+	g.Meta `path:"/v1/usage/user" method:"get" tags:"usage" summary:"Token usage and quota of the current user"`
+	Days   int `p:"days" dc:"统计最近的天数" v:"min:1|max:366" d:"30"`
+}
+
+type UserUsageRes struct {
+	g.Meta `mime:"application/json"`
+	UsageReport
+	Quota []QuotaStatus `json:"quota"`
+}
+
+type KnowledgeUsageReq struct {
+	g.Meta        `path:"/v1/usage/knowledge" method:"get" tags:"usage" summary:"Token usage of a knowledge base"`
+	KnowledgeName string `p:"knowledge_name" v:"required" dc:"知识库名称"`
+	Days          int    `p:"days" dc:"统计最近的天数" v:"min:1|max:366" d:"30"`
+}
+
+type KnowledgeUsageRes struct {
+	g.Meta `mime:"application/json"`
+	UsageReport
+}
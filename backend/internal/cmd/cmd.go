@@ -2,20 +2,33 @@ package cmd
 
 import (
 	"backend/internal/controller/ai_chat"
+	"backend/internal/controller/api_key"
 	"backend/internal/controller/check_jwt"
 	"backend/internal/controller/cron"
 	"backend/internal/controller/cron_execute"
 	"backend/internal/controller/file_controller"
 	"backend/internal/controller/files"
+	"backend/internal/controller/intent"
 	"backend/internal/controller/login"
+	"backend/internal/controller/openai"
+	"backend/internal/controller/prompt"
 	"backend/internal/controller/rag"
+	"backend/internal/controller/settings"
+	"backend/internal/controller/usage"
 	"backend/internal/controller/voice"
 	"backend/internal/controller/ws"
+	logicChat "backend/internal/logic/ai_chat"
 	logicCron "backend/internal/logic/cron"
+	"backend/internal/logic/indexjob"
+	"backend/internal/logic/knowledge"
 	"backend/internal/logic/middleware"
+	logicUsage "backend/internal/logic/usage"
 	createTable "backend/internal/model/gorm"
+	"backend/studyCoach/aiModel/eino_tools/mcptool"
+	"backend/utility/sandbox"
 	"context"
 
+	"github.com/cloudwego/eino/callbacks"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
 	"github.com/gogf/gf/v2/os/gcmd"
@@ -36,6 +49,9 @@ var (
 				g.Log().Warningf(ctx, "database migrate failed (non-fatal): %v", err)
 			}
 
+			// 记录所有模型调用的 token 用量
+			callbacks.AppendGlobalHandlers(logicUsage.NewHandler())
+
 			s := g.Server()
 
 			// 初始化 WebSocket Hub 并启动
@@ -46,6 +62,18 @@ var (
 			// 初始化定时任务调度器
 			logicCron.InitScheduler(ctx)
 
+			// 启动文档索引任务 worker，并恢复未完成的任务
+			indexjob.Start(ctx)
+
+			// 定期清理长期未活动的匿名会话工作目录
+			logicChat.StartWorkDirSweeper(ctx)
+
+			// 探测 execute 沙箱能否隔离网络，不能时不允许联网的命令将被拒绝（除非配置 sandbox.allowUnisolatedNetwork）
+			_ = sandbox.Probe(ctx)
+
+			// 连接已配置的 MCP 服务，断开后自动重连
+			mcptool.Start(ctx)
+
 			//是否允许跨域操作
 			s.Use(func(r *ghttp.Request) {
 				r.Response.CORSDefault()
@@ -59,7 +87,13 @@ var (
 				})
 				group.GET("/readyz", func(r *ghttp.Request) {
 					// Basic readiness check - can be enhanced to check DB/Redis connectivity
-					r.Response.WriteJson(g.Map{"status": "ready", "timestamp": gtime.Now().Unix()})
+					// MCP 服务为可选依赖，未全部连接时返回 degraded，不影响就绪
+					status := "ready"
+					mcpHealth := mcptool.Health(r.Context())
+					if !mcptool.Ready(mcpHealth) {
+						status = "degraded"
+					}
+					r.Response.WriteJson(g.Map{"status": status, "timestamp": gtime.Now().Unix(), "mcp": mcpHealth})
 				})
 				group.GET("/metrics", func(r *ghttp.Request) {
 					// Placeholder for Prometheus metrics - will be enhanced
@@ -100,13 +134,40 @@ var (
 						files.NewV1(),
 						voice.NewV1(),
 						cron_execute.NewV1(),
+						usage.NewV1(),
+						api_key.NewV1(),
+						settings.NewV1(),
+						prompt.NewV1(),
+						intent.NewV1(),
 					)
 				})
 
 			})
 
+			// OpenAI 兼容接口：API Key 认证，错误按 OpenAI 格式返回；客户端 base_url 设为 /gateway/v1
+			s.Group("/gateway", func(group *ghttp.RouterGroup) {
+				group.Middleware(middleware.OpenAIResponse, middleware.APIKey)
+				group.Bind(openai.NewV1())
+			})
+
 			s.Run()
 			return nil
 		},
 	}
+
+	// VectorNamespaceMigrate 为升级前写入的向量数据回填知识库 ID 与用户 UUID
+	VectorNamespaceMigrate = gcmd.Command{
+		Name:  "migrate-vector-namespace",
+		Usage: "main migrate-vector-namespace",
+		Brief: "backfill knowledge base id and user uuid for existing vector points",
+		Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+			return knowledge.BackfillVectorNamespace(ctx)
+		},
+	}
 )
+
+func init() {
+	if err := Main.AddCommand(&VectorNamespaceMigrate, &Eval); err != nil {
+		panic(err)
+	}
+}
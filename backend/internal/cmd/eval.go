@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	v1 "backend/api/rag/v1"
+	"backend/internal/logic/evaluation"
+	"backend/internal/logic/knowledge"
+	logicUsage "backend/internal/logic/usage"
+	createTable "backend/internal/model/gorm"
+	"backend/studyCoach/common"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/os/gcmd"
+)
+
+// Eval 对知识库评测集同步执行一次检索评测，输出 recall@k、MRR、nDCG 并保存运行记录
+var Eval = gcmd.Command{
+	Name:  "eval",
+	Usage: "main eval -kb <knowledge_name> [-user <uuid>] [-k 5] [-score 0.2] [-mode standard] [-profile '{\"hybrid\":true}'] [-note text]",
+	Brief: "run offline retrieval evaluation against a knowledge base question set",
+	Arguments: []gcmd.Argument{
+		{Name: "kb", Brief: "knowledge base name"},
+		{Name: "user", Brief: "owner uuid, required when several users have a knowledge base with the same name"},
+		{Name: "k", Default: "5", Brief: "k for recall@k and nDCG@k"},
+		{Name: "score", Default: "0.2", Brief: "score threshold, same as /v1/retriever"},
+		{Name: "mode", Default: "standard", Brief: "retrieve mode: standard or corrective"},
+		{Name: "profile", Brief: "retrieval profile override in JSON"},
+		{Name: "note", Brief: "note recorded with the run"},
+	},
+	Func: func(ctx context.Context, parser *gcmd.Parser) (err error) {
+		kbName := parser.GetOpt("kb").String()
+		if kbName == "" {
+			return fmt.Errorf("缺少参数 -kb")
+		}
+		if err = createTable.RunMigrateOnStartup(ctx); err != nil {
+			return err
+		}
+		// 记录评测中向量化、重排与模型调用的 token 用量
+		callbacks.AppendGlobalHandlers(logicUsage.NewHandler())
+		var ns common.Namespace
+		if user := parser.GetOpt("user").String(); user != "" {
+			ns, err = knowledge.GetNamespace(ctx, user, kbName)
+		} else {
+			ns, err = knowledge.GetNamespaceByName(ctx, kbName)
+		}
+		if err != nil {
+			return err
+		}
+		var profile *v1.RetrievalProfile
+		if raw := parser.GetOpt("profile").String(); raw != "" {
+			if err = gjson.DecodeTo(raw, &profile); err != nil {
+				return fmt.Errorf("解析 -profile 失败: %w", err)
+			}
+		}
+		run, results, err := evaluation.Run(ctx, evaluation.RunOptions{
+			Namespace:     ns,
+			KnowledgeName: kbName,
+			TopK:          parser.GetOpt("k").Int(),
+			Score:         parser.GetOpt("score").Float64(),
+			Mode:          parser.GetOpt("mode").String(),
+			Profile:       profile,
+			Note:          parser.GetOpt("note").String(),
+		})
+		if err != nil {
+			return err
+		}
+		for _, r := range results {
+			if r.Error != "" {
+				fmt.Printf("[%d] ERROR %s | %s\n", r.QuestionId, r.Error, r.Question)
+				continue
+			}
+			if r.Unlabeled {
+				fmt.Printf("[%d] UNLABELED retrieved=%d | %s\n", r.QuestionId, len(r.Retrieved), r.Question)
+				continue
+			}
+			fmt.Printf("[%d] recall=%.3f mrr=%.3f ndcg=%.3f relevant=%v | %s\n", r.QuestionId, r.Recall, r.Mrr, r.Ndcg, r.Relevant, r.Question)
+		}
+		fmt.Println(strings.Repeat("-", 60))
+		fmt.Printf("run_id:    %s\n", run.RunId)
+		fmt.Printf("questions: %d, unlabeled: %d, duration: %dms\n", run.QuestionCount, run.UnlabeledCount, run.DurationMs)
+		fmt.Printf("recall@%d: %.4f\nMRR:       %.4f\nnDCG@%d:   %.4f\n", run.TopK, run.Recall, run.Mrr, run.TopK, run.Ndcg)
+		fmt.Printf("config:    %s\n", run.Config)
+		if run.Status == v1.EvalStatusFailed {
+			return fmt.Errorf("%s", run.Error)
+		}
+		return nil
+	},
+}
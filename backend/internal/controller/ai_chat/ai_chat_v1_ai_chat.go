@@ -2,16 +2,21 @@ package ai_chat
 
 import (
 	"backend/internal/dao"
+	logic "backend/internal/logic/ai_chat"
+	"backend/internal/logic/settings"
+	"backend/internal/logic/usage"
 	"backend/internal/model/entity"
 	"backend/studyCoach/api"
 	"backend/studyCoach/common"
 	"backend/utility"
 	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	v1 "backend/api/ai_chat/v1"
+	v1settings "backend/api/settings/v1"
 
 	"github.com/cloudwego/eino/schema"
 	"github.com/gogf/gf/v2/errors/gcode"
@@ -29,75 +34,315 @@ func (c *ControllerV1) AiChat(ctx context.Context, req *v1.AiChatReq) (res *v1.A
 	// ======================================
 	// 前置校验：所有校验必须在流式响应前完成，失败直接返回4xx
 	// ======================================
+	ctx, prefs, err := checkChatOptions(ctx, &req.ChatOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	// 会话归属校验：会话不存在时为当前用户（或匿名令牌）创建，属于他人时拒绝
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if resumed, err := resumeLastEvent(ctx, owner, req.ID); resumed {
+		return &v1.AiChatRes{}, err
+	}
+	if ctx, err = chatUsage(ctx, owner, req.ID); err != nil {
+		return nil, err
+	}
+	// 关闭自动保存时新会话为临时会话，不入库，也不计入会话上限
+	if err = logic.GetChat().EnsureSession(ctx, owner, req.ID, req.Question, prefs.AutoSaveSessions); err != nil {
+		return nil, err
+	}
+	if prefs.AutoSaveSessions {
+		pruneSessions(ctx, owner, prefs, req.ID)
+	}
+	turn, err := logic.GetChat().NewTurn(ctx, req.ID, req.ParentMsgId, req.MsgId, req.ReplyMsgId, req.Question, req.GetMultiContent())
+	if err != nil {
+		return nil, err
+	}
+
+	// 调试：打印 MultiContent
+	multiContent := req.GetMultiContent()
+	g.Log().Infof(ctx, "MultiContent 长度: %d", len(multiContent))
+	for i, part := range multiContent {
+		g.Log().Infof(ctx, "MultiContent[%d]: Type=%s, Text=%s, Base64Data长度=%d",
+			i, part.Type, part.Text, len(part.Base64Data))
+	}
+
+	return &v1.AiChatRes{}, runTurn(ctx, req, turn)
+}
+
+func (c *ControllerV1) ChatRegenerate(ctx context.Context, req *v1.ChatRegenerateReq) (res *v1.ChatRegenerateRes, err error) {
+	ctx, _, err = checkChatOptions(ctx, &req.ChatOptions)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if resumed, err := resumeLastEvent(ctx, owner, req.ID); resumed {
+		return &v1.ChatRegenerateRes{}, err
+	}
+	if ctx, err = chatUsage(ctx, owner, req.ID); err != nil {
+		return nil, err
+	}
+	if err = logic.GetChat().CheckSession(ctx, owner, req.ID); err != nil {
+		return nil, err
+	}
+	turn, err := logic.GetChat().RegenerateTurn(ctx, req.ID, req.MsgId, req.ReplyMsgId)
+	if err != nil {
+		return nil, err
+	}
+	chatReq := &v1.AiChatReq{
+		ID:          req.ID,
+		Question:    turn.Question,
+		ChatOptions: req.ChatOptions,
+	}
+	if len(turn.MultiContent) > 0 {
+		chatReq.MultiContentRaw, _ = json.Marshal(turn.MultiContent)
+	}
+	return &v1.ChatRegenerateRes{}, runTurn(ctx, chatReq, turn)
+}
+
+func (c *ControllerV1) ChatEdit(ctx context.Context, req *v1.ChatEditReq) (res *v1.ChatEditRes, err error) {
+	ctx, _, err = checkChatOptions(ctx, &req.ChatOptions)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Question) == "" && len(req.GetMultiContent()) == 0 {
+		return nil, gerror.NewCode(gcode.New(400, "参数错误：编辑后的内容不能为空", nil))
+	}
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if resumed, err := resumeLastEvent(ctx, owner, req.ID); resumed {
+		return &v1.ChatEditRes{}, err
+	}
+	if ctx, err = chatUsage(ctx, owner, req.ID); err != nil {
+		return nil, err
+	}
+	if err = logic.GetChat().CheckSession(ctx, owner, req.ID); err != nil {
+		return nil, err
+	}
+	turn, err := logic.GetChat().EditTurn(ctx, req.ID, req.MsgId, req.NewMsgId, req.ReplyMsgId, req.Question, req.GetMultiContent())
+	if err != nil {
+		return nil, err
+	}
+	chatReq := &v1.AiChatReq{
+		ID:              req.ID,
+		Question:        req.Question,
+		MultiContentRaw: req.MultiContentRaw,
+		ChatOptions:     req.ChatOptions,
+	}
+	return &v1.ChatEditRes{}, runTurn(ctx, chatReq, turn)
+}
+
+func (c *ControllerV1) ChatCancel(ctx context.Context, req *v1.ChatCancelReq) (res *v1.ChatCancelRes, err error) {
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = logic.GetChat().CheckSession(ctx, owner, req.ID); err != nil {
+		return nil, err
+	}
+	n := logic.GetChat().CancelTurn(req.ID, req.TurnId)
+	g.Log().Infof(ctx, "[ChatCancel] session=%s turn=%s cancelled=%d", req.ID, req.TurnId, n)
+	return &v1.ChatCancelRes{Cancelled: n}, nil
+}
+
+func (c *ControllerV1) ChatResume(ctx context.Context, req *v1.ChatResumeReq) (res *v1.ChatResumeRes, err error) {
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if resumed, err := resumeLastEvent(ctx, owner, req.ID); resumed {
+		return &v1.ChatResumeRes{}, err
+	}
+	if req.TurnId == "" {
+		return nil, gerror.NewCode(gcode.New(400, "参数错误：缺少 turn_id 或 Last-Event-ID", nil))
+	}
+	if err = logic.GetChat().CheckSession(ctx, owner, req.ID); err != nil {
+		return nil, err
+	}
+	return &v1.ChatResumeRes{}, common.ServeTurnStream(ctx, req.ID, req.TurnId, req.Offset)
+}
+
+// resumeLastEvent 请求携带 Last-Event-ID 时视为断线重连：续传该轮次已缓冲的事件而不是开启新轮次
+func resumeLastEvent(ctx context.Context, owner logic.Owner, sessionId string) (bool, error) {
+	turnId, offset, ok := common.ParseLastEventId(g.RequestFromCtx(ctx).Header.Get("Last-Event-ID"))
+	if !ok {
+		return false, nil
+	}
+	if err := logic.GetChat().CheckSession(ctx, owner, sessionId); err != nil {
+		return true, err
+	}
+	g.Log().Infof(ctx, "[ChatResume] session=%s turn=%s offset=%d", sessionId, turnId, offset)
+	return true, common.ServeTurnStream(ctx, sessionId, turnId, offset)
+}
+
+// checkChatOptions 校验对话选项并返回当前用户设置：请求未携带的选项使用用户设置的默认值，
+// 回复语言写入 ctx；使用知识库时校验权限并把检索命名空间写入 ctx
+func checkChatOptions(ctx context.Context, opts *v1.ChatOptions) (context.Context, *v1settings.UserSettings, error) {
+	prefs := settings.Current(ctx)
+	applyChatDefaults(ctx, opts, &prefs.ChatDefaults)
+	ctx = common.WithLanguage(ctx, prefs.Language)
+
 	// 1. 参数范围校验
-	if req.TopK <= 0 || req.TopK > 20 {
-		return nil, gerror.NewCode(gcode.New(400, "参数错误：top_k取值范围为1-20", nil))
+	if opts.TopK <= 0 || opts.TopK > 20 {
+		return ctx, nil, gerror.NewCode(gcode.New(400, "参数错误：top_k取值范围为1-20", nil))
 	}
-	if req.Score < 0 || req.Score > 1 {
-		return nil, gerror.NewCode(gcode.New(400, "参数错误：score取值范围为0-1", nil))
+	if opts.Score < 0 || opts.Score > 1 {
+		return ctx, nil, gerror.NewCode(gcode.New(400, "参数错误：score取值范围为0-1", nil))
 	}
 
 	// 2. 知识库权限校验
-	if req.KnowledgeName != "" {
+	if opts.KnowledgeName != "" {
 		userUUID, err := utility.CurrentUserUUID(ctx)
 		if err != nil {
-			return nil, gerror.NewCode(gcode.New(401, "用户信息获取失败，请重新登录", nil))
+			return ctx, nil, gerror.NewCode(gcode.New(401, "用户信息获取失败，请重新登录", nil))
 		}
 		// 校验用户是否有权限访问该知识库
 		var kb entity.KnowledgeBase
 		err = dao.KnowledgeBase.Ctx(ctx).
-			Where(dao.KnowledgeBase.Columns().Name, req.KnowledgeName).
+			Where(dao.KnowledgeBase.Columns().Name, opts.KnowledgeName).
 			Where(dao.KnowledgeBase.Columns().UserUuid, userUUID).
 			Scan(&kb)
 		if err != nil || kb.Id == 0 {
-			return nil, gerror.NewCode(gcode.New(403, "无权访问该知识库或知识库不存在", nil))
+			return ctx, nil, gerror.NewCode(gcode.New(403, "无权访问该知识库或知识库不存在", nil))
 		}
 		if kb.Status != 1 {
-			return nil, gerror.NewCode(gcode.New(400, "知识库已禁用或正在处理中", nil))
+			return ctx, nil, gerror.NewCode(gcode.New(400, "知识库已禁用或正在处理中", nil))
 		}
+		// 检索按知识库 ID + 用户隔离，由 ChatAiModel / ChatNormalModel 从 ctx 读取
+		ctx = common.WithNamespace(ctx, common.Namespace{KnowledgeBaseId: kb.Id, UserUUID: kb.UserUuid})
 	}
 
 	// 3. 上传文件校验（简单校验文件名格式，避免路径遍历）
-	for _, fileName := range req.UploadedFiles {
+	for _, fileName := range opts.UploadedFiles {
 		if strings.Contains(fileName, "..") || strings.Contains(fileName, "/") || strings.Contains(fileName, "\\") {
-			return nil, gerror.NewCode(gcode.New(400, "参数错误：文件名包含非法字符", nil))
+			return ctx, nil, gerror.NewCode(gcode.New(400, "参数错误：文件名包含非法字符", nil))
 		}
 	}
+	return ctx, prefs, nil
+}
 
-	// 调试：打印 MultiContent
-	multiContent := req.GetMultiContent()
-	g.Log().Infof(ctx, "MultiContent 长度: %d", len(multiContent))
-	for i, part := range multiContent {
-		g.Log().Infof(ctx, "MultiContent[%d]: Type=%s, Text=%s, Base64Data长度=%d",
-			i, part.Type, part.Text, len(part.Base64Data))
+// applyChatDefaults 请求中未出现的对话选项使用用户设置的默认值，显式传入的值优先
+func applyChatDefaults(ctx context.Context, opts *v1.ChatOptions, defaults *v1settings.ChatDefaults) {
+	r := g.RequestFromCtx(ctx)
+	if r == nil {
+		return
+	}
+	absent := func(key string) bool { return r.GetRequest(key) == nil }
+	if defaults.TopK != nil && absent("top_k") {
+		opts.TopK = *defaults.TopK
+	}
+	if defaults.Score != nil && absent("score") {
+		opts.Score = *defaults.Score
+	}
+	if defaults.IsStudyMode != nil && absent("is_study_mode") {
+		opts.IsStudyMode = *defaults.IsStudyMode
+	}
+	if defaults.IsNetwork != nil && absent("is_network") {
+		opts.IsNetwork = *defaults.IsNetwork
+	}
+	if defaults.IsDeepThinking != nil && absent("is_deep_thinking") {
+		opts.IsDeepThinking = *defaults.IsDeepThinking
+	}
+}
+
+// pruneSessions 按用户设置的会话上限清理最早的会话，失败只记录日志，不影响本次对话
+func pruneSessions(ctx context.Context, owner logic.Owner, prefs *v1settings.UserSettings, current string) {
+	if owner.UserId == "" || prefs.MaxSessions <= 0 {
+		return
+	}
+	n, err := logic.GetChat().PruneSessions(ctx, owner, prefs.MaxSessions, current)
+	if err != nil {
+		g.Log().Warningf(ctx, "清理超出上限的会话失败: user=%s, 错误: %v", owner.UserId, err)
+		return
+	}
+	if n > 0 {
+		g.Log().Infof(ctx, "已清理超出上限的会话 %d 个: user=%s, max_sessions=%d", n, owner.UserId, prefs.MaxSessions)
+	}
+}
+
+// chatUsage 把用量归属写入 ctx，并在生成前校验当前用户的 token 额度
+func chatUsage(ctx context.Context, owner logic.Owner, sessionId string) (context.Context, error) {
+	scope := usage.Scope{
+		Source:          usage.SourceChat,
+		AnonymousId:     owner.AnonymousId,
+		SessionId:       sessionId,
+		KnowledgeBaseId: common.NamespaceFromContext(ctx).KnowledgeBaseId,
 	}
+	if owner.UserId != "" {
+		userUUID, err := utility.CurrentUserUUID(ctx)
+		if err != nil {
+			return ctx, err
+		}
+		scope.UserUUID = userUUID
+	}
+	if err := usage.CheckQuota(ctx, scope); err != nil {
+		return ctx, err
+	}
+	return usage.WithScope(ctx, scope), nil
+}
 
-	var streamReader *schema.StreamReader[*schema.Message]
-	var documents []*schema.Document
+// runTurn 登记轮次并流式输出：生成使用轮次上下文，客户端断开不会中止，可续传或调用 /chat/cancel 停止
+func runTurn(ctx context.Context, req *v1.AiChatReq, turn *logic.Turn) error {
+	return streamTurn(ctx, turn, func(turnCtx context.Context) (*schema.StreamReader[*schema.Message], []*schema.Document, error) {
+		fmt.Printf("使用联网状态：%t，知识库使用：%s\n", req.IsNetwork, req.KnowledgeName)
+		if req.IsStudyMode != true {
+			return api.ChatNormalModel(turnCtx, req)
+		}
+		return api.ChatAiModel(turnCtx, req)
+	})
+}
 
-	fmt.Printf("使用联网状态：%t，知识库使用：%s\n", req.IsNetwork, req.KnowledgeName)
-	if req.IsStudyMode != true {
-		streamReader, documents, err = api.ChatNormalModel(ctx, req)
-	} else {
-		streamReader, documents, err = api.ChatAiModel(ctx, req)
+// approvalTurn 工具等待审批时随审批记录保存的轮次数据，答复后据此重建轮次续写
+type approvalTurn struct {
+	Turn      *logic.Turn      `json:"turn"`
+	Namespace common.Namespace `json:"namespace"`
+}
+
+// streamTurn 登记轮次，以轮次上下文调用 generate 并把结果流式输出
+func streamTurn(ctx context.Context, turn *logic.Turn, generate func(turnCtx context.Context) (*schema.StreamReader[*schema.Message], []*schema.Document, error)) error {
+	// 先保存用户消息：轮次被取消或生成失败时问题仍保留在会话中
+	if err := logic.GetChat().SaveUserMessage(ctx, turn); err != nil {
+		return err
 	}
+	turnCtx := logic.GetChat().StartTurn(ctx, turn)
+	// Agent 工具等待审批时保存本轮数据，答复后以新轮次续写
+	data, _ := json.Marshal(approvalTurn{Turn: turn, Namespace: common.NamespaceFromContext(ctx)})
+	turnCtx = common.WithApprovalTurn(turnCtx, &common.ApprovalTurn{
+		SessionId: turn.SessionId,
+		TurnId:    turn.ReplyMsgId,
+		Data:      data,
+	})
+
+	streamReader, documents, err := generate(turnCtx)
 
 	// 业务层调用失败，直接返回错误（此时还没发送任何响应头）
 	if err != nil {
+		logic.GetChat().FinishTurn(turn)
 		g.Log().Error(ctx, "LLM调用失败：", err)
 		// 内部错误脱敏，不返回具体错误信息
 		if gerror.Code(err).Code() >= 500 {
-			return nil, gerror.NewCode(gcode.New(500, "服务暂时不可用，请稍后重试", nil))
+			return gerror.NewCode(gcode.New(500, "服务暂时不可用，请稍后重试", nil))
 		}
-		return nil, err
+		return err
 	}
-	defer streamReader.Close()
-
-	// 所有校验通过，进入流式响应
-	err = common.StreamResponse(ctx, streamReader, documents)
-	if err != nil {
+	// 所有校验通过，进入流式响应；streamReader 由 StreamResponse 关闭
+	ctx = common.WithStreamTurn(ctx, &common.StreamTurn{
+		SessionId:   turn.SessionId,
+		TurnId:      turn.ReplyMsgId,
+		MsgId:       turn.UserMsgId,
+		ReplyMsgId:  turn.ReplyMsgId,
+		ParentMsgId: turn.ParentMsgId,
+	})
+	if err = common.StreamResponse(ctx, streamReader, documents); err != nil {
 		g.Log().Error(ctx, "流式响应失败：", err)
 	}
-
-	return &v1.AiChatRes{}, nil
+	return nil
 }
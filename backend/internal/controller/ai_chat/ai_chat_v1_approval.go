@@ -0,0 +1,89 @@
+package ai_chat
+
+import (
+	logic "backend/internal/logic/ai_chat"
+	"backend/studyCoach/api"
+	"backend/studyCoach/common"
+	"context"
+	"encoding/json"
+
+	v1 "backend/api/ai_chat/v1"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/google/uuid"
+)
+
+func (c *ControllerV1) ChatApproval(ctx context.Context, req *v1.ChatApprovalReq) (res *v1.ChatApprovalRes, err error) {
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if resumed, err := resumeLastEvent(ctx, owner, req.ID); resumed {
+		return &v1.ChatApprovalRes{}, err
+	}
+	if err = logic.GetChat().CheckSession(ctx, owner, req.ID); err != nil {
+		return nil, err
+	}
+	rec, err := common.LoadApproval(ctx, req.ID, req.ApprovalId)
+	if err != nil {
+		return nil, err
+	}
+	var data approvalTurn
+	if err = json.Unmarshal(rec.Turn, &data); err != nil || data.Turn == nil {
+		g.Log().Errorf(ctx, "[ChatApproval] 审批记录中的轮次数据无效: approval=%s, 错误: %v", rec.Id, err)
+		return nil, gerror.NewCode(gcode.New(500, "审批记录无效，请重新发送消息", nil))
+	}
+	ctx = common.WithNamespace(ctx, data.Namespace)
+	if ctx, err = chatUsage(ctx, owner, req.ID); err != nil {
+		return nil, err
+	}
+	// 先认领防止重复答复；续写失败时由 ResumeAgent 放回记录
+	if err = common.ClaimApproval(ctx, rec); err != nil {
+		return nil, err
+	}
+	g.Log().Infof(ctx, "[ChatApproval] session=%s approval=%s approved=%v", req.ID, rec.Id, req.Approved)
+
+	// 续写作为新轮次：沿用原轮次的用户消息，回复使用新的消息 ID
+	turn := data.Turn
+	turn.ReplyMsgId = req.ReplyMsgId
+	if turn.ReplyMsgId == "" {
+		turn.ReplyMsgId = uuid.NewString()
+	}
+	decision := &common.ApprovalDecision{Approved: req.Approved, Reason: req.Reason}
+	return &v1.ChatApprovalRes{}, streamTurn(ctx, turn, func(turnCtx context.Context) (*schema.StreamReader[*schema.Message], []*schema.Document, error) {
+		sr, err := api.ResumeChat(turnCtx, rec, decision)
+		return sr, nil, err
+	})
+}
+
+func (c *ControllerV1) ChatApprovals(ctx context.Context, req *v1.ChatApprovalsReq) (res *v1.ChatApprovalsRes, err error) {
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = logic.GetChat().CheckSession(ctx, owner, req.ID); err != nil {
+		return nil, err
+	}
+	approvals, err := common.ListApprovals(ctx, req.ID)
+	if err != nil {
+		g.Log().Errorf(ctx, "[ChatApprovals] 读取待审批记录失败: session=%s, 错误: %v", req.ID, err)
+		return nil, gerror.NewCode(gcode.New(500, "读取待审批记录失败", nil))
+	}
+	res = &v1.ChatApprovalsRes{List: make([]v1.ToolApproval, 0, len(approvals))}
+	for _, a := range approvals {
+		item := v1.ToolApproval{
+			ApprovalId: a.Id,
+			SessionId:  a.SessionId,
+			TurnId:     a.TurnId,
+			ExpiresAt:  a.ExpiresAt,
+		}
+		for _, call := range a.Calls {
+			item.Calls = append(item.Calls, v1.ToolApprovalCall(call))
+		}
+		res.List = append(res.List, item)
+	}
+	return res, nil
+}
@@ -3,20 +3,16 @@ package ai_chat
 import (
 	v1 "backend/api/ai_chat/v1"
 	logic "backend/internal/logic/ai_chat"
-	"backend/utility"
 	"context"
-
-	"github.com/gogf/gf/v2/util/gconv"
 )
 
 func (c *ControllerV1) SaveSession(ctx context.Context, req *v1.SaveSessionReq) (res *v1.SaveSessionRes, err error) {
-	claims, err := utility.JWTMap(ctx)
+	owner, err := logic.CurrentOwner(ctx)
 	if err != nil {
 		return nil, err
 	}
-	userId := gconv.String(claims["Username"])
 
-	newId, err := logic.GetChat().SaveSession(ctx, userId, req)
+	newId, err := logic.GetChat().SaveSession(ctx, owner, req)
 	if err != nil {
 		return nil, err
 	}
@@ -24,13 +20,12 @@ func (c *ControllerV1) SaveSession(ctx context.Context, req *v1.SaveSessionReq)
 }
 
 func (c *ControllerV1) GetHistory(ctx context.Context, req *v1.GetHistoryReq) (res *v1.GetHistoryRes, err error) {
-	claims, err := utility.JWTMap(ctx)
+	owner, err := logic.CurrentOwner(ctx)
 	if err != nil {
 		return nil, err
 	}
-	userId := gconv.String(claims["Username"])
 
-	list, total, err := logic.GetChat().GetHistory(ctx, userId, req.Page, req.PageSize)
+	list, total, err := logic.GetChat().GetHistory(ctx, owner, req.Page, req.PageSize)
 	if err != nil {
 		return nil, err
 	}
@@ -43,26 +38,37 @@ func (c *ControllerV1) GetHistory(ctx context.Context, req *v1.GetHistoryReq) (r
 }
 
 func (c *ControllerV1) GetSession(ctx context.Context, req *v1.GetSessionReq) (res *v1.GetSessionRes, err error) {
-	claims, err := utility.JWTMap(ctx)
+	owner, err := logic.CurrentOwner(ctx)
 	if err != nil {
 		return nil, err
 	}
-	userId := gconv.String(claims["Username"])
 
-	res, err = logic.GetChat().GetSession(ctx, userId, req.Id, req.BeforeMsgId, req.Limit)
+	res, err = logic.GetChat().GetSession(ctx, owner, req.Id, req.BeforeMsgId, req.Limit)
 	return
 }
 
 func (c *ControllerV1) DeleteSession(ctx context.Context, req *v1.DeleteSessionReq) (res *v1.DeleteSessionRes, err error) {
-	claims, err := utility.JWTMap(ctx)
+	owner, err := logic.CurrentOwner(ctx)
 	if err != nil {
 		return nil, err
 	}
-	userId := gconv.String(claims["Username"])
 
-	err = logic.GetChat().DeleteSession(ctx, userId, req.Id)
+	err = logic.GetChat().DeleteSession(ctx, owner, req.Id)
 	if err != nil {
 		return nil, err
 	}
 	return &v1.DeleteSessionRes{Id: req.Id}, nil
 }
+
+func (c *ControllerV1) SwitchBranch(ctx context.Context, req *v1.SwitchBranchReq) (res *v1.SwitchBranchRes, err error) {
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	leaf, err := logic.GetChat().SwitchBranch(ctx, owner, req.Id, req.MsgId)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.SwitchBranchRes{ActiveMsgId: leaf}, nil
+}
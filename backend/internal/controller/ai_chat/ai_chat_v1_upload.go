@@ -2,14 +2,28 @@ package ai_chat
 
 import (
 	v1 "backend/api/ai_chat/v1"
+	logic "backend/internal/logic/ai_chat"
+	"backend/internal/logic/settings"
 	"backend/studyCoach/aiModel/eino_tools/filesystem"
 	"context"
+	"mime"
+	"net/http"
+	"os"
 	"path/filepath"
 
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 )
 
 func (c *ControllerV1) UploadChatFile(ctx context.Context, req *v1.UploadChatFileReq) (res *v1.UploadChatFileRes, err error) {
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = logic.GetChat().EnsureSession(ctx, owner, req.Id, "", settings.Current(ctx).AutoSaveSessions); err != nil {
+		return nil, err
+	}
 	workDir, err := filesystem.GetWorkDirForSession(ctx, req.Id)
 	if err != nil {
 		g.Log().Errorf(ctx, "[UploadChatFile] GetWorkDirForSession failed: %v", err)
@@ -23,6 +37,17 @@ func (c *ControllerV1) UploadChatFile(ctx context.Context, req *v1.UploadChatFil
 		return &v1.UploadChatFileRes{FileNames: []string{}}, nil
 	}
 
+	// 按本次上传的总大小与文件数校验会话工作目录配额
+	defer filesystem.LockWorkDir(req.Id)()
+	var addBytes int64
+	for _, uf := range uploadFiles {
+		addBytes += uf.Size
+	}
+	quota := filesystem.QuotaFor(ctx, owner.UserId == "")
+	if err = filesystem.CheckWorkDirQuota(ctx, req.Id, quota, addBytes, len(uploadFiles)); err != nil {
+		return nil, err
+	}
+
 	var savedNames []string
 	for _, uf := range uploadFiles {
 		// 使用原始文件名，若重名则加后缀（Save 的 randomlyRename 可避免覆盖）
@@ -38,3 +63,78 @@ func (c *ControllerV1) UploadChatFile(ctx context.Context, req *v1.UploadChatFil
 	g.Log().Infof(ctx, "[UploadChatFile] 已保存 %d 个文件到会话 %s: %v", len(savedNames), req.Id, savedNames)
 	return &v1.UploadChatFileRes{FileNames: savedNames}, nil
 }
+
+func (c *ControllerV1) ChatFiles(ctx context.Context, req *v1.ChatFilesReq) (res *v1.ChatFilesRes, err error) {
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = logic.GetChat().CheckSession(ctx, owner, req.Id); err != nil {
+		return nil, err
+	}
+	files, err := filesystem.ListWorkDir(ctx, req.Id)
+	if err != nil {
+		g.Log().Errorf(ctx, "[ChatFiles] 读取工作目录失败: session=%s, 错误: %v", req.Id, err)
+		return nil, err
+	}
+	quota := filesystem.QuotaFor(ctx, owner.UserId == "")
+	res = &v1.ChatFilesRes{
+		Files:    make([]v1.WorkDirFile, 0, len(files)),
+		MaxBytes: quota.MaxBytes,
+		MaxFiles: quota.MaxFiles,
+	}
+	res.TotalBytes, res.FileCount = filesystem.WorkDirUsage(files)
+	for _, f := range files {
+		res.Files = append(res.Files, v1.WorkDirFile{
+			Path:      f.Path,
+			Size:      f.Size,
+			UpdatedAt: f.UpdatedAt.Unix(),
+		})
+	}
+	return res, nil
+}
+
+func (c *ControllerV1) ChatFileDownload(ctx context.Context, req *v1.ChatFileDownloadReq) (res *v1.ChatFileDownloadRes, err error) {
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = logic.GetChat().CheckSession(ctx, owner, req.Id); err != nil {
+		return nil, err
+	}
+	path, err := filesystem.WorkDirFilePath(ctx, req.Id, req.Path)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, gerror.NewCode(gcode.New(404, "文件不存在", nil))
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	// 以 application/octet-stream 直接输出文件内容，响应中间件不再追加 JSON（空文件也不会）
+	r := g.RequestFromCtx(ctx)
+	name := filepath.Base(path)
+	r.Response.Header().Set("Content-Type", "application/octet-stream")
+	r.Response.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	http.ServeContent(r.Response.Writer, r.Request, name, info.ModTime(), f)
+	return nil, nil
+}
+
+func (c *ControllerV1) ChatFileDelete(ctx context.Context, req *v1.ChatFileDeleteReq) (res *v1.ChatFileDeleteRes, err error) {
+	owner, err := logic.CurrentOwner(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err = logic.GetChat().CheckSession(ctx, owner, req.Id); err != nil {
+		return nil, err
+	}
+	if err = filesystem.DeleteWorkDirFile(ctx, req.Id, req.Path); err != nil {
+		return nil, err
+	}
+	g.Log().Infof(ctx, "[ChatFileDelete] 已删除会话 %s 的文件: %s", req.Id, req.Path)
+	return &v1.ChatFileDeleteRes{Path: req.Path}, nil
+}
@@ -0,0 +1,15 @@
+// =================================================================================
+// This is auto-generated by GoFrame CLI tool only once. Fill this file as you wish.
+// =================================================================================
+
+package api_key
+
+import (
+	"backend/api/api_key"
+)
+
+type ControllerV1 struct{}
+
+func NewV1() api_key.IApiKeyV1 {
+	return &ControllerV1{}
+}
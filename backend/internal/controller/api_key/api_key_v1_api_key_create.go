@@ -0,0 +1,29 @@
+package api_key
+
+import (
+	"backend/internal/logic/apikey"
+	"backend/utility"
+	"context"
+
+	"backend/api/api_key/v1"
+)
+
+func (c *ControllerV1) ApiKeyCreate(ctx context.Context, req *v1.ApiKeyCreateReq) (res *v1.ApiKeyCreateRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	key, row, err := apikey.Create(ctx, userUUID, req.Name)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ApiKeyCreateRes{
+		ApiKey: v1.ApiKey{
+			Id:        row.Id,
+			Name:      row.Name,
+			KeyPrefix: row.KeyPrefix,
+			CreatedAt: row.CreatedAt,
+		},
+		Key: key,
+	}, nil
+}
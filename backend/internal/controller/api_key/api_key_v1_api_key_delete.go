@@ -0,0 +1,18 @@
+package api_key
+
+import (
+	"backend/internal/logic/apikey"
+	"backend/utility"
+	"context"
+
+	"backend/api/api_key/v1"
+)
+
+func (c *ControllerV1) ApiKeyDelete(ctx context.Context, req *v1.ApiKeyDeleteReq) (res *v1.ApiKeyDeleteRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = apikey.Delete(ctx, userUUID, req.Id)
+	return
+}
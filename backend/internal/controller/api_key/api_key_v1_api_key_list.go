@@ -0,0 +1,31 @@
+package api_key
+
+import (
+	"backend/internal/logic/apikey"
+	"backend/utility"
+	"context"
+
+	"backend/api/api_key/v1"
+)
+
+func (c *ControllerV1) ApiKeyList(ctx context.Context, req *v1.ApiKeyListReq) (res *v1.ApiKeyListRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	list, err := apikey.List(ctx, userUUID)
+	if err != nil {
+		return nil, err
+	}
+	res = &v1.ApiKeyListRes{List: make([]v1.ApiKey, 0, len(list))}
+	for _, row := range list {
+		res.List = append(res.List, v1.ApiKey{
+			Id:         row.Id,
+			Name:       row.Name,
+			KeyPrefix:  row.KeyPrefix,
+			LastUsedAt: row.LastUsedAt,
+			CreatedAt:  row.CreatedAt,
+		})
+	}
+	return
+}
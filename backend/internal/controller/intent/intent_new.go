@@ -0,0 +1,15 @@
+// =================================================================================
+// This is auto-generated by GoFrame CLI tool only once. Fill this file as you wish.
+// =================================================================================
+
+package intent
+
+import (
+	"backend/api/intent"
+)
+
+type ControllerV1 struct{}
+
+func NewV1() intent.IIntentV1 {
+	return &ControllerV1{}
+}
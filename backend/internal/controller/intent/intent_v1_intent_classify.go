@@ -0,0 +1,25 @@
+package intent
+
+import (
+	"backend/internal/logic/intent"
+	"backend/utility"
+	"context"
+
+	"backend/api/intent/v1"
+)
+
+func (c *ControllerV1) IntentClassify(ctx context.Context, req *v1.IntentClassifyReq) (res *v1.IntentClassifyRes, err error) {
+	if _, err = utility.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	d, confident := intent.Classify(ctx, req.Question)
+	res = &v1.IntentClassifyRes{
+		Intent:    d.Intent,
+		Confident: confident,
+		Scores:    make([]v1.IntentScore, 0, len(d.Scores)),
+	}
+	for _, s := range d.Scores {
+		res.Scores = append(res.Scores, v1.IntentScore{Intent: s.Intent, Score: s.Score})
+	}
+	return res, nil
+}
@@ -0,0 +1,21 @@
+package intent
+
+import (
+	"backend/internal/logic/intent"
+	"backend/utility"
+	"context"
+
+	"backend/api/intent/v1"
+)
+
+func (c *ControllerV1) IntentExampleCreate(ctx context.Context, req *v1.IntentExampleCreateReq) (res *v1.IntentExampleCreateRes, err error) {
+	username, err := utility.CheckAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	example, err := intent.CreateExample(ctx, username, req.Intent, req.Text)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.IntentExampleCreateRes{IntentExample: *example}, nil
+}
@@ -0,0 +1,19 @@
+package intent
+
+import (
+	"backend/internal/logic/intent"
+	"backend/utility"
+	"context"
+
+	"backend/api/intent/v1"
+)
+
+func (c *ControllerV1) IntentExampleDelete(ctx context.Context, req *v1.IntentExampleDeleteReq) (res *v1.IntentExampleDeleteRes, err error) {
+	if _, err = utility.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err = intent.DeleteExample(ctx, req.Id); err != nil {
+		return nil, err
+	}
+	return &v1.IntentExampleDeleteRes{}, nil
+}
@@ -0,0 +1,20 @@
+package intent
+
+import (
+	"backend/internal/logic/intent"
+	"backend/utility"
+	"context"
+
+	"backend/api/intent/v1"
+)
+
+func (c *ControllerV1) IntentExamples(ctx context.Context, req *v1.IntentExamplesReq) (res *v1.IntentExamplesRes, err error) {
+	if _, err = utility.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	list, err := intent.ListExamples(ctx, req.Intent)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.IntentExamplesRes{List: list}, nil
+}
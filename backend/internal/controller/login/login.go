@@ -12,6 +12,7 @@ import (
 
 	"github.com/gogf/gf/v2/errors/gcode"
 	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -20,7 +21,9 @@ func (c *ControllerV1) Login(ctx context.Context, req *v1.LoginReq) (res *v1.Log
 	if err != nil {
 		return nil, err
 	}
-	// 登录成功后，将未登录时的会话合并到用户历史
+	// 登录成功后，将匿名令牌创建的会话转移给用户，并合并仅保存在前端的旧版会话
+	anonymousId := logicChat.AnonymousId(g.RequestFromCtx(ctx).GetHeader(logicChat.AnonymousTokenHeader))
+	_ = logicChat.GetChat().ClaimAnonymousSessions(ctx, anonymousId, req.Username)
 	if len(req.AnonymousSessions) > 0 {
 		sessions := make([]logicChat.MergeSessionInput, 0, len(req.AnonymousSessions))
 		for _, s := range req.AnonymousSessions {
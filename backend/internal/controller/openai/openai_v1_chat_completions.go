@@ -0,0 +1,45 @@
+package openai
+
+import (
+	"backend/internal/logic/apikey"
+	"backend/internal/logic/knowledge"
+	logic "backend/internal/logic/openai"
+	"backend/internal/logic/usage"
+	"backend/studyCoach/common"
+	"context"
+
+	"backend/api/openai/v1"
+)
+
+// ChatCompletions OpenAI 兼容的对话接口：API Key 认证，知识库按 Key 所属用户校验，额度与 /chat 共用
+func (c *ControllerV1) ChatCompletions(ctx context.Context, req *v1.ChatCompletionsReq) (res *v1.ChatCompletionsRes, err error) {
+	userUUID := apikey.UserFromContext(ctx)
+	id := logic.NewCompletionId()
+	scope := usage.Scope{Source: usage.SourceAPI, UserUUID: userUUID, SessionId: id}
+	if req.KnowledgeName != "" {
+		ns, err := knowledge.GetNamespace(ctx, userUUID, req.KnowledgeName)
+		if err != nil {
+			return nil, err
+		}
+		ctx = common.WithNamespace(ctx, ns)
+		scope.KnowledgeBaseId = ns.KnowledgeBaseId
+	}
+	if err = usage.CheckQuota(ctx, scope); err != nil {
+		return nil, err
+	}
+	ctx = usage.WithScope(ctx, scope)
+
+	sr, docs, err := logic.Start(ctx, id, req)
+	if err != nil {
+		return nil, err
+	}
+	if req.Stream {
+		logic.Stream(ctx, id, req, sr, docs)
+		return nil, nil
+	}
+	completion, err := logic.Complete(ctx, id, req, sr, docs)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.ChatCompletionsRes{ChatCompletion: *completion}, nil
+}
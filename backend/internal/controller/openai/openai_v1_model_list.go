@@ -0,0 +1,12 @@
+package openai
+
+import (
+	logic "backend/internal/logic/openai"
+	"context"
+
+	"backend/api/openai/v1"
+)
+
+func (c *ControllerV1) ModelList(ctx context.Context, req *v1.ModelListReq) (res *v1.ModelListRes, err error) {
+	return &v1.ModelListRes{Object: "list", Data: logic.Models()}, nil
+}
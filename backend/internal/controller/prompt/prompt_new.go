@@ -0,0 +1,15 @@
+// =================================================================================
+// This is auto-generated by GoFrame CLI tool only once. Fill this file as you wish.
+// =================================================================================
+
+package prompt
+
+import (
+	"backend/api/prompt"
+)
+
+type ControllerV1 struct{}
+
+func NewV1() prompt.IPromptV1 {
+	return &ControllerV1{}
+}
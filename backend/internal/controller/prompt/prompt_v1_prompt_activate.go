@@ -0,0 +1,20 @@
+package prompt
+
+import (
+	"backend/internal/logic/prompts"
+	"backend/utility"
+	"context"
+
+	"backend/api/prompt/v1"
+)
+
+func (c *ControllerV1) PromptActivate(ctx context.Context, req *v1.PromptActivateReq) (res *v1.PromptActivateRes, err error) {
+	if _, err = utility.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	version, err := prompts.Activate(ctx, req.Id)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.PromptActivateRes{PromptVersion: *version}, nil
+}
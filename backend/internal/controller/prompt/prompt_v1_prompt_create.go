@@ -0,0 +1,21 @@
+package prompt
+
+import (
+	"backend/internal/logic/prompts"
+	"backend/utility"
+	"context"
+
+	"backend/api/prompt/v1"
+)
+
+func (c *ControllerV1) PromptCreate(ctx context.Context, req *v1.PromptCreateReq) (res *v1.PromptCreateRes, err error) {
+	username, err := utility.CheckAdmin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	version, err := prompts.Create(ctx, username, req)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.PromptCreateRes{PromptVersion: *version}, nil
+}
@@ -0,0 +1,19 @@
+package prompt
+
+import (
+	"backend/internal/logic/prompts"
+	"backend/utility"
+	"context"
+
+	"backend/api/prompt/v1"
+)
+
+func (c *ControllerV1) PromptDeactivate(ctx context.Context, req *v1.PromptDeactivateReq) (res *v1.PromptDeactivateRes, err error) {
+	if _, err = utility.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	if err = prompts.Deactivate(ctx, req.Node, req.Scope, req.ScopeId); err != nil {
+		return nil, err
+	}
+	return &v1.PromptDeactivateRes{}, nil
+}
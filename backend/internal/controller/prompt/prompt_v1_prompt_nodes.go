@@ -0,0 +1,20 @@
+package prompt
+
+import (
+	"backend/internal/logic/prompts"
+	"backend/utility"
+	"context"
+
+	"backend/api/prompt/v1"
+)
+
+func (c *ControllerV1) PromptNodes(ctx context.Context, req *v1.PromptNodesReq) (res *v1.PromptNodesRes, err error) {
+	if _, err = utility.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	list, err := prompts.ListNodes(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.PromptNodesRes{List: list}, nil
+}
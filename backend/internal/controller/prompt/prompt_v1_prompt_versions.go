@@ -0,0 +1,20 @@
+package prompt
+
+import (
+	"backend/internal/logic/prompts"
+	"backend/utility"
+	"context"
+
+	"backend/api/prompt/v1"
+)
+
+func (c *ControllerV1) PromptVersions(ctx context.Context, req *v1.PromptVersionsReq) (res *v1.PromptVersionsRes, err error) {
+	if _, err = utility.CheckAdmin(ctx); err != nil {
+		return nil, err
+	}
+	list, err := prompts.ListVersions(ctx, req.Node, req.Scope, req.ScopeId)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.PromptVersionsRes{List: list}, nil
+}
@@ -26,7 +26,7 @@ func (c *ControllerV1) ChunkDelete(ctx context.Context, req *v1.ChunkDeleteReq)
 	if err = knowledge.EnsureDocumentBelongsToUser(ctx, userUUID, chunk.KnowledgeDocId); err != nil {
 		return nil, err
 	}
-	if err = knowledge.DeleteChunkById(ctx, req.Id); err != nil {
+	if err = knowledge.DeleteChunkById(ctx, userUUID, req.Id); err != nil {
 		return nil, err
 	}
 	return &v1.ChunkDeleteRes{}, nil
@@ -16,7 +16,7 @@ func (c *ControllerV1) DocumentsDelete(ctx context.Context, req *v1.DocumentsDel
 	if err = knowledge.EnsureDocumentBelongsToUser(ctx, userUUID, req.DocumentId); err != nil {
 		return nil, err
 	}
-	err = knowledge.DeleteDocument(ctx, req.DocumentId)
+	err = knowledge.DeleteDocument(ctx, userUUID, req.DocumentId)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,26 @@
+package rag
+
+import (
+	"backend/internal/logic/evaluation"
+	"backend/internal/logic/knowledge"
+	"backend/utility"
+	"context"
+
+	"backend/api/rag/v1"
+)
+
+func (c *ControllerV1) EvalQuestionAdd(ctx context.Context, req *v1.EvalQuestionAddReq) (res *v1.EvalQuestionAddRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := knowledge.GetNamespace(ctx, userUUID, req.KnowledgeName)
+	if err != nil {
+		return nil, err
+	}
+	ids, err := evaluation.AddQuestions(ctx, ns, req.KnowledgeName, req.Questions)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.EvalQuestionAddRes{Ids: ids}, nil
+}
@@ -0,0 +1,18 @@
+package rag
+
+import (
+	"backend/internal/logic/evaluation"
+	"backend/utility"
+	"context"
+
+	"backend/api/rag/v1"
+)
+
+func (c *ControllerV1) EvalQuestionDelete(ctx context.Context, req *v1.EvalQuestionDeleteReq) (res *v1.EvalQuestionDeleteRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	err = evaluation.DeleteQuestions(ctx, userUUID, req.Ids)
+	return
+}
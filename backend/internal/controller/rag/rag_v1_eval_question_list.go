@@ -0,0 +1,33 @@
+package rag
+
+import (
+	"backend/internal/logic/evaluation"
+	"backend/internal/logic/knowledge"
+	"backend/utility"
+	"context"
+
+	"backend/api/rag/v1"
+)
+
+func (c *ControllerV1) EvalQuestionList(ctx context.Context, req *v1.EvalQuestionListReq) (res *v1.EvalQuestionListRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := knowledge.GetNamespace(ctx, userUUID, req.KnowledgeName)
+	if err != nil {
+		return nil, err
+	}
+	list, total, err := evaluation.ListQuestions(ctx, ns, req.Page, req.Size)
+	if err != nil {
+		return
+	}
+
+	res = &v1.EvalQuestionListRes{
+		Data:  list,
+		Total: total,
+		Page:  req.Page,
+		Size:  req.Size,
+	}
+	return
+}
@@ -0,0 +1,34 @@
+package rag
+
+import (
+	"backend/internal/logic/evaluation"
+	"backend/internal/logic/knowledge"
+	"backend/utility"
+	"context"
+
+	"backend/api/rag/v1"
+)
+
+func (c *ControllerV1) EvalRun(ctx context.Context, req *v1.EvalRunReq) (res *v1.EvalRunRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := knowledge.GetNamespace(ctx, userUUID, req.KnowledgeName)
+	if err != nil {
+		return nil, err
+	}
+	runId, err := evaluation.Start(ctx, evaluation.RunOptions{
+		Namespace:     ns,
+		KnowledgeName: req.KnowledgeName,
+		TopK:          req.TopK,
+		Score:         req.Score,
+		Mode:          req.Mode,
+		Profile:       req.RetrievalProfile,
+		Note:          req.Note,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &v1.EvalRunRes{RunId: runId}, nil
+}
@@ -0,0 +1,21 @@
+package rag
+
+import (
+	"backend/internal/logic/evaluation"
+	"backend/utility"
+	"context"
+
+	"backend/api/rag/v1"
+)
+
+func (c *ControllerV1) EvalRunGet(ctx context.Context, req *v1.EvalRunGetReq) (res *v1.EvalRunGetRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	run, err := evaluation.GetRun(ctx, userUUID, req.RunId)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.EvalRunGetRes{KnowledgeEvalRuns: run}, nil
+}
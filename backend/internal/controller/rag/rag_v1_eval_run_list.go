@@ -0,0 +1,28 @@
+package rag
+
+import (
+	"backend/internal/logic/evaluation"
+	"backend/utility"
+	"context"
+
+	"backend/api/rag/v1"
+)
+
+func (c *ControllerV1) EvalRunList(ctx context.Context, req *v1.EvalRunListReq) (res *v1.EvalRunListRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	runs, total, err := evaluation.ListRuns(ctx, userUUID, req.KnowledgeName, req.Page, req.Size)
+	if err != nil {
+		return
+	}
+
+	res = &v1.EvalRunListRes{
+		Data:  runs,
+		Total: total,
+		Page:  req.Page,
+		Size:  req.Size,
+	}
+	return
+}
@@ -1,11 +1,11 @@
 package rag
 
 import (
+	"backend/internal/logic/indexjob"
 	"backend/internal/logic/knowledge"
 	"backend/internal/logic/rag"
+	"backend/internal/logic/usage"
 	"backend/internal/model/entity"
-	"backend/studyCoach/api"
-	"backend/studyCoach/mineruworker"
 	"backend/utility"
 	"context"
 	"fmt"
@@ -17,16 +17,20 @@ import (
 	v1 "backend/api/rag/v1"
 )
 
+// Indexer 保存上传文件并创建索引任务后立即返回；解析、切分、向量化、写入与 QA 由后台任务执行
 func (c *ControllerV1) Indexer(ctx context.Context, req *v1.IndexerReq) (res *v1.IndexerRes, err error) {
 	userUUID, err := utility.CurrentUserUUID(ctx)
 	if err != nil {
 		return nil, err
 	}
-	if err = knowledge.EnsureKnowledgeBaseBelongsToUser(ctx, userUUID, req.KnowledgeName); err != nil {
+	ns, err := knowledge.GetNamespace(ctx, userUUID, req.KnowledgeName)
+	if err != nil {
+		return nil, err
+	}
+	if err = usage.CheckQuota(ctx, usage.Scope{UserUUID: userUUID}); err != nil {
 		return nil, err
 	}
-	svr := rag.GetRagSvr()
-	if svr == nil {
+	if rag.GetRagSvr() == nil {
 		return nil, fmt.Errorf("RAG服务未初始化，请检查Elasticsearch和embedding配置")
 	}
 	url := strings.TrimSpace(req.URL)
@@ -43,8 +47,12 @@ func (c *ControllerV1) Indexer(ctx context.Context, req *v1.IndexerReq) (res *v1
 		// URL 索引：fileName 用于展示与 PDF 判断
 		fileName = url
 	}
+	if url == "" {
+		return nil, fmt.Errorf("请上传文件或填写 URL")
+	}
 	documents := entity.KnowledgeDocuments{
 		KnowledgeBaseName: req.KnowledgeName,
+		KnowledgeBaseId:   ns.KnowledgeBaseId,
 		FileName:          fileName,
 		Status:            int(v1.StatusPending),
 	}
@@ -53,43 +61,24 @@ func (c *ControllerV1) Indexer(ctx context.Context, req *v1.IndexerReq) (res *v1
 		g.Log().Errorf(ctx, "SaveDocumentsInfo failed, err=%v", err)
 		return
 	}
-	// 开始索引处理，更新状态为索引中
-	err = knowledge.UpdateDocumentsStatus(ctx, documentsId, int(v1.StatusIndexing))
-	if err != nil {
-		g.Log().Errorf(ctx, "UpdateDocumentsStatus to indexing failed, err=%v", err)
-	}
-
-	// PDF：MinerU 精准解析为 Markdown 写入 files.root/mineru，再以 .md 走索引流水线（本地 PDF 与 PDF URL 均支持）
-	if mineruworker.IsPDFPath(fileName) {
-		mdPath, errMu := mineruworker.ExtractPDFToMarkdownFile(ctx, url, documentsId)
-		if errMu != nil {
-			return nil, errMu
-		}
-		url = mdPath
-	}
 
-	indexReq := &api.IndexReq{
-		URI:           url,
-		KnowledgeName: req.KnowledgeName,
-		DocumentsId:   documentsId,
-		FileName:      fileName,
-	}
-	ids, err := svr.Index(ctx, indexReq)
+	jobId, err := indexjob.Create(ctx, entity.KnowledgeIndexJobs{
+		UserUuid:          userUUID,
+		KnowledgeBaseId:   ns.KnowledgeBaseId,
+		KnowledgeBaseName: req.KnowledgeName,
+		DocumentsId:       documentsId,
+		FileName:          fileName,
+		Source:            url,
+	})
 	if err != nil {
-		return
+		_ = knowledge.UpdateDocumentsStatus(ctx, documentsId, int(v1.StatusFailed))
+		return nil, err
 	}
-
-	// 异步生成 QA 内容（后台处理，不阻塞响应）
-	go func() {
-		asyncCtx := context.Background()
-		asyncCtx = context.WithValue(asyncCtx, "userId", userUUID)
-		if err := svr.GenerateQAAsync(asyncCtx, documentsId, req.KnowledgeName); err != nil {
-			g.Log().Warningf(ctx, "GenerateQAAsync failed for documentsId=%d: %v", documentsId, err)
-		}
-	}()
+	indexjob.Enqueue(jobId)
 
 	res = &v1.IndexerRes{
-		DocIDs: ids,
+		JobId:       jobId,
+		DocumentsId: documentsId,
 	}
 	return
 }
@@ -0,0 +1,21 @@
+package rag
+
+import (
+	"backend/internal/logic/indexjob"
+	"backend/utility"
+	"context"
+
+	"backend/api/rag/v1"
+)
+
+func (c *ControllerV1) IndexerJob(ctx context.Context, req *v1.IndexerJobReq) (res *v1.IndexerJobRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	job, err := indexjob.Get(ctx, userUUID, req.JobId)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.IndexerJobRes{KnowledgeIndexJobs: job}, nil
+}
@@ -0,0 +1,28 @@
+package rag
+
+import (
+	"backend/internal/logic/indexjob"
+	"backend/utility"
+	"context"
+
+	"backend/api/rag/v1"
+)
+
+func (c *ControllerV1) IndexerJobList(ctx context.Context, req *v1.IndexerJobListReq) (res *v1.IndexerJobListRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	jobs, total, err := indexjob.List(ctx, userUUID, req.KnowledgeName, req.Status, req.Page, req.Size)
+	if err != nil {
+		return
+	}
+
+	res = &v1.IndexerJobListRes{
+		Data:  jobs,
+		Total: total,
+		Page:  req.Page,
+		Size:  req.Size,
+	}
+	return
+}
@@ -2,6 +2,7 @@ package rag
 
 import (
 	"backend/internal/dao"
+	"backend/internal/logic/knowledge"
 	"backend/internal/model/do"
 	"backend/utility"
 	"context"
@@ -14,12 +15,18 @@ func (c *ControllerV1) KBCreate(ctx context.Context, req *v1.KBCreateReq) (res *
 	if err != nil {
 		return nil, err
 	}
+	profile, err := knowledge.EncodeRetrievalProfile(req.RetrievalProfile)
+	if err != nil {
+		return nil, err
+	}
 	insertId, err := dao.KnowledgeBase.Ctx(ctx).Data(do.KnowledgeBase{
 		UserUuid:    userUUID,
 		Name:        req.Name,
 		Status:      v1.StatusOK,
 		Description: req.Description,
 		Category:    req.Category,
+
+		RetrievalProfile: profile,
 	}).InsertAndGetId()
 	if err != nil {
 		return nil, err
@@ -2,6 +2,7 @@ package rag
 
 import (
 	"backend/internal/dao"
+	"backend/studyCoach/aiModel/retriever"
 	"backend/utility"
 	"context"
 
@@ -13,6 +14,12 @@ func (c *ControllerV1) KBDelete(ctx context.Context, req *v1.KBDeleteReq) (res *
 	if err != nil {
 		return nil, err
 	}
-	_, err = dao.KnowledgeBase.Ctx(ctx).WherePri(req.Id).Where(dao.KnowledgeBase.Columns().UserUuid, userUUID).Delete()
+	result, err := dao.KnowledgeBase.Ctx(ctx).WherePri(req.Id).Where(dao.KnowledgeBase.Columns().UserUuid, userUUID).Delete()
+	if err != nil {
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		retriever.EvictLocalIndex(req.Id)
+	}
 	return
 }
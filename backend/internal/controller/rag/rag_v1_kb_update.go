@@ -2,6 +2,7 @@ package rag
 
 import (
 	"backend/internal/dao"
+	"backend/internal/logic/knowledge"
 	"backend/internal/model/do"
 	"backend/utility"
 	"context"
@@ -14,11 +15,17 @@ func (c *ControllerV1) KBUpdate(ctx context.Context, req *v1.KBUpdateReq) (res *
 	if err != nil {
 		return nil, err
 	}
-	_, err = dao.KnowledgeBase.Ctx(ctx).Data(do.KnowledgeBase{
+	data := do.KnowledgeBase{
 		Name:        req.Name,
 		Status:      req.Status,
 		Description: req.Description,
 		Category:    req.Category,
-	}).WherePri(req.Id).Where(dao.KnowledgeBase.Columns().UserUuid, userUUID).Update()
+	}
+	if req.RetrievalProfile != nil {
+		if data.RetrievalProfile, err = knowledge.EncodeRetrievalProfile(req.RetrievalProfile); err != nil {
+			return nil, err
+		}
+	}
+	_, err = dao.KnowledgeBase.Ctx(ctx).Data(data).WherePri(req.Id).Where(dao.KnowledgeBase.Columns().UserUuid, userUUID).Update()
 	return
 }
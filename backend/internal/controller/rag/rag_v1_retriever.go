@@ -21,7 +21,8 @@ func (c *ControllerV1) Retriever(ctx context.Context, req *v1.RetrieverReq) (res
 	if err != nil {
 		return nil, err
 	}
-	if err = knowledge.EnsureKnowledgeBaseBelongsToUser(ctx, userUUID, req.KnowledgeName); err != nil {
+	ns, err := knowledge.GetNamespace(ctx, userUUID, req.KnowledgeName)
+	if err != nil {
 		return nil, err
 	}
 	ragSvr := rag.GetRagSvr()
@@ -39,12 +40,16 @@ func (c *ControllerV1) Retriever(ctx context.Context, req *v1.RetrieverReq) (res
 		TopK:          req.TopK,
 		Score:         req.Score,
 		KnowledgeName: req.KnowledgeName,
+		Namespace:     ns,
+		Mode:          req.Mode,
+		Profile:       req.RetrievalProfile,
 	}
 	g.Log().Infof(ctx, "ragReq: %v", ragReq)
-	msg, err := ragSvr.Retriever(ctx, ragReq)
+	result, err := ragSvr.Retrieve(ctx, ragReq)
 	if err != nil {
 		return
 	}
+	msg := result.Documents
 	for _, document := range msg {
 		if document.MetaData != nil {
 			delete(document.MetaData, "_dense_vector")
@@ -70,6 +75,7 @@ func (c *ControllerV1) Retriever(ctx context.Context, req *v1.RetrieverReq) (res
 	}
 	res = &v1.RetrieverRes{
 		Document: msg,
+		Source:   result.Source,
 	}
 	return
 }
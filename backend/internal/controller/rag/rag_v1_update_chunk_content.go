@@ -5,10 +5,14 @@ import (
 
 	"backend/api/rag/v1"
 	"backend/internal/logic/knowledge"
+	"backend/internal/logic/rag"
+	"backend/studyCoach/api"
 	"backend/utility"
 
 	"github.com/gogf/gf/v2/errors/gcode"
 	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
 )
 
 func (c *ControllerV1) UpdateChunkContent(ctx context.Context, req *v1.UpdateChunkContentReq) (res *v1.UpdateChunkContentRes, err error) {
@@ -26,8 +30,48 @@ func (c *ControllerV1) UpdateChunkContent(ctx context.Context, req *v1.UpdateChu
 	if err = knowledge.EnsureDocumentBelongsToUser(ctx, userUUID, chunk.KnowledgeDocId); err != nil {
 		return nil, err
 	}
+	if chunk.Content == req.Content {
+		return &v1.UpdateChunkContentRes{}, nil
+	}
+	svr := rag.GetRagSvr()
+	if svr == nil {
+		return nil, gerror.New("RAG服务未初始化，请检查向量库和embedding配置")
+	}
+	doc, err := knowledge.GetDocumentById(ctx, chunk.KnowledgeDocId)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := knowledge.GetNamespace(ctx, userUUID, doc.KnowledgeBaseName)
+	if err != nil {
+		return nil, err
+	}
 	if err = knowledge.UpdateChunkContentById(ctx, req.Id, req.Content); err != nil {
 		return nil, err
 	}
+	// 正文变更后需重新生成 QA 与向量，沿用原 chunk_id 覆盖向量库中的旧数据
+	reindexReq := &api.ReindexChunkReq{
+		ChunkId:       chunk.ChunkId,
+		Content:       req.Content,
+		Ext:           chunk.Ext,
+		KnowledgeName: doc.KnowledgeBaseName,
+		Namespace:     ns,
+		DocumentsId:   chunk.KnowledgeDocId,
+	}
+	reindexInBackground(svr, reindexReq, req.Id)
 	return &v1.UpdateChunkContentRes{}, nil
 }
+
+type chunkReindexer interface {
+	ReindexChunk(ctx context.Context, req *api.ReindexChunkReq) error
+}
+
+// reindexInBackground 在后台重建单个切片的索引。请求返回后其 ctx 即被取消，
+// 因此重建与失败日志统一使用独立的 ctx；切片的 index_status 由 ReindexChunk 维护。
+func reindexInBackground(svr chunkReindexer, req *api.ReindexChunkReq, id int64) {
+	go func() {
+		ctx := gctx.New()
+		if err := svr.ReindexChunk(ctx, req); err != nil {
+			g.Log().Errorf(ctx, "ReindexChunk failed, chunkId=%d, err=%v", id, err)
+		}
+	}()
+}
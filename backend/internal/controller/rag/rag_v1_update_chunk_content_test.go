@@ -0,0 +1,38 @@
+package rag
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"backend/studyCoach/api"
+)
+
+type fakeReindexer struct {
+	done chan context.Context
+}
+
+func (f *fakeReindexer) ReindexChunk(ctx context.Context, req *api.ReindexChunkReq) error {
+	// 模拟耗时的重建：请求早已返回，ctx 仍需可用
+	time.Sleep(10 * time.Millisecond)
+	f.done <- ctx
+	return errors.New("embedding 失败")
+}
+
+func TestReindexInBackgroundDetachedCtx(t *testing.T) {
+	// 后台重建不继承请求 ctx：请求返回后 ctx 被取消，重建与失败日志仍使用有效的独立 ctx
+	f := &fakeReindexer{done: make(chan context.Context, 1)}
+	reindexInBackground(f, &api.ReindexChunkReq{ChunkId: "c1"}, 1)
+	select {
+	case ctx := <-f.done:
+		if ctx.Err() != nil {
+			t.Fatalf("后台重建的 ctx 不应被取消: %v", ctx.Err())
+		}
+		if _, ok := ctx.Deadline(); ok {
+			t.Fatal("后台重建的 ctx 不应带有请求的截止时间")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("后台重建未执行")
+	}
+}
@@ -0,0 +1,21 @@
+package settings
+
+import (
+	"backend/internal/logic/settings"
+	"backend/utility"
+	"context"
+
+	"backend/api/settings/v1"
+)
+
+func (c *ControllerV1) SettingsGet(ctx context.Context, req *v1.SettingsGetReq) (res *v1.SettingsGetRes, err error) {
+	userId, err := utility.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := settings.Get(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.SettingsGetRes{UserSettings: *s}, nil
+}
@@ -0,0 +1,21 @@
+package settings
+
+import (
+	"backend/internal/logic/settings"
+	"backend/utility"
+	"context"
+
+	"backend/api/settings/v1"
+)
+
+func (c *ControllerV1) SettingsUpdate(ctx context.Context, req *v1.SettingsUpdateReq) (res *v1.SettingsUpdateRes, err error) {
+	userId, err := utility.CurrentUserID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	s, err := settings.Update(ctx, userId, req)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.SettingsUpdateRes{UserSettings: *s}, nil
+}
@@ -0,0 +1,15 @@
+// =================================================================================
+// This is auto-generated by GoFrame CLI tool only once. Fill this file as you wish.
+// =================================================================================
+
+package usage
+
+import (
+	"backend/api/usage"
+)
+
+type ControllerV1 struct{}
+
+func NewV1() usage.IUsageV1 {
+	return &ControllerV1{}
+}
@@ -0,0 +1,26 @@
+package usage
+
+import (
+	"backend/internal/logic/knowledge"
+	"backend/internal/logic/usage"
+	"backend/utility"
+	"context"
+
+	"backend/api/usage/v1"
+)
+
+func (c *ControllerV1) KnowledgeUsage(ctx context.Context, req *v1.KnowledgeUsageReq) (res *v1.KnowledgeUsageRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ns, err := knowledge.GetNamespace(ctx, userUUID, req.KnowledgeName)
+	if err != nil {
+		return nil, err
+	}
+	report, err := usage.KnowledgeReport(ctx, ns.KnowledgeBaseId, req.Days)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.KnowledgeUsageRes{UsageReport: *report}, nil
+}
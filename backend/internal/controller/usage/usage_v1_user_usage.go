@@ -0,0 +1,26 @@
+package usage
+
+import (
+	"backend/internal/logic/usage"
+	"backend/utility"
+	"context"
+
+	"backend/api/usage/v1"
+)
+
+func (c *ControllerV1) UserUsage(ctx context.Context, req *v1.UserUsageReq) (res *v1.UserUsageRes, err error) {
+	userUUID, err := utility.CurrentUserUUID(ctx)
+	if err != nil {
+		return nil, err
+	}
+	scope := usage.Scope{UserUUID: userUUID}
+	report, err := usage.UserReport(ctx, scope, req.Days)
+	if err != nil {
+		return nil, err
+	}
+	quota, err := usage.QuotaStatus(ctx, scope)
+	if err != nil {
+		return nil, err
+	}
+	return &v1.UserUsageRes{UsageReport: *report, Quota: quota}, nil
+}
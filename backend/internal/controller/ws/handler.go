@@ -1,10 +1,12 @@
 package ws
 
 import (
+	"backend/utility"
 	"context"
 	"encoding/json"
 	"log"
 	"net/http"
+	"strings"
 
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
@@ -78,9 +80,14 @@ func (c *Client) readPump(ctx context.Context) {
 		case "ping":
 			_ = sendJSON(c.Conn, map[string]any{"type": "pong"})
 		case "auth":
-			// 鉴权：校验 token，后续可扩展按用户推送
+			// 鉴权：校验 JWT，将用户 UUID 记入 client 以便按用户推送（如索引任务进度）
 			if msg.Token != "" {
-				// 可选：校验 JWT，将 userID 存入 client
+				claims := &utility.JwtClaims{}
+				if _, err := utility.Decryption(strings.TrimPrefix(msg.Token, "Bearer "), claims); err != nil {
+					_ = sendJSON(c.Conn, map[string]any{"type": "auth_failed"})
+					continue
+				}
+				c.Hub.setUserUUID(c, claims.Uuid)
 				_ = sendJSON(c.Conn, map[string]any{"type": "auth_ok"})
 			}
 		default:
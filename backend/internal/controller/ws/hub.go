@@ -28,12 +28,20 @@ type Client struct {
 	Send      chan []byte
 	Remote    string // 客户端地址（TCP 层）
 	UserAgent string // 请求头 User-Agent（截断记入日志）
+	UserUUID  string // auth 成功后写入，用于按用户推送
+}
+
+// directMessage 定向推送给某用户全部连接的消息
+type directMessage struct {
+	userUUID string
+	data     []byte
 }
 
 // Hub 管理所有 WebSocket 连接，支持广播
 type Hub struct {
 	clients    map[*Client]bool
 	broadcast  chan []byte
+	direct     chan directMessage
 	register   chan *Client
 	unregister chan *Client
 	mu         sync.RWMutex
@@ -44,6 +52,7 @@ func NewHub() *Hub {
 	return &Hub{
 		clients:    make(map[*Client]bool),
 		broadcast:  make(chan []byte, 256),
+		direct:     make(chan directMessage, 256),
 		register:   make(chan *Client),
 		unregister: make(chan *Client),
 	}
@@ -82,6 +91,21 @@ func (h *Hub) Run() {
 				}
 			}
 			h.mu.RUnlock()
+
+		case m := <-h.direct:
+			h.mu.Lock()
+			for client := range h.clients {
+				if client.UserUUID != m.userUUID {
+					continue
+				}
+				select {
+				case client.Send <- m.data:
+				default:
+					close(client.Send)
+					delete(h.clients, client)
+				}
+			}
+			h.mu.Unlock()
 		}
 	}
 }
@@ -100,6 +124,30 @@ func (h *Hub) BroadcastJSON(v any) {
 	}
 }
 
+// SendToUser 向指定用户的所有已鉴权连接推送 JSON 消息
+func (h *Hub) SendToUser(userUUID string, v any) {
+	if userUUID == "" {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("[WS] SendToUser marshal error: %v", err)
+		return
+	}
+	select {
+	case h.direct <- directMessage{userUUID: userUUID, data: data}:
+	default:
+		log.Printf("[WS] Direct channel full, drop message")
+	}
+}
+
+// setUserUUID 记录连接所属用户（与 Run 中的读取互斥）
+func (h *Hub) setUserUUID(c *Client, userUUID string) {
+	h.mu.Lock()
+	c.UserUUID = userUUID
+	h.mu.Unlock()
+}
+
 // BroadcastCronComplete 广播定时任务完成通知
 func (h *Hub) BroadcastCronComplete(cronID int64, cronName string, success bool) {
 	h.BroadcastJSON(map[string]any{
@@ -128,3 +176,10 @@ func BroadcastCronCompleteGlobal(cronID int64, cronName string, success bool) {
 		DefaultHub.BroadcastCronComplete(cronID, cronName, success)
 	}
 }
+
+// SendToUserGlobal 使用 DefaultHub 向指定用户推送（供 logic 层调用）
+func SendToUserGlobal(userUUID string, v any) {
+	if DefaultHub != nil {
+		DefaultHub.SendToUser(userUUID, v)
+	}
+}
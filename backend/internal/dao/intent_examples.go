@@ -0,0 +1,22 @@
+// =================================================================================
+// This file is auto-generated by the GoFrame CLI tool. You may modify it as needed.
+// =================================================================================
+
+package dao
+
+import (
+	"backend/internal/dao/internal"
+)
+
+// intentExamplesDao is the data access object for the table intent_examples.
+// You can define custom methods on it to extend its functionality as needed.
+type intentExamplesDao struct {
+	*internal.IntentExamplesDao
+}
+
+var (
+	// IntentExamples is a globally accessible object for table intent_examples operations.
+	IntentExamples = intentExamplesDao{internal.NewIntentExamplesDao()}
+)
+
+// Add your custom methods and functionality below.
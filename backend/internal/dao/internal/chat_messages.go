@@ -24,11 +24,13 @@ type ChatMessagesColumns struct {
 	Id               string //
 	SessionUuid      string //
 	MsgId            string //
+	ParentMsgId      string //
 	Content          string //
 	MultiContent     string //
 	IsUser           string //
 	Timestamp        string //
 	ReasoningContent string //
+	Citations        string //
 }
 
 // chatMessagesColumns holds the columns for the table chat_messages.
@@ -36,11 +38,13 @@ var chatMessagesColumns = ChatMessagesColumns{
 	Id:               "id",
 	SessionUuid:      "session_uuid",
 	MsgId:            "msg_id",
+	ParentMsgId:      "parent_msg_id",
 	Content:          "content",
 	MultiContent:     "multi_content",
 	IsUser:           "is_user",
 	Timestamp:        "timestamp",
 	ReasoningContent: "reasoning_content",
+	Citations:        "citations",
 }
 
 // NewChatMessagesDao creates and returns a new DAO object for table data access.
@@ -21,22 +21,30 @@ type ChatSessionsDao struct {
 
 // ChatSessionsColumns defines and stores column names for the table chat_sessions.
 type ChatSessionsColumns struct {
-	Id        string //
-	Uuid      string //
-	UserId    string //
-	Title     string //
-	CreatedAt string //
-	UpdatedAt string //
+	Id           string //
+	Uuid         string //
+	UserId       string //
+	AnonymousId  string //
+	ActiveMsgId  string //
+	Summary      string //
+	SummaryMsgId string //
+	Title        string //
+	CreatedAt    string //
+	UpdatedAt    string //
 }
 
 // chatSessionsColumns holds the columns for the table chat_sessions.
 var chatSessionsColumns = ChatSessionsColumns{
-	Id:        "id",
-	Uuid:      "uuid",
-	UserId:    "user_id",
-	Title:     "title",
-	CreatedAt: "created_at",
-	UpdatedAt: "updated_at",
+	Id:           "id",
+	Uuid:         "uuid",
+	UserId:       "user_id",
+	AnonymousId:  "anonymous_id",
+	ActiveMsgId:  "active_msg_id",
+	Summary:      "summary",
+	SummaryMsgId: "summary_msg_id",
+	Title:        "title",
+	CreatedAt:    "created_at",
+	UpdatedAt:    "updated_at",
 }
 
 // NewChatSessionsDao creates and returns a new DAO object for table data access.
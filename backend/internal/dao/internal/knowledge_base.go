@@ -21,26 +21,28 @@ type KnowledgeBaseDao struct {
 
 // KnowledgeBaseColumns defines and stores column names for the table knowledge_base.
 type KnowledgeBaseColumns struct {
-	Id          string //
-	UserUuid    string //
-	Name        string //
-	Description string //
-	Category    string //
-	Status      string //
-	CreatedAt   string //
-	UpdatedAt   string //
+	Id               string //
+	UserUuid         string //
+	Name             string //
+	Description      string //
+	Category         string //
+	Status           string //
+	RetrievalProfile string //
+	CreatedAt        string //
+	UpdatedAt        string //
 }
 
 // knowledgeBaseColumns holds the columns for the table knowledge_base.
 var knowledgeBaseColumns = KnowledgeBaseColumns{
-	Id:          "id",
-	UserUuid:    "user_uuid",
-	Name:        "name",
-	Description: "description",
-	Category:    "category",
-	Status:      "status",
-	CreatedAt:   "created_at",
-	UpdatedAt:   "updated_at",
+	Id:               "id",
+	UserUuid:         "user_uuid",
+	Name:             "name",
+	Description:      "description",
+	Category:         "category",
+	Status:           "status",
+	RetrievalProfile: "retrieval_profile",
+	CreatedAt:        "created_at",
+	UpdatedAt:        "updated_at",
 }
 
 // NewKnowledgeBaseDao creates and returns a new DAO object for table data access.
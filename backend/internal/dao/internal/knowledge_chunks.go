@@ -27,6 +27,7 @@ type KnowledgeChunksColumns struct {
 	Content        string //
 	Ext            string //
 	Status         string //
+	IndexStatus    string //
 	CreatedAt      string //
 	UpdatedAt      string //
 }
@@ -39,6 +40,7 @@ var knowledgeChunksColumns = KnowledgeChunksColumns{
 	Content:        "content",
 	Ext:            "ext",
 	Status:         "status",
+	IndexStatus:    "index_status",
 	CreatedAt:      "created_at",
 	UpdatedAt:      "updated_at",
 }
@@ -23,6 +23,7 @@ type KnowledgeDocumentsDao struct {
 type KnowledgeDocumentsColumns struct {
 	Id                string //
 	KnowledgeBaseName string //
+	KnowledgeBaseId   string //
 	FileName          string //
 	Status            string //
 	CreatedAt         string //
@@ -33,6 +34,7 @@ type KnowledgeDocumentsColumns struct {
 var knowledgeDocumentsColumns = KnowledgeDocumentsColumns{
 	Id:                "id",
 	KnowledgeBaseName: "knowledge_base_name",
+	KnowledgeBaseId:   "knowledge_base_id",
 	FileName:          "file_name",
 	Status:            "status",
 	CreatedAt:         "created_at",
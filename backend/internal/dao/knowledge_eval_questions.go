@@ -0,0 +1,22 @@
+// =================================================================================
+// This file is auto-generated by the GoFrame CLI tool. You may modify it as needed.
+// =================================================================================
+
+package dao
+
+import (
+	"backend/internal/dao/internal"
+)
+
+// knowledgeEvalQuestionsDao is the data access object for the table knowledge_eval_questions.
+// You can define custom methods on it to extend its functionality as needed.
+type knowledgeEvalQuestionsDao struct {
+	*internal.KnowledgeEvalQuestionsDao
+}
+
+var (
+	// KnowledgeEvalQuestions is a globally accessible object for table knowledge_eval_questions operations.
+	KnowledgeEvalQuestions = knowledgeEvalQuestionsDao{internal.NewKnowledgeEvalQuestionsDao()}
+)
+
+// Add your custom methods and functionality below.
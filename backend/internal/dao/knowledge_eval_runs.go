@@ -0,0 +1,22 @@
+// =================================================================================
+// This file is auto-generated by the GoFrame CLI tool. You may modify it as needed.
+// =================================================================================
+
+package dao
+
+import (
+	"backend/internal/dao/internal"
+)
+
+// knowledgeEvalRunsDao is the data access object for the table knowledge_eval_runs.
+// You can define custom methods on it to extend its functionality as needed.
+type knowledgeEvalRunsDao struct {
+	*internal.KnowledgeEvalRunsDao
+}
+
+var (
+	// KnowledgeEvalRuns is a globally accessible object for table knowledge_eval_runs operations.
+	KnowledgeEvalRuns = knowledgeEvalRunsDao{internal.NewKnowledgeEvalRunsDao()}
+)
+
+// Add your custom methods and functionality below.
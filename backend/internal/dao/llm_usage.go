@@ -0,0 +1,22 @@
+// =================================================================================
+// This file is auto-generated by the GoFrame CLI tool. You may modify it as needed.
+// =================================================================================
+
+package dao
+
+import (
+	"backend/internal/dao/internal"
+)
+
+// llmUsageDao is the data access object for the table llm_usage.
+// You can define custom methods on it to extend its functionality as needed.
+type llmUsageDao struct {
+	*internal.LlmUsageDao
+}
+
+var (
+	// LlmUsage is a globally accessible object for table llm_usage operations.
+	LlmUsage = llmUsageDao{internal.NewLlmUsageDao()}
+)
+
+// Add your custom methods and functionality below.
@@ -0,0 +1,22 @@
+// =================================================================================
+// This file is auto-generated by the GoFrame CLI tool. You may modify it as needed.
+// =================================================================================
+
+package dao
+
+import (
+	"backend/internal/dao/internal"
+)
+
+// promptTemplatesDao is the data access object for the table prompt_templates.
+// You can define custom methods on it to extend its functionality as needed.
+type promptTemplatesDao struct {
+	*internal.PromptTemplatesDao
+}
+
+var (
+	// PromptTemplates is a globally accessible object for table prompt_templates operations.
+	PromptTemplates = promptTemplatesDao{internal.NewPromptTemplatesDao()}
+)
+
+// Add your custom methods and functionality below.
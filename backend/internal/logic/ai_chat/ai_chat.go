@@ -6,12 +6,10 @@ import (
 
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
-	"github.com/wangle201210/chat-history/eino"
 )
 
 type ChatBase struct {
 	cm model.BaseChatModel
-	eh *eino.History
 }
 
 var chat *ChatBase
@@ -0,0 +1,185 @@
+package ai_chat
+
+import (
+	"backend/internal/dao"
+	"backend/internal/model/entity"
+	"context"
+	"fmt"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// messageTree 会话内的消息树：parent_msg_id 指向父消息，同一父消息下的消息互为版本（重新生成或编辑产生）
+type messageTree struct {
+	byMsgId  map[string]*entity.ChatMessages
+	children map[string][]*entity.ChatMessages // 按 id 升序
+}
+
+// loadTree 加载会话全部消息构建消息树，withReasoning 为 false 时不读取思考过程
+func (c *ChatBase) loadTree(ctx context.Context, session *entity.ChatSessions, withReasoning bool) (*messageTree, error) {
+	var rows []*entity.ChatMessages
+	if isEphemeral(session) {
+		var err error
+		if rows, err = ephemeralMessages(ctx, session.Uuid, withReasoning); err != nil {
+			return nil, err
+		}
+	} else {
+		model := dao.ChatMessages.Ctx(ctx).Where(dao.ChatMessages.Columns().SessionUuid, session.Uuid)
+		if !withReasoning {
+			model = model.FieldsEx(dao.ChatMessages.Columns().ReasoningContent)
+		}
+		if err := model.OrderAsc(dao.ChatMessages.Columns().Id).Scan(&rows); err != nil {
+			g.Log().Errorf(ctx, "获取会话消息失败: session=%s, 错误: %v", session.Uuid, err)
+			return nil, fmt.Errorf("获取会话消息失败: %w", err)
+		}
+	}
+	tree := &messageTree{
+		byMsgId:  make(map[string]*entity.ChatMessages, len(rows)),
+		children: make(map[string][]*entity.ChatMessages),
+	}
+	for _, m := range rows {
+		tree.byMsgId[m.MsgId] = m
+		tree.children[m.ParentMsgId] = append(tree.children[m.ParentMsgId], m)
+	}
+	return tree, nil
+}
+
+// path 从根到 leaf 的分支（时间升序），leaf 为空时返回空
+func (t *messageTree) path(leaf string) []*entity.ChatMessages {
+	var path []*entity.ChatMessages
+	seen := make(map[string]bool)
+	for id := leaf; id != "" && !seen[id]; {
+		m, ok := t.byMsgId[id]
+		if !ok {
+			break
+		}
+		seen[id] = true
+		path = append(path, m)
+		id = m.ParentMsgId
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path
+}
+
+// latestLeaf 从 msgId 沿最新的子消息向下走到叶子
+func (t *messageTree) latestLeaf(msgId string) string {
+	seen := make(map[string]bool)
+	for !seen[msgId] {
+		seen[msgId] = true
+		children := t.children[msgId]
+		if len(children) == 0 {
+			break
+		}
+		msgId = children[len(children)-1].MsgId
+	}
+	return msgId
+}
+
+// siblings 与消息同父的全部版本 msg_id（按创建顺序）
+func (t *messageTree) siblings(m *entity.ChatMessages) []string {
+	list := t.children[m.ParentMsgId]
+	ids := make([]string, 0, len(list))
+	for _, s := range list {
+		if s.IsUser == m.IsUser {
+			ids = append(ids, s.MsgId)
+		}
+	}
+	return ids
+}
+
+// activeLeaf 返回会话当前分支的末尾消息；旧数据没有父子关系时按时间顺序串成一条分支
+func (c *ChatBase) activeLeaf(ctx context.Context, session *entity.ChatSessions) (string, error) {
+	if session.ActiveMsgId != "" || isEphemeral(session) {
+		return session.ActiveMsgId, nil
+	}
+	var rows []entity.ChatMessages
+	err := dao.ChatMessages.Ctx(ctx).
+		Fields(dao.ChatMessages.Columns().Id, dao.ChatMessages.Columns().MsgId, dao.ChatMessages.Columns().ParentMsgId).
+		Where(dao.ChatMessages.Columns().SessionUuid, session.Uuid).
+		OrderAsc(dao.ChatMessages.Columns().Id).
+		Scan(&rows)
+	if err != nil || len(rows) == 0 {
+		return "", err
+	}
+	err = dao.ChatMessages.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		for i := 1; i < len(rows); i++ {
+			if rows[i].ParentMsgId != "" {
+				continue
+			}
+			_, err := dao.ChatMessages.Ctx(ctx).
+				Where(dao.ChatMessages.Columns().Id, rows[i].Id).
+				Data(dao.ChatMessages.Columns().ParentMsgId, rows[i-1].MsgId).
+				Update()
+			if err != nil {
+				return err
+			}
+		}
+		return c.setActive(ctx, session, rows[len(rows)-1].MsgId)
+	})
+	if err != nil {
+		g.Log().Errorf(ctx, "整理会话分支失败: session=%s, 错误: %v", session.Uuid, err)
+		return "", fmt.Errorf("整理会话分支失败: %w", err)
+	}
+	return rows[len(rows)-1].MsgId, nil
+}
+
+func (c *ChatBase) setActive(ctx context.Context, session *entity.ChatSessions, msgId string) error {
+	if isEphemeral(session) {
+		return updateEphemeral(ctx, session.Uuid, map[string]any{ephemeralActive: msgId})
+	}
+	_, err := dao.ChatSessions.Ctx(ctx).
+		Where(dao.ChatSessions.Columns().Uuid, session.Uuid).
+		Data(dao.ChatSessions.Columns().ActiveMsgId, msgId).
+		Update()
+	return err
+}
+
+// SwitchBranch 切换到 msgId 所在的分支（沿最新子消息走到末尾），返回新的分支末尾消息 ID
+func (c *ChatBase) SwitchBranch(ctx context.Context, owner Owner, sessionId, msgId string) (string, error) {
+	session, err := c.ownedSession(ctx, owner, sessionId)
+	if err != nil {
+		return "", err
+	}
+	if _, err = c.activeLeaf(ctx, session); err != nil {
+		return "", err
+	}
+	tree, err := c.loadTree(ctx, session, false)
+	if err != nil {
+		return "", err
+	}
+	if _, ok := tree.byMsgId[msgId]; !ok {
+		return "", gerror.NewCode(gcode.New(404, "消息不存在", nil))
+	}
+	leaf := tree.latestLeaf(msgId)
+	if err = c.setActive(ctx, session, leaf); err != nil {
+		g.Log().Errorf(ctx, "切换分支失败: session=%s, 错误: %v", sessionId, err)
+		return "", fmt.Errorf("切换分支失败: %w", err)
+	}
+	return leaf, nil
+}
+
+// CheckSession 校验会话存在且属于 owner
+func (c *ChatBase) CheckSession(ctx context.Context, owner Owner, sessionId string) error {
+	_, err := c.ownedSession(ctx, owner, sessionId)
+	return err
+}
+
+// ownedSession 查询属于 owner 的会话，不存在或属于他人时返回错误
+func (c *ChatBase) ownedSession(ctx context.Context, owner Owner, sessionId string) (*entity.ChatSessions, error) {
+	session, err := c.findSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, gerror.NewCode(gcode.New(404, "会话不存在", nil))
+	}
+	if !owner.owns(session.UserId, session.AnonymousId) {
+		return nil, gerror.NewCode(gcode.New(403, "无权访问该会话", nil))
+	}
+	return session, nil
+}
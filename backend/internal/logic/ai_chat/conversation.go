@@ -0,0 +1,448 @@
+package ai_chat
+
+import (
+	v1 "backend/api/ai_chat/v1"
+	"backend/internal/dao"
+	"backend/internal/model/do"
+	"backend/internal/model/entity"
+	"backend/studyCoach/common"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/go-sql-driver/mysql"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultSessionTitle = "新对话"
+	sessionTitleRunes   = 20
+	mysqlErrDupEntry    = 1062 // ER_DUP_ENTRY
+)
+
+// Turn 一轮对话：发送或编辑时写入新的用户消息，重新生成时复用已有用户消息；回复消息 ID 同时作为轮次 ID
+type Turn struct {
+	SessionId    string
+	ParentMsgId  string // 用户消息的父消息，模型上下文取到该消息为止的分支
+	UserMsgId    string
+	NewUserMsg   bool
+	Question     string
+	MultiContent []v1.MessagePart
+	ReplyMsgId   string
+	Ephemeral    bool // 临时会话（用户关闭自动保存时创建）：消息只暂存在 Redis，不写入数据库
+
+	cancel context.CancelFunc
+}
+
+// NewTurn 在 parentMsgId 之后发送新消息，parentMsgId 为空时接在当前分支末尾
+func (c *ChatBase) NewTurn(ctx context.Context, sessionId, parentMsgId, userMsgId, replyMsgId, question string, parts []v1.MessagePart) (*Turn, error) {
+	session, err := c.findSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, gerror.NewCode(gcode.New(404, "会话不存在", nil))
+	}
+	leaf, err := c.activeLeaf(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	if parentMsgId == "" {
+		parentMsgId = leaf
+	} else if _, err = c.findMessage(ctx, session, parentMsgId); err != nil {
+		return nil, err
+	}
+	return c.newTurn(ctx, session, parentMsgId, userMsgId, replyMsgId, question, parts)
+}
+
+// EditTurn 编辑用户消息 msgId：作为其同级新版本发送，并在新分支上继续对话
+func (c *ChatBase) EditTurn(ctx context.Context, sessionId, msgId, userMsgId, replyMsgId, question string, parts []v1.MessagePart) (*Turn, error) {
+	session, err := c.existingSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	m, err := c.findMessage(ctx, session, msgId)
+	if err != nil {
+		return nil, err
+	}
+	if m.IsUser != 1 {
+		return nil, gerror.NewCode(gcode.New(400, "只能编辑用户消息", nil))
+	}
+	return c.newTurn(ctx, session, m.ParentMsgId, userMsgId, replyMsgId, question, parts)
+}
+
+// RegenerateTurn 重新生成 msgId 对应的回复（msgId 可为回复或其用户消息），旧回复保留为同级版本
+func (c *ChatBase) RegenerateTurn(ctx context.Context, sessionId, msgId, replyMsgId string) (*Turn, error) {
+	session, err := c.existingSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	m, err := c.findMessage(ctx, session, msgId)
+	if err != nil {
+		return nil, err
+	}
+	if m.IsUser != 1 {
+		if m, err = c.findMessage(ctx, session, m.ParentMsgId); err != nil {
+			return nil, err
+		}
+		if m.IsUser != 1 {
+			return nil, gerror.NewCode(gcode.New(400, "该回复没有对应的用户消息，无法重新生成", nil))
+		}
+	}
+	turn := &Turn{
+		SessionId:   sessionId,
+		ParentMsgId: m.ParentMsgId,
+		UserMsgId:   m.MsgId,
+		Question:    m.Content,
+		ReplyMsgId:  replyMsgId,
+		Ephemeral:   isEphemeral(session),
+	}
+	if m.MultiContent != "" {
+		_ = json.Unmarshal([]byte(m.MultiContent), &turn.MultiContent)
+	}
+	if turn.ReplyMsgId == "" {
+		turn.ReplyMsgId = uuid.NewString()
+	}
+	return turn, nil
+}
+
+func (c *ChatBase) newTurn(ctx context.Context, session *entity.ChatSessions, parentMsgId, userMsgId, replyMsgId, question string, parts []v1.MessagePart) (*Turn, error) {
+	if userMsgId == "" {
+		userMsgId = uuid.NewString()
+	}
+	if replyMsgId == "" {
+		replyMsgId = uuid.NewString()
+	}
+	if userMsgId == replyMsgId {
+		return nil, gerror.NewCode(gcode.New(400, "msg_id 与 reply_msg_id 不能相同", nil))
+	}
+	for _, id := range []string{userMsgId, replyMsgId} {
+		if _, err := c.findMessage(ctx, session, id); err == nil {
+			return nil, gerror.NewCode(gcode.New(409, "消息 ID 已存在", nil))
+		}
+	}
+	return &Turn{
+		SessionId:    session.Uuid,
+		ParentMsgId:  parentMsgId,
+		UserMsgId:    userMsgId,
+		NewUserMsg:   true,
+		Question:     question,
+		MultiContent: parts,
+		ReplyMsgId:   replyMsgId,
+		Ephemeral:    isEphemeral(session),
+	}, nil
+}
+
+// findMessage 查询会话内的消息，不存在时返回 404
+func (c *ChatBase) findMessage(ctx context.Context, session *entity.ChatSessions, msgId string) (*entity.ChatMessages, error) {
+	var (
+		m   *entity.ChatMessages
+		err error
+	)
+	if isEphemeral(session) {
+		m, err = findEphemeralMessage(ctx, session.Uuid, msgId)
+	} else {
+		err = dao.ChatMessages.Ctx(ctx).
+			FieldsEx(dao.ChatMessages.Columns().ReasoningContent).
+			Where(dao.ChatMessages.Columns().SessionUuid, session.Uuid).
+			Where(dao.ChatMessages.Columns().MsgId, msgId).
+			Scan(&m)
+	}
+	if err != nil {
+		g.Log().Errorf(ctx, "查询消息失败: session=%s, msg=%s, 错误: %v", session.Uuid, msgId, err)
+		return nil, fmt.Errorf("查询消息失败: %w", err)
+	}
+	if m == nil {
+		return nil, gerror.NewCode(gcode.New(404, "消息不存在", nil))
+	}
+	return m, nil
+}
+
+// EnsureSession 确保会话存在且属于 owner：不存在时以 title 创建，属于其他用户时返回 403。
+// save 为 false（用户关闭自动保存）时新会话创建为临时会话，不写入数据库；已有会话保持原有方式
+func (c *ChatBase) EnsureSession(ctx context.Context, owner Owner, sessionId, title string, save bool) error {
+	session, err := c.findSession(ctx, sessionId)
+	if err != nil {
+		return err
+	}
+	if session == nil && !save {
+		now := gtime.Now()
+		created, err := createEphemeralSession(ctx, entity.ChatSessions{
+			Uuid:        sessionId,
+			UserId:      owner.UserId,
+			AnonymousId: owner.AnonymousId,
+			Title:       sessionTitle(title),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		})
+		if err != nil || created {
+			return err
+		}
+		// 并发创建，按已存在的会话校验归属
+		if session, err = c.findSession(ctx, sessionId); err != nil || session == nil {
+			return fmt.Errorf("创建会话失败: %v", err)
+		}
+	}
+	if session == nil {
+		now := gtime.Now()
+		_, err = dao.ChatSessions.Ctx(ctx).Data(do.ChatSessions{
+			Uuid:        sessionId,
+			UserId:      owner.UserId,
+			AnonymousId: owner.AnonymousId,
+			Title:       sessionTitle(title),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}).Insert()
+		if err == nil {
+			return nil
+		}
+		if !isDuplicateKey(err) {
+			g.Log().Errorf(ctx, "创建会话失败: session=%s, 错误: %v", sessionId, err)
+			return fmt.Errorf("创建会话失败: %w", err)
+		}
+		// 并发创建，按已存在的会话校验归属
+		if session, err = c.findSession(ctx, sessionId); err != nil || session == nil {
+			return fmt.Errorf("创建会话失败: %v", err)
+		}
+	}
+	if !owner.owns(session.UserId, session.AnonymousId) {
+		return gerror.NewCode(gcode.New(403, "无权访问该会话", nil))
+	}
+	return nil
+}
+
+// isDuplicateKey 判断是否为 MySQL 唯一键冲突（chat_sessions.uuid 唯一索引），即其他请求已创建同一会话
+func isDuplicateKey(err error) bool {
+	var mysqlErr *mysql.MySQLError
+	return errors.As(err, &mysqlErr) && mysqlErr.Number == mysqlErrDupEntry
+}
+
+// findSession 查询会话，数据库中没有时查询临时会话，都不存在时返回 nil
+func (c *ChatBase) findSession(ctx context.Context, sessionId string) (*entity.ChatSessions, error) {
+	var session *entity.ChatSessions
+	err := dao.ChatSessions.Ctx(ctx).Where(dao.ChatSessions.Columns().Uuid, sessionId).Scan(&session)
+	if err != nil {
+		g.Log().Errorf(ctx, "查询会话失败: session=%s, 错误: %v", sessionId, err)
+		return nil, fmt.Errorf("查询会话失败: %w", err)
+	}
+	if session != nil {
+		return session, nil
+	}
+	return loadEphemeralSession(ctx, sessionId)
+}
+
+// existingSession 查询会话，不存在时返回 404
+func (c *ChatBase) existingSession(ctx context.Context, sessionId string) (*entity.ChatSessions, error) {
+	session, err := c.findSession(ctx, sessionId)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil {
+		return nil, gerror.NewCode(gcode.New(404, "会话不存在", nil))
+	}
+	return session, nil
+}
+
+// LoadHistory 读取 leafMsgId 所在分支最近 limit 条消息作为模型上下文（时间升序），leafMsgId 为空时取当前分支
+func (c *ChatBase) LoadHistory(ctx context.Context, sessionId, leafMsgId string, limit int) ([]*schema.Message, error) {
+	session, err := c.findSession(ctx, sessionId)
+	if err != nil || session == nil {
+		return nil, err
+	}
+	if leafMsgId == "" {
+		if leafMsgId, err = c.activeLeaf(ctx, session); err != nil {
+			return nil, err
+		}
+	}
+	tree, err := c.loadTree(ctx, session, false)
+	if err != nil {
+		return nil, err
+	}
+	path := tree.path(leafMsgId)
+	if len(path) > limit {
+		path = path[len(path)-limit:]
+	}
+	history := make([]*schema.Message, 0, len(path))
+	for _, m := range path {
+		if msg := historyMessage(ctx, *m); msg != nil {
+			history = append(history, msg)
+		}
+	}
+	return history, nil
+}
+
+// historyMessage 数据库消息转为模型消息；图片只保留 URL，base64 不入库
+func historyMessage(ctx context.Context, m entity.ChatMessages) *schema.Message {
+	if m.IsUser != 1 {
+		content := m.Content
+		if m.Citations != "" {
+			content = common.StripCitations(content)
+		}
+		if content == "" {
+			return nil
+		}
+		return schema.AssistantMessage(content, nil)
+	}
+	var parts []v1.MessagePart
+	if m.MultiContent != "" {
+		if err := json.Unmarshal([]byte(m.MultiContent), &parts); err != nil {
+			g.Log().Warningf(ctx, "failed to unmarshal multi_content for msg %s: %v", m.MsgId, err)
+		}
+	}
+	var inputParts []schema.MessageInputPart
+	for _, p := range parts {
+		switch {
+		case p.Type == "text" && p.Text != "":
+			inputParts = append(inputParts, schema.MessageInputPart{Type: "text", Text: p.Text})
+		case p.Type == "image_url" && p.ImageURL != "":
+			img := &schema.MessageInputImage{}
+			img.URL = &p.ImageURL
+			inputParts = append(inputParts, schema.MessageInputPart{Type: "image_url", Image: img})
+		}
+	}
+	if len(inputParts) > 1 || (len(inputParts) == 1 && inputParts[0].Image != nil) {
+		return &schema.Message{Role: schema.User, UserInputMultiContent: inputParts}
+	}
+	if m.Content == "" {
+		return nil
+	}
+	return schema.UserMessage(m.Content)
+}
+
+// SaveUserMessage 在开始生成前写入本轮新的用户消息，并把会话当前分支指向它：
+// 轮次被取消或生成失败时已接收的问题仍保留在会话中，回复由 SaveTurn 在流结束时写入。临时会话写入 Redis
+func (c *ChatBase) SaveUserMessage(ctx context.Context, turn *Turn) error {
+	if !turn.NewUserMsg {
+		return nil
+	}
+	var err error
+	if turn.Ephemeral {
+		err = c.saveEphemeralUserMessage(ctx, turn)
+	} else {
+		err = dao.ChatMessages.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+			userMsg := do.ChatMessages{
+				SessionUuid: turn.SessionId,
+				MsgId:       turn.UserMsgId,
+				ParentMsgId: turn.ParentMsgId,
+				Content:     turnQuestion(turn),
+				IsUser:      1,
+				Timestamp:   gtime.Now(),
+			}
+			if parts := storedParts(turn.MultiContent); len(parts) > 0 {
+				multiJSON, _ := json.Marshal(parts)
+				userMsg.MultiContent = string(multiJSON)
+			}
+			if _, err := dao.ChatMessages.Ctx(ctx).Data(userMsg).Insert(); err != nil {
+				return err
+			}
+			_, err := dao.ChatSessions.Ctx(ctx).
+				Where(dao.ChatSessions.Columns().Uuid, turn.SessionId).
+				Data(g.Map{
+					dao.ChatSessions.Columns().ActiveMsgId: turn.UserMsgId,
+					dao.ChatSessions.Columns().UpdatedAt:   gtime.Now(),
+				}).
+				Update()
+			return err
+		})
+	}
+	if err != nil {
+		g.Log().Errorf(ctx, "保存用户消息失败: session=%s, 错误: %v", turn.SessionId, err)
+		return fmt.Errorf("保存用户消息失败: %w", err)
+	}
+	turn.NewUserMsg = false
+	return nil
+}
+
+// SaveTurn 写入一轮对话的回复（含引用来源），并把会话当前分支指向新回复；用户消息尚未写入时一并写入。临时会话写入 Redis
+func (c *ChatBase) SaveTurn(ctx context.Context, turn *Turn, reply *schema.Message, citations []common.Citation) error {
+	if err := c.SaveUserMessage(ctx, turn); err != nil {
+		return err
+	}
+	if turn.Ephemeral {
+		return c.saveEphemeralTurn(ctx, turn, reply, citations)
+	}
+	err := dao.ChatMessages.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		replyMsg := do.ChatMessages{
+			SessionUuid:      turn.SessionId,
+			MsgId:            turn.ReplyMsgId,
+			ParentMsgId:      turn.UserMsgId,
+			Content:          reply.Content,
+			IsUser:           0,
+			Timestamp:        gtime.Now(),
+			ReasoningContent: reply.ReasoningContent,
+		}
+		if len(citations) > 0 {
+			citationsJSON, _ := json.Marshal(citations)
+			replyMsg.Citations = string(citationsJSON)
+		}
+		_, err := dao.ChatMessages.Ctx(ctx).Data(replyMsg).Insert()
+		if err != nil {
+			return err
+		}
+		_, err = dao.ChatSessions.Ctx(ctx).
+			Where(dao.ChatSessions.Columns().Uuid, turn.SessionId).
+			Data(g.Map{
+				dao.ChatSessions.Columns().ActiveMsgId: turn.ReplyMsgId,
+				dao.ChatSessions.Columns().UpdatedAt:   gtime.Now(),
+			}).
+			Update()
+		return err
+	})
+	if err != nil {
+		g.Log().Errorf(ctx, "保存对话失败: session=%s, 错误: %v", turn.SessionId, err)
+		return fmt.Errorf("保存对话失败: %w", err)
+	}
+	return nil
+}
+
+// turnQuestion 用户消息的文本内容：多模态时拼接文本部分
+func turnQuestion(turn *Turn) string {
+	if turn.Question != "" {
+		return turn.Question
+	}
+	var texts []string
+	for _, p := range turn.MultiContent {
+		if p.Type == "text" && p.Text != "" {
+			texts = append(texts, p.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// storedParts 去掉图片 base64 后的多模态内容，仅有文本时不保存
+func storedParts(parts []v1.MessagePart) []v1.MessagePart {
+	var (
+		out      []v1.MessagePart
+		hasImage bool
+	)
+	for _, p := range parts {
+		p.Base64Data = ""
+		if p.Type == "image_url" {
+			hasImage = true
+		}
+		out = append(out, p)
+	}
+	if !hasImage {
+		return nil
+	}
+	return out
+}
+
+func sessionTitle(title string) string {
+	runes := []rune(strings.TrimSpace(title))
+	if len(runes) == 0 {
+		return defaultSessionTitle
+	}
+	if len(runes) > sessionTitleRunes {
+		return string(runes[:sessionTitleRunes]) + "..."
+	}
+	return string(runes)
+}
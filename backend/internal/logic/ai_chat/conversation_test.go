@@ -0,0 +1,32 @@
+package ai_chat
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+func TestIsDuplicateKey(t *testing.T) {
+	dup := &mysql.MySQLError{Number: mysqlErrDupEntry, Message: "Duplicate entry 'x' for key 'uk_chat_sessions_uuid'"}
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"驱动错误", dup, true},
+		{"经 gf 包装", gerror.WrapCode(gcode.CodeDbOperationError, dup, "insert"), true},
+		{"经 fmt 包装", fmt.Errorf("insert: %w", dup), true},
+		{"其他驱动错误", &mysql.MySQLError{Number: 1213, Message: "Deadlock found"}, false},
+		{"仅文本相同", errors.New("Duplicate entry 'x' for key 'uuid'"), false},
+		{"nil", nil, false},
+	}
+	for _, c := range cases {
+		if got := isDuplicateKey(c.err); got != c.want {
+			t.Errorf("%s: isDuplicateKey = %v，期望 %v", c.name, got, c.want)
+		}
+	}
+}
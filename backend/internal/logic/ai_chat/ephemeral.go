@@ -0,0 +1,238 @@
+package ai_chat
+
+import (
+	"backend/internal/model/entity"
+	"backend/studyCoach/common"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// 临时会话：用户关闭自动保存（auto_save_sessions=false）时创建的会话不写入数据库，
+// 会话与消息暂存在 Redis 哈希 chat:ephemeral:<会话> 中，每次写入刷新过期时间，
+// 过期或删除会话时一并清除；不出现在历史列表中，也不计入 max_sessions
+const (
+	defaultEphemeralTTL = 24 * time.Hour // 临时会话最后一次写入后的保留时间
+	ephemeralKeyPrefix  = "chat:ephemeral:"
+
+	ephemeralSession      = "session" // 会话基本信息（所有者、标题、创建时间）
+	ephemeralActive       = "active_msg_id"
+	ephemeralSummary      = "summary"
+	ephemeralSummaryMsgId = "summary_msg_id"
+	ephemeralSeq          = "seq"  // 消息自增 ID，保持与数据库一致的创建顺序
+	ephemeralMsgPrefix    = "msg:" // msg:<msg_id> -> 消息 JSON
+)
+
+func ephemeralKey(sessionId string) string {
+	return ephemeralKeyPrefix + sessionId
+}
+
+func ephemeralTTL(ctx context.Context) time.Duration {
+	return g.Cfg().MustGet(ctx, "chat.ephemeralTTL", defaultEphemeralTTL).Duration()
+}
+
+// isEphemeral 临时会话不入库，Id 为 0
+func isEphemeral(session *entity.ChatSessions) bool {
+	return session.Id == 0
+}
+
+// createEphemeralSession 创建临时会话，会话已存在时返回 false
+func createEphemeralSession(ctx context.Context, session entity.ChatSessions) (bool, error) {
+	b, err := json.Marshal(session)
+	if err != nil {
+		return false, err
+	}
+	key := ephemeralKey(session.Uuid)
+	n, err := g.Redis().HSetNX(ctx, key, ephemeralSession, string(b))
+	if err != nil {
+		g.Log().Errorf(ctx, "创建临时会话失败: session=%s, 错误: %v", session.Uuid, err)
+		return false, fmt.Errorf("创建临时会话失败: %w", err)
+	}
+	if n == 0 {
+		return false, nil
+	}
+	return true, touchEphemeral(ctx, session.Uuid)
+}
+
+// loadEphemeralSession 读取临时会话，不存在时返回 nil
+func loadEphemeralSession(ctx context.Context, sessionId string) (*entity.ChatSessions, error) {
+	vars, err := g.Redis().HMGet(ctx, ephemeralKey(sessionId), ephemeralSession, ephemeralActive, ephemeralSummary, ephemeralSummaryMsgId)
+	if err != nil {
+		g.Log().Errorf(ctx, "查询临时会话失败: session=%s, 错误: %v", sessionId, err)
+		return nil, fmt.Errorf("查询临时会话失败: %w", err)
+	}
+	if len(vars) == 0 || vars[0].IsNil() {
+		return nil, nil
+	}
+	var session entity.ChatSessions
+	if err = json.Unmarshal(vars[0].Bytes(), &session); err != nil {
+		return nil, fmt.Errorf("解析临时会话失败: %w", err)
+	}
+	session.Id = 0
+	session.ActiveMsgId = vars[1].String()
+	session.Summary = vars[2].String()
+	session.SummaryMsgId = vars[3].String()
+	return &session, nil
+}
+
+// ephemeralExists 临时会话是否仍在有效期内
+func ephemeralExists(ctx context.Context, sessionId string) (bool, error) {
+	n, err := g.Redis().Exists(ctx, ephemeralKey(sessionId))
+	return n > 0, err
+}
+
+// updateEphemeral 更新临时会话的字段并刷新过期时间
+func updateEphemeral(ctx context.Context, sessionId string, fields map[string]any) error {
+	if _, err := g.Redis().HSet(ctx, ephemeralKey(sessionId), fields); err != nil {
+		return err
+	}
+	return touchEphemeral(ctx, sessionId)
+}
+
+func touchEphemeral(ctx context.Context, sessionId string) error {
+	_, err := g.Redis().Expire(ctx, ephemeralKey(sessionId), int64(ephemeralTTL(ctx).Seconds()))
+	return err
+}
+
+// setEphemeralTitle 修改临时会话标题
+func setEphemeralTitle(ctx context.Context, session *entity.ChatSessions, title string) error {
+	s := *session
+	s.Title = title
+	s.UpdatedAt = gtime.Now()
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return updateEphemeral(ctx, s.Uuid, map[string]any{ephemeralSession: string(b)})
+}
+
+// ephemeralMessages 临时会话的全部消息（按 id 升序），withReasoning 为 false 时不返回思考过程
+func ephemeralMessages(ctx context.Context, sessionId string, withReasoning bool) ([]*entity.ChatMessages, error) {
+	v, err := g.Redis().HGetAll(ctx, ephemeralKey(sessionId))
+	if err != nil {
+		g.Log().Errorf(ctx, "获取临时会话消息失败: session=%s, 错误: %v", sessionId, err)
+		return nil, fmt.Errorf("获取临时会话消息失败: %w", err)
+	}
+	var rows []*entity.ChatMessages
+	for field, raw := range v.MapStrStr() {
+		if !strings.HasPrefix(field, ephemeralMsgPrefix) {
+			continue
+		}
+		var m entity.ChatMessages
+		if err := json.Unmarshal([]byte(raw), &m); err != nil {
+			g.Log().Warningf(ctx, "解析临时会话消息失败: session=%s, field=%s, 错误: %v", sessionId, field, err)
+			continue
+		}
+		if !withReasoning {
+			m.ReasoningContent = ""
+		}
+		rows = append(rows, &m)
+	}
+	sort.Slice(rows, func(i, j int) bool { return rows[i].Id < rows[j].Id })
+	return rows, nil
+}
+
+// findEphemeralMessage 查询临时会话内的消息，不存在时返回 nil
+func findEphemeralMessage(ctx context.Context, sessionId, msgId string) (*entity.ChatMessages, error) {
+	v, err := g.Redis().HGet(ctx, ephemeralKey(sessionId), ephemeralMsgPrefix+msgId)
+	if err != nil {
+		g.Log().Errorf(ctx, "查询临时会话消息失败: session=%s, msg=%s, 错误: %v", sessionId, msgId, err)
+		return nil, fmt.Errorf("查询消息失败: %w", err)
+	}
+	if v.IsNil() {
+		return nil, nil
+	}
+	var m entity.ChatMessages
+	if err = json.Unmarshal(v.Bytes(), &m); err != nil {
+		return nil, fmt.Errorf("解析临时会话消息失败: %w", err)
+	}
+	m.ReasoningContent = ""
+	return &m, nil
+}
+
+// addEphemeralMessages 按顺序写入临时会话消息并分配自增 ID，同时更新 fields 中的会话字段
+func addEphemeralMessages(ctx context.Context, sessionId string, msgs []*entity.ChatMessages, fields map[string]any) error {
+	key := ephemeralKey(sessionId)
+	if fields == nil {
+		fields = make(map[string]any, len(msgs))
+	}
+	for _, m := range msgs {
+		id, err := g.Redis().HIncrBy(ctx, key, ephemeralSeq, 1)
+		if err != nil {
+			return err
+		}
+		m.Id = id
+		b, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		fields[ephemeralMsgPrefix+m.MsgId] = string(b)
+	}
+	return updateEphemeral(ctx, sessionId, fields)
+}
+
+// deleteEphemeralSession 删除临时会话及其消息
+func deleteEphemeralSession(ctx context.Context, sessionId string) error {
+	_, err := g.Redis().Del(ctx, ephemeralKey(sessionId))
+	return err
+}
+
+// saveEphemeralUserMessage 把本轮用户消息写入临时会话，并把当前分支指向它
+func (c *ChatBase) saveEphemeralUserMessage(ctx context.Context, turn *Turn) error {
+	if err := checkEphemeral(ctx, turn.SessionId); err != nil {
+		return err
+	}
+	userMsg := &entity.ChatMessages{
+		SessionUuid: turn.SessionId,
+		MsgId:       turn.UserMsgId,
+		ParentMsgId: turn.ParentMsgId,
+		Content:     turnQuestion(turn),
+		IsUser:      1,
+		Timestamp:   gtime.Now(),
+	}
+	if parts := storedParts(turn.MultiContent); len(parts) > 0 {
+		multiJSON, _ := json.Marshal(parts)
+		userMsg.MultiContent = string(multiJSON)
+	}
+	return addEphemeralMessages(ctx, turn.SessionId, []*entity.ChatMessages{userMsg}, map[string]any{ephemeralActive: turn.UserMsgId})
+}
+
+// saveEphemeralTurn 把一轮对话的回复写入临时会话，并把当前分支指向新回复
+func (c *ChatBase) saveEphemeralTurn(ctx context.Context, turn *Turn, reply *schema.Message, citations []common.Citation) error {
+	if err := checkEphemeral(ctx, turn.SessionId); err != nil {
+		return err
+	}
+	replyMsg := &entity.ChatMessages{
+		SessionUuid:      turn.SessionId,
+		MsgId:            turn.ReplyMsgId,
+		ParentMsgId:      turn.UserMsgId,
+		Content:          reply.Content,
+		Timestamp:        gtime.Now(),
+		ReasoningContent: reply.ReasoningContent,
+	}
+	if len(citations) > 0 {
+		citationsJSON, _ := json.Marshal(citations)
+		replyMsg.Citations = string(citationsJSON)
+	}
+	if err := addEphemeralMessages(ctx, turn.SessionId, []*entity.ChatMessages{replyMsg}, map[string]any{ephemeralActive: turn.ReplyMsgId}); err != nil {
+		g.Log().Errorf(ctx, "保存临时会话对话失败: session=%s, 错误: %v", turn.SessionId, err)
+		return fmt.Errorf("保存对话失败: %w", err)
+	}
+	return nil
+}
+
+// checkEphemeral 会话已过期时不再写入，避免留下没有所有者的消息
+func checkEphemeral(ctx context.Context, sessionId string) error {
+	if ok, err := ephemeralExists(ctx, sessionId); err != nil || !ok {
+		g.Log().Warningf(ctx, "临时会话已过期，不保存对话: session=%s, 错误: %v", sessionId, err)
+		return fmt.Errorf("临时会话已过期: %s", sessionId)
+	}
+	return nil
+}
@@ -4,19 +4,21 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"strings"
 
 	v1 "backend/api/ai_chat/v1"
 	"backend/internal/dao"
 	"backend/internal/model/entity"
+	"backend/studyCoach/aiModel/eino_tools/filesystem"
 
 	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/os/gtime"
 )
 
-// SaveSession 保存会话
-func (c *ChatBase) SaveSession(ctx context.Context, userId string, req *v1.SaveSessionReq) (string, error) {
+// SaveSession 保存会话：创建或更新标题，会话属于其他用户时返回 403；消息通常由 SaveTurn 在流结束时写入
+func (c *ChatBase) SaveSession(ctx context.Context, owner Owner, req *v1.SaveSessionReq) (string, error) {
 	// Use req.Id as session UUID
 	sessionUuid := req.Id
 	if sessionUuid == "" {
@@ -24,58 +26,42 @@ func (c *ChatBase) SaveSession(ctx context.Context, userId string, req *v1.SaveS
 		sessionUuid = fmt.Sprintf("%d", gtime.TimestampMilli())
 	}
 
-	err := dao.ChatSessions.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
-		now := gtime.Now()
-
-		// 1. Try Update first (Optimistic for existing sessions)
-		updateRes, err := dao.ChatSessions.Ctx(ctx).TX(tx).
-			Data(g.Map{
-				dao.ChatSessions.Columns().Title:     req.Title,
-				dao.ChatSessions.Columns().UpdatedAt: now,
-			}).
-			Where(dao.ChatSessions.Columns().Uuid, sessionUuid).
-			Update()
-
-		if err != nil {
-			return err
+	// 临时会话只能修改标题，消息不写入服务端
+	if session, err := c.findSession(ctx, sessionUuid); err != nil {
+		return "", err
+	} else if session != nil && isEphemeral(session) {
+		if !owner.owns(session.UserId, session.AnonymousId) {
+			return "", gerror.NewCode(gcode.New(403, "无权访问该会话", nil))
 		}
-
-		rowsAffected, _ := updateRes.RowsAffected()
-
-		if rowsAffected == 0 {
-			// 2. If Update failed (not found), Try Insert
-			sessionData := entity.ChatSessions{
-				Uuid:      sessionUuid,
-				UserId:    userId,
-				Title:     req.Title,
-				CreatedAt: now,
-				UpdatedAt: now,
+		if len(req.Messages) > 0 {
+			return "", gerror.NewCode(gcode.New(400, "临时会话的消息不写入服务端", nil))
+		}
+		if req.Title != "" {
+			if err = setEphemeralTitle(ctx, session, req.Title); err != nil {
+				return "", fmt.Errorf("保存会话失败: %w", err)
 			}
-			// Note: Id is auto-increment, not set here.
+		}
+		return sessionUuid, nil
+	}
 
-			_, err = dao.ChatSessions.Ctx(ctx).TX(tx).Data(sessionData).Insert()
+	err := dao.ChatSessions.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		if err := c.EnsureSession(ctx, owner, sessionUuid, req.Title, true); err != nil {
+			return err
+		}
+		if req.Title != "" {
+			_, err := dao.ChatSessions.Ctx(ctx).TX(tx).
+				Data(g.Map{
+					dao.ChatSessions.Columns().Title:     req.Title,
+					dao.ChatSessions.Columns().UpdatedAt: gtime.Now(),
+				}).
+				Where(dao.ChatSessions.Columns().Uuid, sessionUuid).
+				Update()
 			if err != nil {
-				// Check if it is a duplicate entry error (Race condition: inserted by another thread)
-				if strings.Contains(err.Error(), "Duplicate entry") {
-					// 3. If Insert failed (duplicate), Try Update again
-					_, updateErr := dao.ChatSessions.Ctx(ctx).TX(tx).
-						Data(g.Map{
-							dao.ChatSessions.Columns().Title:     req.Title,
-							dao.ChatSessions.Columns().UpdatedAt: now,
-						}).
-						Where(dao.ChatSessions.Columns().Uuid, sessionUuid).
-						Update()
-					if updateErr != nil {
-						return updateErr
-					}
-				} else {
-					// Other errors
-					return err
-				}
+				return err
 			}
 		}
 
-		// 2. Save Messages
+		// 保存前端提交的消息（登录合并旧版本地会话时使用），按 msg_id 更新或插入
 		if len(req.Messages) > 0 {
 			// Get existing messages map for this session to handle updates correctly
 			var existingMsgs []entity.ChatMessages
@@ -134,9 +120,9 @@ func (c *ChatBase) SaveSession(ctx context.Context, userId string, req *v1.SaveS
 }
 
 // GetHistory 获取历史会话（分页）
-func (c *ChatBase) GetHistory(ctx context.Context, userId string, page, pageSize int) ([]v1.ChatSession, int, error) {
+func (c *ChatBase) GetHistory(ctx context.Context, owner Owner, page, pageSize int) ([]v1.ChatSession, int, error) {
 	// 先查总数
-	total, err := dao.ChatSessions.Ctx(ctx).Where(dao.ChatSessions.Columns().UserId, userId).Count()
+	total, err := owner.where(dao.ChatSessions.Ctx(ctx)).Count()
 	if err != nil {
 		return nil, 0, err
 	}
@@ -144,8 +130,7 @@ func (c *ChatBase) GetHistory(ctx context.Context, userId string, page, pageSize
 	// 分页查询
 	var sessions []entity.ChatSessions
 	offset := (page - 1) * pageSize
-	err = dao.ChatSessions.Ctx(ctx).
-		Where(dao.ChatSessions.Columns().UserId, userId).
+	err = owner.where(dao.ChatSessions.Ctx(ctx)).
 		OrderDesc(dao.ChatSessions.Columns().UpdatedAt).
 		Limit(pageSize).
 		Offset(offset).
@@ -167,52 +152,61 @@ func (c *ChatBase) GetHistory(ctx context.Context, userId string, page, pageSize
 }
 
 // GetSession 获取单个会话详情（支持滚动加载）
-func (c *ChatBase) GetSession(ctx context.Context, userId string, sessionId string, beforeMsgId int64, limit int) (*v1.GetSessionRes, error) {
+func (c *ChatBase) GetSession(ctx context.Context, owner Owner, sessionId string, beforeMsgId int64, limit int) (*v1.GetSessionRes, error) {
 	var session entity.ChatSessions
 	// Query by UUID
-	err := dao.ChatSessions.Ctx(ctx).
+	err := owner.where(dao.ChatSessions.Ctx(ctx)).
 		Where(dao.ChatSessions.Columns().Uuid, sessionId).
-		Where(dao.ChatSessions.Columns().UserId, userId).
 		Scan(&session)
 	if err != nil {
 		return nil, err
 	}
 	if session.Id == 0 { // Check if found (Id should be > 0)
-		return nil, fmt.Errorf("session not found")
+		// 数据库中没有时查询临时会话
+		ephemeral, err := loadEphemeralSession(ctx, sessionId)
+		if err != nil {
+			return nil, err
+		}
+		if ephemeral == nil || !owner.owns(ephemeral.UserId, ephemeral.AnonymousId) {
+			return nil, fmt.Errorf("session not found")
+		}
+		session = *ephemeral
 	}
 
-	var messages []entity.ChatMessages
-	query := dao.ChatMessages.Ctx(ctx).
-		Where(dao.ChatMessages.Columns().SessionUuid, sessionId)
-
-	// 滚动加载条件：返回早于beforeMsgId的消息
-	if beforeMsgId > 0 {
-		query = query.WhereLT(dao.ChatMessages.Columns().Id, beforeMsgId)
+	// 只返回当前分支，其他版本通过 siblings 列出
+	leaf, err := c.activeLeaf(ctx, &session)
+	if err != nil {
+		return nil, err
 	}
-
-	// 按ID倒序取最新的limit条，再反转成正序，保持时间升序排列
-	err = query.
-		OrderDesc(dao.ChatMessages.Columns().Id).
-		Limit(limit).
-		Scan(&messages)
+	tree, err := c.loadTree(ctx, &session, true)
 	if err != nil {
 		return nil, err
 	}
+	path := tree.path(leaf)
 
-	// 反转切片，恢复时间升序
-	for i, j := 0, len(messages)-1; i < j; i, j = i+1, j-1 {
-		messages[i], messages[j] = messages[j], messages[i]
+	// 滚动加载条件：返回早于beforeMsgId的消息
+	if beforeMsgId > 0 {
+		end := 0
+		for end < len(path) && path[end].Id < beforeMsgId {
+			end++
+		}
+		path = path[:end]
+	}
+	// 取最新的limit条，保持时间升序排列
+	if len(path) > limit {
+		path = path[len(path)-limit:]
 	}
 
 	res := &v1.GetSessionRes{
-		Id:        session.Uuid, // Return UUID
-		Title:     session.Title,
-		CreatedAt: session.CreatedAt,
-		UpdatedAt: session.UpdatedAt,
-		Messages:  make([]v1.ChatMessage, 0, len(messages)),
+		Id:          session.Uuid, // Return UUID
+		Title:       session.Title,
+		ActiveMsgId: leaf,
+		CreatedAt:   session.CreatedAt,
+		UpdatedAt:   session.UpdatedAt,
+		Messages:    make([]v1.ChatMessage, 0, len(path)),
 	}
 
-	for _, m := range messages {
+	for _, m := range path {
 		isUser := false
 		if m.IsUser == 1 {
 			isUser = true
@@ -220,11 +214,15 @@ func (c *ChatBase) GetSession(ctx context.Context, userId string, sessionId stri
 		chatMsg := v1.ChatMessage{
 			Id:               m.Id,
 			MsgId:            m.MsgId,
+			ParentMsgId:      m.ParentMsgId,
 			Content:          m.Content,
 			IsUser:           isUser,
 			Timestamp:        m.Timestamp,
 			ReasoningContent: m.ReasoningContent,
 		}
+		if siblings := tree.siblings(m); len(siblings) > 1 {
+			chatMsg.Siblings = siblings
+		}
 		// 解析多模态内容
 		if m.MultiContent != "" {
 			var multiContent []v1.MessagePart
@@ -234,20 +232,109 @@ func (c *ChatBase) GetSession(ctx context.Context, userId string, sessionId stri
 				g.Log().Warningf(ctx, "failed to unmarshal multi_content for msg %s: %v, raw: %s", m.MsgId, err, m.MultiContent)
 			}
 		}
+		if m.Citations != "" {
+			if err := json.Unmarshal([]byte(m.Citations), &chatMsg.Citations); err != nil {
+				g.Log().Warningf(ctx, "failed to unmarshal citations for msg %s: %v", m.MsgId, err)
+			}
+		}
 		res.Messages = append(res.Messages, chatMsg)
 	}
 
 	return res, nil
 }
 
-// DeleteSession 删除会话
-func (c *ChatBase) DeleteSession(ctx context.Context, userId string, sessionId string) error {
-	// Delete by UUID
+// DeleteSession 删除会话及其消息（临时会话从 Redis 删除），并删除会话工作目录
+func (c *ChatBase) DeleteSession(ctx context.Context, owner Owner, sessionId string) error {
+	deleted := false
+	err := dao.ChatSessions.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		// Delete by UUID
+		res, err := owner.where(dao.ChatSessions.Ctx(ctx)).
+			Where(dao.ChatSessions.Columns().Uuid, sessionId).
+			Delete()
+		if err != nil {
+			return err
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil
+		}
+		deleted = true
+		// 会话消息同时作为模型上下文，随会话一并删除
+		_, err = dao.ChatMessages.Ctx(ctx).
+			Where(dao.ChatMessages.Columns().SessionUuid, sessionId).
+			Delete()
+		return err
+	})
+	if err == nil && !deleted {
+		deleted, err = c.deleteEphemeral(ctx, owner, sessionId)
+	}
+	if err != nil || !deleted {
+		return err
+	}
+	// 工作目录删除失败只记录日志，遗留目录由定期清理任务回收
+	if err = filesystem.RemoveWorkDir(ctx, sessionId); err != nil {
+		g.Log().Warningf(ctx, "删除会话工作目录失败: session=%s, 错误: %v", sessionId, err)
+	}
+	return nil
+}
+
+// deleteEphemeral 删除属于 owner 的临时会话，会话不是临时会话时返回 false
+func (c *ChatBase) deleteEphemeral(ctx context.Context, owner Owner, sessionId string) (bool, error) {
+	session, err := loadEphemeralSession(ctx, sessionId)
+	if err != nil || session == nil || !owner.owns(session.UserId, session.AnonymousId) {
+		return false, err
+	}
+	if err = deleteEphemeralSession(ctx, sessionId); err != nil {
+		g.Log().Errorf(ctx, "删除临时会话失败: session=%s, 错误: %v", sessionId, err)
+		return false, fmt.Errorf("删除临时会话失败: %w", err)
+	}
+	return true, nil
+}
+
+// pruneBatch 单次清理的会话数量上限
+const pruneBatch = 100
+
+// PruneSessions 只保留 owner 最近更新的 keep 个会话，删除更早的会话及其消息；current 不会被删除
+func (c *ChatBase) PruneSessions(ctx context.Context, owner Owner, keep int, current string) (int, error) {
+	if keep <= 0 {
+		return 0, nil
+	}
+	// current 计入保留数量
+	stale, err := owner.where(dao.ChatSessions.Ctx(ctx)).
+		WhereNot(dao.ChatSessions.Columns().Uuid, current).
+		OrderDesc(dao.ChatSessions.Columns().UpdatedAt).
+		Limit(keep-1, pruneBatch).
+		Fields(dao.ChatSessions.Columns().Uuid).
+		Array()
+	if err != nil {
+		return 0, fmt.Errorf("查询待清理会话失败: %w", err)
+	}
+	for i, v := range stale {
+		sessionId := v.String()
+		if err = c.DeleteSession(ctx, owner, sessionId); err != nil {
+			return i, fmt.Errorf("清理会话失败: session=%s, 错误: %w", sessionId, err)
+		}
+	}
+	return len(stale), nil
+}
+
+// ClaimAnonymousSessions 登录时将匿名令牌创建的会话转移给用户
+func (c *ChatBase) ClaimAnonymousSessions(ctx context.Context, anonymousId, userId string) error {
+	if anonymousId == "" {
+		return nil
+	}
 	_, err := dao.ChatSessions.Ctx(ctx).
-		Where(dao.ChatSessions.Columns().Uuid, sessionId).
-		Where(dao.ChatSessions.Columns().UserId, userId).
-		Delete()
-	return err
+		Data(g.Map{
+			dao.ChatSessions.Columns().UserId:      userId,
+			dao.ChatSessions.Columns().AnonymousId: "",
+		}).
+		Where(dao.ChatSessions.Columns().AnonymousId, anonymousId).
+		Where(dao.ChatSessions.Columns().UserId, "").
+		Update()
+	if err != nil {
+		g.Log().Errorf(ctx, "转移匿名会话失败: user=%s, 错误: %v", userId, err)
+		return fmt.Errorf("转移匿名会话失败: %w", err)
+	}
+	return nil
 }
 
 // MergeSessionInput 合并会话的输入（登录时传入的未登录会话）
@@ -266,12 +353,17 @@ type MergeMessageInput struct {
 	ReasoningContent string
 }
 
-// MergeAnonymousSessions 将未登录时的会话合并到用户历史（登录后由后端调用）
+// MergeAnonymousSessions 将仅保存在前端的旧版未登录会话合并到用户历史（登录后由后端调用）；
+// 服务端已存在的会话由 ClaimAnonymousSessions 按匿名令牌转移，这里跳过
 func (c *ChatBase) MergeAnonymousSessions(ctx context.Context, userId string, sessions []MergeSessionInput) error {
+	owner := Owner{UserId: userId}
 	for _, s := range sessions {
 		if s.Id == "" {
 			continue
 		}
+		if existing, err := c.findSession(ctx, s.Id); err != nil || existing != nil {
+			continue
+		}
 		req := &v1.SaveSessionReq{
 			Id:       s.Id,
 			Title:    s.Title,
@@ -286,7 +378,7 @@ func (c *ChatBase) MergeAnonymousSessions(ctx context.Context, userId string, se
 				ReasoningContent: m.ReasoningContent,
 			})
 		}
-		_, err := c.SaveSession(ctx, userId, req)
+		_, err := c.SaveSession(ctx, owner, req)
 		if err != nil {
 			g.Log().Warningf(ctx, "merge anonymous session %s failed: %v", s.Id, err)
 			// 继续处理其他会话，不因单个失败而中断
@@ -0,0 +1,235 @@
+package ai_chat
+
+import (
+	"backend/internal/dao"
+	"backend/internal/model/entity"
+	"backend/studyCoach/aiModel/CoachChat"
+	"backend/studyCoach/common"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+const (
+	defaultHistoryTokens = 6000  // 最近消息窗口的 token 预算
+	defaultSummaryTokens = 800   // 滚动摘要的长度上限
+	imageTokens          = 800   // 单张图片按固定 token 计入预算
+	summaryInputRunes    = 2000  // 生成摘要时单条消息截取的最大长度
+	summaryInputTokens   = 16000 // 单次合并的新增对话上限，其余留到下一次
+	summaryTimeout       = 2 * time.Minute
+)
+
+// Memory 注入模型上下文的对话记忆：较早对话的滚动摘要 + 预算内的最近消息（时间升序）
+type Memory struct {
+	Summary string
+	History []*schema.Message
+}
+
+// memoryBudget 对话记忆预算，按生成回答的模型配置（chat.memory.models），未配置时使用默认值
+type memoryBudget struct {
+	Model         string `json:"model"`
+	HistoryTokens int    `json:"historyTokens"`
+	SummaryTokens int    `json:"summaryTokens"`
+}
+
+// summarizing 正在生成摘要的会话，同一会话同时只运行一个摘要任务
+var summarizing sync.Map
+
+func budgetFor(ctx context.Context, modelName string) memoryBudget {
+	budget := memoryBudget{
+		Model:         modelName,
+		HistoryTokens: g.Cfg().MustGet(ctx, "chat.memory.historyTokens", defaultHistoryTokens).Int(),
+		SummaryTokens: g.Cfg().MustGet(ctx, "chat.memory.summaryTokens", defaultSummaryTokens).Int(),
+	}
+	var models []memoryBudget
+	if err := g.Cfg().MustGet(ctx, "chat.memory.models").Scan(&models); err != nil {
+		g.Log().Warningf(ctx, "chat.memory.models 配置格式错误: %v", err)
+	}
+	for _, m := range models {
+		if m.Model != modelName {
+			continue
+		}
+		if m.HistoryTokens > 0 {
+			budget.HistoryTokens = m.HistoryTokens
+		}
+		if m.SummaryTokens > 0 {
+			budget.SummaryTokens = m.SummaryTokens
+		}
+	}
+	return budget
+}
+
+func messageTokens(msg *schema.Message) int {
+	n := common.EstimateTokens(msg.Content)
+	for _, p := range msg.UserInputMultiContent {
+		if p.Image != nil {
+			n += imageTokens
+		} else {
+			n += common.EstimateTokens(p.Text)
+		}
+	}
+	return n
+}
+
+// TurnMemory 轮次的对话记忆：用户消息之前的分支
+func (c *ChatBase) TurnMemory(ctx context.Context, turn *Turn, modelName string) (*Memory, error) {
+	if turn.ParentMsgId == "" {
+		return &Memory{}, nil
+	}
+	return c.LoadMemory(ctx, turn.SessionId, turn.ParentMsgId, modelName)
+}
+
+// LoadMemory 读取 leafMsgId 所在分支的对话记忆，leafMsgId 为空时取当前分支。
+// 从最新消息向前取到预算用尽；摘要只在其覆盖的消息位于该分支上时使用。
+// 窗口之外尚未进入摘要的消息在后台合并进摘要，摘要写回之前仍保留在上下文中
+func (c *ChatBase) LoadMemory(ctx context.Context, sessionId, leafMsgId, modelName string) (*Memory, error) {
+	session, err := c.findSession(ctx, sessionId)
+	if err != nil || session == nil {
+		return &Memory{}, err
+	}
+	if leafMsgId == "" {
+		if leafMsgId, err = c.activeLeaf(ctx, session); err != nil {
+			return nil, err
+		}
+	}
+	tree, err := c.loadTree(ctx, session, false)
+	if err != nil {
+		return nil, err
+	}
+	path := tree.path(leafMsgId)
+	budget := budgetFor(ctx, modelName)
+
+	mem := &Memory{}
+	start := 0 // 摘要未覆盖的第一条消息
+	for i, m := range path {
+		if session.SummaryMsgId != "" && m.MsgId == session.SummaryMsgId {
+			mem.Summary, start = session.Summary, i+1
+			break
+		}
+	}
+	// 预算内的最近消息为窗口，path[start:cut] 为窗口之外尚未进入摘要的消息
+	cut, used, kept := len(path), 0, 0
+	for cut > start {
+		msg := historyMessage(ctx, *path[cut-1])
+		if msg == nil {
+			cut--
+			continue
+		}
+		n := messageTokens(msg)
+		if used+n > budget.HistoryTokens && kept > 0 {
+			break
+		}
+		used += n
+		kept++
+		cut--
+	}
+	// 窗口之外的消息在覆盖它们的摘要写回之前仍放入上下文，摘要生成期间不丢失
+	for _, m := range path[start:] {
+		if msg := historyMessage(ctx, *m); msg != nil {
+			mem.History = append(mem.History, msg)
+		}
+	}
+	if cut > start {
+		g.Log().Infof(ctx, "[Memory] 超出预算的消息待合并进摘要: session=%s, 条数=%d, 窗口=%d/%d tokens",
+			sessionId, cut-start, used, budget.HistoryTokens)
+		c.refreshSummary(ctx, session, mem.Summary, path[start:cut], budget)
+	}
+	return mem, nil
+}
+
+// refreshSummary 在后台把 overflow 合并进摘要并写回会话
+func (c *ChatBase) refreshSummary(ctx context.Context, session *entity.ChatSessions, summary string, overflow []*entity.ChatMessages, budget memoryBudget) {
+	sessionId := session.Uuid
+	if _, running := summarizing.LoadOrStore(sessionId, true); running {
+		return
+	}
+	go func() {
+		defer summarizing.Delete(sessionId)
+		ctx, cancel := context.WithTimeout(gctx.NeverDone(ctx), summaryTimeout)
+		defer cancel()
+		newSummary, covered, err := summarize(ctx, summary, overflow, budget.SummaryTokens)
+		if err != nil {
+			g.Log().Warningf(ctx, "[Memory] 生成对话摘要失败: session=%s, 错误: %v", sessionId, err)
+			return
+		}
+		if isEphemeral(session) {
+			err = updateEphemeral(ctx, sessionId, map[string]any{
+				ephemeralSummary:      newSummary,
+				ephemeralSummaryMsgId: overflow[covered-1].MsgId,
+			})
+		} else {
+			_, err = dao.ChatSessions.Ctx(ctx).
+				Where(dao.ChatSessions.Columns().Uuid, sessionId).
+				Data(g.Map{
+					dao.ChatSessions.Columns().Summary:      newSummary,
+					dao.ChatSessions.Columns().SummaryMsgId: overflow[covered-1].MsgId,
+				}).
+				Update()
+		}
+		if err != nil {
+			g.Log().Errorf(ctx, "[Memory] 保存对话摘要失败: session=%s, 错误: %v", sessionId, err)
+			return
+		}
+		g.Log().Infof(ctx, "[Memory] 对话摘要已更新: session=%s, 合并消息=%d", sessionId, covered)
+	}()
+}
+
+// summarize 调用模型把已有摘要与新增对话合并为新的摘要，返回摘要及合并的消息条数
+func summarize(ctx context.Context, summary string, overflow []*entity.ChatMessages, maxTokens int) (string, int, error) {
+	cm, err := CoachChat.RewriteModel(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	var sb strings.Builder
+	if summary != "" {
+		sb.WriteString("已有摘要：\n")
+		sb.WriteString(summary)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("新增对话：\n")
+	covered, used := 0, 0
+	for _, m := range overflow {
+		text := m.Content
+		if m.Citations != "" {
+			text = common.StripCitations(text)
+		}
+		content := []rune(strings.TrimSpace(text))
+		if len(content) > summaryInputRunes {
+			content = append(content[:summaryInputRunes], []rune("……")...)
+		}
+		if used += common.EstimateTokens(string(content)); used > summaryInputTokens && covered > 0 {
+			break
+		}
+		covered++
+		if len(content) == 0 {
+			continue
+		}
+		role := "助手"
+		if m.IsUser == 1 {
+			role = "用户"
+		}
+		fmt.Fprintf(&sb, "%s：%s\n", role, string(content))
+	}
+	system := fmt.Sprintf("你是对话摘要助手。把已有摘要与新增对话合并为一份新的摘要，供后续对话参考。"+
+		"保留用户的学习目标与背景、讨论过的知识点及结论、用户的薄弱点、双方约定的事项和未解决的问题；"+
+		"省略寒暄与重复内容，不要编造对话中没有的信息。使用第三人称陈述，不超过 %d 字，直接输出摘要正文。", maxTokens)
+	reply, err := cm.Generate(common.WithCallName(ctx, "MemorySummary", components.ComponentOfChatModel), []*schema.Message{
+		schema.SystemMessage(system),
+		schema.UserMessage(sb.String()),
+	})
+	if err != nil {
+		return "", 0, err
+	}
+	newSummary := strings.TrimSpace(reply.Content)
+	if newSummary == "" {
+		return "", 0, fmt.Errorf("模型返回的摘要为空")
+	}
+	return newSummary, covered, nil
+}
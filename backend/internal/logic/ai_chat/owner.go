@@ -0,0 +1,71 @@
+package ai_chat
+
+import (
+	"backend/internal/dao"
+	"backend/utility"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// AnonymousTokenHeader 未登录客户端的匿名令牌，由前端生成并持久化
+const AnonymousTokenHeader = "X-Anonymous-Token"
+
+// minAnonymousTokenLen 匿名令牌最小长度，避免可猜测的短令牌
+const minAnonymousTokenLen = 16
+
+// Owner 会话所有者：已登录用户（UserId）或匿名令牌（AnonymousId，令牌的 SHA-256），二者只取其一
+type Owner struct {
+	UserId      string
+	AnonymousId string
+}
+
+// CurrentOwner 解析当前请求的会话所有者：携带 Authorization 时必须是有效登录态，否则使用匿名令牌
+func CurrentOwner(ctx context.Context) (Owner, error) {
+	if utility.GetJWT(ctx) != "" {
+		claims, err := utility.JWTMap(ctx)
+		if err != nil {
+			return Owner{}, err
+		}
+		return Owner{UserId: gconv.String(claims["Username"])}, nil
+	}
+	anonymousId := AnonymousId(g.RequestFromCtx(ctx).GetHeader(AnonymousTokenHeader))
+	if anonymousId == "" {
+		return Owner{}, gerror.NewCode(gcode.New(401, "缺少登录信息或匿名令牌", nil))
+	}
+	return Owner{AnonymousId: anonymousId}, nil
+}
+
+// AnonymousId 匿名令牌只保存哈希，令牌为空或过短时返回空串
+func AnonymousId(token string) string {
+	token = strings.TrimSpace(token)
+	if len(token) < minAnonymousTokenLen {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// where 按所有者过滤会话
+func (o Owner) where(m *gdb.Model) *gdb.Model {
+	if o.UserId != "" {
+		return m.Where(dao.ChatSessions.Columns().UserId, o.UserId)
+	}
+	return m.Where(dao.ChatSessions.Columns().AnonymousId, o.AnonymousId).
+		Where(dao.ChatSessions.Columns().UserId, "")
+}
+
+// owns 判断会话是否属于该所有者
+func (o Owner) owns(userId, anonymousId string) bool {
+	if o.UserId != "" {
+		return userId == o.UserId
+	}
+	return userId == "" && anonymousId == o.AnonymousId
+}
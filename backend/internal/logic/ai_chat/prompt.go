@@ -34,7 +34,7 @@ func newChatTemplate(template prompt.ChatTemplate, data map[string]any) ([]*sche
 	return msg, nil
 }
 func (c *ChatBase) docsMessage(ctx context.Context, id string, que string) (messages []*schema.Message, err error) {
-	history, err := c.eh.GetHistory(id, 30)
+	history, err := c.LoadHistory(ctx, id, "", 30)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,73 @@
+package ai_chat
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+// defaultTurnTimeout 单轮生成的最长时间，超时自动取消
+const defaultTurnTimeout = 10 * time.Minute
+
+// turnRegistry 进行中的轮次：会话 ID -> 轮次 ID -> 取消函数。
+// 生成与 HTTP 请求解耦，客户端断开不会中止生成，需通过 CancelTurn 主动停止
+var turnRegistry = struct {
+	sync.Mutex
+	running map[string]map[string]context.CancelFunc
+}{running: make(map[string]map[string]context.CancelFunc)}
+
+type turnCtxKey struct{}
+
+// StartTurn 登记轮次并返回其生成上下文（保留请求上下文中的值，但不随请求取消）。
+// 生成结束后必须调用 FinishTurn
+func (c *ChatBase) StartTurn(ctx context.Context, turn *Turn) context.Context {
+	timeout := g.Cfg().MustGet(ctx, "chat.turnTimeout", defaultTurnTimeout).Duration()
+	turnCtx, cancel := context.WithTimeout(gctx.NeverDone(ctx), timeout)
+	turnRegistry.Lock()
+	if turnRegistry.running[turn.SessionId] == nil {
+		turnRegistry.running[turn.SessionId] = make(map[string]context.CancelFunc)
+	}
+	turnRegistry.running[turn.SessionId][turn.ReplyMsgId] = cancel
+	turnRegistry.Unlock()
+	turn.cancel = cancel
+	return context.WithValue(turnCtx, turnCtxKey{}, turn)
+}
+
+// FinishTurn 注销轮次并释放其上下文
+func (c *ChatBase) FinishTurn(turn *Turn) {
+	turnRegistry.Lock()
+	if turns := turnRegistry.running[turn.SessionId]; turns != nil {
+		delete(turns, turn.ReplyMsgId)
+		if len(turns) == 0 {
+			delete(turnRegistry.running, turn.SessionId)
+		}
+	}
+	turnRegistry.Unlock()
+	if turn.cancel != nil {
+		turn.cancel()
+	}
+}
+
+// CancelTurn 取消会话中进行中的轮次，turnId 为空时取消该会话全部轮次，返回取消的数量
+func (c *ChatBase) CancelTurn(sessionId, turnId string) int {
+	turnRegistry.Lock()
+	defer turnRegistry.Unlock()
+	turns := turnRegistry.running[sessionId]
+	n := 0
+	for id, cancel := range turns {
+		if turnId == "" || id == turnId {
+			cancel()
+			n++
+		}
+	}
+	return n
+}
+
+// TurnFromContext 获取 StartTurn 登记的轮次
+func TurnFromContext(ctx context.Context) *Turn {
+	turn, _ := ctx.Value(turnCtxKey{}).(*Turn)
+	return turn
+}
@@ -0,0 +1,114 @@
+package ai_chat
+
+import (
+	"backend/internal/dao"
+	"backend/internal/model/entity"
+	"backend/studyCoach/aiModel/eino_tools/filesystem"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	defaultSweepInterval = time.Hour          // 工作目录清理间隔
+	defaultAnonymousIdle = 7 * 24 * time.Hour // 匿名会话未活动多久后删除其工作目录
+	sweepQueryBatch      = 200                // 单次按 UUID 查询会话的数量上限
+)
+
+var sweepOnce sync.Once
+
+// StartWorkDirSweeper 启动工作目录定期清理（workdir.sweep.interval 为 0 时不启动），ctx 取消后停止
+func StartWorkDirSweeper(ctx context.Context) {
+	sweepOnce.Do(func() {
+		interval := g.Cfg().MustGet(ctx, "workdir.sweep.interval", defaultSweepInterval).Duration()
+		if interval <= 0 {
+			g.Log().Infof(ctx, "[WorkDir] 定期清理未启用")
+			return
+		}
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					g.Log().Infof(ctx, "[WorkDir] 定期清理已停止")
+					return
+				case <-ticker.C:
+				}
+				if n, err := SweepWorkDirs(ctx); err != nil {
+					g.Log().Errorf(ctx, "[WorkDir] 清理工作目录失败: %v", err)
+				} else if n > 0 {
+					g.Log().Infof(ctx, "[WorkDir] 已清理工作目录 %d 个", n)
+				}
+			}
+		}()
+		g.Log().Infof(ctx, "[WorkDir] 定期清理已启动, interval=%s", interval)
+	})
+}
+
+// SweepWorkDirs 删除超过 workdir.sweep.anonymousIdle 未活动的匿名会话工作目录，
+// 以及会话已不存在的遗留目录；登录用户的工作目录随会话删除。返回删除的目录数
+func SweepWorkDirs(ctx context.Context) (int, error) {
+	idle := g.Cfg().MustGet(ctx, "workdir.sweep.anonymousIdle", defaultAnonymousIdle).Duration()
+	if idle <= 0 {
+		return 0, nil
+	}
+	cutoff := time.Now().Add(-idle)
+	dirs, err := filesystem.ListWorkDirs(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("读取工作目录失败: %w", err)
+	}
+	// 目录内最近修改时间也早于 cutoff 的才作为候选
+	var candidates []string
+	for _, d := range dirs {
+		if d.UpdatedAt.Before(cutoff) {
+			candidates = append(candidates, d.SessionId)
+		}
+	}
+
+	removed := 0
+	for start := 0; start < len(candidates); start += sweepQueryBatch {
+		batch := candidates[start:min(start+sweepQueryBatch, len(candidates))]
+		var sessions []entity.ChatSessions
+		err = dao.ChatSessions.Ctx(ctx).
+			Fields(dao.ChatSessions.Columns().Uuid, dao.ChatSessions.Columns().UserId, dao.ChatSessions.Columns().UpdatedAt).
+			WhereIn(dao.ChatSessions.Columns().Uuid, batch).
+			Scan(&sessions)
+		if err != nil {
+			return removed, fmt.Errorf("查询会话失败: %w", err)
+		}
+		found := make(map[string]entity.ChatSessions, len(sessions))
+		for _, s := range sessions {
+			found[s.Uuid] = s
+		}
+		for _, sessionId := range batch {
+			s, ok := found[sessionId]
+			if !ok {
+				// 临时会话在有效期内保留目录，过期后按会话不存在清理
+				if exists, err := ephemeralExists(ctx, sessionId); err != nil || exists {
+					continue
+				}
+			}
+			if !sweepable(s, ok, cutoff) {
+				continue
+			}
+			if err := filesystem.RemoveWorkDir(ctx, sessionId); err != nil {
+				g.Log().Warningf(ctx, "[WorkDir] 删除工作目录失败: session=%s, 错误: %v", sessionId, err)
+				continue
+			}
+			removed++
+		}
+	}
+	return removed, nil
+}
+
+// sweepable 判断候选目录是否应删除：会话不存在，或为 cutoff 之后未活动的匿名会话
+func sweepable(s entity.ChatSessions, exists bool, cutoff time.Time) bool {
+	if !exists {
+		return true
+	}
+	return s.UserId == "" && (s.UpdatedAt == nil || !s.UpdatedAt.Time.After(cutoff))
+}
@@ -0,0 +1,33 @@
+package ai_chat
+
+import (
+	"backend/internal/model/entity"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+func TestSweepable(t *testing.T) {
+	cutoff := time.Now().Add(-7 * 24 * time.Hour)
+	before := gtime.New(cutoff.Add(-time.Hour))
+	after := gtime.New(cutoff.Add(time.Hour))
+	cases := []struct {
+		name    string
+		session entity.ChatSessions
+		exists  bool
+		want    bool
+	}{
+		{"会话不存在", entity.ChatSessions{}, false, true},
+		{"匿名会话长期未活动", entity.ChatSessions{UpdatedAt: before}, true, true},
+		{"匿名会话无更新时间", entity.ChatSessions{}, true, true},
+		{"匿名会话近期活动", entity.ChatSessions{UpdatedAt: after}, true, false},
+		{"登录用户会话长期未活动", entity.ChatSessions{UserId: "u1", UpdatedAt: before}, true, false},
+		{"登录用户会话近期活动", entity.ChatSessions{UserId: "u1", UpdatedAt: after}, true, false},
+	}
+	for _, c := range cases {
+		if got := sweepable(c.session, c.exists, cutoff); got != c.want {
+			t.Errorf("%s: sweepable = %v，期望 %v", c.name, got, c.want)
+		}
+	}
+}
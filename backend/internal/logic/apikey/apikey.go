@@ -0,0 +1,126 @@
+package apikey
+
+import (
+	"backend/internal/dao"
+	"backend/internal/model/do"
+	"backend/internal/model/entity"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+const (
+	keyPrefix      = "sk-sc-" // 明文前缀，便于识别与密钥扫描
+	keyBytes       = 24
+	shownPrefixLen = 6 // 列表中展示的随机部分长度
+	maxKeysPerUser = 20
+)
+
+type userCtxKey struct{}
+
+// WithUser 将 API Key 所属用户写入 ctx
+func WithUser(ctx context.Context, userUUID string) context.Context {
+	return context.WithValue(ctx, userCtxKey{}, userUUID)
+}
+
+// UserFromContext 读取通过 API Key 认证的用户 UUID，未认证时返回空
+func UserFromContext(ctx context.Context) string {
+	userUUID, _ := ctx.Value(userCtxKey{}).(string)
+	return userUUID
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create 为用户生成 API Key，明文只在此时返回
+func Create(ctx context.Context, userUUID, name string) (key string, row *entity.ApiKeys, err error) {
+	count, err := dao.ApiKeys.Ctx(ctx).Where(dao.ApiKeys.Columns().UserUuid, userUUID).Count()
+	if err != nil {
+		return "", nil, err
+	}
+	if count >= maxKeysPerUser {
+		return "", nil, gerror.NewCode(gcode.New(400, "API Key 数量已达上限，请先删除不再使用的 Key", nil))
+	}
+	buf := make([]byte, keyBytes)
+	if _, err = rand.Read(buf); err != nil {
+		return "", nil, err
+	}
+	key = keyPrefix + hex.EncodeToString(buf)
+	row = &entity.ApiKeys{
+		UserUuid:  userUUID,
+		Name:      strings.TrimSpace(name),
+		KeyPrefix: key[:len(keyPrefix)+shownPrefixLen] + "…",
+		KeyHash:   hashKey(key),
+		CreatedAt: gtime.Now(),
+	}
+	row.Id, err = dao.ApiKeys.Ctx(ctx).Data(do.ApiKeys{
+		UserUuid:  row.UserUuid,
+		Name:      row.Name,
+		KeyPrefix: row.KeyPrefix,
+		KeyHash:   row.KeyHash,
+	}).InsertAndGetId()
+	if err != nil {
+		g.Log().Errorf(ctx, "[ApiKey] 创建失败: user=%s, 错误: %v", userUUID, err)
+		return "", nil, err
+	}
+	return key, row, nil
+}
+
+// List 用户的 API Key，按创建时间倒序
+func List(ctx context.Context, userUUID string) (list []entity.ApiKeys, err error) {
+	err = dao.ApiKeys.Ctx(ctx).
+		Where(dao.ApiKeys.Columns().UserUuid, userUUID).
+		OrderDesc(dao.ApiKeys.Columns().Id).
+		Scan(&list)
+	return
+}
+
+// Delete 删除用户的 API Key，删除后立即失效
+func Delete(ctx context.Context, userUUID string, id int64) error {
+	result, err := dao.ApiKeys.Ctx(ctx).
+		WherePri(id).
+		Where(dao.ApiKeys.Columns().UserUuid, userUUID).
+		Delete()
+	if err != nil {
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return gerror.NewCode(gcode.New(404, "API Key 不存在", nil))
+	}
+	return nil
+}
+
+// Authenticate 校验 API Key 并返回所属用户 UUID，同时在后台更新最近使用时间
+func Authenticate(ctx context.Context, key string) (string, error) {
+	if !strings.HasPrefix(key, keyPrefix) {
+		return "", gerror.NewCode(gcode.New(401, "API Key 格式错误", nil))
+	}
+	var row *entity.ApiKeys
+	err := dao.ApiKeys.Ctx(ctx).Where(dao.ApiKeys.Columns().KeyHash, hashKey(key)).Scan(&row)
+	if err != nil {
+		g.Log().Errorf(ctx, "[ApiKey] 查询失败: %v", err)
+		return "", gerror.NewCode(gcode.New(500, "API Key 校验失败", nil))
+	}
+	if row == nil {
+		return "", gerror.NewCode(gcode.New(401, "API Key 无效或已删除", nil))
+	}
+	go func(ctx context.Context) {
+		_, err := dao.ApiKeys.Ctx(ctx).WherePri(row.Id).
+			Data(dao.ApiKeys.Columns().LastUsedAt, gtime.Now()).
+			Update()
+		if err != nil {
+			g.Log().Warningf(ctx, "[ApiKey] 更新使用时间失败: id=%d, 错误: %v", row.Id, err)
+		}
+	}(gctx.NeverDone(ctx))
+	return row.UserUuid, nil
+}
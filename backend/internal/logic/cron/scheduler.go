@@ -2,6 +2,8 @@ package cron
 
 import (
 	"backend/internal/dao"
+	"backend/internal/logic/knowledge"
+	"backend/internal/logic/usage"
 	"backend/internal/model/do"
 	"backend/internal/model/entity"
 	"backend/studyCoach/api"
@@ -95,6 +97,13 @@ func executeJobWithLog(ctx context.Context, schedule *entity.KnowledgeBaseCronSc
 	var executeId int64
 	var err error
 
+	// 用量归属到知识库所属用户；同名知识库无法确定归属时只记来源
+	scope := usage.Scope{Source: usage.SourceCron}
+	if ns, nsErr := knowledge.GetNamespaceByName(ctx, schedule.KnowledgeBaseName); nsErr == nil {
+		scope.UserUUID, scope.KnowledgeBaseId = ns.UserUUID, ns.KnowledgeBaseId
+	}
+	ctx = usage.WithScope(ctx, scope)
+
 	// 1. 创建执行记录，状态为执行中
 	executeId, err = dao.CronExecute.Ctx(ctx).Data(do.CronExecute{
 		CronId:      schedule.Id,
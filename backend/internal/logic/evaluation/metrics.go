@@ -0,0 +1,103 @@
+package evaluation
+
+import (
+	"backend/internal/model/entity"
+	"backend/studyCoach/aiModel/retriever"
+	"math"
+)
+
+// judge 按 ID 标记检索结果中每个位置是否相关，返回相关标记与理想情况下的相关数（用于 recall 与 IDCG），
+// 理想相关数为标注的相关 chunk 数：期望 chunk，或参考答案模式下由 labelReference 从知识库标注出的 chunk
+func judge(ids []string, relevant []string) (rel []bool, ideal int) {
+	want := make(map[string]bool, len(relevant))
+	for _, id := range relevant {
+		want[id] = true
+	}
+	rel = make([]bool, len(ids))
+	for i, id := range ids {
+		rel[i] = want[id]
+	}
+	return rel, len(want)
+}
+
+// corpusChunk 知识库中一个启用切片的 ID 与词集，评测开始时计算一次供各题标注复用
+type corpusChunk struct {
+	id    string
+	terms map[string]bool
+}
+
+func newCorpus(chunks []entity.KnowledgeChunks) []corpusChunk {
+	corpus := make([]corpusChunk, 0, len(chunks))
+	for _, c := range chunks {
+		corpus = append(corpus, corpusChunk{id: c.ChunkId, terms: termSet(c.Content)})
+	}
+	return corpus
+}
+
+// labelReference 在整个知识库中标注参考答案的相关 chunk：覆盖参考答案词的比例不低于 coverage 即视为相关，
+// 与是否被检索到无关，检索漏掉的相关 chunk 同样计入理想相关数
+func labelReference(corpus []corpusChunk, reference string, coverage float64) []string {
+	answerTerms := termSet(reference)
+	if len(answerTerms) == 0 {
+		return nil
+	}
+	var relevant []string
+	for _, c := range corpus {
+		hit := 0
+		for t := range answerTerms {
+			if c.terms[t] {
+				hit++
+			}
+		}
+		if float64(hit)/float64(len(answerTerms)) >= coverage {
+			relevant = append(relevant, c.id)
+		}
+	}
+	return relevant
+}
+
+// recallAtK 前 k 个结果中相关数 / 理想相关数
+func recallAtK(rel []bool, ideal, k int) float64 {
+	hit := 0
+	for i := 0; i < len(rel) && i < k; i++ {
+		if rel[i] {
+			hit++
+		}
+	}
+	return float64(hit) / float64(max(ideal, 1))
+}
+
+// reciprocalRank 第一个相关结果排名的倒数，无相关结果为 0
+func reciprocalRank(rel []bool) float64 {
+	for i, r := range rel {
+		if r {
+			return 1 / float64(i+1)
+		}
+	}
+	return 0
+}
+
+// ndcgAtK 二值相关度的 nDCG@k
+func ndcgAtK(rel []bool, ideal, k int) float64 {
+	var dcg, idcg float64
+	for i := 0; i < len(rel) && i < k; i++ {
+		if rel[i] {
+			dcg += 1 / math.Log2(float64(i+2))
+		}
+	}
+	for i := 0; i < min(ideal, k); i++ {
+		idcg += 1 / math.Log2(float64(i+2))
+	}
+	if idcg == 0 {
+		return 0
+	}
+	return dcg / idcg
+}
+
+func termSet(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, t := range retriever.Tokenize(text) {
+		set[t] = true
+	}
+	return set
+}
@@ -0,0 +1,32 @@
+package evaluation
+
+import (
+	"backend/internal/model/entity"
+	"math"
+	"testing"
+)
+
+func TestReferenceIdealCountsUnretrievedChunks(t *testing.T) {
+	// 参考答案模式下理想相关数取知识库中标注出的相关 chunk，检索漏掉的相关 chunk 会拉低 recall 与 nDCG
+	corpus := newCorpus([]entity.KnowledgeChunks{
+		{ChunkId: "a", Content: "快速排序 分治 基准"},
+		{ChunkId: "b", Content: "快速排序 分治 基准 递归"},
+		{ChunkId: "c", Content: "哈希表 冲突"},
+	})
+	relevant := labelReference(corpus, "快速排序 分治 基准", 0.5)
+	if len(relevant) != 2 {
+		t.Fatalf("应标注 2 个相关 chunk，实际 %v", relevant)
+	}
+
+	rel, ideal := judge([]string{"a", "c"}, relevant)
+	if ideal != 2 || !rel[0] || rel[1] {
+		t.Fatalf("相关标记 %v, 理想相关数 %d", rel, ideal)
+	}
+	if r := recallAtK(rel, ideal, 5); r != 0.5 {
+		t.Errorf("只检索到一半相关 chunk，recall 应为 0.5，实际 %v", r)
+	}
+	want := 1 / (1 + 1/math.Log2(3))
+	if n := ndcgAtK(rel, ideal, 5); math.Abs(n-want) > 1e-9 {
+		t.Errorf("nDCG 应按 2 个理想相关计算为 %v，实际 %v", want, n)
+	}
+}
@@ -0,0 +1,110 @@
+package evaluation
+
+import (
+	v1 "backend/api/rag/v1"
+	"backend/internal/dao"
+	"backend/internal/model/do"
+	"backend/internal/model/entity"
+	"backend/studyCoach/common"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	defaultPageSize = 10
+	maxPageSize     = 100
+)
+
+// AddQuestions 向知识库评测集批量添加问题，每个问题需提供期望 chunk 或参考答案
+func AddQuestions(ctx context.Context, ns common.Namespace, knowledgeName string, questions []v1.EvalQuestion) (ids []int64, err error) {
+	for i, q := range questions {
+		if strings.TrimSpace(q.Question) == "" {
+			return nil, fmt.Errorf("第 %d 个问题为空", i+1)
+		}
+		if len(q.ExpectedChunkIds) == 0 && strings.TrimSpace(q.ReferenceAnswer) == "" {
+			return nil, fmt.Errorf("第 %d 个问题缺少期望 chunk 或参考答案", i+1)
+		}
+	}
+	for _, q := range questions {
+		expected := ""
+		if len(q.ExpectedChunkIds) > 0 {
+			expected = gjson.MustEncodeString(q.ExpectedChunkIds)
+		}
+		id, err := dao.KnowledgeEvalQuestions.Ctx(ctx).Data(do.KnowledgeEvalQuestions{
+			UserUuid:          ns.UserUUID,
+			KnowledgeBaseId:   ns.KnowledgeBaseId,
+			KnowledgeBaseName: knowledgeName,
+			Question:          strings.TrimSpace(q.Question),
+			ExpectedChunkIds:  expected,
+			ReferenceAnswer:   strings.TrimSpace(q.ReferenceAnswer),
+		}).InsertAndGetId()
+		if err != nil {
+			g.Log().Errorf(ctx, "保存评测问题失败: knowledge=%s, 错误: %v", knowledgeName, err)
+			return nil, fmt.Errorf("保存评测问题失败: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+// ListQuestions 分页查询知识库评测集
+func ListQuestions(ctx context.Context, ns common.Namespace, page, size int) (list []entity.KnowledgeEvalQuestions, total int, err error) {
+	page, size = normalizePage(page, size)
+	model := dao.KnowledgeEvalQuestions.Ctx(ctx).
+		Where("knowledge_base_id", ns.KnowledgeBaseId).
+		Where("user_uuid", ns.UserUUID)
+	total, err = model.Count()
+	if err != nil {
+		g.Log().Errorf(ctx, "获取评测问题总数失败: %v", err)
+		return nil, 0, fmt.Errorf("获取评测问题总数失败: %w", err)
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+	if err = model.Page(page, size).OrderAsc("id").Scan(&list); err != nil {
+		g.Log().Errorf(ctx, "获取评测问题列表失败: %v", err)
+		return nil, 0, fmt.Errorf("获取评测问题列表失败: %w", err)
+	}
+	return list, total, nil
+}
+
+// DeleteQuestions 删除当前用户的评测问题
+func DeleteQuestions(ctx context.Context, userUUID string, ids []int64) error {
+	_, err := dao.KnowledgeEvalQuestions.Ctx(ctx).
+		WhereIn("id", ids).
+		Where("user_uuid", userUUID).
+		Delete()
+	if err != nil {
+		g.Log().Errorf(ctx, "删除评测问题失败: ids=%v, 错误: %v", ids, err)
+		return fmt.Errorf("删除评测问题失败: %w", err)
+	}
+	return nil
+}
+
+// loadQuestions 加载知识库全部评测问题
+func loadQuestions(ctx context.Context, ns common.Namespace) (list []entity.KnowledgeEvalQuestions, err error) {
+	err = dao.KnowledgeEvalQuestions.Ctx(ctx).
+		Where("knowledge_base_id", ns.KnowledgeBaseId).
+		Where("user_uuid", ns.UserUUID).
+		OrderAsc("id").
+		Scan(&list)
+	if err != nil {
+		g.Log().Errorf(ctx, "加载评测问题失败: %s, 错误: %v", ns, err)
+		return nil, fmt.Errorf("加载评测问题失败: %w", err)
+	}
+	return list, nil
+}
+
+func normalizePage(page, size int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = defaultPageSize
+	}
+	return page, min(size, maxPageSize)
+}
@@ -0,0 +1,342 @@
+package evaluation
+
+import (
+	v1 "backend/api/rag/v1"
+	"backend/internal/dao"
+	"backend/internal/logic/knowledge"
+	"backend/internal/logic/rag"
+	"backend/internal/logic/usage"
+	"backend/internal/model/do"
+	"backend/internal/model/entity"
+	"backend/studyCoach/aiModel/indexer"
+	"backend/studyCoach/api"
+	"backend/studyCoach/common"
+	"backend/studyCoach/llm"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultTopK          = 5
+	defaultScore         = 0.2 // 与 /v1/retriever 默认值一致
+	defaultConcurrency   = 2
+	defaultCoverage      = 0.5
+	questionTimeout      = 3 * time.Minute
+	maxRetrievedRecorded = 20
+)
+
+// RunOptions 评测参数
+type RunOptions struct {
+	Namespace     common.Namespace
+	KnowledgeName string
+	TopK          int
+	Score         float64
+	Mode          string
+	Profile       *v1.RetrievalProfile // 检索配置覆盖项
+	Note          string
+}
+
+// QuestionResult 单题评测结果
+type QuestionResult struct {
+	QuestionId int64    `json:"question_id"`
+	Question   string   `json:"question"`
+	Retrieved  []string `json:"retrieved"`      // 按排名的 chunk_id
+	Relevant   []int    `json:"relevant_ranks"` // 判定为相关的排名（从 1 开始）
+	Recall     float64  `json:"recall"`
+	Mrr        float64  `json:"mrr"`
+	Ndcg       float64  `json:"ndcg"`
+	Unlabeled  bool     `json:"unlabeled,omitempty"` // 没有期望 chunk，参考答案也未标注出相关 chunk，不计入平均指标
+	Error      string   `json:"error,omitempty"`
+}
+
+// Start 创建评测运行并在后台执行，返回运行 ID
+func Start(ctx context.Context, opts RunOptions) (runId string, err error) {
+	run, questions, err := prepare(ctx, &opts)
+	if err != nil {
+		return "", err
+	}
+	go execute(gctx.NeverDone(ctx), run, questions, opts)
+	return run.RunId, nil
+}
+
+// Run 同步执行评测，供命令行使用
+func Run(ctx context.Context, opts RunOptions) (*entity.KnowledgeEvalRuns, []QuestionResult, error) {
+	run, questions, err := prepare(ctx, &opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	results := execute(ctx, run, questions, opts)
+	return run, results, nil
+}
+
+// prepare 校验评测集并创建运行记录，记录当次生效的检索管线配置
+func prepare(ctx context.Context, opts *RunOptions) (*entity.KnowledgeEvalRuns, []entity.KnowledgeEvalQuestions, error) {
+	if rag.GetRagSvr() == nil {
+		return nil, nil, fmt.Errorf("RAG服务未初始化，请检查向量库和embedding配置")
+	}
+	if opts.TopK <= 0 {
+		opts.TopK = defaultTopK
+	}
+	if opts.Score == 0 {
+		opts.Score = defaultScore
+	}
+	if opts.Mode == "" {
+		opts.Mode = api.RetrieveModeStandard
+	}
+	questions, err := loadQuestions(ctx, opts.Namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(questions) == 0 {
+		return nil, nil, fmt.Errorf("知识库「%s」没有评测问题", opts.KnowledgeName)
+	}
+	run := &entity.KnowledgeEvalRuns{
+		RunId:             uuid.NewString(),
+		UserUuid:          opts.Namespace.UserUUID,
+		KnowledgeBaseId:   opts.Namespace.KnowledgeBaseId,
+		KnowledgeBaseName: opts.KnowledgeName,
+		Status:            v1.EvalStatusRunning,
+		TopK:              opts.TopK,
+		Config:            gjson.MustEncodeString(pipelineConfig(ctx, opts)),
+		Note:              opts.Note,
+		QuestionCount:     len(questions),
+	}
+	_, err = dao.KnowledgeEvalRuns.Ctx(ctx).Data(do.KnowledgeEvalRuns{
+		RunId:             run.RunId,
+		UserUuid:          run.UserUuid,
+		KnowledgeBaseId:   run.KnowledgeBaseId,
+		KnowledgeBaseName: run.KnowledgeBaseName,
+		Status:            run.Status,
+		TopK:              run.TopK,
+		Config:            run.Config,
+		Note:              run.Note,
+		QuestionCount:     run.QuestionCount,
+	}).Insert()
+	if err != nil {
+		g.Log().Errorf(ctx, "创建评测运行失败: knowledge=%s, 错误: %v", opts.KnowledgeName, err)
+		return nil, nil, fmt.Errorf("创建评测运行失败: %w", err)
+	}
+	return run, questions, nil
+}
+
+// pipelineConfig 当次检索管线配置：模型、向量引擎、合并后的检索配置与切分参数
+func pipelineConfig(ctx context.Context, opts *RunOptions) g.Map {
+	cfg := g.Cfg()
+	return g.Map{
+		"top_k":             opts.TopK,
+		"score":             opts.Score,
+		"mode":              opts.Mode,
+		"vector_engine":     cfg.MustGet(ctx, "vectorEngine", common.VectorEngineES).String(),
+		"embedding_model":   cfg.MustGet(ctx, "embeddingArk.model").String(),
+		"rewrite_model":     llm.PrimaryModel(ctx, llm.RoleRewrite),
+		"rerank_model":      cfg.MustGet(ctx, "rerank.model").String(),
+		"rrf_k":             cfg.MustGet(ctx, "retriever.hybrid.rrfK", 60).Int(),
+		"retrieval_profile": api.EffectiveRetrievalProfile(ctx, retrieveReq(opts, "")),
+		"chunking":          indexer.ChunkingConfig(),
+	}
+}
+
+func retrieveReq(opts *RunOptions, question string) *api.RetrieveReq {
+	return &api.RetrieveReq{
+		Query:         question,
+		TopK:          opts.TopK,
+		Score:         opts.Score,
+		KnowledgeName: opts.KnowledgeName,
+		Namespace:     opts.Namespace,
+		Mode:          opts.Mode,
+		Profile:       opts.Profile,
+	}
+}
+
+// execute 并发检索每个问题并计算指标，汇总后写回运行记录
+func execute(ctx context.Context, run *entity.KnowledgeEvalRuns, questions []entity.KnowledgeEvalQuestions, opts RunOptions) []QuestionResult {
+	start := time.Now()
+	ctx = usage.WithScope(ctx, usage.Scope{
+		Source:          usage.SourceEval,
+		UserUUID:        opts.Namespace.UserUUID,
+		KnowledgeBaseId: opts.Namespace.KnowledgeBaseId,
+	})
+	concurrency := max(g.Cfg().MustGet(ctx, "eval.concurrency", defaultConcurrency).Int(), 1)
+	coverage := g.Cfg().MustGet(ctx, "eval.answerCoverage", defaultCoverage).Float64()
+	corpus, corpusErr := loadCorpus(ctx, questions, opts.Namespace)
+	results := make([]QuestionResult, len(questions))
+	sem := make(chan struct{}, concurrency)
+	wg := &sync.WaitGroup{}
+	for i, q := range questions {
+		wg.Add(1)
+		go func(i int, q entity.KnowledgeEvalQuestions) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = evaluate(ctx, q, &opts, corpus, corpusErr, coverage)
+		}(i, q)
+	}
+	wg.Wait()
+
+	failed := aggregate(run, results)
+	run.Details = gjson.MustEncodeString(results)
+	run.DurationMs = time.Since(start).Milliseconds()
+	run.FinishedAt = gtime.Now()
+	_, err := dao.KnowledgeEvalRuns.Ctx(ctx).Where("run_id", run.RunId).Data(g.Map{
+		"status":          run.Status,
+		"recall":          run.Recall,
+		"mrr":             run.Mrr,
+		"ndcg":            run.Ndcg,
+		"unlabeled_count": run.UnlabeledCount,
+		"details":         run.Details,
+		"error":           run.Error,
+		"duration_ms":     run.DurationMs,
+		"finished_at":     run.FinishedAt,
+	}).Update()
+	if err != nil {
+		g.Log().Errorf(ctx, "[Eval] 保存评测结果失败: run_id=%s, err=%v", run.RunId, err)
+	}
+	g.Log().Infof(ctx, "[Eval] done in %v, run_id=%s knowledge=%s questions=%d failed=%d unlabeled=%d recall@%d=%.4f mrr=%.4f ndcg=%.4f",
+		time.Since(start), run.RunId, run.KnowledgeBaseName, len(results), failed, run.UnlabeledCount, run.TopK, run.Recall, run.Mrr, run.Ndcg)
+	return results
+}
+
+// aggregate 汇总逐题指标写入运行记录，返回检索失败的问题数。
+// 检索失败与未标注出相关 chunk 的问题都不计入平均值，后者的数量记录在 UnlabeledCount。
+func aggregate(run *entity.KnowledgeEvalRuns, results []QuestionResult) (failed int) {
+	var firstErr string
+	for _, r := range results {
+		switch {
+		case r.Error != "":
+			if failed == 0 {
+				firstErr = r.Error
+			}
+			failed++
+		case r.Unlabeled:
+			run.UnlabeledCount++
+		default:
+			run.Recall += r.Recall
+			run.Mrr += r.Mrr
+			run.Ndcg += r.Ndcg
+		}
+	}
+	run.Status = v1.EvalStatusSucceeded
+	if n := len(results) - failed - run.UnlabeledCount; n > 0 {
+		run.Recall /= float64(n)
+		run.Mrr /= float64(n)
+		run.Ndcg /= float64(n)
+	} else if run.UnlabeledCount == 0 {
+		run.Status = v1.EvalStatusFailed
+		run.Error = fmt.Sprintf("全部 %d 个问题检索失败，首个错误: %s", failed, firstErr)
+	} else {
+		run.Status = v1.EvalStatusFailed
+		run.Error = fmt.Sprintf("%d 个问题检索失败，%d 个问题未标注出相关 chunk，没有可计入指标的问题；请补充期望 chunk 或调整参考答案", failed, run.UnlabeledCount)
+	}
+	return failed
+}
+
+// loadCorpus 存在仅有参考答案的问题时读取知识库全部启用切片，用于标注参考答案的相关 chunk
+func loadCorpus(ctx context.Context, questions []entity.KnowledgeEvalQuestions, ns common.Namespace) ([]corpusChunk, error) {
+	for _, q := range questions {
+		if q.ExpectedChunkIds == "" {
+			chunks, err := knowledge.GetSearchableChunks(ctx, ns)
+			if err != nil {
+				return nil, err
+			}
+			return newCorpus(chunks), nil
+		}
+	}
+	return nil, nil
+}
+
+// evaluate 检索单个问题并计算 recall@k、MRR、nDCG@k；纠错模式下的网络搜索结果不计入
+func evaluate(ctx context.Context, q entity.KnowledgeEvalQuestions, opts *RunOptions, corpus []corpusChunk, corpusErr error, coverage float64) QuestionResult {
+	res := QuestionResult{QuestionId: q.Id, Question: q.Question}
+	qCtx, cancel := context.WithTimeout(ctx, questionTimeout)
+	defer cancel()
+	result, err := rag.GetRagSvr().Retrieve(qCtx, retrieveReq(opts, q.Question))
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	var ids []string
+	for _, doc := range result.Documents {
+		if origin, _ := doc.MetaData[common.Origin].(string); origin == common.OriginWeb {
+			continue
+		}
+		ids = append(ids, doc.ID)
+	}
+	var relevant []string
+	if q.ExpectedChunkIds != "" {
+		if err = gjson.DecodeTo(q.ExpectedChunkIds, &relevant); err != nil {
+			res.Error = fmt.Sprintf("期望 chunk 解析失败: %v", err)
+			return res
+		}
+	}
+	if len(relevant) == 0 {
+		if corpusErr != nil {
+			res.Error = fmt.Sprintf("读取知识库切片失败: %v", corpusErr)
+			return res
+		}
+		relevant = labelReference(corpus, q.ReferenceAnswer, coverage)
+	}
+	res.Retrieved = ids[:min(len(ids), maxRetrievedRecorded)]
+	if len(relevant) == 0 {
+		// 没有可判定的相关 chunk 时任何检索结果都得 0 分，计入平均会无差别地拉低指标
+		res.Unlabeled = true
+		return res
+	}
+	rel, ideal := judge(ids, relevant)
+	for i, r := range rel {
+		if r {
+			res.Relevant = append(res.Relevant, i+1)
+		}
+	}
+	res.Recall = recallAtK(rel, ideal, opts.TopK)
+	res.Mrr = reciprocalRank(rel)
+	res.Ndcg = ndcgAtK(rel, ideal, opts.TopK)
+	return res
+}
+
+// GetRun 查询当前用户的评测运行
+func GetRun(ctx context.Context, userUUID, runId string) (*entity.KnowledgeEvalRuns, error) {
+	var run *entity.KnowledgeEvalRuns
+	err := dao.KnowledgeEvalRuns.Ctx(ctx).
+		Where("run_id", runId).
+		Where("user_uuid", userUUID).
+		Scan(&run)
+	if err != nil {
+		g.Log().Errorf(ctx, "获取评测运行失败: run_id=%s, 错误: %v", runId, err)
+		return nil, fmt.Errorf("获取评测运行失败: %w", err)
+	}
+	if run == nil {
+		return nil, fmt.Errorf("评测运行不存在")
+	}
+	return run, nil
+}
+
+// ListRuns 分页查询当前用户的评测运行（不含逐题结果），可按知识库过滤
+func ListRuns(ctx context.Context, userUUID, knowledgeName string, page, size int) (runs []entity.KnowledgeEvalRuns, total int, err error) {
+	page, size = normalizePage(page, size)
+	model := dao.KnowledgeEvalRuns.Ctx(ctx).Where("user_uuid", userUUID)
+	if knowledgeName != "" {
+		model = model.Where("knowledge_base_name", knowledgeName)
+	}
+	total, err = model.Count()
+	if err != nil {
+		g.Log().Errorf(ctx, "获取评测运行总数失败: %v", err)
+		return nil, 0, fmt.Errorf("获取评测运行总数失败: %w", err)
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+	err = model.FieldsEx("details").Page(page, size).OrderDesc("id").Scan(&runs)
+	if err != nil {
+		g.Log().Errorf(ctx, "获取评测运行列表失败: %v", err)
+		return nil, 0, fmt.Errorf("获取评测运行列表失败: %w", err)
+	}
+	return runs, total, nil
+}
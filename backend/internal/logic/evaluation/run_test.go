@@ -0,0 +1,33 @@
+package evaluation
+
+import (
+	v1 "backend/api/rag/v1"
+	"backend/internal/model/entity"
+	"testing"
+)
+
+func TestAggregateSkipsUnlabeled(t *testing.T) {
+	// 未标注出相关 chunk 的问题与检索失败的问题一样不计入平均值，只记录数量
+	run := &entity.KnowledgeEvalRuns{}
+	failed := aggregate(run, []QuestionResult{
+		{Recall: 1, Mrr: 1, Ndcg: 1},
+		{Recall: 0.5, Mrr: 0.5, Ndcg: 0.5},
+		{Unlabeled: true},
+		{Error: "timeout"},
+	})
+	if failed != 1 || run.UnlabeledCount != 1 {
+		t.Fatalf("failed=%d unlabeled=%d，期望 1/1", failed, run.UnlabeledCount)
+	}
+	if run.Status != v1.EvalStatusSucceeded || run.Recall != 0.75 || run.Mrr != 0.75 || run.Ndcg != 0.75 {
+		t.Fatalf("平均指标应只按 2 个已标注问题计算: %+v", run)
+	}
+}
+
+func TestAggregateAllUnlabeled(t *testing.T) {
+	// 没有任何可计入指标的问题时运行失败，而不是报告 0 分
+	run := &entity.KnowledgeEvalRuns{}
+	aggregate(run, []QuestionResult{{Unlabeled: true}, {Unlabeled: true}})
+	if run.Status != v1.EvalStatusFailed || run.UnlabeledCount != 2 || run.Error == "" {
+		t.Fatalf("全部未标注时应失败并说明原因: %+v", run)
+	}
+}
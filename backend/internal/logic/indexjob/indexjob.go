@@ -0,0 +1,220 @@
+package indexjob
+
+import (
+	v1 "backend/api/rag/v1"
+	"backend/internal/dao"
+	"backend/internal/model/do"
+	"backend/internal/model/entity"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/os/gtime"
+	"github.com/google/uuid"
+)
+
+const (
+	defaultWorkers     = 2
+	defaultMaxAttempts = 3
+	defaultRetryDelay  = 30 * time.Second
+	defaultLeaseTTL    = 2 * time.Minute
+	queueSize          = 1024
+	defaultPageSize    = 10
+	maxPageSize        = 100
+)
+
+var (
+	// queue 待执行的任务 ID，由 Start 初始化
+	queue chan string
+	// pending 已入队未执行的任务 ID，避免重复入队
+	pending   sync.Map
+	startOnce sync.Once
+	// instanceId 当前服务实例标识，认领任务时写入 owner，多副本部署时区分任务由哪个实例执行
+	instanceId = uuid.NewString()
+)
+
+// Start 启动索引任务 worker，恢复未完成的任务，并按租约周期回收其他实例退出后遗留的任务
+func Start(ctx context.Context) {
+	startOnce.Do(func() {
+		workers := g.Cfg().MustGet(ctx, "indexJob.workers", defaultWorkers).Int()
+		if workers <= 0 {
+			workers = defaultWorkers
+		}
+		queue = make(chan string, queueSize)
+		for i := 0; i < workers; i++ {
+			go worker()
+		}
+		g.Log().Infof(ctx, "[IndexJob] started, workers=%d, instance=%s", workers, instanceId)
+		recoverJobs(ctx, nil)
+		go func() {
+			for range time.Tick(leaseTTL(ctx)) {
+				recoverJobs(gctx.New(), gtime.Now())
+			}
+		}()
+	})
+}
+
+// leaseTTL 执行租约时长（indexJob.leaseTTL），执行实例每 1/3 租约续约一次
+func leaseTTL(ctx context.Context) time.Duration {
+	ttl := g.Cfg().MustGet(ctx, "indexJob.leaseTTL", defaultLeaseTTL).Duration()
+	if ttl <= 0 {
+		return defaultLeaseTTL
+	}
+	return ttl
+}
+
+// recoverJobs 续跑未完成的任务：租约过期的执行中任务重新排队，再调度排队中的任务。
+// dueBy 非空时只提交 next_run_at 已到期的任务（周期回收时避免重复设置延迟定时器）
+func recoverJobs(ctx context.Context, dueBy *gtime.Time) {
+	if n, err := RequeueExpired(ctx); err != nil {
+		g.Log().Errorf(ctx, "[IndexJob] 回收租约过期任务失败: %v", err)
+		return
+	} else if n > 0 {
+		g.Log().Infof(ctx, "[IndexJob] 回收租约过期的执行中任务 %d 个", n)
+	}
+	model := dao.KnowledgeIndexJobs.Ctx(ctx).
+		Fields("job_id", "next_run_at").
+		Where("status", v1.JobStatusQueued)
+	if dueBy != nil {
+		model = model.Where("(next_run_at IS NULL OR next_run_at <= ?)", dueBy)
+	}
+	var jobs []entity.KnowledgeIndexJobs
+	if err := model.OrderAsc("id").Scan(&jobs); err != nil {
+		g.Log().Errorf(ctx, "[IndexJob] 加载未完成任务失败: %v", err)
+		return
+	}
+	for _, job := range jobs {
+		schedule(job.JobId, job.NextRunAt)
+	}
+	if len(jobs) > 0 && dueBy == nil {
+		g.Log().Infof(ctx, "[IndexJob] 恢复未完成任务 %d 个", len(jobs))
+	}
+}
+
+// RequeueExpired 将租约已过期的执行中任务重新置为排队，返回回收数量。
+// 执行实例退出或失联后不再续约，租约到期即视为中断；仍在续约的任务（包括其他实例正在执行的）保持不变。
+// 升级前遗留的执行中任务没有租约，同样视为中断
+func RequeueExpired(ctx context.Context) (int64, error) {
+	result, err := dao.KnowledgeIndexJobs.Ctx(ctx).
+		Where("status", v1.JobStatusRunning).
+		Where("(lease_until IS NULL OR lease_until < ?)", gtime.Now()).
+		Data(g.Map{
+			"status":      v1.JobStatusQueued,
+			"owner":       "",
+			"lease_until": nil,
+		}).
+		Update()
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// Create 创建排队中的索引任务，返回任务 ID；需再调用 Enqueue 提交执行
+func Create(ctx context.Context, job entity.KnowledgeIndexJobs) (jobId string, err error) {
+	jobId = uuid.NewString()
+	maxAttempts := job.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = g.Cfg().MustGet(ctx, "indexJob.maxAttempts", defaultMaxAttempts).Int()
+	}
+	_, err = dao.KnowledgeIndexJobs.Ctx(ctx).Data(do.KnowledgeIndexJobs{
+		JobId:             jobId,
+		UserUuid:          job.UserUuid,
+		KnowledgeBaseId:   job.KnowledgeBaseId,
+		KnowledgeBaseName: job.KnowledgeBaseName,
+		DocumentsId:       job.DocumentsId,
+		FileName:          job.FileName,
+		Source:            job.Source,
+		Status:            v1.JobStatusQueued,
+		MaxAttempts:       max(maxAttempts, 1),
+	}).Insert()
+	if err != nil {
+		g.Log().Errorf(ctx, "创建索引任务失败: documentsId=%d, 错误: %v", job.DocumentsId, err)
+		return "", fmt.Errorf("创建索引任务失败: %w", err)
+	}
+	return jobId, nil
+}
+
+// Enqueue 提交任务执行；worker 未启动时任务保持排队，待下次启动时恢复
+func Enqueue(jobId string) {
+	if queue == nil {
+		g.Log().Warningf(gctx.New(), "[IndexJob] worker 未启动，任务保持排队: job_id=%s", jobId)
+		return
+	}
+	if _, loaded := pending.LoadOrStore(jobId, struct{}{}); loaded {
+		return
+	}
+	select {
+	case queue <- jobId:
+	default:
+		// 队列已满时不阻塞调用方
+		go func() { queue <- jobId }()
+	}
+}
+
+// schedule 按 next_run_at 延迟入队
+func schedule(jobId string, at *gtime.Time) {
+	if at == nil {
+		Enqueue(jobId)
+		return
+	}
+	if d := time.Until(at.Time); d > 0 {
+		time.AfterFunc(d, func() { Enqueue(jobId) })
+		return
+	}
+	Enqueue(jobId)
+}
+
+// Get 查询当前用户的任务
+func Get(ctx context.Context, userUUID, jobId string) (*entity.KnowledgeIndexJobs, error) {
+	var job *entity.KnowledgeIndexJobs
+	err := dao.KnowledgeIndexJobs.Ctx(ctx).
+		Where("job_id", jobId).
+		Where("user_uuid", userUUID).
+		Scan(&job)
+	if err != nil {
+		g.Log().Errorf(ctx, "获取索引任务失败: job_id=%s, 错误: %v", jobId, err)
+		return nil, fmt.Errorf("获取索引任务失败: %w", err)
+	}
+	if job == nil {
+		return nil, fmt.Errorf("索引任务不存在")
+	}
+	return job, nil
+}
+
+// List 分页查询当前用户的任务，可按知识库与状态过滤
+func List(ctx context.Context, userUUID, knowledgeName string, status *int, page, size int) (jobs []entity.KnowledgeIndexJobs, total int, err error) {
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = defaultPageSize
+	}
+	if size > maxPageSize {
+		size = maxPageSize
+	}
+	model := dao.KnowledgeIndexJobs.Ctx(ctx).Where("user_uuid", userUUID)
+	if knowledgeName != "" {
+		model = model.Where("knowledge_base_name", knowledgeName)
+	}
+	if status != nil {
+		model = model.Where("status", *status)
+	}
+	total, err = model.Count()
+	if err != nil {
+		g.Log().Errorf(ctx, "获取索引任务总数失败: %v", err)
+		return nil, 0, fmt.Errorf("获取索引任务总数失败: %w", err)
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+	err = model.Page(page, size).OrderDesc("id").Scan(&jobs)
+	if err != nil {
+		g.Log().Errorf(ctx, "获取索引任务列表失败: %v", err)
+		return nil, 0, fmt.Errorf("获取索引任务列表失败: %w", err)
+	}
+	return jobs, total, nil
+}
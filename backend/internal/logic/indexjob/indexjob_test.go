@@ -0,0 +1,111 @@
+package indexjob
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// useQueue 替换任务队列，测试结束后恢复
+func useQueue(t *testing.T, size int) chan string {
+	t.Helper()
+	original := queue
+	queue = make(chan string, size)
+	t.Cleanup(func() {
+		queue = original
+		pending.Range(func(k, _ any) bool {
+			pending.Delete(k)
+			return true
+		})
+	})
+	return queue
+}
+
+func TestEnqueueDedupesPending(t *testing.T) {
+	// 已入队未执行的任务不会重复入队；worker 取出后可再次提交
+	q := useQueue(t, 4)
+	Enqueue("a")
+	Enqueue("a")
+	Enqueue("b")
+	if len(q) != 2 {
+		t.Fatalf("队列长度 %d，期望 2", len(q))
+	}
+	jobId := <-q
+	pending.Delete(jobId)
+	Enqueue(jobId)
+	if len(q) != 2 {
+		t.Fatalf("取出后应允许重新入队，队列长度 %d", len(q))
+	}
+}
+
+func TestScheduleDelaysUntilNextRun(t *testing.T) {
+	q := useQueue(t, 4)
+	schedule("due", gtime.Now().Add(-time.Second))
+	schedule("later", gtime.Now().Add(200*time.Millisecond))
+	if got := <-q; got != "due" || len(q) != 0 {
+		t.Fatalf("已到期任务应立即入队，实际 %q，队列剩余 %d", got, len(q))
+	}
+	select {
+	case got := <-q:
+		if got != "later" {
+			t.Fatalf("延迟任务 %q", got)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("延迟任务未按 next_run_at 入队")
+	}
+}
+
+func TestKeepLease(t *testing.T) {
+	t.Run("续约成功时持续执行", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var renewals atomic.Int32
+		stop := keepLease(ctx, cancel, 10*time.Millisecond, func(context.Context) (bool, error) {
+			renewals.Add(1)
+			return true, nil
+		})
+		time.Sleep(60 * time.Millisecond)
+		if stop() || ctx.Err() != nil {
+			t.Fatal("租约有效时不应取消执行")
+		}
+		if renewals.Load() == 0 {
+			t.Fatal("执行期间应定期续约")
+		}
+	})
+
+	t.Run("数据库错误时重试", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		var calls atomic.Int32
+		stop := keepLease(ctx, cancel, 10*time.Millisecond, func(context.Context) (bool, error) {
+			if calls.Add(1) < 3 {
+				return false, errors.New("connection refused")
+			}
+			return true, nil
+		})
+		time.Sleep(60 * time.Millisecond)
+		if stop() || ctx.Err() != nil || calls.Load() < 3 {
+			t.Fatalf("续约出错时应重试而不是放弃执行，调用 %d 次", calls.Load())
+		}
+	})
+
+	t.Run("租约被回收时取消执行", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stop := keepLease(ctx, cancel, 10*time.Millisecond, func(context.Context) (bool, error) {
+			return false, nil
+		})
+		select {
+		case <-ctx.Done():
+		case <-time.After(time.Second):
+			t.Fatal("租约失效后应取消执行")
+		}
+		if !stop() {
+			t.Fatal("stop 应报告租约已失效")
+		}
+	})
+}
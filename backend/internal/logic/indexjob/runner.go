@@ -0,0 +1,336 @@
+package indexjob
+
+import (
+	v1 "backend/api/rag/v1"
+	"backend/internal/controller/ws"
+	"backend/internal/dao"
+	"backend/internal/logic/knowledge"
+	"backend/internal/logic/rag"
+	"backend/internal/logic/usage"
+	"backend/internal/model/entity"
+	"backend/studyCoach/api"
+	"backend/studyCoach/common"
+	"backend/studyCoach/mineruworker"
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// EventProgress WebSocket 推送的任务进度消息类型
+const EventProgress = "index_job_progress"
+
+// progressInterval 同一阶段内进度落库与推送的最小间隔
+const progressInterval = time.Second
+
+func worker() {
+	for jobId := range queue {
+		pending.Delete(jobId)
+		run(gctx.New(), jobId)
+	}
+}
+
+// run 认领并执行一个排队中的任务，失败时按指数退避重新排队，超过最大次数后标记失败
+func run(ctx context.Context, jobId string) {
+	var job *entity.KnowledgeIndexJobs
+	if err := dao.KnowledgeIndexJobs.Ctx(ctx).Where("job_id", jobId).Scan(&job); err != nil || job == nil {
+		g.Log().Errorf(ctx, "[IndexJob] 加载任务失败: job_id=%s, err=%v", jobId, err)
+		return
+	}
+	if job.Status != v1.JobStatusQueued {
+		return
+	}
+	if job.NextRunAt != nil && time.Until(job.NextRunAt.Time) > 0 {
+		schedule(jobId, job.NextRunAt)
+		return
+	}
+	// 以状态作乐观锁认领，避免同一任务被重复执行；认领时写入执行实例与租约
+	ttl := leaseTTL(ctx)
+	result, err := dao.KnowledgeIndexJobs.Ctx(ctx).
+		Where("job_id", jobId).
+		Where("status", v1.JobStatusQueued).
+		Data(g.Map{
+			"status":      v1.JobStatusRunning,
+			"attempts":    job.Attempts + 1,
+			"owner":       instanceId,
+			"lease_until": gtime.Now().Add(ttl),
+		}).
+		Update()
+	if err != nil {
+		g.Log().Errorf(ctx, "[IndexJob] 认领任务失败: job_id=%s, err=%v", jobId, err)
+		return
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return
+	}
+	job.Status = v1.JobStatusRunning
+	job.Attempts++
+	_ = knowledge.UpdateDocumentsStatus(ctx, job.DocumentsId, int(v1.StatusIndexing))
+
+	start := time.Now()
+	g.Log().Infof(ctx, "[IndexJob] start job_id=%s documentsId=%d attempt=%d/%d stage=%q", job.JobId, job.DocumentsId, job.Attempts, job.MaxAttempts, job.Stage)
+	execCtx, cancel := context.WithCancel(ctx)
+	stopLease := keepLease(execCtx, cancel, ttl/3, func(ctx context.Context) (bool, error) {
+		return renewLease(ctx, jobId, ttl)
+	})
+	err = execute(execCtx, job)
+	lost := stopLease()
+	cancel()
+	if lost {
+		// 租约已被回收并可能由其他实例重新执行，本次结果不再写回
+		g.Log().Warningf(ctx, "[IndexJob] 租约已失效，放弃本次执行: job_id=%s, err=%v", job.JobId, err)
+		return
+	}
+	if err != nil {
+		g.Log().Errorf(ctx, "[IndexJob] failed after %v, job_id=%s stage=%s attempt=%d/%d, err=%v", time.Since(start), job.JobId, job.Stage, job.Attempts, job.MaxAttempts, err)
+		fail(ctx, job, err)
+		return
+	}
+	g.Log().Infof(ctx, "[IndexJob] success in %v, job_id=%s documentsId=%d chunks=%d", time.Since(start), job.JobId, job.DocumentsId, job.ChunkCount)
+	succeed(ctx, job)
+}
+
+// execute 按阶段执行：extract（仅 PDF）-> split/embed/store -> qa。
+// 已完成的解析结果与切片会被复用；切分写入阶段中断时先清理半成品再重做。
+func execute(ctx context.Context, job *entity.KnowledgeIndexJobs) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	svr := rag.GetRagSvr()
+	if svr == nil {
+		return fmt.Errorf("RAG服务未初始化，请检查Elasticsearch和embedding配置")
+	}
+	ns := common.Namespace{KnowledgeBaseId: job.KnowledgeBaseId, UserUUID: job.UserUuid}
+	ctx = usage.WithScope(ctx, usage.Scope{
+		Source:          usage.SourceIndex,
+		UserUUID:        job.UserUuid,
+		KnowledgeBaseId: job.KnowledgeBaseId,
+	})
+	p := &progress{ctx: ctx, job: job}
+
+	if job.WorkUri == "" {
+		workURI := job.Source
+		if mineruworker.IsPDFPath(job.FileName) {
+			p.report(v1.JobStageExtract, 0, 1)
+			if workURI, err = mineruworker.ExtractPDFToMarkdownFile(ctx, job.Source, job.DocumentsId); err != nil {
+				return err
+			}
+			p.report(v1.JobStageExtract, 1, 1)
+		}
+		job.WorkUri = workURI
+		if err = update(ctx, job.JobId, g.Map{"work_uri": workURI}); err != nil {
+			return err
+		}
+	}
+
+	if job.Stage != v1.JobStageQA {
+		if n, err := knowledge.ClearDocumentChunks(ctx, ns, job.DocumentsId); err != nil {
+			return fmt.Errorf("清理上次残留切片失败: %w", err)
+		} else if n > 0 {
+			g.Log().Infof(ctx, "[IndexJob] 清理上次残留切片 %d 个, job_id=%s", n, job.JobId)
+		}
+		ids, err := svr.Index(ctx, &api.IndexReq{
+			URI:           job.WorkUri,
+			KnowledgeName: job.KnowledgeBaseName,
+			Namespace:     ns,
+			DocumentsId:   job.DocumentsId,
+			FileName:      job.FileName,
+			Progress:      p.report,
+		})
+		if err != nil {
+			return err
+		}
+		job.ChunkCount = len(ids)
+		if err = update(ctx, job.JobId, g.Map{"chunk_count": job.ChunkCount}); err != nil {
+			return err
+		}
+	}
+
+	return svr.GenerateQA(ctx, &api.GenerateQAReq{
+		DocumentsId:   job.DocumentsId,
+		KnowledgeName: job.KnowledgeBaseName,
+		Namespace:     ns,
+		Progress:      p.report,
+	})
+}
+
+// keepLease 任务执行期间每隔 interval 续约一次；续约发现租约已不属于本实例时取消执行。
+// 返回的 stop 结束续约并报告租约是否已失效。数据库暂时不可用时继续重试，租约到期前恢复即可
+func keepLease(ctx context.Context, cancel context.CancelFunc, interval time.Duration, renew func(context.Context) (bool, error)) (stop func() (lost bool)) {
+	var (
+		expired  atomic.Bool
+		done     = make(chan struct{})
+		finished = make(chan struct{})
+	)
+	go func() {
+		defer close(finished)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				ok, err := renew(ctx)
+				if err != nil {
+					g.Log().Warningf(ctx, "[IndexJob] 续约失败，稍后重试: %v", err)
+					continue
+				}
+				if !ok {
+					expired.Store(true)
+					cancel()
+					return
+				}
+			}
+		}
+	}()
+	return func() bool {
+		close(done)
+		<-finished
+		return expired.Load()
+	}
+}
+
+// renewLease 延长本实例持有的任务租约，任务已不属于本实例时返回 false
+func renewLease(ctx context.Context, jobId string, ttl time.Duration) (bool, error) {
+	result, err := owned(ctx, jobId).Data(g.Map{"lease_until": gtime.Now().Add(ttl)}).Update()
+	if err != nil {
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	return n > 0, err
+}
+
+// owned 本实例正在执行的任务
+func owned(ctx context.Context, jobId string) *gdb.Model {
+	return dao.KnowledgeIndexJobs.Ctx(ctx).
+		Where("job_id", jobId).
+		Where("status", v1.JobStatusRunning).
+		Where("owner", instanceId)
+}
+
+// finish 写回执行结果并释放租约；任务已被其他实例接管时不写入，返回 false
+func finish(ctx context.Context, jobId string, data g.Map) bool {
+	data["owner"] = ""
+	data["lease_until"] = nil
+	result, err := owned(ctx, jobId).Data(data).Update()
+	if err != nil {
+		g.Log().Errorf(ctx, "[IndexJob] 更新任务失败: job_id=%s, err=%v", jobId, err)
+		return false
+	}
+	n, _ := result.RowsAffected()
+	return n > 0
+}
+
+func succeed(ctx context.Context, job *entity.KnowledgeIndexJobs) {
+	job.Status = v1.JobStatusSucceeded
+	job.Stage = v1.JobStageDone
+	job.Error = ""
+	if !finish(ctx, job.JobId, g.Map{
+		"status":      job.Status,
+		"stage":       job.Stage,
+		"error":       "",
+		"finished_at": gtime.Now(),
+	}) {
+		return
+	}
+	_ = knowledge.UpdateDocumentsStatus(ctx, job.DocumentsId, int(v1.StatusActive))
+	push(job)
+}
+
+// fail 未超过最大次数时按 retryDelay * 2^(attempts-1) 退避后重新排队
+func fail(ctx context.Context, job *entity.KnowledgeIndexJobs, cause error) {
+	job.Error = cause.Error()
+	if job.Attempts < job.MaxAttempts {
+		retryDelay := g.Cfg().MustGet(ctx, "indexJob.retryDelay", defaultRetryDelay).Duration()
+		if retryDelay <= 0 {
+			retryDelay = defaultRetryDelay
+		}
+		next := gtime.Now().Add(retryDelay << (job.Attempts - 1))
+		job.Status = v1.JobStatusQueued
+		job.NextRunAt = next
+		if !finish(ctx, job.JobId, g.Map{
+			"status":      job.Status,
+			"error":       job.Error,
+			"next_run_at": next,
+		}) {
+			return
+		}
+		push(job)
+		schedule(job.JobId, next)
+		return
+	}
+	job.Status = v1.JobStatusFailed
+	if !finish(ctx, job.JobId, g.Map{
+		"status":      job.Status,
+		"error":       job.Error,
+		"finished_at": gtime.Now(),
+	}) {
+		return
+	}
+	_ = knowledge.UpdateDocumentsStatus(ctx, job.DocumentsId, int(v1.StatusFailed))
+	push(job)
+}
+
+func update(ctx context.Context, jobId string, data g.Map) error {
+	_, err := dao.KnowledgeIndexJobs.Ctx(ctx).Where("job_id", jobId).Data(data).Update()
+	if err != nil {
+		g.Log().Errorf(ctx, "[IndexJob] 更新任务失败: job_id=%s, err=%v", jobId, err)
+	}
+	return err
+}
+
+// push 通过 WebSocket 向任务所属用户推送当前状态与进度
+func push(job *entity.KnowledgeIndexJobs) {
+	ws.SendToUserGlobal(job.UserUuid, g.Map{
+		"type": EventProgress,
+		"payload": g.Map{
+			"job_id":         job.JobId,
+			"documents_id":   job.DocumentsId,
+			"knowledge_name": job.KnowledgeBaseName,
+			"file_name":      job.FileName,
+			"status":         job.Status,
+			"stage":          job.Stage,
+			"done":           job.ProgressDone,
+			"total":          job.ProgressTotal,
+			"chunk_count":    job.ChunkCount,
+			"attempts":       job.Attempts,
+			"error":          job.Error,
+		},
+	})
+}
+
+// progress 将管线回调的阶段进度写入任务记录；阶段切换或阶段完成时立即落库，其余按 progressInterval 节流
+type progress struct {
+	ctx       context.Context
+	job       *entity.KnowledgeIndexJobs
+	mu        sync.Mutex
+	lastFlush time.Time
+}
+
+func (p *progress) report(stage string, done, total int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	changed := stage != p.job.Stage
+	p.job.Stage, p.job.ProgressDone, p.job.ProgressTotal = stage, done, total
+	if !changed && done < total && time.Since(p.lastFlush) < progressInterval {
+		return
+	}
+	p.lastFlush = time.Now()
+	_ = update(p.ctx, p.job.JobId, g.Map{
+		"stage":          stage,
+		"progress_done":  done,
+		"progress_total": total,
+	})
+	push(p.job)
+}
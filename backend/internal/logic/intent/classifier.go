@@ -0,0 +1,254 @@
+package intent
+
+import (
+	"backend/internal/dao"
+	"backend/internal/model/do"
+	"backend/internal/model/entity"
+	"backend/studyCoach/common"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino-ext/components/embedding/ark"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	defaultReloadInterval = 5 * time.Minute
+	embedBatch            = 32 // 训练时每次向量化的样例数
+)
+
+// centroid 某一意图全部样例向量的归一化均值
+type centroid struct {
+	intent string
+	vec    []float64
+}
+
+// classifier 最近质心分类器
+type classifier struct {
+	model     string
+	centroids []centroid
+	loadedAt  time.Time
+	version   int64 // 训练开始时的样例版本
+}
+
+var (
+	mu         sync.Mutex
+	current    *classifier
+	version    int64 // 样例版本，样例变更（Reset）时递增
+	trainGroup singleflight.Group
+)
+
+// Reset 样例变更后标记分类器需要重新训练，并清除路由缓存；新分类器就绪前继续使用旧分类器
+func Reset(ctx context.Context) {
+	mu.Lock()
+	version++
+	mu.Unlock()
+	if err := decisions.Clear(ctx); err != nil {
+		g.Log().Warningf(ctx, "[IntentRouter] 清除路由缓存失败: %v", err)
+	}
+}
+
+// getClassifier 返回已训练的分类器，样例变更或超过 router.reloadInterval 后重新训练以获取其他实例的样例变更。
+// 训练在锁外进行且同一时间只有一次；已有分类器时在后台训练并继续使用旧分类器，只有首次训练需要等待
+func getClassifier(ctx context.Context) (*classifier, error) {
+	interval := g.Cfg().MustGet(ctx, "router.reloadInterval", defaultReloadInterval).Duration()
+	mu.Lock()
+	c, v := current, version
+	mu.Unlock()
+	if c != nil && c.version == v && time.Since(c.loadedAt) < interval {
+		return c, nil
+	}
+	// 训练由多个请求共享，不随触发它的请求取消
+	ch := trainGroup.DoChan("train", func() (any, error) {
+		return retrain(context.WithoutCancel(ctx))
+	})
+	if c != nil {
+		return c, nil
+	}
+	select {
+	case r := <-ch:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.(*classifier), nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// retrain 训练并替换当前分类器；失败时继续使用旧分类器，直到下一个重新加载周期再重试
+func retrain(ctx context.Context) (*classifier, error) {
+	mu.Lock()
+	v := version
+	mu.Unlock()
+	c, err := train(ctx)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if err != nil {
+		if current == nil {
+			return nil, err
+		}
+		g.Log().Warningf(ctx, "[IntentRouter] 重新训练失败，继续使用旧分类器: %v", err)
+		retry := *current
+		retry.loadedAt, retry.version = time.Now(), v
+		current = &retry
+		return current, nil
+	}
+	c.version = v
+	if current != nil && current.version != v {
+		// 样例变更后、新分类器就绪前由旧分类器写入的路由缓存已过时
+		if err := decisions.Clear(ctx); err != nil {
+			g.Log().Warningf(ctx, "[IntentRouter] 清除路由缓存失败: %v", err)
+		}
+	}
+	current = c
+	return c, nil
+}
+
+func newEmbedder(ctx context.Context) (embedding.Embedder, string, error) {
+	cfg := g.Cfg()
+	model := cfg.MustGet(ctx, "embeddingArk.model").String()
+	if model == "" {
+		return nil, "", fmt.Errorf("config missing: embeddingArk.model")
+	}
+	apiType := ark.APITypeMultiModal
+	eb, err := ark.NewEmbedder(ctx, &ark.EmbeddingConfig{
+		APIKey:  cfg.MustGet(ctx, "embeddingArk.apiKey").String(),
+		BaseURL: cfg.MustGet(ctx, "embeddingArk.baseURL").String(),
+		Model:   model,
+		APIType: &apiType,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return eb, model, nil
+}
+
+// embed 向量化并归一化，调用计入 IntentRouter 节点的用量
+func embed(ctx context.Context, eb embedding.Embedder, texts []string) ([][]float64, error) {
+	vecs, err := eb.EmbedStrings(common.WithCallName(ctx, "IntentRouter", components.ComponentOfEmbedding), texts)
+	if err != nil {
+		return nil, err
+	}
+	if len(vecs) != len(texts) {
+		return nil, fmt.Errorf("向量数量与文本数量不一致: %d != %d", len(vecs), len(texts))
+	}
+	for _, v := range vecs {
+		normalize(v)
+	}
+	return vecs, nil
+}
+
+// train 读取样例，为缺少向量或向量模型已变更的样例补算向量，按意图计算质心
+func train(ctx context.Context) (*classifier, error) {
+	if err := seed(ctx); err != nil {
+		return nil, fmt.Errorf("写入内置样例失败: %w", err)
+	}
+	var rows []*entity.IntentExamples
+	if err := dao.IntentExamples.Ctx(ctx).OrderAsc(dao.IntentExamples.Columns().Id).Scan(&rows); err != nil {
+		return nil, fmt.Errorf("查询意图样例失败: %w", err)
+	}
+	eb, model, err := newEmbedder(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("创建向量模型失败: %w", err)
+	}
+	vecs := make(map[int64][]float64, len(rows))
+	var stale []*entity.IntentExamples
+	for _, row := range rows {
+		var v []float64
+		if row.EmbeddingModel == model && row.Embedding != "" && gjson.DecodeTo(row.Embedding, &v) == nil && len(v) > 0 {
+			vecs[row.Id] = v
+			continue
+		}
+		stale = append(stale, row)
+	}
+	for start := 0; start < len(stale); start += embedBatch {
+		batch := stale[start:min(start+embedBatch, len(stale))]
+		texts := make([]string, len(batch))
+		for i, row := range batch {
+			texts[i] = row.Text
+		}
+		embedded, err := embed(ctx, eb, texts)
+		if err != nil {
+			return nil, fmt.Errorf("样例向量化失败: %w", err)
+		}
+		for i, row := range batch {
+			vecs[row.Id] = embedded[i]
+			_, err = dao.IntentExamples.Ctx(ctx).
+				Where(dao.IntentExamples.Columns().Id, row.Id).
+				Data(do.IntentExamples{Embedding: gjson.MustEncodeString(embedded[i]), EmbeddingModel: model}).
+				Update()
+			if err != nil {
+				g.Log().Warningf(ctx, "[IntentRouter] 保存样例向量失败: id=%d, 错误: %v", row.Id, err)
+			}
+		}
+	}
+
+	sums := make(map[string][]float64)
+	for _, row := range rows {
+		v := vecs[row.Id]
+		sum, ok := sums[row.Intent]
+		if !ok {
+			sum = make([]float64, len(v))
+			sums[row.Intent] = sum
+		}
+		if len(sum) != len(v) {
+			return nil, fmt.Errorf("样例向量维度不一致: id=%d", row.Id)
+		}
+		for i := range v {
+			sum[i] += v[i]
+		}
+	}
+	c := &classifier{model: model, loadedAt: time.Now()}
+	for _, name := range Intents {
+		if sum, ok := sums[name]; ok {
+			normalize(sum)
+			c.centroids = append(c.centroids, centroid{intent: name, vec: sum})
+		}
+	}
+	if len(c.centroids) == 0 {
+		return nil, fmt.Errorf("没有可用的意图样例")
+	}
+	g.Log().Infof(ctx, "[IntentRouter] 分类器训练完成: 样例 %d 条，新计算向量 %d 条，模型 %s", len(rows), len(stale), model)
+	return c, nil
+}
+
+// scores 问题向量与各意图质心的余弦相似度，按相似度倒序
+func (c *classifier) scores(vec []float64) []Score {
+	list := make([]Score, 0, len(c.centroids))
+	for _, ct := range c.centroids {
+		list = append(list, Score{Intent: ct.intent, Score: dot(vec, ct.vec)})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Score > list[j].Score })
+	return list
+}
+
+func dot(a, b []float64) float64 {
+	var s float64
+	for i := 0; i < len(a) && i < len(b); i++ {
+		s += a[i] * b[i]
+	}
+	return s
+}
+
+func normalize(v []float64) {
+	var n float64
+	for _, x := range v {
+		n += x * x
+	}
+	if n = math.Sqrt(n); n == 0 {
+		return
+	}
+	for i := range v {
+		v[i] /= n
+	}
+}
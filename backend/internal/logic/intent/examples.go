@@ -0,0 +1,175 @@
+package intent
+
+import (
+	v1 "backend/api/intent/v1"
+	"backend/internal/dao"
+	"backend/internal/model/do"
+	"backend/internal/model/entity"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+const (
+	seedLockKey  = "intent:seed_lock"
+	seedLockTTL  = 30 * time.Second // 写入锁的过期时间，也是等待其他实例写入的上限
+	seedLockWait = 200 * time.Millisecond
+)
+
+// seedExamples 样例表为空时写入的内置样例
+var seedExamples = map[string][]string{
+	Emotion: {
+		"你好", "在吗", "今天好累啊", "我好焦虑，感觉学不完了", "考试没考好，心情很差",
+		"谢谢你，晚安", "陪我聊聊天吧", "我今天特别开心", "学不进去，好烦", "你是谁",
+	},
+	TaskStudy: {
+		"我想学 Go 语言", "帮我做一个学习计划", "之前的计划太难了我要换一个", "刚那个学完了接下来干嘛",
+		"这个代码报错了怎么改", "第三步的原理是什么", "解释一下什么是闭包", "怎么准备考研数学",
+		"给我出几道练习题", "帮我总结一下这一章的重点",
+	},
+	PlanModify: {
+		"修改一下这个计划", "在计划里加一个番茄钟", "删掉第三步", "更新计划内容", "把周末任务去掉",
+		"删除这个任务", "不要这个了", "移除这一步", "取消这个计划", "把每天的学习时间改成两小时",
+	},
+}
+
+func toExample(row *entity.IntentExamples) v1.IntentExample {
+	return v1.IntentExample{
+		Id:        row.Id,
+		Intent:    row.Intent,
+		Text:      row.Text,
+		CreatedBy: row.CreatedBy,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+func checkIntent(name string) error {
+	if !slices.Contains(Intents, name) {
+		return gerror.NewCode(gcode.New(400, fmt.Sprintf("未知的意图: %s（可选 %s）", name, strings.Join(Intents, "、")), nil))
+	}
+	return nil
+}
+
+// seed 样例表为空时写入内置样例。多个实例或并发请求可能同时发现空表，
+// 写入前先获取 Redis 锁并重新检查，未获得锁时等待持有者写完
+func seed(ctx context.Context) error {
+	if empty, err := examplesEmpty(ctx); err != nil || !empty {
+		return err
+	}
+	ttl := int64(seedLockTTL.Seconds())
+	deadline := time.Now().Add(seedLockTTL)
+	for {
+		v, err := g.Redis().Set(ctx, seedLockKey, 1, gredis.SetOption{TTLOption: gredis.TTLOption{EX: &ttl}, NX: true})
+		if err != nil {
+			return fmt.Errorf("获取样例写入锁失败: %w", err)
+		}
+		if !v.IsNil() {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("等待其他实例写入内置样例超时")
+		}
+		select {
+		case <-time.After(seedLockWait):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if empty, err := examplesEmpty(ctx); err != nil || !empty {
+			return err
+		}
+	}
+	defer func() {
+		if _, err := g.Redis().Del(ctx, seedLockKey); err != nil {
+			g.Log().Warningf(ctx, "[IntentRouter] 释放样例写入锁失败: %v", err)
+		}
+	}()
+	if empty, err := examplesEmpty(ctx); err != nil || !empty {
+		return err
+	}
+	data := make([]do.IntentExamples, 0)
+	for _, name := range Intents {
+		for _, text := range seedExamples[name] {
+			data = append(data, do.IntentExamples{Intent: name, Text: text})
+		}
+	}
+	if _, err := dao.IntentExamples.Ctx(ctx).Data(data).Insert(); err != nil {
+		return err
+	}
+	g.Log().Infof(ctx, "[IntentRouter] 已写入内置样例 %d 条", len(data))
+	return nil
+}
+
+func examplesEmpty(ctx context.Context) (bool, error) {
+	count, err := dao.IntentExamples.Ctx(ctx).Count()
+	return count == 0, err
+}
+
+// ListExamples 标注样例，intent 为空时返回全部
+func ListExamples(ctx context.Context, name string) ([]v1.IntentExample, error) {
+	if err := seed(ctx); err != nil {
+		return nil, fmt.Errorf("写入内置样例失败: %w", err)
+	}
+	m := dao.IntentExamples.Ctx(ctx).
+		Fields(dao.IntentExamples.Columns().Id, dao.IntentExamples.Columns().Intent, dao.IntentExamples.Columns().Text,
+			dao.IntentExamples.Columns().CreatedBy, dao.IntentExamples.Columns().CreatedAt).
+		OrderAsc(dao.IntentExamples.Columns().Id)
+	if name != "" {
+		m = m.Where(dao.IntentExamples.Columns().Intent, name)
+	}
+	var rows []*entity.IntentExamples
+	if err := m.Scan(&rows); err != nil {
+		return nil, fmt.Errorf("查询意图样例失败: %w", err)
+	}
+	list := make([]v1.IntentExample, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, toExample(row))
+	}
+	return list, nil
+}
+
+// CreateExample 新增标注样例，向量在下次训练时计算
+func CreateExample(ctx context.Context, username, name, text string) (*v1.IntentExample, error) {
+	if err := checkIntent(name); err != nil {
+		return nil, err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, gerror.NewCode(gcode.New(400, "参数错误：样例内容不能为空", nil))
+	}
+	if err := seed(ctx); err != nil {
+		return nil, fmt.Errorf("写入内置样例失败: %w", err)
+	}
+	id, err := dao.IntentExamples.Ctx(ctx).Data(do.IntentExamples{
+		Intent:    name,
+		Text:      text,
+		CreatedBy: username,
+	}).InsertAndGetId()
+	if err != nil {
+		g.Log().Errorf(ctx, "[IntentRouter] 保存样例失败: %v", err)
+		return nil, fmt.Errorf("保存意图样例失败: %w", err)
+	}
+	Reset(ctx)
+	example := toExample(&entity.IntentExamples{Id: id, Intent: name, Text: text, CreatedBy: username, CreatedAt: gtime.Now()})
+	return &example, nil
+}
+
+// DeleteExample 删除标注样例
+func DeleteExample(ctx context.Context, id int64) error {
+	res, err := dao.IntentExamples.Ctx(ctx).Where(dao.IntentExamples.Columns().Id, id).Delete()
+	if err != nil {
+		return fmt.Errorf("删除意图样例失败: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return gerror.NewCode(gcode.New(404, "意图样例不存在", nil))
+	}
+	Reset(ctx)
+	return nil
+}
@@ -0,0 +1,137 @@
+package intent
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcache"
+)
+
+// 学习模式的意图
+const (
+	Emotion    = "emotion"     // 情感与闲聊
+	TaskStudy  = "task_study"  // 学习任务与答疑
+	PlanModify = "plan_modify" // 修改、增加、删除已有计划
+)
+
+// Intents 全部意图
+var Intents = []string{Emotion, TaskStudy, PlanModify}
+
+// 路由结果来源
+const (
+	SourceCache     = "cache"
+	SourceEmbedding = "embedding"
+	SourceLLM       = "llm"
+	SourceDefault   = "default" // 向量与 LLM 都不可用时的默认意图
+)
+
+const (
+	defaultMinScore  = 0.45
+	defaultMinMargin = 0.03
+	defaultCacheTTL  = 10 * time.Minute
+	defaultCacheSize = 10000
+)
+
+// Score 问题与某一意图的相似度
+type Score struct {
+	Intent string
+	Score  float64
+}
+
+// Decision 路由结果
+type Decision struct {
+	Intent string
+	Source string
+	Scores []Score // 向量分类的相似度，倒序；未进行向量分类时为空
+}
+
+// Fallback 向量分类置信度不足时的 LLM 判断，返回意图
+type Fallback func(ctx context.Context) (string, error)
+
+// decisions 归一化问题 -> Decision
+var decisions = gcache.New(defaultCacheSize)
+
+// Route 判断问题的意图：先查缓存，再用向量最近质心分类，置信度不足时调用 fallback；
+// fallback 失败或返回未知意图时使用向量分类的最佳结果，都不可用时为 TaskStudy。
+// 只缓存置信的向量结果与 LLM 结果
+func Route(ctx context.Context, question string, fallback Fallback) Decision {
+	key := normalizeQuestion(question)
+	if key != "" {
+		if v, err := decisions.Get(ctx, key); err == nil && !v.IsNil() {
+			if d, ok := v.Val().(Decision); ok {
+				d.Source = SourceCache
+				return d
+			}
+		}
+	}
+
+	d, confident := classify(ctx, question)
+	if !confident && fallback != nil {
+		name, err := fallback(ctx)
+		if err == nil {
+			err = checkIntent(name)
+		}
+		if err == nil {
+			d.Intent, d.Source, confident = name, SourceLLM, true
+		} else {
+			g.Log().Warningf(ctx, "[IntentRouter] LLM 分支判断失败，使用向量分类结果: %v", err)
+		}
+	}
+	if d.Intent == "" {
+		d.Intent, d.Source = TaskStudy, SourceDefault
+	}
+	if confident && key != "" {
+		ttl := g.Cfg().MustGet(ctx, "router.cacheTTL", defaultCacheTTL).Duration()
+		if err := decisions.Set(ctx, key, d, ttl); err != nil {
+			g.Log().Warningf(ctx, "[IntentRouter] 写入路由缓存失败: %v", err)
+		}
+	}
+	return d
+}
+
+// Classify 只做向量分类，不查缓存也不调用 LLM，供调试阈值使用
+func Classify(ctx context.Context, question string) (Decision, bool) {
+	return classify(ctx, question)
+}
+
+// classify 向量最近质心分类：最高相似度不低于 router.minScore 且领先第二名不少于 router.minMargin 时视为置信
+func classify(ctx context.Context, question string) (Decision, bool) {
+	if !g.Cfg().MustGet(ctx, "router.enabled", true).Bool() {
+		return Decision{}, false
+	}
+	c, err := getClassifier(ctx)
+	if err != nil {
+		g.Log().Warningf(ctx, "[IntentRouter] 分类器不可用: %v", err)
+		return Decision{}, false
+	}
+	eb, _, err := newEmbedder(ctx)
+	if err != nil {
+		g.Log().Warningf(ctx, "[IntentRouter] 创建向量模型失败: %v", err)
+		return Decision{}, false
+	}
+	vecs, err := embed(ctx, eb, []string{question})
+	if err != nil {
+		g.Log().Warningf(ctx, "[IntentRouter] 问题向量化失败: %v", err)
+		return Decision{}, false
+	}
+	scores := c.scores(vecs[0])
+	d := Decision{Intent: scores[0].Intent, Source: SourceEmbedding, Scores: scores}
+	margin := scores[0].Score
+	if len(scores) > 1 {
+		margin -= scores[1].Score
+	}
+	minScore := g.Cfg().MustGet(ctx, "router.minScore", defaultMinScore).Float64()
+	minMargin := g.Cfg().MustGet(ctx, "router.minMargin", defaultMinMargin).Float64()
+	return d, scores[0].Score >= minScore && margin >= minMargin
+}
+
+// normalizeQuestion 缓存键：去掉首尾空白与标点、合并空白并转小写
+func normalizeQuestion(question string) string {
+	question = strings.ToLower(strings.Join(strings.Fields(question), " "))
+	return strings.TrimFunc(question, func(r rune) bool {
+		return unicode.IsPunct(r) || unicode.IsSpace(r) || unicode.IsSymbol(r)
+	})
+}
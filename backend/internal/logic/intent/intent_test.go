@@ -0,0 +1,133 @@
+package intent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+)
+
+// useFakeRouter 用本地 Ark 多模态向量接口替代真实模型：问题文本按 vectors 返回向量；
+// 并以三个正交质心替换当前分类器，避免训练时访问数据库
+func useFakeRouter(t *testing.T, vectors map[string][]float32) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Input []struct {
+				Text string `json:"text"`
+			} `json:"input"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		vec, ok := vectors[req.Input[0].Text]
+		if !ok {
+			http.Error(w, `{"error":{"message":"unknown text"}}`, http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"data": map[string]any{"embedding": vec}})
+	}))
+	t.Cleanup(srv.Close)
+
+	adapter, err := gcfg.NewAdapterContent("embeddingArk:\n  model: fake\n  apiKey: test\n  baseURL: " + srv.URL + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+
+	mu.Lock()
+	previous := current
+	current = &classifier{
+		model: "fake",
+		centroids: []centroid{
+			{intent: Emotion, vec: []float64{1, 0, 0}},
+			{intent: TaskStudy, vec: []float64{0, 1, 0}},
+			{intent: PlanModify, vec: []float64{0, 0, 1}},
+		},
+		loadedAt: time.Now(),
+		version:  version,
+	}
+	mu.Unlock()
+	t.Cleanup(func() {
+		g.Cfg().SetAdapter(original)
+		mu.Lock()
+		current = previous
+		mu.Unlock()
+		_ = decisions.Clear(context.Background())
+	})
+}
+
+func TestRouteNearestCentroid(t *testing.T) {
+	// 与某一质心明显最近时直接采用向量分类，不调用 LLM，并按归一化问题缓存
+	useFakeRouter(t, map[string][]float32{"今天好难过": {0.9, 0.1, 0}})
+	ctx := context.Background()
+	noLLM := func(ctx context.Context) (string, error) {
+		t.Fatal("置信的向量分类不应调用 LLM")
+		return "", nil
+	}
+
+	d := Route(ctx, "今天好难过", noLLM)
+	if d.Intent != Emotion || d.Source != SourceEmbedding {
+		t.Fatalf("应按向量分类为 %s，实际 %+v", Emotion, d)
+	}
+	if len(d.Scores) != 3 || d.Scores[0].Intent != Emotion || d.Scores[0].Score < d.Scores[1].Score {
+		t.Fatalf("相似度应按倒序排列，实际 %+v", d.Scores)
+	}
+
+	d = Route(ctx, "  今天好难过！ ", noLLM)
+	if d.Intent != Emotion || d.Source != SourceCache {
+		t.Fatalf("首尾空白与标点不同的同一问题应命中缓存，实际 %+v", d)
+	}
+}
+
+func TestRouteLowConfidenceFallback(t *testing.T) {
+	// 最高两项相似度过于接近时调用 LLM，采用其返回的已知意图
+	useFakeRouter(t, map[string][]float32{
+		"帮我看看这个": {0.6, 0.58, 0},
+		"随便聊聊计划": {0.6, 0.58, 0},
+	})
+	ctx := context.Background()
+
+	called := 0
+	d := Route(ctx, "帮我看看这个", func(ctx context.Context) (string, error) {
+		called++
+		return PlanModify, nil
+	})
+	if called != 1 || d.Intent != PlanModify || d.Source != SourceLLM {
+		t.Fatalf("低置信时应采用 LLM 结果，实际 %+v（调用 %d 次）", d, called)
+	}
+
+	// LLM 返回未注册的意图或出错时不采用，改用向量分类的最佳结果且不缓存
+	for _, fallback := range []Fallback{
+		func(ctx context.Context) (string, error) { return "ChatLambda", nil },
+		func(ctx context.Context) (string, error) { return "", errors.New("timeout") },
+	} {
+		d = Route(ctx, "随便聊聊计划", fallback)
+		if d.Intent != Emotion || d.Source != SourceEmbedding {
+			t.Fatalf("LLM 结果不可用时应使用向量分类的最佳结果，实际 %+v", d)
+		}
+	}
+}
+
+func TestRouteWithoutClassifier(t *testing.T) {
+	// 向量模型不可用且 LLM 失败时使用默认意图
+	useFakeRouter(t, map[string][]float32{})
+	d := Route(context.Background(), "向量化会失败的问题", func(ctx context.Context) (string, error) {
+		return "", errors.New("unavailable")
+	})
+	if d.Intent != TaskStudy || d.Source != SourceDefault {
+		t.Fatalf("应回退到默认意图 %s，实际 %+v", TaskStudy, d)
+	}
+}
+
+func TestNormalizeQuestion(t *testing.T) {
+	if a, b := normalizeQuestion("  Hello   World? "), normalizeQuestion("hello world"); a != b {
+		t.Fatalf("归一化结果不一致: %q != %q", a, b)
+	}
+}
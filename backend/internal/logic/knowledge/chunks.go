@@ -7,6 +7,7 @@ import (
 	"backend/studyCoach/common"
 	"context"
 
+	"github.com/gogf/gf/v2/database/gdb"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/google/uuid"
 )
@@ -111,18 +112,26 @@ func GetChunkById(ctx context.Context, id int64) (chunk entity.KnowledgeChunks,
 	return
 }
 
-// DeleteChunkById 根据ID软删除知识块
-func DeleteChunkById(ctx context.Context, id int64) error {
+// DeleteChunkById 根据ID软删除知识块，向量库仅删除 userUUID 所属命名空间内的数据
+func DeleteChunkById(ctx context.Context, userUUID string, id int64) error {
 	// 先获取 chunk_id
 	chunk, err := GetChunkById(ctx, id)
 	if err != nil {
 		return err
 	}
+	doc, err := GetDocumentById(ctx, chunk.KnowledgeDocId)
+	if err != nil {
+		return err
+	}
+	ns, err := GetNamespace(ctx, userUUID, doc.KnowledgeBaseName)
+	if err != nil {
+		return err
+	}
 
 	// 从向量库删除
 	cfg, err := common.BuildVectorConfig(ctx)
 	if err == nil && cfg != nil && chunk.ChunkId != "" {
-		if err := cfg.DeleteDocument(ctx, chunk.ChunkId); err != nil {
+		if err := cfg.DeleteDocument(ctx, ns, chunk.ChunkId); err != nil {
 			g.Log().Warningf(ctx, "从向量库删除 chunk 失败: chunk_id=%s, 错误: %v", chunk.ChunkId, err)
 		}
 	}
@@ -132,6 +141,27 @@ func DeleteChunkById(ctx context.Context, id int64) error {
 	return err
 }
 
+// ClearDocumentChunks 清理文档已写入的全部切片（MySQL + 向量库），用于索引任务重试前回滚半成品
+func ClearDocumentChunks(ctx context.Context, ns common.Namespace, documentsId int64) (int, error) {
+	chunks, err := GetAllChunksByDocId(ctx, documentsId, "chunk_id")
+	if err != nil {
+		return 0, err
+	}
+	if len(chunks) == 0 {
+		return 0, nil
+	}
+	cfg, err := common.BuildVectorConfig(ctx)
+	if err == nil && cfg != nil {
+		for _, chunk := range chunks {
+			if err := cfg.DeleteDocument(ctx, ns, chunk.ChunkId); err != nil {
+				g.Log().Warningf(ctx, "从向量库删除 chunk 失败: chunk_id=%s, 错误: %v", chunk.ChunkId, err)
+			}
+		}
+	}
+	_, err = dao.KnowledgeChunks.Ctx(ctx).Where("knowledge_doc_id", documentsId).Delete()
+	return len(chunks), err
+}
+
 // UpdateChunkByIds 根据ID更新知识块（内容或状态；status 可为 0 表示禁用）
 func UpdateChunkByIds(ctx context.Context, ids []int64, data entity.KnowledgeChunks) error {
 	if len(ids) == 0 {
@@ -164,6 +194,26 @@ func UpdateChunkContentById(ctx context.Context, id int64, content string) error
 	return err
 }
 
+// TrackChunkIndex 执行单个切片的重新索引，进度记录在切片自身的 index_status 上：处理中为 Indexing，成功为 Active，失败为 Failed。
+// 所属文档的状态由完整索引任务维护，这里不做修改，避免覆盖同一文档上正在执行的索引任务。
+func TrackChunkIndex(ctx context.Context, chunkId string, reindex func() error) (err error) {
+	setStatus := func(status v1.Status) {
+		_, e := dao.KnowledgeChunks.Ctx(ctx).Where("chunk_id", chunkId).Data(g.Map{"index_status": int(status)}).Update()
+		if e != nil {
+			g.Log().Errorf(ctx, "更新切片索引状态失败: chunk_id=%s, 错误: %v", chunkId, e)
+		}
+	}
+	setStatus(v1.StatusIndexing)
+	defer func() {
+		if err != nil {
+			setStatus(v1.StatusFailed)
+			return
+		}
+		setStatus(v1.StatusActive)
+	}()
+	return reindex()
+}
+
 // GetAllChunksByDocId gets all chunks by document id
 func GetAllChunksByDocId(ctx context.Context, docId int64, fields ...string) (list []entity.KnowledgeChunks, err error) {
 	model := dao.KnowledgeChunks.Ctx(ctx).Where("knowledge_doc_id", docId)
@@ -175,3 +225,60 @@ func GetAllChunksByDocId(ctx context.Context, docId int64, fields ...string) (li
 	err = model.Scan(&list)
 	return
 }
+
+// namespaceDocIds 命名空间内文档 ID 的子查询：按知识库 ID 匹配，且知识库须属于 ns.UserUUID。
+// 知识库名称只在用户内唯一，不能按名称查询，否则会混入其他用户同名知识库的文档
+func namespaceDocIds(ctx context.Context, ns common.Namespace) *gdb.Model {
+	kbIds := dao.KnowledgeBase.Ctx(ctx).Fields(dao.KnowledgeBase.Columns().Id).
+		Where(dao.KnowledgeBase.Columns().Id, ns.KnowledgeBaseId).
+		Where(dao.KnowledgeBase.Columns().UserUuid, ns.UserUUID)
+	return dao.KnowledgeDocuments.Ctx(ctx).Fields(dao.KnowledgeDocuments.Columns().Id).
+		WhereIn(dao.KnowledgeDocuments.Columns().KnowledgeBaseId, kbIds)
+}
+
+// GetDisabledChunkIds 获取知识库下所有被禁用切片的 chunk_id（即向量库文档 ID），检索时作为排除条件
+func GetDisabledChunkIds(ctx context.Context, ns common.Namespace) ([]string, error) {
+	values, err := dao.KnowledgeChunks.Ctx(ctx).
+		Fields("chunk_id").
+		Where("status", ChunkStatusDisabled).
+		WhereIn("knowledge_doc_id", namespaceDocIds(ctx, ns)).
+		Array()
+	if err != nil {
+		g.Log().Errorf(ctx, "获取禁用切片失败: namespace=%s, err=%v", ns, err)
+		return nil, err
+	}
+	ids := make([]string, 0, len(values))
+	for _, v := range values {
+		if s := v.String(); s != "" {
+			ids = append(ids, s)
+		}
+	}
+	return ids, nil
+}
+
+// GetSearchableChunks 获取命名空间内知识库的所有启用切片（chunk_id、content、ext），供本地关键词索引使用
+func GetSearchableChunks(ctx context.Context, ns common.Namespace) (list []entity.KnowledgeChunks, err error) {
+	err = dao.KnowledgeChunks.Ctx(ctx).
+		Fields("chunk_id", "content", "ext").
+		Where("status", ChunkStatusActive).
+		WhereIn("knowledge_doc_id", namespaceDocIds(ctx, ns)).
+		OrderAsc("id").
+		Scan(&list)
+	if err != nil {
+		g.Log().Errorf(ctx, "获取知识库切片失败: namespace=%s, err=%v", ns, err)
+	}
+	return
+}
+
+// GetChunksVersion 知识库切片版本标识（数量 + 最大 ID + 最近更新时间），切片增删改或启停后随之变化
+func GetChunksVersion(ctx context.Context, ns common.Namespace) (string, error) {
+	one, err := dao.KnowledgeChunks.Ctx(ctx).
+		Fields("COUNT(1) AS n, MAX(id) AS max_id, MAX(updated_at) AS updated").
+		WhereIn("knowledge_doc_id", namespaceDocIds(ctx, ns)).
+		One()
+	if err != nil {
+		g.Log().Errorf(ctx, "获取知识库切片版本失败: namespace=%s, err=%v", ns, err)
+		return "", err
+	}
+	return one["n"].String() + "/" + one["max_id"].String() + "/" + one["updated"].String(), nil
+}
@@ -21,6 +21,7 @@ func SaveDocumentsInfo(ctx context.Context, documents entity.KnowledgeDocuments)
 	// 构造插入数据，排除Id字段让数据库自动生成
 	data := g.Map{
 		"knowledge_base_name": documents.KnowledgeBaseName,
+		"knowledge_base_id":   documents.KnowledgeBaseId,
 		"file_name":           documents.FileName,
 		"status":              documents.Status,
 	}
@@ -106,9 +107,17 @@ func GetDocumentsList(ctx context.Context, where entity.KnowledgeDocuments, page
 	return documents, total, nil
 }
 
-// DeleteDocument 删除文档及其相关数据（MySQL + 向量库）
-func DeleteDocument(ctx context.Context, id int64) error {
+// DeleteDocument 删除文档及其相关数据（MySQL + 向量库），向量库仅删除 userUUID 所属命名空间内的数据
+func DeleteDocument(ctx context.Context, userUUID string, id int64) error {
 	g.Log().Debugf(ctx, "删除文档: ID=%d", id)
+	doc, err := GetDocumentById(ctx, id)
+	if err != nil {
+		return err
+	}
+	ns, err := GetNamespace(ctx, userUUID, doc.KnowledgeBaseName)
+	if err != nil {
+		return err
+	}
 
 	return dao.KnowledgeDocuments.Ctx(ctx).Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
 		// 获取该文档的所有 chunk_id
@@ -123,7 +132,7 @@ func DeleteDocument(ctx context.Context, id int64) error {
 		cfg, err := common.BuildVectorConfig(ctx)
 		if err == nil && cfg != nil {
 			for _, chunk := range chunks {
-				if err := cfg.DeleteDocument(ctx, chunk.ChunkId); err != nil {
+				if err := cfg.DeleteDocument(ctx, ns, chunk.ChunkId); err != nil {
 					g.Log().Warningf(ctx, "从向量库删除 chunk 失败: chunk_id=%s, 错误: %v", chunk.ChunkId, err)
 				}
 			}
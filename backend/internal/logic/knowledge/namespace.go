@@ -0,0 +1,92 @@
+package knowledge
+
+import (
+	"backend/internal/dao"
+	"backend/internal/model/entity"
+	"backend/studyCoach/common"
+	"context"
+	"fmt"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// GetNamespace 根据用户与知识库名称获取向量库命名空间（知识库 ID + 用户 UUID）。
+func GetNamespace(ctx context.Context, userUUID string, kbName string) (ns common.Namespace, err error) {
+	var kb entity.KnowledgeBase
+	err = dao.KnowledgeBase.Ctx(ctx).
+		Where(dao.KnowledgeBase.Columns().Name, kbName).
+		Where(dao.KnowledgeBase.Columns().UserUuid, userUUID).
+		Scan(&kb)
+	if err != nil {
+		return ns, err
+	}
+	if kb.Id == 0 {
+		return ns, gerror.NewCode(gcode.CodeNotAuthorized, "无权访问该知识库或知识库不存在")
+	}
+	return common.Namespace{KnowledgeBaseId: kb.Id, UserUUID: kb.UserUuid}, nil
+}
+
+// GetNamespaceByName 仅按知识库名称获取命名空间，用于定时任务等没有用户上下文的场景。
+// 知识库名称只在用户内唯一，若存在多个同名知识库则无法确定归属，返回错误。
+func GetNamespaceByName(ctx context.Context, kbName string) (ns common.Namespace, err error) {
+	var list []entity.KnowledgeBase
+	err = dao.KnowledgeBase.Ctx(ctx).Where(dao.KnowledgeBase.Columns().Name, kbName).Scan(&list)
+	if err != nil {
+		return ns, err
+	}
+	switch len(list) {
+	case 0:
+		return ns, gerror.NewCode(gcode.CodeNotFound, "知识库不存在")
+	case 1:
+		return common.Namespace{KnowledgeBaseId: list[0].Id, UserUUID: list[0].UserUuid}, nil
+	default:
+		return ns, fmt.Errorf("存在 %d 个同名知识库「%s」，无法确定所属用户", len(list), kbName)
+	}
+}
+
+// BackfillVectorNamespace 为升级前写入的向量数据回填知识库 ID 与用户 UUID。
+// 旧数据只带 _knowledge_name，同名知识库属于多个用户时无法判断归属，跳过并记录日志，需人工处理。
+func BackfillVectorNamespace(ctx context.Context) error {
+	cfg, err := common.BuildVectorConfig(ctx)
+	if err != nil {
+		return err
+	}
+	if err = cfg.EnsureNamespaceSchema(ctx); err != nil {
+		return err
+	}
+	var list []entity.KnowledgeBase
+	if err = dao.KnowledgeBase.Ctx(ctx).OrderAsc("id").Scan(&list); err != nil {
+		return err
+	}
+	byName := make(map[string][]entity.KnowledgeBase, len(list))
+	for _, kb := range list {
+		byName[kb.Name] = append(byName[kb.Name], kb)
+	}
+	var done, skipped, failed int
+	for name, kbs := range byName {
+		if len(kbs) > 1 {
+			skipped++
+			g.Log().Warningf(ctx, "BackfillVectorNamespace: 知识库「%s」存在 %d 个同名记录，跳过", name, len(kbs))
+			continue
+		}
+		ns := common.Namespace{KnowledgeBaseId: kbs[0].Id, UserUUID: kbs[0].UserUuid}
+		if !ns.IsValid() {
+			skipped++
+			g.Log().Warningf(ctx, "BackfillVectorNamespace: 知识库「%s」缺少所属用户，跳过", name)
+			continue
+		}
+		if err := cfg.BackfillNamespace(ctx, name, ns); err != nil {
+			failed++
+			g.Log().Errorf(ctx, "BackfillVectorNamespace: 知识库「%s」回填失败: %v", name, err)
+			continue
+		}
+		done++
+	}
+	g.Log().Infof(ctx, "BackfillVectorNamespace 完成: 成功=%d, 跳过=%d, 失败=%d", done, skipped, failed)
+	if failed > 0 {
+		return fmt.Errorf("%d 个知识库回填失败", failed)
+	}
+	return nil
+}
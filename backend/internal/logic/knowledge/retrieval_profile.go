@@ -0,0 +1,42 @@
+package knowledge
+
+import (
+	v1 "backend/api/rag/v1"
+	"backend/internal/dao"
+	"context"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// EncodeRetrievalProfile 序列化检索配置用于落库，nil 返回空串（表示使用默认）
+func EncodeRetrievalProfile(p *v1.RetrievalProfile) (string, error) {
+	if p == nil {
+		return "", nil
+	}
+	s, err := sonic.MarshalString(p)
+	if err != nil {
+		return "", fmt.Errorf("检索配置序列化失败: %w", err)
+	}
+	return s, nil
+}
+
+// GetRetrievalProfile 读取知识库保存的检索配置，未配置时返回 nil
+func GetRetrievalProfile(ctx context.Context, kbId int64) (*v1.RetrievalProfile, error) {
+	value, err := dao.KnowledgeBase.Ctx(ctx).
+		Where(dao.KnowledgeBase.Columns().Id, kbId).
+		Value(dao.KnowledgeBase.Columns().RetrievalProfile)
+	if err != nil {
+		return nil, err
+	}
+	if value.IsEmpty() {
+		return nil, nil
+	}
+	var p v1.RetrievalProfile
+	if err = sonic.UnmarshalString(value.String(), &p); err != nil {
+		g.Log().Warningf(ctx, "知识库检索配置解析失败, kb_id=%d, err=%v", kbId, err)
+		return nil, nil
+	}
+	return &p, nil
+}
@@ -0,0 +1,88 @@
+package middleware
+
+import (
+	v1 "backend/api/openai/v1"
+	"backend/internal/logic/apikey"
+	"backend/internal/logic/usage"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// APIKey OpenAI 兼容接口的认证：Authorization: Bearer <API Key>，通过后将所属用户写入 ctx
+func APIKey(r *ghttp.Request) {
+	parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") || strings.TrimSpace(parts[1]) == "" {
+		r.SetError(gerror.NewCode(gcode.New(http.StatusUnauthorized, "未授权：缺少 API Key", nil)))
+		return
+	}
+	userUUID, err := apikey.Authenticate(r.Context(), strings.TrimSpace(parts[1]))
+	if err != nil {
+		r.SetError(err)
+		return
+	}
+	r.SetCtx(apikey.WithUser(r.Context(), userUUID))
+	r.Middleware.Next()
+}
+
+// OpenAIResponse 以 OpenAI 格式输出响应：错误为 {"error": {...}} 并使用对应的 HTTP 状态码；流式响应由控制器直接写出
+func OpenAIResponse(r *ghttp.Request) {
+	r.Middleware.Next()
+	if r.Response.BufferLength() > 0 || r.Response.BytesWritten() > 0 {
+		return
+	}
+	err := r.GetError()
+	if err == nil {
+		r.Response.WriteJson(r.GetHandlerResponse())
+		return
+	}
+	status := r.Response.Status
+	if status < http.StatusBadRequest {
+		status = http.StatusInternalServerError
+		switch code := gerror.Code(err).Code(); {
+		case code >= http.StatusBadRequest && code < http.StatusInternalServerError:
+			status = code
+		case code == gcode.CodeValidationFailed.Code(), code == gcode.CodeInvalidParameter.Code(), code == gcode.CodeMissingParameter.Code():
+			status = http.StatusBadRequest
+		case code == gcode.CodeNotAuthorized.Code():
+			status = http.StatusForbidden
+		case code == gcode.CodeNotFound.Code():
+			status = http.StatusNotFound
+		}
+	}
+	detail := v1.ErrorDetail{Message: err.Error()}
+	switch status {
+	case http.StatusBadRequest:
+		detail.Type = "invalid_request_error"
+	case http.StatusUnauthorized:
+		detail.Type = "authentication_error"
+		detail.Code = errorCode("invalid_api_key")
+	case http.StatusForbidden:
+		detail.Type = "permission_error"
+	case http.StatusNotFound:
+		detail.Type = "not_found_error"
+	case http.StatusTooManyRequests:
+		detail.Type = "rate_limit_error"
+		detail.Code = errorCode("insufficient_quota")
+		if quota, ok := gerror.Code(err).Detail().(usage.QuotaExceeded); ok {
+			r.Response.Header().Set("Retry-After", strconv.Itoa(int(time.Until(quota.ResetAt).Seconds())+1))
+		}
+	default:
+		// 内部错误脱敏，不返回具体错误信息
+		g.Log().Error(r.Context(), "[OpenAI] 请求失败：", err)
+		detail.Type = "server_error"
+		detail.Message = "服务暂时不可用，请稍后重试"
+	}
+	r.Response.WriteHeader(status)
+	r.Response.WriteJson(v1.ErrorBody{Error: detail})
+}
+
+func errorCode(code string) *string {
+	return &code
+}
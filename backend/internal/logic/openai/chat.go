@@ -0,0 +1,164 @@
+package openai
+
+import (
+	aichatv1 "backend/api/ai_chat/v1"
+	v1 "backend/api/openai/v1"
+	"backend/studyCoach/api"
+	"backend/studyCoach/common"
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/util/gconv"
+	"github.com/google/uuid"
+)
+
+// NewCompletionId 生成本次对话的 ID，同时作为模型调用的会话 ID
+func NewCompletionId() string {
+	return "chatcmpl-" + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+// Start 按 OpenAI 请求调用 NormalChat 或 CoachChat：最后一条 user 消息为本轮问题，之前的消息作为对话历史，不读写服务端会话。
+// 检索、联网与工具调用与 /chat 相同，知识库命名空间与用量归属由调用方写入 ctx
+func Start(ctx context.Context, id string, req *v1.ChatCompletionsReq) (*schema.StreamReader[*schema.Message], []*schema.Document, error) {
+	chatReq, history, err := convertRequest(id, req)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx = common.WithChatHistory(ctx, history)
+	if chatReq.IsStudyMode {
+		return api.ChatAiModel(ctx, chatReq)
+	}
+	return api.ChatNormalModel(ctx, chatReq)
+}
+
+// convertRequest 转换为 /chat 的请求与对话历史
+func convertRequest(id string, req *v1.ChatCompletionsReq) (*aichatv1.AiChatReq, []*schema.Message, error) {
+	chatReq := &aichatv1.AiChatReq{
+		ID: id,
+		ChatOptions: aichatv1.ChatOptions{
+			KnowledgeName:    req.KnowledgeName,
+			TopK:             req.TopK,
+			Score:            req.Score,
+			IsNetwork:        req.IsNetwork,
+			IsStudyMode:      req.IsStudyMode,
+			RetrievalProfile: req.RetrievalProfile,
+		},
+	}
+	if err := applyModel(req.Model, &chatReq.ChatOptions); err != nil {
+		return nil, nil, err
+	}
+	last := len(req.Messages) - 1
+	if last < 0 || req.Messages[last].Role != string(schema.User) {
+		return nil, nil, gerror.NewCode(gcode.New(400, "messages 的最后一条必须是 user 消息", nil))
+	}
+	text, parts, err := parseContent(req.Messages[last].Content)
+	if err != nil {
+		return nil, nil, err
+	}
+	chatReq.Question = text
+	if hasImage(parts) {
+		chatReq.MultiContentRaw, _ = json.Marshal(parts)
+	}
+
+	var (
+		history []*schema.Message
+		system  []string
+	)
+	for _, m := range req.Messages[:last] {
+		text, parts, err := parseContent(m.Content)
+		if err != nil {
+			return nil, nil, err
+		}
+		switch m.Role {
+		case "system", "developer":
+			// 调用方的系统提示合并为一条，放在历史最前面，不替换 StudyCoach 自身的提示词
+			if text != "" {
+				system = append(system, text)
+			}
+		case string(schema.User):
+			msg := schema.UserMessage(text)
+			if hasImage(parts) {
+				msg = multiContentMessage(parts)
+			}
+			history = append(history, msg)
+		case string(schema.Assistant):
+			if text != "" {
+				history = append(history, schema.AssistantMessage(strings.TrimSpace(common.StripCitations(text)), nil))
+			}
+		}
+		// tool 消息对应调用方自己的工具，与 StudyCoach 的工具无关，忽略
+	}
+	if len(system) > 0 {
+		history = append([]*schema.Message{schema.SystemMessage(strings.Join(system, "\n\n"))}, history...)
+	}
+	return chatReq, history, nil
+}
+
+// parseContent 解析 content：字符串或分片数组，返回拼接后的文本与 /chat 格式的分片
+func parseContent(content any) (string, []aichatv1.MessagePart, error) {
+	var items []v1.ContentPart
+	switch c := content.(type) {
+	case nil:
+		return "", nil, nil
+	case string:
+		return c, []aichatv1.MessagePart{{Type: "text", Text: c}}, nil
+	case []any:
+		if err := gconv.Structs(c, &items); err != nil {
+			return "", nil, gerror.NewCode(gcode.New(400, "content 分片格式错误", nil))
+		}
+	default:
+		return "", nil, gerror.NewCode(gcode.New(400, "content 必须是字符串或分片数组", nil))
+	}
+	var (
+		texts []string
+		parts []aichatv1.MessagePart
+	)
+	for _, item := range items {
+		switch item.Type {
+		case "text":
+			texts = append(texts, item.Text)
+			parts = append(parts, aichatv1.MessagePart{Type: "text", Text: item.Text})
+		case "image_url":
+			if item.ImageURL == nil || item.ImageURL.URL == "" {
+				return "", nil, gerror.NewCode(gcode.New(400, "image_url 分片缺少 url", nil))
+			}
+			parts = append(parts, aichatv1.MessagePart{Type: "image_url", ImageURL: item.ImageURL.URL})
+		default:
+			return "", nil, gerror.NewCode(gcode.New(400, "不支持的 content 分片类型："+item.Type, nil))
+		}
+	}
+	return strings.Join(texts, "\n"), parts, nil
+}
+
+func hasImage(parts []aichatv1.MessagePart) bool {
+	for _, p := range parts {
+		if p.Type == "image_url" {
+			return true
+		}
+	}
+	return false
+}
+
+// multiContentMessage 历史中带图片的用户消息
+func multiContentMessage(parts []aichatv1.MessagePart) *schema.Message {
+	msg := &schema.Message{Role: schema.User}
+	for _, p := range parts {
+		if p.Type == "image_url" {
+			url := p.ImageURL
+			msg.UserInputMultiContent = append(msg.UserInputMultiContent, schema.MessageInputPart{
+				Type:  schema.ChatMessagePartTypeImageURL,
+				Image: &schema.MessageInputImage{MessagePartCommon: schema.MessagePartCommon{URL: &url}},
+			})
+			continue
+		}
+		msg.UserInputMultiContent = append(msg.UserInputMultiContent, schema.MessageInputPart{
+			Type: schema.ChatMessagePartTypeText,
+			Text: p.Text,
+		})
+	}
+	return msg
+}
@@ -0,0 +1,45 @@
+package openai
+
+import (
+	aichatv1 "backend/api/ai_chat/v1"
+	v1 "backend/api/openai/v1"
+	"fmt"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// 对外的模型名称，分别对应 NormalChat、开启深度思考的 NormalChat 与 CoachChat
+const (
+	ModelNormal   = "studycoach"
+	ModelThinking = "studycoach-thinking"
+	ModelCoach    = "studycoach-coach"
+)
+
+var models = []string{ModelNormal, ModelThinking, ModelCoach}
+
+// modelsCreated /v1/models 中的 created，固定值
+const modelsCreated = 1735689600
+
+// Models /v1/models 返回的模型列表
+func Models() []v1.Model {
+	list := make([]v1.Model, 0, len(models))
+	for _, id := range models {
+		list = append(list, v1.Model{Id: id, Object: "model", Created: modelsCreated, OwnedBy: "studycoach"})
+	}
+	return list
+}
+
+// applyModel 按模型名称设置对话模式；is_study_mode 扩展字段可直接指定 CoachChat
+func applyModel(model string, opts *aichatv1.ChatOptions) error {
+	switch model {
+	case ModelNormal:
+	case ModelThinking:
+		opts.IsDeepThinking = true
+	case ModelCoach:
+		opts.IsStudyMode = true
+	default:
+		return gerror.NewCode(gcode.New(404, fmt.Sprintf("模型 %s 不存在，可选：%s、%s、%s", model, ModelNormal, ModelThinking, ModelCoach), nil))
+	}
+	return nil
+}
@@ -0,0 +1,225 @@
+package openai
+
+import (
+	v1 "backend/api/openai/v1"
+	"backend/studyCoach/common"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+const (
+	finishStop     = "stop"
+	objectChunk    = "chat.completion.chunk"
+	objectResponse = "chat.completion"
+)
+
+// reply 读取模型流时累积的回答
+type reply struct {
+	content   strings.Builder
+	reasoning strings.Builder
+	usage     *schema.TokenUsage
+	citations *common.CitationFilter
+}
+
+// readStream 逐个分片取出正文与思考的增量并回调 onDelta；纯工具调用的分片不输出。
+// 正文中指向不存在来源的 [n] 在这里删除，与 /chat 一致
+func readStream(sr *schema.StreamReader[*schema.Message], docs []*schema.Document, onDelta func(content, reasoning string)) (*reply, error) {
+	defer sr.Close()
+	rp := &reply{}
+	if len(docs) > 0 {
+		rp.citations = common.NewCitationFilter(docs)
+	}
+	var fullContent, fullReasoning string
+	for {
+		chunk, err := sr.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return rp, err
+		}
+		if chunk.ResponseMeta != nil && chunk.ResponseMeta.Usage != nil {
+			rp.usage = chunk.ResponseMeta.Usage
+		}
+		if len(chunk.ToolCalls) > 0 && chunk.Content == "" {
+			// 工具调用之后是新一轮模型输出，重置增量基准
+			fullContent, fullReasoning = "", ""
+			continue
+		}
+		var content, reasoning string
+		if chunk.Content != "" {
+			content = common.StreamDelta(&fullContent, chunk.Content)
+		}
+		if chunk.ReasoningContent != "" {
+			reasoning = common.StreamDelta(&fullReasoning, chunk.ReasoningContent)
+		}
+		if rp.citations != nil {
+			content = rp.citations.Push(content)
+		}
+		rp.emit(content, reasoning, onDelta)
+	}
+	if rp.citations != nil {
+		rp.emit(rp.citations.Flush(), "", onDelta)
+	}
+	return rp, nil
+}
+
+func (rp *reply) emit(content, reasoning string, onDelta func(content, reasoning string)) {
+	if content == "" && reasoning == "" {
+		return
+	}
+	rp.content.WriteString(content)
+	rp.reasoning.WriteString(reasoning)
+	if onDelta != nil {
+		onDelta(content, reasoning)
+	}
+}
+
+// sourcesExtension 本次检索到的参考资料，没有检索结果时为 nil
+func sourcesExtension(docs []*schema.Document) *v1.Extension {
+	if len(docs) == 0 {
+		return nil
+	}
+	return &v1.Extension{Sources: sources(docs)}
+}
+
+// citationIndexes 回答中实际出现的引用序号
+func (rp *reply) citationIndexes() []int {
+	if rp.citations == nil {
+		return nil
+	}
+	var indexes []int
+	for _, c := range rp.citations.Citations() {
+		indexes = append(indexes, c.Index)
+	}
+	return indexes
+}
+
+// tokenUsage 模型返回的用量；未返回时按文本长度估算
+func (rp *reply) tokenUsage(req *v1.ChatCompletionsReq, docs []*schema.Document) *v1.Usage {
+	if u := rp.usage; u != nil && u.TotalTokens > 0 {
+		return &v1.Usage{PromptTokens: u.PromptTokens, CompletionTokens: u.CompletionTokens, TotalTokens: u.TotalTokens}
+	}
+	prompt := common.EstimateTokens(common.FormatKnowledge(docs))
+	for _, m := range req.Messages {
+		text, _, _ := parseContent(m.Content)
+		prompt += common.EstimateTokens(text)
+	}
+	completion := common.EstimateTokens(rp.content.String()) + common.EstimateTokens(rp.reasoning.String())
+	return &v1.Usage{PromptTokens: prompt, CompletionTokens: completion, TotalTokens: prompt + completion}
+}
+
+func sources(docs []*schema.Document) []v1.Source {
+	list := make([]v1.Source, 0, len(docs))
+	for i, c := range common.DocumentCitations(docs) {
+		list = append(list, v1.Source{
+			Index:         c.Index,
+			ChunkId:       c.ChunkId,
+			DocumentName:  c.DocumentName,
+			KnowledgeName: c.KnowledgeName,
+			Source:        c.Source,
+			Score:         docs[i].Score(),
+			Content:       docs[i].Content,
+		})
+	}
+	return list
+}
+
+// Complete 读完模型流，返回非流式响应
+func Complete(ctx context.Context, id string, req *v1.ChatCompletionsReq, sr *schema.StreamReader[*schema.Message], docs []*schema.Document) (*v1.ChatCompletion, error) {
+	rp, err := readStream(sr, docs, nil)
+	if err != nil {
+		g.Log().Errorf(ctx, "[OpenAI] 生成失败: id=%s, 错误: %v", id, err)
+		return nil, err
+	}
+	finish := finishStop
+	res := &v1.ChatCompletion{
+		Id:      id,
+		Object:  objectResponse,
+		Created: time.Now().Unix(),
+		Model:   req.Model,
+		Choices: []v1.Choice{{
+			Message: &v1.Message{
+				Role:             string(schema.Assistant),
+				Content:          rp.content.String(),
+				ReasoningContent: rp.reasoning.String(),
+			},
+			FinishReason: &finish,
+		}},
+		Usage:      rp.tokenUsage(req, docs),
+		StudyCoach: sourcesExtension(docs),
+	}
+	if res.StudyCoach != nil {
+		res.StudyCoach.Citations = rp.citationIndexes()
+	}
+	return res, nil
+}
+
+// Stream 以 OpenAI 的 SSE 格式输出：首个分片带角色与参考资料，结束分片带 finish_reason 与实际引用，
+// stream_options.include_usage 时再输出用量分片，最后为 [DONE]。客户端断开时生成随请求取消
+func Stream(ctx context.Context, id string, req *v1.ChatCompletionsReq, sr *schema.StreamReader[*schema.Message], docs []*schema.Document) {
+	resp := g.RequestFromCtx(ctx).Response
+	resp.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	resp.Header().Set("Cache-Control", "no-cache")
+	resp.Header().Set("Connection", "keep-alive")
+	resp.Header().Set("X-Accel-Buffering", "no")
+	resp.WriteHeader(200)
+
+	created := time.Now().Unix()
+	chunk := func(delta *v1.Delta, finish *string) *v1.ChatCompletion {
+		return &v1.ChatCompletion{
+			Id:      id,
+			Object:  objectChunk,
+			Created: created,
+			Model:   req.Model,
+			Choices: []v1.Choice{{Delta: delta, FinishReason: finish}},
+		}
+	}
+	first := chunk(&v1.Delta{Role: string(schema.Assistant)}, nil)
+	first.StudyCoach = sourcesExtension(docs)
+	writeEvent(resp, first)
+
+	rp, err := readStream(sr, docs, func(content, reasoning string) {
+		writeEvent(resp, chunk(&v1.Delta{Content: content, ReasoningContent: reasoning}, nil))
+	})
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			g.Log().Errorf(ctx, "[OpenAI] 流式生成失败: id=%s, 错误: %v", id, err)
+			writeEvent(resp, v1.ErrorBody{Error: v1.ErrorDetail{Message: "响应生成失败，请稍后重试", Type: "server_error"}})
+		}
+		return
+	}
+	finish := finishStop
+	last := chunk(&v1.Delta{}, &finish)
+	if indexes := rp.citationIndexes(); len(indexes) > 0 {
+		last.StudyCoach = &v1.Extension{Citations: indexes}
+	}
+	writeEvent(resp, last)
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		usage := chunk(nil, nil)
+		usage.Choices = []v1.Choice{}
+		usage.Usage = rp.tokenUsage(req, docs)
+		writeEvent(resp, usage)
+	}
+	resp.Write("data: [DONE]\n\n")
+	resp.Flush()
+}
+
+func writeEvent(resp *ghttp.Response, v any) {
+	b, err := sonic.Marshal(v)
+	if err != nil {
+		return
+	}
+	resp.Write("data: ")
+	resp.Write(b)
+	resp.Write("\n\n")
+	resp.Flush()
+}
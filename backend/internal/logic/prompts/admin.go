@@ -0,0 +1,236 @@
+package prompts
+
+import (
+	v1 "backend/api/prompt/v1"
+	"backend/internal/dao"
+	"backend/internal/model/do"
+	"backend/internal/model/entity"
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+func toVersion(row *entity.PromptTemplates) v1.PromptVersion {
+	return v1.PromptVersion{
+		Id:        row.Id,
+		Node:      row.Node,
+		Scope:     row.Scope,
+		ScopeId:   row.ScopeId,
+		Version:   row.Version,
+		Content:   row.Content,
+		Remark:    row.Remark,
+		IsActive:  row.IsActive == 1,
+		CreatedBy: row.CreatedBy,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+// checkScope 校验作用范围：全局不带 scope_id，知识库与用户范围的目标必须存在
+func checkScope(ctx context.Context, scope, scopeId string) error {
+	var (
+		count int
+		err   error
+	)
+	switch scope {
+	case ScopeGlobal:
+		if scopeId != "" {
+			return gerror.NewCode(gcode.New(400, "参数错误：全局范围不需要 scope_id", nil))
+		}
+		return nil
+	case ScopeKnowledge:
+		id, parseErr := strconv.ParseInt(scopeId, 10, 64)
+		if parseErr != nil || id <= 0 {
+			return gerror.NewCode(gcode.New(400, "参数错误：知识库范围的 scope_id 应为知识库 ID", nil))
+		}
+		count, err = dao.KnowledgeBase.Ctx(ctx).Where(dao.KnowledgeBase.Columns().Id, id).Count()
+	case ScopeUser:
+		count, err = dao.Users.Ctx(ctx).Where(dao.Users.Columns().Uuid, scopeId).Count()
+	default:
+		return gerror.NewCode(gcode.New(400, "参数错误：scope 取值为 global、knowledge、user", nil))
+	}
+	if err != nil {
+		return fmt.Errorf("校验提示词作用范围失败: %w", err)
+	}
+	if count == 0 {
+		return gerror.NewCode(gcode.New(404, fmt.Sprintf("作用范围不存在: %s=%s", scope, scopeId), nil))
+	}
+	return nil
+}
+
+func scopeModel(ctx context.Context, node, scope, scopeId string) *gdb.Model {
+	return dao.PromptTemplates.Ctx(ctx).
+		Where(dao.PromptTemplates.Columns().Node, node).
+		Where(dao.PromptTemplates.Columns().Scope, scope).
+		Where(dao.PromptTemplates.Columns().ScopeId, scopeId)
+}
+
+// ListNodes 全部节点及其全局生效版本
+func ListNodes(ctx context.Context) ([]v1.PromptNode, error) {
+	var active []*entity.PromptTemplates
+	err := dao.PromptTemplates.Ctx(ctx).
+		Where(dao.PromptTemplates.Columns().Scope, ScopeGlobal).
+		Where(dao.PromptTemplates.Columns().IsActive, 1).
+		Scan(&active)
+	if err != nil {
+		return nil, fmt.Errorf("查询生效提示词失败: %w", err)
+	}
+	list := make([]v1.PromptNode, 0, len(nodes))
+	for _, n := range nodes {
+		item := v1.PromptNode{
+			Node:        n.Name,
+			Description: n.Description,
+			Variables:   n.Variables,
+			Default:     n.Default,
+		}
+		for _, row := range active {
+			if row.Node == n.Name {
+				version := toVersion(row)
+				item.Active = &version
+			}
+		}
+		list = append(list, item)
+	}
+	return list, nil
+}
+
+// ListVersions 节点在某一范围内的全部版本，按版本号倒序
+func ListVersions(ctx context.Context, node, scope, scopeId string) ([]v1.PromptVersion, error) {
+	if _, err := findNode(node); err != nil {
+		return nil, err
+	}
+	var rows []*entity.PromptTemplates
+	err := scopeModel(ctx, node, scope, scopeId).
+		OrderDesc(dao.PromptTemplates.Columns().Version).
+		Scan(&rows)
+	if err != nil {
+		return nil, fmt.Errorf("查询提示词版本失败: %w", err)
+	}
+	list := make([]v1.PromptVersion, 0, len(rows))
+	for _, row := range rows {
+		list = append(list, toVersion(row))
+	}
+	return list, nil
+}
+
+// Create 校验模板变量后保存为新版本，activate 为 true 时同时设为生效版本
+func Create(ctx context.Context, username string, req *v1.PromptCreateReq) (*v1.PromptVersion, error) {
+	n, err := findNode(req.Node)
+	if err != nil {
+		return nil, err
+	}
+	if err = checkScope(ctx, req.Scope, req.ScopeId); err != nil {
+		return nil, err
+	}
+	if err = n.Validate(ctx, req.Content); err != nil {
+		return nil, err
+	}
+	var id int64
+	err = dao.PromptTemplates.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		latest, err := scopeModel(ctx, req.Node, req.Scope, req.ScopeId).
+			LockUpdate().
+			Max(dao.PromptTemplates.Columns().Version)
+		if err != nil {
+			return err
+		}
+		id, err = dao.PromptTemplates.Ctx(ctx).Data(do.PromptTemplates{
+			Node:      req.Node,
+			Scope:     req.Scope,
+			ScopeId:   req.ScopeId,
+			Version:   int(latest) + 1,
+			Content:   req.Content,
+			Remark:    req.Remark,
+			IsActive:  0,
+			CreatedBy: username,
+		}).InsertAndGetId()
+		if err != nil {
+			return err
+		}
+		if req.Activate {
+			return activate(ctx, id, req.Node, req.Scope, req.ScopeId)
+		}
+		return nil
+	})
+	if err != nil {
+		g.Log().Errorf(ctx, "[Prompt] 保存提示词失败: node=%s, scope=%s:%s, 错误: %v", req.Node, req.Scope, req.ScopeId, err)
+		return nil, fmt.Errorf("保存提示词失败: %w", err)
+	}
+	invalidate(ctx, req.Node, req.Scope, req.ScopeId)
+	return get(ctx, id)
+}
+
+// Activate 将指定版本设为所在范围的生效版本，可用于回滚到旧版本
+func Activate(ctx context.Context, id int64) (*v1.PromptVersion, error) {
+	var row *entity.PromptTemplates
+	if err := dao.PromptTemplates.Ctx(ctx).Where(dao.PromptTemplates.Columns().Id, id).Scan(&row); err != nil {
+		return nil, fmt.Errorf("查询提示词版本失败: %w", err)
+	}
+	if row == nil {
+		return nil, gerror.NewCode(gcode.New(404, "提示词版本不存在", nil))
+	}
+	n, err := findNode(row.Node)
+	if err != nil {
+		return nil, err
+	}
+	// 节点变量可能随代码调整，生效前按当前变量重新校验
+	if err = n.Validate(ctx, row.Content); err != nil {
+		return nil, err
+	}
+	err = dao.PromptTemplates.Transaction(ctx, func(ctx context.Context, tx gdb.TX) error {
+		return activate(ctx, row.Id, row.Node, row.Scope, row.ScopeId)
+	})
+	if err != nil {
+		g.Log().Errorf(ctx, "[Prompt] 切换生效版本失败: id=%d, 错误: %v", id, err)
+		return nil, fmt.Errorf("切换生效版本失败: %w", err)
+	}
+	invalidate(ctx, row.Node, row.Scope, row.ScopeId)
+	g.Log().Infof(ctx, "[Prompt] 已切换生效版本: node=%s, scope=%s:%s, version=%d", row.Node, row.Scope, row.ScopeId, row.Version)
+	return get(ctx, id)
+}
+
+// Deactivate 取消范围内的生效版本，之后回退到上一级范围或内置模板
+func Deactivate(ctx context.Context, node, scope, scopeId string) error {
+	if _, err := findNode(node); err != nil {
+		return err
+	}
+	_, err := scopeModel(ctx, node, scope, scopeId).
+		Data(do.PromptTemplates{IsActive: 0}).
+		Update()
+	if err != nil {
+		return fmt.Errorf("取消生效版本失败: %w", err)
+	}
+	invalidate(ctx, node, scope, scopeId)
+	return nil
+}
+
+// activate 在事务内切换生效版本，同一范围只保留一个生效版本
+func activate(ctx context.Context, id int64, node, scope, scopeId string) error {
+	_, err := scopeModel(ctx, node, scope, scopeId).
+		WhereNot(dao.PromptTemplates.Columns().Id, id).
+		Data(do.PromptTemplates{IsActive: 0}).
+		Update()
+	if err != nil {
+		return err
+	}
+	_, err = dao.PromptTemplates.Ctx(ctx).
+		Where(dao.PromptTemplates.Columns().Id, id).
+		Data(do.PromptTemplates{IsActive: 1}).
+		Update()
+	return err
+}
+
+func get(ctx context.Context, id int64) (*v1.PromptVersion, error) {
+	var row *entity.PromptTemplates
+	if err := dao.PromptTemplates.Ctx(ctx).Where(dao.PromptTemplates.Columns().Id, id).Scan(&row); err != nil {
+		return nil, fmt.Errorf("查询提示词版本失败: %w", err)
+	}
+	if row == nil {
+		return nil, gerror.NewCode(gcode.New(404, "提示词版本不存在", nil))
+	}
+	version := toVersion(row)
+	return &version, nil
+}
@@ -0,0 +1,126 @@
+package prompts
+
+import (
+	"backend/studyCoach/common"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/cloudwego/eino/components/prompt"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+// 可配置提示词的节点
+const (
+	NodeAnalysis      = "analysis"       // CoachChat 意图分析
+	NodeCoach         = "coach"          // CoachChat 学习任务与计划修改
+	NodeCompanion     = "companion"      // CoachChat 情感陪伴
+	NodeBranch        = "branch"         // CoachChat 分支判断
+	NodeNormal        = "normal"         // NormalChat
+	NodeNormalNetwork = "normal_network" // NormalChat 联网模式
+	NodeCron          = "cron"           // RegularUpdate 定时更新
+)
+
+// Node 节点的内置模板与 ChatTemplate.Format 提供的变量
+type Node struct {
+	Name        string
+	Description string
+	Default     string
+	Variables   []string
+}
+
+// chatVariables 对话图（stream 与 CoachChat lambda）传给模板的变量
+var chatVariables = []string{"question", "knowledge", "current_time", "uploaded_files"}
+
+// normalVariables NormalChat 另有 NormalModeParams 提供的 role
+var normalVariables = append(slices.Clone(chatVariables), "role")
+
+// cronDefault 定时更新的系统提示词：风格说明 + 当前时间 + 知识库内容
+const cronDefault = "{style}\n当前时间：{time_now}\n\n{knowledge}"
+
+var nodes = []Node{
+	{Name: NodeAnalysis, Description: "学习模式意图分析", Default: common.AnalysisSystemTemplate, Variables: chatVariables},
+	{Name: NodeCoach, Description: "学习模式任务与计划修改", Default: common.SystemCoachTemplate, Variables: chatVariables},
+	{Name: NodeCompanion, Description: "学习模式情感陪伴（未设置版本时优先使用 emotion-companion Skill）", Default: common.EmotionAndCompanionShipTemplate, Variables: chatVariables},
+	{Name: NodeBranch, Description: "学习模式分支判断", Default: common.BranchSystemTemplate, Variables: []string{"question"}},
+	{Name: NodeNormal, Description: "普通模式", Default: common.NormalSystemTemplate, Variables: normalVariables},
+	{Name: NodeNormalNetwork, Description: "普通模式（联网）", Default: common.NormalSystemTemplateWithTools, Variables: normalVariables},
+	{Name: NodeCron, Description: "知识库定时更新", Default: cronDefault, Variables: []string{"style", "time_now", "question", "knowledge"}},
+}
+
+// Nodes 全部可配置节点
+func Nodes() []Node {
+	return nodes
+}
+
+func findNode(name string) (*Node, error) {
+	for i := range nodes {
+		if nodes[i].Name == name {
+			return &nodes[i], nil
+		}
+	}
+	return nil, gerror.NewCode(gcode.New(400, fmt.Sprintf("未知的提示词节点: %s", name), nil))
+}
+
+// Validate 校验模板只引用节点提供的变量，且能按 FString 格式化
+func (n *Node) Validate(ctx context.Context, content string) error {
+	vars, err := templateVariables(content)
+	if err != nil {
+		return gerror.NewCode(gcode.New(400, "模板格式错误："+err.Error(), nil))
+	}
+	for _, v := range vars {
+		if !slices.Contains(n.Variables, v) {
+			return gerror.NewCode(gcode.New(400, fmt.Sprintf("模板引用了未提供的变量 {%s}，节点 %s 可用变量：%s",
+				v, n.Name, strings.Join(n.Variables, "、")), nil))
+		}
+	}
+	sample := make(map[string]any, len(n.Variables))
+	for _, v := range n.Variables {
+		sample[v] = ""
+	}
+	if _, err = prompt.FromMessages(schema.FString, schema.SystemMessage(content)).Format(ctx, sample); err != nil {
+		return gerror.NewCode(gcode.New(400, "模板格式错误："+err.Error(), nil))
+	}
+	return nil
+}
+
+// templateVariables 按 FString（Python format）语法取出模板引用的变量名，{{ 与 }} 为字面量花括号
+func templateVariables(content string) ([]string, error) {
+	var vars []string
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '{':
+			if i+1 < len(content) && content[i+1] == '{' {
+				i++
+				continue
+			}
+			end := strings.IndexByte(content[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("第 %d 个字符处的 { 没有闭合，字面量花括号请写作 {{", i+1)
+			}
+			field := content[i+1 : i+end]
+			name := field
+			if cut := strings.IndexAny(field, ":!.["); cut >= 0 {
+				name = field[:cut]
+			}
+			name = strings.TrimSpace(name)
+			if name == "" {
+				return nil, fmt.Errorf("第 %d 个字符处的变量名为空，字面量花括号请写作 {{ }}", i+1)
+			}
+			if !slices.Contains(vars, name) {
+				vars = append(vars, name)
+			}
+			i += end
+		case '}':
+			if i+1 < len(content) && content[i+1] == '}' {
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("第 %d 个字符处的 } 没有对应的 {，字面量花括号请写作 }}", i+1)
+		}
+	}
+	return vars, nil
+}
@@ -0,0 +1,96 @@
+package prompts
+
+import (
+	"backend/internal/dao"
+	"backend/internal/logic/usage"
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcache"
+)
+
+// 作用范围
+const (
+	ScopeGlobal    = "global"
+	ScopeKnowledge = "knowledge"
+	ScopeUser      = "user"
+)
+
+const defaultCacheTTL = 30 * time.Second
+
+// cache 节点 + 作用范围 -> 生效版本内容，没有生效版本时为空串；多实例部署时其他实例在 TTL 后生效
+var cache = gcache.New()
+
+func cacheKey(node, scope, scopeId string) string {
+	return node + "|" + scope + "|" + scopeId
+}
+
+func cacheTTL(ctx context.Context) time.Duration {
+	return g.Cfg().MustGet(ctx, "prompt.cacheTTL", defaultCacheTTL).Duration()
+}
+
+// invalidate 版本变更后清除对应范围的缓存
+func invalidate(ctx context.Context, node, scope, scopeId string) {
+	if _, err := cache.Remove(ctx, cacheKey(node, scope, scopeId)); err != nil {
+		g.Log().Warningf(ctx, "[Prompt] 清除缓存失败: node=%s, 错误: %v", node, err)
+	}
+}
+
+// System 节点在当前请求下生效的系统提示词：用户版本 > 知识库版本 > 全局版本 > 内置模板
+func System(ctx context.Context, node string) string {
+	if content, ok := Active(ctx, node); ok {
+		return content
+	}
+	n, err := findNode(node)
+	if err != nil {
+		g.Log().Errorf(ctx, "[Prompt] %v", err)
+		return ""
+	}
+	return n.Default
+}
+
+// Active 当前请求命中的生效版本，各范围都没有生效版本时 ok 为 false；
+// 用户与知识库取自 ctx 中的用量归属，读取失败时跳过该范围，不阻断对话
+func Active(ctx context.Context, node string) (string, bool) {
+	scope := usage.ScopeFromContext(ctx)
+	scopes := make([][2]string, 0, 3)
+	if scope.UserUUID != "" {
+		scopes = append(scopes, [2]string{ScopeUser, scope.UserUUID})
+	}
+	if scope.KnowledgeBaseId > 0 {
+		scopes = append(scopes, [2]string{ScopeKnowledge, strconv.FormatInt(scope.KnowledgeBaseId, 10)})
+	}
+	scopes = append(scopes, [2]string{ScopeGlobal, ""})
+	for _, s := range scopes {
+		content, err := cachedActive(ctx, node, s[0], s[1])
+		if err != nil {
+			g.Log().Warningf(ctx, "[Prompt] 读取提示词失败，跳过: node=%s, scope=%s:%s, 错误: %v", node, s[0], s[1], err)
+			continue
+		}
+		if content != "" {
+			return content, true
+		}
+	}
+	return "", false
+}
+
+func cachedActive(ctx context.Context, node, scope, scopeId string) (string, error) {
+	v, err := cache.GetOrSetFunc(ctx, cacheKey(node, scope, scopeId), func(ctx context.Context) (any, error) {
+		content, err := dao.PromptTemplates.Ctx(ctx).
+			Where(dao.PromptTemplates.Columns().Node, node).
+			Where(dao.PromptTemplates.Columns().Scope, scope).
+			Where(dao.PromptTemplates.Columns().ScopeId, scopeId).
+			Where(dao.PromptTemplates.Columns().IsActive, 1).
+			Value(dao.PromptTemplates.Columns().Content)
+		if err != nil {
+			return nil, err
+		}
+		return content.String(), nil
+	}, cacheTTL(ctx))
+	if err != nil {
+		return "", err
+	}
+	return v.String(), nil
+}
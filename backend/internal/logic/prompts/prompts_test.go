@@ -0,0 +1,93 @@
+package prompts
+
+import (
+	"backend/internal/logic/usage"
+	"context"
+	"testing"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+)
+
+func TestValidate(t *testing.T) {
+	ctx := context.Background()
+	// 内置模板均能通过所在节点的校验
+	for i := range nodes {
+		if err := nodes[i].Validate(ctx, nodes[i].Default); err != nil {
+			t.Fatalf("节点 %s 的内置模板校验失败: %v", nodes[i].Name, err)
+		}
+	}
+
+	branch, err := findNode(NodeBranch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid := []string{
+		"根据 {question} 判断分支",
+		"输出 JSON：{{\"branch\": \"coach\"}}，问题：{question}",
+		"没有变量的模板",
+	}
+	for _, content := range valid {
+		if err = branch.Validate(ctx, content); err != nil {
+			t.Fatalf("合法模板 %q 校验失败: %v", content, err)
+		}
+	}
+	invalid := []string{
+		"参考资料：{knowledge}", // branch 节点不提供 knowledge
+		"问题：{question",
+		"问题：question}",
+		"空变量 {}",
+	}
+	for _, content := range invalid {
+		err = branch.Validate(ctx, content)
+		if gerror.Code(err).Code() != 400 {
+			t.Fatalf("非法模板 %q 应返回 400，实际 %v", content, err)
+		}
+	}
+
+	if _, err = findNode("unknown"); gerror.Code(err).Code() != 400 {
+		t.Fatalf("未知节点应返回 400，实际 %v", err)
+	}
+}
+
+func TestActiveResolution(t *testing.T) {
+	// 预置各范围的缓存，不访问数据库：用户版本 > 知识库版本 > 全局版本 > 内置模板
+	ctx := context.Background()
+	node := NodeNormal
+	prime := func(scope, scopeId, content string) {
+		if err := cache.Set(ctx, cacheKey(node, scope, scopeId), content, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Cleanup(func() { _ = cache.Clear(ctx) })
+	prime(ScopeUser, "u1", "用户版本")
+	prime(ScopeUser, "u2", "")
+	prime(ScopeKnowledge, "7", "知识库版本")
+	prime(ScopeKnowledge, "8", "")
+	prime(ScopeGlobal, "", "全局版本")
+
+	cases := []struct {
+		scope usage.Scope
+		want  string
+	}{
+		{usage.Scope{UserUUID: "u1", KnowledgeBaseId: 7}, "用户版本"},
+		{usage.Scope{UserUUID: "u2", KnowledgeBaseId: 7}, "知识库版本"},
+		{usage.Scope{UserUUID: "u2", KnowledgeBaseId: 8}, "全局版本"},
+		{usage.Scope{}, "全局版本"},
+	}
+	for _, c := range cases {
+		if got := System(usage.WithScope(ctx, c.scope), node); got != c.want {
+			t.Fatalf("scope=%+v 生效提示词应为 %q，实际 %q", c.scope, c.want, got)
+		}
+	}
+
+	// 各范围都没有生效版本时回退到内置模板
+	prime(ScopeGlobal, "", "")
+	scoped := usage.WithScope(ctx, usage.Scope{UserUUID: "u2", KnowledgeBaseId: 8})
+	if _, ok := Active(scoped, node); ok {
+		t.Fatal("各范围都没有生效版本时 Active 应返回 false")
+	}
+	n, _ := findNode(node)
+	if got := System(scoped, node); got != n.Default {
+		t.Fatalf("应回退到内置模板，实际 %q", got)
+	}
+}
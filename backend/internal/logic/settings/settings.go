@@ -0,0 +1,149 @@
+package settings
+
+import (
+	v1 "backend/api/settings/v1"
+	"backend/internal/dao"
+	"backend/internal/model/do"
+	"backend/internal/model/entity"
+	"backend/studyCoach/common"
+	"backend/utility"
+	"context"
+	"fmt"
+
+	"github.com/gogf/gf/v2/encoding/gjson"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// settingsJSON settings_json 列的内容
+type settingsJSON struct {
+	ChatDefaults v1.ChatDefaults `json:"chat_defaults"`
+}
+
+// Default 未保存过设置的用户使用的默认值；max_sessions 默认不限，避免静默删除已有会话
+func Default() *v1.UserSettings {
+	return &v1.UserSettings{
+		Language:            common.LanguageZh,
+		NotificationEnabled: true,
+		AutoSaveSessions:    true,
+	}
+}
+
+// Get 读取用户设置，未保存过时返回默认值
+func Get(ctx context.Context, userId int64) (*v1.UserSettings, error) {
+	row, err := find(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		return Default(), nil
+	}
+	s := &v1.UserSettings{
+		Theme:               row.Theme,
+		Language:            row.Language,
+		NotificationEnabled: row.NotificationEnabled == 1,
+		AutoSaveSessions:    row.AutoSaveSessions == 1,
+		MaxSessions:         int(row.MaxSessions),
+		FontSize:            row.FontSize,
+		UpdatedAt:           row.UpdatedAt,
+	}
+	if s.Language == "" {
+		s.Language = common.LanguageZh
+	}
+	if row.SettingsJson != "" {
+		var extra settingsJSON
+		if err = gjson.DecodeTo(row.SettingsJson, &extra); err != nil {
+			g.Log().Warningf(ctx, "解析用户设置失败，忽略默认对话选项: user=%d, 错误: %v", userId, err)
+		}
+		s.ChatDefaults = extra.ChatDefaults
+	}
+	return s, nil
+}
+
+// Current 当前请求用户的设置：未登录或读取失败时返回默认值，不阻断对话
+func Current(ctx context.Context) *v1.UserSettings {
+	if utility.GetJWT(ctx) == "" {
+		return Default()
+	}
+	userId, err := utility.CurrentUserID(ctx)
+	if err != nil {
+		return Default()
+	}
+	s, err := Get(ctx, userId)
+	if err != nil {
+		return Default()
+	}
+	return s
+}
+
+// Update 合并请求中提供的字段并保存，返回更新后的设置
+func Update(ctx context.Context, userId int64, req *v1.SettingsUpdateReq) (*v1.UserSettings, error) {
+	s, err := Get(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if req.Theme != nil {
+		s.Theme = *req.Theme
+	}
+	if req.Language != nil {
+		s.Language = *req.Language
+	}
+	if req.NotificationEnabled != nil {
+		s.NotificationEnabled = *req.NotificationEnabled
+	}
+	if req.AutoSaveSessions != nil {
+		s.AutoSaveSessions = *req.AutoSaveSessions
+	}
+	if req.MaxSessions != nil {
+		s.MaxSessions = *req.MaxSessions
+	}
+	if req.FontSize != nil {
+		s.FontSize = *req.FontSize
+	}
+	if req.ChatDefaults != nil {
+		s.ChatDefaults = *req.ChatDefaults
+	}
+	now := gtime.Now()
+	data := do.UserSettings{
+		Theme:               s.Theme,
+		Language:            s.Language,
+		NotificationEnabled: s.NotificationEnabled,
+		AutoSaveSessions:    s.AutoSaveSessions,
+		MaxSessions:         s.MaxSessions,
+		FontSize:            s.FontSize,
+		SettingsJson:        gjson.MustEncodeString(settingsJSON{ChatDefaults: s.ChatDefaults}),
+		UpdatedAt:           now,
+	}
+	row, err := find(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if row == nil {
+		data.UserId = userId
+		data.CreatedAt = now
+		_, err = dao.UserSettings.Ctx(ctx).Data(data).Insert()
+	} else {
+		_, err = dao.UserSettings.Ctx(ctx).Where(dao.UserSettings.Columns().Id, row.Id).Data(data).Update()
+	}
+	if err != nil {
+		g.Log().Errorf(ctx, "保存用户设置失败: user=%d, 错误: %v", userId, err)
+		return nil, fmt.Errorf("保存用户设置失败: %w", err)
+	}
+	s.UpdatedAt = now
+	return s, nil
+}
+
+// find 查询用户的设置行，同一用户有多行时取最新的一行
+func find(ctx context.Context, userId int64) (*entity.UserSettings, error) {
+	var row *entity.UserSettings
+	err := dao.UserSettings.Ctx(ctx).
+		Where(dao.UserSettings.Columns().UserId, userId).
+		OrderDesc(dao.UserSettings.Columns().Id).
+		Limit(1).
+		Scan(&row)
+	if err != nil {
+		g.Log().Errorf(ctx, "查询用户设置失败: user=%d, 错误: %v", userId, err)
+		return nil, fmt.Errorf("查询用户设置失败: %w", err)
+	}
+	return row, nil
+}
@@ -0,0 +1,244 @@
+package usage
+
+import (
+	"backend/internal/dao"
+	"backend/internal/model/do"
+	"backend/studyCoach/common"
+	"backend/studyCoach/rerank"
+	"context"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gctx"
+)
+
+type (
+	graphCtxKey struct{} // 最外层图的名称
+	nodeCtxKey  struct{} // 最近的具名节点
+	callCtxKey  struct{} // 进行中的模型调用
+)
+
+// call 一次模型、向量化或重排调用：OnStart 时写入 ctx，结束回调中记录用量
+type call struct {
+	start     time.Time
+	graph     string
+	node      string
+	component string
+	model     string
+	prompt    int // 按输入文本估算的 token，接口未返回用量时使用
+}
+
+// NewHandler 记录模型调用用量的全局回调：按 ctx 中的 Scope 归属用户、会话与知识库，按所在图与节点区分调用方。
+// 由启动命令通过 callbacks.AppendGlobalHandlers 注册，图内节点与直接调用的模型都会经过
+func NewHandler() callbacks.Handler {
+	return callbacks.NewHandlerBuilder().
+		OnStartFn(onStart).
+		OnStartWithStreamInputFn(func(ctx context.Context, info *callbacks.RunInfo, input *schema.StreamReader[callbacks.CallbackInput]) context.Context {
+			input.Close()
+			return enter(ctx, info)
+		}).
+		OnEndFn(onEnd).
+		OnEndWithStreamOutputFn(onEndWithStreamOutput).
+		OnErrorFn(onError).
+		Build()
+}
+
+// enter 进入图或具名节点时记录其名称，供内部的模型调用归属
+func enter(ctx context.Context, info *callbacks.RunInfo) context.Context {
+	if info == nil || info.Name == "" {
+		return ctx
+	}
+	switch info.Component {
+	case compose.ComponentOfGraph, compose.ComponentOfChain, compose.ComponentOfWorkflow:
+		if _, ok := ctx.Value(graphCtxKey{}).(string); !ok {
+			ctx = context.WithValue(ctx, graphCtxKey{}, info.Name)
+		}
+		return ctx
+	}
+	return context.WithValue(ctx, nodeCtxKey{}, info.Name)
+}
+
+func onStart(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+	c := &call{start: time.Now()}
+	// 直接在 Lambda 内调用的模型沿用 Lambda 的 RunInfo，因此按输入类型而不是 info.Component 识别
+	switch in := input.(type) {
+	case *model.CallbackInput:
+		c.component = string(components.ComponentOfChatModel)
+		if info != nil && info.Component == rerank.ComponentOfRerank {
+			c.component = string(rerank.ComponentOfRerank)
+		}
+		if in.Config != nil {
+			c.model = in.Config.Model
+		}
+		for _, msg := range in.Messages {
+			c.prompt += messageTokens(msg)
+		}
+	case *embedding.CallbackInput:
+		c.component = string(components.ComponentOfEmbedding)
+		if in.Config != nil {
+			c.model = in.Config.Model
+		}
+		for _, text := range in.Texts {
+			c.prompt += common.EstimateTokens(text)
+		}
+	default:
+		return enter(ctx, info)
+	}
+	c.graph, _ = ctx.Value(graphCtxKey{}).(string)
+	if info != nil && info.Name != "" {
+		c.node = info.Name
+	} else if node, ok := ctx.Value(nodeCtxKey{}).(string); ok {
+		c.node = node
+	} else if info != nil {
+		c.node = info.Type
+	}
+	return context.WithValue(ctx, callCtxKey{}, c)
+}
+
+func onEnd(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+	c, ok := ctx.Value(callCtxKey{}).(*call)
+	if !ok {
+		return ctx
+	}
+	switch out := output.(type) {
+	case *model.CallbackOutput:
+		c.setModel(out.Config)
+		completion := 0
+		if out.Message != nil {
+			completion = messageTokens(out.Message)
+		}
+		c.record(ctx, out.TokenUsage, completion, false)
+	case *embedding.CallbackOutput:
+		if out.Config != nil && c.model == "" {
+			c.model = out.Config.Model
+		}
+		var usage *model.TokenUsage
+		if out.TokenUsage != nil {
+			usage = &model.TokenUsage{
+				PromptTokens:     out.TokenUsage.PromptTokens,
+				CompletionTokens: out.TokenUsage.CompletionTokens,
+				TotalTokens:      out.TokenUsage.TotalTokens,
+			}
+		}
+		c.record(ctx, usage, 0, false)
+	}
+	return ctx
+}
+
+// onEndWithStreamOutput 流式输出的用量在最后的分片中返回，读完回调副本后记录；耗时计到流结束
+func onEndWithStreamOutput(ctx context.Context, info *callbacks.RunInfo, output *schema.StreamReader[callbacks.CallbackOutput]) context.Context {
+	c, ok := ctx.Value(callCtxKey{}).(*call)
+	if !ok {
+		output.Close()
+		return ctx
+	}
+	go func() {
+		defer output.Close()
+		var (
+			usage *model.TokenUsage
+			text  strings.Builder // 已生成的文本，接口未返回用量时用于估算
+		)
+		for {
+			chunk, err := output.Recv()
+			if err == io.EOF {
+				c.record(ctx, usage, common.EstimateTokens(text.String()), false)
+				return
+			}
+			if err != nil {
+				// 中途取消或出错：已生成的部分按估算计入
+				c.record(ctx, usage, common.EstimateTokens(text.String()), true)
+				return
+			}
+			out, ok := chunk.(*model.CallbackOutput)
+			if !ok {
+				continue
+			}
+			c.setModel(out.Config)
+			if out.TokenUsage != nil {
+				usage = out.TokenUsage
+			}
+			if msg := out.Message; msg != nil {
+				text.WriteString(msg.Content)
+				text.WriteString(msg.ReasoningContent)
+				for _, tc := range msg.ToolCalls {
+					text.WriteString(tc.Function.Arguments)
+				}
+			}
+		}
+	}()
+	return ctx
+}
+
+func onError(ctx context.Context, info *callbacks.RunInfo, err error) context.Context {
+	if c, ok := ctx.Value(callCtxKey{}).(*call); ok {
+		c.prompt = 0
+		c.record(ctx, nil, 0, true)
+	}
+	return ctx
+}
+
+func (c *call) setModel(conf *model.Config) {
+	if conf != nil && c.model == "" {
+		c.model = conf.Model
+	}
+}
+
+// record 写入一条用量：接口返回用量时以其为准，否则按文本长度估算
+func (c *call) record(ctx context.Context, usage *model.TokenUsage, completion int, failed bool) {
+	scope := ScopeFromContext(ctx)
+	row := do.LlmUsage{
+		UserUuid:        scope.UserUUID,
+		AnonymousId:     scope.AnonymousId,
+		SessionId:       scope.SessionId,
+		KnowledgeBaseId: scope.KnowledgeBaseId,
+		Source:          scope.Source,
+		Graph:           c.graph,
+		Node:            c.node,
+		Component:       c.component,
+		Model:           c.model,
+		LatencyMs:       time.Since(c.start).Milliseconds(),
+		Failed:          boolInt(failed),
+	}
+	if usage != nil && usage.TotalTokens > 0 {
+		row.PromptTokens = usage.PromptTokens
+		row.CompletionTokens = usage.CompletionTokens
+		row.TotalTokens = usage.TotalTokens
+		row.Estimated = 0
+	} else {
+		row.PromptTokens = c.prompt
+		row.CompletionTokens = completion
+		row.TotalTokens = c.prompt + completion
+		row.Estimated = boolInt(c.prompt+completion > 0)
+	}
+	go func(ctx context.Context) {
+		if _, err := dao.LlmUsage.Ctx(ctx).Data(row).Insert(); err != nil {
+			g.Log().Warningf(ctx, "[Usage] 记录模型用量失败: node=%s, model=%s, 错误: %v", c.node, c.model, err)
+		}
+	}(gctx.NeverDone(ctx))
+}
+
+func messageTokens(msg *schema.Message) int {
+	n := common.EstimateTokens(msg.Content) + common.EstimateTokens(msg.ReasoningContent)
+	for _, p := range msg.UserInputMultiContent {
+		n += common.EstimateTokens(p.Text)
+	}
+	for _, tc := range msg.ToolCalls {
+		n += common.EstimateTokens(tc.Function.Name) + common.EstimateTokens(tc.Function.Arguments)
+	}
+	return n
+}
+
+func boolInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
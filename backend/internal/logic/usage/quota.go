@@ -0,0 +1,122 @@
+package usage
+
+import (
+	v1 "backend/api/usage/v1"
+	"backend/internal/dao"
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// quotaPeriod 额度周期：自然日或自然月
+type quotaPeriod struct {
+	name  string // day | month
+	label string
+	limit int64
+	start time.Time
+	reset time.Time
+}
+
+// periods 按 scope 的身份读取 usage.quota 下的日/月额度（0 为不限）
+func periods(ctx context.Context, scope Scope) []quotaPeriod {
+	prefix := "usage.quota."
+	if scope.UserUUID == "" {
+		prefix = "usage.quota.anonymous."
+	}
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return []quotaPeriod{
+		{
+			name:  "day",
+			label: "今日",
+			limit: g.Cfg().MustGet(ctx, prefix+"dailyTokens", 0).Int64(),
+			start: day,
+			reset: day.AddDate(0, 0, 1),
+		},
+		{
+			name:  "month",
+			label: "本月",
+			limit: g.Cfg().MustGet(ctx, prefix+"monthlyTokens", 0).Int64(),
+			start: month,
+			reset: month.AddDate(0, 1, 0),
+		},
+	}
+}
+
+// QuotaExceeded 额度用完错误（错误码 429）的附加信息，OpenAI 兼容接口据此设置 Retry-After
+type QuotaExceeded struct {
+	ResetAt time.Time // 额度恢复时间
+}
+
+// CheckQuota 在对话或索引开始前校验 scope 所属用户的日/月 token 额度，用完时返回错误码 429。
+// 无法识别用户或统计失败时放行，不因用量记录问题阻断业务
+func CheckQuota(ctx context.Context, scope Scope) error {
+	if scope.UserUUID == "" && scope.AnonymousId == "" {
+		return nil
+	}
+	for _, p := range periods(ctx, scope) {
+		if p.limit <= 0 {
+			continue
+		}
+		used, err := usedTokens(ctx, scope, p.start)
+		if err != nil {
+			g.Log().Errorf(ctx, "[Usage] 统计用量失败，跳过额度校验: user=%s, 错误: %v", scope.UserUUID, err)
+			return nil
+		}
+		if used < p.limit {
+			continue
+		}
+		g.Log().Infof(ctx, "[Usage] 额度已用完: user=%s anonymous=%s period=%s used=%d limit=%d",
+			scope.UserUUID, scope.AnonymousId, p.name, used, p.limit)
+		return gerror.NewCode(gcode.New(http.StatusTooManyRequests,
+			fmt.Sprintf("%s token 额度已用完（已用 %d / 上限 %d），将于 %s 恢复", p.label, used, p.limit, p.reset.Format("2006-01-02 15:04")),
+			QuotaExceeded{ResetAt: p.reset}))
+	}
+	return nil
+}
+
+// QuotaStatus 当前用户各周期的额度使用情况
+func QuotaStatus(ctx context.Context, scope Scope) ([]v1.QuotaStatus, error) {
+	var status []v1.QuotaStatus
+	for _, p := range periods(ctx, scope) {
+		used, err := usedTokens(ctx, scope, p.start)
+		if err != nil {
+			return nil, err
+		}
+		status = append(status, v1.QuotaStatus{
+			Period:  p.name,
+			Used:    used,
+			Limit:   p.limit,
+			ResetAt: p.reset.Format(time.DateTime),
+		})
+	}
+	return status, nil
+}
+
+// usedTokens 统计 since 之后 scope 所属用户的 token 用量
+func usedTokens(ctx context.Context, scope Scope, since time.Time) (int64, error) {
+	sum, err := ownerModel(ctx, scope).
+		WhereGTE(dao.LlmUsage.Columns().CreatedAt, since).
+		Sum(dao.LlmUsage.Columns().TotalTokens)
+	if err != nil {
+		return 0, err
+	}
+	return int64(sum), nil
+}
+
+// ownerModel 按用户或匿名标识过滤用量记录
+func ownerModel(ctx context.Context, scope Scope) *gdb.Model {
+	m := dao.LlmUsage.Ctx(ctx)
+	if scope.UserUUID != "" {
+		return m.Where(dao.LlmUsage.Columns().UserUuid, scope.UserUUID)
+	}
+	return m.Where(dao.LlmUsage.Columns().AnonymousId, scope.AnonymousId).
+		Where(dao.LlmUsage.Columns().UserUuid, "")
+}
@@ -0,0 +1,47 @@
+package usage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+)
+
+func TestPeriods(t *testing.T) {
+	// 登录用户与匿名用户分别读取各自的日/月额度，周期从当日/当月零点开始
+	adapter, err := gcfg.NewAdapterContent("usage:\n  quota:\n    dailyTokens: 100\n    monthlyTokens: 3000\n    anonymous:\n      dailyTokens: 10\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	t.Cleanup(func() { g.Cfg().SetAdapter(original) })
+	ctx := context.Background()
+
+	user := periods(ctx, Scope{UserUUID: "u1"})
+	if len(user) != 2 || user[0].limit != 100 || user[1].limit != 3000 {
+		t.Fatalf("登录用户额度 %+v", user)
+	}
+	anonymous := periods(ctx, Scope{AnonymousId: "a1"})
+	if anonymous[0].limit != 10 || anonymous[1].limit != 0 {
+		t.Fatalf("匿名用户额度 %+v", anonymous)
+	}
+
+	now := time.Now()
+	day, month := user[0], user[1]
+	if day.start.After(now) || !day.reset.After(now) || day.reset.Sub(day.start) < 23*time.Hour || day.start.Hour() != 0 {
+		t.Fatalf("日周期 %s ~ %s", day.start, day.reset)
+	}
+	if month.start.Day() != 1 || month.start.After(now) || !month.reset.After(now) || month.reset.Day() != 1 {
+		t.Fatalf("月周期 %s ~ %s", month.start, month.reset)
+	}
+}
+
+func TestCheckQuotaWithoutOwner(t *testing.T) {
+	// 无法识别用户时直接放行，不访问数据库
+	if err := CheckQuota(context.Background(), Scope{Source: SourceCron}); err != nil {
+		t.Fatalf("无归属的调用应放行，实际 %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package usage
+
+import (
+	v1 "backend/api/usage/v1"
+	"backend/internal/dao"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const statFields = "COUNT(*) AS calls, COALESCE(SUM(prompt_tokens), 0) AS prompt_tokens, " +
+	"COALESCE(SUM(completion_tokens), 0) AS completion_tokens, COALESCE(SUM(total_tokens), 0) AS total_tokens"
+
+// UserReport 用户最近 days 天的用量
+func UserReport(ctx context.Context, scope Scope, days int) (*v1.UsageReport, error) {
+	return report(ctx, func() *gdb.Model { return ownerModel(ctx, scope) }, days)
+}
+
+// KnowledgeReport 知识库最近 days 天的用量（索引、QA 生成、检索与带知识库的对话）
+func KnowledgeReport(ctx context.Context, knowledgeBaseId int64, days int) (*v1.UsageReport, error) {
+	return report(ctx, func() *gdb.Model {
+		return dao.LlmUsage.Ctx(ctx).Where(dao.LlmUsage.Columns().KnowledgeBaseId, knowledgeBaseId)
+	}, days)
+}
+
+// report 汇总总量、按天、按模型、按来源与按节点的用量；base 每次返回新的查询
+func report(ctx context.Context, base func() *gdb.Model, days int) (*v1.UsageReport, error) {
+	now := time.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, 1-days)
+	scoped := func() *gdb.Model {
+		return base().WhereGTE(dao.LlmUsage.Columns().CreatedAt, since)
+	}
+	res := &v1.UsageReport{
+		Daily:    []v1.UsageDaily{},
+		ByModel:  []v1.UsageGroup{},
+		BySource: []v1.UsageGroup{},
+		ByNode:   []v1.UsageGroup{},
+	}
+	if err := scoped().Fields(statFields).Scan(&res.Total); err != nil {
+		g.Log().Errorf(ctx, "统计用量失败: %v", err)
+		return nil, fmt.Errorf("统计用量失败: %w", err)
+	}
+	if err := scoped().Fields("DATE(created_at) AS date, " + statFields).
+		Group("DATE(created_at)").OrderAsc("date").Scan(&res.Daily); err != nil {
+		g.Log().Errorf(ctx, "统计每日用量失败: %v", err)
+		return nil, fmt.Errorf("统计用量失败: %w", err)
+	}
+	for i := range res.Daily {
+		// parseTime 下 DATE() 按时间返回，只保留日期部分
+		if len(res.Daily[i].Date) > len(time.DateOnly) {
+			res.Daily[i].Date = res.Daily[i].Date[:len(time.DateOnly)]
+		}
+	}
+	groups := []struct {
+		column string
+		dst    *[]v1.UsageGroup
+	}{
+		{dao.LlmUsage.Columns().Model, &res.ByModel},
+		{dao.LlmUsage.Columns().Source, &res.BySource},
+		{dao.LlmUsage.Columns().Node, &res.ByNode},
+	}
+	for _, grp := range groups {
+		err := scoped().Fields(grp.column + " AS name, " + statFields).
+			Group(grp.column).OrderDesc("total_tokens").Scan(grp.dst)
+		if err != nil {
+			g.Log().Errorf(ctx, "统计用量失败: group=%s, 错误: %v", grp.column, err)
+			return nil, fmt.Errorf("统计用量失败: %w", err)
+		}
+	}
+	return res, nil
+}
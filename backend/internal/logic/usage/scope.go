@@ -0,0 +1,34 @@
+package usage
+
+import "context"
+
+// 调用来源
+const (
+	SourceChat  = "chat"
+	SourceIndex = "index"
+	SourceCron  = "cron"
+	SourceEval  = "eval"
+	SourceAPI   = "api" // OpenAI 兼容接口
+)
+
+// Scope 用量归属：由发起调用的请求或任务写入 ctx，回调记录用量时读取
+type Scope struct {
+	Source          string
+	UserUUID        string
+	AnonymousId     string // 未登录用户的匿名标识，与 UserUUID 二者只取其一
+	SessionId       string
+	KnowledgeBaseId int64
+}
+
+type scopeCtxKey struct{}
+
+// WithScope 将用量归属写入 ctx
+func WithScope(ctx context.Context, scope Scope) context.Context {
+	return context.WithValue(ctx, scopeCtxKey{}, scope)
+}
+
+// ScopeFromContext 读取 ctx 中的用量归属，未设置时返回零值
+func ScopeFromContext(ctx context.Context) Scope {
+	scope, _ := ctx.Value(scopeCtxKey{}).(Scope)
+	return scope
+}
@@ -15,9 +15,11 @@ type ChatMessages struct {
 	Id               any         //
 	SessionUuid      any         //
 	MsgId            any         //
+	ParentMsgId      any         //
 	Content          any         //
 	MultiContent     any         //
 	IsUser           any         //
 	Timestamp        *gtime.Time //
 	ReasoningContent any         //
+	Citations        any         //
 }
@@ -11,11 +11,15 @@ import (
 
 // ChatSessions is the golang structure of table chat_sessions for DAO operations like Where/Data.
 type ChatSessions struct {
-	g.Meta    `orm:"table:chat_sessions, do:true"`
-	Id        any         //
-	Uuid      any         //
-	UserId    any         //
-	Title     any         //
-	CreatedAt *gtime.Time //
-	UpdatedAt *gtime.Time //
+	g.Meta       `orm:"table:chat_sessions, do:true"`
+	Id           any         //
+	Uuid         any         //
+	UserId       any         //
+	AnonymousId  any         //
+	ActiveMsgId  any         //
+	Summary      any         //
+	SummaryMsgId any         //
+	Title        any         //
+	CreatedAt    *gtime.Time //
+	UpdatedAt    *gtime.Time //
 }
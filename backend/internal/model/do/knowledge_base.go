@@ -11,13 +11,14 @@ import (
 
 // KnowledgeBase is the golang structure of table knowledge_base for DAO operations like Where/Data.
 type KnowledgeBase struct {
-	g.Meta      `orm:"table:knowledge_base, do:true"`
-	Id          any         //
-	UserUuid    any         //
-	Name        any         //
-	Description any         //
-	Category    any         //
-	Status      any         //
-	CreatedAt   *gtime.Time //
-	UpdatedAt   *gtime.Time //
+	g.Meta           `orm:"table:knowledge_base, do:true"`
+	Id               any         //
+	UserUuid         any         //
+	Name             any         //
+	Description      any         //
+	Category         any         //
+	Status           any         //
+	RetrievalProfile any         //
+	CreatedAt        *gtime.Time //
+	UpdatedAt        *gtime.Time //
 }
@@ -18,6 +18,7 @@ type KnowledgeChunks struct {
 	Content        any         //
 	Ext            any         //
 	Status         any         //
+	IndexStatus    any         //
 	CreatedAt      *gtime.Time //
 	UpdatedAt      *gtime.Time //
 }
@@ -14,6 +14,7 @@ type KnowledgeDocuments struct {
 	g.Meta            `orm:"table:knowledge_documents, do:true"`
 	Id                any         //
 	KnowledgeBaseName any         //
+	KnowledgeBaseId   any         //
 	FileName          any         //
 	Status            any         //
 	CreatedAt         *gtime.Time //
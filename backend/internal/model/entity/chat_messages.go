@@ -13,9 +13,11 @@ type ChatMessages struct {
 	Id               int64       `json:"id"               orm:"id"                description:""` //
 	SessionUuid      string      `json:"sessionUuid"      orm:"session_uuid"      description:""` //
 	MsgId            string      `json:"msgId"            orm:"msg_id"            description:""` //
+	ParentMsgId      string      `json:"parentMsgId"      orm:"parent_msg_id"     description:""` //
 	Content          string      `json:"content"          orm:"content"           description:""` //
 	MultiContent     string      `json:"multiContent"     orm:"multi_content"     description:""` //
 	IsUser           int         `json:"isUser"           orm:"is_user"           description:""` //
 	Timestamp        *gtime.Time `json:"timestamp"        orm:"timestamp"         description:""` //
 	ReasoningContent string      `json:"reasoningContent" orm:"reasoning_content" description:""` //
+	Citations        string      `json:"citations"        orm:"citations"         description:""` //
 }
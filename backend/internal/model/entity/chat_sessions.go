@@ -10,10 +10,14 @@ import (
 
 // ChatSessions is the golang structure for table chat_sessions.
 type ChatSessions struct {
-	Id        int64       `json:"id"        orm:"id"         description:""` //
-	Uuid      string      `json:"uuid"      orm:"uuid"       description:""` //
-	UserId    string      `json:"userId"    orm:"user_id"    description:""` //
-	Title     string      `json:"title"     orm:"title"      description:""` //
-	CreatedAt *gtime.Time `json:"createdAt" orm:"created_at" description:""` //
-	UpdatedAt *gtime.Time `json:"updatedAt" orm:"updated_at" description:""` //
+	Id           int64       `json:"id"           orm:"id"             description:""` //
+	Uuid         string      `json:"uuid"         orm:"uuid"           description:""` //
+	UserId       string      `json:"userId"       orm:"user_id"        description:""` //
+	AnonymousId  string      `json:"anonymousId"  orm:"anonymous_id"   description:""` //
+	ActiveMsgId  string      `json:"activeMsgId"  orm:"active_msg_id"  description:""` //
+	Summary      string      `json:"summary"      orm:"summary"        description:""` //
+	SummaryMsgId string      `json:"summaryMsgId" orm:"summary_msg_id" description:""` //
+	Title        string      `json:"title"        orm:"title"          description:""` //
+	CreatedAt    *gtime.Time `json:"createdAt"    orm:"created_at"     description:""` //
+	UpdatedAt    *gtime.Time `json:"updatedAt"    orm:"updated_at"     description:""` //
 }
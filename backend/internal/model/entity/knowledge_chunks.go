@@ -16,6 +16,7 @@ type KnowledgeChunks struct {
 	Content        string      `json:"content"        orm:"content"          description:""` //
 	Ext            string      `json:"ext"            orm:"ext"              description:""` //
 	Status         int         `json:"status"         orm:"status"           description:""` //
+	IndexStatus    int         `json:"indexStatus"    orm:"index_status"     description:""` //
 	CreatedAt      *gtime.Time `json:"createdAt"      orm:"created_at"       description:""` //
 	UpdatedAt      *gtime.Time `json:"updatedAt"      orm:"updated_at"       description:""` //
 }
@@ -12,6 +12,7 @@ import (
 type KnowledgeDocuments struct {
 	Id                int64       `json:"id"                orm:"id"                  description:""` //
 	KnowledgeBaseName string      `json:"knowledgeBaseName" orm:"knowledge_base_name" description:""` //
+	KnowledgeBaseId   int64       `json:"knowledgeBaseId"   orm:"knowledge_base_id"   description:""` //
 	FileName          string      `json:"fileName"          orm:"file_name"           description:""` //
 	Status            int         `json:"status"            orm:"status"              description:""` //
 	CreatedAt         *gtime.Time `json:"createdAt"         orm:"created_at"          description:""` //
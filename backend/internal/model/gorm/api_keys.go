@@ -0,0 +1,19 @@
+package gorm
+
+import "time"
+
+// ApiKeys 用户创建的 API Key，用于 OpenAI 兼容接口等非浏览器调用方；只保存 SHA-256，明文仅在创建时返回
+type ApiKeys struct {
+	ID         int64      `gorm:"primaryKey;column:id;autoIncrement"`                     // 主键
+	UserUUID   string     `gorm:"column:user_uuid;type:varchar(255);not null;index"`      // 所属用户 UUID
+	Name       string     `gorm:"column:name;type:varchar(100);not null;default:''"`      // 备注名称
+	KeyPrefix  string     `gorm:"column:key_prefix;type:varchar(32);not null;default:''"` // 明文前缀，用于列表中辨认
+	KeyHash    string     `gorm:"column:key_hash;type:char(64);not null;uniqueIndex"`     // 明文的 SHA-256
+	LastUsedAt *time.Time `gorm:"column:last_used_at;type:timestamp;null"`                // 最近使用时间
+	CreatedAt  time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`        // 创建时间
+}
+
+// TableName 设置表名
+func (ApiKeys) TableName() string {
+	return "api_keys"
+}
@@ -0,0 +1,37 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"gorm.io/gorm"
+)
+
+// backfillDocumentKnowledgeBaseID 为升级前创建的文档回填 knowledge_base_id：
+// 优先取其索引任务记录的知识库 ID，其次按知识库名称匹配（仅当该名称只属于一个知识库时）。
+// 仍无法确定归属的文档保持 0，不会出现在按知识库 ID 检索的结果中
+func backfillDocumentKnowledgeBaseID(ctx context.Context, db *gorm.DB) error {
+	byJob := db.Exec(`UPDATE knowledge_documents d
+		JOIN (SELECT documents_id, MAX(knowledge_base_id) AS kb_id FROM knowledge_index_jobs
+			WHERE knowledge_base_id > 0 GROUP BY documents_id) j ON j.documents_id = d.id
+		SET d.knowledge_base_id = j.kb_id
+		WHERE d.knowledge_base_id = 0`)
+	if byJob.Error != nil {
+		return byJob.Error
+	}
+	byName := db.Exec(`UPDATE knowledge_documents d
+		JOIN (SELECT name, MIN(id) AS kb_id FROM knowledge_base GROUP BY name HAVING COUNT(1) = 1) kb ON kb.name = d.knowledge_base_name
+		SET d.knowledge_base_id = kb.kb_id
+		WHERE d.knowledge_base_id = 0`)
+	if byName.Error != nil {
+		return byName.Error
+	}
+	if n := byJob.RowsAffected + byName.RowsAffected; n > 0 {
+		g.Log().Infof(ctx, "已回填文档所属知识库 ID: %d 条", n)
+	}
+	var orphans int64
+	if err := db.Model(&KnowledgeDocuments{}).Where("knowledge_base_id = 0").Count(&orphans).Error; err == nil && orphans > 0 {
+		g.Log().Warningf(ctx, "%d 条文档无法确定所属知识库（同名知识库属于多个用户），需人工设置 knowledge_base_id", orphans)
+	}
+	return nil
+}
@@ -4,12 +4,16 @@ import "time"
 
 // ChatSessions 聊天会话表
 type ChatSessions struct {
-	ID        int64     `gorm:"primaryKey;column:id;autoIncrement"`     // 主键
-	UUID      string    `gorm:"column:uuid;type:varchar(255);index"`    // 会话唯一标识
-	UserID    string    `gorm:"column:user_id;type:varchar(255);index"` // 所属用户 ID
-	Title     string    `gorm:"column:title;type:varchar(255)"`         // 会话标题
-	CreatedAt time.Time `gorm:"column:created_at;type:datetime"`        // 创建时间
-	UpdatedAt time.Time `gorm:"column:updated_at;type:datetime"`        // 更新时间
+	ID           int64     `gorm:"primaryKey;column:id;autoIncrement"`                              // 主键
+	UUID         string    `gorm:"column:uuid;type:varchar(255);uniqueIndex:uk_chat_sessions_uuid"` // 会话唯一标识
+	UserID       string    `gorm:"column:user_id;type:varchar(255);index"`                          // 所属用户 ID
+	AnonymousID  string    `gorm:"column:anonymous_id;type:varchar(64);index"`                      // 匿名用户标识（匿名令牌的 SHA-256）
+	ActiveMsgID  string    `gorm:"column:active_msg_id;type:varchar(255)"`                          // 当前分支末尾消息 ID
+	Summary      string    `gorm:"column:summary;type:text"`                                        // 较早对话的滚动摘要
+	SummaryMsgID string    `gorm:"column:summary_msg_id;type:varchar(255)"`                         // 摘要覆盖到的最后一条消息 ID
+	Title        string    `gorm:"column:title;type:varchar(255)"`                                  // 会话标题
+	CreatedAt    time.Time `gorm:"column:created_at;type:datetime"`                                 // 创建时间
+	UpdatedAt    time.Time `gorm:"column:updated_at;type:datetime"`                                 // 更新时间
 }
 
 func (ChatSessions) TableName() string {
@@ -0,0 +1,30 @@
+package gorm
+
+import (
+	"context"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"gorm.io/gorm"
+)
+
+// dedupeChatSessionUUID 为 chat_sessions.uuid 建唯一索引做准备，须在 AutoMigrate 之前执行：
+// 升级前的普通索引下并发创建可能留下同一 uuid 的多条会话，保留 id 最小（最先创建）的一条，
+// 消息按 session_uuid 关联，随之归属保留的会话；再删除旧的普通索引 idx_chat_sessions_uuid
+func dedupeChatSessionUUID(ctx context.Context, db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&ChatSessions{}) || migrator.HasIndex(&ChatSessions{}, "uk_chat_sessions_uuid") {
+		return nil
+	}
+	res := db.Exec(`DELETE s FROM chat_sessions s
+		JOIN chat_sessions k ON k.uuid = s.uuid AND k.id < s.id`)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected > 0 {
+		g.Log().Warningf(ctx, "已删除重复的会话记录: %d 条（同一会话 ID 保留最先创建的一条）", res.RowsAffected)
+	}
+	if migrator.HasIndex(&ChatSessions{}, "idx_chat_sessions_uuid") {
+		return migrator.DropIndex(&ChatSessions{}, "idx_chat_sessions_uuid")
+	}
+	return nil
+}
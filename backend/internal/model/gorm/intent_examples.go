@@ -0,0 +1,19 @@
+package gorm
+
+import "time"
+
+// IntentExamples 意图路由的标注样例：按意图计算向量质心，向量随样例缓存，向量模型变更后重新计算
+type IntentExamples struct {
+	ID             int64     `gorm:"primaryKey;column:id;autoIncrement"`                           // 主键
+	Intent         string    `gorm:"column:intent;type:varchar(32);not null;index"`                // 意图：emotion、task_study、plan_modify
+	Text           string    `gorm:"column:text;type:varchar(500);not null"`                       // 样例问题
+	Embedding      string    `gorm:"column:embedding;type:longtext;null"`                          // 样例向量（JSON 数组）
+	EmbeddingModel string    `gorm:"column:embedding_model;type:varchar(128);not null;default:''"` // 计算向量所用的模型
+	CreatedBy      string    `gorm:"column:created_by;type:varchar(100);not null;default:''"`      // 创建人用户名，内置样例为空
+	CreatedAt      time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime"`              // 创建时间
+}
+
+// TableName 设置表名
+func (IntentExamples) TableName() string {
+	return "intent_examples"
+}
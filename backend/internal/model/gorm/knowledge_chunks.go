@@ -12,6 +12,7 @@ type KnowledgeChunks struct {
 	Content        string    `gorm:"column:content;type:text"`                                          // 切片文本内容
 	Ext            string    `gorm:"column:ext;type:varchar(1024)"`                                     // 扩展信息（JSON），如位置、元数据等
 	Status         int8      `gorm:"column:status;type:tinyint(1);not null;default:1"`                  // 状态：1 正常
+	IndexStatus    int8      `gorm:"column:index_status;type:tinyint;not null;default:2"`               // 切片自身的索引状态，取值同文档状态：1 重新索引中，2 已索引，3 失败
 	CreateTime     time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime"`                   // 创建时间
 	UpdateTime     time.Time `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`                   // 更新时间
 
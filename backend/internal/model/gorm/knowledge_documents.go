@@ -8,6 +8,7 @@ import (
 type KnowledgeDocuments struct {
 	ID                int64     `gorm:"primaryKey;column:id;autoIncrement"`                    // 主键
 	KnowledgeBaseName string    `gorm:"column:knowledge_base_name;type:varchar(255);not null"` // 所属知识库名称
+	KnowledgeBaseID   int64     `gorm:"column:knowledge_base_id;not null;default:0;index"`     // 所属知识库 ID（知识库名称只在用户内唯一）
 	FileName          string    `gorm:"column:file_name;type:varchar(255)"`                    // 文件名或 URL
 	Status            int8      `gorm:"column:status;type:tinyint;not null;default:0"`         // 状态：0 待处理，1 索引中，2 已完成，3 失败
 	CreateTime        time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime"`       // 创建时间
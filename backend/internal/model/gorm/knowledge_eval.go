@@ -0,0 +1,49 @@
+package gorm
+
+import "time"
+
+// KnowledgeEvalQuestions 检索评测问题集，按知识库维护期望命中的 chunk 或参考答案
+type KnowledgeEvalQuestions struct {
+	ID                int64     `gorm:"primaryKey;column:id;autoIncrement"`                    // 主键
+	UserUUID          string    `gorm:"column:user_uuid;type:varchar(255);not null;index"`     // 所属用户 UUID
+	KnowledgeBaseID   int64     `gorm:"column:knowledge_base_id;not null;index"`               // 知识库 ID
+	KnowledgeBaseName string    `gorm:"column:knowledge_base_name;type:varchar(255);not null"` // 知识库名称
+	Question          string    `gorm:"column:question;type:text;not null"`                    // 问题
+	ExpectedChunkIDs  string    `gorm:"column:expected_chunk_ids;type:text"`                   // 期望命中的 chunk_id 列表（JSON 数组）
+	ReferenceAnswer   string    `gorm:"column:reference_answer;type:text"`                     // 参考答案（无期望 chunk 时按文本重合判定相关）
+	CreatedAt         time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime"`       // 创建时间
+	UpdatedAt         time.Time `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`       // 更新时间
+}
+
+// TableName 设置表名
+func (KnowledgeEvalQuestions) TableName() string {
+	return "knowledge_eval_questions"
+}
+
+// KnowledgeEvalRuns 检索评测运行记录：汇总指标、逐题结果与当次检索管线配置
+type KnowledgeEvalRuns struct {
+	ID                int64      `gorm:"primaryKey;column:id;autoIncrement"`                    // 主键
+	RunID             string     `gorm:"column:run_id;type:varchar(64);not null;uniqueIndex"`   // 运行唯一 ID（对外返回）
+	UserUUID          string     `gorm:"column:user_uuid;type:varchar(255);not null;index"`     // 所属用户 UUID
+	KnowledgeBaseID   int64      `gorm:"column:knowledge_base_id;not null;index"`               // 知识库 ID
+	KnowledgeBaseName string     `gorm:"column:knowledge_base_name;type:varchar(255);not null"` // 知识库名称
+	Status            int8       `gorm:"column:status;type:tinyint;not null;default:0"`         // 状态：0 执行中，1 成功，2 失败
+	TopK              int        `gorm:"column:top_k;not null;default:5"`                       // 评测的 k
+	Config            string     `gorm:"column:config;type:text"`                               // 检索管线配置（JSON）
+	Note              string     `gorm:"column:note;type:varchar(255)"`                         // 备注，如本次调整的内容
+	QuestionCount     int        `gorm:"column:question_count;not null;default:0"`              // 评测问题数
+	UnlabeledCount    int        `gorm:"column:unlabeled_count;not null;default:0"`             // 未标注出相关 chunk、不计入平均指标的问题数
+	Recall            float64    `gorm:"column:recall;not null;default:0"`                      // 平均 recall@k
+	Mrr               float64    `gorm:"column:mrr;not null;default:0"`                         // 平均 MRR
+	Ndcg              float64    `gorm:"column:ndcg;not null;default:0"`                        // 平均 nDCG@k
+	Details           string     `gorm:"column:details;type:longtext"`                          // 逐题结果（JSON）
+	Error             string     `gorm:"column:error;type:text"`                                // 失败原因
+	DurationMs        int64      `gorm:"column:duration_ms;not null;default:0"`                 // 耗时（毫秒）
+	FinishedAt        *time.Time `gorm:"column:finished_at;type:datetime"`                      // 结束时间
+	CreatedAt         time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`       // 创建时间
+}
+
+// TableName 设置表名
+func (KnowledgeEvalRuns) TableName() string {
+	return "knowledge_eval_runs"
+}
@@ -0,0 +1,35 @@
+package gorm
+
+import "time"
+
+// KnowledgeIndexJobs 文档索引任务表，记录异步索引各阶段进度，支持失败重试与重启后续跑
+type KnowledgeIndexJobs struct {
+	ID                int64      `gorm:"primaryKey;column:id;autoIncrement"`                    // 主键
+	JobID             string     `gorm:"column:job_id;type:varchar(64);not null;uniqueIndex"`   // 任务唯一 ID（对外返回）
+	UserUUID          string     `gorm:"column:user_uuid;type:varchar(255);not null;index"`     // 提交用户 UUID
+	KnowledgeBaseID   int64      `gorm:"column:knowledge_base_id;not null"`                     // 知识库 ID
+	KnowledgeBaseName string     `gorm:"column:knowledge_base_name;type:varchar(255);not null"` // 知识库名称
+	DocumentsID       int64      `gorm:"column:documents_id;not null;index"`                    // 关联 knowledge_documents.id
+	FileName          string     `gorm:"column:file_name;type:varchar(255)"`                    // 文件名或 URL
+	Source            string     `gorm:"column:source;type:varchar(1024);not null"`             // 原始文件路径或 URL
+	WorkURI           string     `gorm:"column:work_uri;type:varchar(1024)"`                    // 解析后实际索引的路径（PDF 为 Markdown）
+	Status            int8       `gorm:"column:status;type:tinyint;not null;default:0;index"`   // 状态：0 排队，1 执行中，2 成功，3 失败
+	Stage             string     `gorm:"column:stage;type:varchar(20);not null;default:''"`     // 当前阶段：extract/split/embed/store/qa/done
+	ProgressDone      int        `gorm:"column:progress_done;not null;default:0"`               // 当前阶段已完成数量
+	ProgressTotal     int        `gorm:"column:progress_total;not null;default:0"`              // 当前阶段总数量
+	ChunkCount        int        `gorm:"column:chunk_count;not null;default:0"`                 // 切分得到的 chunk 数
+	Attempts          int        `gorm:"column:attempts;not null;default:0"`                    // 已执行次数
+	MaxAttempts       int        `gorm:"column:max_attempts;not null;default:3"`                // 最大执行次数
+	Error             string     `gorm:"column:error;type:text"`                                // 最近一次失败原因
+	NextRunAt         *time.Time `gorm:"column:next_run_at;type:datetime"`                      // 重试时间
+	Owner             string     `gorm:"column:owner;type:varchar(64);not null;default:''"`     // 执行中任务所属的服务实例
+	LeaseUntil        *time.Time `gorm:"column:lease_until;type:datetime"`                      // 执行租约到期时间，执行实例定期续约
+	FinishedAt        *time.Time `gorm:"column:finished_at;type:datetime"`                      // 结束时间
+	CreatedAt         time.Time  `gorm:"column:created_at;type:timestamp;autoCreateTime"`       // 创建时间
+	UpdatedAt         time.Time  `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`       // 更新时间
+}
+
+// TableName 设置表名
+func (KnowledgeIndexJobs) TableName() string {
+	return "knowledge_index_jobs"
+}
@@ -0,0 +1,29 @@
+package gorm
+
+import "time"
+
+// LlmUsage 模型调用用量：每次对话模型、向量化或重排调用一条，按用户、会话、知识库、图节点与模型归属
+type LlmUsage struct {
+	ID               int64     `gorm:"primaryKey;column:id;autoIncrement"`                             // 主键
+	UserUUID         string    `gorm:"column:user_uuid;type:varchar(255);not null;default:'';index"`   // 所属用户 UUID
+	AnonymousID      string    `gorm:"column:anonymous_id;type:varchar(64);not null;default:'';index"` // 匿名用户标识（匿名令牌的 SHA-256）
+	SessionID        string    `gorm:"column:session_id;type:varchar(255);not null;default:'';index"`  // 会话 ID
+	KnowledgeBaseID  int64     `gorm:"column:knowledge_base_id;not null;default:0;index"`              // 知识库 ID
+	Source           string    `gorm:"column:source;type:varchar(32);not null;default:''"`             // 调用来源：chat、index、cron、eval
+	Graph            string    `gorm:"column:graph;type:varchar(128);not null;default:''"`             // 所在的图
+	Node             string    `gorm:"column:node;type:varchar(128);not null;default:''"`              // 图节点或组件名称
+	Component        string    `gorm:"column:component;type:varchar(32);not null;default:''"`          // 组件类型：ChatModel、Embedding、Rerank
+	Model            string    `gorm:"column:model;type:varchar(128);not null;default:''"`             // 模型名称
+	PromptTokens     int       `gorm:"column:prompt_tokens;not null;default:0"`                        // 输入 token
+	CompletionTokens int       `gorm:"column:completion_tokens;not null;default:0"`                    // 输出 token
+	TotalTokens      int       `gorm:"column:total_tokens;not null;default:0"`                         // 合计 token
+	LatencyMs        int64     `gorm:"column:latency_ms;not null;default:0"`                           // 耗时（毫秒，流式为到流结束）
+	Estimated        int8      `gorm:"column:estimated;type:tinyint;not null;default:0"`               // 1 表示接口未返回用量，按文本长度估算
+	Failed           int8      `gorm:"column:failed;type:tinyint;not null;default:0"`                  // 1 表示调用失败
+	CreatedAt        time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime;index"`          // 调用时间
+}
+
+// TableName 设置表名
+func (LlmUsage) TableName() string {
+	return "llm_usage"
+}
@@ -19,12 +19,19 @@ var ProjectTables = []any{
 	&KnowledgeBase{},
 	&KnowledgeDocuments{},
 	&KnowledgeChunks{},
+	&KnowledgeIndexJobs{},
+	&KnowledgeEvalQuestions{},
+	&KnowledgeEvalRuns{},
 	&KnowledgeBaseCronSchedule{},
 	&CronLog{},
 	&CronExecute{},
 	&Files{},
 	&UserSettings{},
 	&DocumentVectors{},
+	&LlmUsage{},
+	&ApiKeys{},
+	&PromptTemplates{},
+	&IntentExamples{},
 }
 
 // tableOptions 建表选项：表及所有字段继承 utf8mb4 + utf8mb4_unicode_ci
@@ -54,10 +54,18 @@ func RunMigrateOnStartup(ctx context.Context) error {
 		g.Log().Info(ctx, "表已存在，无需建表")
 	}
 
+	if err := dedupeChatSessionUUID(ctx, db); err != nil {
+		return err
+	}
+
 	if err := AutoMigrate(db); err != nil {
 		return err
 	}
 
+	if err := backfillDocumentKnowledgeBaseID(ctx, db); err != nil {
+		g.Log().Warningf(ctx, "回填文档所属知识库 ID 失败: %v", err)
+	}
+
 	if err := seedTestUserIfAbsent(ctx, db); err != nil {
 		g.Log().Warningf(ctx, "插入默认 test 用户失败（可忽略或检查 users 表）: %v", err)
 	}
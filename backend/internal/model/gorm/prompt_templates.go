@@ -0,0 +1,23 @@
+package gorm
+
+import "time"
+
+// PromptTemplates 提示词模板版本：按节点与作用范围（全局、知识库、用户）分别编号，每个范围最多一个生效版本
+type PromptTemplates struct {
+	ID        int64     `gorm:"primaryKey;column:id;autoIncrement"`                                                     // 主键
+	Node      string    `gorm:"column:node;type:varchar(64);not null;index:idx_prompt_scope,priority:1"`                // 节点名称，如 analysis、coach
+	Scope     string    `gorm:"column:scope;type:varchar(16);not null;index:idx_prompt_scope,priority:2"`               // 作用范围：global、knowledge、user
+	ScopeID   string    `gorm:"column:scope_id;type:varchar(64);not null;default:'';index:idx_prompt_scope,priority:3"` // 知识库 ID 或用户 UUID，全局为空
+	Version   int       `gorm:"column:version;type:int;not null"`                                                       // 同一节点与范围内递增的版本号
+	Content   string    `gorm:"column:content;type:longtext;not null"`                                                  // 系统提示词内容
+	Remark    string    `gorm:"column:remark;type:varchar(255);not null;default:''"`                                    // 版本说明
+	IsActive  int       `gorm:"column:is_active;type:tinyint;not null;default:0"`                                       // 是否为生效版本
+	CreatedBy string    `gorm:"column:created_by;type:varchar(100);not null;default:''"`                                // 创建人用户名
+	CreatedAt time.Time `gorm:"column:created_at;type:timestamp;autoCreateTime"`                                        // 创建时间
+	UpdatedAt time.Time `gorm:"column:updated_at;type:timestamp;autoUpdateTime"`                                        // 更新时间
+}
+
+// TableName 设置表名
+func (PromptTemplates) TableName() string {
+	return "prompt_templates"
+}
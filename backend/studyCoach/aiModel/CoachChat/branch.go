@@ -1,15 +1,35 @@
 package CoachChat
 
 import (
+	"backend/internal/logic/intent"
+	"backend/studyCoach/common"
 	"context"
 	"fmt"
 	"log"
 	"strings"
 
+	"github.com/cloudwego/eino/components"
 	"github.com/cloudwego/eino/schema"
 )
 
-// newBranch 路由分支：使用原始问题而非意图分析结果，确保识别「修改计划」等语义
+// intentTargets 意图对应的分支节点，同时作为 AddBranch 注册的分支目标
+var intentTargets = map[string]string{
+	intent.Emotion:    "EmotionAndCompanionShipLambda",
+	intent.TaskStudy:  "TaskStudyLambda",
+	intent.PlanModify: "PlanModifyLambda",
+}
+
+// branchEndNodes AddBranch 注册的分支目标
+func branchEndNodes() map[string]bool {
+	nodes := make(map[string]bool, len(intentTargets))
+	for _, node := range intentTargets {
+		nodes[node] = true
+	}
+	return nodes
+}
+
+// newBranch 路由分支：使用原始问题而非意图分析结果，确保识别「修改计划」等语义；
+// 由意图路由按向量分类，置信度不足时才调用 LLM 判断
 func newBranch(ctx context.Context, input *schema.Message) (endNode string, err error) {
 	content := ""
 	if q := ctx.Value("question"); q != nil {
@@ -21,10 +41,20 @@ func newBranch(ctx context.Context, input *schema.Message) (endNode string, err
 		content = strings.ToLower(input.Content)
 	}
 	log.Printf("[newBranch] 开始分支判断 (question=%s)", content)
-	log.Println("AnalysisChatModel分支输出")
+	d := intent.Route(ctx, content, func(ctx context.Context) (string, error) {
+		return llmBranch(ctx, content)
+	})
+	endNode = intentTargets[d.Intent]
+	log.Printf("[newBranch] 分支判断完成 - 意图: %s, 来源: %s, 节点: %s", d.Intent, d.Source, endNode)
+	return endNode, nil
+}
+
+// llmBranch LLM 分支判断：输出须为已注册的分支节点名，返回对应的意图
+func llmBranch(ctx context.Context, content string) (string, error) {
 	param := map[string]interface{}{
 		"question":     content,
 		"chat_history": ctx.Value("chat_history"),
+		"summary":      ctx.Value("summary"),
 	}
 	model, err := BranchNewChatModel(ctx)
 	if err != nil {
@@ -38,11 +68,21 @@ func newBranch(ctx context.Context, input *schema.Message) (endNode string, err
 	if err != nil {
 		return "", err
 	}
-	generate, err := model.Generate(ctx, format)
+	generate, err := model.Generate(common.WithCallName(ctx, "IntentBranch", components.ComponentOfChatModel), format)
 	if err != nil {
 		return "", err
 	}
-	log.Printf("[newBranch] 分支判断完成 - 结果: %s", generate.Content)
 	log.Println("Branch结果分析为：", generate.Content)
-	return generate.Content, nil
+	return branchIntent(generate.Content)
+}
+
+// branchIntent 将 LLM 输出的节点名映射为意图，忽略首尾空白、引号与大小写；未注册的节点返回错误
+func branchIntent(output string) (string, error) {
+	node := strings.Trim(output, " \t\r\n`'\"")
+	for name, target := range intentTargets {
+		if strings.EqualFold(node, target) {
+			return name, nil
+		}
+	}
+	return "", fmt.Errorf("LLM 分支判断返回了未注册的节点: %q", output)
 }
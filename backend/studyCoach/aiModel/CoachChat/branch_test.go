@@ -0,0 +1,37 @@
+package CoachChat
+
+import (
+	"backend/internal/logic/intent"
+	"testing"
+)
+
+func TestBranchIntent(t *testing.T) {
+	// LLM 输出忽略首尾空白、引号与大小写后须为已注册的分支节点
+	valid := map[string]string{
+		"PlanModifyLambda":                  intent.PlanModify,
+		"  `taskstudylambda`\n":             intent.TaskStudy,
+		"\"EmotionAndCompanionShipLambda\"": intent.Emotion,
+	}
+	for output, want := range valid {
+		got, err := branchIntent(output)
+		if err != nil || got != want {
+			t.Fatalf("输出 %q 应映射为 %s，实际 %s (%v)", output, want, got, err)
+		}
+	}
+	for _, output := range []string{"", "ChatLambda", "PlanModifyLambda，因为用户想修改计划"} {
+		if got, err := branchIntent(output); err == nil {
+			t.Fatalf("未注册的节点 %q 应返回错误，实际映射为 %s", output, got)
+		}
+	}
+}
+
+func TestIntentTargetsRegistered(t *testing.T) {
+	// 每个意图都对应 AddBranch 注册的分支目标，路由结果不会使图执行失败
+	nodes := branchEndNodes()
+	for _, name := range intent.Intents {
+		target, ok := intentTargets[name]
+		if !ok || !nodes[target] {
+			t.Fatalf("意图 %s 没有注册的分支目标", name)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package CoachChat
 
 import (
 	"backend/studyCoach/aiModel/eino_tools/filesystem"
+	"backend/studyCoach/aiModel/eino_tools/mcptool"
 	"backend/studyCoach/aiModel/eino_tools/plantask"
 	"backend/studyCoach/aiModel/eino_tools/skill"
 	"backend/studyCoach/aiModel/eino_tools/studyplan"
@@ -14,8 +15,28 @@ import (
 	"github.com/cloudwego/eino/flow/agent/react"
 )
 
+// Agent 名称：工具审批记录按名称在恢复时重建对应 Agent
+const (
+	reActAgentName      = "CoachReActAgent"
+	planModifyAgentName = "CoachPlanModifyAgent"
+)
+
+func init() {
+	common.RegisterAgent(reActAgentName, newReActAgent)
+	common.RegisterAgent(planModifyAgentName, newPlanModifyAgent)
+}
+
 // newLambda3 component initialization function of node 'ReActLambda' in graph 'StudyCoachFor'
-func newLambda3(ctx context.Context, conf *common.Config) (lba *compose.Lambda, err error) {
+func newLambda3(ctx context.Context) (lba *compose.Lambda, err error) {
+	ins, err := newReActAgent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return common.BuildAgentLambda(ctx, reActAgentName, ins)
+}
+
+// newReActAgent ReActLambda 使用的 Agent，工具等待审批后按 reActAgentName 重建并恢复
+func newReActAgent(ctx context.Context) (*react.Agent, error) {
 	// 从上下文中获取isNetwork参数
 	isNetwork := false
 	if val := ctx.Value("isNetwork"); val != nil {
@@ -29,15 +50,16 @@ func newLambda3(ctx context.Context, conf *common.Config) (lba *compose.Lambda,
 		MaxStep:               100,
 		StreamToolCallChecker: common.DrainStreamChecker,
 	}
-	chatModelIns11, err := newChatModel2(ctx, conf)
+	chatModelIns11, err := newChatModel2(ctx)
 	if err != nil {
 		return nil, err
 	}
 	config.ToolCallingModel = chatModelIns11
 
-	// 注入工具调用通知中间件，实现 Generate 模式下的实时 tool_status 推送
+	// 注入工具审批与调用通知中间件：需审批的工具确认后才执行，实现 Generate 模式下的实时 tool_status 推送
 	config.ToolsConfig.ToolCallMiddlewares = append(
 		config.ToolsConfig.ToolCallMiddlewares,
+		common.BuildApprovalMiddleware(),
 		common.BuildNotifyMiddleware(),
 	)
 
@@ -70,6 +92,13 @@ func newLambda3(ctx context.Context, conf *common.Config) (lba *compose.Lambda,
 	} else {
 		log.Printf("[ReActLambda] Filesystem 工具加载失败(跳过): %v", err)
 	}
+	// MCP 工具：按 mcp.modes.coach 白名单挂载外部 MCP 服务的工具
+	if mcpTools, err := mcptool.NewTools(ctx, mcptool.ModeCoach); err == nil && len(mcpTools) > 0 {
+		config.ToolsConfig.Tools = append(config.ToolsConfig.Tools, mcpTools...)
+		log.Printf("[ReActLambda] 已添加 MCP 工具 %d 个", len(mcpTools))
+	} else if err != nil {
+		log.Printf("[ReActLambda] MCP 工具加载失败(跳过): %v", err)
+	}
 
 	if isNetwork {
 		toolIns21, err := NewTool(ctx)
@@ -82,32 +111,34 @@ func newLambda3(ctx context.Context, conf *common.Config) (lba *compose.Lambda,
 		log.Printf("[ReActLambda] 网络搜索未启用")
 	}
 
-	ins, err := react.NewAgent(ctx, config)
-	if err != nil {
-		return nil, err
-	}
-	lba, err = compose.AnyLambda(ins.Generate, common.BuildGenToStream(ins), nil, nil)
+	return react.NewAgent(ctx, config)
+}
+
+// newLambda4 PlanModifyModel：修改、增加、删除现有计划，含 filesystem 支持
+func newLambda4(ctx context.Context) (lba *compose.Lambda, err error) {
+	ins, err := newPlanModifyAgent(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return lba, nil
+	return common.BuildAgentLambda(ctx, planModifyAgentName, ins)
 }
 
-// newLambda4 PlanModifyModel：修改、增加、删除现有计划，含 filesystem 支持
-func newLambda4(ctx context.Context, conf *common.Config) (lba *compose.Lambda, err error) {
+// newPlanModifyAgent PlanModifyModel 使用的 Agent
+func newPlanModifyAgent(ctx context.Context) (*react.Agent, error) {
 	config := &react.AgentConfig{
 		MaxStep:               100,
 		StreamToolCallChecker: common.DrainStreamChecker,
 	}
-	chatModelIns11, err := newChatModel3(ctx, conf)
+	chatModelIns11, err := newChatModel3(ctx)
 	if err != nil {
 		return nil, err
 	}
 	config.ToolCallingModel = chatModelIns11
 
-	// 注入工具调用通知中间件
+	// 注入工具审批与调用通知中间件
 	config.ToolsConfig.ToolCallMiddlewares = append(
 		config.ToolsConfig.ToolCallMiddlewares,
+		common.BuildApprovalMiddleware(),
 		common.BuildNotifyMiddleware(),
 	)
 
@@ -146,14 +177,13 @@ func newLambda4(ctx context.Context, conf *common.Config) (lba *compose.Lambda,
 	} else {
 		log.Printf("[PlanModifyModel] Filesystem 工具加载失败(跳过): %v", err)
 	}
-
-	ins, err := react.NewAgent(ctx, config)
-	if err != nil {
-		return nil, err
-	}
-	lba, err = compose.AnyLambda(ins.Generate, common.BuildGenToStream(ins), nil, nil)
-	if err != nil {
-		return nil, err
+	// MCP 工具
+	if mcpTools, err := mcptool.NewTools(ctx, mcptool.ModeCoach); err == nil && len(mcpTools) > 0 {
+		config.ToolsConfig.Tools = append(config.ToolsConfig.Tools, mcpTools...)
+		log.Printf("[PlanModifyModel] 已添加 MCP 工具 %d 个", len(mcpTools))
+	} else if err != nil {
+		log.Printf("[PlanModifyModel] MCP 工具加载失败(跳过): %v", err)
 	}
-	return lba, nil
+
+	return react.NewAgent(ctx, config)
 }
@@ -19,6 +19,7 @@ func newLambda(ctx context.Context, input *schema.Message) (output map[string]an
 	output = common.GetSafeTemplateParams()
 	output["question"] = input.Content
 	output["chat_history"] = ctx.Value("chat_history")
+	output["summary"] = ctx.Value("summary")
 	output["knowledge"] = ctx.Value("knowledge")
 	output["current_time"] = common.GetCurrentTimeString()
 	log.Println("EmotionAndCompanionShipLambda已处理消息")
@@ -35,6 +36,7 @@ func newLambda1(ctx context.Context, input *schema.Message) (output map[string]a
 	output = common.GetSafeTemplateParams()
 	output["question"] = input.Content
 	output["chat_history"] = ctx.Value("chat_history")
+	output["summary"] = ctx.Value("summary")
 	output["knowledge"] = ctx.Value("knowledge")
 	output["current_time"] = common.GetCurrentTimeString()
 	log.Println("ChatLambda已处理消息")
@@ -49,6 +51,7 @@ func newLambda2(ctx context.Context, input *schema.Message) (output map[string]a
 	output = common.GetSafeTemplateParams()
 	output["question"] = input.Content
 	output["chat_history"] = ctx.Value("chat_history")
+	output["summary"] = ctx.Value("summary")
 	output["knowledge"] = ctx.Value("knowledge")
 	output["current_time"] = common.GetCurrentTimeString()
 	log.Println("PlanModifyLambda 已处理消息")
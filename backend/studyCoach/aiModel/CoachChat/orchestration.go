@@ -1,14 +1,13 @@
 package CoachChat
 
 import (
-	"backend/studyCoach/common"
 	"context"
 
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 )
 
-func BuildstudyCoachFor(ctx context.Context, conf *common.Config) (r compose.Runnable[map[string]any, *schema.Message], err error) {
+func BuildstudyCoachFor(ctx context.Context) (r compose.Runnable[map[string]any, *schema.Message], err error) {
 	const (
 		AnalysisChatTemplate            = "AnalysisChatTemplate"
 		AnalysisChatModel               = "AnalysisChatModel"
@@ -28,29 +27,29 @@ func BuildstudyCoachFor(ctx context.Context, conf *common.Config) (r compose.Run
 		return nil, err
 	}
 	_ = g.AddChatTemplateNode(AnalysisChatTemplate, analysisChatTemplateKeyOfChatTemplate)
-	analysisChatModelKeyOfChatModel, err := newChatModel(ctx, conf)
+	analysisChatModelKeyOfChatModel, err := newChatModel(ctx)
 	if err != nil {
 		return nil, err
 	}
-	_ = g.AddChatModelNode(AnalysisChatModel, analysisChatModelKeyOfChatModel)
+	_ = g.AddChatModelNode(AnalysisChatModel, analysisChatModelKeyOfChatModel, compose.WithNodeName(AnalysisChatModel))
 	_ = g.AddLambdaNode(EmotionAndCompanionShipLambda, compose.InvokableLambda(newLambda))
 	_ = g.AddLambdaNode(TaskStudyLambda, compose.InvokableLambda(newLambda1))
 	_ = g.AddLambdaNode(PlanModifyLambda, compose.InvokableLambda(newLambda2))
-	emotionAndCompanionChatModelKeyOfChatModel, err := newChatModel1(ctx, conf)
+	emotionAndCompanionChatModelKeyOfChatModel, err := newChatModel1(ctx)
 	if err != nil {
 		return nil, err
 	}
-	_ = g.AddChatModelNode(EmotionAndCompanionChatModel, emotionAndCompanionChatModelKeyOfChatModel)
+	_ = g.AddChatModelNode(EmotionAndCompanionChatModel, emotionAndCompanionChatModelKeyOfChatModel, compose.WithNodeName(EmotionAndCompanionChatModel))
 	taskChatTemplateKeyOfChatTemplate, err := newChatTemplate1(ctx)
 	if err != nil {
 		return nil, err
 	}
 	_ = g.AddChatTemplateNode(TaskChatTemplate, taskChatTemplateKeyOfChatTemplate)
-	reActLambdaKeyOfLambda, err := newLambda3(ctx, conf)
+	reActLambdaKeyOfLambda, err := newLambda3(ctx)
 	if err != nil {
 		return nil, err
 	}
-	_ = g.AddLambdaNode(ReActLambda, reActLambdaKeyOfLambda)
+	_ = g.AddLambdaNode(ReActLambda, reActLambdaKeyOfLambda, compose.WithNodeName(ReActLambda))
 	planModifyTemplateKeyOfChatTemplate, err := newChatTemplate2(ctx)
 	if err != nil {
 		return nil, err
@@ -61,11 +60,11 @@ func BuildstudyCoachFor(ctx context.Context, conf *common.Config) (r compose.Run
 		return nil, err
 	}
 	_ = g.AddChatTemplateNode(EmotionAndCompanionShipTemplate, emotionAndCompanionShipTemplateKeyOfChatTemplate)
-	planModifyModelKeyOfLambda, err := newLambda4(ctx, conf)
+	planModifyModelKeyOfLambda, err := newLambda4(ctx)
 	if err != nil {
 		return nil, err
 	}
-	_ = g.AddLambdaNode(PlanModifyModel, planModifyModelKeyOfLambda)
+	_ = g.AddLambdaNode(PlanModifyModel, planModifyModelKeyOfLambda, compose.WithNodeName(PlanModifyModel))
 	_ = g.AddEdge(compose.START, AnalysisChatTemplate)
 	_ = g.AddEdge(EmotionAndCompanionChatModel, compose.END)
 	_ = g.AddEdge(ReActLambda, compose.END)
@@ -77,7 +76,7 @@ func BuildstudyCoachFor(ctx context.Context, conf *common.Config) (r compose.Run
 	_ = g.AddEdge(EmotionAndCompanionShipTemplate, EmotionAndCompanionChatModel)
 	_ = g.AddEdge(TaskChatTemplate, ReActLambda)
 	_ = g.AddEdge(PlanModifyTemplate, PlanModifyModel)
-	_ = g.AddBranch(AnalysisChatModel, compose.NewGraphBranch(newBranch, map[string]bool{EmotionAndCompanionShipLambda: true, TaskStudyLambda: true, PlanModifyLambda: true}))
+	_ = g.AddBranch(AnalysisChatModel, compose.NewGraphBranch(newBranch, branchEndNodes()))
 	r, err = g.Compile(ctx, compose.WithGraphName("StudyCoachFor"))
 	if err != nil {
 		return nil, err
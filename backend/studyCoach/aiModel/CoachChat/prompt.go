@@ -1,6 +1,7 @@
 package CoachChat
 
 import (
+	"backend/internal/logic/prompts"
 	"backend/studyCoach/common"
 	"context"
 	"fmt"
@@ -23,9 +24,20 @@ type ChatTemplateConfig struct {
 	Role       schema.RoleType
 	System     schema.RoleType
 	FormatType schema.FormatType
+	Node       string // 提示词节点，系统提示词在 Format 时按请求解析
+	Fallback   string // 没有生效版本时使用的系统提示词
 	Templates  []schema.MessagesTemplate
 }
 
+// withSystem 在模板前加上节点当前生效的系统提示词，没有生效版本时使用 fallback
+func withSystem(ctx context.Context, node, fallback string, templates []schema.MessagesTemplate) []schema.MessagesTemplate {
+	system, ok := prompts.Active(ctx, node)
+	if !ok {
+		system = fallback
+	}
+	return append([]schema.MessagesTemplate{schema.SystemMessage(system)}, templates...)
+}
+
 // 分析用户问题模版
 // newChatTemplate component initialization function of node 'AnalysisChatTemplate' in graph 'studyCoachFor'
 func newChatTemplate(ctx context.Context) (ctp prompt.ChatTemplate, err error) {
@@ -33,8 +45,10 @@ func newChatTemplate(ctx context.Context) (ctp prompt.ChatTemplate, err error) {
 		Role:       schema.User,
 		System:     schema.System,
 		FormatType: schema.FString,
+		Node:       prompts.NodeAnalysis, // 专门的意图分析提示词
+		Fallback:   common.AnalysisSystemTemplate,
 		Templates: []schema.MessagesTemplate{
-			schema.SystemMessage(common.AnalysisSystemTemplate), // 专门的意图分析提示词
+			schema.MessagesPlaceholder("summary", true),
 			schema.MessagesPlaceholder("chat_history", true),
 			schema.UserMessage(common.UserQuestion),
 		},
@@ -45,7 +59,7 @@ func newChatTemplate(ctx context.Context) (ctp prompt.ChatTemplate, err error) {
 
 func (impl *ChatTemplateImpl) Format(ctx context.Context, vs map[string]any, opts ...prompt.Option) ([]*schema.Message, error) {
 	/*初始化模版*/
-	template := prompt.FromMessages(impl.config.FormatType, impl.config.Templates...) //修改了此处
+	template := prompt.FromMessages(impl.config.FormatType, withSystem(ctx, impl.config.Node, impl.config.Fallback, impl.config.Templates)...) //修改了此处
 	format, err := template.Format(ctx, vs)
 	if err != nil {
 		return nil, fmt.Errorf("提示工程构建失败: %w", err)
@@ -65,6 +79,8 @@ type ChatTemplate1Config struct {
 	Role       schema.RoleType
 	System     schema.RoleType
 	FormatType schema.FormatType
+	Node       string // 提示词节点，系统提示词在 Format 时按请求解析
+	Fallback   string // 没有生效版本时使用的系统提示词
 	Templates  []schema.MessagesTemplate
 }
 
@@ -76,8 +92,10 @@ func newChatTemplate1(ctx context.Context) (ctp prompt.ChatTemplate, err error)
 		Role:       schema.User,
 		System:     schema.System,
 		FormatType: schema.FString,
+		Node:       prompts.NodeCoach,
+		Fallback:   common.SystemCoachTemplate,
 		Templates: []schema.MessagesTemplate{
-			schema.SystemMessage(common.SystemCoachTemplate),
+			schema.MessagesPlaceholder("summary", true),
 			schema.MessagesPlaceholder("chat_history", true),
 			schema.UserMessage(common.UserQuestion),
 		},
@@ -88,7 +106,7 @@ func newChatTemplate1(ctx context.Context) (ctp prompt.ChatTemplate, err error)
 
 func (impl *ChatTemplate1Impl) Format(ctx context.Context, vs map[string]any, opts ...prompt.Option) ([]*schema.Message, error) {
 	/*初始化模版*/
-	template := prompt.FromMessages(impl.config.FormatType, impl.config.Templates...)
+	template := prompt.FromMessages(impl.config.FormatType, withSystem(ctx, impl.config.Node, impl.config.Fallback, impl.config.Templates)...)
 	format, err := template.Format(ctx, vs)
 	if err != nil {
 		return nil, fmt.Errorf("提示工程构建失败: %w", err)
@@ -109,6 +127,8 @@ type BranchChatTemplateConfig struct {
 	Role       schema.RoleType
 	System     schema.RoleType
 	FormatType schema.FormatType
+	Node       string // 提示词节点，系统提示词在 Format 时按请求解析
+	Fallback   string // 没有生效版本时使用的系统提示词
 	Templates  []schema.MessagesTemplate
 }
 
@@ -118,8 +138,10 @@ func BranchChatTemplate(ctx context.Context) (ctp prompt.ChatTemplate, err error
 		Role:       schema.User,
 		System:     schema.System,
 		FormatType: schema.FString,
+		Node:       prompts.NodeBranch,
+		Fallback:   common.BranchSystemTemplate,
 		Templates: []schema.MessagesTemplate{
-			schema.SystemMessage(common.BranchSystemTemplate),
+			schema.MessagesPlaceholder("summary", true),
 			schema.MessagesPlaceholder("chat_history", true),
 			schema.UserMessage(common.BranchAsrQuestion),
 		},
@@ -128,7 +150,7 @@ func BranchChatTemplate(ctx context.Context) (ctp prompt.ChatTemplate, err error
 	return ctp, nil
 }
 func (impl *BranchChatTemplateImpl) Format(ctx context.Context, vs map[string]any, opts ...prompt.Option) ([]*schema.Message, error) {
-	template := prompt.FromMessages(impl.config.FormatType, impl.config.Templates...)
+	template := prompt.FromMessages(impl.config.FormatType, withSystem(ctx, impl.config.Node, impl.config.Fallback, impl.config.Templates)...)
 	format, err := template.Format(ctx, vs)
 	if err != nil {
 		return nil, fmt.Errorf("提示工程构建失败: %w", err)
@@ -148,6 +170,8 @@ type ChatTemplate2Config struct {
 	Role       schema.RoleType
 	System     schema.RoleType
 	FormatType schema.FormatType
+	Node       string // 提示词节点，系统提示词在 Format 时按请求解析
+	Fallback   string // 没有生效版本时使用的系统提示词
 	Templates  []schema.MessagesTemplate
 }
 
@@ -157,8 +181,10 @@ func newChatTemplate2(ctx context.Context) (ctp prompt.ChatTemplate, err error)
 		Role:       schema.User,
 		System:     schema.System,
 		FormatType: schema.FString,
+		Node:       prompts.NodeCoach,
+		Fallback:   common.SystemCoachTemplate,
 		Templates: []schema.MessagesTemplate{
-			schema.SystemMessage(common.SystemCoachTemplate),
+			schema.MessagesPlaceholder("summary", true),
 			schema.MessagesPlaceholder("chat_history", true),
 			schema.UserMessage(common.UserQuestion),
 		},
@@ -168,7 +194,7 @@ func newChatTemplate2(ctx context.Context) (ctp prompt.ChatTemplate, err error)
 }
 
 func (impl *ChatTemplate2Impl) Format(ctx context.Context, vs map[string]any, opts ...prompt.Option) ([]*schema.Message, error) {
-	template := prompt.FromMessages(impl.config.FormatType, impl.config.Templates...)
+	template := prompt.FromMessages(impl.config.FormatType, withSystem(ctx, impl.config.Node, impl.config.Fallback, impl.config.Templates)...)
 	format, err := template.Format(ctx, vs)
 	if err != nil {
 		return nil, fmt.Errorf("提示工程构建失败: %w", err)
@@ -188,6 +214,8 @@ type ChatTemplate3Config struct {
 	Role       schema.RoleType
 	System     schema.RoleType
 	FormatType schema.FormatType
+	Node       string // 提示词节点，系统提示词在 Format 时按请求解析
+	Fallback   string // 没有生效版本时使用的系统提示词
 	Templates  []schema.MessagesTemplate
 }
 
@@ -216,13 +244,14 @@ func loadEmotionCompanionSkill(ctx context.Context) string {
 
 // newChatTemplate3 component initialization function of node 'EmotionAndCompanionShipTemplate' in graph 'studyCoachFor'
 func newChatTemplate3(ctx context.Context) (ctp prompt.ChatTemplate, err error) {
-	systemContent := loadEmotionCompanionSkill(ctx)
 	config := &ChatTemplate3Config{
 		Role:       schema.User,
 		System:     schema.System,
 		FormatType: schema.FString,
+		Node:       prompts.NodeCompanion,
+		Fallback:   loadEmotionCompanionSkill(ctx),
 		Templates: []schema.MessagesTemplate{
-			schema.SystemMessage(systemContent),
+			schema.MessagesPlaceholder("summary", true),
 			schema.MessagesPlaceholder("chat_history", true),
 			schema.UserMessage(common.UserQuestion),
 		},
@@ -232,7 +261,7 @@ func newChatTemplate3(ctx context.Context) (ctp prompt.ChatTemplate, err error)
 }
 
 func (impl *ChatTemplate3Impl) Format(ctx context.Context, vs map[string]any, opts ...prompt.Option) ([]*schema.Message, error) {
-	template := prompt.FromMessages(impl.config.FormatType, impl.config.Templates...)
+	template := prompt.FromMessages(impl.config.FormatType, withSystem(ctx, impl.config.Node, impl.config.Fallback, impl.config.Templates)...)
 	format, err := template.Format(ctx, vs)
 	if err != nil {
 		return nil, fmt.Errorf("提示工程构建失败: %w", err)
@@ -2,6 +2,7 @@ package NormalChat
 
 import (
 	"backend/studyCoach/aiModel/eino_tools/filesystem"
+	"backend/studyCoach/aiModel/eino_tools/mcptool"
 	"backend/studyCoach/aiModel/eino_tools/skill"
 	"backend/studyCoach/common"
 	"context"
@@ -11,8 +12,24 @@ import (
 	"github.com/cloudwego/eino/flow/agent/react"
 )
 
+// agentName 工具审批记录按名称在恢复时重建 Agent
+const agentName = "NormalReActAgent"
+
+func init() {
+	common.RegisterAgent(agentName, newAgent)
+}
+
 // newLambda component initialization function of node 'NormalModel' in graph 'NormalChat'
 func newLambda(ctx context.Context) (lba *compose.Lambda, err error) {
+	ins, err := newAgent(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return common.BuildAgentLambda(ctx, agentName, ins)
+}
+
+// newAgent NormalModel 使用的 Agent，工具等待审批后按 agentName 重建并恢复
+func newAgent(ctx context.Context) (*react.Agent, error) {
 	isNetwork := false
 	if val := ctx.Value("isNetwork"); val != nil {
 		if networkFlag, ok := val.(bool); ok {
@@ -30,9 +47,10 @@ func newLambda(ctx context.Context) (lba *compose.Lambda, err error) {
 	}
 	config.ToolCallingModel = chatModelIns11
 
-	// 注入工具调用通知中间件，实现 Generate 模式下的实时 tool_status 推送
+	// 注入工具审批与调用通知中间件：需审批的工具确认后才执行，实现 Generate 模式下的实时 tool_status 推送
 	config.ToolsConfig.ToolCallMiddlewares = append(
 		config.ToolsConfig.ToolCallMiddlewares,
+		common.BuildApprovalMiddleware(),
 		common.BuildNotifyMiddleware(),
 	)
 	// 系统时间已通过提示词注入 current_time，无需 get_system_time 工具
@@ -50,6 +68,13 @@ func newLambda(ctx context.Context) (lba *compose.Lambda, err error) {
 	} else {
 		log.Printf("[ReActLambda] Filesystem 工具加载失败(跳过): %v", err)
 	}
+	// MCP 工具：按 mcp.modes.normal 白名单挂载外部 MCP 服务的工具
+	if mcpTools, err := mcptool.NewTools(ctx, mcptool.ModeNormal); err == nil && len(mcpTools) > 0 {
+		config.ToolsConfig.Tools = append(config.ToolsConfig.Tools, mcpTools...)
+		log.Printf("[ReActLambda] 已添加 MCP 工具 %d 个", len(mcpTools))
+	} else if err != nil {
+		log.Printf("[ReActLambda] MCP 工具加载失败(跳过): %v", err)
+	}
 	if isNetwork {
 		toolIns21, err := newTool(ctx)
 		if err != nil {
@@ -61,13 +86,5 @@ func newLambda(ctx context.Context) (lba *compose.Lambda, err error) {
 	} else {
 		log.Printf("[ReActLambda] 网络搜索未启用")
 	}
-	ins, err := react.NewAgent(ctx, config)
-	if err != nil {
-		return nil, err
-	}
-	lba, err = compose.AnyLambda(ins.Generate, common.BuildGenToStream(ins), nil, nil)
-	if err != nil {
-		return nil, err
-	}
-	return lba, nil
+	return react.NewAgent(ctx, config)
 }
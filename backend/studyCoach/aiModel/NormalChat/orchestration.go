@@ -22,7 +22,7 @@ func BuildNormalChat(ctx context.Context) (r compose.Runnable[map[string]any, *s
 	if err != nil {
 		return nil, err
 	}
-	_ = g.AddLambdaNode(NormalModel, normalModelKeyOfLambda)
+	_ = g.AddLambdaNode(NormalModel, normalModelKeyOfLambda, compose.WithNodeName(NormalModel))
 	_ = g.AddEdge(compose.START, NormalChatTemplate)
 	_ = g.AddEdge(NormalModel, compose.END)
 	_ = g.AddEdge(NormalChatTemplate, NormalModel)
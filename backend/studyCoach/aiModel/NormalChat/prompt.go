@@ -1,6 +1,7 @@
 package NormalChat
 
 import (
+	"backend/internal/logic/prompts"
 	"backend/studyCoach/common"
 	"context"
 
@@ -17,9 +18,10 @@ type ChatTemplateConfig struct {
 
 // newChatTemplate component initialization function of node 'NormalChatTemplate' in graph 'NormalChat'
 func newChatTemplate(ctx context.Context) (ctp prompt.ChatTemplate, err error) {
-	systemTemplate := common.NormalSystemTemplate
+	// 图每次请求重新构建，系统提示词取当前生效版本
+	systemTemplate := prompts.System(ctx, prompts.NodeNormal)
 	if isNetwork, _ := ctx.Value("isNetwork").(bool); isNetwork {
-		systemTemplate = common.NormalSystemTemplateWithTools
+		systemTemplate = prompts.System(ctx, prompts.NodeNormalNetwork)
 	}
 	config := &ChatTemplateConfig{
 		Role:       schema.User,
@@ -27,6 +29,7 @@ func newChatTemplate(ctx context.Context) (ctp prompt.ChatTemplate, err error) {
 		FormatType: schema.FString,
 		Templates: []schema.MessagesTemplate{
 			schema.SystemMessage(systemTemplate),
+			schema.MessagesPlaceholder("summary", true),
 			schema.MessagesPlaceholder("chat_history", true),
 			schema.UserMessage(common.UserQuestion),
 		},
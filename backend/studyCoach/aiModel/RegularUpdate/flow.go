@@ -1,7 +1,6 @@
 package RegularUpdate
 
 import (
-	"backend/studyCoach/common"
 	"context"
 
 	"github.com/cloudwego/eino/components/tool"
@@ -10,9 +9,9 @@ import (
 )
 
 // newLambda component initialization function of node 'Lambda2' in graph 'RegularUpdate'
-func newLambda(ctx context.Context, conf *common.Config) (lba *compose.Lambda, err error) {
+func newLambda(ctx context.Context) (lba *compose.Lambda, err error) {
 	config := &react.AgentConfig{}
-	chatModelIns11, err := newChatModel(ctx, conf)
+	chatModelIns11, err := newChatModel(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -1,23 +1,12 @@
 package RegularUpdate
 
 import (
-	"backend/studyCoach/common"
+	"backend/studyCoach/llm"
 	"context"
 
-	"github.com/cloudwego/eino-ext/components/model/ark"
 	"github.com/cloudwego/eino/components/model"
-	"github.com/gogf/gf/v2/frame/g"
 )
 
-func newChatModel(ctx context.Context, conf *common.Config) (cm model.ToolCallingChatModel, err error) {
-	config := &ark.ChatModelConfig{
-		Model:   g.Cfg().MustGet(ctx, "cron.model").String(),
-		BaseURL: g.Cfg().MustGet(ctx, "cron.baseURL").String(),
-		APIKey:  g.Cfg().MustGet(ctx, "cron.apiKey").String(),
-	}
-	cm, err = ark.NewChatModel(ctx, config)
-	if err != nil {
-		return nil, err
-	}
-	return cm, nil
+func newChatModel(ctx context.Context) (cm model.ToolCallingChatModel, err error) {
+	return llm.NewChatModel(ctx, llm.RoleCron)
 }
@@ -1,14 +1,13 @@
 package RegularUpdate
 
 import (
-	"backend/studyCoach/common"
 	"context"
 
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 )
 
-func BuildRegularUpdate(ctx context.Context, conf *common.Config) (r compose.Runnable[map[string]any, *schema.Message], err error) {
+func BuildRegularUpdate(ctx context.Context) (r compose.Runnable[map[string]any, *schema.Message], err error) {
 	const (
 		CustomChatTemplate1 = "CustomChatTemplate1"
 		Lambda2             = "Lambda2"
@@ -19,11 +18,11 @@ func BuildRegularUpdate(ctx context.Context, conf *common.Config) (r compose.Run
 		return nil, err
 	}
 	_ = g.AddChatTemplateNode(CustomChatTemplate1, customChatTemplate1KeyOfChatTemplate)
-	lambda2KeyOfLambda, err := newLambda(ctx, conf)
+	lambda2KeyOfLambda, err := newLambda(ctx)
 	if err != nil {
 		return nil, err
 	}
-	_ = g.AddLambdaNode(Lambda2, lambda2KeyOfLambda)
+	_ = g.AddLambdaNode(Lambda2, lambda2KeyOfLambda, compose.WithNodeName(Lambda2))
 	_ = g.AddEdge(compose.START, CustomChatTemplate1)
 	_ = g.AddEdge(Lambda2, compose.END)
 	_ = g.AddEdge(CustomChatTemplate1, Lambda2)
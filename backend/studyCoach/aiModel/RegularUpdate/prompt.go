@@ -1,6 +1,7 @@
 package RegularUpdate
 
 import (
+	"backend/internal/logic/prompts"
 	"context"
 	"fmt"
 	"log"
@@ -20,11 +21,12 @@ type ChatTemplateConfig struct {
 
 // newChatTemplate component initialization function of node 'CustomChatTemplate1' in graph 'RegularUpdate'
 func newChatTemplate(ctx context.Context) (ctp prompt.ChatTemplate, err error) {
+	// 图每次调用重新构建，系统提示词取当前生效版本
 	config := &ChatTemplateConfig{
 		Templates: []schema.MessagesTemplate{
-			schema.SystemMessage("{{style}} Current time: {{time_now}}\n\n{{knowledge}}"),
+			schema.SystemMessage(prompts.System(ctx, prompts.NodeCron)),
 			schema.MessagesPlaceholder("chat_history", true),
-			schema.UserMessage("{{question}}"),
+			schema.UserMessage("{question}"),
 		},
 	}
 	ctp = &ChatTemplateImpl{config: config}
@@ -3,15 +3,16 @@
 package filesystem
 
 import (
+	"backend/internal/logic/usage"
 	"backend/studyCoach/aiModel/eino_tools/studyplan"
 	"backend/utility"
+	"backend/utility/sandbox"
 	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
 	"strings"
@@ -19,35 +20,34 @@ import (
 
 	"github.com/cloudwego/eino/components/tool"
 	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
 	"golang.org/x/text/encoding/simplifiedchinese"
 	"golang.org/x/text/transform"
 )
 
-// GetWorkDirForSession 根据 sessionID 返回会话工作目录，供上传等场景使用
+// GetWorkDirForSession 根据 sessionID 返回会话工作目录（不存在时创建），供上传等场景使用
 func GetWorkDirForSession(ctx context.Context, sessionID string) (string, error) {
-	if sessionID == "" {
-		return "", fmt.Errorf("无法获取会话 ID")
+	workDir, err := sessionWorkDir(ctx, sessionID)
+	if err != nil {
+		return "", err
 	}
-	base := filepath.Join(utility.FilesRoot(ctx), "uploads", "workdir")
-	workDir := filepath.Join(base, sessionID)
 	if err := os.MkdirAll(workDir, 0755); err != nil {
 		return "", fmt.Errorf("创建工作目录失败: %v", err)
 	}
-	abs, err := filepath.Abs(workDir)
-	if err != nil {
-		return workDir, nil
-	}
-	return abs, nil
+	return workDir, nil
 }
 
-func getWorkDir(ctx context.Context) (string, error) {
-	sessionID := ""
+func sessionIDFromContext(ctx context.Context) string {
 	if v := ctx.Value(studyplan.SessionIDContextKey{}); v != nil {
-		if s, ok := v.(string); ok && s != "" {
-			sessionID = s
+		if s, ok := v.(string); ok {
+			return s
 		}
 	}
-	return GetWorkDirForSession(ctx, sessionID)
+	return ""
+}
+
+func getWorkDir(ctx context.Context) (string, error) {
+	return GetWorkDirForSession(ctx, sessionIDFromContext(ctx))
 }
 
 // resolvePath 将相对路径解析到工作目录内，防止 path traversal
@@ -56,20 +56,7 @@ func resolvePath(ctx context.Context, relPath string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	relPath = filepath.Clean(relPath)
-	if relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) {
-		return "", fmt.Errorf("路径不允许越出工作目录")
-	}
-	full := filepath.Join(workDir, relPath)
-	absFull, err := filepath.Abs(full)
-	if err != nil {
-		return "", err
-	}
-	absWork, _ := filepath.Abs(workDir)
-	if !strings.HasPrefix(absFull, absWork) {
-		return "", fmt.Errorf("路径不允许越出工作目录")
-	}
-	return absFull, nil
+	return resolveIn(workDir, relPath)
 }
 
 // ReadFileTool 读取文件内容
@@ -91,6 +78,9 @@ func (t *ReadFileTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
 }
 
 func (t *ReadFileTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	if sessionIDFromContext(ctx) == "" {
+		return studyplan.NoSessionResult, nil
+	}
 	var args struct {
 		Path string `json:"path"`
 	}
@@ -136,6 +126,9 @@ func (t *WriteFileTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
 }
 
 func (t *WriteFileTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	if sessionIDFromContext(ctx) == "" {
+		return studyplan.NoSessionResult, nil
+	}
 	var args struct {
 		Path    string `json:"path"`
 		Content string `json:"content"`
@@ -151,6 +144,19 @@ func (t *WriteFileTool) InvokableRun(ctx context.Context, argumentsInJSON string
 	if err != nil {
 		return "", err
 	}
+
+	// 配额按写入后的增量校验：覆盖已有文件时只计大小差
+	sessionID := sessionIDFromContext(ctx)
+	defer LockWorkDir(sessionID)()
+	addBytes, addFiles := int64(len(args.Content)), 1
+	if info, err := os.Stat(fullPath); err == nil && info.Mode().IsRegular() {
+		addBytes -= info.Size()
+		addFiles = 0
+	}
+	quota := QuotaFor(ctx, usage.ScopeFromContext(ctx).UserUUID == "")
+	if err := CheckWorkDirQuota(ctx, sessionID, quota, addBytes, addFiles); err != nil {
+		return "", err
+	}
 	dir := filepath.Dir(fullPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
 		return "", fmt.Errorf("创建目录失败: %v", err)
@@ -199,6 +205,8 @@ func (t *ExecuteTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
 		Desc: `在工作目录内执行 Shell 命令。用于运行 Python 脚本、数据处理命令等。
 参数：command 为要执行的命令（如 "python process.py"、"ls -la"）。
 注意：命令在工作目录内执行，请使用相对路径引用文件。
+命令在沙箱中运行，有执行时间、CPU、内存与输出长度限制，通常无法访问网络。
+返回 JSON：exit_code、stdout、stderr、stdout_truncated/stderr_truncated（输出是否被截断）、timed_out（是否超时）、signal（被终止时的信号）；命令写入超出会话工作目录配额时返回 quota_error，本次新建的文件可能被删除（quota_removed）。
 【重要限制】不支持图片处理和 OCR 命令（如 tesseract、imagemagick 等）。图片内容请直接通过多模态能力识别，无需调用外部工具。`,
 		ParamsOneOf: schema.NewParamsOneOfByParams(map[string]*schema.ParameterInfo{
 			"command": {
@@ -211,6 +219,9 @@ func (t *ExecuteTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
 }
 
 func (t *ExecuteTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	if sessionIDFromContext(ctx) == "" {
+		return studyplan.NoSessionResult, nil
+	}
 	var args struct {
 		Command string `json:"command"`
 	}
@@ -239,21 +250,59 @@ func (t *ExecuteTool) InvokableRun(ctx context.Context, argumentsInJSON string,
 		shellCmd = []string{"/bin/sh", "-c", command}
 	}
 
-	execCmd := exec.CommandContext(ctx, shellCmd[0], shellCmd[1:]...)
-	execCmd.Dir = workDir
-
-	// 设置环境变量以支持UTF-8编码
-	execCmd.Env = append(os.Environ(), "PYTHONIOENCODING=utf-8")
+	// 命令可任意写入工作目录，执行前记录已有文件，执行后按配额清理新建的文件
+	sessionID := sessionIDFromContext(ctx)
+	quota := QuotaFor(ctx, usage.ScopeFromContext(ctx).UserUUID == "")
+	before, err := ListWorkDir(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
 
-	out, err := execCmd.CombinedOutput()
-	outStr := string(out)
+	tier := sandboxTier(ctx)
+	res, err := sandbox.Run(ctx, workDir, shellCmd, sandbox.LimitsFor(ctx, tier))
+	if err != nil {
+		return "", fmt.Errorf("执行失败: %v", err)
+	}
+	out := executeResult{Result: res}
+	out.QuotaRemoved, err = EnforceWorkDirQuota(ctx, sessionID, quota, before)
+	if err != nil {
+		out.QuotaError = err.Error()
+	} else if len(out.QuotaRemoved) > 0 {
+		out.QuotaError = "命令输出超出会话工作目录配额，已删除本次新建的部分文件"
+	}
+	if out.QuotaError != "" {
+		g.Log().Warningf(ctx, "[Sandbox] 工作目录超出配额: session=%s, 已删除=%v, 错误: %s", sessionID, out.QuotaRemoved, out.QuotaError)
+	}
 	if runtime.GOOS == "windows" {
-		outStr = decodeWindowsOutput(out)
+		res.Stdout = decodeWindowsOutput([]byte(res.Stdout))
+		res.Stderr = decodeWindowsOutput([]byte(res.Stderr))
+	}
+	if res.TimedOut || res.Signal != "" {
+		g.Log().Infof(ctx, "[Sandbox] 命令被终止: tier=%s, 超时=%v, 信号=%s, 耗时=%dms", tier, res.TimedOut, res.Signal, res.DurationMs)
 	}
+	b, err := json.Marshal(out)
 	if err != nil {
-		return "", fmt.Errorf("执行失败: %v\n输出: %s", err, outStr)
+		return "", err
+	}
+	return string(b), nil
+}
+
+// executeResult execute 工具的返回：沙箱执行结果与工作目录配额处理情况
+type executeResult struct {
+	*sandbox.Result
+	QuotaError   string   `json:"quota_error,omitempty"`
+	QuotaRemoved []string `json:"quota_removed,omitempty"` // 因超出配额被删除的新建文件
+}
+
+// sandboxTier 按调用方确定沙箱限制档位：匿名访客 anonymous，管理员 admin，其余登录用户 default
+func sandboxTier(ctx context.Context) string {
+	if usage.ScopeFromContext(ctx).UserUUID == "" {
+		return sandbox.TierAnonymous
+	}
+	if _, err := utility.CheckAdmin(ctx); err == nil {
+		return sandbox.TierAdmin
 	}
-	return outStr, nil
+	return sandbox.TierDefault
 }
 
 func parseJSON(s string, v interface{}) error {
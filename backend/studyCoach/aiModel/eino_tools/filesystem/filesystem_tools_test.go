@@ -0,0 +1,41 @@
+package filesystem
+
+import (
+	"backend/studyCoach/aiModel/eino_tools/studyplan"
+	"context"
+	"os"
+	"testing"
+
+	"github.com/cloudwego/eino/components/tool"
+)
+
+func TestToolsWithoutSessionCreateNoWorkDir(t *testing.T) {
+	// 没有服务端会话（OpenAI 兼容接口）时工具不报错中断对话，也不创建工作目录
+	workDir := useFilesRoot(t, "existing")
+	root, err := os.ReadDir(workDirRoot(context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		tool tool.InvokableTool
+		args string
+	}{
+		{&ReadFileTool{}, `{"path":"a.txt"}`},
+		{&WriteFileTool{}, `{"path":"a.txt","content":"x"}`},
+		{&ExecuteTool{}, `{"command":"echo hi"}`},
+	}
+	for _, c := range cases {
+		info, _ := c.tool.Info(context.Background())
+		out, err := c.tool.InvokableRun(context.Background(), c.args)
+		if err != nil || out != studyplan.NoSessionResult {
+			t.Errorf("%s 在没有会话时应返回提示，实际 %q, %v", info.Name, out, err)
+		}
+	}
+	after, err := os.ReadDir(workDirRoot(context.Background()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(after) != len(root) {
+		t.Fatalf("没有会话时不应创建工作目录，%s 所在目录前后为 %d/%d 项", workDir, len(root), len(after))
+	}
+}
@@ -0,0 +1,338 @@
+package filesystem
+
+import (
+	"backend/utility"
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// WorkDirQuota 单个会话工作目录的上限（配置 workdir.quota，匿名会话为 workdir.quota.anonymous），0 表示不限
+type WorkDirQuota struct {
+	MaxBytes int64
+	MaxFiles int
+}
+
+// QuotaFor 返回会话工作目录的上限，anonymous 为匿名令牌创建的会话
+func QuotaFor(ctx context.Context, anonymous bool) WorkDirQuota {
+	prefix := "workdir.quota."
+	if anonymous {
+		prefix = "workdir.quota.anonymous."
+	}
+	return WorkDirQuota{
+		MaxBytes: g.Cfg().MustGet(ctx, prefix+"maxMB", 0).Int64() << 20,
+		MaxFiles: g.Cfg().MustGet(ctx, prefix+"maxFiles", 0).Int(),
+	}
+}
+
+// WorkDirFile 工作目录中的文件
+type WorkDirFile struct {
+	Path      string // 相对工作目录，分隔符统一为 /
+	Size      int64
+	UpdatedAt time.Time
+}
+
+// WorkDirEntry 磁盘上存在的会话工作目录，供清理任务使用
+type WorkDirEntry struct {
+	SessionId string
+	UpdatedAt time.Time
+}
+
+// workDirLock 会话工作目录锁，refs 为持有或等待该锁的调用数，归零时从 workDirLocks 移除
+type workDirLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+var (
+	workDirLocksMu sync.Mutex
+	workDirLocks   = make(map[string]*workDirLock) // sessionID -> 锁，保证同一会话的配额校验与写入不会并发交错
+)
+
+// LockWorkDir 锁定会话工作目录，返回解锁函数；校验配额到写入完成之间需持有
+func LockWorkDir(sessionID string) func() {
+	workDirLocksMu.Lock()
+	l, ok := workDirLocks[sessionID]
+	if !ok {
+		l = &workDirLock{}
+		workDirLocks[sessionID] = l
+	}
+	l.refs++
+	workDirLocksMu.Unlock()
+
+	l.mu.Lock()
+	return func() {
+		l.mu.Unlock()
+		workDirLocksMu.Lock()
+		if l.refs--; l.refs == 0 {
+			delete(workDirLocks, sessionID)
+		}
+		workDirLocksMu.Unlock()
+	}
+}
+
+func workDirRoot(ctx context.Context) string {
+	return filepath.Join(utility.FilesRoot(ctx), "uploads", "workdir")
+}
+
+// sessionWorkDir 返回会话工作目录的绝对路径（不创建），会话 ID 不能包含路径分隔符
+func sessionWorkDir(ctx context.Context, sessionID string) (string, error) {
+	if sessionID == "" {
+		return "", fmt.Errorf("无法获取会话 ID")
+	}
+	if sessionID == "." || sessionID == ".." || strings.ContainsAny(sessionID, `/\`) {
+		return "", gerror.NewCode(gcode.New(400, "会话 ID 不合法", nil))
+	}
+	return filepath.Abs(filepath.Join(workDirRoot(ctx), sessionID))
+}
+
+// resolveIn 将相对路径解析到 workDir 内，越出工作目录时返回错误
+func resolveIn(workDir, relPath string) (string, error) {
+	relPath = filepath.Clean(relPath)
+	if relPath == ".." || strings.HasPrefix(relPath, ".."+string(filepath.Separator)) || filepath.IsAbs(relPath) {
+		return "", fmt.Errorf("路径不允许越出工作目录")
+	}
+	full := filepath.Join(workDir, relPath)
+	if !within(workDir, full) {
+		return "", fmt.Errorf("路径不允许越出工作目录")
+	}
+	return full, nil
+}
+
+// within 判断 path 是否位于 dir 内（含 dir 本身）
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// ListWorkDir 返回会话工作目录中的文件（不含目录与符号链接），按路径排序；目录不存在时返回空列表
+func ListWorkDir(ctx context.Context, sessionID string) ([]WorkDirFile, error) {
+	workDir, err := sessionWorkDir(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]WorkDirFile, 0)
+	err = filepath.WalkDir(workDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == workDir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // 遍历期间被删除
+		}
+		rel, _ := filepath.Rel(workDir, path)
+		files = append(files, WorkDirFile{
+			Path:      filepath.ToSlash(rel),
+			Size:      info.Size(),
+			UpdatedAt: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("读取工作目录失败: %w", err)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+// WorkDirUsage 统计文件总大小与数量
+func WorkDirUsage(files []WorkDirFile) (bytes int64, count int) {
+	for _, f := range files {
+		bytes += f.Size
+	}
+	return bytes, len(files)
+}
+
+// CheckWorkDirQuota 校验会话工作目录再增加 addBytes 字节、addFiles 个文件后是否超出上限，超出时返回 413
+func CheckWorkDirQuota(ctx context.Context, sessionID string, quota WorkDirQuota, addBytes int64, addFiles int) error {
+	if quota.MaxBytes <= 0 && quota.MaxFiles <= 0 {
+		return nil
+	}
+	files, err := ListWorkDir(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	used, count := WorkDirUsage(files)
+	if quota.MaxBytes > 0 && used+addBytes > quota.MaxBytes {
+		return gerror.NewCode(gcode.New(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("会话工作目录空间不足（已用 %.1f MB / 上限 %d MB），请删除不需要的文件", float64(used)/(1<<20), quota.MaxBytes>>20), nil))
+	}
+	if quota.MaxFiles > 0 && count+addFiles > quota.MaxFiles {
+		return gerror.NewCode(gcode.New(http.StatusRequestEntityTooLarge,
+			fmt.Sprintf("会话工作目录文件数已达上限（%d / %d），请删除不需要的文件", count, quota.MaxFiles), nil))
+	}
+	return nil
+}
+
+// WorkDirFilePath 返回会话工作目录中文件的绝对路径，供下载使用；
+// 文件不存在、不是普通文件或经符号链接指向工作目录外时返回 404
+func WorkDirFilePath(ctx context.Context, sessionID, relPath string) (string, error) {
+	workDir, err := sessionWorkDir(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+	full, err := resolveIn(workDir, relPath)
+	if err != nil {
+		return "", gerror.NewCode(gcode.New(400, err.Error(), nil))
+	}
+	notFound := gerror.NewCode(gcode.New(404, "文件不存在", nil))
+	realDir, err := filepath.EvalSymlinks(workDir)
+	if err != nil {
+		return "", notFound
+	}
+	real, err := filepath.EvalSymlinks(full)
+	if err != nil || !within(realDir, real) {
+		return "", notFound
+	}
+	if info, err := os.Stat(real); err != nil || !info.Mode().IsRegular() {
+		return "", notFound
+	}
+	return real, nil
+}
+
+// DeleteWorkDirFile 删除会话工作目录中的文件或子目录，不存在时返回 404。
+// 上级目录按符号链接解析后须仍在工作目录内；目标本身是符号链接时只删除链接
+func DeleteWorkDirFile(ctx context.Context, sessionID, relPath string) error {
+	workDir, err := sessionWorkDir(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	full, err := resolveIn(workDir, relPath)
+	if err != nil || full == workDir {
+		return gerror.NewCode(gcode.New(400, "路径不允许越出工作目录", nil))
+	}
+	defer LockWorkDir(sessionID)()
+	notFound := gerror.NewCode(gcode.New(404, "文件不存在", nil))
+	realDir, err := filepath.EvalSymlinks(workDir)
+	if err != nil {
+		return notFound
+	}
+	realParent, err := filepath.EvalSymlinks(filepath.Dir(full))
+	if err != nil {
+		return notFound
+	}
+	if !within(realDir, realParent) {
+		return gerror.NewCode(gcode.New(400, "路径不允许越出工作目录", nil))
+	}
+	target := filepath.Join(realParent, filepath.Base(full))
+	if target == realDir {
+		return gerror.NewCode(gcode.New(400, "路径不允许越出工作目录", nil))
+	}
+	if _, err = os.Lstat(target); err != nil {
+		return notFound
+	}
+	if err = os.RemoveAll(target); err != nil {
+		return fmt.Errorf("删除文件失败: %w", err)
+	}
+	return nil
+}
+
+// EnforceWorkDirQuota 在命令执行后校验配额：超出时按修改时间从新到旧删除本次新建的文件（before 中不存在的），
+// 直到满足配额。返回被删除的文件；删除后仍超出（如命令增大了已有文件）时同时返回 413
+func EnforceWorkDirQuota(ctx context.Context, sessionID string, quota WorkDirQuota, before []WorkDirFile) ([]string, error) {
+	if quota.MaxBytes <= 0 && quota.MaxFiles <= 0 {
+		return nil, nil
+	}
+	workDir, err := sessionWorkDir(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer LockWorkDir(sessionID)()
+	files, err := ListWorkDir(ctx, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	used, count := WorkDirUsage(files)
+	over := func() bool {
+		return quota.MaxBytes > 0 && used > quota.MaxBytes || quota.MaxFiles > 0 && count > quota.MaxFiles
+	}
+	if !over() {
+		return nil, nil
+	}
+	existed := make(map[string]bool, len(before))
+	for _, f := range before {
+		existed[f.Path] = true
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].UpdatedAt.After(files[j].UpdatedAt) })
+	var removed []string
+	for _, f := range files {
+		if !over() {
+			break
+		}
+		if existed[f.Path] {
+			continue
+		}
+		if err := os.Remove(filepath.Join(workDir, filepath.FromSlash(f.Path))); err != nil {
+			g.Log().Warningf(ctx, "[WorkDir] 删除超出配额的文件失败: session=%s, path=%s, 错误: %v", sessionID, f.Path, err)
+			continue
+		}
+		used -= f.Size
+		count--
+		removed = append(removed, f.Path)
+	}
+	if over() {
+		return removed, gerror.NewCode(gcode.New(http.StatusRequestEntityTooLarge, "会话工作目录超出配额，请删除不需要的文件", nil))
+	}
+	return removed, nil
+}
+
+// RemoveWorkDir 删除会话工作目录，目录不存在时忽略
+func RemoveWorkDir(ctx context.Context, sessionID string) error {
+	workDir, err := sessionWorkDir(ctx, sessionID)
+	if err != nil {
+		return err
+	}
+	defer LockWorkDir(sessionID)()
+	if err = os.RemoveAll(workDir); err != nil {
+		return fmt.Errorf("删除工作目录失败: %w", err)
+	}
+	return nil
+}
+
+// ListWorkDirs 返回磁盘上所有会话工作目录及其最近修改时间（目录内文件的最大修改时间）
+func ListWorkDirs(ctx context.Context) ([]WorkDirEntry, error) {
+	entries, err := os.ReadDir(workDirRoot(ctx))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	var dirs []WorkDirEntry
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		entry := WorkDirEntry{SessionId: e.Name(), UpdatedAt: info.ModTime()}
+		if files, err := ListWorkDir(ctx, e.Name()); err == nil {
+			for _, f := range files {
+				if f.UpdatedAt.After(entry.UpdatedAt) {
+					entry.UpdatedAt = f.UpdatedAt
+				}
+			}
+		}
+		dirs = append(dirs, entry)
+	}
+	return dirs, nil
+}
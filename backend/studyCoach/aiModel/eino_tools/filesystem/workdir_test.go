@@ -0,0 +1,262 @@
+package filesystem
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+)
+
+// useFilesRoot 将 files.root 指向临时目录，返回该会话的工作目录（已创建）
+func useFilesRoot(t *testing.T, sessionID string) string {
+	t.Helper()
+	root := t.TempDir()
+	adapter, err := gcfg.NewAdapterContent("files:\n  root: " + root + "\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	t.Cleanup(func() { g.Cfg().SetAdapter(original) })
+	workDir, err := GetWorkDirForSession(context.Background(), sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return workDir
+}
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(strings.Repeat("x", size)), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func symlink(t *testing.T, target, link string) {
+	t.Helper()
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("无法创建符号链接: %v", err)
+	}
+}
+
+func errCode(err error) int {
+	return gerror.Code(err).Code()
+}
+
+func TestResolveIn(t *testing.T) {
+	workDir := filepath.Join(t.TempDir(), "session")
+	cases := []struct {
+		path string
+		want string // 空表示应拒绝
+	}{
+		{"a.txt", "a.txt"},
+		{"sub/a.txt", "sub/a.txt"},
+		{"./sub/../a.txt", "a.txt"},
+		{".", "."},
+		{"..", ""},
+		{"../a.txt", ""},
+		{"sub/../../a.txt", ""},
+		{"../session-other/a.txt", ""},
+		{"/etc/passwd", ""},
+	}
+	for _, c := range cases {
+		got, err := resolveIn(workDir, c.path)
+		if c.want == "" {
+			if err == nil {
+				t.Errorf("resolveIn(%q) 应拒绝，实际 %q", c.path, got)
+			}
+			continue
+		}
+		if want := filepath.Join(workDir, c.want); err != nil || got != want {
+			t.Errorf("resolveIn(%q) = %q, %v；期望 %q", c.path, got, err, want)
+		}
+	}
+}
+
+func TestSessionWorkDirRejectsPathSeparators(t *testing.T) {
+	useFilesRoot(t, "s1")
+	for _, id := range []string{"", ".", "..", "a/b", `a\b`, "../s1"} {
+		if _, err := sessionWorkDir(context.Background(), id); err == nil {
+			t.Errorf("会话 ID %q 应被拒绝", id)
+		}
+	}
+}
+
+func TestCheckWorkDirQuota(t *testing.T) {
+	workDir := useFilesRoot(t, "s1")
+	writeFile(t, filepath.Join(workDir, "a.txt"), 600)
+	writeFile(t, filepath.Join(workDir, "sub", "b.txt"), 300)
+
+	cases := []struct {
+		name     string
+		quota    WorkDirQuota
+		addBytes int64
+		addFiles int
+		reject   bool
+	}{
+		{"不限额", WorkDirQuota{}, 1 << 30, 100, false},
+		{"字节数未超出", WorkDirQuota{MaxBytes: 1000}, 100, 1, false},
+		{"字节数超出", WorkDirQuota{MaxBytes: 1000}, 101, 1, true},
+		{"文件数未超出", WorkDirQuota{MaxFiles: 3}, 0, 1, false},
+		{"文件数超出", WorkDirQuota{MaxFiles: 3}, 0, 2, true},
+		{"覆盖文件缩小", WorkDirQuota{MaxBytes: 800}, -200, 0, false},
+	}
+	for _, c := range cases {
+		err := CheckWorkDirQuota(context.Background(), "s1", c.quota, c.addBytes, c.addFiles)
+		if !c.reject {
+			if err != nil {
+				t.Errorf("%s: 不应拒绝，实际 %v", c.name, err)
+			}
+			continue
+		}
+		if errCode(err) != http.StatusRequestEntityTooLarge {
+			t.Errorf("%s: 应返回 413，实际 %v", c.name, err)
+		}
+	}
+}
+
+func TestWorkDirFilePath(t *testing.T) {
+	workDir := useFilesRoot(t, "s1")
+	outside := filepath.Join(filepath.Dir(workDir), "s2")
+	writeFile(t, filepath.Join(workDir, "a.txt"), 10)
+	writeFile(t, filepath.Join(outside, "secret.txt"), 10)
+	symlink(t, filepath.Join(outside, "secret.txt"), filepath.Join(workDir, "leak.txt"))
+	symlink(t, outside, filepath.Join(workDir, "linkdir"))
+	symlink(t, filepath.Join(workDir, "a.txt"), filepath.Join(workDir, "alias.txt"))
+
+	cases := []struct {
+		path string
+		code int // 0 表示应成功
+	}{
+		{"a.txt", 0},
+		{"alias.txt", 0},
+		{"missing.txt", http.StatusNotFound},
+		{"leak.txt", http.StatusNotFound},
+		{"linkdir/secret.txt", http.StatusNotFound},
+		{"../s2/secret.txt", http.StatusBadRequest},
+		{".", http.StatusNotFound},
+	}
+	for _, c := range cases {
+		got, err := WorkDirFilePath(context.Background(), "s1", c.path)
+		if c.code == 0 {
+			if err != nil || !within(workDir, got) {
+				t.Errorf("WorkDirFilePath(%q) = %q, %v；应返回工作目录内的文件", c.path, got, err)
+			}
+			continue
+		}
+		if errCode(err) != c.code {
+			t.Errorf("WorkDirFilePath(%q) = %q, %v；期望 %d", c.path, got, err, c.code)
+		}
+	}
+}
+
+func TestDeleteWorkDirFile(t *testing.T) {
+	workDir := useFilesRoot(t, "s1")
+	outside := filepath.Join(filepath.Dir(workDir), "s2")
+	secret := filepath.Join(outside, "secret.txt")
+	writeFile(t, filepath.Join(workDir, "a.txt"), 10)
+	writeFile(t, filepath.Join(workDir, "sub", "b.txt"), 10)
+	writeFile(t, secret, 10)
+	symlink(t, outside, filepath.Join(workDir, "linkdir"))
+	symlink(t, secret, filepath.Join(workDir, "leak.txt"))
+
+	cases := []struct {
+		path string
+		code int // 0 表示应成功
+	}{
+		{"linkdir/secret.txt", http.StatusBadRequest},
+		{"../s2/secret.txt", http.StatusBadRequest},
+		{".", http.StatusBadRequest},
+		{"missing.txt", http.StatusNotFound},
+		{"leak.txt", 0}, // 只删除链接本身
+		{"linkdir", 0},
+		{"a.txt", 0},
+		{"sub", 0},
+	}
+	for _, c := range cases {
+		err := DeleteWorkDirFile(context.Background(), "s1", c.path)
+		if c.code == 0 {
+			if err != nil {
+				t.Errorf("DeleteWorkDirFile(%q): %v", c.path, err)
+			}
+			if _, err := os.Lstat(filepath.Join(workDir, c.path)); !os.IsNotExist(err) {
+				t.Errorf("DeleteWorkDirFile(%q) 后文件仍存在", c.path)
+			}
+			continue
+		}
+		if errCode(err) != c.code {
+			t.Errorf("DeleteWorkDirFile(%q) = %v；期望 %d", c.path, err, c.code)
+		}
+	}
+	if _, err := os.Stat(secret); err != nil {
+		t.Fatalf("工作目录外的文件被删除: %v", err)
+	}
+}
+
+func TestEnforceWorkDirQuotaRemovesNewFiles(t *testing.T) {
+	workDir := useFilesRoot(t, "s1")
+	writeFile(t, filepath.Join(workDir, "keep.txt"), 500)
+	before, err := ListWorkDir(context.Background(), "s1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(workDir, "out", "big.bin"), 800)
+
+	removed, err := EnforceWorkDirQuota(context.Background(), "s1", WorkDirQuota{MaxBytes: 1000}, before)
+	if err != nil {
+		t.Fatalf("删除新文件后应满足配额: %v", err)
+	}
+	if len(removed) != 1 || removed[0] != "out/big.bin" {
+		t.Fatalf("应删除 out/big.bin，实际 %v", removed)
+	}
+	if _, err := os.Stat(filepath.Join(workDir, "keep.txt")); err != nil {
+		t.Fatalf("已有文件不应被删除: %v", err)
+	}
+
+	// 已有文件被增大时无法通过删除新文件满足配额
+	writeFile(t, filepath.Join(workDir, "keep.txt"), 1200)
+	if _, err = EnforceWorkDirQuota(context.Background(), "s1", WorkDirQuota{MaxBytes: 1000}, before); errCode(err) != http.StatusRequestEntityTooLarge {
+		t.Fatalf("应返回 413，实际 %v", err)
+	}
+}
+
+func TestLockWorkDirKeepsMutexWhileWaiting(t *testing.T) {
+	unlock := LockWorkDir("s1")
+	acquired := make(chan func())
+	go func() { acquired <- LockWorkDir("s1") }()
+	// 等待者持有引用前不会释放；释放后等待者拿到的必须是同一把锁
+	for {
+		workDirLocksMu.Lock()
+		refs := workDirLocks["s1"].refs
+		workDirLocksMu.Unlock()
+		if refs == 2 {
+			break
+		}
+	}
+	unlock()
+	unlock2 := <-acquired
+	third := make(chan struct{})
+	go func() { LockWorkDir("s1")(); close(third) }()
+	select {
+	case <-third:
+		t.Fatal("持有锁期间不应有第二个持有者")
+	case <-time.After(50 * time.Millisecond):
+	}
+	unlock2()
+	<-third
+	workDirLocksMu.Lock()
+	defer workDirLocksMu.Unlock()
+	if _, ok := workDirLocks["s1"]; ok {
+		t.Fatal("无持有者后锁应被移除")
+	}
+}
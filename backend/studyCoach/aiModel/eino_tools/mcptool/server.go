@@ -0,0 +1,360 @@
+package mcptool
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/mark3labs/mcp-go/client"
+	"github.com/mark3labs/mcp-go/client/transport"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// 连接方式（配置 mcp.servers.<name>.transport）
+const (
+	TransportStdio = "stdio" // 启动本地命令，经 stdin/stdout 通信
+	TransportSSE   = "sse"   // HTTP + SSE
+	TransportHTTP  = "http"  // Streamable HTTP
+)
+
+// 连接状态
+const (
+	StateIdle  = "idle"  // 尚未连接
+	StateReady = "ready" // 已连接并获取工具列表
+	StateDown  = "down"  // 连接失败或断开，等待重连
+)
+
+const (
+	defaultConnectTimeout = 10 * time.Second
+	defaultCallTimeout    = 60 * time.Second
+	defaultHealthInterval = 30 * time.Second
+	maxRetryBackoff       = time.Minute
+)
+
+// serverConfig 单个 MCP 服务的配置
+type serverConfig struct {
+	Transport string            `json:"transport"`
+	Command   string            `json:"command"` // stdio：启动命令
+	Args      []string          `json:"args"`
+	Env       map[string]string `json:"env"`     // stdio：在服务进程环境变量基础上追加
+	URL       string            `json:"url"`     // sse/http：服务地址
+	Headers   map[string]string `json:"headers"` // sse/http：请求头，如鉴权
+}
+
+// server 一个 MCP 服务的连接：首次使用时连接，断开后按退避间隔重连
+type server struct {
+	name string
+	conf serverConfig
+
+	mu         sync.Mutex
+	cli        *client.Client
+	tools      []mcp.Tool
+	known      []string      // 最近一次连接成功时的工具名，用于判断工具集是否变化
+	connecting chan struct{} // 非空表示正在连接，连接结束时关闭
+	state      string
+	lastErr    string
+	lastOK     time.Time
+	failures   int
+	retryAt    time.Time
+}
+
+var (
+	loadOnce sync.Once
+	servers  map[string]*server
+
+	// toolsVersion 任一服务连接后工具集发生变化时递增，缓存了工具列表的 Agent 图据此重建
+	toolsVersion atomic.Int64
+)
+
+// ToolsVersion 返回当前 MCP 工具集版本；与构建图时记录的版本不同说明有服务新连上或工具列表变化
+func ToolsVersion() int64 {
+	return toolsVersion.Load()
+}
+
+// loadServers 读取 mcp.servers，配置变更需重启服务生效
+func loadServers(ctx context.Context) map[string]*server {
+	loadOnce.Do(func() {
+		servers = make(map[string]*server)
+		for name, v := range g.Cfg().MustGet(ctx, "mcp.servers").MapStrVar() {
+			var conf serverConfig
+			if err := v.Scan(&conf); err != nil {
+				g.Log().Errorf(ctx, "[MCP] 服务配置无效(跳过): server=%s, 错误: %v", name, err)
+				continue
+			}
+			if conf.Transport == "" {
+				conf.Transport = TransportStdio
+				if conf.URL != "" {
+					conf.Transport = TransportHTTP
+				}
+			}
+			servers[name] = &server{name: name, conf: conf, state: StateIdle}
+		}
+	})
+	return servers
+}
+
+func sortedServers(ctx context.Context) []*server {
+	list := make([]*server, 0, len(loadServers(ctx)))
+	for _, s := range loadServers(ctx) {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].name < list[j].name })
+	return list
+}
+
+func (s *server) newClient() (*client.Client, error) {
+	switch s.conf.Transport {
+	case TransportStdio:
+		if s.conf.Command == "" {
+			return nil, fmt.Errorf("未配置 command")
+		}
+		env := make([]string, 0, len(s.conf.Env))
+		for k, v := range s.conf.Env {
+			env = append(env, k+"="+v)
+		}
+		return client.NewStdioMCPClient(s.conf.Command, env, s.conf.Args...)
+	case TransportSSE:
+		return client.NewSSEMCPClient(s.conf.URL, transport.WithHeaders(s.conf.Headers))
+	case TransportHTTP:
+		return client.NewStreamableHttpClient(s.conf.URL, transport.WithHTTPHeaders(s.conf.Headers))
+	default:
+		return nil, fmt.Errorf("不支持的连接方式: %s", s.conf.Transport)
+	}
+}
+
+// connection 返回可用的客户端与工具列表，未连接且已到重连时间时先连接。
+// 连接在锁外进行，/readyz 与其他请求不会被慢服务阻塞；同一服务同时只有一个连接在进行，其余调用等待其结果
+func (s *server) connection(ctx context.Context) (*client.Client, []mcp.Tool, error) {
+	s.mu.Lock()
+	if s.cli != nil {
+		defer s.mu.Unlock()
+		return s.cli, s.tools, nil
+	}
+	if wait := s.connecting; wait != nil {
+		s.mu.Unlock()
+		select {
+		case <-wait:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if s.cli == nil {
+			return nil, nil, fmt.Errorf("MCP 服务 %s 不可用: %s", s.name, s.lastErr)
+		}
+		return s.cli, s.tools, nil
+	}
+	if time.Now().Before(s.retryAt) {
+		defer s.mu.Unlock()
+		return nil, nil, fmt.Errorf("MCP 服务 %s 不可用: %s", s.name, s.lastErr)
+	}
+	done := make(chan struct{})
+	s.connecting = done
+	s.mu.Unlock()
+
+	cli, tools, err := s.dial(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.connecting = nil
+	close(done)
+	if err != nil {
+		s.failLocked(err)
+		g.Log().Warningf(ctx, "[MCP] 连接失败: server=%s, 第 %d 次, %s 后重试, 错误: %v",
+			s.name, s.failures, time.Until(s.retryAt).Round(time.Second), err)
+		return nil, nil, fmt.Errorf("连接 MCP 服务 %s 失败: %w", s.name, err)
+	}
+	cli.OnConnectionLost(func(err error) {
+		s.disconnect(context.Background(), cli, err)
+	})
+	s.cli, s.tools = cli, tools
+	s.state, s.lastErr, s.failures, s.lastOK = StateReady, "", 0, time.Now()
+	if names := toolNames(tools); !slices.Equal(names, s.known) {
+		s.known = names
+		toolsVersion.Add(1)
+	}
+	g.Log().Infof(ctx, "[MCP] 已连接: server=%s, transport=%s, 工具数=%d", s.name, s.conf.Transport, len(tools))
+	return s.cli, s.tools, nil
+}
+
+// dial 建立连接并获取工具列表，不持有 s.mu
+func (s *server) dial(ctx context.Context) (*client.Client, []mcp.Tool, error) {
+	cli, err := s.newClient()
+	if err != nil {
+		return nil, nil, err
+	}
+	// 连接本身不随本次请求结束，只有初始化与获取工具受超时限制
+	if err = cli.Start(context.WithoutCancel(ctx)); err == nil {
+		var tools []mcp.Tool
+		if tools, err = initialize(ctx, cli); err == nil {
+			return cli, tools, nil
+		}
+	}
+	_ = cli.Close()
+	return nil, nil, err
+}
+
+func toolNames(tools []mcp.Tool) []string {
+	names := make([]string, 0, len(tools))
+	for _, t := range tools {
+		names = append(names, t.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func initialize(ctx context.Context, cli *client.Client) ([]mcp.Tool, error) {
+	ctx, cancel := context.WithTimeout(ctx, g.Cfg().MustGet(ctx, "mcp.connectTimeout", defaultConnectTimeout).Duration())
+	defer cancel()
+	req := mcp.InitializeRequest{}
+	req.Params.ProtocolVersion = mcp.LATEST_PROTOCOL_VERSION
+	req.Params.ClientInfo = mcp.Implementation{Name: "studycoach", Version: "1.0.0"}
+	if _, err := cli.Initialize(ctx, req); err != nil {
+		return nil, fmt.Errorf("初始化失败: %w", err)
+	}
+	list, err := cli.ListTools(ctx, mcp.ListToolsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("获取工具列表失败: %w", err)
+	}
+	return list.Tools, nil
+}
+
+// failLocked 记录失败并按 1s、2s、4s… 退避，最长 maxRetryBackoff
+func (s *server) failLocked(err error) {
+	s.failures++
+	s.state = StateDown
+	s.lastErr = err.Error()
+	backoff := maxRetryBackoff
+	if s.failures < 7 {
+		backoff = min(time.Second<<(s.failures-1), maxRetryBackoff)
+	}
+	s.retryAt = time.Now().Add(backoff)
+}
+
+// disconnect 关闭已断开的客户端，cli 已被替换时忽略
+func (s *server) disconnect(ctx context.Context, cli *client.Client, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cli != cli || cli == nil {
+		return
+	}
+	// 可能在传输层的断线回调中调用，异步关闭避免阻塞传输层
+	go func() { _ = cli.Close() }()
+	s.cli, s.tools = nil, nil
+	s.failLocked(err)
+	g.Log().Warningf(ctx, "[MCP] 连接断开: server=%s, 错误: %v", s.name, err)
+}
+
+// check 探测连接：已连接时 Ping，失败则断开；未连接且到重连时间时重连
+func (s *server) check(ctx context.Context) {
+	s.mu.Lock()
+	cli := s.cli
+	s.mu.Unlock()
+	if cli == nil {
+		_, _, _ = s.connection(ctx)
+		return
+	}
+	pingCtx, cancel := context.WithTimeout(ctx, g.Cfg().MustGet(ctx, "mcp.connectTimeout", defaultConnectTimeout).Duration())
+	defer cancel()
+	if err := cli.Ping(pingCtx); err != nil {
+		s.disconnect(ctx, cli, fmt.Errorf("ping 失败: %w", err))
+		return
+	}
+	s.mu.Lock()
+	if s.cli == cli {
+		s.lastOK = time.Now()
+	}
+	s.mu.Unlock()
+}
+
+var startOnce sync.Once
+
+// Start 连接所有已配置的 MCP 服务并按 mcp.healthInterval 探测，断开的服务自动重连
+func Start(ctx context.Context) {
+	startOnce.Do(func() {
+		list := sortedServers(ctx)
+		if len(list) == 0 {
+			return
+		}
+		interval := g.Cfg().MustGet(ctx, "mcp.healthInterval", defaultHealthInterval).Duration()
+		if interval <= 0 {
+			interval = defaultHealthInterval
+		}
+		go func() {
+			for _, s := range list {
+				s.check(ctx)
+			}
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for range ticker.C {
+				for _, s := range list {
+					s.check(ctx)
+				}
+			}
+		}()
+		g.Log().Infof(ctx, "[MCP] 已启动连接管理, 服务数=%d, 探测间隔=%s", len(list), interval)
+	})
+}
+
+// ServerHealth MCP 服务的连接状态，在 /readyz 中输出
+type ServerHealth struct {
+	Name      string `json:"name"`
+	Transport string `json:"transport"`
+	State     string `json:"state"`
+	Tools     int    `json:"tools"`
+	Failures  int    `json:"failures,omitempty"`   // 连续失败次数
+	LastError string `json:"last_error,omitempty"` // 最近一次失败原因
+	LastOK    int64  `json:"last_ok,omitempty"`    // 最近一次连接或探测成功的时间（Unix 秒）
+}
+
+// Health 返回所有已配置 MCP 服务的连接状态
+func Health(ctx context.Context) []ServerHealth {
+	list := make([]ServerHealth, 0, len(loadServers(ctx)))
+	for _, s := range sortedServers(ctx) {
+		s.mu.Lock()
+		h := ServerHealth{
+			Name:      s.name,
+			Transport: s.conf.Transport,
+			State:     s.state,
+			Tools:     len(s.tools),
+			Failures:  s.failures,
+			LastError: s.lastErr,
+		}
+		if !s.lastOK.IsZero() {
+			h.LastOK = s.lastOK.Unix()
+		}
+		s.mu.Unlock()
+		list = append(list, h)
+	}
+	return list
+}
+
+// Ready 所有已配置的 MCP 服务均已连接时返回 true，未配置时也为 true
+func Ready(health []ServerHealth) bool {
+	for _, h := range health {
+		if h.State != StateReady {
+			return false
+		}
+	}
+	return true
+}
+
+// exposedName 模型看到的工具名 <server>__<tool>，避免与内置工具重名；只保留函数名允许的字符，最长 64
+func exposedName(serverName, toolName string) string {
+	name := strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '_' || r == '-' {
+			return r
+		}
+		return '_'
+	}, serverName+"__"+toolName)
+	if len(name) > 64 {
+		name = name[:64]
+	}
+	return name
+}
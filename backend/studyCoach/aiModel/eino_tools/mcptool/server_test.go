@@ -0,0 +1,78 @@
+package mcptool
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+)
+
+func TestConnectionDialsOutsideLock(t *testing.T) {
+	// 服务接受连接但不响应：连接进行中 Health 不被阻塞，并发调用只发起一次连接并共享失败结果
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	var (
+		conns []net.Conn
+		mu    sync.Mutex
+	)
+	t.Cleanup(func() {
+		for _, c := range conns {
+			_ = c.Close()
+		}
+	})
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			conns = append(conns, conn)
+			mu.Unlock()
+		}
+	}()
+
+	adapter, err := gcfg.NewAdapterContent("mcp:\n  connectTimeout: \"500ms\"\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	t.Cleanup(func() { g.Cfg().SetAdapter(original) })
+
+	s := &server{name: "hang", conf: serverConfig{Transport: TransportHTTP, URL: "http://" + l.Addr().String() + "/mcp"}, state: StateIdle}
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() {
+			_, _, err := s.connection(context.Background())
+			errs <- err
+		}()
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	start := time.Now()
+	s.mu.Lock()
+	state := s.state
+	s.mu.Unlock()
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond || state != StateIdle {
+		t.Fatalf("连接进行中读取状态不应阻塞: %v, state=%s", elapsed, state)
+	}
+
+	for range 3 {
+		if err := <-errs; err == nil {
+			t.Fatal("服务无响应时连接应失败")
+		}
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(conns) != 1 || s.failures != 1 {
+		t.Fatalf("并发调用应只连接一次: accepted=%d failures=%d", len(conns), s.failures)
+	}
+}
@@ -0,0 +1,158 @@
+// Package mcptool 将外部 MCP 服务（stdio 命令、SSE 或 Streamable HTTP）的工具挂载为 Agent 工具。
+// 服务在 mcp.servers 中配置，各对话模式可用的工具由 mcp.modes 白名单决定；
+// 连接在首次使用时建立，断开后自动重连，状态在 /readyz 中输出。
+package mcptool
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/schema"
+	"github.com/eino-contrib/jsonschema"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/mark3labs/mcp-go/mcp"
+)
+
+// 对话模式（配置 mcp.modes.<mode>）
+const (
+	ModeNormal = "normal" // NormalChat
+	ModeCoach  = "coach"  // CoachChat（ReActLambda 与 PlanModifyModel）
+)
+
+// NewTools 返回 mode 白名单内的 MCP 工具。白名单项为 server（该服务全部工具）或 server/tool；
+// 连接失败的服务记录日志后跳过，不影响其他工具
+func NewTools(ctx context.Context, mode string) ([]tool.BaseTool, error) {
+	allow := make(map[string][]string) // server -> 工具名，nil 表示全部
+	var order []string
+	for _, entry := range g.Cfg().MustGet(ctx, "mcp.modes."+mode).Strings() {
+		serverName, toolName, _ := strings.Cut(strings.TrimSpace(entry), "/")
+		if _, ok := allow[serverName]; !ok {
+			order = append(order, serverName)
+			allow[serverName] = []string{}
+		}
+		if toolName == "" {
+			allow[serverName] = nil
+		} else if allow[serverName] != nil {
+			allow[serverName] = append(allow[serverName], toolName)
+		}
+	}
+
+	var tools []tool.BaseTool
+	for _, serverName := range order {
+		s, ok := loadServers(ctx)[serverName]
+		if !ok {
+			g.Log().Warningf(ctx, "[MCP] mcp.modes.%s 引用了未配置的服务: %s", mode, serverName)
+			continue
+		}
+		_, list, err := s.connection(ctx)
+		if err != nil {
+			g.Log().Warningf(ctx, "[MCP] 服务不可用(跳过): %v", err)
+			continue
+		}
+		names := allow[serverName]
+		for _, t := range list {
+			if names != nil && !contains(names, t.Name) {
+				continue
+			}
+			info, err := toolInfo(s.name, t)
+			if err != nil {
+				g.Log().Warningf(ctx, "[MCP] 工具参数定义无效(跳过): server=%s, tool=%s, 错误: %v", s.name, t.Name, err)
+				continue
+			}
+			tools = append(tools, &mcpTool{srv: s, name: t.Name, info: info})
+		}
+	}
+	return tools, nil
+}
+
+func contains(list []string, v string) bool {
+	for _, item := range list {
+		if item == v {
+			return true
+		}
+	}
+	return false
+}
+
+// toolInfo 将 MCP 工具定义转为 eino ToolInfo
+func toolInfo(serverName string, t mcp.Tool) (*schema.ToolInfo, error) {
+	raw := t.RawInputSchema
+	if len(raw) == 0 {
+		b, err := sonic.Marshal(t.InputSchema)
+		if err != nil {
+			return nil, err
+		}
+		raw = b
+	}
+	params := &jsonschema.Schema{}
+	if err := sonic.Unmarshal(raw, params); err != nil {
+		return nil, err
+	}
+	return &schema.ToolInfo{
+		Name:        exposedName(serverName, t.Name),
+		Desc:        t.Description,
+		ParamsOneOf: schema.NewParamsOneOfByJSONSchema(params),
+	}, nil
+}
+
+// mcpTool 一个 MCP 工具。调用时取服务当前的连接，重连后已构建的 Agent 无需重建
+type mcpTool struct {
+	srv  *server
+	name string // MCP 服务端的工具名
+	info *schema.ToolInfo
+}
+
+func (t *mcpTool) Info(ctx context.Context) (*schema.ToolInfo, error) {
+	return t.info, nil
+}
+
+// InvokableRun 调用失败与服务端返回的错误作为工具结果交给模型，外部服务异常不中断本轮对话
+func (t *mcpTool) InvokableRun(ctx context.Context, argumentsInJSON string, opts ...tool.Option) (string, error) {
+	cli, _, err := t.srv.connection(ctx)
+	if err != nil {
+		return fmt.Sprintf("MCP 服务 %s 暂不可用，工具 %s 未执行：%v", t.srv.name, t.name, err), nil
+	}
+	callCtx, cancel := context.WithTimeout(ctx, g.Cfg().MustGet(ctx, "mcp.callTimeout", defaultCallTimeout).Duration())
+	defer cancel()
+	res, err := cli.CallTool(callCtx, mcp.CallToolRequest{
+		Params: mcp.CallToolParams{
+			Name:      t.name,
+			Arguments: json.RawMessage(argumentsInJSON),
+		},
+	})
+	if err != nil {
+		g.Log().Warningf(ctx, "[MCP] 调用工具失败: server=%s, tool=%s, 错误: %v", t.srv.name, t.name, err)
+		// 调用失败可能是连接已断开，立即探测以便后续调用重连
+		t.srv.check(context.WithoutCancel(ctx))
+		return fmt.Sprintf("调用 MCP 工具 %s 失败：%v", t.name, err), nil
+	}
+	text := resultText(res)
+	if res.IsError {
+		return "MCP 工具返回错误：" + text, nil
+	}
+	return text, nil
+}
+
+// resultText 拼接结果中的文本内容，非文本内容按 JSON 输出；无内容时输出结构化结果
+func resultText(res *mcp.CallToolResult) string {
+	var parts []string
+	for _, c := range res.Content {
+		if tc, ok := mcp.AsTextContent(c); ok {
+			parts = append(parts, tc.Text)
+			continue
+		}
+		if b, err := sonic.Marshal(c); err == nil {
+			parts = append(parts, string(b))
+		}
+	}
+	if len(parts) == 0 && res.StructuredContent != nil {
+		if b, err := sonic.Marshal(res.StructuredContent); err == nil {
+			parts = append(parts, string(b))
+		}
+	}
+	return strings.Join(parts, "\n")
+}
@@ -32,6 +32,9 @@ const (
 // SessionIDContextKey 用于在 context 中传递 session_id
 type SessionIDContextKey struct{}
 
+// NoSessionResult 没有服务端会话（如 OpenAI 兼容接口）时会话相关工具的返回：不创建工作目录与计划文件，由模型直接回复
+const NoSessionResult = "当前对话没有服务端会话（如通过 OpenAI 兼容接口调用），无法使用工作目录与学习计划，请直接在回复中给出内容"
+
 func getSessionID(ctx context.Context) string {
 	if v := ctx.Value(SessionIDContextKey{}); v != nil {
 		if s, ok := v.(string); ok && s != "" {
@@ -261,7 +264,7 @@ func (t *SavePlanTool) InvokableRun(ctx context.Context, argumentsInJSON string,
 
 	sessionID := getSessionID(ctx)
 	if sessionID == "" {
-		return "", fmt.Errorf("无法获取会话 ID，请确保在对话上下文中")
+		return NoSessionResult, nil
 	}
 
 	timestamp := time.Now().Format("20060102_150405")
@@ -314,7 +317,7 @@ func (t *ReadPlanTool) InvokableRun(ctx context.Context, argumentsInJSON string,
 
 	sessionID := getSessionID(ctx)
 	if sessionID == "" {
-		return "", fmt.Errorf("无法获取会话 ID")
+		return NoSessionResult, nil
 	}
 
 	// 每次读取时尝试同步本地待上传到 SeaweedFS
@@ -400,7 +403,7 @@ func (t *DeletePlanTool) InvokableRun(ctx context.Context, argumentsInJSON strin
 
 	sessionID := getSessionID(ctx)
 	if sessionID == "" {
-		return "", fmt.Errorf("无法获取会话 ID")
+		return NoSessionResult, nil
 	}
 
 	safeTitle := sanitizePath(args.PlanTitle)
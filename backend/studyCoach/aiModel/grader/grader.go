@@ -1,10 +1,12 @@
 package grader
 
 import (
+	"backend/studyCoach/common"
 	"context"
 	"fmt"
 	"strings"
 
+	"github.com/cloudwego/eino/components"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
 	"github.com/gogf/gf/v2/frame/g"
@@ -26,7 +28,7 @@ func (x *Grader) Retriever(ctx context.Context, docs []*schema.Document, questio
 	if err != nil {
 		return
 	}
-	result, err := x.cm.Generate(ctx, messages)
+	result, err := x.cm.Generate(common.WithCallName(ctx, "RelevanceGrader", components.ComponentOfChatModel), messages)
 	if err != nil {
 		return false, fmt.Errorf("检查下检索到的结果是否能够回答当前问题失败: %v", err)
 	}
@@ -39,7 +41,7 @@ func (x *Grader) Related(ctx context.Context, doc *schema.Document, question str
 	if err != nil {
 		return
 	}
-	result, err := x.cm.Generate(ctx, messages)
+	result, err := x.cm.Generate(common.WithCallName(ctx, "RelevanceGrader", components.ComponentOfChatModel), messages)
 	if err != nil {
 		return false, fmt.Errorf("检查下检索到的结果是否和用户问题相关失败: %v", err)
 	}
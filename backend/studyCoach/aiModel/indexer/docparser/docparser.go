@@ -0,0 +1,111 @@
+// Package docparser 提供 DOCX、PPTX、EPUB 文档解析器，输出带标题结构的 Markdown 文本，
+// 以便索引流水线走标题切分；幻灯片/章节序号以位置标记写入正文，由 transformer 转为 chunk 元数据。
+package docparser
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/cloudwego/eino/components/document/parser"
+	"github.com/cloudwego/eino/schema"
+)
+
+// maxEntrySize 压缩包内单个条目解压后的大小上限，防止 zip 炸弹
+var maxEntrySize int64 = 64 << 20
+
+// markdownParser 将压缩包格式文档转换为 Markdown 的通用解析器
+type markdownParser struct {
+	name    string
+	convert func(ctx context.Context, zr *zip.Reader) (string, error)
+}
+
+func (p *markdownParser) Parse(ctx context.Context, reader io.Reader, opts ...parser.Option) ([]*schema.Document, error) {
+	option := parser.GetCommonOptions(&parser.Options{}, opts...)
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("%s 文件格式错误: %w", p.name, err)
+	}
+	content, err := p.convert(ctx, zr)
+	if err != nil {
+		return nil, fmt.Errorf("%s 解析失败: %w", p.name, err)
+	}
+
+	meta := make(map[string]any)
+	meta[parser.MetaKeySource] = option.URI
+	for k, v := range option.ExtraMeta {
+		meta[k] = v
+	}
+	return []*schema.Document{{
+		Content:  content,
+		MetaData: meta,
+	}}, nil
+}
+
+// openEntry 打开压缩包内的条目（名称大小写不敏感）
+func openEntry(zr *zip.Reader, name string) (io.ReadCloser, error) {
+	name = strings.TrimPrefix(path.Clean(name), "/")
+	for _, f := range zr.File {
+		if strings.EqualFold(f.Name, name) {
+			rc, err := f.Open()
+			if err != nil {
+				return nil, err
+			}
+			return &entryReader{ReadCloser: rc, name: f.Name, remain: maxEntrySize}, nil
+		}
+	}
+	return nil, fmt.Errorf("缺少 %s", name)
+}
+
+// entryReader 限制条目解压后的大小，超限时返回错误而不是静默截断内容
+type entryReader struct {
+	io.ReadCloser
+	name   string
+	remain int64
+}
+
+func (e *entryReader) Read(p []byte) (int, error) {
+	if e.remain <= 0 {
+		// 已读满上限，再多读出一个字节即说明条目超限
+		var probe [1]byte
+		if n, _ := e.ReadCloser.Read(probe[:]); n > 0 {
+			return 0, fmt.Errorf("%s 解压后超过 %d MB", e.name, maxEntrySize>>20)
+		}
+		return 0, io.EOF
+	}
+	if int64(len(p)) > e.remain {
+		p = p[:e.remain]
+	}
+	n, err := e.ReadCloser.Read(p)
+	e.remain -= int64(n)
+	return n, err
+}
+
+// readEntry 读取压缩包内条目的全部内容
+func readEntry(zr *zip.Reader, name string) ([]byte, error) {
+	rc, err := openEntry(zr, name)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// heading 生成 Markdown 标题行，级别限定在 1~6
+func heading(level int, text string) string {
+	level = max(1, min(level, 6))
+	return strings.Repeat("#", level) + " " + text
+}
+
+// collapseSpace 合并连续空白为单个空格
+func collapseSpace(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,158 @@
+package docparser
+
+import (
+	"archive/zip"
+	"backend/studyCoach/common"
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// buildZip 按文件名与内容生成内存中的压缩包
+func buildZip(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return zr
+}
+
+// requireOrder 要求 parts 依次出现在 s 中
+func requireOrder(t *testing.T, s string, parts ...string) {
+	t.Helper()
+	rest := s
+	for _, p := range parts {
+		i := strings.Index(rest, p)
+		if i < 0 {
+			t.Fatalf("缺少或顺序错误: %q\n%s", p, s)
+		}
+		rest = rest[i+len(p):]
+	}
+}
+
+const wordNS = `xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"`
+
+func TestConvertDocx(t *testing.T) {
+	// 中文版 Word 的数字样式 ID 通过 styles.xml 的样式名识别为标题；列表与表格保留结构
+	zr := buildZip(t, map[string]string{
+		"word/styles.xml": `<w:styles ` + wordNS + `>
+<w:style w:styleId="1"><w:name w:val="heading 1"/></w:style>
+<w:style w:styleId="2"><w:name w:val="heading 2"/></w:style>
+</w:styles>`,
+		"word/document.xml": `<w:document ` + wordNS + `><w:body>
+<w:p><w:pPr><w:pStyle w:val="1"/></w:pPr><w:r><w:t>排序算法</w:t></w:r></w:p>
+<w:p><w:r><w:t>快速排序基于</w:t></w:r><w:r><w:t xml:space="preserve"> 分治。</w:t></w:r></w:p>
+<w:p><w:pPr><w:pStyle w:val="2"/></w:pPr><w:r><w:t>步骤</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="0"/></w:numPr></w:pPr><w:r><w:t>选基准</w:t></w:r></w:p>
+<w:p><w:pPr><w:numPr><w:ilvl w:val="1"/></w:numPr></w:pPr><w:r><w:t>取中位数</w:t></w:r></w:p>
+<w:tbl>
+<w:tr><w:tc><w:p><w:r><w:t>算法</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>复杂度</w:t></w:r></w:p></w:tc></w:tr>
+<w:tr><w:tc><w:p><w:r><w:t>快排</w:t></w:r></w:p></w:tc><w:tc><w:p><w:r><w:t>O(n log n)</w:t></w:r></w:p></w:tc></w:tr>
+</w:tbl>
+</w:body></w:document>`,
+	})
+	got, err := convertDocx(context.Background(), zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireOrder(t, got,
+		"# 排序算法\n",
+		"快速排序基于 分治。\n",
+		"## 步骤\n",
+		"- 选基准\n",
+		"  - 取中位数\n",
+		"| 算法 | 复杂度 |\n| --- | --- |\n| 快排 | O(n log n) |",
+	)
+}
+
+func TestConvertPptx(t *testing.T) {
+	// 按 presentation.xml 的放映顺序输出，幻灯片标记为放映序号；无标题的幻灯片以序号命名
+	slide := func(title, body string) string {
+		var sb strings.Builder
+		sb.WriteString(`<p:sld xmlns:a="http://schemas.openxmlformats.org/drawingml/2006/main" xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main"><p:cSld><p:spTree>`)
+		if title != "" {
+			sb.WriteString(`<p:sp><p:nvSpPr><p:nvPr><p:ph type="title"/></p:nvPr></p:nvSpPr><p:txBody><a:p><a:r><a:t>` + title + `</a:t></a:r></a:p></p:txBody></p:sp>`)
+		}
+		sb.WriteString(`<p:sp><p:txBody>` + body + `</p:txBody></p:sp></p:spTree></p:cSld></p:sld>`)
+		return sb.String()
+	}
+	zr := buildZip(t, map[string]string{
+		"ppt/presentation.xml": `<p:presentation xmlns:p="http://schemas.openxmlformats.org/presentationml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">
+<p:sldIdLst><p:sldId id="256" r:id="rId3"/><p:sldId id="257" r:id="rId2"/></p:sldIdLst></p:presentation>`,
+		"ppt/_rels/presentation.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId2" Target="slides/slide1.xml"/>
+<Relationship Id="rId3" Target="slides/slide2.xml"/>
+</Relationships>`,
+		"ppt/slides/slide2.xml": slide("二叉树", `<a:p><a:r><a:t>定义</a:t></a:r></a:p><a:p><a:pPr lvl="1"/><a:r><a:t>每个节点最多两个子节点</a:t></a:r></a:p>`),
+		"ppt/slides/slide1.xml": slide("", `<a:p><a:r><a:t>谢谢</a:t></a:r></a:p>`),
+	})
+	got, err := convertPptx(context.Background(), zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireOrder(t, got,
+		fmt.Sprintf(common.SlideMarkerFormat, 1), "# 二叉树", "定义", "- 每个节点最多两个子节点",
+		fmt.Sprintf(common.SlideMarkerFormat, 2), "# 幻灯片 2", "谢谢",
+	)
+}
+
+func TestConvertEpub(t *testing.T) {
+	// 按书脊顺序输出章节；缺失或无正文的章节跳过，章节序号保持连续
+	chapter := func(body string) string {
+		return `<html xmlns="http://www.w3.org/1999/xhtml"><head><title>t</title></head><body>` + body + `</body></html>`
+	}
+	zr := buildZip(t, map[string]string{
+		"META-INF/container.xml": `<container><rootfiles><rootfile full-path="OEBPS/content.opf"/></rootfiles></container>`,
+		"OEBPS/content.opf": `<package><manifest>
+<item id="c1" href="text/ch%201.xhtml"/><item id="c2" href="text/ch2.xhtml"/>
+<item id="empty" href="text/empty.xhtml"/><item id="missing" href="text/missing.xhtml"/>
+</manifest><spine><itemref idref="c2"/><itemref idref="empty"/><itemref idref="missing"/><itemref idref="c1"/></spine></package>`,
+		"OEBPS/text/ch2.xhtml":   chapter(`<h1>第一章 数组</h1><p>数组是 <em>连续</em> 存储。</p><ul><li>随机访问</li></ul>`),
+		"OEBPS/text/empty.xhtml": chapter(`<div> </div>`),
+		"OEBPS/text/ch 1.xhtml":  chapter(`<h2>第二章 链表</h2><blockquote>节点相连</blockquote>`),
+	})
+	got, err := convertEpub(context.Background(), zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requireOrder(t, got,
+		fmt.Sprintf(common.SectionMarkerFormat, 1), "# 第一章 数组", "数组是 连续 存储。", "- 随机访问",
+		fmt.Sprintf(common.SectionMarkerFormat, 2), "## 第二章 链表", "> 节点相连",
+	)
+	if strings.Contains(got, fmt.Sprintf(common.SectionMarkerFormat, 3)) {
+		t.Fatalf("空章节与缺失章节不应占用序号:\n%s", got)
+	}
+}
+
+func TestEntrySizeLimit(t *testing.T) {
+	// 条目解压后超过上限时返回错误，不静默截断
+	original := maxEntrySize
+	maxEntrySize = 64
+	t.Cleanup(func() { maxEntrySize = original })
+
+	zr := buildZip(t, map[string]string{"word/document.xml": strings.Repeat("a", 65)})
+	if _, err := readEntry(zr, "word/document.xml"); err == nil {
+		t.Fatal("超过上限的条目应返回错误")
+	}
+	zr = buildZip(t, map[string]string{"word/document.xml": strings.Repeat("a", 64)})
+	if data, err := readEntry(zr, "word/document.xml"); err != nil || len(data) != 64 {
+		t.Fatalf("恰好等于上限的条目应完整读取: len=%d err=%v", len(data), err)
+	}
+}
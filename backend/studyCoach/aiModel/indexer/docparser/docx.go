@@ -0,0 +1,189 @@
+package docparser
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino/components/document/parser"
+)
+
+var headingStyleRe = regexp.MustCompile(`(?i)^heading\s*([1-9])$`)
+
+// NewDocxParser 创建 Word（.docx）解析器：标题样式转为 Markdown 标题，列表与表格保留结构
+func NewDocxParser(ctx context.Context) (parser.Parser, error) {
+	return &markdownParser{name: "docx", convert: convertDocx}, nil
+}
+
+func convertDocx(ctx context.Context, zr *zip.Reader) (string, error) {
+	levels := docxHeadingLevels(zr)
+	rc, err := openEntry(zr, "word/document.xml")
+	if err != nil {
+		return "", err
+	}
+	defer rc.Close()
+
+	var (
+		out       strings.Builder
+		para      strings.Builder
+		style     string
+		outline   = -1
+		listLevel = -1
+		inText    bool
+		tblDepth  int
+		row       []string
+		cell      strings.Builder
+		rowCount  int
+	)
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para.Reset()
+				style, outline, listLevel = "", -1, -1
+			case "pStyle":
+				style = attr(t, "val")
+			case "outlineLvl":
+				outline, _ = strconv.Atoi(attr(t, "val"))
+			case "ilvl":
+				listLevel, _ = strconv.Atoi(attr(t, "val"))
+			case "numPr":
+				listLevel = max(listLevel, 0)
+			case "t":
+				inText = true
+			case "tab":
+				para.WriteString(" ")
+			case "br", "cr":
+				para.WriteString("\n")
+			case "tbl":
+				tblDepth++
+				if tblDepth == 1 {
+					rowCount = 0
+					out.WriteString("\n")
+				}
+			case "tr":
+				if tblDepth == 1 {
+					row = row[:0]
+				}
+			case "tc":
+				if tblDepth == 1 {
+					cell.Reset()
+				}
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text := strings.TrimSpace(para.String())
+				if text == "" {
+					continue
+				}
+				if tblDepth > 0 {
+					if cell.Len() > 0 {
+						cell.WriteString(" ")
+					}
+					cell.WriteString(collapseSpace(text))
+					continue
+				}
+				level := levels[style]
+				if level == 0 && outline >= 0 && outline < 9 {
+					level = outline + 1
+				}
+				switch {
+				case level > 0:
+					out.WriteString("\n" + heading(level, collapseSpace(text)) + "\n\n")
+				case listLevel >= 0:
+					out.WriteString(strings.Repeat("  ", listLevel) + "- " + text + "\n")
+				default:
+					out.WriteString(text + "\n\n")
+				}
+			case "tc":
+				if tblDepth == 1 {
+					row = append(row, strings.ReplaceAll(cell.String(), "|", "\\|"))
+				}
+			case "tr":
+				if tblDepth == 1 && len(row) > 0 {
+					out.WriteString("| " + strings.Join(row, " | ") + " |\n")
+					if rowCount == 0 {
+						out.WriteString(strings.Repeat("| --- ", len(row)) + "|\n")
+					}
+					rowCount++
+				}
+			case "tbl":
+				tblDepth--
+				if tblDepth == 0 {
+					out.WriteString("\n")
+				}
+			}
+		}
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// docxHeadingLevels 从 styles.xml 解析样式 ID 对应的标题级别（兼容中文版 Word 的数字样式 ID）
+func docxHeadingLevels(zr *zip.Reader) map[string]int {
+	levels := map[string]int{"Title": 1}
+	for i := 1; i <= 9; i++ {
+		levels["Heading"+strconv.Itoa(i)] = i
+	}
+	data, err := readEntry(zr, "word/styles.xml")
+	if err != nil {
+		return levels
+	}
+	var styles struct {
+		Style []struct {
+			ID      string   `xml:"styleId,attr"`
+			Name    valAttr  `xml:"name"`
+			Outline *valAttr `xml:"pPr>outlineLvl"`
+		} `xml:"style"`
+	}
+	if err = xml.Unmarshal(data, &styles); err != nil {
+		return levels
+	}
+	for _, s := range styles.Style {
+		name := strings.TrimSpace(s.Name.Val)
+		switch m := headingStyleRe.FindStringSubmatch(name); {
+		case m != nil:
+			levels[s.ID], _ = strconv.Atoi(m[1])
+		case strings.EqualFold(name, "title"):
+			levels[s.ID] = 1
+		case s.Outline != nil:
+			if n, err := strconv.Atoi(s.Outline.Val); err == nil && n < 9 {
+				levels[s.ID] = n + 1
+			}
+		}
+	}
+	return levels
+}
+
+type valAttr struct {
+	Val string `xml:"val,attr"`
+}
+
+// attr 按本地名读取属性值（忽略命名空间前缀）
+func attr(e xml.StartElement, local string) string {
+	for _, a := range e.Attr {
+		if a.Name.Local == local {
+			return a.Value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,200 @@
+package docparser
+
+import (
+	"archive/zip"
+	"backend/studyCoach/common"
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"github.com/cloudwego/eino/components/document/parser"
+	"golang.org/x/net/html"
+)
+
+// NewEpubParser 创建电子书（.epub）解析器：按书脊（spine）顺序输出各章节，
+// HTML 标题转为 Markdown 标题，并写入章节序号标记
+func NewEpubParser(ctx context.Context) (parser.Parser, error) {
+	return &markdownParser{name: "epub", convert: convertEpub}, nil
+}
+
+func convertEpub(ctx context.Context, zr *zip.Reader) (string, error) {
+	chapters, err := epubSpine(zr)
+	if err != nil {
+		return "", err
+	}
+	var (
+		out     strings.Builder
+		section int
+	)
+	for _, name := range chapters {
+		if err = ctx.Err(); err != nil {
+			return "", err
+		}
+		data, err := readEntry(zr, name)
+		if err != nil {
+			// 书脊引用缺失的文件时跳过该章节
+			continue
+		}
+		text, err := xhtmlToMarkdown(data)
+		if err != nil {
+			return "", fmt.Errorf("%s: %w", name, err)
+		}
+		if text == "" {
+			continue
+		}
+		section++
+		out.WriteString(fmt.Sprintf(common.SectionMarkerFormat, section))
+		out.WriteString("\n\n" + text + "\n\n")
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// epubSpine 通过 container.xml 定位 OPF，按书脊顺序返回章节文件路径
+func epubSpine(zr *zip.Reader) ([]string, error) {
+	data, err := readEntry(zr, "META-INF/container.xml")
+	if err != nil {
+		return nil, err
+	}
+	var container struct {
+		Rootfiles []struct {
+			FullPath string `xml:"full-path,attr"`
+		} `xml:"rootfiles>rootfile"`
+	}
+	if err = xml.Unmarshal(data, &container); err != nil {
+		return nil, err
+	}
+	if len(container.Rootfiles) == 0 {
+		return nil, fmt.Errorf("container.xml 未声明 rootfile")
+	}
+	opfPath := container.Rootfiles[0].FullPath
+	if data, err = readEntry(zr, opfPath); err != nil {
+		return nil, err
+	}
+	var pkg struct {
+		Items []struct {
+			ID   string `xml:"id,attr"`
+			Href string `xml:"href,attr"`
+		} `xml:"manifest>item"`
+		ItemRefs []struct {
+			IDRef string `xml:"idref,attr"`
+		} `xml:"spine>itemref"`
+	}
+	if err = xml.Unmarshal(data, &pkg); err != nil {
+		return nil, err
+	}
+	hrefs := make(map[string]string, len(pkg.Items))
+	for _, item := range pkg.Items {
+		hrefs[item.ID] = item.Href
+	}
+	baseDir := path.Dir(opfPath)
+	chapters := make([]string, 0, len(pkg.ItemRefs))
+	for _, ref := range pkg.ItemRefs {
+		href, ok := hrefs[ref.IDRef]
+		if !ok {
+			continue
+		}
+		if unescaped, err := url.PathUnescape(href); err == nil {
+			href = unescaped
+		}
+		chapters = append(chapters, path.Join(baseDir, href))
+	}
+	return chapters, nil
+}
+
+// xhtmlToMarkdown 将章节 XHTML 转为 Markdown：保留标题、段落、列表与引用
+func xhtmlToMarkdown(data []byte) (string, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(data))
+	if err != nil {
+		return "", err
+	}
+	var blocks []string
+	for _, n := range doc.Find("body").Nodes {
+		blocks = appendBlocks(blocks, n)
+	}
+	return strings.Join(blocks, "\n\n"), nil
+}
+
+// appendBlocks 递归遍历块级元素，内联内容合并为一段文本
+func appendBlocks(blocks []string, n *html.Node) []string {
+	var inline strings.Builder
+	flush := func() {
+		if text := collapseSpace(inline.String()); text != "" {
+			blocks = append(blocks, text)
+		}
+		inline.Reset()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			inline.WriteString(c.Data)
+			continue
+		}
+		if c.Type != html.ElementNode {
+			continue
+		}
+		switch c.Data {
+		case "script", "style", "head":
+		case "h1", "h2", "h3", "h4", "h5", "h6":
+			flush()
+			if text := nodeText(c); text != "" {
+				blocks = append(blocks, heading(int(c.Data[1]-'0'), text))
+			}
+		case "p", "blockquote", "pre", "figcaption", "dt", "dd":
+			flush()
+			if text := nodeText(c); text != "" {
+				if c.Data == "blockquote" {
+					text = "> " + text
+				}
+				blocks = append(blocks, text)
+			}
+		case "li":
+			flush()
+			if text := nodeText(c); text != "" {
+				blocks = append(blocks, "- "+text)
+			}
+		case "tr":
+			flush()
+			var cells []string
+			for td := c.FirstChild; td != nil; td = td.NextSibling {
+				if td.Type == html.ElementNode && (td.Data == "td" || td.Data == "th") {
+					cells = append(cells, strings.ReplaceAll(nodeText(td), "|", "\\|"))
+				}
+			}
+			if len(cells) > 0 {
+				blocks = append(blocks, "| "+strings.Join(cells, " | ")+" |")
+			}
+		case "br":
+			inline.WriteString(" ")
+		case "div", "section", "article", "ul", "ol", "dl", "table", "thead", "tbody", "tfoot", "figure", "aside", "nav", "header", "footer", "main":
+			flush()
+			blocks = appendBlocks(blocks, c)
+		default:
+			inline.WriteString(nodeText(c))
+		}
+	}
+	flush()
+	return blocks
+}
+
+// nodeText 节点内全部文本，空白合并
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+		}
+		if n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style") {
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return collapseSpace(sb.String())
+}
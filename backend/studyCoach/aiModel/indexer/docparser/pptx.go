@@ -0,0 +1,203 @@
+package docparser
+
+import (
+	"archive/zip"
+	"backend/studyCoach/common"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino/components/document/parser"
+)
+
+// NewPptxParser 创建 PowerPoint（.pptx）解析器：每张幻灯片以标题为一级标题，正文按层级输出列表，
+// 并写入幻灯片序号标记
+func NewPptxParser(ctx context.Context) (parser.Parser, error) {
+	return &markdownParser{name: "pptx", convert: convertPptx}, nil
+}
+
+func convertPptx(ctx context.Context, zr *zip.Reader) (string, error) {
+	slides, err := pptxSlidePaths(zr)
+	if err != nil {
+		return "", err
+	}
+	var out strings.Builder
+	for i, name := range slides {
+		if err = ctx.Err(); err != nil {
+			return "", err
+		}
+		title, body, err := pptxSlide(zr, name)
+		if err != nil {
+			return "", fmt.Errorf("第 %d 张幻灯片: %w", i+1, err)
+		}
+		if title == "" && body == "" {
+			continue
+		}
+		if title == "" {
+			title = fmt.Sprintf("幻灯片 %d", i+1)
+		}
+		out.WriteString(fmt.Sprintf(common.SlideMarkerFormat, i+1))
+		out.WriteString("\n\n" + heading(1, title) + "\n\n")
+		if body != "" {
+			out.WriteString(body + "\n\n")
+		}
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// pptxSlidePaths 按 presentation.xml 中的放映顺序返回幻灯片路径
+func pptxSlidePaths(zr *zip.Reader) ([]string, error) {
+	data, err := readEntry(zr, "ppt/presentation.xml")
+	if err != nil {
+		return nil, err
+	}
+	var pres struct {
+		SlideIds []struct {
+			Attrs []xml.Attr `xml:",any,attr"`
+		} `xml:"sldIdLst>sldId"`
+	}
+	if err = xml.Unmarshal(data, &pres); err != nil {
+		return nil, err
+	}
+	rels, err := readRels(zr, "ppt/_rels/presentation.xml.rels", "ppt")
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(pres.SlideIds))
+	for _, s := range pres.SlideIds {
+		for _, a := range s.Attrs {
+			// r:id 带关系命名空间，区别于数字 id
+			if a.Name.Local == "id" && a.Name.Space != "" {
+				if target, ok := rels[a.Value]; ok {
+					paths = append(paths, target)
+				}
+			}
+		}
+	}
+	return paths, nil
+}
+
+// readRels 读取 .rels 关系文件，返回 Id -> 目标路径（相对 baseDir 解析）
+func readRels(zr *zip.Reader, name, baseDir string) (map[string]string, error) {
+	data, err := readEntry(zr, name)
+	if err != nil {
+		return nil, err
+	}
+	var rels struct {
+		Relationship []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err = xml.Unmarshal(data, &rels); err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(rels.Relationship))
+	for _, r := range rels.Relationship {
+		if strings.HasPrefix(r.Target, "/") {
+			m[r.ID] = strings.TrimPrefix(r.Target, "/")
+		} else {
+			m[r.ID] = path.Join(baseDir, r.Target)
+		}
+	}
+	return m, nil
+}
+
+// pptxSlide 解析单张幻灯片，返回标题与正文（表格按行输出）
+func pptxSlide(zr *zip.Reader, name string) (title, body string, err error) {
+	rc, err := openEntry(zr, name)
+	if err != nil {
+		return "", "", err
+	}
+	defer rc.Close()
+
+	var (
+		lines    []string
+		titles   []string
+		para     strings.Builder
+		isTitle  bool
+		level    int
+		inText   bool
+		inTable  bool
+		row      []string
+		rowCount int
+		cellText []string
+	)
+	dec := xml.NewDecoder(rc)
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "sp":
+				isTitle = false
+			case "ph":
+				typ := attr(t, "type")
+				isTitle = typ == "title" || typ == "ctrTitle"
+			case "p":
+				para.Reset()
+				level = 0
+			case "pPr":
+				level, _ = strconv.Atoi(attr(t, "lvl"))
+			case "t":
+				inText = true
+			case "br":
+				para.WriteString(" ")
+			case "tbl":
+				inTable = true
+				rowCount = 0
+			case "tr":
+				row = row[:0]
+			case "tc":
+				cellText = cellText[:0]
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "t":
+				inText = false
+			case "p":
+				text := collapseSpace(para.String())
+				switch {
+				case text == "":
+				case inTable:
+					cellText = append(cellText, text)
+				case isTitle:
+					titles = append(titles, text)
+				case level > 0:
+					lines = append(lines, strings.Repeat("  ", level-1)+"- "+text)
+				default:
+					lines = append(lines, text)
+				}
+			case "tc":
+				row = append(row, strings.ReplaceAll(strings.Join(cellText, " "), "|", "\\|"))
+			case "tr":
+				if len(row) > 0 {
+					lines = append(lines, "| "+strings.Join(row, " | ")+" |")
+					if rowCount == 0 {
+						lines = append(lines, strings.Repeat("| --- ", len(row))+"|")
+					}
+					rowCount++
+				}
+			case "tbl":
+				inTable = false
+			case "sp":
+				isTitle = false
+			}
+		}
+	}
+	return strings.Join(titles, " "), strings.Join(lines, "\n"), nil
+}
@@ -53,9 +53,9 @@ func NewIndexer(ctx context.Context, config *Config) (indexer.Indexer, error) {
 			} else {
 				return nil, fmt.Errorf("必须提供知识库名称")
 			}
-			var knowledgeBaseId int64
-			if value, ok := ctx.Value(common.KnowledgeBaseIdKey).(int64); ok {
-				knowledgeBaseId = value
+			ns := common.NamespaceFromContext(ctx)
+			if !ns.IsValid() {
+				return nil, fmt.Errorf("必须提供知识库 ID 与所属用户")
 			}
 			if !config.IncludeQAVector && len(doc.ID) == 0 {
 				doc.ID = uuid.New().String()
@@ -79,7 +79,10 @@ func NewIndexer(ctx context.Context, config *Config) (indexer.Indexer, error) {
 					Value: knowledgeName,
 				},
 				common.KnowledgeBaseId: {
-					Value: knowledgeBaseId,
+					Value: ns.KnowledgeBaseId,
+				},
+				common.UserUUID: {
+					Value: ns.UserUUID,
 				},
 			}
 			if config.IncludeQAVector {
@@ -15,7 +15,7 @@ import (
 )
 
 // OnIndexedCallback 索引完成后的回调，用于异步 QA 生成与状态更新。
-// 参数：ctx（含 KnowledgeName）、docs、documentsId。
+// 参数：ctx（含 KnowledgeName 与命名空间）、docs、documentsId。
 type OnIndexedCallback func(ctx context.Context, docs []*schema.Document, documentsId int64)
 
 // wrapIndexerWithChunks 包装 indexer：在写入向量库前落库 MySQL chunks，写入后触发 QA 回调。
@@ -62,11 +62,13 @@ func (w *indexerWithChunks) Store(ctx context.Context, docs []*schema.Document,
 
 	if w.onIndexed != nil && len(docs) > 0 && documentsId > 0 {
 		knowledgeName, _ := ctx.Value(common.KnowledgeName).(string)
+		ns := common.NamespaceFromContext(ctx)
 		docsCopy := make([]*schema.Document, len(docs))
 		copy(docsCopy, docs)
 		go func() {
 			ctxN := gctx.New()
 			ctxN = context.WithValue(ctxN, common.KnowledgeName, knowledgeName)
+			ctxN = common.WithNamespace(ctxN, ns)
 			w.onIndexed(ctxN, docsCopy, documentsId)
 		}()
 	}
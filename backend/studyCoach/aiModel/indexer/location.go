@@ -0,0 +1,116 @@
+package indexer
+
+import (
+	"backend/studyCoach/common"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/cloudwego/eino-ext/components/document/loader/file"
+	"github.com/cloudwego/eino/schema"
+)
+
+var locationMarkerRe = regexp.MustCompile(`<!--\s*(page|slide|section):\s*(\d+)\s*-->`)
+
+// locationKeys 位置标记类型对应的 MetaData 字段
+var locationKeys = map[string]string{
+	"page":    common.PageNumber,
+	"slide":   common.SlideNumber,
+	"section": common.SectionNumber,
+}
+
+// annotateLocations 解析文档解析阶段写入的页码/幻灯片/章节标记，将序号范围写入 MetaData 并移除标记。
+// 按分段顺序继承上一段的位置，仅统计实际有正文的部分；移除标记后为空的分段会被丢弃。
+func annotateLocations(docs []*schema.Document) []*schema.Document {
+	cur := map[string]int{}
+	var source any
+	for _, doc := range docs {
+		if doc.MetaData == nil {
+			doc.MetaData = make(map[string]any)
+		}
+		if doc.MetaData[file.MetaKeySource] != source {
+			source = doc.MetaData[file.MetaKeySource]
+			cur = map[string]int{}
+		}
+		locs := locationMarkerRe.FindAllStringSubmatchIndex(doc.Content, -1)
+		if len(locs) == 0 && len(cur) == 0 {
+			continue
+		}
+		ranges := map[string][2]int{}
+		add := func(text string) {
+			if strings.TrimSpace(text) == "" {
+				return
+			}
+			for kind, n := range cur {
+				r, ok := ranges[kind]
+				if !ok {
+					ranges[kind] = [2]int{n, n}
+					continue
+				}
+				ranges[kind] = [2]int{min(r[0], n), max(r[1], n)}
+			}
+		}
+		prev := 0
+		for _, loc := range locs {
+			add(doc.Content[prev:loc[0]])
+			cur[doc.Content[loc[2]:loc[3]]], _ = strconv.Atoi(doc.Content[loc[4]:loc[5]])
+			prev = loc[1]
+		}
+		add(doc.Content[prev:])
+		if len(locs) > 0 {
+			doc.Content = strings.TrimSpace(locationMarkerRe.ReplaceAllString(doc.Content, ""))
+		}
+		for kind, r := range ranges {
+			doc.MetaData[locationKeys[kind]] = formatLocationRange(r[0], r[1])
+		}
+	}
+	out := docs[:0]
+	for _, doc := range docs {
+		if doc.Content != "" {
+			out = append(out, doc)
+		}
+	}
+	return out
+}
+
+// mergeLocations 合并两个分段的页码/幻灯片/章节范围
+func mergeLocations(orgDoc, addDoc *schema.Document) {
+	for _, key := range locationKeys {
+		a, aok := parseLocationRange(orgDoc.MetaData[key])
+		b, bok := parseLocationRange(addDoc.MetaData[key])
+		switch {
+		case aok && bok:
+			orgDoc.MetaData[key] = formatLocationRange(min(a[0], b[0]), max(a[1], b[1]))
+		case bok:
+			orgDoc.MetaData[key] = formatLocationRange(b[0], b[1])
+		}
+	}
+}
+
+func parseLocationRange(v any) ([2]int, bool) {
+	s, ok := v.(string)
+	if !ok || s == "" {
+		return [2]int{}, false
+	}
+	from, to, found := strings.Cut(s, "-")
+	a, err := strconv.Atoi(from)
+	if err != nil {
+		return [2]int{}, false
+	}
+	if !found {
+		return [2]int{a, a}, true
+	}
+	b, err := strconv.Atoi(to)
+	if err != nil {
+		return [2]int{}, false
+	}
+	return [2]int{a, b}, true
+}
+
+func formatLocationRange(first, last int) string {
+	if first == last {
+		return strconv.Itoa(first)
+	}
+	return fmt.Sprintf("%d-%d", first, last)
+}
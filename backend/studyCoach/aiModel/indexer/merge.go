@@ -31,10 +31,11 @@ func addDocIDAndMerge(ctx context.Context, docs []*schema.Document) (output []*s
 	for _, doc := range docs {
 		doc.ID = uuid.New().String() // 覆盖之前的id
 	}
-	switch docs[0].MetaData[file.MetaKeyExtension] {
-	case ".md":
+	ext, _ := docs[0].MetaData[file.MetaKeyExtension].(string)
+	switch {
+	case common.MarkdownExts[ext]:
 		return mergeMarkdown(ctx, docs)
-	case ".xlsx":
+	case ext == ".xlsx":
 		return mergeExcel(ctx, docs)
 	default:
 		return docs, nil
@@ -72,6 +73,7 @@ func mergeMarkdown(ctx context.Context, docs []*schema.Document) (output []*sche
 		} else {
 			mergeTitle(nd, doc, common.Title2)
 			mergeTitle(nd, doc, common.Title3)
+			mergeLocations(nd, doc)
 			nd.Content += doc.Content
 		}
 	}
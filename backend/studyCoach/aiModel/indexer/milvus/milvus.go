@@ -68,7 +68,7 @@ func NewIndexer(ctx context.Context, config *Config) (indexer.Indexer, error) {
 	return &indexerWrapper{inner: inner}, nil
 }
 
-// indexerWrapper 包装 eino-ext Milvus indexer，在 Store 时注入 knowledge_name
+// indexerWrapper 包装 eino-ext Milvus indexer，在 Store 时注入 knowledge_name 与命名空间
 type indexerWrapper struct {
 	inner indexer.Indexer
 }
@@ -78,8 +78,11 @@ func (w *indexerWrapper) Store(ctx context.Context, docs []*schema.Document, opt
 	if knowledgeName == "" {
 		return nil, fmt.Errorf("必须提供知识库名称")
 	}
-	knowledgeBaseId, _ := ctx.Value(common.KnowledgeBaseIdKey).(int64)
-	g.Log().Infof(ctx, "MilvusIndexer.Store: storing %d documents, knowledge_name=%s, kb_id=%d", len(docs), knowledgeName, knowledgeBaseId)
+	ns := common.NamespaceFromContext(ctx)
+	if !ns.IsValid() {
+		return nil, fmt.Errorf("必须提供知识库 ID 与所属用户")
+	}
+	g.Log().Infof(ctx, "MilvusIndexer.Store: storing %d documents, knowledge_name=%s, %s", len(docs), knowledgeName, ns)
 
 	for _, doc := range docs {
 		if len(doc.ID) == 0 {
@@ -89,9 +92,8 @@ func (w *indexerWrapper) Store(ctx context.Context, docs []*schema.Document, opt
 			doc.MetaData = make(map[string]any)
 		}
 		doc.MetaData[common.KnowledgeName] = knowledgeName
-		if knowledgeBaseId > 0 {
-			doc.MetaData[common.KnowledgeBaseId] = knowledgeBaseId
-		}
+		doc.MetaData[common.KnowledgeBaseId] = ns.KnowledgeBaseId
+		doc.MetaData[common.UserUUID] = ns.UserUUID
 		if ext := docmeta.GetExtData(doc); len(ext) > 0 {
 			marshal, _ := sonic.Marshal(ext)
 			doc.MetaData[common.FieldExtra] = string(marshal)
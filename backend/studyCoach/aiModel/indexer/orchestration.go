@@ -7,7 +7,7 @@ import (
 	"github.com/cloudwego/eino/compose"
 )
 
-// BuildIndexer 构建索引管线。onIndexed 非空时在向量库写入后执行（QA 生成 + 状态更新）。
+// BuildIndexer 构建索引管线，写入向量库前落库 MySQL chunks。onIndexed 非空时在向量库写入后异步执行。
 func BuildIndexer(ctx context.Context, conf *common.Config, onIndexed OnIndexedCallback) (r compose.Runnable[any, []string], err error) {
 	const (
 		Loader1              = "Loader"
@@ -26,10 +26,8 @@ func BuildIndexer(ctx context.Context, conf *common.Config, onIndexed OnIndexedC
 	if err != nil {
 		return nil, err
 	}
-	indexer2KeyOfIndexer := innerIndexer
-	if onIndexed != nil {
-		indexer2KeyOfIndexer = wrapIndexerWithChunks(innerIndexer, onIndexed)
-	}
+	// 始终落库 MySQL chunks；QA 由调用方（索引任务 QA 阶段）或 onIndexed 负责
+	indexer2KeyOfIndexer := wrapIndexerWithChunks(innerIndexer, onIndexed)
 	_ = g.AddIndexerNode(Indexer2, indexer2KeyOfIndexer)
 	documentTransformer2KeyOfDocumentTransformer, err := newDocumentTransformer(ctx)
 	if err != nil {
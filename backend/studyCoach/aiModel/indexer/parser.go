@@ -1,6 +1,7 @@
 package indexer
 
 import (
+	"backend/studyCoach/aiModel/indexer/docparser"
 	"backend/studyCoach/common"
 	"context"
 
@@ -24,6 +25,19 @@ func newParser(ctx context.Context) (p parser.Parser, err error) {
 		return nil, err
 	}
 
+	docxParser, err := docparser.NewDocxParser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	pptxParser, err := docparser.NewPptxParser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	epubParser, err := docparser.NewEpubParser(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// PDF 不在此解析：索引前由 MinerU 转为 Markdown 后再走 Loader（见 rag Indexer 与 mineruworker）。
 	// 创建扩展解析器
 	p, err = parser.NewExtParser(ctx, &parser.ExtParserConfig{
@@ -31,6 +45,9 @@ func newParser(ctx context.Context) (p parser.Parser, err error) {
 		Parsers: map[string]parser.Parser{
 			".html": htmlParser,
 			".xlsx": xlsxParser,
+			".docx": docxParser,
+			".pptx": pptxParser,
+			".epub": epubParser,
 		},
 		// 设置默认解析器，用于处理未知格式
 		FallbackParser: textParser,
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cloudwego/eino/components"
 	"github.com/cloudwego/eino/schema"
 	"github.com/gogf/gf/v2/frame/g"
 )
@@ -66,7 +67,7 @@ func getQAContent(ctx context.Context, doc *schema.Document, knowledgeName strin
 	if err != nil {
 		return
 	}
-	generate, err := cm.Generate(ctx, []*schema.Message{
+	generate, err := cm.Generate(common.WithCallName(ctx, "QAGeneration", components.ComponentOfChatModel), []*schema.Message{
 		{
 			Role: schema.System,
 			Content: fmt.Sprintf("你是一个专业的问题生成助手，任务是从给定的文本中提取或生成可能的问题。你不需要回答这些问题，只需生成问题本身。\n"+
@@ -101,7 +102,7 @@ func getQAContentWithTime(ctx context.Context, doc *schema.Document, knowledgeNa
 	if err != nil {
 		return
 	}
-	ctx2, cancel := context.WithTimeout(ctx, timeout)
+	ctx2, cancel := context.WithTimeout(common.WithCallName(ctx, "QAGeneration", components.ComponentOfChatModel), timeout)
 	defer cancel()
 	content := clipContent(doc.Content, 5000)
 	generate, err := cm.Generate(ctx2, []*schema.Message{
@@ -29,12 +29,12 @@ func (idx *Indexer) StoreWithNamedVectors(ctx context.Context, docs []*schema.Do
 		return nil, fmt.Errorf("必须提供知识库名称")
 	}
 
-	var knowledgeBaseId int64
-	if value, ok := ctx.Value(common.KnowledgeBaseIdKey).(int64); ok {
-		knowledgeBaseId = value
+	ns := common.NamespaceFromContext(ctx)
+	if !ns.IsValid() {
+		return nil, fmt.Errorf("必须提供知识库 ID 与所属用户")
 	}
 
-	g.Log().Infof(ctx, "QdrantIndexer.StoreWithNamedVectors: storing %d documents to collection %s, knowledge_name=%s", len(docs), idx.config.Collection, knowledgeName)
+	g.Log().Infof(ctx, "QdrantIndexer.StoreWithNamedVectors: storing %d documents to collection %s, knowledge_name=%s, %s", len(docs), idx.config.Collection, knowledgeName, ns)
 
 	points := make([]*qdrantclient.PointStruct, 0, len(docs))
 	ids := make([]string, 0, len(docs))
@@ -66,10 +66,11 @@ func (idx *Indexer) StoreWithNamedVectors(ctx context.Context, docs []*schema.Do
 		payload[common.KnowledgeName] = &qdrantclient.Value{
 			Kind: &qdrantclient.Value_StringValue{StringValue: knowledgeName},
 		}
-		if knowledgeBaseId > 0 {
-			payload[common.KnowledgeBaseId] = &qdrantclient.Value{
-				Kind: &qdrantclient.Value_IntegerValue{IntegerValue: knowledgeBaseId},
-			}
+		payload[common.KnowledgeBaseId] = &qdrantclient.Value{
+			Kind: &qdrantclient.Value_IntegerValue{IntegerValue: ns.KnowledgeBaseId},
+		}
+		payload[common.UserUUID] = &qdrantclient.Value{
+			Kind: &qdrantclient.Value_StringValue{StringValue: ns.UserUUID},
 		}
 
 		if doc.MetaData != nil {
@@ -44,14 +44,32 @@ func mostlyEnglish(s string) bool {
 	return float64(ascii)/float64(letters) > 0.6
 }
 
+const (
+	chunkSize    = 1000 // 每段内容1000字
+	chunkOverlap = 100  // 有10%的重叠
+)
+
+var chunkSeparators = []string{"\n", "。", "?", "？", "!", "！"}
+
+// ChunkingConfig 当前切分配置，随评测运行一并记录，便于对比不同切分策略的效果
+func ChunkingConfig() map[string]any {
+	return map[string]any{
+		"splitter":         "recursive",
+		"chunk_size":       chunkSize,
+		"overlap_size":     chunkOverlap,
+		"separators":       chunkSeparators,
+		"markdown_headers": []string{"#", "##", "###"},
+	}
+}
+
 // newDocumentTransformer component initialization function of node 'DocumentTransformer3' in graph 'rag'
 func newDocumentTransformer(ctx context.Context) (tfr document.Transformer, err error) {
 	trans := &transformer{}
 	// 递归分割
 	config := &recursive.Config{
-		ChunkSize:   1000, // 每段内容1000字
-		OverlapSize: 100,  // 有10%的重叠
-		Separators:  []string{"\n", "。", "?", "？", "!", "！"},
+		ChunkSize:   chunkSize,
+		OverlapSize: chunkOverlap,
+		Separators:  chunkSeparators,
 	}
 	recTrans, err := recursive.NewSplitter(ctx, config)
 	if err != nil {
@@ -78,14 +96,19 @@ type transformer struct {
 func (x *transformer) Transform(ctx context.Context, docs []*schema.Document, opts ...document.TransformerOption) ([]*schema.Document, error) {
 	isMd := false
 	for _, doc := range docs {
-		// 只需要判断第一个是不是.md
-		if doc.MetaData["_extension"] == ".md" {
+		// 只需要判断第一个是不是 Markdown 结构（.md 及 docx/pptx/epub 解析产物）
+		if ext, _ := doc.MetaData["_extension"].(string); common.MarkdownExts[ext] {
 			isMd = true
 			break
 		}
 	}
 	if isMd {
-		return x.markdown.Transform(ctx, docs, opts...)
+		out, err := x.markdown.Transform(ctx, docs, opts...)
+		if err != nil {
+			return nil, err
+		}
+		// 解析产物带有页码/幻灯片/章节标记，转为 chunk 元数据
+		return annotateLocations(out), nil
 	}
 	return x.recursive.Transform(ctx, docs, opts...)
 }
@@ -0,0 +1,209 @@
+package retriever
+
+import (
+	"backend/internal/model/entity"
+	"backend/studyCoach/common"
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// BM25 参数，与 ES 默认值一致
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// ChunkLoader 本地倒排索引的切片来源，由调用方注入（通常为知识库切片表），检索包不依赖业务逻辑
+type ChunkLoader struct {
+	// Version 知识库切片版本标识，切片增删改或启停后变化
+	Version func(ctx context.Context, ns common.Namespace) (string, error)
+	// Chunks 知识库的全部启用切片
+	Chunks func(ctx context.Context, ns common.Namespace) ([]entity.KnowledgeChunks, error)
+}
+
+// localBM25 基于切片表的本地倒排索引，按知识库懒加载到进程内缓存，切片版本变化时重建
+type localBM25 struct {
+	loader ChunkLoader
+	cache  *bm25Cache
+}
+
+type bm25Posting struct {
+	doc int
+	tf  int
+}
+
+type bm25Index struct {
+	version  string
+	size     int // 切片正文总字节数，用于缓存容量统计
+	chunks   []entity.KnowledgeChunks
+	lengths  []int
+	avgLen   float64
+	postings map[string][]bm25Posting
+}
+
+func (l *localBM25) Search(ctx context.Context, req *LexicalRequest) ([]*schema.Document, error) {
+	if !req.Namespace.IsValid() {
+		return nil, fmt.Errorf("invalid retriever namespace (%s)", req.Namespace)
+	}
+	idx, err := l.index(ctx, req.Namespace)
+	if err != nil || idx == nil {
+		return nil, err
+	}
+	exclude := make(map[string]bool, len(req.ExcludeIDs))
+	for _, id := range req.ExcludeIDs {
+		exclude[id] = true
+	}
+	hits, scores := idx.rank(req.Query, exclude, req.TopK)
+	docs := make([]*schema.Document, 0, len(hits))
+	for _, i := range hits {
+		chunk := idx.chunks[i]
+		doc := &schema.Document{
+			ID:      chunk.ChunkId,
+			Content: chunk.Content,
+			MetaData: map[string]any{
+				common.FieldExtra:      chunk.Ext,
+				common.KnowledgeName:   req.KnowledgeName,
+				common.KnowledgeBaseId: req.Namespace.KnowledgeBaseId,
+				common.UserUUID:        req.Namespace.UserUUID,
+			},
+		}
+		docs = append(docs, doc.WithScore(scores[i]))
+	}
+	return docs, nil
+}
+
+// rank 按 BM25 计算 query 与各切片的得分，返回按得分降序的切片下标（不含 exclude 中的 chunk_id，最多 topK 个）
+func (idx *bm25Index) rank(query string, exclude map[string]bool, topK int) ([]int, map[int]float64) {
+	n := float64(len(idx.chunks))
+	scores := make(map[int]float64)
+	for _, term := range uniqueTerms(Tokenize(query)) {
+		list := idx.postings[term]
+		if len(list) == 0 {
+			continue
+		}
+		idf := math.Log(1 + (n-float64(len(list))+0.5)/(float64(len(list))+0.5))
+		for _, p := range list {
+			tf := float64(p.tf)
+			norm := bm25K1 * (1 - bm25B + bm25B*float64(idx.lengths[p.doc])/idx.avgLen)
+			scores[p.doc] += idf * tf * (bm25K1 + 1) / (tf + norm)
+		}
+	}
+	hits := make([]int, 0, len(scores))
+	for i := range scores {
+		if !exclude[idx.chunks[i].ChunkId] {
+			hits = append(hits, i)
+		}
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		return scores[hits[i]] > scores[hits[j]]
+	})
+	if topK > 0 && len(hits) > topK {
+		hits = hits[:topK]
+	}
+	return hits, scores
+}
+
+// index 获取知识库的倒排索引，缓存缺失、过期或版本变化时重建；知识库无切片时返回 nil
+func (l *localBM25) index(ctx context.Context, ns common.Namespace) (*bm25Index, error) {
+	kbId := ns.KnowledgeBaseId
+	version, err := l.loader.Version(ctx, ns)
+	if err != nil {
+		return nil, err
+	}
+	if idx := l.cache.get(kbId); idx != nil && idx.version == version {
+		return idx, nil
+	}
+	chunks, err := l.loader.Chunks(ctx, ns)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		l.cache.remove(kbId)
+		return nil, nil
+	}
+	idx := buildBM25Index(version, chunks)
+	l.cache.put(kbId, idx)
+	return idx, nil
+}
+
+func buildBM25Index(version string, chunks []entity.KnowledgeChunks) *bm25Index {
+	idx := &bm25Index{
+		version:  version,
+		chunks:   chunks,
+		lengths:  make([]int, len(chunks)),
+		postings: make(map[string][]bm25Posting),
+	}
+	total := 0
+	for i, chunk := range chunks {
+		terms := Tokenize(chunk.Content)
+		idx.size += len(chunk.Content)
+		idx.lengths[i] = len(terms)
+		total += len(terms)
+		tf := make(map[string]int, len(terms))
+		for _, t := range terms {
+			tf[t]++
+		}
+		for t, c := range tf {
+			idx.postings[t] = append(idx.postings[t], bm25Posting{doc: i, tf: c})
+		}
+	}
+	idx.avgLen = max(float64(total)/float64(len(chunks)), 1)
+	return idx
+}
+
+// Tokenize 简单分词：字母数字按单词小写切分；中日韩文字输出单字与相邻二元组，兼顾召回与短语匹配
+func Tokenize(text string) []string {
+	var (
+		terms []string
+		word  strings.Builder
+		prev  rune
+	)
+	flush := func() {
+		if word.Len() > 0 {
+			terms = append(terms, word.String())
+			word.Reset()
+		}
+	}
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flush()
+			terms = append(terms, string(r))
+			if prev != 0 {
+				terms = append(terms, string([]rune{prev, r}))
+			}
+			prev = r
+			continue
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			word.WriteRune(unicode.ToLower(r))
+		default:
+			flush()
+		}
+		prev = 0
+	}
+	flush()
+	return terms
+}
+
+func isCJK(r rune) bool {
+	return unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || unicode.Is(unicode.Hangul, r)
+}
+
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	out := terms[:0]
+	for _, t := range terms {
+		if !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	return out
+}
@@ -0,0 +1,121 @@
+package retriever
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// 本地倒排索引缓存的默认容量（切片正文总量）与空闲有效期
+const (
+	defaultBM25CacheMB  = 256
+	defaultBM25CacheTTL = 30 * time.Minute
+)
+
+var (
+	bm25CacheOnce   sync.Once
+	sharedBM25Cache *bm25Cache
+)
+
+// localBM25Cache 进程内共享的倒排索引缓存，容量与有效期读取
+// retriever.hybrid.localIndexMaxMB、retriever.hybrid.localIndexTTL
+func localBM25Cache(ctx context.Context) *bm25Cache {
+	bm25CacheOnce.Do(func() {
+		maxMB := g.Cfg().MustGet(ctx, "retriever.hybrid.localIndexMaxMB", defaultBM25CacheMB).Int()
+		ttl := g.Cfg().MustGet(ctx, "retriever.hybrid.localIndexTTL", defaultBM25CacheTTL).Duration()
+		sharedBM25Cache = newBM25Cache(max(maxMB, 1)<<20, ttl)
+	})
+	return sharedBM25Cache
+}
+
+// EvictLocalIndex 释放知识库的本地倒排索引，删除知识库时调用
+func EvictLocalIndex(kbId int64) {
+	localBM25Cache(context.Background()).remove(kbId)
+}
+
+// bm25Cache 按知识库缓存倒排索引：切片正文总量超过 maxBytes 时淘汰最久未使用的索引，
+// 超过 ttl 未被使用的索引随下次读写释放
+type bm25Cache struct {
+	mu       sync.Mutex
+	maxBytes int
+	ttl      time.Duration
+	bytes    int
+	lru      *list.List // *bm25Entry，队首为最近使用
+	items    map[int64]*list.Element
+	now      func() time.Time
+}
+
+type bm25Entry struct {
+	kbId     int64
+	idx      *bm25Index
+	lastUsed time.Time
+}
+
+func newBM25Cache(maxBytes int, ttl time.Duration) *bm25Cache {
+	return &bm25Cache{
+		maxBytes: maxBytes,
+		ttl:      ttl,
+		lru:      list.New(),
+		items:    map[int64]*list.Element{},
+		now:      time.Now,
+	}
+}
+
+func (c *bm25Cache) get(kbId int64) *bm25Index {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.evictExpired()
+	el, ok := c.items[kbId]
+	if !ok {
+		return nil
+	}
+	entry := el.Value.(*bm25Entry)
+	entry.lastUsed = c.now()
+	c.lru.MoveToFront(el)
+	return entry.idx
+}
+
+// put 写入索引并按容量淘汰；单个索引超过容量时仍保留，直到有其他索引写入
+func (c *bm25Cache) put(kbId int64, idx *bm25Index) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[kbId]; ok {
+		c.removeElement(el)
+	}
+	c.items[kbId] = c.lru.PushFront(&bm25Entry{kbId: kbId, idx: idx, lastUsed: c.now()})
+	c.bytes += idx.size
+	c.evictExpired()
+	for c.bytes > c.maxBytes && c.lru.Len() > 1 {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+func (c *bm25Cache) remove(kbId int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.items[kbId]; ok {
+		c.removeElement(el)
+	}
+}
+
+// evictExpired 从最久未使用的一端释放超过 ttl 未使用的索引
+func (c *bm25Cache) evictExpired() {
+	if c.ttl <= 0 {
+		return
+	}
+	for el := c.lru.Back(); el != nil; el = c.lru.Back() {
+		if c.now().Sub(el.Value.(*bm25Entry).lastUsed) <= c.ttl {
+			return
+		}
+		c.removeElement(el)
+	}
+}
+
+func (c *bm25Cache) removeElement(el *list.Element) {
+	entry := c.lru.Remove(el).(*bm25Entry)
+	delete(c.items, entry.kbId)
+	c.bytes -= entry.idx.size
+}
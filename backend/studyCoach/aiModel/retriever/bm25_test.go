@@ -0,0 +1,134 @@
+package retriever
+
+import (
+	"backend/internal/model/entity"
+	"backend/studyCoach/common"
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestTokenize(t *testing.T) {
+	cases := []struct {
+		text string
+		want []string
+	}{
+		{"Hello, World 42", []string{"hello", "world", "42"}},
+		{"机器学习", []string{"机", "器", "机器", "学", "器学", "习", "学习"}},
+		{"Go语言", []string{"go", "语", "言", "语言"}},
+		{"", nil},
+	}
+	for _, c := range cases {
+		if got := Tokenize(c.text); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Tokenize(%q) = %q，期望 %q", c.text, got, c.want)
+		}
+	}
+}
+
+func TestBM25Rank(t *testing.T) {
+	idx := buildBM25Index("v1", []entity.KnowledgeChunks{
+		{ChunkId: "a", Content: "goroutine channel select"},
+		{ChunkId: "b", Content: "goroutine goroutine goroutine scheduler"},
+		{ChunkId: "c", Content: "python list comprehension"},
+		{ChunkId: "d", Content: "channel buffered channel"},
+	})
+	cases := []struct {
+		name    string
+		query   string
+		exclude map[string]bool
+		topK    int
+		want    []string
+	}{
+		{"词频高者靠前", "goroutine", nil, 0, []string{"b", "a"}},
+		{"多词累加", "goroutine channel", nil, 0, []string{"a", "b", "d"}},
+		{"未命中", "rust", nil, 0, []string{}},
+		{"排除禁用切片", "goroutine channel", map[string]bool{"a": true}, 0, []string{"b", "d"}},
+		{"截取 topK", "goroutine channel", nil, 1, []string{"a"}},
+	}
+	for _, c := range cases {
+		hits, _ := idx.rank(c.query, c.exclude, c.topK)
+		got := make([]string, 0, len(hits))
+		for _, i := range hits {
+			got = append(got, idx.chunks[i].ChunkId)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: rank(%q) = %v，期望 %v", c.name, c.query, got, c.want)
+		}
+	}
+}
+
+func TestBM25CacheEviction(t *testing.T) {
+	now := time.Unix(0, 0)
+	c := newBM25Cache(10, time.Minute)
+	c.now = func() time.Time { return now }
+	index := func(size int) *bm25Index { return &bm25Index{size: size} }
+
+	// 超出容量时淘汰最久未使用的知识库
+	c.put(1, index(4))
+	c.put(2, index(4))
+	c.get(1)
+	c.put(3, index(4))
+	if c.get(2) != nil || c.get(1) == nil || c.get(3) == nil {
+		t.Fatalf("应淘汰最久未使用的知识库 2，当前 %v", c.items)
+	}
+	if c.bytes != 8 {
+		t.Fatalf("容量统计 %d，期望 8", c.bytes)
+	}
+
+	// 空闲超过有效期的索引被释放
+	now = now.Add(30 * time.Second)
+	c.get(3)
+	now = now.Add(45 * time.Second)
+	if c.get(1) != nil || c.get(3) == nil {
+		t.Fatal("空闲超过有效期的知识库 1 应被释放，知识库 3 仍在有效期内")
+	}
+
+	// 删除知识库时释放
+	c.remove(3)
+	if c.get(3) != nil || c.bytes != 0 || c.lru.Len() != 0 {
+		t.Fatalf("删除后缓存应为空: bytes=%d len=%d", c.bytes, c.lru.Len())
+	}
+}
+
+func TestLocalBM25RebuildsOnVersionChange(t *testing.T) {
+	// 切片版本不变时复用缓存，变化后重新加载；知识库无切片时释放缓存
+	version, loads := "v1", 0
+	chunks := []entity.KnowledgeChunks{{ChunkId: "a", Content: "goroutine channel"}}
+	l := &localBM25{
+		loader: ChunkLoader{
+			Version: func(context.Context, common.Namespace) (string, error) { return version, nil },
+			Chunks: func(context.Context, common.Namespace) ([]entity.KnowledgeChunks, error) {
+				loads++
+				return chunks, nil
+			},
+		},
+		cache: newBM25Cache(1<<20, time.Hour),
+	}
+	req := &LexicalRequest{Query: "goroutine", Namespace: common.Namespace{KnowledgeBaseId: 7, UserUUID: "u"}, TopK: 5}
+	search := func() []*schema.Document {
+		docs, err := l.Search(context.Background(), req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return docs
+	}
+
+	if docs := search(); len(docs) != 1 || docs[0].ID != "a" {
+		t.Fatalf("检索结果 %v", docs)
+	}
+	search()
+	if loads != 1 {
+		t.Fatalf("版本未变时应复用缓存，加载 %d 次", loads)
+	}
+	version, chunks = "v2", []entity.KnowledgeChunks{{ChunkId: "b", Content: "goroutine scheduler"}}
+	if docs := search(); len(docs) != 1 || docs[0].ID != "b" || loads != 2 {
+		t.Fatalf("版本变化后应重建索引: %v, 加载 %d 次", docs, loads)
+	}
+	version, chunks = "v3", nil
+	if docs := search(); len(docs) != 0 || l.cache.get(7) != nil {
+		t.Fatal("知识库无切片时应释放缓存")
+	}
+}
@@ -0,0 +1,83 @@
+package retriever
+
+import (
+	"backend/studyCoach/common"
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/search"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/gogf/gf/v2/util/gconv"
+)
+
+// LexicalRequest 关键词检索参数
+type LexicalRequest struct {
+	Query         string
+	KnowledgeName string
+	Namespace     common.Namespace
+	ExcludeIDs    []string // 要排除的文档 ID（被禁用的切片）
+	TopK          int
+}
+
+// LexicalRetriever 关键词（BM25）检索，与向量检索结果做融合
+type LexicalRetriever interface {
+	Search(ctx context.Context, req *LexicalRequest) ([]*schema.Document, error)
+}
+
+// NewLexicalRetriever ES 直接使用 content 字段的 BM25 检索；Qdrant/Milvus 没有全文索引，
+// 回退到由 loader 提供切片的本地倒排索引
+func NewLexicalRetriever(ctx context.Context, conf *common.Config, loader ChunkLoader) LexicalRetriever {
+	if conf.UseES() {
+		return &esLexicalRetriever{client: conf.Client, index: conf.IndexName}
+	}
+	return &localBM25{loader: loader, cache: localBM25Cache(ctx)}
+}
+
+type esLexicalRetriever struct {
+	client *elasticsearch.Client
+	index  string
+}
+
+func (r *esLexicalRetriever) Search(ctx context.Context, req *LexicalRequest) ([]*schema.Document, error) {
+	if !req.Namespace.IsValid() {
+		return nil, fmt.Errorf("invalid retriever namespace (%s)", req.Namespace)
+	}
+	q := &types.Query{
+		Bool: &types.BoolQuery{
+			Must:   []types.Query{{Match: map[string]types.MatchQuery{common.FieldContent: {Query: req.Query}}}},
+			Filter: req.Namespace.ESQueries(),
+		},
+	}
+	if len(req.ExcludeIDs) > 0 {
+		q.Bool.MustNot = []types.Query{
+			{Terms: &types.TermsQuery{TermsQuery: map[string]types.TermsQueryField{"_id": req.ExcludeIDs}}},
+		}
+	}
+	resp, err := search.NewSearchFunc(r.client)().
+		Index(r.index).
+		Request(&search.Request{Query: q, Size: &req.TopK}).
+		Do(ctx)
+	if err != nil {
+		return nil, err
+	}
+	enabledKBIds, _ := getEnabledKBIds(ctx)
+	kbIdMap := make(map[int64]bool, len(enabledKBIds))
+	for _, id := range enabledKBIds {
+		kbIdMap[id] = true
+	}
+	docs := make([]*schema.Document, 0, len(resp.Hits.Hits))
+	for _, hit := range resp.Hits.Hits {
+		doc, err := EsHit2Document(ctx, hit)
+		if err != nil {
+			return nil, err
+		}
+		// 与向量检索一致，过滤已停用的知识库
+		if len(kbIdMap) > 0 && !kbIdMap[gconv.Int64(doc.MetaData[common.KnowledgeBaseId])] {
+			continue
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/cloudwego/eino/components/retriever"
 	"github.com/cloudwego/eino/schema"
 	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/util/gconv"
 
 	milvus2 "github.com/cloudwego/eino-ext/components/retriever/milvus2"
 	milvus2search "github.com/cloudwego/eino-ext/components/retriever/milvus2/search_mode"
@@ -88,7 +89,7 @@ func (w *milvusRetrieverWrapper) Retrieve(ctx context.Context, query string, opt
 
 	filtered := make([]*schema.Document, 0, len(docs))
 	for _, doc := range docs {
-		if kbId, ok := doc.MetaData[common.KnowledgeBaseId].(int64); ok && kbIdMap[kbId] {
+		if kbIdMap[gconv.Int64(doc.MetaData[common.KnowledgeBaseId])] {
 			filtered = append(filtered, doc)
 		}
 	}
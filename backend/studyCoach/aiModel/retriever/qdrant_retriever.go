@@ -123,12 +123,10 @@ func (r *QdrantRetriever) Retrieve(ctx context.Context, query string, opts ...re
 		if queryReq.Filter == nil {
 			queryReq.Filter = kbFilter
 		} else {
-			// 合并过滤条件
-			queryReq.Filter = &qdrant.Filter{
-				Must: append(queryReq.Filter.Must, &qdrant.Condition{
-					ConditionOneOf: &qdrant.Condition_Filter{Filter: kbFilter},
-				}),
-			}
+			// 合并过滤条件（保留调用方的 MustNot 等条件）
+			queryReq.Filter.Must = append(queryReq.Filter.Must, &qdrant.Condition{
+				ConditionOneOf: &qdrant.Condition_Filter{Filter: kbFilter},
+			})
 		}
 	}
 
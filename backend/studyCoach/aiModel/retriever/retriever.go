@@ -13,6 +13,7 @@ import (
 	"github.com/cloudwego/eino/components/retriever"
 	"github.com/cloudwego/eino/schema"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/gogf/gf/v2/util/gconv"
 )
 
 // newRetriever component initialization function of node 'Retriever1' in graph 'retriever'
@@ -106,6 +107,10 @@ func EsHit2Document(ctx context.Context, hit types.Hit) (doc *schema.Document, e
 			doc.MetaData[common.FieldExtra] = val.(string)
 		case common.KnowledgeName:
 			doc.MetaData[common.KnowledgeName] = val.(string)
+		case common.KnowledgeBaseId:
+			doc.MetaData[common.KnowledgeBaseId] = gconv.Int64(val)
+		case common.UserUUID:
+			doc.MetaData[common.UserUUID] = gconv.String(val)
 		case common.FieldCronID:
 			// cron_id 可能为 nil（手动索引时未设置），检索时忽略即可
 			continue
@@ -147,7 +152,7 @@ func (w *esRetrieverWrapper) Retrieve(ctx context.Context, query string, opts ..
 
 	filtered := make([]*schema.Document, 0, len(docs))
 	for _, doc := range docs {
-		if kbId, ok := doc.MetaData[common.KnowledgeBaseId].(int64); ok && kbIdMap[kbId] {
+		if kbIdMap[gconv.Int64(doc.MetaData[common.KnowledgeBaseId])] {
 			filtered = append(filtered, doc)
 		}
 	}
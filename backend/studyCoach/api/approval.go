@@ -0,0 +1,23 @@
+package api
+
+import (
+	v1 "backend/api/ai_chat/v1"
+	"backend/studyCoach/aiModel/eino_tools/studyplan"
+	"backend/studyCoach/common"
+	"context"
+	"fmt"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// ResumeChat 按用户对工具审批的答复恢复 Agent 并续写回复，续写结束后随 ctx 中的轮次保存
+func ResumeChat(ctx context.Context, rec *common.PendingApproval, decision *common.ApprovalDecision) (*schema.StreamReader[*schema.Message], error) {
+	// 工具按会话定位学习计划与工作目录
+	ctx = context.WithValue(ctx, studyplan.SessionIDContextKey{}, rec.SessionId)
+	streamData, err := common.ResumeAgent(ctx, rec, decision)
+	if err != nil {
+		return nil, fmt.Errorf("恢复生成失败：%w", err)
+	}
+	srs := streamData.Copy(2)
+	return chanOutput(ctx, srs, &v1.AiChatReq{ID: rec.SessionId}, nil)
+}
@@ -0,0 +1,229 @@
+package api
+
+import (
+	"backend/studyCoach/aiModel/CoachChat"
+	"backend/studyCoach/aiModel/grader"
+	"backend/studyCoach/common"
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	RetrieveModeStandard   = "standard"   // 仅按重排分数阈值过滤
+	RetrieveModeCorrective = "corrective" // 纠错式 RAG：逐条评估相关性，不足时重写查询或回退网络搜索
+)
+
+const (
+	RetrieveSourceKnowledge = "knowledge" // 首轮知识库检索
+	RetrieveSourceRewrite   = "rewrite"   // 纠错重写查询后的知识库检索
+	RetrieveSourceWeb       = "web"       // 网络搜索兜底
+	RetrieveSourceNone      = "none"      // 无可用上下文
+)
+
+const (
+	gradeConcurrency = 4    // 相关性评估并发数
+	webDocMaxRunes   = 3000 // 单条网络搜索结果截断长度
+)
+
+// RetrieveResult 检索结果及其来源路径
+type RetrieveResult struct {
+	Documents []*schema.Document
+	Source    string // RetrieveSource*
+}
+
+// Retrieve 按 req.Mode 检索：standard 等同 Retriever；corrective 在其基础上做相关性评估与兜底
+func (x *Rag) Retrieve(ctx context.Context, req *RetrieveReq) (*RetrieveResult, error) {
+	if req.Mode == RetrieveModeCorrective {
+		return x.correctiveRetrieve(ctx, req)
+	}
+	docs, err := x.Retriever(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	source := RetrieveSourceKnowledge
+	if len(docs) == 0 {
+		source = RetrieveSourceNone
+	}
+	return &RetrieveResult{Documents: docs, Source: source}, nil
+}
+
+// correctiveRetrieve 检索后由 grader 过滤不相关文档；相关文档不足以回答问题时重写查询再检索，
+// 仍不足则使用网络搜索结果补充
+func (x *Rag) correctiveRetrieve(ctx context.Context, req *RetrieveReq) (res *RetrieveResult, err error) {
+	cfg := g.Cfg()
+	maxRewrites := cfg.MustGet(ctx, "retriever.corrective.maxRewrites", 1).Int()
+	minRelevant := max(cfg.MustGet(ctx, "retriever.corrective.minRelevant", 1).Int(), 1)
+	webSearch := cfg.MustGet(ctx, "retriever.corrective.webSearch", true).Bool()
+
+	start := time.Now()
+	defer func() {
+		if err == nil {
+			g.Log().Infof(ctx, "CorrectiveRetrieve done in %v, knowledge=%s, source=%s, results=%d", time.Since(start), req.KnowledgeName, res.Source, len(res.Documents))
+		}
+	}()
+
+	cm, err := CoachChat.RewriteModel(ctx)
+	if err != nil {
+		return nil, err
+	}
+	gr := grader.NewGrader(cm)
+	var (
+		query    = req.Query
+		used     string
+		relevant []*schema.Document
+		source   = RetrieveSourceKnowledge
+	)
+	for round := 0; round <= maxRewrites; round++ {
+		if round > 0 {
+			if query, err = rewriteQuery(ctx, cm, used, req.Query, req.KnowledgeName); err != nil {
+				g.Log().Warningf(ctx, "CorrectiveRetrieve rewrite failed, err=%v", err)
+				break
+			}
+			source = RetrieveSourceRewrite
+		}
+		used += query + " "
+		r := req.copy()
+		r.Query = query
+		if round > 0 {
+			// 重写轮次直接按纠错重写后的查询检索，不再叠加 Retriever 自身的查询重写
+			r.Profile = withoutRewrite(req.Profile)
+		}
+		docs, err := x.Retriever(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+		relevant = mergeDocs(relevant, gradeDocs(ctx, gr, docs, req.Query))
+		g.Log().Infof(ctx, "CorrectiveRetrieve round=%d query=%q retrieved=%d relevant=%d", round, query, len(docs), len(relevant))
+		if enoughContext(ctx, gr, relevant, req.Query, minRelevant) {
+			return &RetrieveResult{Documents: topK(relevant, req.TopK), Source: source}, nil
+		}
+	}
+	err = nil
+
+	relevant = topK(relevant, req.TopK)
+	if webSearch {
+		if webDocs := webDocuments(ctx, req.Query); len(webDocs) > 0 {
+			return &RetrieveResult{Documents: append(relevant, webDocs...), Source: RetrieveSourceWeb}, nil
+		}
+	}
+	if len(relevant) == 0 {
+		source = RetrieveSourceNone
+	}
+	return &RetrieveResult{Documents: relevant, Source: source}, nil
+}
+
+// gradeDocs 并发评估每个文档与问题的相关性，保留相关文档；评估失败时保留该文档
+func gradeDocs(ctx context.Context, gr *grader.Grader, docs []*schema.Document, question string) []*schema.Document {
+	keep := make([]bool, len(docs))
+	sem := make(chan struct{}, gradeConcurrency)
+	wg := &sync.WaitGroup{}
+	for i, doc := range docs {
+		wg.Add(1)
+		go func(i int, doc *schema.Document) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			pass, err := gr.Related(ctx, doc, question)
+			if err != nil {
+				g.Log().Warningf(ctx, "grade doc failed, id=%s, err=%v", doc.ID, err)
+				pass = true
+			}
+			keep[i] = pass
+		}(i, doc)
+	}
+	wg.Wait()
+	out := make([]*schema.Document, 0, len(docs))
+	for i, doc := range docs {
+		if keep[i] {
+			out = append(out, doc)
+		}
+	}
+	return out
+}
+
+// enoughContext 相关文档数量达标且整体足以回答问题；整体评估失败时仅按数量判断
+func enoughContext(ctx context.Context, gr *grader.Grader, docs []*schema.Document, question string, minRelevant int) bool {
+	if len(docs) < minRelevant {
+		return false
+	}
+	pass, err := gr.Retriever(ctx, docs, question)
+	if err != nil {
+		g.Log().Warningf(ctx, "grade retrieval failed, err=%v", err)
+		return true
+	}
+	return pass
+}
+
+// rewriteQuery 基于已尝试过的查询再次改写，避免重复检索同一批不相关结果
+func rewriteQuery(ctx context.Context, cm model.BaseChatModel, used, question, knowledgeName string) (string, error) {
+	messages, err := CoachChat.GetOptimizedQueryMessages(used, question, knowledgeName)
+	if err != nil {
+		return "", err
+	}
+	rewriteCtx, cancel := context.WithTimeout(common.WithCallName(ctx, "QueryRewrite", components.ComponentOfChatModel), 30*time.Second)
+	defer cancel()
+	msg, err := cm.Generate(rewriteCtx, messages)
+	if err != nil {
+		return "", err
+	}
+	if msg.Content == "" {
+		return "", fmt.Errorf("重写结果为空")
+	}
+	return msg.Content, nil
+}
+
+// webDocuments 网络搜索兜底，结果转为文档（_source=web）
+func webDocuments(ctx context.Context, question string) []*schema.Document {
+	searchCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	sources := SearchConcurrentlyWithCache(searchCtx, question)
+	docs := make([]*schema.Document, 0, len(sources))
+	for i, content := range sources {
+		if r := []rune(content); len(r) > webDocMaxRunes {
+			content = string(r[:webDocMaxRunes])
+		}
+		docs = append(docs, &schema.Document{
+			ID:       fmt.Sprintf("web_%d", i+1),
+			Content:  content,
+			MetaData: map[string]any{common.Origin: common.OriginWeb},
+		})
+	}
+	return docs
+}
+
+// mergeDocs 按 ID 合并，同 ID 保留较高分
+func mergeDocs(base, more []*schema.Document) []*schema.Document {
+	idx := make(map[string]int, len(base))
+	for i, doc := range base {
+		idx[doc.ID] = i
+	}
+	for _, doc := range more {
+		if i, ok := idx[doc.ID]; ok {
+			if doc.Score() > base[i].Score() {
+				base[i] = doc
+			}
+			continue
+		}
+		idx[doc.ID] = len(base)
+		base = append(base, doc)
+	}
+	return base
+}
+
+func topK(docs []*schema.Document, k int) []*schema.Document {
+	sort.SliceStable(docs, func(i, j int) bool {
+		return docs[i].Score() > docs[j].Score()
+	})
+	if k > 0 && len(docs) > k {
+		docs = docs[:k]
+	}
+	return docs
+}
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"backend/internal/logic/knowledge"
+	"backend/studyCoach/aiModel/retriever"
+	"context"
+	"sort"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// defaultRRFK RRF 融合常数，取论文推荐值
+const defaultRRFK = 60
+
+// knowledgeChunkLoader Qdrant/Milvus 本地倒排索引的切片来源：知识库切片表
+var knowledgeChunkLoader = retriever.ChunkLoader{
+	Version: knowledge.GetChunksVersion,
+	Chunks:  knowledge.GetSearchableChunks,
+}
+
+// lexicalRetrieve 关键词检索，候选数与向量检索一致；失败时仅记录日志，退化为纯向量检索
+func (x *Rag) lexicalRetrieve(ctx context.Context, req *RetrieveReq) []*schema.Document {
+	if x.lexical == nil {
+		return nil
+	}
+	docs, err := x.lexical.Search(ctx, &retriever.LexicalRequest{
+		Query:         req.optQuery,
+		KnowledgeName: req.KnowledgeName,
+		Namespace:     req.Namespace,
+		ExcludeIDs:    req.excludeIDs,
+		TopK:          req.profile.candidateSize,
+	})
+	if err != nil {
+		g.Log().Warningf(ctx, "lexical retrieve failed, knowledge=%s, err=%v", req.KnowledgeName, err)
+		return nil
+	}
+	return docs
+}
+
+// rrfFuse 按 Reciprocal Rank Fusion 融合多路结果：score = Σ 1/(k + rank)，rank 从 1 开始。
+// 同一文档优先保留先出现列表中的版本；分数除以理论最大值 len(lists)/(k+1) 缩放到 0-1，便于关闭重排时参与阈值过滤
+func rrfFuse(k int, lists ...[]*schema.Document) []*schema.Document {
+	if k <= 0 {
+		k = defaultRRFK
+	}
+	var (
+		fused  []*schema.Document
+		scores = make(map[string]float64)
+		seen   = make(map[string]bool)
+	)
+	for _, list := range lists {
+		list = append([]*schema.Document(nil), list...)
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Score() > list[j].Score()
+		})
+		for rank, doc := range list {
+			scores[doc.ID] += 1 / float64(k+rank+1)
+			if !seen[doc.ID] {
+				seen[doc.ID] = true
+				fused = append(fused, doc)
+			}
+		}
+	}
+	maxScore := float64(len(lists)) / float64(k+1)
+	for _, doc := range fused {
+		doc.WithScore(scores[doc.ID] / maxScore)
+	}
+	sort.SliceStable(fused, func(i, j int) bool {
+		return fused[i].Score() > fused[j].Score()
+	})
+	return fused
+}
@@ -0,0 +1,76 @@
+package api
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func docs(scored ...any) []*schema.Document {
+	var list []*schema.Document
+	for i := 0; i < len(scored); i += 2 {
+		list = append(list, (&schema.Document{ID: scored[i].(string)}).WithScore(scored[i+1].(float64)))
+	}
+	return list
+}
+
+func ids(list []*schema.Document) []string {
+	out := make([]string, 0, len(list))
+	for _, d := range list {
+		out = append(out, d.ID)
+	}
+	return out
+}
+
+func TestRRFFuse(t *testing.T) {
+	cases := []struct {
+		name  string
+		lists [][]*schema.Document
+		want  []string
+	}{
+		{
+			name:  "两路都命中的文档排在前面",
+			lists: [][]*schema.Document{docs("a", 0.9, "b", 0.8, "c", 0.7), docs("c", 12.0, "a", 8.0, "d", 1.0)},
+			want:  []string{"a", "c", "b", "d"},
+		},
+		{
+			name:  "按各路自身分数排名，与输入顺序无关",
+			lists: [][]*schema.Document{docs("b", 0.1, "a", 0.9), docs("x", 1.0)},
+			want:  []string{"a", "x", "b"},
+		},
+		{
+			name:  "得分相同时保留先出现的顺序",
+			lists: [][]*schema.Document{docs("a", 0.9, "b", 0.8), docs("b", 5.0, "a", 4.0)},
+			want:  []string{"a", "b"},
+		},
+		{
+			name:  "单路结果保持原排名",
+			lists: [][]*schema.Document{docs("a", 0.3, "b", 0.2, "c", 0.1), nil},
+			want:  []string{"a", "b", "c"},
+		},
+	}
+	for _, c := range cases {
+		got := rrfFuse(60, c.lists...)
+		if !reflect.DeepEqual(ids(got), c.want) {
+			t.Errorf("%s: rrfFuse = %v，期望 %v", c.name, ids(got), c.want)
+		}
+	}
+}
+
+func TestRRFFuseScore(t *testing.T) {
+	// 两路均排第一时得分为理论最大值，缩放后为 1
+	got := rrfFuse(60, docs("a", 0.5, "b", 0.4), docs("a", 3.0))
+	if math.Abs(got[0].Score()-1) > 1e-9 {
+		t.Fatalf("两路第一的得分应为 1，实际 %v", got[0].Score())
+	}
+	want := (1.0 / 62) / (2.0 / 61)
+	if math.Abs(got[1].Score()-want) > 1e-9 {
+		t.Fatalf("b 的得分应为 %v，实际 %v", want, got[1].Score())
+	}
+	// k <= 0 时使用默认值
+	if !reflect.DeepEqual(ids(rrfFuse(0, docs("a", 1.0), docs("b", 1.0, "a", 0.5))), []string{"a", "b"}) {
+		t.Fatal("k<=0 时应按默认 k 融合")
+	}
+}
@@ -0,0 +1,92 @@
+package api
+
+import (
+	v1rag "backend/api/rag/v1"
+	"context"
+	"sync"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/components/embedding"
+	"github.com/cloudwego/eino/components/indexer"
+)
+
+// IndexProgressFunc 索引进度回调：stage 为 v1rag.JobStage*，done/total 为当前阶段的完成数与总数
+type IndexProgressFunc func(stage string, done, total int)
+
+// progressTracker 汇总 Eino 组件回调，换算为阶段进度
+type progressTracker struct {
+	mu     sync.Mutex
+	stage  string
+	done   int
+	total  int
+	report IndexProgressFunc
+}
+
+func (t *progressTracker) set(stage string, done, total int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.stage, t.done, t.total = stage, done, total
+	t.report(stage, done, total)
+}
+
+// add 在当前阶段累加完成数，阶段不匹配时忽略；返回累加后是否已满及阶段总数
+func (t *progressTracker) add(stage string, n int) (full bool, total int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.stage != stage {
+		return false, t.total
+	}
+	t.done = min(t.done+n, t.total)
+	t.report(t.stage, t.done, t.total)
+	return t.done >= t.total, t.total
+}
+
+// newIndexProgressHandler 索引管线进度：切分完成 -> 向量化（按 embedding 批次累计）-> 写入
+func newIndexProgressHandler(report IndexProgressFunc) callbacks.Handler {
+	t := &progressTracker{report: report}
+	return callbacks.NewHandlerBuilder().
+		OnStartFn(func(ctx context.Context, info *callbacks.RunInfo, input callbacks.CallbackInput) context.Context {
+			if info.Component == components.ComponentOfIndexer {
+				if in := indexer.ConvCallbackInput(input); in != nil {
+					t.set(v1rag.JobStageEmbed, 0, len(in.Docs))
+				}
+			}
+			return ctx
+		}).
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			switch info.Component {
+			case components.ComponentOfTransformer:
+				if out := document.ConvTransformerCallbackOutput(output); out != nil {
+					t.set(v1rag.JobStageSplit, len(out.Output), len(out.Output))
+				}
+			case components.ComponentOfEmbedding:
+				if out := embedding.ConvCallbackOutput(output); out != nil {
+					if full, total := t.add(v1rag.JobStageEmbed, len(out.Embeddings)); full {
+						t.set(v1rag.JobStageStore, 0, total)
+					}
+				}
+			case components.ComponentOfIndexer:
+				if out := indexer.ConvCallbackOutput(output); out != nil {
+					t.set(v1rag.JobStageStore, len(out.IDs), len(out.IDs))
+				}
+			}
+			return ctx
+		}).
+		Build()
+}
+
+// newQAProgressHandler QA 生成进度：每个 chunk 调用一次 QA 模型
+func newQAProgressHandler(report IndexProgressFunc, total int) callbacks.Handler {
+	t := &progressTracker{report: report}
+	t.set(v1rag.JobStageQA, 0, total)
+	return callbacks.NewHandlerBuilder().
+		OnEndFn(func(ctx context.Context, info *callbacks.RunInfo, output callbacks.CallbackOutput) context.Context {
+			if info.Component == components.ComponentOfChatModel {
+				t.add(v1rag.JobStageQA, 1)
+			}
+			return ctx
+		}).
+		Build()
+}
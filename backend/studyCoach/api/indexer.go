@@ -1,28 +1,37 @@
 package api
 
 import (
+	v1rag "backend/api/rag/v1"
 	"backend/internal/dao"
+	"backend/internal/logic/knowledge"
 	"backend/internal/model/entity"
 	"backend/studyCoach/common"
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/bytedance/sonic"
 	"github.com/cloudwego/eino/components/document"
+	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 	"github.com/gogf/gf/v2/frame/g"
 )
 
 type IndexReq struct {
-	URI           string // 文档地址，可以是文件路径（pdf，html，md等），也可以是网址
-	KnowledgeName string // 知识库名称
-	DocumentsId   int64  // 文档ID
-	FileName      string // 文件名
+	URI           string            // 文档地址，可以是文件路径（pdf，html，md等），也可以是网址
+	KnowledgeName string            // 知识库名称
+	Namespace     common.Namespace  // 知识库命名空间（知识库 ID + 用户 UUID）
+	DocumentsId   int64             // 文档ID
+	FileName      string            // 文件名
+	Progress      IndexProgressFunc // 可选，阶段进度回调
 }
 
 type IndexAsyncReq struct {
 	Docs          []*schema.Document
-	KnowledgeName string // 知识库名称
-	DocumentsId   int64  // 文档ID
+	KnowledgeName string            // 知识库名称
+	Namespace     common.Namespace  // 知识库命名空间（知识库 ID + 用户 UUID）
+	DocumentsId   int64             // 文档ID
+	Progress      IndexProgressFunc // 可选，QA 生成进度回调
 }
 
 // Index
@@ -33,11 +42,17 @@ func (x *Rag) Index(ctx context.Context, req *IndexReq) (ids []string, err error
 		URI: req.URI,
 	}
 	ctx = context.WithValue(ctx, common.KnowledgeName, req.KnowledgeName)
+	ctx = common.WithNamespace(ctx, req.Namespace)
 	ctx = context.WithValue(ctx, common.DocumentsIdKey, req.DocumentsId)
 	ctx = context.WithValue(ctx, "_file_name", req.FileName)
 	start := time.Now()
 	g.Log().Infof(ctx, "Index start: uri=%s knowledge=%s documentsId=%d (含 PDF 解析、切分、Embedding 批量写入，大文件或 chunk 多时会较慢)", req.URI, req.KnowledgeName, req.DocumentsId)
-	ids, err = x.idxer.Invoke(ctx, s)
+	var opts []compose.Option
+	if req.Progress != nil {
+		req.Progress(v1rag.JobStageSplit, 0, 0)
+		opts = append(opts, compose.WithCallbacks(newIndexProgressHandler(req.Progress)))
+	}
+	ids, err = x.idxer.Invoke(ctx, s, opts...)
 	if err != nil {
 		g.Log().Errorf(ctx, "Index idxer.Invoke failed after %v, err:\n%v", time.Since(start), err)
 		return
@@ -54,9 +69,14 @@ func (x *Rag) Index(ctx context.Context, req *IndexReq) (ids []string, err error
 // 通过 schema.Document 异步 生成QA&embedding
 func (x *Rag) IndexAsync(ctx context.Context, req *IndexAsyncReq) (ids []string, err error) {
 	ctx = context.WithValue(ctx, common.KnowledgeName, req.KnowledgeName)
+	ctx = common.WithNamespace(ctx, req.Namespace)
 	start := time.Now()
 	g.Log().Infof(ctx, "IndexAsync start: knowledge=%s documentsId=%d docs=%d", req.KnowledgeName, req.DocumentsId, len(req.Docs))
-	ids, err = x.idxerAsync.Invoke(ctx, req.Docs)
+	var opts []compose.Option
+	if req.Progress != nil {
+		opts = append(opts, compose.WithCallbacks(newQAProgressHandler(req.Progress, len(req.Docs))))
+	}
+	ids, err = x.idxerAsync.Invoke(ctx, req.Docs, opts...)
 	if err != nil {
 		g.Log().Errorf(ctx, "IndexAsync idxerAsync.Invoke failed after %v, err=%v", time.Since(start), err)
 		return
@@ -65,47 +85,105 @@ func (x *Rag) IndexAsync(ctx context.Context, req *IndexAsyncReq) (ids []string,
 	return
 }
 
-func (x *Rag) DeleteDocument(ctx context.Context, documentID string) error {
-	return x.conf.DeleteDocument(ctx, documentID)
+type ReindexChunkReq struct {
+	ChunkId       string           // 切片唯一 ID，即向量库中的文档 ID
+	Content       string           // 编辑后的切片正文
+	Ext           string           // knowledge_chunks.ext，保留文件名、标题等元数据
+	KnowledgeName string           // 知识库名称
+	Namespace     common.Namespace // 知识库命名空间（知识库 ID + 用户 UUID）
+	DocumentsId   int64            // 文档ID
+}
+
+// ReindexChunk 切片正文被编辑后重新生成 QA 与 content/qa 向量，并按原 chunk_id 覆盖写入向量库。
+// 处理进度记录在切片自身的 index_status 上，不改动文档状态，以免覆盖同一文档上的完整索引任务。
+func (x *Rag) ReindexChunk(ctx context.Context, req *ReindexChunkReq) error {
+	if req.ChunkId == "" {
+		return fmt.Errorf("chunk_id 不能为空")
+	}
+	return knowledge.TrackChunkIndex(ctx, req.ChunkId, func() error {
+		metaData := map[string]any{}
+		if req.Ext != "" {
+			if e := sonic.UnmarshalString(req.Ext, &metaData); e != nil {
+				g.Log().Warningf(ctx, "ReindexChunk: ext 解析失败 chunk_id=%s, err=%v", req.ChunkId, e)
+			}
+		}
+		metaData[common.KnowledgeName] = req.KnowledgeName
+		doc := &schema.Document{
+			ID:       req.ChunkId,
+			Content:  req.Content,
+			MetaData: metaData,
+		}
+		start := time.Now()
+		ictx := context.WithValue(ctx, common.KnowledgeName, req.KnowledgeName)
+		ictx = common.WithNamespace(ictx, req.Namespace)
+		// idxerAsync 会先生成 QA，再同时写入 content_vector 与 qa_content_vector；三种引擎均为 upsert 语义
+		if _, err := x.idxerAsync.Invoke(ictx, []*schema.Document{doc}); err != nil {
+			g.Log().Errorf(ctx, "ReindexChunk failed after %v, chunk_id=%s, err=%v", time.Since(start), req.ChunkId, err)
+			return err
+		}
+		g.Log().Infof(ctx, "ReindexChunk success in %v, chunk_id=%s documentsId=%d", time.Since(start), req.ChunkId, req.DocumentsId)
+		return nil
+	})
+}
+
+func (x *Rag) DeleteDocument(ctx context.Context, ns common.Namespace, documentID string) error {
+	return x.conf.DeleteDocument(ctx, ns, documentID)
 }
 
-// GenerateQAAsync 异步生成 QA 内容并更新向量库
-func (x *Rag) GenerateQAAsync(ctx context.Context, documentsId int64, knowledgeName string) error {
+type GenerateQAReq struct {
+	DocumentsId   int64             // 文档ID
+	KnowledgeName string            // 知识库名称
+	Namespace     common.Namespace  // 知识库命名空间（知识库 ID + 用户 UUID）
+	Progress      IndexProgressFunc // 可选，QA 生成进度回调
+}
+
+// GenerateQA 基于 MySQL 中的 chunks 生成 QA 内容并更新向量库（按 chunk_id upsert，可重复执行）
+func (x *Rag) GenerateQA(ctx context.Context, req *GenerateQAReq) error {
+	documentsId := req.DocumentsId
 	// 从 MySQL 获取该文档的所有 chunks
 	var chunks []*entity.KnowledgeChunks
 	err := dao.KnowledgeChunks.Ctx(ctx).Where("knowledge_doc_id", documentsId).Scan(&chunks)
 	if err != nil {
-		g.Log().Errorf(ctx, "GenerateQAAsync: 获取 chunks 失败 documentsId=%d, err=%v", documentsId, err)
+		g.Log().Errorf(ctx, "GenerateQA: 获取 chunks 失败 documentsId=%d, err=%v", documentsId, err)
 		return err
 	}
 
 	if len(chunks) == 0 {
-		g.Log().Infof(ctx, "GenerateQAAsync: 文档无 chunks, documentsId=%d", documentsId)
+		g.Log().Infof(ctx, "GenerateQA: 文档无 chunks, documentsId=%d", documentsId)
 		return nil
 	}
 
-	// 转换为 schema.Document
+	// 转换为 schema.Document；保留 ext 中的文件名、标题、页码等元数据，避免 upsert 覆盖丢失
 	docs := make([]*schema.Document, len(chunks))
 	for i, chunk := range chunks {
+		metaData := map[string]any{}
+		if chunk.Ext != "" {
+			if e := sonic.UnmarshalString(chunk.Ext, &metaData); e != nil {
+				g.Log().Warningf(ctx, "GenerateQA: ext 解析失败 chunk_id=%s, err=%v", chunk.ChunkId, e)
+			}
+		}
+		metaData[common.KnowledgeName] = req.KnowledgeName
 		docs[i] = &schema.Document{
 			ID:       chunk.ChunkId,
 			Content:  chunk.Content,
-			MetaData: map[string]any{common.KnowledgeName: knowledgeName},
+			MetaData: metaData,
 		}
 	}
 
 	// 调用异步索引生成 QA
-	ctx = context.WithValue(ctx, common.KnowledgeName, knowledgeName)
+	ctx = context.WithValue(ctx, common.KnowledgeName, req.KnowledgeName)
 	_, err = x.IndexAsync(ctx, &IndexAsyncReq{
 		Docs:          docs,
-		KnowledgeName: knowledgeName,
+		KnowledgeName: req.KnowledgeName,
+		Namespace:     req.Namespace,
 		DocumentsId:   documentsId,
+		Progress:      req.Progress,
 	})
 	if err != nil {
-		g.Log().Errorf(ctx, "GenerateQAAsync: IndexAsync 失败 documentsId=%d, err=%v", documentsId, err)
+		g.Log().Errorf(ctx, "GenerateQA: IndexAsync 失败 documentsId=%d, err=%v", documentsId, err)
 		return err
 	}
 
-	g.Log().Infof(ctx, "GenerateQAAsync: 完成 documentsId=%d, chunks=%d", documentsId, len(chunks))
+	g.Log().Infof(ctx, "GenerateQA: 完成 documentsId=%d, chunks=%d", documentsId, len(chunks))
 	return nil
 }
@@ -2,82 +2,60 @@ package api
 
 import (
 	v1 "backend/api/ai_chat/v1"
-	v1rag "backend/api/rag/v1"
-	"backend/internal/logic/knowledge"
+	chatLogic "backend/internal/logic/ai_chat"
 	"backend/studyCoach/aiModel/CoachChat"
 	"backend/studyCoach/aiModel/NormalChat"
+	"backend/studyCoach/aiModel/eino_tools/mcptool"
 	"backend/studyCoach/aiModel/eino_tools/studyplan"
 	"backend/studyCoach/aiModel/indexer"
 	"backend/studyCoach/aiModel/retriever"
 	"backend/studyCoach/common"
+	"backend/studyCoach/llm"
 	"context"
 	"fmt"
 	"io"
+	"net/http"
 	"strings"
 	"sync"
-	"time"
 
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/gogf/gf/v2/frame/g"
-	"github.com/wangle201210/chat-history/eino"
-)
-
-const (
-	//scoreThreshold = 1.05 // 设置一个很小的阈值
-	esTopK = 50
+	"github.com/gogf/gf/v2/os/gctx"
 )
 
 var client *elasticsearch.Client
 var esConf *common.Config
-var eh *eino.History
-
-// coachGraph 按 isNetwork 缓存已编译的 CoachChat 图，避免每次请求都重建
-type coachGraphEntry struct {
-	graph compose.Runnable[map[string]any, *schema.Message]
-	conf  *common.Config // 记录创建时的 conf，用于检测配置变更
-}
 
+// coachGraphCache 按 isNetwork 缓存已编译的 CoachChat 图，避免每次请求都重建；
+// 图中模型每次调用时按 llm.routes 路由，模型配置变更无需重建；MCP 工具集变化（如服务恢复连接）时重建以挂载新工具
 var (
-	coachGraphCache [2]*coachGraphEntry // [0]=no-network, [1]=network
-	coachGraphMu    sync.RWMutex
+	coachGraphCache   [2]compose.Runnable[map[string]any, *schema.Message] // [0]=no-network, [1]=network
+	coachGraphVersion [2]int64                                             // 构建时的 mcptool.ToolsVersion
+	coachGraphMu      sync.Mutex
 )
 
-// getCoachGraph 返回缓存的 CoachChat 图；conf 变更时自动重建
-func getCoachGraph(ctx context.Context, conf *common.Config, isNetwork bool) (compose.Runnable[map[string]any, *schema.Message], error) {
+// getCoachGraph 返回缓存的 CoachChat 图，未命中时构建
+func getCoachGraph(ctx context.Context, isNetwork bool) (compose.Runnable[map[string]any, *schema.Message], error) {
 	idx := 0
 	if isNetwork {
 		idx = 1
 	}
-
-	coachGraphMu.RLock()
-	entry := coachGraphCache[idx]
-	coachGraphMu.RUnlock()
-
-	// 命中缓存且 conf 未变更（比较 APIKey+ChatModel 即可）
-	if entry != nil && entry.conf.APIKey == conf.APIKey && entry.conf.ChatModel == conf.ChatModel {
-		return entry.graph, nil
-	}
-
-	// 未命中或 conf 变更，重建
 	coachGraphMu.Lock()
 	defer coachGraphMu.Unlock()
-
-	// Double-check
-	entry = coachGraphCache[idx]
-	if entry != nil && entry.conf.APIKey == conf.APIKey && entry.conf.ChatModel == conf.ChatModel {
-		return entry.graph, nil
+	version := mcptool.ToolsVersion()
+	if graph := coachGraphCache[idx]; graph != nil && coachGraphVersion[idx] == version {
+		return graph, nil
 	}
-
 	buildCtx := context.WithValue(context.Background(), "isNetwork", isNetwork)
-	graph, err := CoachChat.BuildstudyCoachFor(buildCtx, conf)
+	graph, err := CoachChat.BuildstudyCoachFor(buildCtx)
 	if err != nil {
 		return nil, err
 	}
-	coachGraphCache[idx] = &coachGraphEntry{graph: graph, conf: conf}
-	g.Log().Infof(ctx, "[getCoachGraph] CoachChat 图已重建 isNetwork=%v", isNetwork)
+	coachGraphCache[idx], coachGraphVersion[idx] = graph, version
+	g.Log().Infof(ctx, "[getCoachGraph] CoachChat 图已构建 isNetwork=%v mcpToolsVersion=%d", isNetwork, version)
 	return graph, nil
 }
 
@@ -86,17 +64,17 @@ type Rag struct {
 	idxerAsync compose.Runnable[[]*schema.Document, []string]
 	rtrvr      compose.Runnable[string, []*schema.Document]
 	qaRtrvr    compose.Runnable[string, []*schema.Document]
-	client     *elasticsearch.Client // ES 客户端，仅 UseES 时非空
+	lexical    retriever.LexicalRetriever // 关键词检索，混合检索时与向量结果融合
+	client     *elasticsearch.Client      // ES 客户端，仅 UseES 时非空
 	cm         model.BaseChatModel
 	conf       *common.Config
 }
 type StreamType struct {
-	Conf          *common.Config
 	Question      string
 	Knowledge     []*schema.Document
 	Id            string
-	Eh            *eino.History
 	IsStudyMode   bool
+	Model         string           // 生成回答的模型，用于选取对话记忆预算
 	UploadedFiles []string         // 已上传到会话工作目录的文件名，供 prompt 注入
 	MultiContent  []v1.MessagePart // 多模态内容
 }
@@ -112,12 +90,6 @@ func init() {
 	if esConf.UseES() {
 		client = esConf.Client
 	}
-	dbConf, err := g.Cfg().Get(ctx, "db.mysql")
-	if err != nil || dbConf.String() == "" {
-		g.Log().Warningf(ctx, "config missing: db.mysql, err=%v", err)
-		return
-	}
-	eh = eino.NewEinoHistory(dbConf.String())
 }
 
 // buildMultiContentMessage 构建多模态消息
@@ -154,12 +126,6 @@ func ChatAiModel(ctx context.Context, req *v1.AiChatReq) (*schema.StreamReader[*
 	g.Log().Infof(ctx, "[ChatAiModel] 开始处理请求 - ID: %s, 网络搜索: %v, 知识库: %s", req.ID, req.IsNetwork, req.KnowledgeName)
 
 	g.Log().Infof(ctx, "用户内容：%s", req.Question)
-	//硅基流动
-	conf := &common.Config{
-		APIKey:    g.Cfg().MustGet(ctx, "siliconflow.apiKey").String(),
-		BaseURL:   g.Cfg().MustGet(ctx, "siliconflow.baseURL").String(),
-		ChatModel: g.Cfg().MustGet(ctx, "siliconflow.model").String(),
-	}
 	// 初始化 RAG 组件，避免后续调用空指针
 	rag, err := NewRagChat(ctx, esConf)
 	if err != nil {
@@ -173,6 +139,8 @@ func ChatAiModel(ctx context.Context, req *v1.AiChatReq) (*schema.StreamReader[*
 			TopK:          req.TopK,
 			Score:         req.Score,
 			KnowledgeName: req.KnowledgeName,
+			Namespace:     common.NamespaceFromContext(ctx),
+			Profile:       req.RetrievalProfile,
 		})
 		if err != nil {
 			return nil, nil, err
@@ -185,12 +153,11 @@ func ChatAiModel(ctx context.Context, req *v1.AiChatReq) (*schema.StreamReader[*
 	ctxWithNetwork := context.WithValue(ctx, "isNetwork", req.IsNetwork)
 	ctxWithNetwork = context.WithValue(ctxWithNetwork, common.IsDeepThinking, "true")
 	streamType := StreamType{
-		Conf:          conf,
 		Question:      req.Question,
 		Knowledge:     documents,
 		Id:            req.ID,
-		Eh:            eh,
 		IsStudyMode:   req.IsStudyMode,
+		Model:         llm.PrimaryModel(ctx, llm.RoleCompanion),
 		UploadedFiles: req.UploadedFiles,
 		MultiContent:  req.GetMultiContent(),
 	}
@@ -203,7 +170,7 @@ func ChatAiModel(ctx context.Context, req *v1.AiChatReq) (*schema.StreamReader[*
 		return nil, nil, fmt.Errorf("生成答案失败：%w", err)
 	}
 	srs := streamData.Copy(2)
-	sr, err := chanOutput(ctx, srs, req, eh)
+	sr, err := chanOutput(ctx, srs, req, documents)
 	return sr, documents, err
 }
 
@@ -224,6 +191,8 @@ func ChatNormalModel(ctx context.Context, req *v1.AiChatReq) (*schema.StreamRead
 			TopK:          req.TopK,
 			Score:         req.Score,
 			KnowledgeName: req.KnowledgeName,
+			Namespace:     common.NamespaceFromContext(ctx),
+			Profile:       req.RetrievalProfile,
 		})
 		if err != nil {
 			return nil, nil, err
@@ -237,8 +206,8 @@ func ChatNormalModel(ctx context.Context, req *v1.AiChatReq) (*schema.StreamRead
 		Question:      req.Question,
 		Knowledge:     documents,
 		Id:            req.ID,
-		Eh:            eh,
 		IsStudyMode:   req.IsStudyMode,
+		Model:         llm.PrimaryModel(ctx, llm.RoleChat),
 		UploadedFiles: req.UploadedFiles,
 		MultiContent:  req.GetMultiContent(),
 	}
@@ -252,17 +221,32 @@ func ChatNormalModel(ctx context.Context, req *v1.AiChatReq) (*schema.StreamRead
 		return nil, nil, fmt.Errorf("生成答案失败：%w", err)
 	}
 	srs := streamData.Copy(2)
-	sr, err := chanOutput(ctx, srs, req, eh)
+	sr, err := chanOutput(ctx, srs, req, documents)
 	return sr, documents, err
 }
 
 // 流式输出
 func stream(ctx context.Context, streamType *StreamType, output map[string]interface{}) (res *schema.StreamReader[*schema.Message], err error) {
-	history, err := streamType.Eh.GetHistory(streamType.Id, 30)
+	// 对话记忆：按模型预算截取的最近消息 + 较早对话的滚动摘要
+	var memory *chatLogic.Memory
+	// 会话相关工具（工作目录、学习计划）使用的会话 ID，调用方自带历史时为空
+	sessionId := streamType.Id
+	if h, ok := common.ChatHistoryFromContext(ctx); ok {
+		// 调用方自带历史（OpenAI 兼容接口），不读取会话，也不创建工作目录与计划文件：该 ID 没有所属用户，无法查看或清理
+		memory = &chatLogic.Memory{History: h}
+		sessionId = ""
+	} else if turn := chatLogic.TurnFromContext(ctx); turn != nil {
+		memory, err = chatLogic.GetChat().TurnMemory(ctx, turn, streamType.Model)
+	} else {
+		memory, err = chatLogic.GetChat().LoadMemory(ctx, streamType.Id, "", streamType.Model)
+	}
 	if err != nil {
 		g.Log().Errorf(ctx, "获取历史记录失败: %v", err)
 		return nil, fmt.Errorf("get history failed: %v", err)
 	}
+	history := memory.History
+	// 用户设置为英文时追加回复语言要求，与摘要一起放在系统提示之后
+	summary := append(common.SummaryMessages(memory.Summary), common.LanguageMessages(common.LanguageFromContext(ctx))...)
 
 	// 如果有多模态内容，添加到历史末尾
 	if streamType.MultiContent != nil && len(streamType.MultiContent) > 0 {
@@ -270,12 +254,12 @@ func stream(ctx context.Context, streamType *StreamType, output map[string]inter
 		history = append(history, userMsg)
 	}
 
-	g.Log().Infof(ctx, "历史记录数量: %d", len(history))
+	g.Log().Infof(ctx, "历史记录数量: %d, 摘要: %v", len(history), memory.Summary != "")
 	var modelStream compose.Runnable[map[string]any, *schema.Message]
 	//判断是否开启联网
 	if streamType.IsStudyMode == false {
 		ctx = context.WithValue(ctx, "chat_history", history)
-		ctx = context.WithValue(ctx, studyplan.SessionIDContextKey{}, streamType.Id)
+		ctx = context.WithValue(ctx, studyplan.SessionIDContextKey{}, sessionId)
 		modelStream, err = NormalChat.BuildNormalChat(ctx)
 		if err != nil {
 			g.Log().Errorf(ctx, "构建模型失败: %v", err)
@@ -283,12 +267,13 @@ func stream(ctx context.Context, streamType *StreamType, output map[string]inter
 		}
 	} else {
 		ctx = context.WithValue(ctx, "chat_history", history)
+		ctx = context.WithValue(ctx, "summary", summary)
 		ctx = context.WithValue(ctx, "question", streamType.Question)
-		ctx = context.WithValue(ctx, "knowledge", streamType.Knowledge)
-		ctx = context.WithValue(ctx, studyplan.SessionIDContextKey{}, streamType.Id)
+		ctx = context.WithValue(ctx, "knowledge", common.FormatKnowledge(streamType.Knowledge))
+		ctx = context.WithValue(ctx, studyplan.SessionIDContextKey{}, sessionId)
 		isNetwork := ctx.Value("isNetwork")
 		networkFlag, _ := isNetwork.(bool)
-		modelStream, err = getCoachGraph(ctx, streamType.Conf, networkFlag)
+		modelStream, err = getCoachGraph(ctx, networkFlag)
 		if err != nil {
 			g.Log().Errorf(ctx, "构建模型失败: %v", err)
 			return nil, fmt.Errorf("构建模型失败: %v", err)
@@ -298,91 +283,67 @@ func stream(ctx context.Context, streamType *StreamType, output map[string]inter
 	// 构建结构化的输入
 	output["question"] = streamType.Question // 保持兼容性
 	output["chat_history"] = history
-	output["knowledge"] = streamType.Knowledge
+	output["summary"] = summary
+	// 参考资料编号注入，模型以 [n] 标注引用
+	output["knowledge"] = common.FormatKnowledge(streamType.Knowledge)
 	output["current_time"] = common.GetCurrentTimeString() // 每次请求注入当前时间，供提示词使用
 	if len(streamType.UploadedFiles) > 0 {
 		output["uploaded_files"] = "已上传文件（工作目录内，可用 read_file 读取）：" + strings.Join(streamType.UploadedFiles, "、")
 	} else {
 		output["uploaded_files"] = ""
 	}
-	// 添加重试机制，最多重试3次，但只重试Stream调用
-	maxRetries := 3
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		res, err = modelStream.Stream(ctx, output)
-		if err != nil {
-			mode := "NormalChat"
-			if streamType.IsStudyMode {
-				mode = "CoachChat"
-			}
-			g.Log().Errorf(ctx, "流式生成失败 (模式=%s, 尝试 %d/%d): %v", mode, attempt+1, maxRetries, err)
-
-			// 检查上下文是否已取消，避免无意义的重试
-			if ctx.Err() != nil {
-				return nil, ctx.Err()
-			}
-
-			// 如果是最后一次尝试，直接返回错误
-			if attempt == maxRetries-1 {
-				errMsg := fmt.Sprintf("llm generate failed after %d attempts: %v", maxRetries, err)
-				// 401/403 或 RequestError：API Key 无效/过期或模型无权限
-				if strings.Contains(err.Error(), "401") || strings.Contains(err.Error(), "403") || strings.Contains(err.Error(), "RequestError") {
-					errMsg += " (提示: 401/403 通常表示 API Key 无效/过期或该模型无访问权限，请检查 config.yaml 中的 siliconflow/qa/Analysis/ark 等配置)"
-				}
-				// 当 API 返回纯文本错误（如 "Unauthorized"）而非 JSON 时，SDK 会报 unmarshal 错误，给出友好说明
-				if strings.Contains(err.Error(), "cannot unmarshal string into Go value of type model.ErrorResponse") {
-					errMsg = "llm generate failed: API 返回了非 JSON 格式的错误响应（可能是 401 未授权）。请检查 config.yaml 中的 API Key 配置是否正确（siliconflow/qa/Analysis/ark 等）"
-				}
-				return nil, fmt.Errorf("%s", errMsg)
-			}
-
-			// 使用指数退避策略，但限制最大延迟时间
-			backoffDelay := time.Duration(1<<uint(attempt)) * time.Second
-			if backoffDelay > 5*time.Second {
-				backoffDelay = 5 * time.Second
-			}
-
-			g.Log().Errorf(ctx, "等待 %v 后重试...", backoffDelay)
-			select {
-			case <-time.After(backoffDelay):
-				// 继续重试
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			}
-			continue
+	// 首选与备用模型之间的故障切换由 llm 路由完成，这里不再重试
+	res, err = modelStream.Stream(ctx, output)
+	if err != nil {
+		mode := "NormalChat"
+		if streamType.IsStudyMode {
+			mode = "CoachChat"
 		}
-
-		g.Log().Infof(ctx, "流式生成成功 (尝试 %d/%d)", attempt+1, maxRetries)
-		return res, nil
+		g.Log().Errorf(ctx, "流式生成失败 (模式=%s): %v", mode, err)
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if code := llm.StatusCode(err); code == http.StatusUnauthorized || code == http.StatusForbidden {
+			return nil, fmt.Errorf("llm generate failed: %w (提示: 401/403 通常表示 API Key 无效/过期或该模型无访问权限，请检查 config.yaml 中 llm.providers 的配置)", err)
+		}
+		return nil, fmt.Errorf("llm generate failed: %w", err)
 	}
-
-	return nil, fmt.Errorf("流式生成失败，已重试%d次", maxRetries)
+	return res, nil
 }
 
-// 输出管道
-func chanOutput(ctx context.Context, srs []*schema.StreamReader[*schema.Message], req *v1.AiChatReq, eh *eino.History) (*schema.StreamReader[*schema.Message], error) {
+// 输出管道：流结束后将本轮问答写入会话；轮次被取消时不保存不完整的回复
+func chanOutput(ctx context.Context, srs []*schema.StreamReader[*schema.Message], req *v1.AiChatReq, docs []*schema.Document) (*schema.StreamReader[*schema.Message], error) {
+	turn := chatLogic.TurnFromContext(ctx)
 	go func() {
 		defer srs[1].Close()
+		if turn != nil {
+			defer chatLogic.GetChat().FinishTurn(turn)
+		}
 		fullMsgs := make([]*schema.Message, 0)
+		// 轮次取消时上游模型流会返回错误并结束循环
 		for {
-			// 监听上下文取消，防止泄露
-			select {
-			case <-ctx.Done():
-				g.Log().Infof(ctx, "上下文取消 - ID: %s, 错误: %v", req.ID, ctx.Err())
-				return
-			default:
-				// 继续执行
-			}
 			chunk, err := srs[1].Recv()
 			if err == io.EOF {
+				if ctx.Err() != nil {
+					g.Log().Infof(ctx, "轮次已取消，不保存回复 - ID: %s", req.ID)
+					return
+				}
 				// 流结束，保存完整消息
 				fullMsg, err := schema.ConcatMessages(fullMsgs)
 				if err != nil {
 					fmt.Printf("error concatenating messages: %v\n", err)
 					return
 				}
-				err = eh.SaveMessage(fullMsg, req.ID)
-				if err != nil {
-					fmt.Printf("save assistant message err: %v\n", err)
+				if turn == nil {
+					// 未登记轮次（如 OpenAI 兼容接口）的回复由调用方保存
+					return
+				}
+				// 与 SSE 输出一致：删除指向不存在来源的引用标记
+				var citations []common.Citation
+				if len(docs) > 0 {
+					fullMsg.Content, citations = common.CleanCitations(fullMsg.Content, docs)
+				}
+				if err = chatLogic.GetChat().SaveTurn(gctx.NeverDone(ctx), turn, fullMsg, citations); err != nil {
 					return
 				}
 				GetMsg(fullMsg)
@@ -415,15 +376,8 @@ func NewRagChat(ctx context.Context, conf *common.Config) (*Rag, error) {
 	if err != nil {
 		return nil, err
 	}
-	onIndexed := func(ctx context.Context, docs []*schema.Document, documentsId int64) {
-		_, err := buildIndexAsync.Invoke(ctx, docs)
-		if err != nil {
-			g.Log().Errorf(ctx, "IndexAsync (QA) failed, documentsId=%d, err=%v", documentsId, err)
-			return
-		}
-		knowledge.UpdateDocumentsStatus(ctx, documentsId, int(v1rag.StatusActive))
-	}
-	buildIndex, err := indexer.BuildIndexer(ctx, conf, onIndexed)
+	// QA 生成与文档状态由索引任务（internal/logic/indexjob）的 QA 阶段负责，可持久化重试
+	buildIndex, err := indexer.BuildIndexer(ctx, conf, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -446,6 +400,7 @@ func NewRagChat(ctx context.Context, conf *common.Config) (*Rag, error) {
 		idxerAsync: buildIndexAsync,
 		rtrvr:      buildRetriever,
 		qaRtrvr:    qaRetriever,
+		lexical:    retriever.NewLexicalRetriever(ctx, conf, knowledgeChunkLoader),
 		client:     conf.Client,
 		cm:         cm,
 		conf:       conf,
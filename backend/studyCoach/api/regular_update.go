@@ -3,6 +3,7 @@ package api
 import (
 	"backend/internal/controller/ws"
 	"backend/internal/dao"
+	"backend/internal/logic/knowledge"
 	"backend/internal/model/do"
 	"backend/internal/model/entity"
 	"backend/studyCoach/aiModel/RegularUpdate"
@@ -52,8 +53,17 @@ func ExecuteRegularUpdate(ctx context.Context, task *entity.KnowledgeBaseCronSch
 	log.Printf("[Cron] 开始执行任务: %s (ID: %d)", task.CronName, task.Id)
 	insertCronTaskLog(ctx, task, "INFO", fmt.Sprintf("开始执行 id=%d name=%s kb=%s", task.Id, task.CronName, task.KnowledgeBaseName))
 
+	// 定时任务没有用户上下文，按知识库名称解析命名空间
+	ns, err := knowledge.GetNamespaceByName(ctx, task.KnowledgeBaseName)
+	if err != nil {
+		log.Printf("[Cron] 任务 %s 解析知识库失败: %v", task.CronName, err)
+		insertCronTaskLog(ctx, task, "ERROR", fmt.Sprintf("解析知识库失败: %v", err))
+		ws.BroadcastCronCompleteGlobal(task.Id, task.CronName, false)
+		return err
+	}
+
 	// 调用 AI 模型获取更新内容（传入完整 task 和 rag，支持知识库预检索）
-	msg, err := regularUpdateModel(ctx, task, rag)
+	msg, err := regularUpdateModel(ctx, task, rag, ns)
 	if err != nil {
 		log.Printf("[Cron] 任务 %s AI生成失败: %v", task.CronName, err)
 		insertCronTaskLog(ctx, task, "ERROR", fmt.Sprintf("AI 生成失败: %v", err))
@@ -66,7 +76,7 @@ func ExecuteRegularUpdate(ctx context.Context, task *entity.KnowledgeBaseCronSch
 		// 全量更新：清除旧数据
 		cronID := fmt.Sprintf("%d", task.Id)
 		log.Printf("[Cron] 全量更新，正在清理旧数据: CronID=%s", cronID)
-		if err := rag.conf.DeleteDocumentsByCronID(ctx, cronID); err != nil {
+		if err := rag.conf.DeleteDocumentsByCronID(ctx, ns, cronID); err != nil {
 			log.Printf("[Cron] 清理旧数据失败(可能不存在): %v", err)
 			// 继续执行，不因删除失败而终止
 		}
@@ -88,6 +98,7 @@ func ExecuteRegularUpdate(ctx context.Context, task *entity.KnowledgeBaseCronSch
 	req := &IndexAsyncReq{
 		Docs:          []*schema.Document{doc},
 		KnowledgeName: task.KnowledgeBaseName,
+		Namespace:     ns,
 		DocumentsId:   int64(task.Id),
 	}
 
@@ -132,7 +143,7 @@ func ExecuteRegularUpdate(ctx context.Context, task *entity.KnowledgeBaseCronSch
 	return nil
 }
 
-func regularUpdateModel(ctx context.Context, task *entity.KnowledgeBaseCronSchedule, rag *Rag) (*schema.Message, error) {
+func regularUpdateModel(ctx context.Context, task *entity.KnowledgeBaseCronSchedule, rag *Rag, ns common.Namespace) (*schema.Message, error) {
 	log.Printf("[RegularUpdateModel] 开始处理请求")
 	input := task.CronName
 	var sources []string
@@ -147,6 +158,7 @@ func regularUpdateModel(ctx context.Context, task *entity.KnowledgeBaseCronSched
 			TopK:          5,
 			Score:         1.3,
 			KnowledgeName: task.KnowledgeBaseName,
+			Namespace:     ns,
 		})
 		kbCancel()
 		if kbErr != nil {
@@ -170,29 +182,9 @@ func regularUpdateModel(ctx context.Context, task *entity.KnowledgeBaseCronSched
 	sources = append(sources, SearchConcurrentlyWithCache(searchCtx, input)...)
 	sources = append(sources, input)
 
-	cfg := g.Cfg()
-	apiKey, err := cfg.Get(ctx, "cron.apiKey")
-	if err != nil || apiKey.String() == "" {
-		return nil, fmt.Errorf("config missing: cron.apiKey")
-	}
-	baseURL, err := cfg.Get(ctx, "cron.baseURL")
-	if err != nil || baseURL.String() == "" {
-		return nil, fmt.Errorf("config missing: cron.baseURL")
-	}
-	chatModel, err := cfg.Get(ctx, "cron.model")
-	if err != nil || chatModel.String() == "" {
-		return nil, fmt.Errorf("config missing: cron.model")
-	}
-	conf := &common.Config{
-		APIKey:    apiKey.String(),
-		BaseURL:   baseURL.String(),
-		ChatModel: chatModel.String(),
-		IndexName: "NetworkUpdate",
-	}
-
 	maxRetries := 3
 	for attempt := 0; attempt < maxRetries; attempt++ {
-		model, err := RegularUpdate.BuildRegularUpdate(ctx, conf)
+		model, err := RegularUpdate.BuildRegularUpdate(ctx)
 		if err != nil {
 			log.Printf("构建模型失败 (尝试 %d/%d): %v", attempt+1, maxRetries, err)
 			if attempt == maxRetries {
@@ -0,0 +1,136 @@
+package api
+
+import (
+	v1rag "backend/api/rag/v1"
+	"backend/internal/logic/knowledge"
+	"context"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	defaultRewriteRounds = 3  // 默认查询重写轮数
+	defaultCandidateSize = 50 // 默认每次向量检索的候选数
+)
+
+// retrievalProfile 生效的检索配置，按 默认 < 知识库 < 请求 逐级覆盖
+type retrievalProfile struct {
+	rewriteRounds  int
+	fields         string
+	rerank         bool
+	candidateSize  int
+	scoreNormalize string
+	hybrid         bool
+}
+
+func defaultRetrievalProfile() retrievalProfile {
+	return retrievalProfile{
+		rewriteRounds:  defaultRewriteRounds,
+		fields:         v1rag.RetrievalFieldBoth,
+		rerank:         true,
+		candidateSize:  defaultCandidateSize,
+		scoreNormalize: v1rag.ScoreNormalizeShift,
+	}
+}
+
+// apply 用 o 中已设置的字段覆盖当前配置
+func (p *retrievalProfile) apply(o *v1rag.RetrievalProfile) {
+	if o == nil {
+		return
+	}
+	if o.RewriteRounds != nil && *o.RewriteRounds >= 0 {
+		p.rewriteRounds = *o.RewriteRounds
+	}
+	if o.Fields != "" {
+		p.fields = o.Fields
+	}
+	if o.Rerank != nil {
+		p.rerank = *o.Rerank
+	}
+	if o.CandidateSize > 0 {
+		p.candidateSize = o.CandidateSize
+	}
+	if o.ScoreNormalize != "" {
+		p.scoreNormalize = o.ScoreNormalize
+	}
+	if o.Hybrid != nil {
+		p.hybrid = *o.Hybrid
+	}
+}
+
+// withoutRewrite 复制覆盖项并将查询重写轮数设为 0：查询已在外部重写时，按该查询直接检索，
+// 避免 Retriever 再对其做多轮重写
+func withoutRewrite(o *v1rag.RetrievalProfile) *v1rag.RetrievalProfile {
+	out := v1rag.RetrievalProfile{}
+	if o != nil {
+		out = *o
+	}
+	rounds := 0
+	out.RewriteRounds = &rounds
+	return &out
+}
+
+// useContent 是否检索正文向量
+func (p retrievalProfile) useContent() bool {
+	return p.fields != v1rag.RetrievalFieldQA
+}
+
+// useQA 是否检索 QA 向量
+func (p retrievalProfile) useQA() bool {
+	return p.fields != v1rag.RetrievalFieldContent
+}
+
+// resolveRetrievalProfile 合并知识库保存的配置与请求覆盖项
+func resolveRetrievalProfile(ctx context.Context, req *RetrieveReq) retrievalProfile {
+	p := defaultRetrievalProfile()
+	if req.Namespace.KnowledgeBaseId > 0 {
+		kbProfile, err := knowledge.GetRetrievalProfile(ctx, req.Namespace.KnowledgeBaseId)
+		if err != nil {
+			g.Log().Warningf(ctx, "获取知识库检索配置失败，使用默认配置, kb_id=%d, err=%v", req.Namespace.KnowledgeBaseId, err)
+		}
+		p.apply(kbProfile)
+	}
+	p.apply(req.Profile)
+	return p
+}
+
+// EffectiveRetrievalProfile 返回默认、知识库与请求覆盖项合并后实际生效的检索配置，用于评测记录
+func EffectiveRetrievalProfile(ctx context.Context, req *RetrieveReq) *v1rag.RetrievalProfile {
+	p := resolveRetrievalProfile(ctx, req)
+	return &v1rag.RetrievalProfile{
+		RewriteRounds:  &p.rewriteRounds,
+		Fields:         p.fields,
+		Rerank:         &p.rerank,
+		CandidateSize:  p.candidateSize,
+		ScoreNormalize: p.scoreNormalize,
+		Hybrid:         &p.hybrid,
+	}
+}
+
+// normalizeScores 按配置归一化向量检索原始分数
+func normalizeScores(docs []*schema.Document, method string) {
+	switch method {
+	case v1rag.ScoreNormalizeShift:
+		for _, doc := range docs {
+			if doc.Score() > 1 {
+				doc.WithScore(doc.Score() - 1)
+			}
+		}
+	case v1rag.ScoreNormalizeMinMax:
+		if len(docs) == 0 {
+			return
+		}
+		lo, hi := docs[0].Score(), docs[0].Score()
+		for _, doc := range docs[1:] {
+			lo, hi = min(lo, doc.Score()), max(hi, doc.Score())
+		}
+		for _, doc := range docs {
+			if hi > lo {
+				doc.WithScore((doc.Score() - lo) / (hi - lo))
+			} else {
+				doc.WithScore(1)
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package api
+
+import (
+	v1rag "backend/api/rag/v1"
+	"context"
+	"math"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+func TestWithoutRewrite(t *testing.T) {
+	// 纠错重写轮次关闭 Retriever 的查询重写，其余覆盖项保持不变，且不修改请求原有的覆盖项
+	rounds, hybrid := 2, true
+	req := &v1rag.RetrievalProfile{RewriteRounds: &rounds, Fields: v1rag.RetrievalFieldQA, Hybrid: &hybrid, CandidateSize: 20}
+
+	p := defaultRetrievalProfile()
+	p.apply(withoutRewrite(req))
+	if p.rewriteRounds != 0 || p.fields != v1rag.RetrievalFieldQA || !p.hybrid || p.candidateSize != 20 {
+		t.Fatalf("生效配置 %+v", p)
+	}
+	if *req.RewriteRounds != 2 {
+		t.Fatalf("原覆盖项被修改: rewrite_rounds=%d", *req.RewriteRounds)
+	}
+
+	// 知识库配置了重写轮数、请求没有覆盖项时，同样关闭重写
+	p = defaultRetrievalProfile()
+	kb := 4
+	p.apply(&v1rag.RetrievalProfile{RewriteRounds: &kb})
+	p.apply(withoutRewrite(nil))
+	if p.rewriteRounds != 0 {
+		t.Fatalf("请求无覆盖项时重写轮数应为 0，实际 %d", p.rewriteRounds)
+	}
+}
+
+func TestRetrievalProfileLayering(t *testing.T) {
+	// 按 默认 < 知识库 < 请求 逐级覆盖，未设置的字段沿用上一级
+	kbRounds, kbRerank := 1, false
+	kb := &v1rag.RetrievalProfile{RewriteRounds: &kbRounds, Rerank: &kbRerank, Fields: v1rag.RetrievalFieldContent}
+	reqHybrid := true
+	req := &v1rag.RetrievalProfile{Fields: v1rag.RetrievalFieldQA, CandidateSize: 80, Hybrid: &reqHybrid}
+
+	p := defaultRetrievalProfile()
+	p.apply(kb)
+	p.apply(req)
+	if p.rewriteRounds != 1 || p.rerank {
+		t.Fatalf("知识库配置未生效: %+v", p)
+	}
+	if p.fields != v1rag.RetrievalFieldQA || p.candidateSize != 80 || !p.hybrid {
+		t.Fatalf("请求覆盖项未生效: %+v", p)
+	}
+	if p.scoreNormalize != v1rag.ScoreNormalizeShift {
+		t.Fatalf("未设置的归一化方式应沿用默认 shift，实际 %s", p.scoreNormalize)
+	}
+
+	// nil、负数轮数、空串与非正候选数不覆盖已有值；显式的 0 轮与 false 会覆盖
+	p = defaultRetrievalProfile()
+	p.apply(nil)
+	negative := -1
+	p.apply(&v1rag.RetrievalProfile{RewriteRounds: &negative, CandidateSize: -5})
+	if p != defaultRetrievalProfile() {
+		t.Fatalf("无效覆盖项不应改变默认配置: %+v", p)
+	}
+	zero, off := 0, false
+	p.apply(&v1rag.RetrievalProfile{RewriteRounds: &zero, Rerank: &off})
+	if p.rewriteRounds != 0 || p.rerank {
+		t.Fatalf("显式的 0 轮与关闭重排应生效: %+v", p)
+	}
+}
+
+func TestRetrievalProfileFields(t *testing.T) {
+	cases := []struct {
+		fields      string
+		content, qa bool
+	}{
+		{v1rag.RetrievalFieldBoth, true, true},
+		{v1rag.RetrievalFieldContent, true, false},
+		{v1rag.RetrievalFieldQA, false, true},
+	}
+	for _, c := range cases {
+		p := retrievalProfile{fields: c.fields}
+		if p.useContent() != c.content || p.useQA() != c.qa {
+			t.Fatalf("fields=%s: useContent=%v useQA=%v", c.fields, p.useContent(), p.useQA())
+		}
+	}
+}
+
+func TestRetrievalProfileValidation(t *testing.T) {
+	ctx := context.Background()
+	rounds, tooMany := 5, 6
+	valid := []*v1rag.RetrievalProfile{
+		{},
+		{RewriteRounds: &rounds, Fields: v1rag.RetrievalFieldQA, CandidateSize: 500, ScoreNormalize: v1rag.ScoreNormalizeMinMax},
+	}
+	for _, p := range valid {
+		if err := g.Validator().Data(p).Run(ctx); err != nil {
+			t.Fatalf("合法配置 %+v 校验失败: %v", p, err)
+		}
+	}
+	invalid := []*v1rag.RetrievalProfile{
+		{RewriteRounds: &tooMany},
+		{Fields: "title"},
+		{CandidateSize: 501},
+		{ScoreNormalize: "zscore"},
+	}
+	for _, p := range invalid {
+		if err := g.Validator().Data(p).Run(ctx); err == nil {
+			t.Fatalf("非法配置 %+v 应校验失败", p)
+		}
+	}
+}
+
+func TestNormalizeScores(t *testing.T) {
+	newDocs := func(scores ...float64) []*schema.Document {
+		docs := make([]*schema.Document, len(scores))
+		for i, s := range scores {
+			docs[i] = (&schema.Document{}).WithScore(s)
+		}
+		return docs
+	}
+	scoresOf := func(docs []*schema.Document) []float64 {
+		out := make([]float64, len(docs))
+		for i, doc := range docs {
+			out[i] = doc.Score()
+		}
+		return out
+	}
+
+	// shift：仅大于 1 的分数减 1
+	docs := newDocs(1.8, 0.6)
+	normalizeScores(docs, v1rag.ScoreNormalizeShift)
+	if got := scoresOf(docs); math.Abs(got[0]-0.8) > 1e-9 || got[1] != 0.6 {
+		t.Fatalf("shift 归一化结果 %v", got)
+	}
+
+	// minmax：线性缩放到 0-1，分数全部相同时均为 1
+	docs = newDocs(2, 4, 3)
+	normalizeScores(docs, v1rag.ScoreNormalizeMinMax)
+	if got := scoresOf(docs); got[0] != 0 || got[1] != 1 || got[2] != 0.5 {
+		t.Fatalf("minmax 归一化结果 %v", got)
+	}
+	docs = newDocs(0.3, 0.3)
+	normalizeScores(docs, v1rag.ScoreNormalizeMinMax)
+	if got := scoresOf(docs); got[0] != 1 || got[1] != 1 {
+		t.Fatalf("分数相同时 minmax 结果 %v", got)
+	}
+
+	// none：保持原始分数
+	docs = newDocs(1.8)
+	normalizeScores(docs, v1rag.ScoreNormalizeNone)
+	if got := scoresOf(docs); got[0] != 1.8 {
+		t.Fatalf("none 不应改变分数，实际 %v", got)
+	}
+}
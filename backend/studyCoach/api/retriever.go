@@ -1,6 +1,8 @@
 package api
 
 import (
+	v1rag "backend/api/rag/v1"
+	"backend/internal/logic/knowledge"
 	"backend/studyCoach/aiModel/CoachChat"
 	"backend/studyCoach/common"
 	"backend/studyCoach/rerank"
@@ -9,19 +11,24 @@ import (
 	"sync"
 	"time"
 
+	"github.com/cloudwego/eino/components"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/schema"
 	"github.com/gogf/gf/v2/frame/g"
 )
 
 type RetrieveReq struct {
-	Query         string   // 检索关键词
-	TopK          int      // 检索结果数量
-	Score         float64  // 分数阈值：范围 0-2，通常取 1.5+（1=不相关，2=完全相同）
-	KnowledgeName string   // 知识库名字
-	optQuery      string   // 优化后的检索关键词
-	excludeIDs    []string // 要排除的 _id 列表
-	rankScore     float64  // 重排分数：score 转换至 0-1 范围
+	Query         string                  // 检索关键词
+	TopK          int                     // 检索结果数量
+	Score         float64                 // 分数阈值：范围 0-2，通常取 1.5+（1=不相关，2=完全相同）
+	KnowledgeName string                  // 知识库名字
+	Namespace     common.Namespace        // 知识库命名空间（知识库 ID + 用户 UUID），为空时从 ctx 读取
+	Mode          string                  // 检索模式：standard（默认）/ corrective（纠错式 RAG），仅 Retrieve 生效
+	Profile       *v1rag.RetrievalProfile // 检索配置覆盖项，为空时使用知识库配置
+	profile       retrievalProfile        // 生效的检索配置
+	optQuery      string                  // 优化后的检索关键词
+	excludeIDs    []string                // 要排除的 _id 列表
+	rankScore     float64                 // 重排分数：score 转换至 0-1 范围
 }
 
 func (x *RetrieveReq) copy() *RetrieveReq {
@@ -30,6 +37,10 @@ func (x *RetrieveReq) copy() *RetrieveReq {
 		TopK:          x.TopK,
 		Score:         x.Score,
 		KnowledgeName: x.KnowledgeName,
+		Namespace:     x.Namespace,
+		Mode:          x.Mode,
+		Profile:       x.Profile,
+		profile:       x.profile,
 		optQuery:      x.optQuery,
 		excludeIDs:    x.excludeIDs,
 		rankScore:     x.rankScore,
@@ -56,53 +67,65 @@ func (x *Rag) Retriever(ctx context.Context, req *RetrieveReq) (msg []*schema.Do
 	if req.rankScore >= 1 {
 		req.rankScore -= 1
 	}
-	rewriteModel, err := CoachChat.RewriteModel(ctx)
+	if !req.Namespace.IsValid() {
+		req.Namespace = common.NamespaceFromContext(ctx)
+	}
+	req.profile = resolveRetrievalProfile(ctx, req)
+	// 被禁用的切片在向量检索阶段直接排除（三种引擎均支持按 ID 排除）
+	req.excludeIDs, err = knowledge.GetDisabledChunkIds(ctx, req.Namespace)
 	if err != nil {
 		return
 	}
 	wg := &sync.WaitGroup{}
-	var loopErr error
-	// 3 轮 Query 重写与检索（TODO: 改为配置项）
-	for i := 0; i < 3; i++ {
-		question := req.Query
-		var (
-			optMessages    []*schema.Message
-			rewriteMessage *schema.Message
-		)
-		optMessages, err = CoachChat.GetOptimizedQueryMessages(used, question, req.KnowledgeName)
-		if err != nil {
-			loopErr = err
-			break
+	collect := func(reqCopy *RetrieveReq) {
+		defer wg.Done()
+		rDocs, retrieveErr := x.retrieveDoOnce(ctx, reqCopy)
+		if retrieveErr != nil {
+			g.Log().Errorf(ctx, "retrieveDoOnce failed, err=%v", retrieveErr)
+			return
 		}
-		// 为rewrite模型调用设置30秒超时
-		rewriteCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
-		rewriteMessage, err = rewriteModel.Generate(rewriteCtx, optMessages)
-		cancel()
-		if err != nil {
-			loopErr = err
-			break
+		for _, doc := range rDocs {
+			if old, e := relatedDocs.LoadOrStore(doc.ID, doc); e {
+				// 同文档则保存较高分的结果（对于不同的optQuery，rerank可能会有不同的结果）
+				if doc.Score() > old.(*schema.Document).Score() {
+					relatedDocs.Store(doc.ID, doc)
+				}
+			}
 		}
-		optimizedQuery := rewriteMessage.Content
-		used += optimizedQuery + " "
-		req.optQuery = optimizedQuery
+	}
+	var loopErr error
+	if req.profile.rewriteRounds == 0 {
+		// 不重写，直接用原问题检索
+		req.optQuery = req.Query
 		wg.Add(1)
-		go func(reqCopy *RetrieveReq) {
-			defer wg.Done()
-			rDocs, retrieveErr := x.retrieveDoOnce(ctx, reqCopy)
-			if retrieveErr != nil {
-				g.Log().Errorf(ctx, "retrieveDoOnce failed, err=%v", retrieveErr)
-				return
+		go collect(req.copy())
+	} else {
+		rewriteModel, err := CoachChat.RewriteModel(ctx)
+		if err != nil {
+			return nil, err
+		}
+		// 按检索配置进行多轮 Query 重写与检索
+		for i := 0; i < req.profile.rewriteRounds; i++ {
+			question := req.Query
+			optMessages, err := CoachChat.GetOptimizedQueryMessages(used, question, req.KnowledgeName)
+			if err != nil {
+				loopErr = err
+				break
 			}
-			for _, doc := range rDocs {
-				if old, e := relatedDocs.LoadOrStore(doc.ID, doc); e {
-					// 同文档则保存较高分的结果（对于不同的optQuery，rerank可能会有不同的结果）
-					if doc.Score() > old.(*schema.Document).Score() {
-						relatedDocs.Store(doc.ID, doc)
-					}
-				}
+			// 为rewrite模型调用设置30秒超时
+			rewriteCtx, cancel := context.WithTimeout(common.WithCallName(ctx, "QueryRewrite", components.ComponentOfChatModel), 30*time.Second)
+			rewriteMessage, err := rewriteModel.Generate(rewriteCtx, optMessages)
+			cancel()
+			if err != nil {
+				loopErr = err
+				break
 			}
-
-		}(req.copy())
+			optimizedQuery := rewriteMessage.Content
+			used += optimizedQuery + " "
+			req.optQuery = optimizedQuery
+			wg.Add(1)
+			go collect(req.copy())
+		}
 	}
 	wg.Wait()
 	if loopErr != nil {
@@ -133,21 +156,41 @@ func (x *Rag) retrieveDoOnce(ctx context.Context, req *RetrieveReq) (relatedDocs
 		}
 	}()
 	var (
-		docs   []*schema.Document
-		qaDocs []*schema.Document
+		docs     []*schema.Document
+		qaDocs   []*schema.Document
+		lexDocs  []*schema.Document
+		lexicalW sync.WaitGroup
 	)
 	g.Log().Infof(ctx, "query: %v", req.optQuery)
+	// 混合检索：关键词检索与向量检索并行
+	if req.profile.hybrid {
+		lexicalW.Add(1)
+		go func() {
+			defer lexicalW.Done()
+			lexDocs = x.lexicalRetrieve(ctx, req)
+		}()
+	}
+	defer lexicalW.Wait()
 	// 通过内容检索
-	docs, err = x.retrieve(ctx, req, false)
-	if err != nil {
-		g.Log().Errorf(ctx, "retrieve failed, err=%v", err)
-		return
+	if req.profile.useContent() {
+		docs, err = x.retrieve(ctx, req, false)
+		if err != nil {
+			g.Log().Errorf(ctx, "retrieve failed, err=%v", err)
+			return
+		}
 	}
-	// 通过qa检索（仅当 qaRtrvr 已初始化；失败不影响主流程）
-	if x.qaRtrvr != nil {
+	if !req.profile.useContent() && x.qaRtrvr == nil {
+		g.Log().Warningf(ctx, "检索配置仅使用 QA 向量，但 QA 检索器未初始化, knowledge=%s", req.KnowledgeName)
+	}
+	// 通过qa检索（仅当 qaRtrvr 已初始化；与正文同时检索时失败不影响主流程）
+	if req.profile.useQA() && x.qaRtrvr != nil {
 		qaDocs, err = x.retrieve(ctx, req, true)
 		if err != nil {
 			g.Log().Errorf(ctx, "qa retrieve failed, err=%v", err)
+			if !req.profile.useContent() {
+				return
+			}
+			err = nil
 		} else {
 			docs = append(docs, qaDocs...)
 		}
@@ -156,11 +199,20 @@ func (x *Rag) retrieveDoOnce(ctx context.Context, req *RetrieveReq) (relatedDocs
 	docs = common.RemoveDuplicates(docs, func(doc *schema.Document) string {
 		return doc.ID
 	})
-	// 重排
-	docs, err = rerank.NewRerank(ctx, req.optQuery, docs, req.TopK)
-	if err != nil {
-		g.Log().Errorf(ctx, "Rerank failed, err=%v", err)
-		return
+	if req.profile.hybrid {
+		lexicalW.Wait()
+		g.Log().Debugf(ctx, "hybrid fuse, vector=%d, lexical=%d", len(docs), len(lexDocs))
+		docs = rrfFuse(g.Cfg().MustGet(ctx, "retriever.hybrid.rrfK", defaultRRFK).Int(), docs, lexDocs)
+	}
+	// 重排；关闭时按向量（混合检索时为 RRF）分数排序截取
+	if req.profile.rerank {
+		docs, err = rerank.NewRerank(ctx, req.optQuery, docs, req.TopK)
+		if err != nil {
+			g.Log().Errorf(ctx, "Rerank failed, err=%v", err)
+			return
+		}
+	} else {
+		docs = topK(docs, req.TopK)
 	}
 	for _, doc := range docs {
 		if doc.Score() < req.rankScore {
@@ -172,7 +224,7 @@ func (x *Rag) retrieveDoOnce(ctx context.Context, req *RetrieveReq) (relatedDocs
 	return
 }
 func (x *Rag) retrieve(ctx context.Context, req *RetrieveReq, qa bool) (msg []*schema.Document, err error) {
-	filterOpts, err := buildRetrieverFilterOptions(x.conf, req.KnowledgeName, req.excludeIDs, esTopK)
+	filterOpts, err := buildRetrieverFilterOptions(x.conf, req.Namespace, req.excludeIDs, req.profile.candidateSize)
 	if err != nil {
 		return nil, err
 	}
@@ -190,11 +242,7 @@ func (x *Rag) retrieve(ctx context.Context, req *RetrieveReq, qa bool) (msg []*s
 	if err != nil {
 		return nil, err
 	}
-	for _, s := range msg {
-		if s.Score() > 1 {
-			// 本身没意义，最终分数由rerank给，这里只是为了方便测试观察
-			s.WithScore(s.Score() - 1)
-		}
-	}
+	// 开启重排时最终分数由 rerank 给出；关闭重排时归一化后的分数直接参与阈值过滤
+	normalizeScores(msg, req.profile.scoreNormalize)
 	return msg, nil
 }
@@ -13,22 +13,25 @@ import (
 )
 
 // buildRetrieverFilterOptions 根据向量引擎类型构建检索过滤选项。
-// 返回 TopK + 引擎专属 filter 的 retriever.Option 列表。
-func buildRetrieverFilterOptions(conf *common.Config, knowledgeName string, excludeIDs []string, topK int) ([]er.Option, error) {
+// 返回 TopK + 引擎专属 filter 的 retriever.Option 列表；按命名空间（知识库 ID + 用户 UUID）隔离。
+func buildRetrieverFilterOptions(conf *common.Config, ns common.Namespace, excludeIDs []string, topK int) ([]er.Option, error) {
+	if !ns.IsValid() {
+		return nil, fmt.Errorf("invalid retriever namespace (%s)", ns)
+	}
 	opts := []er.Option{er.WithTopK(topK)}
 
 	if conf.UseES() {
-		esQuery := buildESFilterQuery(knowledgeName, excludeIDs)
+		esQuery := buildESFilterQuery(ns, excludeIDs)
 		opts = append(opts, es8.WithFilters(esQuery))
 		return opts, nil
 	}
 	if conf.UseQdrant() {
-		qdrantFilter := buildQdrantFilter(knowledgeName, excludeIDs)
+		qdrantFilter := buildQdrantFilter(ns, excludeIDs)
 		opts = append(opts, er.WithDSLInfo(map[string]any{"filter": qdrantFilter}))
 		return opts, nil
 	}
 	if conf.UseMilvus() {
-		milvusExpr := buildMilvusFilterExpr(knowledgeName, excludeIDs)
+		milvusExpr := buildMilvusFilterExpr(ns, excludeIDs)
 		if milvusExpr != "" {
 			opts = append(opts, milvus2.WithFilter(milvusExpr))
 		}
@@ -41,27 +44,23 @@ func buildRetrieverFilterOptions(conf *common.Config, knowledgeName string, excl
 // buildMilvusFilterExpr 构建 Milvus 布尔过滤表达式。
 // eino-ext milvus2 将 MetaData 存入 metadata JSON 字段，主键为 id。
 // 语法参考 https://milvus.io/docs/boolean.md
-func buildMilvusFilterExpr(knowledgeName string, excludeIDs []string) string {
-	escape := func(s string) string {
-		return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
-	}
-	// metadata["_knowledge_name"] 匹配知识库
-	parts := []string{fmt.Sprintf(`metadata["%s"] == %s`, common.KnowledgeName, escape(knowledgeName))}
+func buildMilvusFilterExpr(ns common.Namespace, excludeIDs []string) string {
+	parts := []string{ns.MilvusExpr()}
 	if len(excludeIDs) > 0 {
 		quoted := make([]string, len(excludeIDs))
 		for i, id := range excludeIDs {
-			quoted[i] = escape(id)
+			quoted[i] = common.MilvusQuote(id)
 		}
 		parts = append(parts, fmt.Sprintf("id not in [%s]", strings.Join(quoted, ", ")))
 	}
 	return strings.Join(parts, " && ")
 }
 
-// buildESFilterQuery 构建 ES bool query：knowledge_name 匹配 + 排除指定 _id。
-func buildESFilterQuery(knowledgeName string, excludeIDs []string) []types.Query {
+// buildESFilterQuery 构建 ES bool query：命名空间 term 过滤 + 排除指定 _id。
+func buildESFilterQuery(ns common.Namespace, excludeIDs []string) []types.Query {
 	q := types.Query{
 		Bool: &types.BoolQuery{
-			Must: []types.Query{{Match: map[string]types.MatchQuery{common.KnowledgeName: {Query: knowledgeName}}}},
+			Filter: ns.ESQueries(),
 		},
 	}
 	if len(excludeIDs) > 0 {
@@ -72,21 +71,10 @@ func buildESFilterQuery(knowledgeName string, excludeIDs []string) []types.Query
 	return []types.Query{q}
 }
 
-// buildQdrantFilter 构建 Qdrant Filter：knowledge_name 匹配 + 排除指定 ID。
-func buildQdrantFilter(knowledgeName string, excludeIDs []string) *qdrant.Filter {
+// buildQdrantFilter 构建 Qdrant Filter：命名空间匹配 + 排除指定 ID。
+func buildQdrantFilter(ns common.Namespace, excludeIDs []string) *qdrant.Filter {
 	f := &qdrant.Filter{
-		Must: []*qdrant.Condition{
-			{
-				ConditionOneOf: &qdrant.Condition_Field{
-					Field: &qdrant.FieldCondition{
-						Key: common.KnowledgeName,
-						Match: &qdrant.Match{
-							MatchValue: &qdrant.Match_Keyword{Keyword: knowledgeName},
-						},
-					},
-				},
-			},
-		},
+		Must: ns.QdrantConditions(),
 	}
 	if len(excludeIDs) > 0 {
 		ids := make([]*qdrant.PointId, len(excludeIDs))
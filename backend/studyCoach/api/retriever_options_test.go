@@ -0,0 +1,79 @@
+package api
+
+import (
+	"backend/studyCoach/common"
+	"reflect"
+	"testing"
+
+	"github.com/milvus-io/milvus/client/v2/milvusclient"
+)
+
+var testNS = common.Namespace{KnowledgeBaseId: 7, UserUUID: "u1"}
+
+func TestMilvusFilterExcludesDisabledChunks(t *testing.T) {
+	// 禁用切片按主键排除，ID 中的引号需转义，避免破坏表达式
+	got := buildMilvusFilterExpr(testNS, []string{"a", `b"c`})
+	want := testNS.MilvusExpr() + ` && id not in ["a", "b\"c"]`
+	if got != want {
+		t.Fatalf("得到 %s\n期望 %s", got, want)
+	}
+	if got := buildMilvusFilterExpr(testNS, nil); got != testNS.MilvusExpr() {
+		t.Fatalf("没有禁用切片时只按命名空间过滤，得到 %s", got)
+	}
+}
+
+func TestESFilterExcludesDisabledChunks(t *testing.T) {
+	q := buildESFilterQuery(testNS, []string{"a", "b"})
+	if len(q) != 1 || q[0].Bool == nil {
+		t.Fatalf("应为单个 bool query: %+v", q)
+	}
+	if !reflect.DeepEqual(q[0].Bool.Filter, testNS.ESQueries()) {
+		t.Fatalf("filter 应为命名空间条件: %+v", q[0].Bool.Filter)
+	}
+	if len(q[0].Bool.MustNot) != 1 || q[0].Bool.MustNot[0].Terms == nil {
+		t.Fatalf("应以 must_not terms 排除禁用切片: %+v", q[0].Bool.MustNot)
+	}
+	if got := q[0].Bool.MustNot[0].Terms.TermsQuery["_id"]; !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("排除的 _id 为 %v", got)
+	}
+	if q := buildESFilterQuery(testNS, nil); q[0].Bool.MustNot != nil {
+		t.Fatal("没有禁用切片时不应有 must_not")
+	}
+}
+
+func TestQdrantFilterExcludesDisabledChunks(t *testing.T) {
+	f := buildQdrantFilter(testNS, []string{"a", "b"})
+	if !reflect.DeepEqual(f.Must, testNS.QdrantConditions()) {
+		t.Fatalf("must 应为命名空间条件: %+v", f.Must)
+	}
+	if len(f.MustNot) != 1 {
+		t.Fatalf("应有一个 must_not 条件: %+v", f.MustNot)
+	}
+	var got []string
+	for _, id := range f.MustNot[0].GetHasId().GetHasId() {
+		got = append(got, id.GetUuid())
+	}
+	if !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Fatalf("排除的点 ID 为 %v", got)
+	}
+	if f := buildQdrantFilter(testNS, nil); f.MustNot != nil {
+		t.Fatal("没有禁用切片时不应有 must_not")
+	}
+}
+
+func TestBuildRetrieverFilterOptions(t *testing.T) {
+	milvus := &common.Config{VectorEngine: common.VectorEngineMilvus, MilvusConfig: &milvusclient.ClientConfig{}}
+	if _, err := buildRetrieverFilterOptions(milvus, common.Namespace{KnowledgeBaseId: 7}, nil, 5); err == nil {
+		t.Fatal("命名空间缺少用户时应拒绝检索，避免跨用户读取")
+	}
+	opts, err := buildRetrieverFilterOptions(milvus, testNS, []string{"a"}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(opts) != 2 {
+		t.Fatalf("应包含 TopK 与过滤条件，实际 %d 个选项", len(opts))
+	}
+	if _, err := buildRetrieverFilterOptions(&common.Config{}, testNS, nil, 5); err == nil {
+		t.Fatal("未配置向量引擎时应返回错误")
+	}
+}
@@ -22,7 +22,7 @@ type RequestPayload struct {
 
 func TextToSpeech(ctx context.Context, input string) ([]byte, error) {
 	// 从配置文件获取基础URL
-	baseURL := g.Cfg().MustGet(ctx, "siliconflow.baseURL").String()
+	baseURL := g.Cfg().MustGet(ctx, "voice.baseURL").String()
 	if baseURL == "" {
 		return nil, fmt.Errorf("base URL not found in configuration")
 	}
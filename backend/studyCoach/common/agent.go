@@ -0,0 +1,146 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent"
+	"github.com/cloudwego/eino/flow/agent/react"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// AgentBuilder 构建 ReAct Agent，审批恢复时在任意实例上重建同一 Agent
+type AgentBuilder func(ctx context.Context) (*react.Agent, error)
+
+// agentBuilders Agent 名称 -> AgentBuilder
+var agentBuilders sync.Map
+
+// RegisterAgent 登记 Agent 构建函数，name 与 BuildAgentLambda 使用的名称一致
+func RegisterAgent(name string, build AgentBuilder) {
+	agentBuilders.Store(name, build)
+}
+
+const agentNodeKey = "Agent"
+
+// agentRunner 带检查点的 ReAct Agent：将 Agent 图作为子图编译，工具等待审批时中断并保存检查点
+type agentRunner struct {
+	name     string
+	runnable compose.Runnable[[]*schema.Message, *schema.Message]
+}
+
+func newAgentRunner(ctx context.Context, name string, ins *react.Agent) (*agentRunner, error) {
+	graph, opts := ins.ExportGraph()
+	wg := compose.NewGraph[[]*schema.Message, *schema.Message]()
+	if err := wg.AddGraphNode(agentNodeKey, graph, opts...); err != nil {
+		return nil, err
+	}
+	_ = wg.AddEdge(compose.START, agentNodeKey)
+	_ = wg.AddEdge(agentNodeKey, compose.END)
+	r, err := wg.Compile(ctx, compose.WithCheckPointStore(checkPointStore{}), compose.WithGraphName(name))
+	if err != nil {
+		return nil, err
+	}
+	return &agentRunner{name: name, runnable: r}, nil
+}
+
+// BuildAgentLambda 将 ReAct Agent 包装为 Lambda（Stream 模式见 genToStream）。
+// 对话接口写入 ApprovalTurn 时以轮次 ID 作为检查点 ID 运行，需审批的工具会暂停本轮
+func BuildAgentLambda(ctx context.Context, name string, ins *react.Agent) (*compose.Lambda, error) {
+	a, err := newAgentRunner(ctx, name, ins)
+	if err != nil {
+		return nil, err
+	}
+	return compose.AnyLambda(a.generate, genToStream(a.generate), nil, nil)
+}
+
+func (a *agentRunner) generate(ctx context.Context, msgs []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+	turn, ok := ctx.Value(approvalTurnKey{}).(*ApprovalTurn)
+	if !ok || g.Redis() == nil {
+		return a.runnable.Invoke(ctx, msgs, agent.GetComposeOptions(opts...)...)
+	}
+	run := &approvalRun{
+		turn:         turn,
+		agent:        a.name,
+		checkPointId: turn.TurnId,
+		address:      compose.GetCurrentAddress(ctx),
+	}
+	return a.run(ctx, run, msgs, false, opts...)
+}
+
+// run 以 run.checkPointId 运行（或从该检查点恢复）Agent：中断时保存待审批记录，恢复后正常结束时删除检查点
+func (a *agentRunner) run(ctx context.Context, run *approvalRun, msgs []*schema.Message, resumed bool, opts ...agent.AgentOption) (*schema.Message, error) {
+	ctx = context.WithValue(ctx, approvalRunKey{}, run)
+	out, err := a.runnable.Invoke(ctx, msgs, append(agent.GetComposeOptions(opts...), compose.WithCheckPointID(run.checkPointId))...)
+	if err != nil {
+		if info, ok := compose.ExtractInterruptInfo(err); ok {
+			return nil, run.pause(ctx, info)
+		}
+		return nil, err
+	}
+	if resumed {
+		deleteCheckPoint(ctx, run.checkPointId)
+	}
+	return out, nil
+}
+
+// ResumeAgent 按用户答复从检查点恢复 Agent，返回续写回复的流。
+// ctx 中的 ApprovalTurn 为续写所在的轮次，续写中再次遇到需审批的工具时以其生成新的审批请求。
+// rec 须已由 ClaimApproval 认领；恢复或续写失败时放回待审批记录，检查点仍在，用户可重新答复
+func ResumeAgent(ctx context.Context, rec *PendingApproval, decision *ApprovalDecision) (*schema.StreamReader[*schema.Message], error) {
+	sr, err := resumeAgent(ctx, rec, decision)
+	if err != nil {
+		restoreApproval(ctx, rec)
+	}
+	return sr, err
+}
+
+func resumeAgent(ctx context.Context, rec *PendingApproval, decision *ApprovalDecision) (*schema.StreamReader[*schema.Message], error) {
+	turn, ok := ctx.Value(approvalTurnKey{}).(*ApprovalTurn)
+	if !ok {
+		return nil, fmt.Errorf("恢复 Agent 缺少轮次信息")
+	}
+	v, ok := agentBuilders.Load(rec.Agent)
+	if !ok {
+		return nil, fmt.Errorf("未知的 Agent: %s", rec.Agent)
+	}
+	// 与原轮次一致的联网与深度思考设置
+	ctx = context.WithValue(ctx, "isNetwork", rec.IsNetwork)
+	ctx = context.WithValue(ctx, IsDeepThinking, rec.IsDeepThinking)
+	ins, err := v.(AgentBuilder)(ctx)
+	if err != nil {
+		return nil, err
+	}
+	a, err := newAgentRunner(ctx, rec.Agent, ins)
+	if err != nil {
+		return nil, err
+	}
+	// 中断点 ID 含 Agent 所在的调用路径，按原路径恢复才能定位
+	for _, seg := range rec.Address {
+		ctx = compose.AppendAddressSegment(ctx, seg.Type, seg.ID)
+	}
+	resumeData := make(map[string]any, len(rec.InterruptIds))
+	for _, id := range rec.InterruptIds {
+		resumeData[id] = decision
+	}
+	ctx = compose.BatchResumeWithData(ctx, resumeData)
+	run := &approvalRun{
+		turn:         turn,
+		agent:        rec.Agent,
+		checkPointId: rec.CheckPointId,
+		address:      rec.Address,
+	}
+	generate := func(ctx context.Context, msgs []*schema.Message, opts ...agent.AgentOption) (*schema.Message, error) {
+		out, err := a.run(ctx, run, msgs, true, opts...)
+		// 续写中再次暂停时已生成新的审批请求，其余错误放回原记录
+		var paused *ApprovalRequiredError
+		if err != nil && !errors.As(err, &paused) {
+			restoreApproval(ctx, rec)
+		}
+		return out, err
+	}
+	return genToStream(generate)(ctx, nil)
+}
@@ -0,0 +1,301 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/google/uuid"
+)
+
+// 工具审批策略（配置 approval.tools，未列出的工具为 auto）
+const (
+	ApprovalAuto   = "auto"   // 直接执行
+	ApprovalAlways = "always" // 每次执行前需用户确认
+	ApprovalDeny   = "deny"   // 禁止执行
+)
+
+const (
+	defaultApprovalTTL  = 24 * time.Hour // 待审批记录与检查点的保留时间
+	approvalKeyPrefix   = "chat:approval:"
+	checkPointKeyPrefix = "chat:checkpoint:"
+)
+
+func init() {
+	schema.RegisterName[*ToolApprovalCall]("_study_coach_tool_approval_call")
+}
+
+// ToolApprovalPolicy 返回工具的审批策略，配置值无法识别时按 always 处理
+func ToolApprovalPolicy(ctx context.Context, toolName string) string {
+	policy := g.Cfg().MustGet(ctx, "approval.tools."+toolName).String()
+	switch policy {
+	case "", ApprovalAuto:
+		return ApprovalAuto
+	case ApprovalDeny:
+		return ApprovalDeny
+	default:
+		return ApprovalAlways
+	}
+}
+
+func approvalTTL(ctx context.Context) time.Duration {
+	return g.Cfg().MustGet(ctx, "approval.ttl", defaultApprovalTTL).Duration()
+}
+
+// ToolApprovalCall 一次待确认的工具调用
+type ToolApprovalCall struct {
+	CallId    string `json:"call_id"`
+	Tool      string `json:"tool"`
+	Name      string `json:"name"`      // 展示名，与 tool_status 一致
+	Arguments string `json:"arguments"` // 模型生成的 JSON 参数
+}
+
+// ToolApproval 以 tool_approval_required 事件下发的审批请求，用户通过 /chat/approval 答复
+type ToolApproval struct {
+	Id        string             `json:"approval_id"`
+	SessionId string             `json:"session_id"`
+	TurnId    string             `json:"turn_id"`
+	Calls     []ToolApprovalCall `json:"calls"`
+	ExpiresAt int64              `json:"expires_at"`
+}
+
+// PendingApproval 待审批记录：Agent 中断时写入 Redis，答复时取出并从检查点恢复
+type PendingApproval struct {
+	ToolApproval
+	Agent          string                   `json:"agent"`
+	CheckPointId   string                   `json:"checkpoint_id"`
+	Address        []compose.AddressSegment `json:"address"`       // Agent 所在的调用路径，恢复时按原路径定位中断点
+	InterruptIds   []string                 `json:"interrupt_ids"` // 与 Calls 一一对应
+	IsNetwork      bool                     `json:"is_network"`
+	IsDeepThinking bool                     `json:"is_deep_thinking"`
+	Turn           json.RawMessage          `json:"turn"` // 调用方恢复轮次所需的数据
+}
+
+// ApprovalDecision 用户对审批请求的答复
+type ApprovalDecision struct {
+	Approved bool
+	Reason   string
+}
+
+// ApprovalTurn 可审批的轮次，由对话接口写入 ctx；未写入时（如 OpenAI 兼容接口）需审批的工具直接拒绝
+type ApprovalTurn struct {
+	SessionId string
+	TurnId    string
+	Data      json.RawMessage // 随审批记录保存，恢复时原样返回
+}
+
+type approvalTurnKey struct{}
+
+// WithApprovalTurn 将可审批的轮次写入 ctx
+func WithApprovalTurn(ctx context.Context, turn *ApprovalTurn) context.Context {
+	return context.WithValue(ctx, approvalTurnKey{}, turn)
+}
+
+// approvalRun 本次 Agent 运行的审批上下文，由 agentRunner 写入 ctx
+type approvalRun struct {
+	turn         *ApprovalTurn
+	agent        string
+	checkPointId string
+	address      compose.Address
+}
+
+type approvalRunKey struct{}
+
+// ApprovalRequiredError Agent 因工具需要审批而暂停，流式响应据此下发 tool_approval_required 事件
+type ApprovalRequiredError struct {
+	Approval *ToolApproval
+}
+
+func (e *ApprovalRequiredError) Error() string {
+	return fmt.Sprintf("工具调用等待用户审批: %s", e.Approval.Id)
+}
+
+// BuildApprovalMiddleware 返回工具审批中间件：always 策略的工具在执行前中断 Agent，
+// 用户答复后从检查点恢复，同意则执行，拒绝则把拒绝结果作为工具输出交给模型。
+// 需放在 BuildNotifyMiddleware 之前，确认后才推送 tool_status
+func BuildApprovalMiddleware() compose.ToolMiddleware {
+	return compose.ToolMiddleware{
+		Invokable: func(next compose.InvokableToolEndpoint) compose.InvokableToolEndpoint {
+			return func(ctx context.Context, input *compose.ToolInput) (*compose.ToolOutput, error) {
+				switch ToolApprovalPolicy(ctx, input.Name) {
+				case ApprovalAuto:
+					return next(ctx, input)
+				case ApprovalDeny:
+					return &compose.ToolOutput{Result: fmt.Sprintf("工具 %s 已被禁用，操作未执行，请告知用户。", input.Name)}, nil
+				}
+				if _, ok := ctx.Value(approvalRunKey{}).(*approvalRun); !ok {
+					return &compose.ToolOutput{Result: fmt.Sprintf("工具 %s 需要用户确认，当前对话方式不支持确认，操作未执行，请告知用户。", input.Name)}, nil
+				}
+				if isResume, hasData, decision := compose.GetResumeContext[*ApprovalDecision](ctx); isResume && hasData && decision != nil {
+					if decision.Approved {
+						g.Log().Infof(ctx, "[Approval] 用户已同意执行工具: %s", input.Name)
+						return next(ctx, input)
+					}
+					g.Log().Infof(ctx, "[Approval] 用户拒绝执行工具: %s", input.Name)
+					result := fmt.Sprintf("用户拒绝执行工具 %s，操作未执行。", input.Name)
+					if decision.Reason != "" {
+						result += "拒绝原因：" + decision.Reason
+					}
+					return &compose.ToolOutput{Result: result}, nil
+				}
+				return nil, compose.Interrupt(ctx, &ToolApprovalCall{
+					CallId:    input.CallID,
+					Tool:      input.Name,
+					Name:      SkillToolDisplayName(input.Name, input.Arguments),
+					Arguments: input.Arguments,
+				})
+			}
+		},
+	}
+}
+
+// pause 把中断信息保存为待审批记录，返回 ApprovalRequiredError
+func (r *approvalRun) pause(ctx context.Context, info *compose.InterruptInfo) error {
+	ttl := approvalTTL(ctx)
+	rec := &PendingApproval{
+		ToolApproval: ToolApproval{
+			Id:        uuid.NewString(),
+			SessionId: r.turn.SessionId,
+			TurnId:    r.turn.TurnId,
+			ExpiresAt: time.Now().Add(ttl).Unix(),
+		},
+		Agent:        r.agent,
+		CheckPointId: r.checkPointId,
+		Address:      r.address,
+		Turn:         r.turn.Data,
+	}
+	if v, ok := ctx.Value("isNetwork").(bool); ok {
+		rec.IsNetwork = v
+	}
+	if v, ok := ctx.Value(IsDeepThinking).(bool); ok {
+		rec.IsDeepThinking = v
+	}
+	for _, ic := range info.InterruptContexts {
+		call, ok := ic.Info.(*ToolApprovalCall)
+		if !ic.IsRootCause || !ok {
+			continue
+		}
+		rec.Calls = append(rec.Calls, *call)
+		rec.InterruptIds = append(rec.InterruptIds, ic.ID)
+	}
+	if len(rec.Calls) == 0 {
+		return fmt.Errorf("agent 中断但没有待审批的工具调用")
+	}
+	if err := saveApproval(ctx, rec); err != nil {
+		return err
+	}
+	g.Log().Infof(ctx, "[Approval] 等待用户审批: session=%s, turn=%s, approval=%s, 工具数=%d",
+		rec.SessionId, rec.TurnId, rec.Id, len(rec.Calls))
+	return &ApprovalRequiredError{Approval: &rec.ToolApproval}
+}
+
+// saveApproval 写入待审批记录并刷新会话审批记录的过期时间
+func saveApproval(ctx context.Context, rec *PendingApproval) error {
+	b, err := sonic.Marshal(rec)
+	if err != nil {
+		return err
+	}
+	key := approvalKeyPrefix + rec.SessionId
+	if _, err = g.Redis().HSet(ctx, key, map[string]any{rec.Id: string(b)}); err != nil {
+		return fmt.Errorf("保存待审批记录失败: %w", err)
+	}
+	if _, err = g.Redis().Expire(ctx, key, int64(approvalTTL(ctx).Seconds())); err != nil {
+		return fmt.Errorf("保存待审批记录失败: %w", err)
+	}
+	return nil
+}
+
+// ListApprovals 返回会话中未过期的待审批请求，供断线重连后重新展示
+func ListApprovals(ctx context.Context, sessionId string) ([]ToolApproval, error) {
+	redis := g.Redis()
+	if redis == nil {
+		return nil, nil
+	}
+	v, err := redis.HGetAll(ctx, approvalKeyPrefix+sessionId)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	list := make([]ToolApproval, 0)
+	for _, raw := range v.MapStrStr() {
+		var rec PendingApproval
+		if err := sonic.UnmarshalString(raw, &rec); err != nil || rec.ExpiresAt < now {
+			continue
+		}
+		list = append(list, rec.ToolApproval)
+	}
+	return list, nil
+}
+
+// LoadApproval 读取待审批记录，不存在或已过期时返回 404
+func LoadApproval(ctx context.Context, sessionId, approvalId string) (*PendingApproval, error) {
+	redis := g.Redis()
+	if redis == nil {
+		return nil, gerror.NewCode(gcode.New(404, "审批请求不存在或已过期", nil))
+	}
+	v, err := redis.HGet(ctx, approvalKeyPrefix+sessionId, approvalId)
+	if err != nil {
+		return nil, err
+	}
+	var rec PendingApproval
+	if v.IsEmpty() || sonic.Unmarshal(v.Bytes(), &rec) != nil || rec.ExpiresAt < time.Now().Unix() {
+		return nil, gerror.NewCode(gcode.New(404, "审批请求不存在或已过期", nil))
+	}
+	return &rec, nil
+}
+
+// ClaimApproval 删除待审批记录，同一请求只能答复一次，已被答复时返回 409
+func ClaimApproval(ctx context.Context, rec *PendingApproval) error {
+	n, err := g.Redis().HDel(ctx, approvalKeyPrefix+rec.SessionId, rec.Id)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return gerror.NewCode(gcode.New(409, "审批请求已处理", nil))
+	}
+	return nil
+}
+
+// restoreApproval 续写失败时放回已认领的待审批记录，用户可重新答复；记录已过期时不再放回
+func restoreApproval(ctx context.Context, rec *PendingApproval) {
+	if rec.ExpiresAt < time.Now().Unix() {
+		return
+	}
+	if err := saveApproval(context.WithoutCancel(ctx), rec); err != nil {
+		g.Log().Errorf(ctx, "[Approval] 恢复待审批记录失败: session=%s, approval=%s, 错误: %v", rec.SessionId, rec.Id, err)
+		return
+	}
+	g.Log().Infof(ctx, "[Approval] 续写失败，已恢复待审批记录: session=%s, approval=%s", rec.SessionId, rec.Id)
+}
+
+// checkPointStore 基于 Redis 的 Agent 检查点存储，审批可在断线重连或切换实例后继续
+type checkPointStore struct{}
+
+func (checkPointStore) Get(ctx context.Context, checkPointId string) ([]byte, bool, error) {
+	v, err := g.Redis().Get(ctx, checkPointKeyPrefix+checkPointId)
+	if err != nil {
+		return nil, false, err
+	}
+	if v.IsNil() {
+		return nil, false, nil
+	}
+	return v.Bytes(), true, nil
+}
+
+func (checkPointStore) Set(ctx context.Context, checkPointId string, checkPoint []byte) error {
+	return g.Redis().SetEX(ctx, checkPointKeyPrefix+checkPointId, checkPoint, int64(approvalTTL(ctx).Seconds()))
+}
+
+// deleteCheckPoint Agent 运行结束后删除检查点，失败只记录日志
+func deleteCheckPoint(ctx context.Context, checkPointId string) {
+	if _, err := g.Redis().Del(ctx, checkPointKeyPrefix+checkPointId); err != nil {
+		g.Log().Warningf(ctx, "[Approval] 删除检查点失败: id=%s, 错误: %v", checkPointId, err)
+	}
+}
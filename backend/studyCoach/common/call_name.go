@@ -0,0 +1,13 @@
+package common
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+)
+
+// WithCallName 为不经过图编排、直接调用的组件标注名称：组件回调的 RunInfo.Name 即为该名称，用量记录据此区分调用方
+func WithCallName(ctx context.Context, name string, component components.Component) context.Context {
+	return callbacks.ReuseHandlers(ctx, &callbacks.RunInfo{Name: name, Component: component})
+}
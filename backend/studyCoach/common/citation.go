@@ -0,0 +1,187 @@
+package common
+
+import (
+	v1 "backend/api/ai_chat/v1"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+const maxCitationDigits = 3 // 引用标记 [n] 中序号的最大位数
+
+// Citation 回答中的引用标记 [Index] 对应的来源切片，即会话接口返回的 v1.Citation，
+// 保存回答时按该结构序列化、读取历史时再按其反序列化
+type Citation = v1.Citation
+
+// FormatKnowledge 将检索结果编号后注入提示词，并要求模型以 [n] 标注引用来源；无检索结果时返回空
+func FormatKnowledge(docs []*schema.Document) string {
+	if len(docs) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("以下是编号的参考资料。回答中用到某条资料时，在对应语句末尾标注其编号，如 [1] 或 [1][3]；")
+	sb.WriteString("只能使用下列编号，资料未涉及的内容不要标注。\n")
+	for i, doc := range docs {
+		c := citationOf(i+1, doc)
+		fmt.Fprintf(&sb, "\n[%d] 来源：%s", i+1, c.DocumentName)
+		if c.KnowledgeName != "" {
+			fmt.Fprintf(&sb, "（知识库：%s）", c.KnowledgeName)
+		}
+		sb.WriteString("\n")
+		sb.WriteString(strings.TrimSpace(doc.Content))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// DocumentCitations 按注入提示词时的编号列出全部参考资料的来源
+func DocumentCitations(docs []*schema.Document) []Citation {
+	citations := make([]Citation, 0, len(docs))
+	for i, doc := range docs {
+		citations = append(citations, citationOf(i+1, doc))
+	}
+	return citations
+}
+
+func citationOf(index int, doc *schema.Document) Citation {
+	c := Citation{Index: index, ChunkId: doc.ID}
+	c.KnowledgeName, _ = doc.MetaData[KnowledgeName].(string)
+	c.DocumentName, _ = doc.MetaData["_file_name"].(string)
+	// 纠错检索的网络兜底结果以 _origin 标记，知识库切片的 _source 为文件路径
+	origin, _ := doc.MetaData[Origin].(string)
+	source, _ := doc.MetaData["_source"].(string)
+	switch {
+	case origin == OriginWeb:
+		c.Source = OriginWeb
+		c.DocumentName = "网络搜索"
+	case c.DocumentName == "" && source != "":
+		c.DocumentName = filepath.Base(source)
+	case c.DocumentName == "":
+		c.DocumentName = "未知来源"
+	}
+	return c
+}
+
+// CitationFilter 流式校验回答中的引用标记：序号超出参考资料范围的标记被删除，
+// 未写完的标记暂存到下一段再判断。数组下标（如 a[1]）与代码块中的方括号不视为引用
+type CitationFilter struct {
+	docs []*schema.Document
+	used map[int]bool
+
+	pending     []rune // 可能是引用标记的未完成片段，以 '[' 开头
+	prev        rune   // 已输出的上一个字符
+	afterMarker bool   // 上一个输出是引用标记，允许紧接 [n][m]
+	ticks       int    // 连续反引号数
+	inCode      bool   // 位于 ``` 代码块内
+}
+
+// NewCitationFilter 按注入提示词的参考资料创建过滤器
+func NewCitationFilter(docs []*schema.Document) *CitationFilter {
+	return &CitationFilter{docs: docs, used: make(map[int]bool)}
+}
+
+// Push 输入一段增量文本，返回可以输出的文本
+func (f *CitationFilter) Push(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		if len(f.pending) > 0 {
+			if r >= '0' && r <= '9' && len(f.pending) <= maxCitationDigits {
+				f.pending = append(f.pending, r)
+				continue
+			}
+			if r == ']' && len(f.pending) > 1 {
+				f.closeMarker(&sb)
+				continue
+			}
+			f.emit(&sb, f.pending...)
+			f.pending = nil
+		}
+		if r == '[' && f.markerAllowed() {
+			f.pending = []rune{r}
+			continue
+		}
+		f.emit(&sb, r)
+	}
+	return sb.String()
+}
+
+// Flush 输出暂存的未完成片段，流结束时调用
+func (f *CitationFilter) Flush() string {
+	var sb strings.Builder
+	f.emit(&sb, f.pending...)
+	f.pending = nil
+	return sb.String()
+}
+
+// Citations 回答中实际出现的引用，按序号升序
+func (f *CitationFilter) Citations() []Citation {
+	indexes := make([]int, 0, len(f.used))
+	for n := range f.used {
+		indexes = append(indexes, n)
+	}
+	sort.Ints(indexes)
+	citations := make([]Citation, 0, len(indexes))
+	for _, n := range indexes {
+		citations = append(citations, citationOf(n, f.docs[n-1]))
+	}
+	return citations
+}
+
+// closeMarker 完成一个 [n]：序号有效时输出并记录，否则丢弃
+func (f *CitationFilter) closeMarker(sb *strings.Builder) {
+	n, _ := strconv.Atoi(string(f.pending[1:]))
+	f.pending = nil
+	if n < 1 || n > len(f.docs) {
+		return
+	}
+	f.used[n] = true
+	sb.WriteString("[" + strconv.Itoa(n) + "]")
+	f.prev = ']'
+	f.afterMarker = true
+	f.ticks = 0
+}
+
+func (f *CitationFilter) emit(sb *strings.Builder, runes ...rune) {
+	for _, r := range runes {
+		sb.WriteRune(r)
+		if r == '`' {
+			f.ticks++
+		} else {
+			if f.ticks >= 3 {
+				f.inCode = !f.inCode
+			}
+			f.ticks = 0
+		}
+		f.prev = r
+		f.afterMarker = false
+	}
+}
+
+func (f *CitationFilter) markerAllowed() bool {
+	if f.inCode || f.ticks > 0 {
+		return false
+	}
+	if f.afterMarker || f.prev == 0 {
+		return true
+	}
+	r := f.prev
+	return !(r == '_' || r == ')' || r == ']' || r < utf8.RuneSelf && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'))
+}
+
+// CleanCitations 校验完整回答中的引用标记，返回清理后的文本与实际引用
+func CleanCitations(content string, docs []*schema.Document) (string, []Citation) {
+	f := NewCitationFilter(docs)
+	cleaned := f.Push(content) + f.Flush()
+	return cleaned, f.Citations()
+}
+
+// StripCitations 删除回答中的全部引用标记，用于把带引用的历史回答放回上下文（其来源不在本轮参考资料中）
+func StripCitations(content string) string {
+	cleaned, _ := CleanCitations(content, nil)
+	return cleaned
+}
@@ -0,0 +1,30 @@
+package common
+
+import (
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+func TestDocumentCitationsOrigin(t *testing.T) {
+	// 网络搜索结果由 _origin 标记；_source 恒为文件路径，即使路径恰好是 web 也按文件处理
+	docs := []*schema.Document{
+		{ID: "web_1", MetaData: map[string]any{Origin: OriginWeb}},
+		{ID: "c1", MetaData: map[string]any{"_source": "web"}},
+		{ID: "c2", MetaData: map[string]any{"_source": "/data/notes/排序.md"}},
+	}
+	got := DocumentCitations(docs)
+	want := []Citation{
+		{Index: 1, ChunkId: "web_1", DocumentName: "网络搜索", Source: OriginWeb},
+		{Index: 2, ChunkId: "c1", DocumentName: "web"},
+		{Index: 3, ChunkId: "c2", DocumentName: "排序.md"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("引用数 %d，期望 %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("第 %d 条引用 %+v，期望 %+v", i+1, got[i], want[i])
+		}
+	}
+}
@@ -12,6 +12,7 @@ const (
 	FieldExtra           = "ext"               // 扩展元数据 JSON
 	KnowledgeName        = "_knowledge_name"   // 知识库名称
 	KnowledgeBaseId      = "knowledge_base_id" // 知识库 ID
+	UserUUID             = "user_uuid"         // 知识库所属用户 UUID
 
 	RetrieverFieldKey = "_retriever_field" // 检索时指定向量字段（content_vector / qa_content_vector）
 
@@ -21,6 +22,9 @@ const (
 	// KnowledgeBaseIdKey 用于在 context 中传递知识库 ID
 	KnowledgeBaseIdKey = "_knowledge_base_id"
 
+	// UserUUIDKey 用于在 context 中传递知识库所属用户 UUID
+	UserUUIDKey = "_user_uuid"
+
 	// IsDeepThinking 深度思考开关，用于 NormalChat 的 ark Thinking
 	IsDeepThinking = "_is_deep_thinking"
 
@@ -33,9 +37,23 @@ const (
 	ImageFeatures = "image_features" // 图片特征描述
 
 	XlsxRow = "_row" // Excel 行号
+
+	// Origin 文档来源类型，与存放文件路径的 _source 分开；知识库切片不设置，网络搜索结果为 OriginWeb
+	Origin    = "_origin"
+	OriginWeb = "web"
+
+	PageNumber          = "_page"                // PDF 页码（单页 "3"，跨页 "3-5"）
+	SlideNumber         = "_slide"               // PPTX 幻灯片序号
+	SectionNumber       = "_section"             // EPUB 章节序号
+	PageMarkerFormat    = "<!-- page: %d -->"    // 本地 PDF 解析写入 Markdown 的页码标记
+	SlideMarkerFormat   = "<!-- slide: %d -->"   // PPTX 解析写入 Markdown 的幻灯片标记
+	SectionMarkerFormat = "<!-- section: %d -->" // EPUB 解析写入 Markdown 的章节标记
 )
 
 var (
+	// MarkdownExts 解析后输出 Markdown 结构、走标题切分的扩展名
+	MarkdownExts = map[string]bool{".md": true, ".docx": true, ".pptx": true, ".epub": true}
+
 	// ExtKeys ext 里面需要存储的数据
-	ExtKeys = []string{"_extension", "_file_name", "_source", Title1, Title2, Title3}
+	ExtKeys = []string{"_extension", "_file_name", "_source", Title1, Title2, Title3, PageNumber, SlideNumber, SectionNumber}
 )
@@ -1,20 +1,20 @@
 package common
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
 	"github.com/elastic/go-elasticsearch/v8"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/deletebyquery"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/create"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/exists"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/putmapping"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/conflicts"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/densevectorsimilarity"
-	"github.com/gogf/gf/v2/frame/g"
 )
 
 func createEsIndex(ctx context.Context, client *elasticsearch.Client, indexName string) error {
@@ -28,7 +28,10 @@ func createEsIndex(ctx context.Context, client *elasticsearch.Client, indexName
 				FieldContent:  types.NewTextProperty(),
 				FieldExtra:    types.NewTextProperty(),
 				FieldCronID:   types.NewKeywordProperty(),
-				KnowledgeName: types.NewKeywordProperty(), // 必须为 keyword，SearchDocumentsByIDs 用 Match 精确匹配
+				KnowledgeName: types.NewKeywordProperty(),
+				// 命名空间字段：检索、删除均按 term 精确过滤
+				KnowledgeBaseId: types.NewLongNumberProperty(),
+				UserUUID:        types.NewKeywordProperty(),
 				FieldContentVector: &types.DenseVectorProperty{
 					Dims:       TypeOf(1024),
 					Index:      TypeOf(true),
@@ -55,28 +58,36 @@ func CreateIndexIfNotExists(ctx context.Context, client *elasticsearch.Client, i
 		return err
 	}
 	if indexExists {
-		return nil
+		// 旧索引补充命名空间字段 mapping，避免写入时被动态映射为 text 导致 term 过滤失效
+		return ensureEsNamespaceMapping(ctx, client, indexName)
 	}
 	err = createEsIndex(ctx, client, indexName)
 	return err
 }
 
-// DeleteDocument 删除索引中的单个文档
-func DeleteDocument(ctx context.Context, client *elasticsearch.Client, documentID string) error {
-	return withRetry(func() error {
-		indexName := g.Cfg().MustGet(ctx, "es.indexName").String()
-		res, err := client.Delete(indexName, documentID)
-		if err != nil {
-			return fmt.Errorf("delete document failed: %w", err)
-		}
-		defer res.Body.Close()
-
-		if res.IsError() {
-			return fmt.Errorf("delete document failed: %s", res.String())
-		}
+// ensureEsNamespaceMapping 为已有索引追加 knowledge_base_id / user_uuid 字段 mapping（已存在同类型字段时为幂等操作）
+func ensureEsNamespaceMapping(ctx context.Context, client *elasticsearch.Client, indexName string) error {
+	_, err := putmapping.NewPutMappingFunc(client)(indexName).Properties(map[string]types.Property{
+		KnowledgeBaseId: types.NewLongNumberProperty(),
+		UserUUID:        types.NewKeywordProperty(),
+	}).Do(ctx)
+	if err != nil {
+		return fmt.Errorf("put namespace mapping failed: %w", err)
+	}
+	return nil
+}
 
-		return nil
-	})
+// esDeleteByQuery 按查询条件删除文档并立即刷新
+func (c *Config) esDeleteByQuery(ctx context.Context, query *types.Query) error {
+	_, err := deletebyquery.NewDeleteByQueryFunc(c.Client)(c.IndexName).
+		Query(query).
+		Conflicts(conflicts.Proceed).
+		Refresh(true).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("delete by query failed: %w", err)
+	}
+	return nil
 }
 
 // withRetry 包装函数，添加重试机制
@@ -86,38 +97,3 @@ func withRetry(operation func() error) error {
 
 	return backoff.Retry(operation, b)
 }
-
-// DeleteDocumentsByCronID 删除指定CronID的所有文档
-func DeleteDocumentsByCronID(ctx context.Context, client *elasticsearch.Client, cronID string) error {
-	return withRetry(func() error {
-		indexName := g.Cfg().MustGet(ctx, "es.indexName").String()
-
-		// 构造删除查询
-		query := map[string]interface{}{
-			"query": map[string]interface{}{
-				"term": map[string]interface{}{
-					FieldCronID: cronID,
-				},
-			},
-		}
-
-		var buf bytes.Buffer
-		if err := json.NewEncoder(&buf).Encode(query); err != nil {
-			return fmt.Errorf("encode query failed: %w", err)
-		}
-
-		// 使用 DeleteByQuery API
-		// 注意：DeleteByQuery 需要 explicit index
-		res, err := client.DeleteByQuery([]string{indexName}, &buf)
-		if err != nil {
-			return fmt.Errorf("delete by query failed: %w", err)
-		}
-		defer res.Body.Close()
-
-		if res.IsError() {
-			return fmt.Errorf("delete by query failed: %s", res.String())
-		}
-
-		return nil
-	})
-}
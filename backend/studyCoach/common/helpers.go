@@ -12,7 +12,7 @@ import (
 	"github.com/elastic/go-elasticsearch/v8/typedapi/indices/refresh"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
 	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/densevectorsimilarity"
-	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/gogf/gf/v2/util/gconv"
 	"github.com/qdrant/go-client/qdrant"
 )
 
@@ -46,9 +46,11 @@ func (c *Config) CreateIndex(ctx context.Context) error {
 		_, err := create.NewCreateFunc(c.Client)(c.IndexName).Request(&create.Request{
 			Mappings: &types.TypeMapping{
 				Properties: map[string]types.Property{
-					FieldContent:  types.NewTextProperty(),
-					FieldExtra:    types.NewTextProperty(),
-					KnowledgeName: types.NewKeywordProperty(),
+					FieldContent:    types.NewTextProperty(),
+					FieldExtra:      types.NewTextProperty(),
+					KnowledgeName:   types.NewKeywordProperty(),
+					KnowledgeBaseId: types.NewLongNumberProperty(),
+					UserUUID:        types.NewKeywordProperty(),
 					FieldContentVector: &types.DenseVectorProperty{
 						Dims:       TypeOf(1024),
 						Index:      TypeOf(true),
@@ -85,16 +87,7 @@ func (c *Config) CreateIndex(ctx context.Context) error {
 		}
 
 		// 创建 payload 索引以支持过滤
-		_, err = c.QdrantClient.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
-			CollectionName: c.IndexName,
-			FieldName:      KnowledgeName,
-			FieldType:      qdrant.FieldType_FieldTypeKeyword.Enum(),
-		})
-		if err != nil {
-			return fmt.Errorf("failed to create field index: %w", err)
-		}
-
-		return nil
+		return c.ensureQdrantFieldIndexes(ctx)
 	}
 	if c.UseMilvus() {
 		// Milvus 由 indexer 首次 Store 时自动创建 collection，此处无需操作
@@ -103,33 +96,28 @@ func (c *Config) CreateIndex(ctx context.Context) error {
 	return fmt.Errorf("no valid client configuration")
 }
 
-// DeleteDocument 按文档 ID 删除单条文档（支持 ES、Qdrant、Milvus）。
-func (c *Config) DeleteDocument(ctx context.Context, documentID string) error {
+// DeleteDocument 按文档 ID 删除单条文档，仅删除命名空间内的数据（支持 ES、Qdrant、Milvus）。
+func (c *Config) DeleteDocument(ctx context.Context, ns Namespace, documentID string) error {
+	if !ns.IsValid() {
+		return fmt.Errorf("delete document failed: invalid namespace (%s)", ns)
+	}
 	if c.UseES() {
-		// ES
-		res, err := c.Client.Delete(c.IndexName, documentID)
-		if err != nil {
-			return fmt.Errorf("delete document failed: %w", err)
-		}
-		defer res.Body.Close()
-
-		if res.IsError() {
-			return fmt.Errorf("delete document failed: %s", res.String())
+		query := &types.Query{
+			Bool: &types.BoolQuery{
+				Filter: append(ns.ESQueries(),
+					types.Query{Ids: &types.IdsQuery{Values: []string{documentID}}},
+				),
+			},
 		}
-		return nil
+		return c.esDeleteByQuery(ctx, query)
 	}
 	if c.UseQdrant() {
+		filter := &qdrant.Filter{
+			Must: append(ns.QdrantConditions(), qdrant.NewHasID(qdrant.NewID(documentID))),
+		}
 		_, err := c.QdrantClient.Delete(ctx, &qdrant.DeletePoints{
 			CollectionName: c.IndexName,
-			Points: &qdrant.PointsSelector{
-				PointsSelectorOneOf: &qdrant.PointsSelector_Points{
-					Points: &qdrant.PointsIdsList{
-						Ids: []*qdrant.PointId{
-							{PointIdOptions: &qdrant.PointId_Uuid{Uuid: documentID}},
-						},
-					},
-				},
-			},
+			Points:         qdrant.NewPointsSelectorFilter(filter),
 		})
 		if err != nil {
 			return fmt.Errorf("failed to delete document: %w", err)
@@ -137,14 +125,8 @@ func (c *Config) DeleteDocument(ctx context.Context, documentID string) error {
 		return nil
 	}
 	if c.UseMilvus() {
-		if c.MilvusClient == nil {
-			return fmt.Errorf("milvus client not configured")
-		}
-		_, err := c.MilvusClient.Delete(ctx, milvusclient.NewDeleteOption(c.IndexName).WithExpr(fmt.Sprintf("id == \"%s\"", documentID)))
-		if err != nil {
-			return fmt.Errorf("failed to delete document from milvus: %w", err)
-		}
-		return nil
+		expr := fmt.Sprintf("id == %s && %s", MilvusQuote(documentID), ns.MilvusExpr())
+		return c.milvusDeleteByExpr(ctx, expr)
 	}
 	return fmt.Errorf("no valid client configuration")
 }
@@ -206,16 +188,18 @@ func (c *Config) GetKnowledgeBaseList(ctx context.Context) ([]string, error) {
 	return nil, fmt.Errorf("no valid client configuration")
 }
 
-// SearchDocumentsByIDs 按知识库名称和 ID 列表精确拉取文档（用于异步索引回查）。
-func (c *Config) SearchDocumentsByIDs(ctx context.Context, knowledgeName string, docIDs []string, size int) ([]*schema.Document, error) {
+// SearchDocumentsByIDs 按命名空间和 ID 列表精确拉取文档（用于异步索引回查）。
+func (c *Config) SearchDocumentsByIDs(ctx context.Context, ns Namespace, docIDs []string, size int) ([]*schema.Document, error) {
+	if !ns.IsValid() {
+		return nil, fmt.Errorf("search documents failed: invalid namespace (%s)", ns)
+	}
 	if c.UseES() {
 		// ES
 		esQuery := &types.Query{
 			Bool: &types.BoolQuery{
-				Must: []types.Query{
-					{Match: map[string]types.MatchQuery{KnowledgeName: {Query: knowledgeName}}},
-					{Terms: &types.TermsQuery{TermsQuery: map[string]types.TermsQueryField{"_id": docIDs}}},
-				},
+				Filter: append(ns.ESQueries(),
+					types.Query{Ids: &types.IdsQuery{Values: docIDs}},
+				),
 			},
 		}
 
@@ -249,27 +233,7 @@ func (c *Config) SearchDocumentsByIDs(ctx context.Context, knowledgeName string,
 		}
 
 		filter := &qdrant.Filter{
-			Must: []*qdrant.Condition{
-				{
-					ConditionOneOf: &qdrant.Condition_Field{
-						Field: &qdrant.FieldCondition{
-							Key: KnowledgeName,
-							Match: &qdrant.Match{
-								MatchValue: &qdrant.Match_Keyword{
-									Keyword: knowledgeName,
-								},
-							},
-						},
-					},
-				},
-				{
-					ConditionOneOf: &qdrant.Condition_HasId{
-						HasId: &qdrant.HasIdCondition{
-							HasId: ids,
-						},
-					},
-				},
-			},
+			Must: append(ns.QdrantConditions(), qdrant.NewHasID(ids...)),
 		}
 
 		limit := uint32(size)
@@ -296,21 +260,45 @@ func (c *Config) SearchDocumentsByIDs(ctx context.Context, knowledgeName string,
 		return docs, nil
 	}
 	if c.UseMilvus() {
-		return c.searchDocumentsByIDsMilvus(ctx, knowledgeName, docIDs, size)
+		return c.searchDocumentsByIDsMilvus(ctx, ns, docIDs, size)
 	}
 	return nil, fmt.Errorf("no valid client configuration")
 }
 
-// DeleteDocumentsByCronID 按 cron_id 删除该定时任务产生的所有文档。
-// 仅 ES 已实现；Qdrant、Milvus 待实现。
-func (c *Config) DeleteDocumentsByCronID(ctx context.Context, cronID string) error {
+// DeleteDocumentsByCronID 按 cron_id 删除该定时任务在命名空间内产生的所有文档。
+func (c *Config) DeleteDocumentsByCronID(ctx context.Context, ns Namespace, cronID string) error {
+	if !ns.IsValid() {
+		return fmt.Errorf("delete by cron_id failed: invalid namespace (%s)", ns)
+	}
 	if c.UseES() {
-		return DeleteDocumentsByCronID(ctx, c.Client, cronID)
+		query := &types.Query{
+			Bool: &types.BoolQuery{
+				Filter: append(ns.ESQueries(),
+					types.Query{Term: map[string]types.TermQuery{FieldCronID: {Value: cronID}}},
+				),
+			},
+		}
+		return withRetry(func() error {
+			return c.esDeleteByQuery(ctx, query)
+		})
 	}
-	if c.UseQdrant() || c.UseMilvus() {
-		// TODO: 实现 Qdrant/Milvus 的按 cron_id 删除
+	if c.UseQdrant() {
+		filter := &qdrant.Filter{
+			Must: append(ns.QdrantConditions(), qdrant.NewMatchKeyword(FieldCronID, cronID)),
+		}
+		_, err := c.QdrantClient.Delete(ctx, &qdrant.DeletePoints{
+			CollectionName: c.IndexName,
+			Points:         qdrant.NewPointsSelectorFilter(filter),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to delete documents by cron_id: %w", err)
+		}
 		return nil
 	}
+	if c.UseMilvus() {
+		expr := fmt.Sprintf(`metadata["%s"] == %s && %s`, FieldCronID, MilvusQuote(cronID), ns.MilvusExpr())
+		return c.milvusDeleteByExpr(ctx, expr)
+	}
 	return fmt.Errorf("no valid client configuration")
 }
 
@@ -346,6 +334,10 @@ func esHitToDocument(ctx context.Context, hit types.Hit) (*schema.Document, erro
 			doc.MetaData[FieldExtra] = val.(string)
 		case KnowledgeName:
 			doc.MetaData[KnowledgeName] = val.(string)
+		case KnowledgeBaseId:
+			doc.MetaData[KnowledgeBaseId] = gconv.Int64(val)
+		case UserUUID:
+			doc.MetaData[UserUUID] = gconv.String(val)
 		case FieldCronID:
 			// cron_id 可能为 nil（手动索引时未设置），检索时忽略即可
 			continue
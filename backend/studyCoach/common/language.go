@@ -0,0 +1,38 @@
+package common
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// 回复语言，与前端界面语言一致
+const (
+	LanguageZh = "zh"
+	LanguageEn = "en"
+)
+
+type languageKey struct{}
+
+// WithLanguage 设置本次对话的回复语言（来自用户设置）
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, languageKey{}, lang)
+}
+
+// LanguageFromContext 读取回复语言，未设置时为中文
+func LanguageFromContext(ctx context.Context) string {
+	if lang, _ := ctx.Value(languageKey{}).(string); lang != "" {
+		return lang
+	}
+	return LanguageZh
+}
+
+// LanguageMessages 非中文时追加的回复语言要求，与摘要一起注入模板的 summary 占位；提示词本身为中文
+func LanguageMessages(lang string) []*schema.Message {
+	switch lang {
+	case LanguageEn:
+		return []*schema.Message{schema.SystemMessage("用户的界面语言为英文：除非用户明确要求使用其他语言，请始终使用英文（English）回答，包括标题、列表与学习计划等全部输出。")}
+	default:
+		return nil
+	}
+}
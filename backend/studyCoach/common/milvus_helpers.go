@@ -5,24 +5,29 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/bytedance/sonic"
 	"github.com/cloudwego/eino/schema"
 	"github.com/milvus-io/milvus/client/v2/milvusclient"
 )
 
+// milvusClient 获取 Milvus 客户端：优先使用 Config 中的连接，否则按 MilvusConfig 取进程内共用的客户端
+func (c *Config) milvusClient(ctx context.Context) (*milvusclient.Client, error) {
+	if c.MilvusClient != nil {
+		return c.MilvusClient, nil
+	}
+	if c.MilvusConfig != nil {
+		return cachedMilvusClient(ctx, c.MilvusConfig)
+	}
+	return nil, fmt.Errorf("milvus client not configured")
+}
+
 // milvusCollectionExists 检查 Milvus collection 是否存在
 func (c *Config) milvusCollectionExists(ctx context.Context) (bool, error) {
-	client := c.MilvusClient
-	if client == nil && c.MilvusConfig != nil {
-		var err error
-		client, err = milvusclient.New(ctx, c.MilvusConfig)
-		if err != nil {
-			return false, err
-		}
-	}
-	if client == nil {
-		return false, fmt.Errorf("milvus client not configured")
+	client, err := c.milvusClient(ctx)
+	if err != nil {
+		return false, err
 	}
-	_, err := client.DescribeCollection(ctx, milvusclient.NewDescribeCollectionOption(c.IndexName))
+	_, err = client.DescribeCollection(ctx, milvusclient.NewDescribeCollectionOption(c.IndexName))
 	if err != nil {
 		if strings.Contains(strings.ToLower(err.Error()), "not found") {
 			return false, nil
@@ -32,10 +37,62 @@ func (c *Config) milvusCollectionExists(ctx context.Context) (bool, error) {
 	return true, nil
 }
 
-// searchDocumentsByIDsMilvus Milvus 按 ID 查询文档，待实现
-func (c *Config) searchDocumentsByIDsMilvus(ctx context.Context, knowledgeName string, docIDs []string, size int) ([]*schema.Document, error) {
-	_ = knowledgeName
-	_ = docIDs
-	_ = size
-	return nil, fmt.Errorf("SearchDocumentsByIDs for Milvus not implemented yet")
+// milvusDeleteByExpr 按过滤表达式删除 Milvus 中的数据
+func (c *Config) milvusDeleteByExpr(ctx context.Context, expr string) error {
+	client, err := c.milvusClient(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.Delete(ctx, milvusclient.NewDeleteOption(c.IndexName).WithExpr(expr))
+	if err != nil {
+		return fmt.Errorf("failed to delete document from milvus: %w", err)
+	}
+	return nil
+}
+
+// searchDocumentsByIDsMilvus Milvus 按命名空间与 ID 列表查询文档
+func (c *Config) searchDocumentsByIDsMilvus(ctx context.Context, ns Namespace, docIDs []string, size int) ([]*schema.Document, error) {
+	if len(docIDs) == 0 {
+		return nil, nil
+	}
+	client, err := c.milvusClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+	quoted := make([]string, len(docIDs))
+	for i, id := range docIDs {
+		quoted[i] = MilvusQuote(id)
+	}
+	expr := fmt.Sprintf("id in [%s] && %s", strings.Join(quoted, ", "), ns.MilvusExpr())
+	rs, err := client.Query(ctx, milvusclient.NewQueryOption(c.IndexName).
+		WithFilter(expr).
+		WithOutputFields("id", FieldContent, "metadata").
+		WithLimit(size))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query milvus: %w", err)
+	}
+	idCol, contentCol, metaCol := rs.GetColumn("id"), rs.GetColumn(FieldContent), rs.GetColumn("metadata")
+	if idCol == nil {
+		return nil, nil
+	}
+	docs := make([]*schema.Document, 0, idCol.Len())
+	for i := 0; i < idCol.Len(); i++ {
+		id, err := idCol.GetAsString(i)
+		if err != nil {
+			continue
+		}
+		doc := &schema.Document{ID: id, MetaData: map[string]any{}}
+		if contentCol != nil {
+			doc.Content, _ = contentCol.GetAsString(i)
+		}
+		if metaCol != nil {
+			if raw, err := metaCol.Get(i); err == nil {
+				if b, ok := raw.([]byte); ok {
+					_ = sonic.Unmarshal(b, &doc.MetaData)
+				}
+			}
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
 }
@@ -0,0 +1,67 @@
+package common
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// Namespace 向量库隔离命名空间：知识库 ID + 所属用户 UUID。
+// 知识库名称只在同一用户下唯一，检索、删除必须按 ID 过滤，避免不同用户的同名知识库共享数据。
+type Namespace struct {
+	KnowledgeBaseId int64
+	UserUUID        string
+}
+
+// IsValid 知识库 ID 与用户 UUID 均已设置
+func (n Namespace) IsValid() bool {
+	return n.KnowledgeBaseId > 0 && n.UserUUID != ""
+}
+
+func (n Namespace) String() string {
+	return fmt.Sprintf("kb_id=%d user=%s", n.KnowledgeBaseId, n.UserUUID)
+}
+
+// WithNamespace 将命名空间写入 context，供 indexer 写入 payload
+func WithNamespace(ctx context.Context, n Namespace) context.Context {
+	ctx = context.WithValue(ctx, KnowledgeBaseIdKey, n.KnowledgeBaseId)
+	return context.WithValue(ctx, UserUUIDKey, n.UserUUID)
+}
+
+// NamespaceFromContext 从 context 读取命名空间，未设置时返回零值
+func NamespaceFromContext(ctx context.Context) Namespace {
+	var n Namespace
+	n.KnowledgeBaseId, _ = ctx.Value(KnowledgeBaseIdKey).(int64)
+	n.UserUUID, _ = ctx.Value(UserUUIDKey).(string)
+	return n
+}
+
+// ESQueries 构建 ES term 过滤条件（knowledge_base_id / user_uuid 均为精确匹配字段）
+func (n Namespace) ESQueries() []types.Query {
+	return []types.Query{
+		{Term: map[string]types.TermQuery{KnowledgeBaseId: {Value: n.KnowledgeBaseId}}},
+		{Term: map[string]types.TermQuery{UserUUID: {Value: n.UserUUID}}},
+	}
+}
+
+// QdrantConditions 构建 Qdrant payload 过滤条件
+func (n Namespace) QdrantConditions() []*qdrant.Condition {
+	return []*qdrant.Condition{
+		qdrant.NewMatchInt(KnowledgeBaseId, n.KnowledgeBaseId),
+		qdrant.NewMatchKeyword(UserUUID, n.UserUUID),
+	}
+}
+
+// MilvusExpr 构建 Milvus 过滤表达式（命名空间字段存放在 metadata JSON 中）
+func (n Namespace) MilvusExpr() string {
+	return fmt.Sprintf(`metadata["%s"] == %d && metadata["%s"] == %s`,
+		KnowledgeBaseId, n.KnowledgeBaseId, UserUUID, MilvusQuote(n.UserUUID))
+}
+
+// MilvusQuote 将字符串转为 Milvus 表达式中的字符串字面量
+func MilvusQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
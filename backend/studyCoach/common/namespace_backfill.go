@@ -0,0 +1,168 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/bytedance/sonic"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/core/updatebyquery"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types"
+	"github.com/elastic/go-elasticsearch/v8/typedapi/types/enums/conflicts"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/milvus-io/milvus/client/v2/column"
+	"github.com/milvus-io/milvus/client/v2/entity"
+	"github.com/milvus-io/milvus/client/v2/milvusclient"
+	"github.com/qdrant/go-client/qdrant"
+)
+
+// milvusBackfillBatch Milvus 回填时每批查询/upsert 的条数
+const milvusBackfillBatch = 256
+
+// EnsureNamespaceSchema 为已存在的索引/集合补齐命名空间字段（ES mapping / Qdrant payload 索引）。
+func (c *Config) EnsureNamespaceSchema(ctx context.Context) error {
+	if c.UseES() {
+		return ensureEsNamespaceMapping(ctx, c.Client, c.IndexName)
+	}
+	if c.UseQdrant() {
+		return c.ensureQdrantFieldIndexes(ctx)
+	}
+	// Milvus 命名空间存放在 metadata JSON 中，无需额外 schema
+	return nil
+}
+
+// ensureQdrantFieldIndexes 创建过滤所需的 payload 索引（重复创建为幂等操作）
+func (c *Config) ensureQdrantFieldIndexes(ctx context.Context) error {
+	fields := []struct {
+		name      string
+		fieldType qdrant.FieldType
+	}{
+		{KnowledgeName, qdrant.FieldType_FieldTypeKeyword},
+		{KnowledgeBaseId, qdrant.FieldType_FieldTypeInteger},
+		{UserUUID, qdrant.FieldType_FieldTypeKeyword},
+		{FieldCronID, qdrant.FieldType_FieldTypeKeyword},
+	}
+	for _, f := range fields {
+		_, err := c.QdrantClient.CreateFieldIndex(ctx, &qdrant.CreateFieldIndexCollection{
+			CollectionName: c.IndexName,
+			FieldName:      f.name,
+			FieldType:      f.fieldType.Enum(),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to create field index %s: %w", f.name, err)
+		}
+	}
+	return nil
+}
+
+// BackfillNamespace 为旧数据回填命名空间：将 _knowledge_name 等于 knowledgeName 的所有点写入知识库 ID 与用户 UUID。
+// 调用方需保证 knowledgeName 在全部用户中唯一，否则无法判断旧数据归属。
+func (c *Config) BackfillNamespace(ctx context.Context, knowledgeName string, ns Namespace) (err error) {
+	if !ns.IsValid() {
+		return fmt.Errorf("backfill namespace failed: invalid namespace (%s)", ns)
+	}
+	if c.UseES() {
+		return c.backfillNamespaceES(ctx, knowledgeName, ns)
+	}
+	if c.UseQdrant() {
+		_, err = c.QdrantClient.SetPayload(ctx, &qdrant.SetPayloadPoints{
+			CollectionName: c.IndexName,
+			Wait:           TypeOf(true),
+			Payload: qdrant.NewValueMap(map[string]any{
+				KnowledgeBaseId: ns.KnowledgeBaseId,
+				UserUUID:        ns.UserUUID,
+			}),
+			PointsSelector: qdrant.NewPointsSelectorFilter(&qdrant.Filter{
+				Must: []*qdrant.Condition{qdrant.NewMatchKeyword(KnowledgeName, knowledgeName)},
+			}),
+		})
+		if err != nil {
+			return fmt.Errorf("qdrant set payload failed: %w", err)
+		}
+		return nil
+	}
+	if c.UseMilvus() {
+		return c.backfillNamespaceMilvus(ctx, knowledgeName, ns)
+	}
+	return fmt.Errorf("no valid client configuration")
+}
+
+func (c *Config) backfillNamespaceES(ctx context.Context, knowledgeName string, ns Namespace) error {
+	kbID, _ := json.Marshal(ns.KnowledgeBaseId)
+	userUUID, _ := json.Marshal(ns.UserUUID)
+	source := fmt.Sprintf("ctx._source.%s = params.kb_id; ctx._source.%s = params.user_uuid", KnowledgeBaseId, UserUUID)
+	res, err := updatebyquery.NewUpdateByQueryFunc(c.Client)(c.IndexName).
+		Query(&types.Query{Term: map[string]types.TermQuery{KnowledgeName: {Value: knowledgeName}}}).
+		Script(&types.Script{
+			Source: &source,
+			Params: map[string]json.RawMessage{"kb_id": kbID, "user_uuid": userUUID},
+		}).
+		Conflicts(conflicts.Proceed).
+		Refresh(true).
+		Do(ctx)
+	if err != nil {
+		return fmt.Errorf("es update by query failed: %w", err)
+	}
+	if res.Updated != nil {
+		g.Log().Infof(ctx, "BackfillNamespace(es): knowledge=%s %s updated=%d", knowledgeName, ns, *res.Updated)
+	}
+	return nil
+}
+
+// backfillNamespaceMilvus Milvus 不支持局部更新 JSON 字段，需查询整行后改写 metadata 再 upsert
+func (c *Config) backfillNamespaceMilvus(ctx context.Context, knowledgeName string, ns Namespace) error {
+	client, err := c.milvusClient(ctx)
+	if err != nil {
+		return err
+	}
+	// 仅处理尚未回填的数据，upsert 后不再命中过滤条件，循环直到查询为空
+	expr := fmt.Sprintf(`metadata["%s"] == %s && not (%s)`, KnowledgeName, MilvusQuote(knowledgeName), ns.MilvusExpr())
+	total := 0
+	for {
+		rs, err := client.Query(ctx, milvusclient.NewQueryOption(c.IndexName).
+			WithFilter(expr).
+			WithOutputFields("*").
+			WithLimit(milvusBackfillBatch).
+			WithConsistencyLevel(entity.ClStrong))
+		if err != nil {
+			return fmt.Errorf("failed to query milvus: %w", err)
+		}
+		metaCol := rs.GetColumn("metadata")
+		if rs.Len() == 0 || metaCol == nil {
+			break
+		}
+		metas := make([][]byte, 0, metaCol.Len())
+		for i := 0; i < metaCol.Len(); i++ {
+			meta := map[string]any{}
+			if raw, err := metaCol.Get(i); err == nil {
+				if b, ok := raw.([]byte); ok {
+					_ = sonic.Unmarshal(b, &meta)
+				}
+			}
+			meta[KnowledgeBaseId] = ns.KnowledgeBaseId
+			meta[UserUUID] = ns.UserUUID
+			b, err := sonic.Marshal(meta)
+			if err != nil {
+				return fmt.Errorf("marshal metadata failed: %w", err)
+			}
+			metas = append(metas, b)
+		}
+		cols := make([]column.Column, 0, len(rs.Fields))
+		for _, col := range rs.Fields {
+			if col.Name() == "metadata" {
+				cols = append(cols, column.NewColumnJSONBytes("metadata", metas))
+				continue
+			}
+			cols = append(cols, col)
+		}
+		if _, err = client.Upsert(ctx, milvusclient.NewColumnBasedInsertOption(c.IndexName, cols...)); err != nil {
+			return fmt.Errorf("failed to upsert milvus: %w", err)
+		}
+		total += rs.Len()
+		if rs.Len() < milvusBackfillBatch {
+			break
+		}
+	}
+	g.Log().Infof(ctx, "BackfillNamespace(milvus): knowledge=%s %s updated=%d", knowledgeName, ns, total)
+	return nil
+}
@@ -0,0 +1,89 @@
+package common
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/elastic/go-elasticsearch/v8"
+)
+
+func TestNamespaceFilters(t *testing.T) {
+	// 三种引擎都同时按知识库 ID 与用户 UUID 过滤，用户 UUID 中的引号不能破坏 Milvus 表达式
+	ns := Namespace{KnowledgeBaseId: 7, UserUUID: `u"1`}
+	if got, want := ns.MilvusExpr(), `metadata["knowledge_base_id"] == 7 && metadata["user_uuid"] == "u\"1"`; got != want {
+		t.Fatalf("Milvus 表达式 %s，期望 %s", got, want)
+	}
+
+	es := ns.ESQueries()
+	if len(es) != 2 || es[0].Term[KnowledgeBaseId].Value != int64(7) || es[1].Term[UserUUID].Value != `u"1` {
+		t.Fatalf("ES 过滤条件不正确: %+v", es)
+	}
+
+	conds := ns.QdrantConditions()
+	if len(conds) != 2 {
+		t.Fatalf("Qdrant 过滤条件数 %d", len(conds))
+	}
+	kb, user := conds[0].GetField(), conds[1].GetField()
+	if kb.GetKey() != KnowledgeBaseId || kb.GetMatch().GetInteger() != 7 ||
+		user.GetKey() != UserUUID || user.GetMatch().GetKeyword() != `u"1` {
+		t.Fatalf("Qdrant 过滤条件不正确: %v / %v", kb, user)
+	}
+
+	if got := NamespaceFromContext(WithNamespace(context.Background(), ns)); got != ns {
+		t.Fatalf("context 往返后为 %+v", got)
+	}
+	for _, invalid := range []Namespace{{}, {KnowledgeBaseId: 7}, {UserUUID: "u1"}} {
+		if invalid.IsValid() {
+			t.Fatalf("%+v 不应视为有效命名空间", invalid)
+		}
+	}
+}
+
+func TestBackfillNamespaceES(t *testing.T) {
+	// 旧数据按 _knowledge_name 匹配，经 update_by_query 写入知识库 ID 与用户 UUID
+	var path string
+	var body struct {
+		Query struct {
+			Term map[string]struct {
+				Value string `json:"value"`
+			} `json:"term"`
+		} `json:"query"`
+		Script struct {
+			Params map[string]any `json:"params"`
+		} `json:"script"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		raw, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(raw, &body)
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"updated":3,"failures":[]}`))
+	}))
+	defer srv.Close()
+	client, err := elasticsearch.NewClient(elasticsearch.Config{Addresses: []string{srv.URL}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	conf := &Config{Client: client, IndexName: "rag"}
+
+	if err = conf.BackfillNamespace(context.Background(), "算法", Namespace{KnowledgeBaseId: 7}); err == nil {
+		t.Fatal("命名空间缺少用户时应拒绝回填")
+	}
+	if err = conf.BackfillNamespace(context.Background(), "算法", Namespace{KnowledgeBaseId: 7, UserUUID: "u1"}); err != nil {
+		t.Fatal(err)
+	}
+	if path != "/rag/_update_by_query" {
+		t.Fatalf("请求路径 %s", path)
+	}
+	if body.Query.Term[KnowledgeName].Value != "算法" {
+		t.Fatalf("应按知识库名称匹配旧数据: %+v", body.Query)
+	}
+	if body.Script.Params["kb_id"] != float64(7) || body.Script.Params["user_uuid"] != "u1" {
+		t.Fatalf("回填参数不正确: %+v", body.Script.Params)
+	}
+}
@@ -2,6 +2,7 @@ package common
 
 import (
 	"context"
+	"errors"
 	"io"
 	"strings"
 	"time"
@@ -9,12 +10,10 @@ import (
 	"github.com/bytedance/sonic"
 	"github.com/cloudwego/eino/compose"
 	"github.com/cloudwego/eino/flow/agent"
-	"github.com/cloudwego/eino/flow/agent/react"
 	"github.com/cloudwego/eino/schema"
-	"github.com/gogf/gf/v2/errors/gcode"
-	"github.com/gogf/gf/v2/errors/gerror"
 	"github.com/gogf/gf/v2/frame/g"
 	"github.com/gogf/gf/v2/net/ghttp"
+	"github.com/gogf/gf/v2/os/gctx"
 	"github.com/google/uuid"
 )
 
@@ -49,87 +48,90 @@ type StreamData struct {
 	Document         []*schema.Document `json:"document"`
 }
 
+// StreamTurn 本轮对话的消息 ID，流开始时以 turn 事件下发，供前端停止生成、重新生成与切换版本
+type StreamTurn struct {
+	SessionId   string `json:"session_id"`
+	TurnId      string `json:"turn_id"`
+	MsgId       string `json:"msg_id"`
+	ReplyMsgId  string `json:"reply_msg_id"`
+	ParentMsgId string `json:"parent_msg_id"`
+}
+
+type streamTurnKey struct{}
+
+// WithStreamTurn 将本轮消息 ID 写入 ctx，StreamResponse 开始时下发
+func WithStreamTurn(ctx context.Context, turn *StreamTurn) context.Context {
+	return context.WithValue(ctx, streamTurnKey{}, turn)
+}
+
 // ToolStatusData 工具执行状态，用于前端展示「正在执行 XXX」提示
 type ToolStatusData struct {
 	Tool string `json:"tool"` // 工具名，如 skill、web_search、read_file
 	Name string `json:"name"` // 具体操作，如 high-eq-communication、skill 的 skill 参数
 }
 
+// StreamResponse 以 SSE 输出模型流。事件先写入本轮缓冲（递增序号，同步到 Redis），再推送给客户端；
+// 生成不随连接断开而中止，客户端可携带 Last-Event-ID 续传。streamReader 由本函数负责关闭
 func StreamResponse(ctx context.Context, streamReader *schema.StreamReader[*schema.Message], docs []*schema.Document) (err error) {
-	// 获取HTTP响应对象
-	httpReq := ghttp.RequestFromCtx(ctx)
-	httpResp := httpReq.Response
-	// 设置响应头
-	httpResp.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
-	httpResp.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
-	httpResp.Header().Set("Pragma", "no-cache")
-	httpResp.Header().Set("Expires", "0")
-	httpResp.Header().Set("Connection", "keep-alive")
-	httpResp.Header().Set("X-Accel-Buffering", "no") // 禁用Nginx缓冲
-	httpResp.Header().Set("X-Content-Type-Options", "nosniff")
-
-	// 立即发送响应头
-	httpResp.WriteHeader(200)
+	turn, ok := ctx.Value(streamTurnKey{}).(*StreamTurn)
+	if !ok {
+		turn = &StreamTurn{TurnId: uuid.NewString()}
+	}
+	stream := newTurnStream(turn.SessionId, turn.TurnId)
+	go produceStream(gctx.NeverDone(ctx), stream, streamReader, docs, turn)
+	return ServeTurnStream(ctx, turn.SessionId, turn.TurnId, 0)
+}
+
+// produceStream 读取模型流并转为 SSE 事件写入缓冲，直到流结束、出错或轮次被取消
+func produceStream(ctx context.Context, stream *turnStream, streamReader *schema.StreamReader[*schema.Message], docs []*schema.Document, turn *StreamTurn) {
+	defer streamReader.Close()
+	defer stream.emit(ctx, eventDone, "")
+
 	sd := &StreamData{
 		Id:      uuid.NewString(),
 		Created: time.Now().Unix(),
 	}
+	if b, _ := sonic.Marshal(turn); len(b) > 0 {
+		stream.emit(ctx, eventTurn, string(b))
+	}
 	if len(docs) > 0 {
 		sd.Document = docs
 		marshal, _ := sonic.Marshal(sd)
-		writeSSEDocuments(httpResp, string(marshal))
+		stream.emit(ctx, eventDocuments, string(marshal))
 	}
 	sd.Document = nil // 置空，发一次就够了
 
 	// 用于跟踪已发送的内容长度，实现增量发送
 	var fullContent string
 	var fullReasoning string
-
-	// ======================================
-	// 新增：客户端断开监听 + 心跳保活
-	// ======================================
-	// 心跳定时器：每15秒发送一次ping，防止长连接被中间节点断开
-	heartbeatTicker := time.NewTicker(15 * time.Second)
-	defer heartbeatTicker.Stop()
-
-	// 处理协程：监听上下文取消（客户端断开）和心跳
-	done := make(chan struct{})
-	defer close(done)
-
-	go func() {
-		for {
-			select {
-			case <-ctx.Done():
-				// 客户端断开连接，立即关闭streamReader释放资源
-				streamReader.Close()
-				g.Log().Infof(ctx, "[Stream] 客户端断开连接，已终止流式响应")
-				return
-			case <-heartbeatTicker.C:
-				// 发送心跳ping事件
-				writeSSEPing(httpResp)
-			case <-done:
-				return
-			}
-		}
-	}()
+	// 有参考资料时校验引用标记，删除指向不存在来源的 [n]
+	var citations *CitationFilter
+	if len(docs) > 0 {
+		citations = NewCitationFilter(docs)
+	}
 
 	// 处理流式响应
 	for {
-		select {
-		case <-ctx.Done():
-			// 客户端断开，直接返回
-			return ctx.Err()
-		default:
-		}
-
 		chunk, err := streamReader.Recv()
 		if err == io.EOF {
 			break
 		}
+		if err != nil && errors.Is(err, context.Canceled) {
+			// 轮次被主动停止
+			stream.emit(ctx, eventCancelled, "{}")
+			break
+		}
+		var approval *ApprovalRequiredError
+		if err != nil && errors.As(err, &approval) {
+			if b, _ := sonic.Marshal(approval.Approval); len(b) > 0 {
+				stream.emit(ctx, eventApproval, string(b))
+			}
+			break
+		}
 		if err != nil {
 			// 错误脱敏处理，不泄露内部信息
 			g.Log().Error(ctx, "流式响应错误：", err)
-			writeSSEError(httpResp, gerror.NewCode(gcode.New(500, "响应生成失败，请稍后重试", nil)))
+			stream.emit(ctx, eventError, "响应生成失败，请稍后重试")
 			break
 		}
 
@@ -147,8 +149,7 @@ func StreamResponse(ctx context.Context, streamReader *schema.StreamReader[*sche
 				displayName := toolDisplayName(tc)
 				ts := &ToolStatusData{Tool: tc.Function.Name, Name: displayName}
 				if b, _ := sonic.Marshal(ts); len(b) > 0 {
-					writeSSEToolStatus(httpResp, string(b))
-					httpResp.Flush()
+					stream.emit(ctx, eventToolStatus, string(b))
 				}
 			}
 			// 纯工具调用 chunk（无正文内容）：重置累计内容，为下一轮 LLM 回复做准备
@@ -165,44 +166,24 @@ func StreamResponse(ctx context.Context, streamReader *schema.StreamReader[*sche
 		var contentToSend string
 		var reasoningToSend string
 
-		// 处理 Content（与原有逻辑一致）
+		// 处理 Content 与 ReasoningContent（思考过程，流式增量）
 		if hasContent {
-			if len(chunk.Content) > len(fullContent) && len(fullContent) > 0 {
-				if chunk.Content[:len(fullContent)] == fullContent {
-					contentToSend = chunk.Content[len(fullContent):]
-					fullContent = chunk.Content
-				} else {
-					contentToSend = chunk.Content
-					fullContent += chunk.Content
-				}
-			} else {
-				contentToSend = chunk.Content
-				fullContent += chunk.Content
-			}
+			contentToSend = StreamDelta(&fullContent, chunk.Content)
 		}
-
-		// 处理 ReasoningContent（思考过程，流式增量）
 		if hasReasoning {
-			if len(chunk.ReasoningContent) > len(fullReasoning) && len(fullReasoning) > 0 {
-				if chunk.ReasoningContent[:len(fullReasoning)] == fullReasoning {
-					reasoningToSend = chunk.ReasoningContent[len(fullReasoning):]
-					fullReasoning = chunk.ReasoningContent
-				} else {
-					reasoningToSend = chunk.ReasoningContent
-					fullReasoning += chunk.ReasoningContent
-				}
-			} else {
-				reasoningToSend = chunk.ReasoningContent
-				fullReasoning += chunk.ReasoningContent
-			}
+			reasoningToSend = StreamDelta(&fullReasoning, chunk.ReasoningContent)
+		}
+
+		if citations != nil {
+			contentToSend = citations.Push(contentToSend)
 		}
 
 		// 回答内容 / 思考过程：拆成小段模拟流式输出
 		if len(contentToSend) > 0 {
-			sendSSEStreamed(httpResp, sd, contentToSend, contentChunkSize, contentChunkIntervalMs, streamFieldContent)
+			sendSSEStreamed(ctx, stream, sd, contentToSend, contentChunkSize, contentChunkIntervalMs, streamFieldContent)
 		}
 		if len(reasoningToSend) > 0 {
-			sendSSEStreamed(httpResp, sd, reasoningToSend, reasoningChunkSize, reasoningChunkIntervalMs, streamFieldReasoning)
+			sendSSEStreamed(ctx, stream, sd, reasoningToSend, reasoningChunkSize, reasoningChunkIntervalMs, streamFieldReasoning)
 		}
 	}
 	// 兜底：若最终内容以「正在...」类过渡句结尾，说明模型可能在工具调用后返回空，追加友好提示
@@ -211,13 +192,31 @@ func StreamResponse(ctx context.Context, streamReader *schema.StreamReader[*sche
 		endsWithEllipsis := strings.HasSuffix(trimmed, "...") || strings.HasSuffix(trimmed, "…")
 		hasTransition := strings.Contains(trimmed, "正在检查") || strings.Contains(trimmed, "正在保存") || strings.Contains(trimmed, "让我检查")
 		if endsWithEllipsis && hasTransition {
-			sendSSEStreamed(httpResp, sd, "处理已完成，可继续对话。", contentChunkSize, contentChunkIntervalMs, streamFieldContent)
+			sendSSEStreamed(ctx, stream, sd, "处理已完成，可继续对话。", contentChunkSize, contentChunkIntervalMs, streamFieldContent)
 			g.Log().Infof(context.Background(), "[Stream] 检测到工具过渡句后流结束，已追加兜底提示")
 		}
 	}
-	// 发送结束事件
-	writeSSEDone(httpResp)
-	return nil
+	if citations != nil {
+		if rest := citations.Flush(); rest != "" {
+			sendSSEStreamed(ctx, stream, sd, rest, contentChunkSize, 0, streamFieldContent)
+		}
+		if used := citations.Citations(); len(used) > 0 {
+			if b, _ := sonic.Marshal(used); len(b) > 0 {
+				stream.emit(ctx, eventCitations, string(b))
+			}
+		}
+	}
+}
+
+// StreamDelta 计算分片中的新增文本并累加到 full：部分模型的分片为截至当前的完整文本，以 full 为前缀时只取新增部分
+func StreamDelta(full *string, text string) string {
+	if len(text) > len(*full) && len(*full) > 0 && text[:len(*full)] == *full {
+		delta := text[len(*full):]
+		*full = text
+		return delta
+	}
+	*full += text
+	return text
 }
 
 // streamedField 表示本次按 rune 切片写入 StreamData 的字段（正文或思考）。
@@ -228,9 +227,9 @@ const (
 	streamFieldReasoning
 )
 
-// sendSSEStreamed 将一段文本按 rune 切分后逐段写入 SSE，模拟打字机流式效果。
+// sendSSEStreamed 将一段文本按 rune 切分后逐段写入事件缓冲，模拟打字机流式效果。
 // 火山 Ark 等可能一次性返回完整 reasoning_content，此处统一按字符拆分发送。
-func sendSSEStreamed(resp *ghttp.Response, sd *StreamData, text string, chunkSize, intervalMs int, field streamedField) {
+func sendSSEStreamed(ctx context.Context, stream *turnStream, sd *StreamData, text string, chunkSize, intervalMs int, field streamedField) {
 	runes := []rune(text)
 	for i := 0; i < len(runes); i += chunkSize {
 		end := i + chunkSize
@@ -247,70 +246,32 @@ func sendSSEStreamed(resp *ghttp.Response, sd *StreamData, text string, chunkSiz
 			sd.ReasoningContent = ""
 		}
 		marshal, _ := sonic.Marshal(sd)
-		writeSSEData(resp, string(marshal))
-		resp.Flush()
+		stream.emit(ctx, eventData, string(marshal))
 		if intervalMs > 0 {
 			time.Sleep(time.Duration(intervalMs) * time.Millisecond)
 		}
 	}
 }
 
-// writeSSEData 写入SSE事件
-func writeSSEData(resp *ghttp.Response, data string) {
-	if len(data) == 0 {
-		return
-	}
-	// 直接写入，避免fmt.Sprintf的开销
-	resp.Write([]byte("data:"))
-	resp.Write([]byte(data))
-	resp.Write([]byte("\n\n"))
+// writeSSEHeaders 设置 SSE 响应头并立即发送
+func writeSSEHeaders(resp *ghttp.Response) {
+	resp.Header().Set("Content-Type", "text/event-stream; charset=utf-8")
+	resp.Header().Set("Cache-Control", "no-cache, no-store, must-revalidate")
+	resp.Header().Set("Pragma", "no-cache")
+	resp.Header().Set("Expires", "0")
+	resp.Header().Set("Connection", "keep-alive")
+	resp.Header().Set("X-Accel-Buffering", "no") // 禁用Nginx缓冲
+	resp.Header().Set("X-Content-Type-Options", "nosniff")
+	resp.WriteHeader(200)
 	resp.Flush()
 }
 
-func writeSSEDone(resp *ghttp.Response) {
-	resp.Write([]byte("data:[DONE]\n\n"))
-	resp.Flush()
-}
-
-func writeSSEDocuments(resp *ghttp.Response, data string) {
-	resp.Write([]byte("documents:"))
-	resp.Write([]byte(data))
-	resp.Write([]byte("\n\n"))
-	resp.Flush()
-}
-
-// writeSSEToolStatus 写入工具执行状态事件，前端可展示「正在执行 XXX」
-func writeSSEToolStatus(resp *ghttp.Response, data string) {
-	if len(data) == 0 {
-		return
-	}
-	resp.Write([]byte("event: tool_status\n"))
-	resp.Write([]byte("data:"))
-	resp.Write([]byte(data))
-	resp.Write([]byte("\n\n"))
-	resp.Flush()
-}
-
-// writeSSEError 写入SSE错误
 // writeSSEPing 发送心跳ping事件，保持长连接存活
 func writeSSEPing(resp *ghttp.Response) {
 	resp.Write([]byte("event: ping\ndata: {}\n\n"))
 	resp.Flush()
 }
 
-func writeSSEError(resp *ghttp.Response, err error) {
-	g.Log().Error(context.Background(), err)
-	// 错误脱敏，只返回错误码和友好提示
-	errMsg := err.Error()
-	if gerror.Code(err).Code() >= 500 {
-		errMsg = "服务暂时不可用，请稍后重试"
-	}
-	resp.Write([]byte("event: error\ndata: "))
-	resp.Write([]byte(errMsg))
-	resp.Write([]byte("\n\n"))
-	resp.Flush()
-}
-
 // --- React Agent 流式工具调用（CoachChat / NormalChat 共用）---
 
 // toolCallNotify 工具调用通知，含 Name 与 Arguments，供 toolDisplayName 展示如 skill(emotion-companion)
@@ -343,10 +304,10 @@ func BuildNotifyMiddleware() compose.ToolMiddleware {
 	}
 }
 
-// BuildGenToStream 解决 Eino 0.8.4 中 ins.Stream 无法传递工具调用后内容的问题。
+// genToStream 解决 Eino 0.8.4 中 ins.Stream 无法传递工具调用后内容的问题。
 // 使用 schema.Pipe + goroutine 实现完整的多轮次流输出，中间件通过 channel 通知 SSE 层。
-// 供 react.Agent 模块使用。
-func BuildGenToStream(ins *react.Agent) func(context.Context, []*schema.Message, ...agent.AgentOption) (*schema.StreamReader[*schema.Message], error) {
+// 供 BuildAgentLambda 与 ResumeAgent 使用。
+func genToStream(generate func(context.Context, []*schema.Message, ...agent.AgentOption) (*schema.Message, error)) func(context.Context, []*schema.Message, ...agent.AgentOption) (*schema.StreamReader[*schema.Message], error) {
 	return func(genCtx context.Context, msgs []*schema.Message, opts ...agent.AgentOption) (*schema.StreamReader[*schema.Message], error) {
 		sr, sw := schema.Pipe[*schema.Message](20)
 
@@ -377,7 +338,7 @@ func BuildGenToStream(ins *react.Agent) func(context.Context, []*schema.Message,
 			}()
 
 			// 同步跑完所有工具轮次，获取最终回复
-			finalMsg, genErr := ins.Generate(genCtx, msgs, opts...)
+			finalMsg, genErr := generate(genCtx, msgs, opts...)
 
 			// 通知通知 goroutine 结束，等待其写完最后的 tool_status
 			close(notifyChan)
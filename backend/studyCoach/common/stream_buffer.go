@@ -0,0 +1,247 @@
+package common
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bytedance/sonic"
+	_ "github.com/gogf/gf/contrib/nosql/redis/v2"
+	"github.com/gogf/gf/v2/errors/gcode"
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/net/ghttp"
+)
+
+// SSE 事件类型，空字符串为普通 data 事件
+const (
+	eventData       = ""
+	eventTurn       = "turn"
+	eventDocuments  = "documents"
+	eventToolStatus = "tool_status"
+	eventCitations  = "citations"              // 回答结束时下发实际出现的引用标记及其来源
+	eventApproval   = "tool_approval_required" // 工具等待用户审批，本轮在此结束，答复后以新轮次续写
+	eventCancelled  = "cancelled"
+	eventError      = "error"
+	eventDone       = "done" // 结束事件，写出为 data:[DONE]
+)
+
+const (
+	defaultStreamTTL  = 10 * time.Minute // 轮次结束后事件缓冲的保留时间
+	defaultTurnTTL    = 10 * time.Minute // 轮次进行中缓冲的最长时间（与 chat.turnTimeout 一致）
+	streamPollEvery   = 300 * time.Millisecond
+	streamKeyPrefix   = "chat:stream:"
+	heartbeatInterval = 15 * time.Second
+)
+
+// streamEvent 缓冲中的一条 SSE 事件，Seq 从 1 递增，与轮次 ID 组成 SSE id（turnId:seq）
+type streamEvent struct {
+	Seq   int64  `json:"seq"`
+	Event string `json:"event,omitempty"`
+	Data  string `json:"data"`
+}
+
+// turnStream 单轮的事件缓冲：本实例内存中保留全部事件供实时推送，同时追加到 Redis 列表，
+// 供客户端断线重连或请求落到其他实例时回放
+type turnStream struct {
+	key string
+
+	mu       sync.Mutex
+	events   []streamEvent
+	finished bool
+	notify   chan struct{} // 有新事件时关闭并替换，唤醒等待的读取方
+
+	redisFailed bool // Redis 写入失败后只记一次日志，仍可从内存读取
+}
+
+// localStreams 本实例产生的轮次缓冲：streamKey -> *turnStream
+var localStreams sync.Map
+
+func streamKey(sessionId, turnId string) string {
+	return streamKeyPrefix + sessionId + ":" + turnId
+}
+
+func streamTTL(ctx context.Context) time.Duration {
+	return g.Cfg().MustGet(ctx, "chat.streamTTL", defaultStreamTTL).Duration()
+}
+
+// newTurnStream 创建并登记轮次缓冲
+func newTurnStream(sessionId, turnId string) *turnStream {
+	s := &turnStream{key: streamKey(sessionId, turnId), notify: make(chan struct{})}
+	localStreams.Store(s.key, s)
+	return s
+}
+
+// emit 追加一条事件；done 事件后缓冲结束，到期后从内存与 Redis 中清除
+func (s *turnStream) emit(ctx context.Context, event, data string) {
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	ev := streamEvent{Seq: int64(len(s.events)) + 1, Event: event, Data: data}
+	s.events = append(s.events, ev)
+	s.finished = event == eventDone
+	close(s.notify)
+	s.notify = make(chan struct{})
+	s.mu.Unlock()
+
+	s.persist(ctx, ev)
+	if ev.Event == eventDone {
+		time.AfterFunc(streamTTL(ctx), func() { localStreams.CompareAndDelete(s.key, s) })
+	}
+}
+
+// persist 追加到 Redis 列表并刷新过期时间：进行中按轮次最长时间 + 保留时间，结束后按保留时间
+func (s *turnStream) persist(ctx context.Context, ev streamEvent) {
+	redis := g.Redis()
+	if redis == nil || s.redisFailed {
+		return
+	}
+	b, _ := sonic.Marshal(ev)
+	ttl := streamTTL(ctx)
+	if ev.Seq == 1 {
+		ttl += g.Cfg().MustGet(ctx, "chat.turnTimeout", defaultTurnTTL).Duration()
+	}
+	_, err := redis.RPush(ctx, s.key, b)
+	if err == nil && (ev.Seq == 1 || ev.Event == eventDone) {
+		_, err = redis.Expire(ctx, s.key, int64(ttl.Seconds()))
+	}
+	if err != nil {
+		s.redisFailed = true
+		g.Log().Warningf(ctx, "[Stream] 事件缓冲写入 Redis 失败，仅本实例可续传: key=%s, 错误: %v", s.key, err)
+	}
+}
+
+// since 返回序号大于 offset 的事件、缓冲是否已结束，以及新事件到达时关闭的通道
+func (s *turnStream) since(offset int64) ([]streamEvent, bool, <-chan struct{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var events []streamEvent
+	if offset < int64(len(s.events)) {
+		events = append(events, s.events[max(offset, 0):]...)
+	}
+	return events, s.finished, s.notify
+}
+
+// loadStreamEvents 从 Redis 读取序号大于 offset 的事件
+func loadStreamEvents(ctx context.Context, key string, offset int64) ([]streamEvent, error) {
+	redis := g.Redis()
+	if redis == nil {
+		return nil, nil
+	}
+	vars, err := redis.LRange(ctx, key, max(offset, 0), -1)
+	if err != nil {
+		return nil, err
+	}
+	events := make([]streamEvent, 0, len(vars))
+	for _, v := range vars {
+		var ev streamEvent
+		if err := sonic.Unmarshal(v.Bytes(), &ev); err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+	return events, nil
+}
+
+// streamExists 轮次缓冲是否存在（本实例内存或 Redis）
+func streamExists(ctx context.Context, key string) bool {
+	if _, ok := localStreams.Load(key); ok {
+		return true
+	}
+	redis := g.Redis()
+	if redis == nil {
+		return false
+	}
+	n, err := redis.Exists(ctx, key)
+	return err == nil && n > 0
+}
+
+// ParseLastEventId 解析 SSE id（turnId:seq），返回轮次 ID 与已收到的事件序号
+func ParseLastEventId(id string) (turnId string, offset int64, ok bool) {
+	i := strings.LastIndex(id, ":")
+	if i <= 0 {
+		return "", 0, false
+	}
+	offset, err := strconv.ParseInt(id[i+1:], 10, 64)
+	if err != nil || offset < 0 {
+		return "", 0, false
+	}
+	return id[:i], offset, true
+}
+
+// ServeTurnStream 以 SSE 推送轮次中序号大于 offset 的事件，之后继续实时推送直到轮次结束或客户端断开。
+// 缓冲不存在（未开始或已过期）时返回 404，此时尚未写出响应头
+func ServeTurnStream(ctx context.Context, sessionId, turnId string, offset int64) error {
+	key := streamKey(sessionId, turnId)
+	if !streamExists(ctx, key) {
+		return gerror.NewCode(gcode.New(404, "回复不存在或已过期", nil))
+	}
+	resp := ghttp.RequestFromCtx(ctx).Response
+	writeSSEHeaders(resp)
+
+	heartbeat := time.NewTicker(heartbeatInterval)
+	defer heartbeat.Stop()
+	deadline := time.Now().Add(g.Cfg().MustGet(ctx, "chat.turnTimeout", defaultTurnTTL).Duration() + streamTTL(ctx))
+	for {
+		var (
+			events   []streamEvent
+			finished bool
+			wait     <-chan struct{}  // 本实例缓冲：新事件到达
+			poll     <-chan time.Time // 其他实例的缓冲：定时轮询
+		)
+		if v, ok := localStreams.Load(key); ok {
+			events, finished, wait = v.(*turnStream).since(offset)
+		} else {
+			// 轮次在其他实例生成：轮询 Redis
+			var err error
+			if events, err = loadStreamEvents(ctx, key, offset); err != nil {
+				g.Log().Errorf(ctx, "[Stream] 读取事件缓冲失败: key=%s, 错误: %v", key, err)
+				return nil
+			}
+			if len(events) == 0 && (!streamExists(ctx, key) || time.Now().After(deadline)) {
+				return nil
+			}
+			finished = len(events) > 0 && events[len(events)-1].Event == eventDone
+			poll = time.After(streamPollEvery)
+		}
+		for _, ev := range events {
+			writeStreamEvent(resp, turnId, ev)
+			offset = ev.Seq
+		}
+		if finished {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			g.Log().Infof(ctx, "[Stream] 客户端断开连接，生成继续进行，可携带 Last-Event-ID 续传: turn=%s, seq=%d", turnId, offset)
+			return nil
+		case <-heartbeat.C:
+			writeSSEPing(resp)
+		case <-wait:
+		case <-poll:
+		}
+	}
+}
+
+// writeStreamEvent 写出一条带 id 的 SSE 事件
+func writeStreamEvent(resp *ghttp.Response, turnId string, ev streamEvent) {
+	resp.Write([]byte("id: " + turnId + ":" + strconv.FormatInt(ev.Seq, 10) + "\n"))
+	switch ev.Event {
+	case eventData:
+		resp.Write([]byte("data:" + ev.Data))
+	case eventDone:
+		resp.Write([]byte("data:[DONE]"))
+	case eventDocuments:
+		resp.Write([]byte("documents:" + ev.Data))
+	case eventError:
+		resp.Write([]byte("event: error\ndata: " + ev.Data))
+	default:
+		resp.Write([]byte("event: " + ev.Event + "\ndata:" + ev.Data))
+	}
+	resp.Write([]byte("\n\n"))
+	resp.Flush()
+}
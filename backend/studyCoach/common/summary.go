@@ -0,0 +1,28 @@
+package common
+
+import (
+	"context"
+
+	"github.com/cloudwego/eino/schema"
+)
+
+// SummaryMessages 较早对话的滚动摘要，注入模板的 summary 占位；无摘要时返回空列表（不能为 nil）
+func SummaryMessages(summary string) []*schema.Message {
+	if summary == "" {
+		return []*schema.Message{}
+	}
+	return []*schema.Message{schema.SystemMessage("以下是本次对话较早内容的摘要，供理解上下文参考：\n" + summary)}
+}
+
+type chatHistoryKey struct{}
+
+// WithChatHistory 由调用方提供对话历史（如 OpenAI 兼容接口请求中的 messages），生成时不再从会话读取记忆
+func WithChatHistory(ctx context.Context, history []*schema.Message) context.Context {
+	return context.WithValue(ctx, chatHistoryKey{}, history)
+}
+
+// ChatHistoryFromContext 读取调用方提供的对话历史，未提供时 ok 为 false
+func ChatHistoryFromContext(ctx context.Context) (history []*schema.Message, ok bool) {
+	history, ok = ctx.Value(chatHistoryKey{}).([]*schema.Message)
+	return history, ok
+}
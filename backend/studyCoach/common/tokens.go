@@ -0,0 +1,14 @@
+package common
+
+// EstimateTokens 粗略估算 token 数：中日韩字符约 1 字 1 token，其余约 4 字符 1 token
+func EstimateTokens(s string) int {
+	cjk, other := 0, 0
+	for _, r := range s {
+		if r >= 0x2E80 {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/elastic/go-elasticsearch/v8"
 	"github.com/gogf/gf/v2/frame/g"
@@ -73,10 +74,7 @@ func BuildVectorConfig(ctx context.Context) (*Config, error) {
 		}
 		// qdrant go-client 使用 gRPC，address 格式如 localhost:6334
 		host, port := parseHostPort(address.String(), "localhost", 6334)
-		qdrantClient, err := qdrant.NewClient(&qdrant.Config{
-			Host: host,
-			Port: port,
-		})
+		qdrantClient, err := cachedQdrantClient(host, port)
 		if err != nil {
 			return nil, fmt.Errorf("qdrant client init failed: %w", err)
 		}
@@ -104,7 +102,7 @@ func BuildVectorConfig(ctx context.Context) (*Config, error) {
 			Username: username.String(),
 			Password: password.String(),
 		}
-		milvusClient, err := milvusclient.New(ctx, milvusConfig)
+		milvusClient, err := cachedMilvusClient(ctx, milvusConfig)
 		if err != nil {
 			return nil, fmt.Errorf("milvus client init failed: %w", err)
 		}
@@ -118,6 +116,44 @@ func BuildVectorConfig(ctx context.Context) (*Config, error) {
 	}
 }
 
+// 按连接参数缓存的 Qdrant / Milvus 客户端：BuildVectorConfig 在请求中反复调用，
+// 客户端各自持有 gRPC 连接，同一连接参数在进程内只创建一个并一直复用
+var (
+	vectorClientsMu sync.Mutex
+	qdrantClients   = map[string]*qdrant.Client{}
+	milvusClients   = map[string]*milvusclient.Client{}
+)
+
+func cachedQdrantClient(host string, port int) (*qdrant.Client, error) {
+	key := fmt.Sprintf("%s:%d", host, port)
+	vectorClientsMu.Lock()
+	defer vectorClientsMu.Unlock()
+	if client, ok := qdrantClients[key]; ok {
+		return client, nil
+	}
+	client, err := qdrant.NewClient(&qdrant.Config{Host: host, Port: port})
+	if err != nil {
+		return nil, err
+	}
+	qdrantClients[key] = client
+	return client, nil
+}
+
+func cachedMilvusClient(ctx context.Context, conf *milvusclient.ClientConfig) (*milvusclient.Client, error) {
+	key := strings.Join([]string{conf.Address, conf.Username, conf.Password, conf.DBName, conf.APIKey}, "\x00")
+	vectorClientsMu.Lock()
+	defer vectorClientsMu.Unlock()
+	if client, ok := milvusClients[key]; ok {
+		return client, nil
+	}
+	client, err := milvusclient.New(ctx, conf)
+	if err != nil {
+		return nil, err
+	}
+	milvusClients[key] = client
+	return client, nil
+}
+
 func parseHostPort(addr, defaultHost string, defaultPort int) (string, int) {
 	if addr == "" {
 		return defaultHost, defaultPort
@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/aws/smithy-go/ptr"
+	"github.com/cloudwego/eino-ext/components/model/ark"
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/gogf/gf/v2/encoding/gjson"
+	modelThink "github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
+)
+
+// clientSpec 创建客户端所需的全部参数，序列化后作为缓存键；配置变更后自动使用新客户端
+type clientSpec struct {
+	Provider         Provider
+	Model            string
+	Temperature      *float32
+	TopP             *float32
+	FrequencyPenalty *float32
+	PresencePenalty  *float32
+	Thinking         *bool
+}
+
+var clients sync.Map // 缓存键 -> model.ToolCallingChatModel
+
+// client 返回候选模型的客户端，相同参数复用同一实例
+func client(ctx context.Context, c candidate, route *Route, o *options) (model.ToolCallingChatModel, error) {
+	spec := clientSpec{
+		Provider:         c.provider,
+		Model:            c.model,
+		Temperature:      route.Temperature,
+		TopP:             route.TopP,
+		FrequencyPenalty: route.FrequencyPenalty,
+		PresencePenalty:  route.PresencePenalty,
+		Thinking:         route.Thinking,
+	}
+	if o.thinking != nil {
+		spec.Thinking = o.thinking
+	}
+	key := gjson.MustEncodeString(spec)
+	if cm, ok := clients.Load(key); ok {
+		return cm.(model.ToolCallingChatModel), nil
+	}
+	cm, err := newClient(ctx, &spec)
+	if err != nil {
+		return nil, fmt.Errorf("创建模型客户端失败: %s, 错误: %w", c.key(), err)
+	}
+	actual, _ := clients.LoadOrStore(key, cm)
+	return actual.(model.ToolCallingChatModel), nil
+}
+
+func newClient(ctx context.Context, spec *clientSpec) (model.ToolCallingChatModel, error) {
+	p := spec.Provider
+	switch p.Type {
+	case TypeArk:
+		config := &ark.ChatModelConfig{
+			APIKey:           p.APIKey,
+			BaseURL:          p.BaseURL,
+			Model:            spec.Model,
+			Temperature:      spec.Temperature,
+			TopP:             spec.TopP,
+			FrequencyPenalty: spec.FrequencyPenalty,
+			PresencePenalty:  spec.PresencePenalty,
+			RetryTimes:       ptr.Int(0), // 失败由路由切换到备用模型，不在同一模型上重试
+		}
+		if spec.Thinking != nil {
+			config.Thinking = &ark.Thinking{Type: modelThink.ThinkingTypeDisabled}
+			if *spec.Thinking {
+				config.Thinking.Type = modelThink.ThinkingTypeEnabled
+			}
+		}
+		return ark.NewChatModel(ctx, config)
+	case TypeOpenAI, TypeOllama:
+		apiKey := p.APIKey
+		if apiKey == "" && p.Type == TypeOllama {
+			apiKey = TypeOllama // Ollama 不校验密钥，但客户端要求非空
+		}
+		return openai.NewChatModel(ctx, &openai.ChatModelConfig{
+			APIKey:           apiKey,
+			BaseURL:          p.BaseURL,
+			Model:            spec.Model,
+			Temperature:      spec.Temperature,
+			TopP:             spec.TopP,
+			FrequencyPenalty: spec.FrequencyPenalty,
+			PresencePenalty:  spec.PresencePenalty,
+		})
+	default:
+		return nil, fmt.Errorf("不支持的模型提供方类型: %q（支持 openai、ark、ollama）", p.Type)
+	}
+}
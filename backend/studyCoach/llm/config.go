@@ -0,0 +1,140 @@
+// Package llm 提供对话模型的提供方注册、按节点角色的路由与故障切换。
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// 提供方类型
+const (
+	TypeOpenAI = "openai" // OpenAI 兼容接口（SiliconFlow、DeepSeek 等）
+	TypeArk    = "ark"    // 火山引擎 Ark
+	TypeOllama = "ollama" // 本地 Ollama，走其 OpenAI 兼容接口
+)
+
+// 节点角色，对应 llm.routes 下的键
+const (
+	RoleAnalysis  = "analysis"  // CoachChat 意图分析
+	RoleCompanion = "companion" // CoachChat 情感陪伴
+	RoleReact     = "react"     // CoachChat ReAct 答疑
+	RolePlan      = "plan"      // CoachChat 学习计划
+	RoleBranch    = "branch"    // CoachChat 语义路由
+	RoleChat      = "chat"      // NormalChat 普通对话
+	RoleRewrite   = "rewrite"   // 查询改写、纠错检索与记忆摘要
+	RoleQA        = "qa"        // 索引时的 QA 生成与问答
+	RoleCron      = "cron"      // 定时更新知识库
+	RoleASR       = "asr"       // 语音识别结果整理
+)
+
+const (
+	defaultOllamaBaseURL     = "http://localhost:11434/v1"
+	defaultTimeout           = 2 * time.Minute
+	defaultFirstTokenTimeout = 30 * time.Second
+)
+
+// Provider 模型提供方，对应 llm.providers 的一项
+type Provider struct {
+	Name    string `json:"name"`
+	Type    string `json:"type"` // openai | ark | ollama
+	BaseURL string `json:"baseURL"`
+	APIKey  string `json:"apiKey"`
+}
+
+// Route 节点角色的模型路由：首选模型、按顺序尝试的备用模型与采样参数。
+// 模型以「提供方/模型名」表示，模型名本身可以包含 /
+type Route struct {
+	Primary          string   `json:"primary"`
+	Fallbacks        []string `json:"fallbacks"`
+	Temperature      *float32 `json:"temperature"`
+	TopP             *float32 `json:"topP"`
+	FrequencyPenalty *float32 `json:"frequencyPenalty"`
+	PresencePenalty  *float32 `json:"presencePenalty"`
+	Thinking         *bool    `json:"thinking"` // 仅 Ark 生效，不配置时使用模型默认
+}
+
+// candidate 路由解析出的一个可调用模型
+type candidate struct {
+	provider Provider
+	model    string
+}
+
+// key 健康状态与日志使用的标识：提供方/模型名
+func (c candidate) key() string {
+	return c.provider.Name + "/" + c.model
+}
+
+// loadRoute 读取角色的路由配置
+func loadRoute(ctx context.Context, role string) (*Route, error) {
+	v, err := g.Cfg().Get(ctx, "llm.routes."+role)
+	if err != nil || v.IsNil() {
+		return nil, fmt.Errorf("config missing: llm.routes.%s", role)
+	}
+	var route *Route
+	if err = v.Scan(&route); err != nil {
+		return nil, fmt.Errorf("解析模型路由失败: role=%s, 错误: %w", role, err)
+	}
+	if route == nil || route.Primary == "" {
+		return nil, fmt.Errorf("config missing: llm.routes.%s.primary", role)
+	}
+	return route, nil
+}
+
+// loadProviders 读取全部提供方，按名称索引
+func loadProviders(ctx context.Context) (map[string]Provider, error) {
+	var list []Provider
+	if err := g.Cfg().MustGet(ctx, "llm.providers").Scan(&list); err != nil {
+		return nil, fmt.Errorf("解析模型提供方失败: %w", err)
+	}
+	providers := make(map[string]Provider, len(list))
+	for _, p := range list {
+		if p.Type == TypeOllama && p.BaseURL == "" {
+			p.BaseURL = defaultOllamaBaseURL
+		}
+		providers[p.Name] = p
+	}
+	return providers, nil
+}
+
+// resolve 将路由展开为按优先级排列的候选模型
+func resolve(ctx context.Context, route *Route) ([]candidate, error) {
+	providers, err := loadProviders(ctx)
+	if err != nil {
+		return nil, err
+	}
+	refs := append([]string{route.Primary}, route.Fallbacks...)
+	candidates := make([]candidate, 0, len(refs))
+	for _, ref := range refs {
+		name, modelName, ok := strings.Cut(ref, "/")
+		if !ok || modelName == "" {
+			return nil, fmt.Errorf("模型引用格式错误: %q，应为 提供方/模型名", ref)
+		}
+		p, ok := providers[name]
+		if !ok {
+			return nil, fmt.Errorf("模型提供方不存在: %s", name)
+		}
+		candidates = append(candidates, candidate{provider: p, model: modelName})
+	}
+	return candidates, nil
+}
+
+// PrimaryModel 角色首选模型的名称（不含提供方），用于选取对话记忆预算与记录评测配置；未配置时返回空串
+func PrimaryModel(ctx context.Context, role string) string {
+	route, err := loadRoute(ctx, role)
+	if err != nil {
+		return ""
+	}
+	_, modelName, _ := strings.Cut(route.Primary, "/")
+	return modelName
+}
+
+// timeouts 单次调用超时与流式首包超时
+func timeouts(ctx context.Context) (timeout, firstToken time.Duration) {
+	timeout = g.Cfg().MustGet(ctx, "llm.timeout", defaultTimeout).Duration()
+	firstToken = g.Cfg().MustGet(ctx, "llm.firstTokenTimeout", defaultFirstTokenTimeout).Duration()
+	return timeout, firstToken
+}
@@ -0,0 +1,84 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+const (
+	defaultFailureThreshold = 3
+	defaultCooldown         = 30 * time.Second
+)
+
+// modelHealth 单个提供方/模型的健康状态：连续失败达到阈值后熔断，冷却期内优先尝试其他候选
+type modelHealth struct {
+	failures  int
+	openUntil time.Time
+}
+
+var (
+	healthMu sync.Mutex
+	health   = map[string]*modelHealth{}
+)
+
+// healthy 候选是否不在熔断冷却期
+func healthy(c candidate) bool {
+	healthMu.Lock()
+	defer healthMu.Unlock()
+	h, ok := health[c.key()]
+	return !ok || time.Now().After(h.openUntil)
+}
+
+// order 将熔断中的候选移到末尾，保持其余顺序；全部熔断时仍按原顺序尝试
+func order(candidates []candidate) []candidate {
+	ordered := make([]candidate, 0, len(candidates))
+	var open []candidate
+	for _, c := range candidates {
+		if healthy(c) {
+			ordered = append(ordered, c)
+		} else {
+			open = append(open, c)
+		}
+	}
+	return append(ordered, open...)
+}
+
+// markSuccess 调用成功，清零失败计数
+func markSuccess(ctx context.Context, c candidate) {
+	healthMu.Lock()
+	h, ok := health[c.key()]
+	recovered := ok && h.failures >= failureThreshold(ctx)
+	delete(health, c.key())
+	healthMu.Unlock()
+	if recovered {
+		g.Log().Infof(ctx, "[LLM] 模型恢复可用: %s", c.key())
+	}
+}
+
+// markFailure 记录一次可切换的失败，连续失败达到阈值时熔断 cooldown
+func markFailure(ctx context.Context, c candidate, err error) {
+	threshold := failureThreshold(ctx)
+	cooldown := g.Cfg().MustGet(ctx, "llm.health.cooldown", defaultCooldown).Duration()
+	healthMu.Lock()
+	h, ok := health[c.key()]
+	if !ok {
+		h = &modelHealth{}
+		health[c.key()] = h
+	}
+	h.failures++
+	failures := h.failures
+	if failures >= threshold {
+		h.openUntil = time.Now().Add(cooldown)
+	}
+	healthMu.Unlock()
+	if failures >= threshold {
+		g.Log().Warningf(ctx, "[LLM] 模型连续失败 %d 次，熔断 %v: %s, 错误: %v", failures, cooldown, c.key(), err)
+	}
+}
+
+func failureThreshold(ctx context.Context) int {
+	return max(g.Cfg().MustGet(ctx, "llm.health.failureThreshold", defaultFailureThreshold).Int(), 1)
+}
@@ -0,0 +1,275 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+	goopenai "github.com/meguminnnnnnnnn/go-openai"
+	arkModel "github.com/volcengine/volcengine-go-sdk/service/arkruntime/model"
+)
+
+// Option 创建模型时的选项
+type Option func(*options)
+
+type options struct {
+	thinking *bool
+}
+
+// WithThinking 覆盖路由配置的思考模式（如 NormalChat 按请求开启深度思考）
+func WithThinking(enabled bool) Option {
+	return func(o *options) {
+		o.thinking = &enabled
+	}
+}
+
+// routedModel 按角色路由的对话模型：每次调用时读取路由，依次尝试首选与备用模型，
+// 5xx、429、超时与连接错误时切换到下一个候选
+type routedModel struct {
+	role  string
+	opts  options
+	tools []*schema.ToolInfo
+}
+
+// target 一次调用中的候选模型及其客户端
+type target struct {
+	candidate
+	cm model.ToolCallingChatModel
+}
+
+// NewChatModel 创建角色对应的对话模型，创建时校验路由配置
+func NewChatModel(ctx context.Context, role string, opts ...Option) (model.ToolCallingChatModel, error) {
+	route, err := loadRoute(ctx, role)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = resolve(ctx, route); err != nil {
+		return nil, fmt.Errorf("模型路由配置错误: role=%s, 错误: %w", role, err)
+	}
+	m := &routedModel{role: role}
+	for _, opt := range opts {
+		opt(&m.opts)
+	}
+	return m, nil
+}
+
+func (m *routedModel) WithTools(tools []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	nm := *m
+	nm.tools = tools
+	return &nm, nil
+}
+
+func (m *routedModel) GetType() string {
+	return "Routed"
+}
+
+// IsCallbacksEnabled 回调由实际调用的客户端触发，记录的是真正使用的模型
+func (m *routedModel) IsCallbacksEnabled() bool {
+	return true
+}
+
+// targets 解析当前路由，返回按健康状态排序的候选客户端
+func (m *routedModel) targets(ctx context.Context) ([]target, error) {
+	route, err := loadRoute(ctx, m.role)
+	if err != nil {
+		return nil, err
+	}
+	candidates, err := resolve(ctx, route)
+	if err != nil {
+		return nil, err
+	}
+	targets := make([]target, 0, len(candidates))
+	for _, c := range order(candidates) {
+		cm, err := client(ctx, c, route, &m.opts)
+		if err != nil {
+			return nil, err
+		}
+		if len(m.tools) > 0 {
+			if cm, err = cm.WithTools(m.tools); err != nil {
+				return nil, err
+			}
+		}
+		targets = append(targets, target{candidate: c, cm: cm})
+	}
+	return targets, nil
+}
+
+func (m *routedModel) Generate(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.Message, error) {
+	targets, err := m.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	timeout, _ := timeouts(ctx)
+	var lastErr error
+	for i, t := range targets {
+		callCtx, cancel := context.WithTimeout(ctx, timeout)
+		msg, err := t.cm.Generate(callCtx, input, opts...)
+		cancel()
+		if err == nil {
+			markSuccess(ctx, t.candidate)
+			if i > 0 {
+				g.Log().Infof(ctx, "[LLM] role=%s 已切换到备用模型 %s", m.role, t.key())
+			}
+			return msg, nil
+		}
+		if !m.switchable(ctx, t, i == len(targets)-1, err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("模型调用失败，全部 %d 个候选不可用: %w", len(targets), lastErr)
+}
+
+func (m *routedModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	targets, err := m.targets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	_, firstToken := timeouts(ctx)
+	var lastErr error
+	for i, t := range targets {
+		sr, err := streamOnce(ctx, t.cm, firstToken, input, opts...)
+		if err == nil {
+			markSuccess(ctx, t.candidate)
+			if i > 0 {
+				g.Log().Infof(ctx, "[LLM] role=%s 已切换到备用模型 %s", m.role, t.key())
+			}
+			return sr, nil
+		}
+		if !m.switchable(ctx, t, i == len(targets)-1, err) {
+			return nil, err
+		}
+		lastErr = err
+	}
+	return nil, fmt.Errorf("模型调用失败，全部 %d 个候选不可用: %w", len(targets), lastErr)
+}
+
+// switchable 判断失败的调用能否切换到下一个候选，可切换的失败记入健康状态
+func (m *routedModel) switchable(ctx context.Context, t target, last bool, err error) bool {
+	if ctx.Err() != nil || !Retryable(err) {
+		return false
+	}
+	markFailure(ctx, t.candidate, err)
+	if !last {
+		g.Log().Warningf(ctx, "[LLM] role=%s 模型 %s 调用失败，切换下一个候选: %v", m.role, t.key(), err)
+	}
+	return true
+}
+
+// streamOnce 发起一次流式调用并等待首个分片：首包前的错误或超时交由调用方切换候选，
+// 首包之后的内容原样转发
+func streamOnce(ctx context.Context, cm model.ToolCallingChatModel, firstToken time.Duration,
+	input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	callCtx, cancel := context.WithCancel(ctx)
+	type chunk struct {
+		sr  *schema.StreamReader[*schema.Message]
+		msg *schema.Message
+		err error
+	}
+	// 建立连接与读取首个分片都计入首包超时：上游迟迟不返回响应头时 Stream 本身就会阻塞
+	first := make(chan chunk, 1)
+	go func() {
+		sr, err := cm.Stream(callCtx, input, opts...)
+		if err != nil {
+			first <- chunk{err: err}
+			return
+		}
+		msg, err := sr.Recv()
+		first <- chunk{sr, msg, err}
+	}()
+	timer := time.NewTimer(firstToken)
+	defer timer.Stop()
+	var c chunk
+	select {
+	case c = <-first:
+	case <-timer.C:
+		cancel()
+		go func() {
+			if c := <-first; c.sr != nil {
+				c.sr.Close()
+			}
+		}()
+		return nil, fmt.Errorf("等待首个分片超时（%v）: %w", firstToken, context.DeadlineExceeded)
+	}
+	if c.sr == nil {
+		cancel()
+		return nil, c.err
+	}
+	sr := c.sr
+	if errors.Is(c.err, io.EOF) {
+		sr.Close()
+		cancel()
+		return schema.StreamReaderFromArray([]*schema.Message{}), nil
+	}
+	if c.err != nil {
+		sr.Close()
+		cancel()
+		return nil, c.err
+	}
+	out, w := schema.Pipe[*schema.Message](8)
+	go func() {
+		defer func() {
+			sr.Close()
+			cancel()
+			w.Close()
+		}()
+		if w.Send(c.msg, nil) {
+			return
+		}
+		for {
+			msg, err := sr.Recv()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+			if w.Send(msg, err) || err != nil {
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// StatusCode 从模型调用错误中取出上游返回的 HTTP 状态码，无法识别时返回 0
+func StatusCode(err error) int {
+	var (
+		oaiAPI *openai.APIError // eino-ext 转换后的 go-openai APIError
+		oaiReq *goopenai.RequestError
+		arkAPI *arkModel.APIError
+		arkReq *arkModel.RequestError
+	)
+	switch {
+	case errors.As(err, &oaiAPI):
+		return oaiAPI.HTTPStatusCode
+	case errors.As(err, &oaiReq):
+		return oaiReq.HTTPStatusCode
+	case errors.As(err, &arkAPI):
+		return arkAPI.HTTPStatusCode
+	case errors.As(err, &arkReq):
+		return arkReq.HTTPStatusCode
+	}
+	return 0
+}
+
+// Retryable 错误是否应切换到备用模型：上游 5xx、429、408，超时与网络连接错误；
+// 调用方取消与 4xx（请求本身有误）不切换
+func Retryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	if code := StatusCode(err); code > 0 {
+		return code >= http.StatusInternalServerError || code == http.StatusTooManyRequests || code == http.StatusRequestTimeout
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,250 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+	goopenai "github.com/meguminnnnnnnnn/go-openai"
+)
+
+func TestRetryable(t *testing.T) {
+	cases := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"调用方取消", context.Canceled, false},
+		{"包装的调用方取消", fmt.Errorf("stream: %w", context.Canceled), false},
+		{"超时", context.DeadlineExceeded, true},
+		{"首包超时", fmt.Errorf("等待首个分片超时: %w", context.DeadlineExceeded), true},
+		{"连接中断", io.ErrUnexpectedEOF, true},
+		{"网络错误", &net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{"500", &openai.APIError{HTTPStatusCode: 500}, true},
+		{"502", fmt.Errorf("generate: %w", &openai.APIError{HTTPStatusCode: 502}), true},
+		{"429", &openai.APIError{HTTPStatusCode: 429}, true},
+		{"408", &goopenai.RequestError{HTTPStatusCode: 408}, true},
+		{"503 RequestError", &goopenai.RequestError{HTTPStatusCode: 503}, true},
+		{"400", &openai.APIError{HTTPStatusCode: 400}, false},
+		{"401", &openai.APIError{HTTPStatusCode: 401}, false},
+		{"404", &goopenai.RequestError{HTTPStatusCode: 404}, false},
+		{"其他错误", errors.New("参数错误"), false},
+	}
+	for _, c := range cases {
+		if got := Retryable(c.err); got != c.want {
+			t.Errorf("%s: Retryable(%v) = %v，期望 %v", c.name, c.err, got, c.want)
+		}
+	}
+}
+
+// fakeProvider OpenAI 兼容的模拟上游：status 非 0 时返回该状态码，delay 为响应前的等待，
+// tail 为流式响应首个分片与后续分片之间的等待
+type fakeProvider struct {
+	*httptest.Server
+	reply  string
+	status int
+	delay  time.Duration
+	tail   time.Duration
+	hits   atomic.Int32
+}
+
+func newFakeProvider(t *testing.T, reply string, status int, delay time.Duration) *fakeProvider {
+	t.Helper()
+	p := &fakeProvider{reply: reply, status: status, delay: delay}
+	p.Server = httptest.NewServer(http.HandlerFunc(p.serve))
+	t.Cleanup(p.Close)
+	return p
+}
+
+func (p *fakeProvider) serve(w http.ResponseWriter, r *http.Request) {
+	p.hits.Add(1)
+	body, _ := io.ReadAll(r.Body)
+	select {
+	case <-time.After(p.delay):
+	case <-r.Context().Done():
+		return
+	}
+	if p.status != 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(p.status)
+		fmt.Fprintf(w, `{"error":{"message":"模拟错误 %d","type":"test"}}`, p.status)
+		return
+	}
+	if strings.Contains(string(body), `"stream":true`) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		head, rest := p.reply, ""
+		if i := strings.Index(p.reply, " "); i >= 0 {
+			head, rest = p.reply[:i], p.reply[i:]
+		}
+		writeChunk(w, head)
+		w.(http.Flusher).Flush()
+		time.Sleep(p.tail)
+		if rest != "" {
+			writeChunk(w, rest)
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, `{"id":"1","object":"chat.completion","choices":[{"index":0,"message":{"role":"assistant","content":%q},"finish_reason":"stop"}]}`, p.reply)
+}
+
+func writeChunk(w io.Writer, content string) {
+	fmt.Fprintf(w, "data: {\"id\":\"1\",\"object\":\"chat.completion.chunk\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":%q}}]}\n\n", content)
+}
+
+// useRoute 配置 chat 角色：首选 primary，备用 fallback，并清空健康状态
+func useRoute(t *testing.T, primary, fallback *fakeProvider, timeout, firstToken time.Duration) {
+	t.Helper()
+	content := fmt.Sprintf(`
+llm:
+  timeout: %s
+  firstTokenTimeout: %s
+  health:
+    failureThreshold: 100
+  providers:
+    - name: "p1"
+      type: "openai"
+      baseURL: %q
+      apiKey: "k"
+    - name: "p2"
+      type: "openai"
+      baseURL: %q
+      apiKey: "k"
+  routes:
+    chat:
+      primary: "p1/m1"
+      fallbacks: ["p2/m2"]
+`, timeout, firstToken, primary.URL, fallback.URL)
+	adapter, err := gcfg.NewAdapterContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	resetHealth := func() {
+		healthMu.Lock()
+		health = map[string]*modelHealth{}
+		healthMu.Unlock()
+	}
+	resetHealth()
+	t.Cleanup(func() {
+		g.Cfg().SetAdapter(original)
+		resetHealth()
+	})
+}
+
+func TestRoutedModelFailover(t *testing.T) {
+	cases := []struct {
+		name     string
+		status   int
+		delay    time.Duration
+		failover bool
+	}{
+		{"500 切换", http.StatusInternalServerError, 0, true},
+		{"503 切换", http.StatusServiceUnavailable, 0, true},
+		{"429 切换", http.StatusTooManyRequests, 0, true},
+		{"超时切换", 0, 2 * time.Second, true},
+		{"400 不切换", http.StatusBadRequest, 0, false},
+		{"401 不切换", http.StatusUnauthorized, 0, false},
+		{"404 不切换", http.StatusNotFound, 0, false},
+	}
+	input := []*schema.Message{schema.UserMessage("hi")}
+	for _, c := range cases {
+		for _, mode := range []string{"Generate", "Stream"} {
+			t.Run(c.name+"/"+mode, func(t *testing.T) {
+				primary := newFakeProvider(t, "primary", c.status, c.delay)
+				fallback := newFakeProvider(t, "fallback", 0, 0)
+				useRoute(t, primary, fallback, 300*time.Millisecond, 300*time.Millisecond)
+				ctx := context.Background()
+				cm, err := NewChatModel(ctx, RoleChat)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				var reply string
+				if mode == "Generate" {
+					var msg *schema.Message
+					if msg, err = cm.Generate(ctx, input); err == nil {
+						reply = msg.Content
+					}
+				} else {
+					var sr *schema.StreamReader[*schema.Message]
+					if sr, err = cm.Stream(ctx, input); err == nil {
+						var msg *schema.Message
+						msg, err = schema.ConcatMessageStream(sr)
+						if err == nil {
+							reply = msg.Content
+						}
+					}
+				}
+
+				if primary.hits.Load() == 0 {
+					t.Fatal("应先调用首选模型")
+				}
+				if !c.failover {
+					if err == nil || fallback.hits.Load() != 0 {
+						t.Fatalf("不应切换到备用模型：err=%v, 备用调用 %d 次", err, fallback.hits.Load())
+					}
+					if code := StatusCode(err); code != c.status {
+						t.Fatalf("应返回上游状态码 %d，实际 %d (%v)", c.status, code, err)
+					}
+					return
+				}
+				if err != nil || reply != "fallback" || fallback.hits.Load() != 1 {
+					t.Fatalf("应切换到备用模型：reply=%q, err=%v, 备用调用 %d 次", reply, err, fallback.hits.Load())
+				}
+			})
+		}
+	}
+}
+
+func TestRoutedModelAllCandidatesFail(t *testing.T) {
+	primary := newFakeProvider(t, "primary", http.StatusBadGateway, 0)
+	fallback := newFakeProvider(t, "fallback", http.StatusServiceUnavailable, 0)
+	useRoute(t, primary, fallback, time.Second, time.Second)
+	cm, err := NewChatModel(context.Background(), RoleChat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = cm.Generate(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err == nil || StatusCode(err) != http.StatusServiceUnavailable {
+		t.Fatalf("全部候选失败时应返回最后一个错误，实际 %v", err)
+	}
+	if primary.hits.Load() != 1 || fallback.hits.Load() != 1 {
+		t.Fatalf("每个候选应各调用一次，实际 %d/%d", primary.hits.Load(), fallback.hits.Load())
+	}
+}
+
+func TestStreamOnceForwardsAfterFirstChunk(t *testing.T) {
+	// 首包之后的延迟不再触发切换
+	p := newFakeProvider(t, "slow tail", 0, 0)
+	p.tail = 500 * time.Millisecond
+	fallback := newFakeProvider(t, "fallback", 0, 0)
+	useRoute(t, p, fallback, time.Second, 200*time.Millisecond)
+	cm, err := NewChatModel(context.Background(), RoleChat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sr, err := cm.Stream(context.Background(), []*schema.Message{schema.UserMessage("hi")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := schema.ConcatMessageStream(sr)
+	if err != nil || msg.Content != "slow tail" || fallback.hits.Load() != 0 {
+		t.Fatalf("应使用首选模型的完整输出：msg=%v, err=%v, 备用调用 %d 次", msg, err, fallback.hits.Load())
+	}
+}
@@ -1,4 +1,5 @@
-// Package mineruworker 在索引前将 PDF（本地路径或 http(s) URL）经 MinerU 精准解析为 Markdown 文件。
+// Package mineruworker 在索引前将 PDF（本地路径或 http(s) URL）解析为 Markdown 文件。
+// 支持 MinerU 精准解析与纯 Go 本地解析两种后端，由 mineru.backend 选择。
 package mineruworker
 
 import (
@@ -28,9 +29,37 @@ func IsPDFPath(s string) bool {
 	return strings.EqualFold(filepath.Ext(s), ".pdf")
 }
 
-// ExtractPDFToMarkdownFile 使用 MinerU 精准解析（默认开启 OCR），将 Markdown 写入 <files.root>/mineru/doc_{documentsId}/extracted.md。
+// 解析后端（mineru.backend）
+const (
+	BackendMinerU = "mineru" // 仅使用 MinerU 远程服务
+	BackendLocal  = "local"  // 仅使用本地纯 Go 解析（无 OCR）
+	BackendAuto   = "auto"   // 优先 MinerU，未启用或调用失败时回退本地解析（默认）
+)
+
+// ExtractPDFToMarkdownFile 将 PDF 解析为 Markdown 写入 <FilesMinerUDir>/doc_{documentsId}/extracted.md。
 // source 可为本地绝对/相对路径，或 http(s) 指向的 PDF。
 func ExtractPDFToMarkdownFile(ctx context.Context, source string, documentsId int64) (mdAbsPath string, err error) {
+	switch backend := getBackend(ctx); backend {
+	case BackendMinerU:
+		return extractWithMinerU(ctx, source, documentsId)
+	case BackendLocal:
+		return extractWithLocal(ctx, source, documentsId)
+	case BackendAuto:
+		if isMinerUEnabled(ctx) && getToken(ctx) != "" {
+			mdAbsPath, err = extractWithMinerU(ctx, source, documentsId)
+			if err == nil {
+				return mdAbsPath, nil
+			}
+			g.Log().Warningf(ctx, "[MinerU] 解析失败，回退本地解析, documentsId=%d err=%v", documentsId, err)
+		}
+		return extractWithLocal(ctx, source, documentsId)
+	default:
+		return "", fmt.Errorf("不支持的 mineru.backend: %s（可选 mineru/local/auto）", backend)
+	}
+}
+
+// extractWithMinerU 使用 MinerU 精准解析（默认开启 OCR）
+func extractWithMinerU(ctx context.Context, source string, documentsId int64) (mdAbsPath string, err error) {
 	token := getToken(ctx)
 	if token == "" {
 		return "", fmt.Errorf("未配置 mineru.token 或环境变量 MINERU_TOKEN，无法解析 PDF")
@@ -62,22 +91,48 @@ func ExtractPDFToMarkdownFile(ctx context.Context, source string, documentsId in
 		return "", fmt.Errorf("MinerU 返回空 Markdown，State=%s", result.State)
 	}
 
+	abs, err := writeMarkdownFile(ctx, documentsId, result.Markdown)
+	if err != nil {
+		return "", err
+	}
+	g.Log().Infof(ctx, "[MinerU] 解析完成, md=%s bytes=%d", abs, len(result.Markdown))
+	return abs, nil
+}
+
+// docOutputDir 单个文档的解析输出目录
+func docOutputDir(ctx context.Context, documentsId int64) (string, error) {
 	outDir := filepath.Join(utility.FilesMinerUDir(ctx), fmt.Sprintf("doc_%d", documentsId))
 	if err := os.MkdirAll(outDir, 0755); err != nil {
 		return "", fmt.Errorf("创建 MinerU 缓存目录失败: %w", err)
 	}
+	return outDir, nil
+}
+
+// writeMarkdownFile 将 Markdown 写入 doc_{documentsId}/extracted.md，返回绝对路径
+func writeMarkdownFile(ctx context.Context, documentsId int64, markdown string) (string, error) {
+	outDir, err := docOutputDir(ctx, documentsId)
+	if err != nil {
+		return "", err
+	}
 	mdPath := filepath.Join(outDir, "extracted.md")
-	if err := os.WriteFile(mdPath, []byte(result.Markdown), 0644); err != nil {
+	if err := os.WriteFile(mdPath, []byte(markdown), 0644); err != nil {
 		return "", fmt.Errorf("写入 Markdown 失败: %w", err)
 	}
 	abs, err := filepath.Abs(mdPath)
 	if err != nil {
 		return mdPath, nil
 	}
-	g.Log().Infof(ctx, "[MinerU] 解析完成, md=%s bytes=%d", abs, len(result.Markdown))
 	return abs, nil
 }
 
+func getBackend(ctx context.Context) string {
+	v, err := g.Cfg().Get(ctx, "mineru.backend")
+	if err != nil || strings.TrimSpace(v.String()) == "" {
+		return BackendAuto
+	}
+	return strings.ToLower(strings.TrimSpace(v.String()))
+}
+
 func getToken(ctx context.Context) string {
 	if v, err := g.Cfg().Get(ctx, "mineru.token"); err == nil {
 		if t := strings.TrimSpace(v.String()); t != "" {
@@ -0,0 +1,295 @@
+package mineruworker
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"backend/studyCoach/common"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/ledongthuc/pdf"
+)
+
+const (
+	maxRemotePDFSize   = 200 << 20       // 远程 PDF 下载大小上限
+	pdfDownloadTimeout = 5 * time.Minute // 远程 PDF 下载总超时
+)
+
+// extractWithLocal 使用纯 Go 本地解析 PDF 文本层（不含 OCR），每页前写入页码标记。
+// 扫描件等无文本层的 PDF 会返回错误，需改用 MinerU。
+func extractWithLocal(ctx context.Context, source string, documentsId int64) (mdAbsPath string, err error) {
+	g.Log().Infof(ctx, "[LocalPDF] 开始本地解析 PDF, documentsId=%d source=%s", documentsId, source)
+	path := source
+	if isRemote(source) {
+		if path, err = downloadPDF(ctx, source, documentsId); err != nil {
+			return "", err
+		}
+	}
+
+	markdown, pages, total, err := pdfMarkdown(ctx, path)
+	if err != nil {
+		return "", err
+	}
+	if pages == 0 {
+		return "", fmt.Errorf("PDF 未包含可提取的文本层（可能为扫描件），请启用 MinerU OCR 解析")
+	}
+
+	abs, err := writeMarkdownFile(ctx, documentsId, markdown)
+	if err != nil {
+		return "", err
+	}
+	g.Log().Infof(ctx, "[LocalPDF] 解析完成, md=%s pages=%d/%d bytes=%d", abs, pages, total, len(markdown))
+	return abs, nil
+}
+
+// pdfMarkdown 逐页提取文本并在每页前写入页码标记，返回 Markdown、含文本的页数与总页数。
+// ledongthuc/pdf 遇到损坏或不规范的文件会直接 panic（打开、读取页数与页面内容时均可能发生），
+// 整个解析过程在同一个 recover 中转为错误返回，不再重入解析器
+func pdfMarkdown(ctx context.Context, path string) (markdown string, pages, total int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("解析 PDF 失败（文件损坏或格式不受支持）: %v", r)
+		}
+	}()
+
+	f, r, err := pdf.Open(path)
+	if err != nil {
+		return "", 0, 0, fmt.Errorf("打开 PDF 失败: %w", err)
+	}
+	defer f.Close()
+
+	var sb strings.Builder
+	total = r.NumPage()
+	for i := 1; i <= total; i++ {
+		if err = ctx.Err(); err != nil {
+			return "", 0, 0, err
+		}
+		page := r.Page(i)
+		if page.V.IsNull() {
+			continue
+		}
+		text := pageMarkdown(page)
+		if text == "" {
+			continue
+		}
+		pages++
+		sb.WriteString(fmt.Sprintf(common.PageMarkerFormat, i))
+		sb.WriteString("\n\n")
+		sb.WriteString(text)
+		sb.WriteString("\n\n")
+	}
+	return sb.String(), pages, total, nil
+}
+
+// pageMarkdown 将单页文本按行还原，并根据字号推断标题层级
+func pageMarkdown(page pdf.Page) string {
+	rows := groupRows(page.Content().Text)
+	if len(rows) == 0 {
+		return ""
+	}
+	body := medianFontSize(rows)
+
+	var (
+		sb    strings.Builder
+		prevY float64
+	)
+	for i, row := range rows {
+		line := strings.TrimSpace(row.text())
+		if line == "" {
+			continue
+		}
+		// 行距明显变大视为段落分隔
+		if i > 0 && prevY-row.y > row.size*1.8 {
+			sb.WriteString("\n")
+		}
+		prevY = row.y
+		if prefix := headingPrefix(row.size, body, line); prefix != "" {
+			sb.WriteString("\n" + prefix + " " + line + "\n\n")
+			continue
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// textRow 同一基线上的文本片段
+type textRow struct {
+	y     float64
+	size  float64
+	items []pdf.Text
+}
+
+func (r *textRow) text() string {
+	sort.Slice(r.items, func(i, j int) bool { return r.items[i].X < r.items[j].X })
+	var (
+		sb   strings.Builder
+		endX float64
+	)
+	for i, t := range r.items {
+		// 片段间距超过字号的 1/4 时补空格
+		if i > 0 && t.X-endX > t.FontSize*0.25 && !strings.HasPrefix(t.S, " ") && !strings.HasSuffix(sb.String(), " ") {
+			sb.WriteString(" ")
+		}
+		sb.WriteString(t.S)
+		endX = t.X + t.W
+	}
+	return sb.String()
+}
+
+// groupRows 按 Y 坐标聚合为行，自上而下排序
+func groupRows(texts []pdf.Text) []*textRow {
+	sorted := make([]pdf.Text, 0, len(texts))
+	for _, t := range texts {
+		if t.S != "" {
+			sorted = append(sorted, t)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Y > sorted[j].Y })
+
+	var rows []*textRow
+	for _, t := range sorted {
+		if n := len(rows); n > 0 && math.Abs(rows[n-1].y-t.Y) <= math.Max(rows[n-1].size, t.FontSize)*0.5 {
+			row := rows[n-1]
+			row.items = append(row.items, t)
+			row.size = math.Max(row.size, t.FontSize)
+			continue
+		}
+		rows = append(rows, &textRow{y: t.Y, size: t.FontSize, items: []pdf.Text{t}})
+	}
+	return rows
+}
+
+// medianFontSize 按字符数加权的正文字号
+func medianFontSize(rows []*textRow) float64 {
+	var sizes []float64
+	for _, row := range rows {
+		for _, t := range row.items {
+			for range t.S {
+				sizes = append(sizes, t.FontSize)
+			}
+		}
+	}
+	if len(sizes) == 0 {
+		return 0
+	}
+	sort.Float64s(sizes)
+	return sizes[len(sizes)/2]
+}
+
+// headingPrefix 字号显著大于正文且行较短时视为标题
+func headingPrefix(size, body float64, line string) string {
+	if body <= 0 || len([]rune(line)) > 60 {
+		return ""
+	}
+	switch ratio := size / body; {
+	case ratio >= 1.8:
+		return "#"
+	case ratio >= 1.3:
+		return "##"
+	default:
+		return ""
+	}
+}
+
+func isRemote(source string) bool {
+	s := strings.ToLower(strings.TrimSpace(source))
+	return strings.HasPrefix(s, "http://") || strings.HasPrefix(s, "https://")
+}
+
+// pdfTrustedClient 下载 mineru.allowedHosts 中配置的主机（如内网对象存储）上的 PDF
+var pdfTrustedClient = &http.Client{Timeout: pdfDownloadTimeout}
+
+// pdfPublicClient 下载其他来源的 PDF：不走代理，建立连接时校验解析后的 IP，
+// 拒绝回环、内网与链路本地地址，重定向后的连接同样校验
+var pdfPublicClient = &http.Client{
+	Timeout: pdfDownloadTimeout,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: publicAddrOnly,
+		}).DialContext,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 30 * time.Second,
+	},
+}
+
+func publicAddrOnly(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() {
+		return fmt.Errorf("不允许从内网地址 %s 下载 PDF，可信主机请加入 mineru.allowedHosts", host)
+	}
+	return nil
+}
+
+// pdfClient 按 URL 主机选择下载客户端
+func pdfClient(ctx context.Context, rawURL string) (*http.Client, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("PDF 地址无效: %w", err)
+	}
+	for _, host := range g.Cfg().MustGet(ctx, "mineru.allowedHosts").Strings() {
+		if strings.EqualFold(strings.TrimSpace(host), u.Hostname()) {
+			return pdfTrustedClient, nil
+		}
+	}
+	return pdfPublicClient, nil
+}
+
+// downloadPDF 将远程 PDF 下载到文档解析目录，超过 maxRemotePDFSize 时返回错误
+func downloadPDF(ctx context.Context, rawURL string, documentsId int64) (string, error) {
+	client, err := pdfClient(ctx, rawURL)
+	if err != nil {
+		return "", err
+	}
+	outDir, err := docOutputDir(ctx, documentsId)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rawURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("创建下载请求失败: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("下载 PDF 失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("下载 PDF 失败, status=%d", resp.StatusCode)
+	}
+	if resp.ContentLength > maxRemotePDFSize {
+		return "", fmt.Errorf("PDF 超过 %d MB 上限", maxRemotePDFSize>>20)
+	}
+
+	path := filepath.Join(outDir, "source.pdf")
+	out, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("创建 PDF 文件失败: %w", err)
+	}
+	defer out.Close()
+	n, err := io.Copy(out, io.LimitReader(resp.Body, maxRemotePDFSize+1))
+	if err != nil {
+		return "", fmt.Errorf("写入 PDF 文件失败: %w", err)
+	}
+	if n > maxRemotePDFSize {
+		return "", fmt.Errorf("PDF 超过 %d MB 上限", maxRemotePDFSize>>20)
+	}
+	return path, nil
+}
@@ -0,0 +1,141 @@
+package mineruworker
+
+import (
+	"backend/studyCoach/common"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+)
+
+// buildPDF 生成每页一段文本的最小 PDF，空字符串表示无文本层的页面
+func buildPDF(pages ...string) []byte {
+	n := len(pages)
+	// 对象编号：1 Catalog、2 Pages、3 Font，之后每页依次为 Page 与 Contents
+	objs := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica >>",
+	}
+	var kids []string
+	for i, text := range pages {
+		pageObj, contentObj := 4+2*i, 5+2*i
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageObj))
+		stream := ""
+		if text != "" {
+			stream = fmt.Sprintf("BT /F1 12 Tf 72 720 Td (%s) Tj ET", text)
+		}
+		objs = append(objs,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 612 792] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>", contentObj),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", len(stream), stream))
+	}
+	objs[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), n)
+
+	var buf bytes.Buffer
+	buf.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objs))
+	for i, obj := range objs {
+		offsets[i] = buf.Len()
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", i+1, obj)
+	}
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(objs)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objs)+1, xref)
+	return buf.Bytes()
+}
+
+func writeTemp(t *testing.T, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPDFMarkdownPageMarkers(t *testing.T) {
+	// 每个有文本的页面前写入对应页码标记，无文本层的页面跳过但不影响后续页码
+	path := writeTemp(t, "doc.pdf", buildPDF("First page", "", "Third page"))
+	markdown, pages, total, err := pdfMarkdown(context.Background(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pages != 2 || total != 3 {
+		t.Fatalf("含文本页 %d / 总页数 %d，期望 2/3", pages, total)
+	}
+	first := strings.Index(markdown, fmt.Sprintf(common.PageMarkerFormat, 1))
+	third := strings.Index(markdown, fmt.Sprintf(common.PageMarkerFormat, 3))
+	if first < 0 || third < 0 || strings.Contains(markdown, fmt.Sprintf(common.PageMarkerFormat, 2)) {
+		t.Fatalf("页码标记不正确:\n%s", markdown)
+	}
+	if !strings.Contains(markdown[first:third], "First page") || !strings.Contains(markdown[third:], "Third page") {
+		t.Fatalf("页面文本应位于各自的页码标记之后:\n%s", markdown)
+	}
+}
+
+func TestPDFMarkdownCorruptFile(t *testing.T) {
+	// 损坏的文件返回错误，解析器内部的 panic 不会传出
+	valid := buildPDF("First page")
+	cases := map[string][]byte{
+		"非 PDF": []byte("not a pdf at all"),
+		"截断":    valid[:len(valid)/2],
+		// 交叉引用指向错误偏移：打开文件读取页数时 panic
+		"交叉引用偏移错误": bytes.Replace(valid, []byte("0000000009 00000 n"), []byte("0000000001 00000 n"), 1),
+		// 页面内容指向非流对象：读取页面文本时 panic
+		"页面内容损坏": bytes.Replace(valid, []byte("/Contents 5 0 R"), []byte("/Contents 3 0 R"), 1),
+	}
+	for name, data := range cases {
+		t.Run(name, func(t *testing.T) {
+			path := writeTemp(t, "corrupt.pdf", data)
+			if _, _, _, err := pdfMarkdown(context.Background(), path); err == nil {
+				t.Fatal("损坏的 PDF 应返回错误")
+			}
+		})
+	}
+}
+
+func TestDownloadPDFHostGuard(t *testing.T) {
+	// 未列入 mineru.allowedHosts 的主机不能解析到内网地址；列入后可正常下载
+	body := buildPDF("Remote page")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(body)
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	useConfig := func(allowed string) {
+		adapter, err := gcfg.NewAdapterContent(fmt.Sprintf("mineru:\n  cacheDir: %q\n  allowedHosts: [%s]\n", t.TempDir(), allowed))
+		if err != nil {
+			t.Fatal(err)
+		}
+		original := g.Cfg().GetAdapter()
+		g.Cfg().SetAdapter(adapter)
+		t.Cleanup(func() { g.Cfg().SetAdapter(original) })
+	}
+
+	useConfig("")
+	if _, err := downloadPDF(context.Background(), srv.URL+"/a.pdf", 1); err == nil {
+		t.Fatal("回环地址未列入 allowedHosts 时应拒绝下载")
+	}
+
+	useConfig(fmt.Sprintf("%q", u.Hostname()))
+	path, err := downloadPDF(context.Background(), srv.URL+"/a.pdf", 1)
+	if err != nil {
+		t.Fatalf("allowedHosts 中的主机应允许下载: %v", err)
+	}
+	if got, _ := os.ReadFile(path); !bytes.Equal(got, body) {
+		t.Fatal("下载内容与源文件不一致")
+	}
+}
@@ -9,6 +9,9 @@ import (
 	"time"
 
 	"github.com/bytedance/sonic"
+	"github.com/cloudwego/eino/callbacks"
+	"github.com/cloudwego/eino/components"
+	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
 	"github.com/gogf/gf/v2/frame/g"
 )
@@ -40,8 +43,17 @@ type Result struct {
 type Resp struct {
 	ID      string    `json:"id"`
 	Results []*Result `json:"results"`
+	Meta    struct {
+		Tokens struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+		} `json:"tokens"`
+	} `json:"meta"`
 }
 
+// ComponentOfRerank 重排调用在 Eino 回调中的组件类型，输入输出沿用 model.CallbackInput / CallbackOutput 以便统一记录用量
+const ComponentOfRerank components.Component = "Rerank"
+
 var rerankCfg *Conf
 
 func NewRerank(ctx context.Context, query string, docs []*schema.Document, topK int) (output []*schema.Document, err error) {
@@ -91,7 +103,7 @@ func rerank(ctx context.Context, query string, docs []*schema.Document, topK int
 		data.Documents = append(data.Documents, doc.Content)
 	}
 	// 重排
-	results, err := rerankDoHttp(ctx, data)
+	results, err := rerankWithCallbacks(ctx, data)
 	if err != nil {
 		return
 	}
@@ -105,7 +117,32 @@ func rerank(ctx context.Context, query string, docs []*schema.Document, topK int
 	return
 }
 
-func rerankDoHttp(ctx context.Context, data *Data) ([]*Result, error) {
+// rerankWithCallbacks 触发 Eino 回调后调用重排接口，回调输出中带上接口返回的 token 用量
+func rerankWithCallbacks(ctx context.Context, data *Data) ([]*Result, error) {
+	cfg, err := GetConf(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ctx = callbacks.ReuseHandlers(ctx, &callbacks.RunInfo{Name: "Rerank", Type: "SiliconFlow", Component: ComponentOfRerank})
+	ctx = callbacks.OnStart(ctx, &model.CallbackInput{Config: &model.Config{Model: cfg.Model}})
+	res, err := rerankDoHttp(ctx, data)
+	if err != nil {
+		callbacks.OnError(ctx, err)
+		return nil, err
+	}
+	tokens := res.Meta.Tokens
+	callbacks.OnEnd(ctx, &model.CallbackOutput{
+		Config: &model.Config{Model: cfg.Model},
+		TokenUsage: &model.TokenUsage{
+			PromptTokens:     tokens.InputTokens,
+			CompletionTokens: tokens.OutputTokens,
+			TotalTokens:      tokens.InputTokens + tokens.OutputTokens,
+		},
+	})
+	return res.Results, nil
+}
+
+func rerankDoHttp(ctx context.Context, data *Data) (*Resp, error) {
 	cfg, err := GetConf(ctx)
 	if err != nil {
 		return nil, err
@@ -140,10 +177,10 @@ func rerankDoHttp(ctx context.Context, data *Data) ([]*Result, error) {
 	if err != nil {
 		return nil, err
 	}
-	res := Resp{}
-	err = sonic.Unmarshal(body, &res)
+	res := &Resp{}
+	err = sonic.Unmarshal(body, res)
 	if err != nil {
 		return nil, err
 	}
-	return res.Results, nil
+	return res, nil
 }
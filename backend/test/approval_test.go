@@ -0,0 +1,242 @@
+package integrationtest
+
+import (
+	"backend/studyCoach/common"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/cloudwego/eino/compose"
+	"github.com/cloudwego/eino/flow/agent/react"
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+)
+
+const (
+	approvalTestAgent = "it_approval_agent"
+	approvalTestTool  = "it_echo"
+)
+
+// approvalModel 首次调用请求执行 it_echo，收到工具结果后把结果作为最终回复；failNext 时下一次调用返回错误
+type approvalModel struct {
+	failNext atomic.Bool
+}
+
+func (m *approvalModel) Generate(_ context.Context, input []*schema.Message, _ ...model.Option) (*schema.Message, error) {
+	if m.failNext.CompareAndSwap(true, false) {
+		return nil, errors.New("模拟：模型调用失败")
+	}
+	if last := input[len(input)-1]; last.Role == schema.Tool {
+		return schema.AssistantMessage("工具结果："+last.Content, nil), nil
+	}
+	return schema.AssistantMessage("", []schema.ToolCall{{
+		ID:       "call_1",
+		Type:     "function",
+		Function: schema.FunctionCall{Name: approvalTestTool, Arguments: `{"text":"hi"}`},
+	}}), nil
+}
+
+func (m *approvalModel) Stream(ctx context.Context, input []*schema.Message, opts ...model.Option) (*schema.StreamReader[*schema.Message], error) {
+	msg, err := m.Generate(ctx, input, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return schema.StreamReaderFromArray([]*schema.Message{msg}), nil
+}
+
+func (m *approvalModel) WithTools(_ []*schema.ToolInfo) (model.ToolCallingChatModel, error) {
+	return m, nil
+}
+
+// echoTool 记录执行次数，确认审批前不会执行
+type echoTool struct {
+	calls atomic.Int32
+}
+
+func (e *echoTool) Info(_ context.Context) (*schema.ToolInfo, error) {
+	return &schema.ToolInfo{Name: approvalTestTool, Desc: "回显输入"}, nil
+}
+
+func (e *echoTool) InvokableRun(_ context.Context, args string, _ ...tool.Option) (string, error) {
+	e.calls.Add(1)
+	return "echo " + args, nil
+}
+
+// approvalFixture 注册使用 approvalModel 与 echoTool 的 Agent，并把 it_echo 设为每次需审批
+type approvalFixture struct {
+	model        *approvalModel
+	tool         *echoTool
+	deepThinking atomic.Bool // 最近一次构建 Agent 时 ctx 中的深度思考开关
+}
+
+func newApprovalFixture(t *testing.T) *approvalFixture {
+	t.Helper()
+	adapter, err := gcfg.NewAdapterContent("approval:\n  tools:\n    " + approvalTestTool + ": always\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	t.Cleanup(func() { g.Cfg().SetAdapter(original) })
+
+	f := &approvalFixture{model: &approvalModel{}, tool: &echoTool{}}
+	common.RegisterAgent(approvalTestAgent, f.newAgent)
+	return f
+}
+
+// newAgent 与生产环境一致：审批中间件挂在工具节点上，恢复时按名称重建
+func (f *approvalFixture) newAgent(ctx context.Context) (*react.Agent, error) {
+	v, _ := ctx.Value(common.IsDeepThinking).(bool)
+	f.deepThinking.Store(v)
+	return react.NewAgent(ctx, &react.AgentConfig{
+		ToolCallingModel: f.model,
+		ToolsConfig: compose.ToolsNodeConfig{
+			Tools:               []tool.BaseTool{f.tool},
+			ToolCallMiddlewares: []compose.ToolMiddleware{common.BuildApprovalMiddleware()},
+		},
+		MaxStep: 10,
+	})
+}
+
+// pause 以新轮次运行 Agent，返回其暂停时保存的待审批记录
+func (f *approvalFixture) pause(t *testing.T, ctx context.Context, sessionId string) *common.PendingApproval {
+	t.Helper()
+	ins, err := f.newAgent(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lambda, err := common.BuildAgentLambda(ctx, approvalTestAgent, ins)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r, err := compose.NewChain[[]*schema.Message, *schema.Message]().AppendLambda(lambda).Compile(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx = common.WithApprovalTurn(ctx, &common.ApprovalTurn{SessionId: sessionId, TurnId: sessionId + "_turn1", Data: []byte(`{}`)})
+	ctx = context.WithValue(ctx, common.IsDeepThinking, true)
+	_, err = r.Invoke(ctx, []*schema.Message{schema.UserMessage("请回显 hi")})
+	var paused *common.ApprovalRequiredError
+	if !errors.As(err, &paused) {
+		t.Fatalf("需审批的工具应暂停 Agent，实际 %v", err)
+	}
+	if n := f.tool.calls.Load(); n != 0 {
+		t.Fatalf("审批前工具不应执行，实际执行 %d 次", n)
+	}
+	rec, err := common.LoadApproval(ctx, sessionId, paused.Approval.Id)
+	if err != nil {
+		t.Fatalf("读取待审批记录: %v", err)
+	}
+	if len(rec.Calls) != 1 || rec.Calls[0].Tool != approvalTestTool || !rec.IsDeepThinking {
+		t.Fatalf("待审批记录不符: %+v", rec)
+	}
+	return rec
+}
+
+// resume 认领并按 decision 恢复，返回续写的文本
+func (f *approvalFixture) resume(ctx context.Context, rec *common.PendingApproval, decision *common.ApprovalDecision) (string, error) {
+	if err := common.ClaimApproval(ctx, rec); err != nil {
+		return "", err
+	}
+	ctx = common.WithApprovalTurn(ctx, &common.ApprovalTurn{SessionId: rec.SessionId, TurnId: rec.SessionId + "_turn2", Data: []byte(`{}`)})
+	sr, err := common.ResumeAgent(ctx, rec, decision)
+	if err != nil {
+		return "", err
+	}
+	defer sr.Close()
+	var sb strings.Builder
+	for {
+		msg, err := sr.Recv()
+		if err == io.EOF {
+			return sb.String(), nil
+		}
+		if err != nil {
+			return sb.String(), err
+		}
+		sb.WriteString(msg.Content)
+	}
+}
+
+func approvalSession(t *testing.T) string {
+	t.Helper()
+	sessionId := fmt.Sprintf("it_approval_%d", time.Now().UnixNano())
+	t.Cleanup(func() {
+		_, _ = g.Redis().Del(context.Background(), "chat:approval:"+sessionId, "chat:checkpoint:"+sessionId+"_turn1")
+	})
+	return sessionId
+}
+
+func TestIntegration_Approval_ApproveResumes(t *testing.T) {
+	logCaseStart(t, "工具审批：暂停 → 同意 → 从检查点续写并执行工具")
+	requireRedis(t)
+	f := newApprovalFixture(t)
+	ctx := context.Background()
+	sessionId := approvalSession(t)
+
+	rec := f.pause(t, ctx, sessionId)
+	out, err := f.resume(ctx, rec, &common.ApprovalDecision{Approved: true})
+	if err != nil {
+		t.Fatalf("续写失败: %v", err)
+	}
+	if n := f.tool.calls.Load(); n != 1 || !strings.Contains(out, "echo") {
+		t.Fatalf("同意后工具应执行一次并返回结果，实际执行 %d 次，回复 %q", n, out)
+	}
+	if !f.deepThinking.Load() {
+		t.Fatal("续写应沿用原轮次的深度思考设置")
+	}
+	if _, err = common.LoadApproval(ctx, sessionId, rec.Id); err == nil {
+		t.Fatal("已答复的审批记录应被删除")
+	}
+	if err = common.ClaimApproval(ctx, rec); err == nil {
+		t.Fatal("同一审批请求不应被重复答复")
+	}
+}
+
+func TestIntegration_Approval_DenyResumes(t *testing.T) {
+	logCaseStart(t, "工具审批：暂停 → 拒绝 → 续写时不执行工具")
+	requireRedis(t)
+	f := newApprovalFixture(t)
+	ctx := context.Background()
+	sessionId := approvalSession(t)
+
+	rec := f.pause(t, ctx, sessionId)
+	out, err := f.resume(ctx, rec, &common.ApprovalDecision{Approved: false, Reason: "不需要"})
+	if err != nil {
+		t.Fatalf("续写失败: %v", err)
+	}
+	if n := f.tool.calls.Load(); n != 0 || !strings.Contains(out, "用户拒绝执行工具") || !strings.Contains(out, "不需要") {
+		t.Fatalf("拒绝后工具不应执行且回复应包含拒绝原因，实际执行 %d 次，回复 %q", n, out)
+	}
+}
+
+func TestIntegration_Approval_RestoredOnResumeFailure(t *testing.T) {
+	logCaseStart(t, "工具审批：续写失败时放回待审批记录，可重新答复")
+	requireRedis(t)
+	f := newApprovalFixture(t)
+	ctx := context.Background()
+	sessionId := approvalSession(t)
+
+	rec := f.pause(t, ctx, sessionId)
+	f.model.failNext.Store(true)
+	if _, err := f.resume(ctx, rec, &common.ApprovalDecision{Approved: true}); err == nil {
+		t.Fatal("模型调用失败时续写应返回错误")
+	}
+	restored, err := common.LoadApproval(ctx, sessionId, rec.Id)
+	if err != nil {
+		t.Fatalf("续写失败后待审批记录应被放回: %v", err)
+	}
+	if _, err = f.resume(ctx, restored, &common.ApprovalDecision{Approved: true}); err != nil {
+		t.Fatalf("放回后重新答复应成功: %v", err)
+	}
+	if n := f.tool.calls.Load(); n != 2 {
+		t.Fatalf("两次续写均应从检查点执行工具，实际执行 %d 次", n)
+	}
+}
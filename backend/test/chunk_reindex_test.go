@@ -0,0 +1,73 @@
+package integrationtest
+
+import (
+	v1 "backend/api/rag/v1"
+	"backend/internal/dao"
+	"backend/internal/logic/knowledge"
+	"backend/internal/model/do"
+	"backend/internal/model/entity"
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// 编辑单个切片后的重新索引只更新切片自身的 index_status，不覆盖文档上正在进行的索引任务状态
+func TestIntegration_Knowledge_ChunkReindexKeepsDocumentStatus(t *testing.T) {
+	logCaseStart(t, "切片重新索引：状态记录在切片上，文档状态保持不变")
+	requireMigratedDB(t)
+	ctx := context.Background()
+
+	docId, err := knowledge.SaveDocumentsInfo(ctx, entity.KnowledgeDocuments{
+		KnowledgeBaseName: fmt.Sprintf("it_reindex_%d", time.Now().UnixNano()),
+		Status:            int(v1.StatusIndexing),
+	})
+	if err != nil {
+		t.Fatalf("创建文档: %v", err)
+	}
+	chunkId := fmt.Sprintf("it_reindex_chunk_%d", time.Now().UnixNano())
+	if _, err = dao.KnowledgeChunks.Ctx(ctx).Data(do.KnowledgeChunks{
+		KnowledgeDocId: docId,
+		ChunkId:        chunkId,
+		Content:        "编辑后的内容",
+		Status:         knowledge.ChunkStatusActive,
+	}).Insert(); err != nil {
+		t.Fatalf("创建切片: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = dao.KnowledgeChunks.Ctx(ctx).Where("knowledge_doc_id", docId).Delete()
+		_, _ = dao.KnowledgeDocuments.Ctx(ctx).Where("id", docId).Delete()
+	})
+
+	assert := func(wantChunk v1.Status) {
+		t.Helper()
+		chunk, err := dao.KnowledgeChunks.Ctx(ctx).Where("chunk_id", chunkId).Value("index_status")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if chunk.Int() != int(wantChunk) {
+			t.Fatalf("切片索引状态 %d，期望 %d", chunk.Int(), wantChunk)
+		}
+		doc, err := knowledge.GetDocumentById(ctx, docId)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if doc.Status != int(v1.StatusIndexing) {
+			t.Fatalf("文档状态被改为 %d，应保持完整索引任务写入的 Indexing", doc.Status)
+		}
+	}
+
+	if err = knowledge.TrackChunkIndex(ctx, chunkId, func() error {
+		assert(v1.StatusIndexing)
+		return errors.New("embedding 失败")
+	}); err == nil {
+		t.Fatal("重新索引失败时应返回错误")
+	}
+	assert(v1.StatusFailed)
+
+	if err = knowledge.TrackChunkIndex(ctx, chunkId, func() error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+	assert(v1.StatusActive)
+}
@@ -1,14 +1,24 @@
 package integrationtest
 
 import (
+	modelgorm "backend/internal/model/gorm"
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 	"os"
 	"strings"
 	"testing"
 	"time"
+
+	_ "github.com/gogf/gf/contrib/drivers/mysql/v2"
+	_ "github.com/gogf/gf/contrib/nosql/redis/v2"
+	"github.com/gogf/gf/v2/database/gdb"
+	"github.com/gogf/gf/v2/database/gredis"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
 )
 
 // gfAPIResponse 与后端统一 JSON 外层约定一致（code/message/data），便于解析集成测试结果。
@@ -102,3 +112,55 @@ func mustGFCode0(t *testing.T, raw []byte, ctx string) gfAPIResponse {
 	}
 	return w
 }
+
+// requireDB 连接 STUDYCOACH_TEST_DB_LINK 指定的数据库（需为已完成迁移的服务库，如
+// mysql:root:root@tcp(127.0.0.1:3306)/studyCoach?parseTime=true）；未设置或不可达时跳过当前测试。
+func requireDB(t *testing.T) {
+	t.Helper()
+	link := strings.TrimSpace(os.Getenv("STUDYCOACH_TEST_DB_LINK"))
+	if link == "" {
+		t.Skip("前置条件不满足：未设置 STUDYCOACH_TEST_DB_LINK")
+	}
+	if err := gdb.SetConfig(gdb.Config{gdb.DefaultGroupName: gdb.ConfigGroup{{Link: link}}}); err != nil {
+		t.Fatalf("设置数据库配置: %v", err)
+	}
+	if err := g.DB().PingMaster(); err != nil {
+		t.Skipf("前置条件不满足：无法连接数据库 (%v)", err)
+	}
+}
+
+// requireMigratedDB 在 requireDB 基础上对测试库执行启动迁移，确保表结构与当前代码一致
+func requireMigratedDB(t *testing.T) {
+	t.Helper()
+	requireDB(t)
+	link := strings.TrimSpace(os.Getenv("STUDYCOACH_TEST_DB_LINK"))
+	adapter, err := gcfg.NewAdapterContent(fmt.Sprintf("db:\n  mysql: %q\n", link))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	defer g.Cfg().SetAdapter(original)
+	if err = modelgorm.RunMigrateOnStartup(context.Background()); err != nil {
+		t.Fatalf("数据库迁移: %v", err)
+	}
+}
+
+// requireRedis 连接 STUDYCOACH_TEST_REDIS_ADDR 指定的 Redis（如 127.0.0.1:6379，
+// 密码取 STUDYCOACH_TEST_REDIS_PASS）；未设置或不可达时跳过当前测试。
+func requireRedis(t *testing.T) {
+	t.Helper()
+	addr := strings.TrimSpace(os.Getenv("STUDYCOACH_TEST_REDIS_ADDR"))
+	if addr == "" {
+		t.Skip("前置条件不满足：未设置 STUDYCOACH_TEST_REDIS_ADDR")
+	}
+	gredis.SetConfig(&gredis.Config{Address: addr, Pass: os.Getenv("STUDYCOACH_TEST_REDIS_PASS")})
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	if g.Redis() == nil {
+		t.Skip("前置条件不满足：Redis 客户端不可用")
+	}
+	if _, err := g.Redis().Do(ctx, "PING"); err != nil {
+		t.Skipf("前置条件不满足：无法连接 Redis (%v)", err)
+	}
+}
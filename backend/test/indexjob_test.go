@@ -0,0 +1,106 @@
+package integrationtest
+
+import (
+	v1 "backend/api/rag/v1"
+	"backend/internal/dao"
+	"backend/internal/logic/indexjob"
+	"backend/internal/model/entity"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gtime"
+)
+
+// insertJob 直接写入一条指定状态与租约的任务记录，测试结束后删除
+func insertJob(t *testing.T, status int, owner string, leaseUntil *gtime.Time) string {
+	t.Helper()
+	jobId := fmt.Sprintf("it_job_%d", time.Now().UnixNano())
+	_, err := dao.KnowledgeIndexJobs.Ctx(context.Background()).Data(g.Map{
+		"job_id":              jobId,
+		"user_uuid":           "it_user",
+		"knowledge_base_name": "it_kb",
+		"documents_id":        0,
+		"source":              "/nonexistent/it.txt",
+		"status":              status,
+		"owner":               owner,
+		"lease_until":         leaseUntil,
+	}).Insert()
+	if err != nil {
+		t.Fatalf("写入任务: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = dao.KnowledgeIndexJobs.Ctx(context.Background()).Where("job_id", jobId).Delete()
+	})
+	return jobId
+}
+
+func loadJob(t *testing.T, jobId string) *entity.KnowledgeIndexJobs {
+	t.Helper()
+	var job *entity.KnowledgeIndexJobs
+	if err := dao.KnowledgeIndexJobs.Ctx(context.Background()).Where("job_id", jobId).Scan(&job); err != nil || job == nil {
+		t.Fatalf("读取任务 %s: %v", jobId, err)
+	}
+	return job
+}
+
+// 多副本部署：只回收租约已过期的执行中任务，其他实例仍在续约的任务保持执行中
+func TestIntegration_IndexJob_RequeueOnlyExpiredLeases(t *testing.T) {
+	logCaseStart(t, "索引任务：仅回收租约过期的执行中任务")
+	requireMigratedDB(t)
+	ctx := context.Background()
+	alive := insertJob(t, v1.JobStatusRunning, "other-instance", gtime.Now().Add(time.Minute))
+	expired := insertJob(t, v1.JobStatusRunning, "dead-instance", gtime.Now().Add(-time.Minute))
+	legacy := insertJob(t, v1.JobStatusRunning, "", nil)
+
+	if _, err := indexjob.RequeueExpired(ctx); err != nil {
+		t.Fatalf("回收任务: %v", err)
+	}
+	if job := loadJob(t, alive); job.Status != v1.JobStatusRunning || job.Owner != "other-instance" {
+		t.Fatalf("租约有效的任务不应被回收: status=%d owner=%q", job.Status, job.Owner)
+	}
+	for _, jobId := range []string{expired, legacy} {
+		if job := loadJob(t, jobId); job.Status != v1.JobStatusQueued || job.Owner != "" || job.LeaseUntil != nil {
+			t.Fatalf("租约过期或没有租约的任务应重新排队: status=%d owner=%q lease=%v", job.Status, job.Owner, job.LeaseUntil)
+		}
+	}
+}
+
+// 执行流程：认领时写入租约，执行失败且达到最大次数后标记失败并释放租约
+func TestIntegration_IndexJob_RunnerFailsAndReleasesLease(t *testing.T) {
+	logCaseStart(t, "索引任务：认领、执行失败与释放租约")
+	requireMigratedDB(t)
+	ctx := context.Background()
+	indexjob.Start(ctx)
+	jobId, err := indexjob.Create(ctx, entity.KnowledgeIndexJobs{
+		UserUuid:          "it_user",
+		KnowledgeBaseName: "it_kb",
+		FileName:          "missing.txt",
+		Source:            "/nonexistent/missing.txt",
+		MaxAttempts:       1,
+	})
+	if err != nil {
+		t.Fatalf("创建任务: %v", err)
+	}
+	t.Cleanup(func() {
+		_, _ = dao.KnowledgeIndexJobs.Ctx(context.Background()).Where("job_id", jobId).Delete()
+	})
+	indexjob.Enqueue(jobId)
+
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		job := loadJob(t, jobId)
+		if job.Status == v1.JobStatusFailed {
+			if job.Attempts != 1 || job.Owner != "" || job.LeaseUntil != nil || job.Error == "" {
+				t.Fatalf("失败任务应记录原因并释放租约: %+v", job)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("任务未在期限内结束: status=%d", job.Status)
+		}
+		time.Sleep(200 * time.Millisecond)
+	}
+}
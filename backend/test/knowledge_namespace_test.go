@@ -0,0 +1,84 @@
+package integrationtest
+
+import (
+	"backend/internal/dao"
+	"backend/internal/logic/knowledge"
+	"backend/internal/model/do"
+	"backend/internal/model/entity"
+	"backend/studyCoach/common"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestIntegration_Knowledge_SameNameIsolation 两个用户各有一个同名知识库，
+// 本地关键词索引读取的切片与版本只能来自请求方自己的知识库
+func TestIntegration_Knowledge_SameNameIsolation(t *testing.T) {
+	logCaseStart(t, "同名知识库：切片查询按知识库 ID 与所属用户隔离")
+	requireDB(t)
+	ctx := context.Background()
+
+	name := fmt.Sprintf("it_same_name_%d", time.Now().UnixNano())
+	type tenant struct {
+		ns      common.Namespace
+		docId   int64
+		chunkId string
+	}
+	tenants := make([]*tenant, 2)
+	for i := range tenants {
+		userUUID := fmt.Sprintf("it_user_%d_%d", i, time.Now().UnixNano())
+		kbId, err := dao.KnowledgeBase.Ctx(ctx).Data(do.KnowledgeBase{UserUuid: userUUID, Name: name, Status: 1}).InsertAndGetId()
+		if err != nil {
+			t.Fatalf("创建知识库: %v", err)
+		}
+		docId, err := knowledge.SaveDocumentsInfo(ctx, entity.KnowledgeDocuments{KnowledgeBaseName: name, KnowledgeBaseId: kbId})
+		if err != nil {
+			t.Fatalf("创建文档: %v", err)
+		}
+		chunkId := fmt.Sprintf("%s_chunk_%d", name, i)
+		if _, err = dao.KnowledgeChunks.Ctx(ctx).Data(do.KnowledgeChunks{
+			KnowledgeDocId: docId,
+			ChunkId:        chunkId,
+			Content:        fmt.Sprintf("用户 %d 的私有内容", i),
+			Status:         knowledge.ChunkStatusActive,
+		}).Insert(); err != nil {
+			t.Fatalf("创建切片: %v", err)
+		}
+		tenants[i] = &tenant{ns: common.Namespace{KnowledgeBaseId: kbId, UserUUID: userUUID}, docId: docId, chunkId: chunkId}
+	}
+	t.Cleanup(func() {
+		for _, tn := range tenants {
+			_, _ = dao.KnowledgeChunks.Ctx(ctx).Where("knowledge_doc_id", tn.docId).Delete()
+			_, _ = dao.KnowledgeDocuments.Ctx(ctx).Where("id", tn.docId).Delete()
+			_, _ = dao.KnowledgeBase.Ctx(ctx).Where("id", tn.ns.KnowledgeBaseId).Delete()
+		}
+	})
+
+	for i, tn := range tenants {
+		chunks, err := knowledge.GetSearchableChunks(ctx, tn.ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(chunks) != 1 || chunks[0].ChunkId != tn.chunkId {
+			t.Fatalf("用户 %d 应只读到自己的 1 个切片，实际 %+v", i, chunks)
+		}
+		version, err := knowledge.GetChunksVersion(ctx, tn.ns)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if version[:2] != "1/" {
+			t.Fatalf("用户 %d 的切片版本应只统计 1 个切片，实际 %s", i, version)
+		}
+	}
+
+	// 知识库 ID 与用户不匹配时读不到任何切片
+	forged := common.Namespace{KnowledgeBaseId: tenants[1].ns.KnowledgeBaseId, UserUUID: tenants[0].ns.UserUUID}
+	chunks, err := knowledge.GetSearchableChunks(ctx, forged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 0 {
+		t.Fatalf("他人的知识库 ID 不应读到切片，实际 %+v", chunks)
+	}
+}
@@ -0,0 +1,127 @@
+package integrationtest
+
+import (
+	"backend/mcp/clock_time"
+	"backend/studyCoach/aiModel/eino_tools/mcptool"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/components/tool"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+)
+
+// freePort 返回一个当前空闲的本地端口
+func freePort(t *testing.T) int {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+// TestIntegration_MCP_ClockServerTools 以仓库内的时钟示例服务代替外部 MCP 服务，
+// 验证按对话模式白名单挂载工具、调用工具，以及不可用服务在健康状态中的体现
+func TestIntegration_MCP_ClockServerTools(t *testing.T) {
+	logCaseStart(t, "MCP 工具：白名单挂载→调用→健康状态")
+	ctx := context.Background()
+
+	port := freePort(t)
+	t.Setenv("MCP_HOST", "127.0.0.1")
+	t.Setenv("MCP_PORT", fmt.Sprint(port))
+	clock_time.StartMCPServer()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		conn, err := net.DialTimeout("tcp", fmt.Sprintf("127.0.0.1:%d", port), time.Second)
+		if err == nil {
+			conn.Close()
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("时钟服务未启动: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	adapter, err := gcfg.NewAdapterContent(fmt.Sprintf(`
+mcp:
+  connectTimeout: "3s"
+  servers:
+    clock:
+      transport: sse
+      url: "http://127.0.0.1:%d/sse"
+    offline:
+      transport: http
+      url: "http://127.0.0.1:%d/mcp"
+  modes:
+    coach: ["clock/get_current_time"]
+    normal: ["clock/not_exist", "offline"]
+`, port, freePort(t)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	t.Cleanup(func() { g.Cfg().SetAdapter(original) })
+
+	version := mcptool.ToolsVersion()
+	tools, err := mcptool.NewTools(ctx, mcptool.ModeCoach)
+	if err != nil {
+		t.Fatalf("NewTools(coach): %v", err)
+	}
+	// 服务连上后工具集版本递增，缓存的 Agent 图据此重建并挂载新工具
+	if mcptool.ToolsVersion() == version {
+		t.Fatal("clock 连接成功后工具集版本应递增")
+	}
+	version = mcptool.ToolsVersion()
+	if len(tools) != 1 {
+		t.Fatalf("coach 模式应挂载 1 个工具，实际 %d 个", len(tools))
+	}
+	info, err := tools[0].Info(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Name != "clock__get_current_time" {
+		t.Fatalf("工具名应为 clock__get_current_time，实际 %q", info.Name)
+	}
+	out, err := tools[0].(tool.InvokableTool).InvokableRun(ctx, `{"format":"readable","timezone":"UTC"}`)
+	if err != nil {
+		t.Fatalf("调用工具: %v", err)
+	}
+	if _, err := time.Parse("2006-01-02 15:04:05", strings.Join(strings.Fields(out)[:2], " ")); err != nil {
+		t.Fatalf("工具结果应为当前时间，实际 %q", out)
+	}
+	t.Logf("get_current_time: %s", out)
+
+	// normal 模式：白名单中的工具不存在、服务不可达，均不挂载
+	tools, err = mcptool.NewTools(ctx, mcptool.ModeNormal)
+	if err != nil {
+		t.Fatalf("NewTools(normal): %v", err)
+	}
+	if len(tools) != 0 {
+		t.Fatalf("normal 模式不应挂载工具，实际 %d 个", len(tools))
+	}
+	if mcptool.ToolsVersion() != version {
+		t.Fatal("连接失败与已连接服务的复用不应改变工具集版本")
+	}
+
+	health := make(map[string]mcptool.ServerHealth)
+	for _, h := range mcptool.Health(ctx) {
+		health[h.Name] = h
+	}
+	if h := health["clock"]; h.State != mcptool.StateReady || h.Tools != 1 {
+		t.Fatalf("clock 应为 ready 且有 1 个工具，实际 %+v", h)
+	}
+	if h := health["offline"]; h.State != mcptool.StateDown || h.LastError == "" || h.Failures != 1 {
+		t.Fatalf("offline 应为 down 并记录错误，实际 %+v", h)
+	}
+	if mcptool.Ready(mcptool.Health(ctx)) {
+		t.Fatal("存在不可用服务时 Ready 应为 false")
+	}
+}
@@ -0,0 +1,59 @@
+package integrationtest
+
+import (
+	logic "backend/internal/logic/ai_chat"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+)
+
+// 超出窗口预算的消息在摘要写回前仍保留在对话记忆中
+func TestIntegration_Memory_OverflowKeptUntilSummarized(t *testing.T) {
+	logCaseStart(t, "对话记忆：超出预算且尚未进入摘要的消息不丢失")
+	requireDB(t)
+	requireRedis(t)
+	adapter, err := gcfg.NewAdapterContent("chat:\n  memory:\n    historyTokens: 1\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	t.Cleanup(func() { g.Cfg().SetAdapter(original) })
+
+	ctx := context.Background()
+	chat := logic.GetChat()
+	owner := logic.Owner{AnonymousId: fmt.Sprintf("it_anon_%d", time.Now().UnixNano())}
+	sessionId := fmt.Sprintf("it_memory_%d", time.Now().UnixNano())
+	if err = chat.EnsureSession(ctx, owner, sessionId, "记忆测试", false); err != nil {
+		t.Fatalf("创建会话: %v", err)
+	}
+	t.Cleanup(func() { _ = chat.DeleteSession(context.Background(), owner, sessionId) })
+
+	const rounds = 3
+	for i := 0; i < rounds; i++ {
+		turn, err := chat.NewTurn(ctx, sessionId, "", "", "", fmt.Sprintf("问题 %d", i), nil)
+		if err != nil {
+			t.Fatalf("创建轮次: %v", err)
+		}
+		if err = chat.SaveTurn(ctx, turn, schema.AssistantMessage(fmt.Sprintf("回答 %d", i), nil), nil); err != nil {
+			t.Fatalf("保存轮次: %v", err)
+		}
+	}
+
+	mem, err := chat.LoadMemory(ctx, sessionId, "", "")
+	if err != nil {
+		t.Fatalf("读取对话记忆: %v", err)
+	}
+	// 首次读取时尚无摘要，后台摘要任务写回之前窗口之外的消息也应在其中
+	if mem.Summary != "" || len(mem.History) != rounds*2 {
+		t.Fatalf("摘要写回前应保留全部 %d 条消息，实际 %d 条", rounds*2, len(mem.History))
+	}
+	if first := mem.History[0]; first.Content != "问题 0" {
+		t.Fatalf("消息应按时间升序，首条为 %q", first.Content)
+	}
+}
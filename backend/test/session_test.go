@@ -0,0 +1,88 @@
+package integrationtest
+
+import (
+	logic "backend/internal/logic/ai_chat"
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// 首轮对话绑定会话归属，其他用户以同一会话 ID 访问被拒绝
+func TestIntegration_Session_OwnerBound(t *testing.T) {
+	logCaseStart(t, "会话归属：首轮绑定所有者，其他用户同 ID 访问返回 403")
+	requireMigratedDB(t)
+	ctx := context.Background()
+	chat := logic.GetChat()
+	owner := logic.Owner{AnonymousId: fmt.Sprintf("it_anon_a_%d", time.Now().UnixNano())}
+	other := logic.Owner{AnonymousId: fmt.Sprintf("it_anon_b_%d", time.Now().UnixNano())}
+	sessionId := fmt.Sprintf("it_session_owner_%d", time.Now().UnixNano())
+	t.Cleanup(func() { _ = chat.DeleteSession(context.Background(), owner, sessionId) })
+
+	if err := chat.EnsureSession(ctx, owner, sessionId, "归属测试", true); err != nil {
+		t.Fatalf("创建会话: %v", err)
+	}
+	if err := chat.EnsureSession(ctx, owner, sessionId, "归属测试", true); err != nil {
+		t.Fatalf("所有者再次访问应成功: %v", err)
+	}
+	err := chat.EnsureSession(ctx, other, sessionId, "抢占", true)
+	if code := gerror.Code(err).Code(); code != 403 {
+		t.Fatalf("其他用户访问应返回 403，实际 %v", err)
+	}
+}
+
+// 同一会话 ID 的首轮并发到达：只创建一条记录，先创建者成为所有者，其余用户被拒绝
+func TestIntegration_Session_ConcurrentFirstTurn(t *testing.T) {
+	logCaseStart(t, "会话归属：并发首轮只创建一个会话，其余所有者返回 403")
+	requireMigratedDB(t)
+	ctx := context.Background()
+	chat := logic.GetChat()
+	sessionId := fmt.Sprintf("it_session_race_%d", time.Now().UnixNano())
+
+	const n = 8
+	owners := make([]logic.Owner, n)
+	errs := make([]error, n)
+	for i := range owners {
+		owners[i] = logic.Owner{AnonymousId: fmt.Sprintf("it_anon_%d_%d", i, time.Now().UnixNano())}
+	}
+	start := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := range owners {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			errs[i] = chat.EnsureSession(ctx, owners[i], sessionId, "并发测试", true)
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	winner := -1
+	for i, err := range errs {
+		switch {
+		case err == nil && winner == -1:
+			winner = i
+		case err == nil:
+			t.Fatalf("只能有一个所有者创建成功，%d 与 %d 均成功", winner, i)
+		case gerror.Code(err).Code() != 403:
+			t.Fatalf("落败的请求应返回 403，实际 %v", err)
+		}
+	}
+	if winner == -1 {
+		t.Fatalf("应有一个请求创建成功: %v", errs)
+	}
+	t.Cleanup(func() { _ = chat.DeleteSession(context.Background(), owners[winner], sessionId) })
+
+	rows, err := g.DB().Model("chat_sessions").Where("uuid", sessionId).Count()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rows != 1 {
+		t.Fatalf("同一会话 ID 应只有 1 条记录，实际 %d", rows)
+	}
+}
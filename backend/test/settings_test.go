@@ -0,0 +1,110 @@
+package integrationtest
+
+import (
+	v1 "backend/api/settings/v1"
+	"backend/internal/dao"
+	logic "backend/internal/logic/ai_chat"
+	"backend/internal/logic/settings"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// 更新设置只覆盖请求中提供的字段，其余字段保持上次保存的值
+func TestIntegration_Settings_UpdateMerges(t *testing.T) {
+	logCaseStart(t, "用户设置：部分更新只覆盖提供的字段")
+	requireMigratedDB(t)
+	ctx := context.Background()
+	userId := time.Now().UnixNano() % 1_000_000_000_000
+	t.Cleanup(func() {
+		_, _ = dao.UserSettings.Ctx(context.Background()).Where(dao.UserSettings.Columns().UserId, userId).Delete()
+	})
+
+	s, err := settings.Get(ctx, userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *s != *settings.Default() {
+		t.Fatalf("未保存过设置时应返回默认值，实际 %+v", s)
+	}
+
+	theme, language, maxSessions, topK := "dark", "en", 20, 8
+	if _, err = settings.Update(ctx, userId, &v1.SettingsUpdateReq{
+		Theme:        &theme,
+		Language:     &language,
+		MaxSessions:  &maxSessions,
+		ChatDefaults: &v1.ChatDefaults{TopK: &topK},
+	}); err != nil {
+		t.Fatalf("首次保存设置: %v", err)
+	}
+
+	autoSave := false
+	if _, err = settings.Update(ctx, userId, &v1.SettingsUpdateReq{AutoSaveSessions: &autoSave}); err != nil {
+		t.Fatalf("部分更新设置: %v", err)
+	}
+	s, err = settings.Get(ctx, userId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.AutoSaveSessions || s.Theme != theme || s.Language != language || s.MaxSessions != maxSessions || !s.NotificationEnabled {
+		t.Fatalf("未提供的字段应保持不变: %+v", s)
+	}
+	if s.ChatDefaults.TopK == nil || *s.ChatDefaults.TopK != topK {
+		t.Fatalf("默认对话选项应保持不变: %+v", s.ChatDefaults)
+	}
+	count, err := dao.UserSettings.Ctx(ctx).Where(dao.UserSettings.Columns().UserId, userId).Count()
+	if err != nil || count != 1 {
+		t.Fatalf("同一用户应只有 1 行设置，实际 %d (%v)", count, err)
+	}
+}
+
+// 清理超出上限的旧会话时保留最近的会话与当前会话，即使当前会话最早更新
+func TestIntegration_Settings_PruneKeepsCurrent(t *testing.T) {
+	logCaseStart(t, "会话上限：清理最早的会话，当前会话始终保留")
+	requireMigratedDB(t)
+	ctx := context.Background()
+	chat := logic.GetChat()
+	owner := logic.Owner{AnonymousId: fmt.Sprintf("it_anon_prune_%d", time.Now().UnixNano())}
+
+	// sessions[0] 最早更新，作为当前会话
+	sessions := make([]string, 5)
+	base := time.Now().Add(-time.Hour)
+	for i := range sessions {
+		sessions[i] = fmt.Sprintf("it_prune_%d_%d", i, time.Now().UnixNano())
+		if err := chat.EnsureSession(ctx, owner, sessions[i], "清理测试", true); err != nil {
+			t.Fatalf("创建会话: %v", err)
+		}
+		if _, err := dao.ChatSessions.Ctx(ctx).Where(dao.ChatSessions.Columns().Uuid, sessions[i]).
+			Data(g.Map{dao.ChatSessions.Columns().UpdatedAt: base.Add(time.Duration(i) * time.Minute)}).Update(); err != nil {
+			t.Fatalf("调整会话更新时间: %v", err)
+		}
+	}
+	t.Cleanup(func() {
+		for _, id := range sessions {
+			_ = chat.DeleteSession(context.Background(), owner, id)
+		}
+	})
+
+	pruned, err := chat.PruneSessions(ctx, owner, 3, sessions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 2 {
+		t.Fatalf("应清理 2 个会话，实际 %d", pruned)
+	}
+	remaining, err := dao.ChatSessions.Ctx(ctx).
+		Where(dao.ChatSessions.Columns().AnonymousId, owner.AnonymousId).
+		OrderDesc(dao.ChatSessions.Columns().UpdatedAt).
+		Array(dao.ChatSessions.Columns().Uuid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := g.NewVar(remaining).Strings()
+	want := []string{sessions[4], sessions[3], sessions[0]}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("保留的会话应为最近 2 个加当前会话 %v，实际 %v", want, got)
+	}
+}
@@ -0,0 +1,59 @@
+package integrationtest
+
+import (
+	logic "backend/internal/logic/ai_chat"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// 轮次在生成前被取消：用户消息已在开始生成前写入，会话中保留问题且当前分支指向它
+func TestIntegration_Turn_UserMessageKeptOnCancel(t *testing.T) {
+	logCaseStart(t, "轮次取消：已接收的用户消息保留在会话中（保存与临时会话）")
+	requireDB(t)
+	requireRedis(t)
+	for _, save := range []bool{true, false} {
+		t.Run(fmt.Sprintf("save=%v", save), func(t *testing.T) {
+			ctx := context.Background()
+			chat := logic.GetChat()
+			owner := logic.Owner{AnonymousId: fmt.Sprintf("it_anon_%d", time.Now().UnixNano())}
+			sessionId := fmt.Sprintf("it_turn_cancel_%d", time.Now().UnixNano())
+			if err := chat.EnsureSession(ctx, owner, sessionId, "取消测试", save); err != nil {
+				t.Fatalf("创建会话: %v", err)
+			}
+			t.Cleanup(func() { _ = chat.DeleteSession(context.Background(), owner, sessionId) })
+
+			turn, err := chat.NewTurn(ctx, sessionId, "", "", "", "被取消的问题", nil)
+			if err != nil {
+				t.Fatalf("创建轮次: %v", err)
+			}
+			if err = chat.SaveUserMessage(ctx, turn); err != nil {
+				t.Fatalf("保存用户消息: %v", err)
+			}
+			turnCtx := chat.StartTurn(ctx, turn)
+			if n := chat.CancelTurn(sessionId, turn.ReplyMsgId); n != 1 || turnCtx.Err() == nil {
+				t.Fatalf("应取消进行中的轮次，实际取消 %d 个", n)
+			}
+			chat.FinishTurn(turn)
+
+			res, err := chat.GetSession(ctx, owner, sessionId, 0, 20)
+			if err != nil {
+				t.Fatalf("读取会话: %v", err)
+			}
+			if res.ActiveMsgId != turn.UserMsgId || len(res.Messages) != 1 ||
+				res.Messages[0].MsgId != turn.UserMsgId || res.Messages[0].Content != "被取消的问题" {
+				t.Fatalf("取消后会话应保留用户消息：active=%s, messages=%+v", res.ActiveMsgId, res.Messages)
+			}
+
+			// 重新生成接在已保存的用户消息之后，不会重复写入
+			regen, err := chat.RegenerateTurn(ctx, sessionId, turn.UserMsgId, "")
+			if err != nil {
+				t.Fatalf("重新生成: %v", err)
+			}
+			if regen.NewUserMsg || regen.UserMsgId != turn.UserMsgId {
+				t.Fatalf("重新生成应复用已保存的用户消息: %+v", regen)
+			}
+		})
+	}
+}
@@ -0,0 +1,86 @@
+package integrationtest
+
+import (
+	"backend/internal/dao"
+	"backend/internal/logic/usage"
+	"backend/internal/model/do"
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/errors/gerror"
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+)
+
+// 日额度按当日用量累计校验，用完后返回 429 与恢复时间；其他用户与未配置额度的匿名用户不受影响
+func TestIntegration_Usage_DailyQuota(t *testing.T) {
+	logCaseStart(t, "用量额度：当日用量达到上限后拒绝，昨日用量不计入")
+	requireMigratedDB(t)
+	adapter, err := gcfg.NewAdapterContent("usage:\n  quota:\n    dailyTokens: 100\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	t.Cleanup(func() { g.Cfg().SetAdapter(original) })
+
+	ctx := context.Background()
+	suffix := time.Now().UnixNano()
+	user := usage.Scope{UserUUID: fmt.Sprintf("it_quota_user_%d", suffix)}
+	other := usage.Scope{UserUUID: fmt.Sprintf("it_quota_other_%d", suffix)}
+	anonymous := usage.Scope{AnonymousId: fmt.Sprintf("it_quota_anon_%d", suffix)}
+	t.Cleanup(func() {
+		_, _ = dao.LlmUsage.Ctx(context.Background()).
+			WhereIn(dao.LlmUsage.Columns().UserUuid, []string{user.UserUUID, other.UserUUID}).Delete()
+		_, _ = dao.LlmUsage.Ctx(context.Background()).
+			Where(dao.LlmUsage.Columns().AnonymousId, anonymous.AnonymousId).Delete()
+	})
+	record := func(scope usage.Scope, tokens int) int64 {
+		id, err := dao.LlmUsage.Ctx(ctx).Data(do.LlmUsage{
+			UserUuid:    scope.UserUUID,
+			AnonymousId: scope.AnonymousId,
+			Source:      usage.SourceChat,
+			Model:       "it-model",
+			TotalTokens: tokens,
+		}).InsertAndGetId()
+		if err != nil {
+			t.Fatalf("写入用量: %v", err)
+		}
+		return id
+	}
+
+	// 昨日的大量用量不计入今日额度
+	yesterday := record(user, 1000)
+	if _, err = dao.LlmUsage.Ctx(ctx).Where(dao.LlmUsage.Columns().Id, yesterday).
+		Data(g.Map{dao.LlmUsage.Columns().CreatedAt: time.Now().AddDate(0, 0, -1)}).Update(); err != nil {
+		t.Fatalf("调整用量时间: %v", err)
+	}
+	record(user, 60)
+	if err = usage.CheckQuota(ctx, user); err != nil {
+		t.Fatalf("今日已用 60 / 100 时应放行，实际 %v", err)
+	}
+
+	record(user, 50)
+	err = usage.CheckQuota(ctx, user)
+	if code := gerror.Code(err); code.Code() != http.StatusTooManyRequests {
+		t.Fatalf("今日已用 110 / 100 时应返回 429，实际 %v", err)
+	}
+	exceeded, ok := gerror.Code(err).Detail().(usage.QuotaExceeded)
+	if !ok || !exceeded.ResetAt.After(time.Now()) || exceeded.ResetAt.Sub(time.Now()) > 24*time.Hour {
+		t.Fatalf("恢复时间应为明日零点，实际 %+v", gerror.Code(err).Detail())
+	}
+
+	// 额度按用户计，其他用户不受影响
+	record(other, 10)
+	if err = usage.CheckQuota(ctx, other); err != nil {
+		t.Fatalf("其他用户不应受影响，实际 %v", err)
+	}
+	// 匿名用户额度单独配置，未配置时不限
+	record(anonymous, 1000)
+	if err = usage.CheckQuota(ctx, anonymous); err != nil {
+		t.Fatalf("匿名额度未配置时应放行，实际 %v", err)
+	}
+}
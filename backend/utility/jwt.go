@@ -126,6 +126,33 @@ func CurrentUserID(ctx context.Context) (int64, error) {
 	return id, nil
 }
 
+// CurrentUsername 从 JWT 解析当前用户名（登录时写入 claims["Username"]）。
+func CurrentUsername(ctx context.Context) (string, error) {
+	claims, err := JWTMap(ctx)
+	if err != nil {
+		return "", err
+	}
+	username := strings.TrimSpace(gconv.String(claims["Username"]))
+	if username == "" {
+		return "", gerror.NewCode(gcode.CodeInvalidParameter, "token 中缺少用户名")
+	}
+	return username, nil
+}
+
+// CheckAdmin 校验当前用户是否为管理员（配置 admin.usernames），非管理员返回 403。
+func CheckAdmin(ctx context.Context) (string, error) {
+	username, err := CurrentUsername(ctx)
+	if err != nil {
+		return "", err
+	}
+	for _, name := range g.Cfg().MustGet(ctx, "admin.usernames").Strings() {
+		if strings.TrimSpace(name) == username {
+			return username, nil
+		}
+	}
+	return "", gerror.NewCode(gcode.New(403, "仅管理员可执行该操作", nil))
+}
+
 // CurrentUserUUID 返回当前用户的 users.uuid：优先 JWT 的 uuid；旧 token 无 uuid 时按 Id 查库（兼容未重登用户）。
 func CurrentUserUUID(ctx context.Context) (string, error) {
 	claims, err := JWTMap(ctx)
@@ -0,0 +1,46 @@
+// Package reexec 沙箱子进程的启动辅助：服务进程以 /proc/self/exe 重新执行自身并带上 EnvKey，
+// 子进程在 init 中设置 rlimit 后 execve 目标命令。
+// 本包只依赖标准库，保证其 init 先于业务包（连接 Redis、ES 等）执行。
+package reexec
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EnvKey 子进程标记，值为 Encode 生成的资源限制
+const EnvKey = "STUDYCOACH_SANDBOX_RLIMITS"
+
+// Rlimits 子进程的资源限制，0 表示不限制
+type Rlimits struct {
+	CPUSeconds uint64
+	AsBytes    uint64
+	FSizeBytes uint64
+	NProc      uint64
+}
+
+// Encode 将 Rlimits 编码为 EnvKey 的值
+func Encode(l Rlimits) string {
+	return strings.Join([]string{
+		strconv.FormatUint(l.CPUSeconds, 10),
+		strconv.FormatUint(l.AsBytes, 10),
+		strconv.FormatUint(l.FSizeBytes, 10),
+		strconv.FormatUint(l.NProc, 10),
+	}, ",")
+}
+
+func decode(s string) (Rlimits, bool) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 4 {
+		return Rlimits{}, false
+	}
+	var v [4]uint64
+	for i, p := range parts {
+		n, err := strconv.ParseUint(p, 10, 64)
+		if err != nil {
+			return Rlimits{}, false
+		}
+		v[i] = n
+	}
+	return Rlimits{CPUSeconds: v[0], AsBytes: v[1], FSizeBytes: v[2], NProc: v[3]}, true
+}
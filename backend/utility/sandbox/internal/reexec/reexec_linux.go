@@ -0,0 +1,81 @@
+package reexec
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+func init() {
+	if spec, ok := os.LookupEnv(EnvKey); ok {
+		os.Exit(run(spec, os.Args[1:]))
+	}
+}
+
+// run 设置 rlimit 后 execve argv，只在失败时返回（126 无法执行，127 命令不存在）
+func run(spec string, argv []string) int {
+	limits, ok := decode(spec)
+	if !ok || len(argv) == 0 {
+		fmt.Fprintln(os.Stderr, "sandbox: 无效的启动参数")
+		return 126
+	}
+	path, err := exec.LookPath(argv[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+		return 127
+	}
+	env := make([]string, 0, len(os.Environ()))
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, EnvKey+"=") {
+			env = append(env, kv)
+		}
+	}
+
+	// 先准备好 execve 的参数：设置 RLIMIT_AS 后 Go 运行时可能无法再申请内存
+	pathp, err := syscall.BytePtrFromString(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+		return 126
+	}
+	argvp, err := syscall.SlicePtrFromStrings(argv)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+		return 126
+	}
+	envp, err := syscall.SlicePtrFromStrings(env)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "sandbox: %v\n", err)
+		return 126
+	}
+
+	// CPU 硬限制比软限制多 1 秒：超出软限制时先收到 SIGXCPU，便于区分 CPU 超限
+	for _, l := range []struct {
+		resource int
+		cur, max uint64
+	}{
+		{unix.RLIMIT_NPROC, limits.NProc, limits.NProc},
+		{unix.RLIMIT_CPU, limits.CPUSeconds, limits.CPUSeconds + 1},
+		{unix.RLIMIT_FSIZE, limits.FSizeBytes, limits.FSizeBytes},
+		{unix.RLIMIT_AS, limits.AsBytes, limits.AsBytes},
+	} {
+		if l.cur == 0 {
+			continue
+		}
+		if err := unix.Setrlimit(l.resource, &unix.Rlimit{Cur: l.cur, Max: l.max}); err != nil {
+			fmt.Fprintf(os.Stderr, "sandbox: 设置资源限制失败: %v\n", err)
+			return 126
+		}
+	}
+
+	_, _, errno := syscall.RawSyscall(syscall.SYS_EXECVE,
+		uintptr(unsafe.Pointer(pathp)),
+		uintptr(unsafe.Pointer(&argvp[0])),
+		uintptr(unsafe.Pointer(&envp[0])))
+	fmt.Fprintf(os.Stderr, "sandbox: 执行 %s 失败: %v\n", argv[0], errno)
+	return 126
+}
@@ -0,0 +1,209 @@
+// Package sandbox 以受限子进程执行 execute 工具的命令：独立进程组、资源限制（rlimit）、
+// 墙钟超时、精简环境变量、输出截断，以及可选的网络命名空间隔离。
+// 资源限制与网络隔离仅在 Linux 上生效，其他系统只保留超时、环境变量与输出截断。
+package sandbox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+)
+
+// 限制档位（配置 sandbox.tiers.<tier>，未配置的项继承 default）
+const (
+	TierDefault   = "default"   // 登录用户
+	TierAnonymous = "anonymous" // 匿名访客
+	TierAdmin     = "admin"     // 管理员
+)
+
+// Limits 单次执行的资源限制，数值为 0 表示不限制
+type Limits struct {
+	Timeout     time.Duration // 墙钟超时
+	CPUSeconds  uint64        // RLIMIT_CPU
+	MemoryMB    uint64        // RLIMIT_AS
+	FileSizeMB  uint64        // RLIMIT_FSIZE
+	MaxProcs    uint64        // RLIMIT_NPROC：命令在独立 user namespace 中时只统计沙箱内的进程，否则按运行服务的系统用户计数
+	MaxOutputKB int           // stdout、stderr 各自保留的上限
+	Network     bool          // 允许联网；false 时在支持的系统上隔离网络命名空间
+}
+
+// defaultLimits 未配置 sandbox.tiers 时的限制
+var defaultLimits = Limits{
+	Timeout:     60 * time.Second,
+	CPUSeconds:  30,
+	MemoryMB:    1024,
+	FileSizeMB:  64,
+	MaxProcs:    512,
+	MaxOutputKB: 64,
+}
+
+// LimitsFor 返回档位的限制：sandbox.tiers.<tier> 优先，其次 sandbox.tiers.default，最后为内置默认值
+func LimitsFor(ctx context.Context, tier string) Limits {
+	l := defaultLimits
+	get := func(key string) (v *g.Var, ok bool) {
+		for _, t := range []string{tier, TierDefault} {
+			if v = g.Cfg().MustGet(ctx, "sandbox.tiers."+t+"."+key); !v.IsNil() {
+				return v, true
+			}
+		}
+		return nil, false
+	}
+	if v, ok := get("timeout"); ok {
+		l.Timeout = v.Duration()
+	}
+	if v, ok := get("cpuSeconds"); ok {
+		l.CPUSeconds = v.Uint64()
+	}
+	if v, ok := get("memoryMB"); ok {
+		l.MemoryMB = v.Uint64()
+	}
+	if v, ok := get("fileSizeMB"); ok {
+		l.FileSizeMB = v.Uint64()
+	}
+	if v, ok := get("maxProcs"); ok {
+		l.MaxProcs = v.Uint64()
+	}
+	if v, ok := get("maxOutputKB"); ok {
+		l.MaxOutputKB = v.Int()
+	}
+	if v, ok := get("network"); ok {
+		l.Network = v.Bool()
+	}
+	return l
+}
+
+// Result 命令执行结果。命令以非零状态退出、超时或被信号终止都不视为错误，由调用方按字段判断
+type Result struct {
+	ExitCode        int    `json:"exit_code"` // 被信号终止时为 -1
+	Stdout          string `json:"stdout"`
+	Stderr          string `json:"stderr"`
+	StdoutTruncated bool   `json:"stdout_truncated"`
+	StderrTruncated bool   `json:"stderr_truncated"`
+	TimedOut        bool   `json:"timed_out"`
+	Signal          string `json:"signal,omitempty"` // 终止进程的信号，如 CPU 超限时为 SIGXCPU
+	NetworkIsolated bool   `json:"network_isolated"`
+	DurationMs      int64  `json:"duration_ms"`
+}
+
+var (
+	probeOnce sync.Once
+	probeErr  error
+)
+
+// Probe 探测能否为命令隔离网络命名空间（容器内或内核禁用 user namespace 时不能），只在首次调用时探测，
+// 服务启动时调用以尽早发现；不能隔离时返回原因
+func Probe(ctx context.Context) error {
+	probeOnce.Do(func() {
+		if probeErr = probeNetworkIsolation(ctx); probeErr != nil {
+			if g.Cfg().MustGet(ctx, "sandbox.allowUnisolatedNetwork").Bool() {
+				g.Log().Warningf(ctx, "[Sandbox] 无法隔离网络命名空间，按 sandbox.allowUnisolatedNetwork 不隔离网络执行命令: %v", probeErr)
+			} else {
+				g.Log().Errorf(ctx, "[Sandbox] 无法隔离网络命名空间，不允许联网的命令将被拒绝执行: %v", probeErr)
+			}
+			return
+		}
+		g.Log().Infof(ctx, "[Sandbox] 网络命名空间隔离可用")
+	})
+	return probeErr
+}
+
+// Run 在 dir 下以 limits 执行 argv，仅在命令无法启动时返回错误
+func Run(ctx context.Context, dir string, argv []string, limits Limits) (*Result, error) {
+	if len(argv) == 0 {
+		return nil, errors.New("sandbox: 命令为空")
+	}
+	runCtx := ctx
+	if limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		runCtx, cancel = context.WithTimeout(ctx, limits.Timeout)
+		defer cancel()
+	}
+
+	// 不允许联网但无法隔离时拒绝执行，除非配置 sandbox.allowUnisolatedNetwork
+	isolate := !limits.Network
+	if isolate {
+		if err := Probe(ctx); err != nil {
+			if !g.Cfg().MustGet(ctx, "sandbox.allowUnisolatedNetwork").Bool() {
+				return nil, fmt.Errorf("sandbox: 当前环境无法隔离网络（%v），已拒绝执行；如可接受命令联网，请设置 sandbox.allowUnisolatedNetwork", err)
+			}
+			isolate = false
+		}
+	}
+
+	maxOutput := limits.MaxOutputKB * 1024
+	stdout := &limitedBuffer{max: maxOutput}
+	stderr := &limitedBuffer{max: maxOutput}
+	env := buildEnv(ctx, dir)
+
+	start := time.Now()
+	cmd, err := startCommand(runCtx, dir, argv, env, limits, isolate, stdout, stderr)
+	if err != nil {
+		return nil, err
+	}
+	waitErr := cmd.Wait()
+	cleanup(cmd)
+
+	res := &Result{
+		ExitCode:        -1,
+		Stdout:          stdout.String(),
+		Stderr:          stderr.String(),
+		StdoutTruncated: stdout.truncated,
+		StderrTruncated: stderr.truncated,
+		TimedOut:        errors.Is(runCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil,
+		NetworkIsolated: isolate,
+		DurationMs:      time.Since(start).Milliseconds(),
+	}
+	if cmd.ProcessState != nil {
+		res.ExitCode = cmd.ProcessState.ExitCode()
+		res.Signal = exitSignal(cmd.ProcessState)
+	} else if waitErr != nil {
+		return nil, waitErr
+	}
+	return res, nil
+}
+
+// buildEnv 子进程的环境变量：不继承服务进程的环境（密钥、数据库地址等），
+// 只保留系统基础变量和 sandbox.envPassthrough 中列出的变量
+func buildEnv(ctx context.Context, dir string) []string {
+	env := baseEnv(dir)
+	env = append(env, "PYTHONIOENCODING=utf-8")
+	for _, key := range g.Cfg().MustGet(ctx, "sandbox.envPassthrough").Strings() {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+v)
+		}
+	}
+	return env
+}
+
+// limitedBuffer 只保留前 max 字节的输出，超出部分丢弃并标记截断；max 为 0 时不限制
+type limitedBuffer struct {
+	max       int
+	buf       bytes.Buffer
+	truncated bool
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if b.max > 0 {
+		remain := b.max - b.buf.Len()
+		if remain < len(p) {
+			b.truncated = true
+			if remain <= 0 {
+				return n, nil
+			}
+			p = p[:remain]
+		}
+	}
+	b.buf.Write(p)
+	return n, nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
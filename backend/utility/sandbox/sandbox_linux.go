@@ -0,0 +1,123 @@
+package sandbox
+
+import (
+	"backend/utility/sandbox/internal/reexec"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+
+	"golang.org/x/sys/unix"
+)
+
+// startCommand 经 reexec 设置 rlimit 后启动命令，进程组独立；isolate 为 true 时隔离网络命名空间
+func startCommand(ctx context.Context, dir string, argv, env []string, limits Limits, isolate bool, stdout, stderr io.Writer) (*exec.Cmd, error) {
+	cmd := newCommand(ctx, dir, argv, env, limits, isolate, stdout, stderr)
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动命令失败: %w", err)
+	}
+	return cmd, nil
+}
+
+// probeNetworkIsolation 以隔离网络命名空间的方式启动一次 true，能启动即表示支持
+func probeNetworkIsolation(ctx context.Context) error {
+	dir := os.TempDir()
+	cmd := newCommand(ctx, dir, []string{"true"}, baseEnv(dir), Limits{}, true, io.Discard, io.Discard)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	_ = cmd.Wait()
+	return nil
+}
+
+var (
+	userNSOnce sync.Once
+	userNSErr  error
+)
+
+// scopedNProc 非 root 运行时能否为命令单独创建 user namespace。内核（5.14 起）按 user namespace 统计 RLIMIT_NPROC，
+// 命令在独立的命名空间中运行时，限制只覆盖沙箱内的进程，而不是服务用户的全部进程与线程。
+// root 不受 RLIMIT_NPROC 约束，不需要也不创建；不支持时退回按服务用户计数并记录告警
+func scopedNProc(ctx context.Context) bool {
+	if os.Geteuid() == 0 {
+		return false
+	}
+	userNSOnce.Do(func() {
+		cmd := exec.Command("true")
+		cmd.SysProcAttr = &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWUSER}
+		mapCurrentUser(cmd.SysProcAttr)
+		if userNSErr = cmd.Run(); userNSErr != nil {
+			g.Log().Warningf(ctx, "[Sandbox] 无法创建 user namespace，maxProcs 将按运行服务的系统用户计数（含服务自身线程）: %v", userNSErr)
+		}
+	})
+	return userNSErr == nil
+}
+
+// mapCurrentUser 新 user namespace 内只映射服务自身的 uid/gid，命令的文件权限与服务一致
+func mapCurrentUser(attr *syscall.SysProcAttr) {
+	uid, gid := os.Geteuid(), os.Getegid()
+	attr.UidMappings = []syscall.SysProcIDMap{{ContainerID: uid, HostID: uid, Size: 1}}
+	attr.GidMappings = []syscall.SysProcIDMap{{ContainerID: gid, HostID: gid, Size: 1}}
+	attr.GidMappingsEnableSetgroups = false
+}
+
+func newCommand(ctx context.Context, dir string, argv, env []string, limits Limits, isolate bool, stdout, stderr io.Writer) *exec.Cmd {
+	rlimits := reexec.Rlimits{
+		CPUSeconds: limits.CPUSeconds,
+		AsBytes:    limits.MemoryMB << 20,
+		FSizeBytes: limits.FileSizeMB << 20,
+		NProc:      limits.MaxProcs,
+	}
+	cmd := exec.CommandContext(ctx, "/proc/self/exe")
+	cmd.Args = append([]string{"sandbox"}, argv...)
+	cmd.Dir = dir
+	cmd.Env = append(append([]string{}, env...), reexec.EnvKey+"="+reexec.Encode(rlimits))
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	attr := &syscall.SysProcAttr{Setpgid: true}
+	if isolate {
+		// 等同 unshare -n（非 root 时 unshare -rn）：新网络命名空间内只有未启用的 lo
+		attr.Cloneflags = syscall.CLONE_NEWNET
+	}
+	// 非 root 时隔离网络需要 user namespace；限制进程数时也单独创建，使 RLIMIT_NPROC 只统计沙箱内的进程
+	if os.Geteuid() != 0 && (isolate || limits.MaxProcs > 0 && scopedNProc(ctx)) {
+		attr.Cloneflags |= syscall.CLONE_NEWUSER
+		mapCurrentUser(attr)
+	}
+	cmd.SysProcAttr = attr
+	// 超时或请求取消时结束整个进程组，而不只是 shell
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	cmd.WaitDelay = 2 * time.Second
+	return cmd
+}
+
+// cleanup 命令结束后结束进程组内残留的后台进程
+func cleanup(cmd *exec.Cmd) {
+	_ = syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+func exitSignal(state *os.ProcessState) string {
+	if ws, ok := state.Sys().(syscall.WaitStatus); ok && ws.Signaled() {
+		return unix.SignalName(ws.Signal())
+	}
+	return ""
+}
+
+func baseEnv(dir string) []string {
+	return []string{
+		"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin",
+		"HOME=" + dir,
+		"TMPDIR=" + dir,
+		"LANG=C.UTF-8",
+		"LC_ALL=C.UTF-8",
+	}
+}
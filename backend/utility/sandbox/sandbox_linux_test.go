@@ -0,0 +1,206 @@
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/gogf/gf/v2/frame/g"
+	"github.com/gogf/gf/v2/os/gcfg"
+)
+
+// useConfig 以 content 替换配置，测试结束后恢复
+func useConfig(t *testing.T, content string) {
+	t.Helper()
+	adapter, err := gcfg.NewAdapterContent(content)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original := g.Cfg().GetAdapter()
+	g.Cfg().SetAdapter(adapter)
+	t.Cleanup(func() { g.Cfg().SetAdapter(original) })
+}
+
+func TestLimitedBuffer(t *testing.T) {
+	cases := []struct {
+		max       int
+		writes    []string
+		want      string
+		truncated bool
+	}{
+		{0, []string{"hello", "world"}, "helloworld", false},
+		{10, []string{"hello", "world"}, "helloworld", false},
+		{8, []string{"hello", "world"}, "hellowor", true},
+		{5, []string{"hello", "world"}, "hello", true},
+		{3, []string{"hello"}, "hel", true},
+	}
+	for _, c := range cases {
+		b := &limitedBuffer{max: c.max}
+		for _, w := range c.writes {
+			// 截断时也须返回完整长度，否则子进程输出管道会报 short write
+			if n, err := b.Write([]byte(w)); n != len(w) || err != nil {
+				t.Fatalf("max=%d: Write(%q) = %d, %v", c.max, w, n, err)
+			}
+		}
+		if b.String() != c.want || b.truncated != c.truncated {
+			t.Errorf("max=%d: 得到 %q truncated=%v，期望 %q truncated=%v", c.max, b.String(), b.truncated, c.want, c.truncated)
+		}
+	}
+}
+
+func TestLimitsFor(t *testing.T) {
+	useConfig(t, `
+sandbox:
+  tiers:
+    default:
+      timeout: "30s"
+      cpuSeconds: 20
+      network: false
+    anonymous:
+      timeout: "5s"
+      memoryMB: 256
+    admin:
+      cpuSeconds: 0
+      network: true
+`)
+	ctx := context.Background()
+	cases := []struct {
+		tier string
+		want Limits
+	}{
+		{TierDefault, Limits{Timeout: 30 * time.Second, CPUSeconds: 20, MemoryMB: 1024, FileSizeMB: 64, MaxProcs: 512, MaxOutputKB: 64}},
+		{TierAnonymous, Limits{Timeout: 5 * time.Second, CPUSeconds: 20, MemoryMB: 256, FileSizeMB: 64, MaxProcs: 512, MaxOutputKB: 64}},
+		{TierAdmin, Limits{Timeout: 30 * time.Second, CPUSeconds: 0, MemoryMB: 1024, FileSizeMB: 64, MaxProcs: 512, MaxOutputKB: 64, Network: true}},
+		{"unknown", Limits{Timeout: 30 * time.Second, CPUSeconds: 20, MemoryMB: 1024, FileSizeMB: 64, MaxProcs: 512, MaxOutputKB: 64}},
+	}
+	for _, c := range cases {
+		if got := LimitsFor(ctx, c.tier); got != c.want {
+			t.Errorf("LimitsFor(%s) = %+v，期望 %+v", c.tier, got, c.want)
+		}
+	}
+}
+
+func TestRunTimeoutKillsProcessGroup(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Now()
+	res, err := Run(context.Background(), dir, []string{"/bin/sh", "-c", "sleep 30 & echo $! > bg.pid; wait"},
+		Limits{Timeout: 500 * time.Millisecond, Network: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.TimedOut || res.Signal != "SIGKILL" {
+		t.Fatalf("应超时并被 SIGKILL 结束，实际 %+v", res)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("超时后未及时返回: %s", d)
+	}
+	b, err := os.ReadFile(filepath.Join(dir, "bg.pid"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 后台进程已被结束（已回收或仅剩僵尸）
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		stat, err := os.ReadFile("/proc/" + strconv.Itoa(pid) + "/stat")
+		if err != nil || strings.Contains(string(stat), ") Z ") {
+			break
+		}
+		if time.Now().After(deadline) {
+			_ = syscall.Kill(pid, syscall.SIGKILL)
+			t.Fatalf("后台进程 %d 在超时后仍在运行", pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func TestRunFileSizeLimit(t *testing.T) {
+	dir := t.TempDir()
+	res, err := Run(context.Background(), dir, []string{"/bin/sh", "-c", "head -c 3145728 /dev/zero > big.bin"},
+		Limits{Timeout: 10 * time.Second, FileSizeMB: 1, Network: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ExitCode == 0 {
+		t.Fatalf("写入超出 RLIMIT_FSIZE 应失败，实际 %+v", res)
+	}
+	info, err := os.Stat(filepath.Join(dir, "big.bin"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Size() > 1<<20 {
+		t.Fatalf("文件大小 %d 超出 1MB 限制", info.Size())
+	}
+}
+
+func TestRunNetworkIsolation(t *testing.T) {
+	if err := Probe(context.Background()); err != nil {
+		t.Skipf("当前环境无法隔离网络命名空间: %v", err)
+	}
+	// /proc/net/dev 按网络命名空间显示，隔离后只有 lo
+	res, err := Run(context.Background(), t.TempDir(), []string{"/bin/sh", "-c", "tail -n +3 /proc/net/dev | cut -d: -f1"},
+		Limits{Timeout: 10 * time.Second, Network: false})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.NetworkIsolated || strings.Join(strings.Fields(res.Stdout), ",") != "lo" {
+		t.Fatalf("隔离后应只有 lo，实际 isolated=%v, stdout=%q, stderr=%q", res.NetworkIsolated, res.Stdout, res.Stderr)
+	}
+}
+
+func TestRunRefusesWithoutNetworkIsolation(t *testing.T) {
+	_ = Probe(context.Background())
+	original := probeErr
+	probeErr = errors.New("模拟：不支持网络命名空间")
+	t.Cleanup(func() { probeErr = original })
+
+	useConfig(t, "sandbox:\n  allowUnisolatedNetwork: false\n")
+	if _, err := Run(context.Background(), t.TempDir(), []string{"true"}, Limits{Timeout: 5 * time.Second}); err == nil {
+		t.Fatal("无法隔离网络时应拒绝执行 network=false 的命令")
+	}
+	if res, err := Run(context.Background(), t.TempDir(), []string{"true"}, Limits{Timeout: 5 * time.Second, Network: true}); err != nil || res.ExitCode != 0 {
+		t.Fatalf("允许联网的命令应照常执行: %+v, %v", res, err)
+	}
+
+	useConfig(t, "sandbox:\n  allowUnisolatedNetwork: true\n")
+	res, err := Run(context.Background(), t.TempDir(), []string{"true"}, Limits{Timeout: 5 * time.Second})
+	if err != nil || res.ExitCode != 0 || res.NetworkIsolated {
+		t.Fatalf("配置 allowUnisolatedNetwork 后应不隔离执行: %+v, %v", res, err)
+	}
+}
+
+func TestRunMaxProcsScopedToSandbox(t *testing.T) {
+	if os.Geteuid() == 0 {
+		t.Skip("root 不受 RLIMIT_NPROC 约束")
+	}
+	if !scopedNProc(context.Background()) {
+		t.Skip("当前环境无法创建 user namespace，maxProcs 按服务用户计数")
+	}
+	// 测试进程自身的线程已超过 3 个：只有按沙箱计数时命令才能再创建 2 个子进程
+	res, err := Run(context.Background(), t.TempDir(), []string{"/bin/sh", "-c", "sleep 0.1 & sleep 0.1 & wait; echo ok"},
+		Limits{Timeout: 10 * time.Second, MaxProcs: 3, Network: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ExitCode != 0 || strings.TrimSpace(res.Stdout) != "ok" {
+		t.Fatalf("maxProcs 应只统计沙箱内的进程: %+v", res)
+	}
+	// 沙箱内超出限制的 fork 仍然失败
+	res, err = Run(context.Background(), t.TempDir(), []string{"/bin/sh", "-c", "for i in 1 2 3 4 5 6; do sleep 1 & done; wait"},
+		Limits{Timeout: 10 * time.Second, MaxProcs: 3, Network: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.ExitCode == 0 {
+		t.Fatalf("超出 maxProcs 的 fork 应失败: %+v", res)
+	}
+}
@@ -0,0 +1,48 @@
+//go:build !linux
+
+package sandbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// startCommand 非 Linux 系统不支持 rlimit 与网络命名空间，只保留超时、环境变量与输出截断
+func startCommand(ctx context.Context, dir string, argv, env []string, limits Limits, isolate bool, stdout, stderr io.Writer) (*exec.Cmd, error) {
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.WaitDelay = 2 * time.Second
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("启动命令失败: %w", err)
+	}
+	return cmd, nil
+}
+
+func probeNetworkIsolation(ctx context.Context) error {
+	return errors.New("当前系统不支持网络命名空间")
+}
+
+func cleanup(cmd *exec.Cmd) {}
+
+func exitSignal(state *os.ProcessState) string {
+	return ""
+}
+
+// baseEnv 保留 Windows 上运行 cmd.exe 与 Python 所需的系统变量
+func baseEnv(dir string) []string {
+	env := []string{"HOME=" + dir, "TMPDIR=" + dir, "TEMP=" + dir, "TMP=" + dir}
+	for _, key := range []string{"PATH", "Path", "PATHEXT", "SystemRoot", "SystemDrive", "ComSpec", "WINDIR", "LANG"} {
+		if v, ok := os.LookupEnv(key); ok {
+			env = append(env, key+"="+v)
+		}
+	}
+	return env
+}
@@ -1,22 +1,38 @@
 /**
- * 聊天会话管理：创建、删除、切换、保存会话（已登录→数据库，未登录→本地存储）
+ * 聊天会话管理：创建、删除、切换、保存会话
+ * 消息由后端在每轮回复结束时写入会话；已登录只同步标题，未登录另在本地存储保留一份用于展示
  */
 
 import { useState, useEffect, useCallback, useRef } from 'react';
 import { message } from 'antd';
 import type { ChatSession, Message, UseChatSessionsReturn } from '../types/chat';
-import ChatHistoryService from '@/services/chatHistory';
+import ChatHistoryService, { type Message as ServerMessage } from '@/services/chatHistory';
 
 /** 未登录用户的本地存储 key（与云端数据隔离） */
 const STORAGE_KEY_LOCAL = 'ai_chat_sessions_local';
 
+/** 后端消息转为页面消息（含分支版本信息） */
+const toMessage = (msg: ServerMessage): Message => ({
+  id: msg.id,
+  msg_id: msg.msg_id,
+  content: msg.content,
+  isUser: msg.isUser,
+  timestamp: new Date(msg.timestamp),
+  ...(msg.reasoningContent ? { reasoningContent: msg.reasoningContent } : {}),
+  ...(msg.parent_msg_id ? { parent_msg_id: msg.parent_msg_id } : {}),
+  ...(msg.siblings?.length ? { siblings: msg.siblings } : {}),
+  ...(msg.citations?.length ? { citations: msg.citations } : {}),
+});
+
 export const useChatSessions = (): UseChatSessionsReturn => {
   const [currentSessionId, setCurrentSessionId] = useState<string>('');
   const [chatSessions, setChatSessions] = useState<ChatSession[]>([]);
   const [messages, setMessages] = useState<Message[]>([]);
+  const currentSessionIdRef = useRef(currentSessionId);
+  currentSessionIdRef.current = currentSessionId;
 
   const generateMsgId = useCallback((): string => {
-    return `${Date.now()}`;
+    return crypto.randomUUID();
   }, []);
 
   // 已登录保存到数据库，未登录保存到本地存储
@@ -25,23 +41,13 @@ export const useChatSessions = (): UseChatSessionsReturn => {
       const token = localStorage.getItem('access_token');
 
       if (token) {
-        // 已登录：保存到数据库
+        // 已登录：同步会话标题（消息由后端写入）
         if (currentSessionId) {
           const currentSession = sessions.find(s => s.id === currentSessionId);
           if (currentSession) {
-            const apiMessages: any[] = currentSession.messages.map(msg => ({
-              id: msg.id,
-              msg_id: msg.msg_id,
-              content: msg.content,
-              isUser: msg.isUser,
-              timestamp: msg.timestamp.toISOString(),
-              ...(msg.reasoningContent ? { reasoningContent: msg.reasoningContent } : {}),
-            }));
-
             const res = await ChatHistoryService.saveSession({
               id: currentSession.id,
               title: currentSession.title,
-              messages: apiMessages
             });
 
             if (res.id && res.id !== currentSession.id) {
@@ -89,14 +95,7 @@ export const useChatSessions = (): UseChatSessionsReturn => {
 
             const fullSession: ChatSession = {
               ...sessions[0],
-              messages: detailRes.messages.map((msg: any) => ({
-                id: msg.id,
-                msg_id: msg.msg_id,
-                content: msg.content,
-                isUser: msg.isUser,
-                timestamp: new Date(msg.timestamp),
-                ...(msg.reasoningContent ? { reasoningContent: msg.reasoningContent } : {}),
-              }))
+              messages: detailRes.messages.map(toMessage)
             };
 
             const updatedSessions = [...sessions];
@@ -173,20 +172,13 @@ export const useChatSessions = (): UseChatSessionsReturn => {
     setCurrentSessionId(newSessionId);
     setMessages(newSession.messages);
 
-    // 如果已登录，立即保存到后端
+    // 如果已登录，立即在后端创建会话（问候语只在本地展示）
     const token = localStorage.getItem('access_token');
     if (token) {
       try {
         await ChatHistoryService.saveSession({
           id: newSessionId,
           title: '新对话',
-          messages: newSession.messages.map(msg => ({
-            id: msg.id,
-            msg_id: msg.msg_id,
-            content: msg.content,
-            isUser: msg.isUser,
-            timestamp: msg.timestamp.toISOString(),
-          })),
         });
       } catch (error) {
         console.error('保存新会话到后端失败:', error);
@@ -211,14 +203,7 @@ export const useChatSessions = (): UseChatSessionsReturn => {
     if (token) {
       try {
         const detailRes = await ChatHistoryService.getSession(sessionId);
-        const messages = detailRes.messages.map((msg: any) => ({
-          id: msg.id,
-          msg_id: msg.msg_id,
-          content: msg.content,
-          isUser: msg.isUser,
-          timestamp: new Date(msg.timestamp),
-          ...(msg.reasoningContent ? { reasoningContent: msg.reasoningContent } : {}),
-        }));
+        const messages = detailRes.messages.map(toMessage);
 
         setMessages(messages);
         setCurrentSessionId(sessionId);
@@ -240,6 +225,22 @@ export const useChatSessions = (): UseChatSessionsReturn => {
     }
   }, [chatSessions]);
 
+  // 从后端重新拉取当前分支（切换版本、重新生成或编辑后调用）
+  const reloadSession = useCallback(async (sessionId: string) => {
+    try {
+      const detailRes = await ChatHistoryService.getSession(sessionId);
+      const messages = detailRes.messages.map(toMessage);
+      setChatSessions(prev => prev.map(s =>
+        s.id === sessionId ? { ...s, messages } : s
+      ));
+      if (sessionId === currentSessionIdRef.current) {
+        setMessages(messages);
+      }
+    } catch (error) {
+      console.error('获取会话详情失败:', error);
+    }
+  }, []);
+
   // 删除会话
   const deleteSession = useCallback(async (sessionId: string) => {
     const token = localStorage.getItem('access_token');
@@ -341,6 +342,7 @@ export const useChatSessions = (): UseChatSessionsReturn => {
     // 操作方法
     createNewSession,
     loadSession,
+    reloadSession,
     deleteSession,
     updateCurrentSession,
     setMessages,
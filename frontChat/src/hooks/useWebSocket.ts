@@ -1,6 +1,6 @@
 /**
  * WebSocket Hook - 用于定时任务完成等实时通知
- * 连接 /gateway/ws，支持 ready、cron_complete、pong 等消息
+ * 连接 /gateway/ws，支持 ready、cron_complete、index_job_progress、pong 等消息
  */
 import { useEffect, useRef, useState, useCallback } from 'react';
 import { API_CONFIG } from '@/utils/axios/config';
@@ -13,6 +13,21 @@ export interface WsMessage {
   status?: string;
 }
 
+/** 索引任务进度（status：0 排队，1 执行中，2 成功，3 失败；stage：extract/split/embed/store/qa/done） */
+export interface IndexJobProgress {
+  job_id: string;
+  documents_id: number;
+  knowledge_name: string;
+  file_name: string;
+  status: number;
+  stage: string;
+  done: number;
+  total: number;
+  chunk_count: number;
+  attempts: number;
+  error: string;
+}
+
 function getWsUrl(): string {
   const base = API_CONFIG.BASE_URL.replace(/\/$/, '');
   const wsProtocol = base.startsWith('https') ? 'wss:' : 'ws:';
@@ -25,6 +40,8 @@ export interface UseWebSocketOptions {
   enabled?: boolean;
   /** 收到 cron_complete 时的回调 */
   onCronComplete?: (payload: { cron_id: number; cron_name: string; success: boolean }) => void;
+  /** 收到 index_job_progress 时的回调（仅推送给任务所属用户） */
+  onIndexJobProgress?: (payload: IndexJobProgress) => void;
   /** 收到任意消息时的回调 */
   onMessage?: (msg: WsMessage) => void;
   /** 连接状态变化 */
@@ -39,6 +56,7 @@ export function useWebSocket(options: UseWebSocketOptions = {}) {
   const {
     enabled = true,
     onCronComplete,
+    onIndexJobProgress,
     onMessage,
     onStateChange,
     reconnectInterval = 3000,
@@ -50,8 +68,10 @@ export function useWebSocket(options: UseWebSocketOptions = {}) {
   const reconnectCountRef = useRef(0);
   const reconnectTimerRef = useRef<ReturnType<typeof setTimeout> | null>(null);
   const onCronCompleteRef = useRef(onCronComplete);
+  const onIndexJobProgressRef = useRef(onIndexJobProgress);
   const onMessageRef = useRef(onMessage);
   onCronCompleteRef.current = onCronComplete;
+  onIndexJobProgressRef.current = onIndexJobProgress;
   onMessageRef.current = onMessage;
 
   const setStateAndNotify = useCallback(
@@ -103,6 +123,10 @@ export function useWebSocket(options: UseWebSocketOptions = {}) {
               success: Boolean(p.success),
             });
           }
+
+          if (msg.type === 'index_job_progress' && msg.payload && onIndexJobProgressRef.current) {
+            onIndexJobProgressRef.current(msg.payload as unknown as IndexJobProgress);
+          }
         } catch {
           // 非 JSON 消息忽略
         }
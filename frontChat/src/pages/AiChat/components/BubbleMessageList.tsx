@@ -1,11 +1,12 @@
 /**
  * @fileoverview 气泡消息列表
  * @description 使用 Ant Design X 的 Bubble 渲染用户/AI 气泡消息，
- * 支持移动端样式、连接状态指示、思维链展示与实时回复。
+ * 支持移动端样式、连接状态指示、思维链展示与实时回复，
+ * 以及重新生成、编辑重发与消息版本切换。
  */
 import React, { useMemo, useState } from 'react';
-import { Card, Avatar, Button } from 'antd';
-import { DownOutlined, UpOutlined } from '@ant-design/icons';
+import { Card, Avatar, Button, Input, Space } from 'antd';
+import { DownOutlined, UpOutlined, LeftOutlined, RightOutlined, RedoOutlined, EditOutlined } from '@ant-design/icons';
 import { Bubble, XProvider, ThoughtChain } from '@ant-design/x';
 import zhCN from '@ant-design/x/locale/zh_CN';
 import enUS from '@ant-design/x/locale/en_US';
@@ -15,7 +16,7 @@ import Latex from '@ant-design/x-markdown/plugins/Latex';
 import Mermaid from '@ant-design/x-markdown/plugins/Mermaid';
 import { RobotOutlined, UserOutlined } from '@ant-design/icons';
 import { useTranslation } from 'react-i18next';
-import type { Message } from '@/types/chat';
+import type { Citation, Message } from '@/types/chat';
 import { SSEConnectionState } from '@/utils/sse/sse';
 import type { ThoughtChainItemType } from '@ant-design/x';
 import '@ant-design/x-markdown/themes/light.css';
@@ -46,20 +47,41 @@ const renderMarkdown = (content: React.ReactNode) => {
   );
 };
 
-/** AI 消息内容：主内容 + 可展开的思考过程 */
+/** 引用来源列表：与回答中的 [n] 标记对应 */
+const CitationList: React.FC<{ citations: Citation[]; t: (key: string) => string }> = ({ citations, t }) => (
+  <div style={{ borderTop: '1px solid rgba(0,0,0,0.06)', paddingTop: 8, fontSize: 12, color: '#8c8c8c' }}>
+    <div style={{ marginBottom: 4 }}>{t('chat.citations.title')}</div>
+    {citations.map((c) => (
+      <div key={c.index} title={c.chunk_id} style={{ lineHeight: 1.8 }}>
+        [{c.index}] {c.source === 'web' ? t('chat.citations.web') : c.document_name}
+        {c.knowledge_name ? ` · ${c.knowledge_name}` : ''}
+      </div>
+    ))}
+  </div>
+);
+
+/** AI 消息内容：主内容 + 引用来源 + 可展开的思考过程 */
 const AssistantMessageContent: React.FC<{
   content: string;
   reasoningContent?: string;
+  citations?: Citation[];
   renderMarkdown: (c: React.ReactNode) => React.ReactNode;
   t: (key: string) => string;
-}> = ({ content, reasoningContent, renderMarkdown, t }) => {
+}> = ({ content, reasoningContent, citations, renderMarkdown, t }) => {
   const [expanded, setExpanded] = useState(false);
+  const citationList = citations?.length ? <CitationList citations={citations} t={t} /> : null;
   if (!reasoningContent) {
-    return <>{renderMarkdown(content)}</>;
+    return (
+      <div style={{ display: 'flex', flexDirection: 'column', gap: 8 }}>
+        {renderMarkdown(content)}
+        {citationList}
+      </div>
+    );
   }
   return (
     <div style={{ display: 'flex', flexDirection: 'column', gap: 8 }}>
       {renderMarkdown(content)}
+      {citationList}
       <div style={{ marginTop: 4 }}>
         <Button
           type="text"
@@ -96,6 +118,65 @@ const AssistantMessageContent: React.FC<{
   );
 };
 
+/** 消息操作栏：版本切换（‹ 2/3 ›）、重新生成（AI 回复）、编辑（用户消息） */
+const MessageActions: React.FC<{
+  message: Message;
+  disabled: boolean;
+  onRegenerate?: (msgId: string) => void;
+  onStartEdit?: () => void;
+  onSwitchVersion?: (msgId: string) => void;
+  t: (key: string) => string;
+}> = ({ message, disabled, onRegenerate, onStartEdit, onSwitchVersion, t }) => {
+  const siblings = message.siblings ?? [];
+  const index = siblings.indexOf(message.msg_id);
+  const actionStyle = { color: '#8c8c8c' } as React.CSSProperties;
+  return (
+    <Space size={2}>
+      {siblings.length > 1 && index >= 0 && onSwitchVersion && (
+        <>
+          <Button type="text" size="small" style={actionStyle} icon={<LeftOutlined />}
+            disabled={disabled || index === 0}
+            onClick={() => onSwitchVersion(siblings[index - 1])} />
+          <span style={{ fontSize: 12, color: '#8c8c8c' }}>{index + 1}/{siblings.length}</span>
+          <Button type="text" size="small" style={actionStyle} icon={<RightOutlined />}
+            disabled={disabled || index === siblings.length - 1}
+            onClick={() => onSwitchVersion(siblings[index + 1])} />
+        </>
+      )}
+      {message.isUser
+        ? onStartEdit && (
+            <Button type="text" size="small" style={actionStyle} icon={<EditOutlined />}
+              disabled={disabled} title={t('chat.turn.edit')} onClick={onStartEdit} />
+          )
+        : onRegenerate && (
+            <Button type="text" size="small" style={actionStyle} icon={<RedoOutlined />}
+              disabled={disabled} title={t('chat.turn.regenerate')} onClick={() => onRegenerate(message.msg_id)} />
+          )}
+    </Space>
+  );
+};
+
+/** 用户消息编辑框 */
+const MessageEditor: React.FC<{
+  initialValue: string;
+  onSubmit: (text: string) => void;
+  onCancel: () => void;
+  t: (key: string) => string;
+}> = ({ initialValue, onSubmit, onCancel, t }) => {
+  const [value, setValue] = useState(initialValue);
+  return (
+    <div style={{ display: 'flex', flexDirection: 'column', gap: 8, minWidth: 240 }}>
+      <Input.TextArea value={value} autoSize={{ minRows: 2, maxRows: 8 }} onChange={(e) => setValue(e.target.value)} />
+      <Space style={{ justifyContent: 'flex-end' }}>
+        <Button size="small" onClick={onCancel}>{t('chat.turn.cancel')}</Button>
+        <Button size="small" type="primary" disabled={!value.trim()} onClick={() => onSubmit(value.trim())}>
+          {t('chat.turn.send')}
+        </Button>
+      </Space>
+    </div>
+  );
+};
+
 interface BubbleMessageListProps {
   messages: Message[];
   isMobile: boolean;
@@ -109,6 +190,12 @@ interface BubbleMessageListProps {
   documentsCount?: number;
   hasKnowledgeBase?: boolean;
   currentToolStatus?: string;
+  /** 重新生成 AI 回复 */
+  onRegenerate?: (msgId: string) => void;
+  /** 编辑用户消息并重新发送 */
+  onEdit?: (msgId: string, text: string) => void;
+  /** 切换到同级的另一个版本 */
+  onSwitchVersion?: (msgId: string) => void;
 }
 
 const hideAvatar = { display: 'none' } as React.CSSProperties;
@@ -128,9 +215,13 @@ const BubbleMessageList: React.FC<BubbleMessageListProps> = ({
   documentsCount = 0,
   hasKnowledgeBase = false,
   currentToolStatus = '',
+  onRegenerate,
+  onEdit,
+  onSwitchVersion,
 }) => {
   const { t, i18n } = useTranslation();
   const locale = i18n.language === 'en' ? enUS : zhCN;
+  const [editingMsgId, setEditingMsgId] = useState<string | null>(null);
 
   const thoughtChainItems = useMemo<ThoughtChainItemType[]>(() => {
     const connecting = connectionState === SSEConnectionState.CONNECTING || connectionState === SSEConnectionState.RECONNECTING;
@@ -193,7 +284,29 @@ const BubbleMessageList: React.FC<BubbleMessageListProps> = ({
                 avatar: m.isUser
                   ? <Avatar icon={<UserOutlined />} style={userAvatarStyle} />
                   : <Avatar icon={<RobotOutlined />} style={aiAvatarStyle} />,
-                content: m.isUser
+                footer: (
+                  <MessageActions
+                    message={m}
+                    disabled={loading}
+                    onRegenerate={onRegenerate}
+                    onStartEdit={onEdit && !m.attachments?.length ? () => setEditingMsgId(m.msg_id) : undefined}
+                    onSwitchVersion={onSwitchVersion}
+                    t={t}
+                  />
+                ),
+                content: m.isUser && editingMsgId === m.msg_id && onEdit
+                  ? (
+                      <MessageEditor
+                        initialValue={m.content}
+                        onCancel={() => setEditingMsgId(null)}
+                        onSubmit={(text) => {
+                          setEditingMsgId(null);
+                          onEdit(m.msg_id, text);
+                        }}
+                        t={t}
+                      />
+                    )
+                  : m.isUser
                   ? (m.attachments?.length
                       ? (
                           <div style={{ display: 'flex', flexDirection: 'column', gap: 8 }}>
@@ -236,8 +349,8 @@ const BubbleMessageList: React.FC<BubbleMessageListProps> = ({
                           </div>
                         )
                       : m.content)
-                  : m.reasoningContent
-                    ? <AssistantMessageContent content={m.content} reasoningContent={m.reasoningContent} renderMarkdown={renderMarkdown} t={t} />
+                  : m.reasoningContent || m.citations?.length
+                    ? <AssistantMessageContent content={m.content} reasoningContent={m.reasoningContent} citations={m.citations} renderMarkdown={renderMarkdown} t={t} />
                     : m.content,
               })),
               ...(loading && thoughtChainItems.length > 0 && !currentAiMessage
@@ -17,7 +17,7 @@ interface UseChatComposerParams {
   fetchReferenceDocuments: (query: string) => Promise<ReferenceDocument[]>;
   setReferenceDocuments: (docs: ReferenceDocument[]) => void;
   setShowReferences: (show: boolean) => void;
-  send: (text: string, sessionId: string, uploadedFiles?: string[], multiContent?: MessagePart[]) => void;
+  send: (text: string, sessionId: string, uploadedFiles?: string[], multiContent?: MessagePart[], msgId?: string) => void;
   streamingLoading: boolean;
   /** 上传文件并返回服务端文件名列表，发送前若有附件则调用 */
   uploadFilesIfNeeded?: (sessionId: string) => Promise<string[]>;
@@ -104,7 +104,7 @@ const useChatComposer = (params: UseChatComposerParams) => {
     if (currentUploadedFiles.length > 0 && uploadFilesIfNeeded) {
       fileNames = await uploadFilesIfNeeded(currentSessionId);
     }
-    send(questionText, currentSessionId, fileNames, multiContent);
+    send(questionText, currentSessionId, fileNames, multiContent, userMessage.msg_id);
     clearUploadedFiles?.();
 
     // 异步获取引用文档
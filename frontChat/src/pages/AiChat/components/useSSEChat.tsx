@@ -7,7 +7,9 @@ import { SSEConnectionState } from '@/utils/sse/sse';
 import { XRequest } from '@ant-design/x-sdk';
 import { API_CONFIG } from '@/utils/axios/config';
 import { clearAuthStorage } from '@/utils/axios/interceptors';
-import type { Message, MessagePart } from '@/types/chat';
+import { chatAuthHeaders } from '@/utils/token/anonymousToken';
+import ChatHistoryService from '@/services/chatHistory';
+import type { Citation, Message, MessagePart } from '@/types/chat';
 import { useTranslation } from 'react-i18next';
 
 interface AdvancedSettings {
@@ -23,10 +25,15 @@ interface UseSSEChatParams {
   setMessages: (updater: (prev: Message[]) => Message[]) => void;
   isStudyMode: boolean;
   isDeepThinking?: boolean;
+  /** 重新生成/编辑的轮次结束后回调，用于刷新消息版本信息 */
+  onBranchTurnDone?: (sessionId: string) => void;
 }
 
 interface ChatParams {
   id: string;
+  msg_id?: string;
+  new_msg_id?: string;
+  reply_msg_id?: string;
   question?: string;
   multi_content?: MessagePart[];
   knowledge_name: string;
@@ -41,11 +48,18 @@ interface ChatParams {
 /** 最大重连次数，供外部（index.tsx）消费以保持一致 */
 export const MAX_RECONNECT_ATTEMPTS = 3;
 
+/** 轮次请求：普通发送、重新生成或编辑后重新发送 */
+interface TurnRequest {
+  path: '/gateway/chat' | '/gateway/chat/regenerate' | '/gateway/chat/edit';
+  ids: Pick<ChatParams, 'msg_id' | 'new_msg_id' | 'reply_msg_id'>;
+}
+
 // --- 工厂函数：创建 AI 消息对象 ---
 const createAIMessage = (
   content: string,
   msgId: string,
-  reasoningContent?: string
+  reasoningContent?: string,
+  citations?: Citation[]
 ): Message => ({
   id: Date.now(),
   msg_id: msgId,
@@ -53,17 +67,28 @@ const createAIMessage = (
   isUser: false,
   timestamp: new Date(),
   ...(reasoningContent ? { reasoningContent } : {}),
+  ...(citations?.length ? { citations } : {}),
 });
 
 const useSSEChat = (params: UseSSEChatParams) => {
-  const { selectedKnowledge, advancedSettings, isNetworkEnabled, isStudyMode, isDeepThinking = false, generateMsgId, setMessages } = params;
+  const { selectedKnowledge, advancedSettings, isNetworkEnabled, isStudyMode, isDeepThinking = false, generateMsgId, setMessages, onBranchTurnDone } = params;
   const { t } = useTranslation();
 
   // Refs
   const requestRef = useRef<ReturnType<typeof XRequest> | null>(null);
   const accumulatedMessageRef = useRef<string>('');
   const accumulatedReasoningRef = useRef<string>('');
+  // 回答结束时 citations 事件下发的引用来源
+  const citationsRef = useRef<Citation[]>([]);
   const isUserStoppedRef = useRef<boolean>(false);
+  // 本轮消息 ID，随请求发送，与后端持久化的消息一致
+  const replyMsgIdRef = useRef<string>('');
+  const turnRequestRef = useRef<TurnRequest>({ path: '/gateway/chat', ids: {} });
+  // 后端 turn 事件返回的轮次 ID，停止生成时用于 /chat/cancel
+  const sessionIdRef = useRef<string>('');
+  const turnIdRef = useRef<string>('');
+  // 最后收到的 SSE 事件 id（turnId:seq），重连时作为 Last-Event-ID 续传，避免重复开启轮次
+  const lastEventIdRef = useRef<string>('');
   const retryTimerRef = useRef<ReturnType<typeof setTimeout> | null>(null);
   // 用 ref 保存最新 connectionState，解决 onError 闭包陷阱
   const connectionStateRef = useRef<SSEConnectionState>(SSEConnectionState.DISCONNECTED);
@@ -90,6 +115,7 @@ const useSSEChat = (params: UseSSEChatParams) => {
     setCurrentToolStatus('');
     accumulatedMessageRef.current = '';
     accumulatedReasoningRef.current = '';
+    citationsRef.current = [];
   }, []);
 
   // 清理请求、定时器、缓存
@@ -142,15 +168,26 @@ const useSSEChat = (params: UseSSEChatParams) => {
   const handleDonePayload = useCallback(() => {
     const finalMsg = accumulatedMessageRef.current.trim();
     if (finalMsg) {
-      setMessages((prev) => [
-        ...prev,
-        createAIMessage(finalMsg, generateMsgId(), accumulatedReasoningRef.current.trim() || undefined),
-      ]);
+      // 更新函数可能在 resetStreamState 之后执行，先取出 ref 中的值
+      const aiMessage = createAIMessage(finalMsg, replyMsgIdRef.current || generateMsgId(), accumulatedReasoningRef.current.trim() || undefined, citationsRef.current);
+      setMessages((prev) => [...prev, aiMessage]);
     }
     resetStreamState();
     setLoading(false);
     setConnectionState(SSEConnectionState.DISCONNECTED);
-  }, [generateMsgId, setMessages, resetStreamState]);
+    turnIdRef.current = '';
+    if (turnRequestRef.current.path !== '/gateway/chat') {
+      onBranchTurnDone?.(sessionIdRef.current);
+    }
+  }, [generateMsgId, setMessages, resetStreamState, onBranchTurnDone]);
+
+  const handleTurnEvent = useCallback((chunk: any) => {
+    try {
+      const data = typeof chunk?.data === 'string' ? JSON.parse(chunk.data) : chunk?.data;
+      turnIdRef.current = data?.turn_id || '';
+      if (data?.reply_msg_id) replyMsgIdRef.current = data.reply_msg_id;
+    } catch { /* ignore */ }
+  }, []);
 
   const handleContentPayload = useCallback((payload: string) => {
     let contentSegment = payload;
@@ -189,7 +226,8 @@ const useSSEChat = (params: UseSSEChatParams) => {
   const createConnection = useCallback(async (question: string, sessionId: string, uploadedFiles: string[] = [], multiContent?: MessagePart[], attempt = 0) => {
     if (isUserStoppedRef.current) return;
 
-    const endpoint = `${API_CONFIG.BASE_URL}/gateway/chat`;
+    const { path, ids } = turnRequestRef.current;
+    const endpoint = `${API_CONFIG.BASE_URL}${path}`;
 
     setLoading(true);
     setConnectionState(attempt === 0 ? SSEConnectionState.CONNECTING : SSEConnectionState.RECONNECTING);
@@ -206,9 +244,12 @@ const useSSEChat = (params: UseSSEChatParams) => {
         headers: {
           'Content-Type': 'application/json',
           'Accept': 'text/event-stream',
+          ...chatAuthHeaders(),
+          ...(attempt > 0 && lastEventIdRef.current ? { 'Last-Event-ID': lastEventIdRef.current } : {}),
         },
         params: {
           id: sessionId,
+          ...ids,
           ...(multiContent ? { multi_content: multiContent } : { question }),
           knowledge_name: selectedKnowledge === 'none' ? '' : selectedKnowledge,
           top_k: advancedSettings.topK,
@@ -222,22 +263,52 @@ const useSSEChat = (params: UseSSEChatParams) => {
           onUpdate: (chunk: any) => {
             if (isUserStoppedRef.current) return;
 
+            if (typeof chunk?.id === 'string' && chunk.id) {
+              lastEventIdRef.current = chunk.id;
+            }
+
             if (chunk?.event === 'error') {
               handleErrorEvent(chunk);
               return;
             }
 
+            // 开始流式输出前的业务错误以 JSON 返回：{ code, message }；429 为 token 额度用完
+            if (typeof chunk?.code === 'number' && chunk.code !== 0) {
+              handleErrorEvent({ data: chunk.code === 429 ? t('chat.sse.quotaExceeded') : chunk.message });
+              return;
+            }
+
             if (isFirstChunk) {
               setConnectionState(SSEConnectionState.CONNECTED);
               setReconnectAttempts(0);
               isFirstChunk = false;
             }
 
+            if (chunk?.event === 'turn') {
+              handleTurnEvent(chunk);
+              return;
+            }
+
+            // 轮次被停止（可能来自其他标签页），已生成的内容未被保存，仅本地展示
+            if (chunk?.event === 'cancelled') {
+              replyMsgIdRef.current = '';
+              handleDonePayload();
+              return;
+            }
+
             if (chunk?.event === 'tool_status') {
               handleToolStatusEvent(chunk);
               return;
             }
 
+            if (chunk?.event === 'citations') {
+              try {
+                const parsed = JSON.parse(chunk.data);
+                if (Array.isArray(parsed)) citationsRef.current = parsed;
+              } catch { /* ignore */ }
+              return;
+            }
+
             setCurrentToolStatus('');
 
             // 解析 documents 事件
@@ -326,24 +397,54 @@ const useSSEChat = (params: UseSSEChatParams) => {
     }
   // connectionState 从依赖数组移除，改用 connectionStateRef.current
   }, [selectedKnowledge, advancedSettings, isNetworkEnabled, isStudyMode, isDeepThinking, generateMsgId, setMessages, t,
-      resetStreamState, handleErrorEvent, handleToolStatusEvent, handleDonePayload, handleContentPayload]);
+      resetStreamState, handleErrorEvent, handleTurnEvent, handleToolStatusEvent, handleDonePayload, handleContentPayload]);
 
   // --- 导出方法 ---
 
-  const send = useCallback((text: string, sessionId: string, uploadedFiles: string[] = [], multiContent?: MessagePart[]) => {
+  const startTurn = useCallback((turn: TurnRequest, sessionId: string) => {
     cleanup();
     isUserStoppedRef.current = false;
+    sessionIdRef.current = sessionId;
+    turnIdRef.current = '';
+    lastEventIdRef.current = '';
+    replyMsgIdRef.current = turn.ids.reply_msg_id || '';
+    turnRequestRef.current = turn;
+  }, [cleanup]);
+
+  const send = useCallback((text: string, sessionId: string, uploadedFiles: string[] = [], multiContent?: MessagePart[], msgId?: string) => {
+    startTurn({
+      path: '/gateway/chat',
+      ids: msgId ? { msg_id: msgId, reply_msg_id: generateMsgId() } : {},
+    }, sessionId);
     createConnection(text, sessionId, uploadedFiles, multiContent, 0);
-  }, [createConnection, cleanup]);
+  }, [createConnection, startTurn, generateMsgId]);
+
+  /** 重新生成回复，msgId 为要替换的 AI 回复 */
+  const regenerate = useCallback((sessionId: string, msgId: string) => {
+    startTurn({ path: '/gateway/chat/regenerate', ids: { msg_id: msgId, reply_msg_id: generateMsgId() } }, sessionId);
+    createConnection('', sessionId, [], undefined, 0);
+  }, [createConnection, startTurn, generateMsgId]);
+
+  /** 编辑用户消息 msgId 并以 newMsgId 在新分支上重新发送 */
+  const edit = useCallback((text: string, sessionId: string, msgId: string, newMsgId: string) => {
+    startTurn({ path: '/gateway/chat/edit', ids: { msg_id: msgId, new_msg_id: newMsgId, reply_msg_id: generateMsgId() } }, sessionId);
+    createConnection(text, sessionId, [], undefined, 0);
+  }, [createConnection, startTurn, generateMsgId]);
 
   const stop = useCallback(() => {
     isUserStoppedRef.current = true;
+    // 生成与连接解耦，断开连接不会停止后端生成，需显式取消
+    if (sessionIdRef.current && loading) {
+      ChatHistoryService.cancelTurn(sessionIdRef.current, turnIdRef.current).catch(() => { /* 已结束 */ });
+    }
+    turnIdRef.current = '';
     if (requestRef.current) (requestRef.current as any).abort?.();
     if (retryTimerRef.current) clearTimeout(retryTimerRef.current);
 
     if (accumulatedMessageRef.current.trim()) {
       setMessages((prev) => [
         ...prev,
+        // 停止后的部分回复不会被后端保存，仅本地展示
         createAIMessage(accumulatedMessageRef.current.trim(), generateMsgId(), accumulatedReasoningRef.current.trim() || undefined),
       ]);
     }
@@ -352,7 +453,7 @@ const useSSEChat = (params: UseSSEChatParams) => {
     setLoading(false);
     setConnectionState(SSEConnectionState.DISCONNECTED);
     setConnectionError(null);
-  }, [generateMsgId, setMessages, resetStreamState]);
+  }, [loading, generateMsgId, setMessages, resetStreamState]);
 
   return {
     connectionState,
@@ -365,6 +466,8 @@ const useSSEChat = (params: UseSSEChatParams) => {
     loading,
     documentsCount,
     send,
+    regenerate,
+    edit,
     stop,
   };
 };
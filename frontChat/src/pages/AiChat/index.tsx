@@ -17,6 +17,7 @@ import useSSEChat, { MAX_RECONNECT_ATTEMPTS } from './components/useSSEChat.tsx'
 import useVoiceService from './components/useVoiceService.tsx';
 import InputArea from './components/InputArea';
 import { useChatSettings } from '@/hooks/useChatSettings';
+import ChatHistoryService from '@/services/chatHistory';
 
 
 const AIChat: React.FC = () => {
@@ -27,6 +28,7 @@ const AIChat: React.FC = () => {
     messages,
     createNewSession,
     loadSession,
+    reloadSession,
     deleteSession,
     setMessages,
     generateMsgId,
@@ -72,6 +74,8 @@ const AIChat: React.FC = () => {
     loading: streamingLoading,
     documentsCount,
     send,
+    regenerate,
+    edit,
     stop,
   } = useSSEChat({
     selectedKnowledge,
@@ -81,6 +85,7 @@ const AIChat: React.FC = () => {
     isDeepThinking,
     generateMsgId,
     setMessages,
+    onBranchTurnDone: reloadSession,
   });
 
   const [currentUploadedFiles, setCurrentUploadedFiles] = useState<UploadedFile[]>([]);
@@ -116,6 +121,37 @@ const AIChat: React.FC = () => {
 
   const handleStop = () => { stop(); };
 
+  // 重新生成：移除该回复及其后的消息，新回复作为同级版本
+  const handleRegenerate = useCallback((msgId: string) => {
+    if (streamingLoading) return;
+    const index = messages.findIndex((m) => m.msg_id === msgId);
+    if (index < 0) return;
+    setMessages(messages.slice(0, index));
+    regenerate(currentSessionId, msgId);
+  }, [messages, streamingLoading, currentSessionId, setMessages, regenerate]);
+
+  // 编辑重发：在被编辑消息处开出新分支，原消息保留为同级版本
+  const handleEdit = useCallback((msgId: string, text: string) => {
+    if (streamingLoading) return;
+    const index = messages.findIndex((m) => m.msg_id === msgId);
+    if (index < 0) return;
+    const newMsgId = generateMsgId();
+    setMessages([
+      ...messages.slice(0, index),
+      { id: Date.now(), msg_id: newMsgId, content: text, isUser: true, timestamp: new Date() },
+    ]);
+    edit(text, currentSessionId, msgId, newMsgId);
+  }, [messages, streamingLoading, currentSessionId, setMessages, generateMsgId, edit]);
+
+  const handleSwitchVersion = useCallback(async (msgId: string) => {
+    try {
+      await ChatHistoryService.switchBranch(currentSessionId, msgId);
+      await reloadSession(currentSessionId);
+    } catch {
+      message.error(t('chat.turn.versionSwitchFailed'));
+    }
+  }, [currentSessionId, reloadSession, t]);
+
   const uploadFilesIfNeeded = useCallback(async (sessionId: string) => {
     return fileUploadRef.current?.uploadFiles(sessionId) ?? [];
   }, []);
@@ -264,6 +300,9 @@ const AIChat: React.FC = () => {
                 documentsCount={documentsCount}
                 currentToolStatus={currentToolStatus}
                 hasKnowledgeBase={selectedKnowledge !== 'none' && !!selectedKnowledge}
+                onRegenerate={handleRegenerate}
+                onEdit={handleEdit}
+                onSwitchVersion={handleSwitchVersion}
               />
 
               {/* 输入区域 */}
@@ -4,7 +4,7 @@ import { FileTextOutlined, EditOutlined, DeleteOutlined } from '@ant-design/icon
 import type { ColumnsType } from 'antd/es/table';
 import type { TableRowSelection } from 'antd/es/table/interface';
 import { useTranslation } from 'react-i18next';
-import { type KnowledgeChunk, ChunkStatus } from '@/services/chunks';
+import { type KnowledgeChunk, ChunkStatus, ChunkIndexStatus } from '@/services/chunks';
 
 interface ChunkTableProps {
   loading: boolean;
@@ -71,7 +71,7 @@ const ChunkTable: React.FC<ChunkTableProps> = ({
       title: t('kb.status'),
       dataIndex: 'status',
       key: 'status',
-      width: 100,
+      width: 200,
       render: (status: ChunkStatus, record) => (
         <Space>
           <Tag color={getStatusType(status)}>
@@ -82,6 +82,12 @@ const ChunkTable: React.FC<ChunkTableProps> = ({
             checked={status === ChunkStatus.ACTIVE}
             onChange={() => onToggleStatus(record)}
           />
+          {record.indexStatus === ChunkIndexStatus.INDEXING && (
+            <Tag color="processing">{t('kb.chunkReindexing')}</Tag>
+          )}
+          {record.indexStatus === ChunkIndexStatus.FAILED && (
+            <Tag color="error">{t('kb.chunkReindexFailed')}</Tag>
+          )}
         </Space>
       ),
     },
@@ -3,7 +3,7 @@
  * @description 用于上传和索引文档到知识库的页面
  */
 
-import React, { useState, useEffect } from 'react';
+import React, { useState, useEffect, useRef } from 'react';
 import {
   Card,
   Upload,
@@ -33,6 +33,7 @@ import type { UploadProps, UploadFile } from 'antd';
 import { useTranslation } from 'react-i18next';
 import ApiClient from '@/utils/axios/index';
 import { KnowledgeBaseService, type KnowledgeBase, KBStatus } from '@/services/knowledgeBase';
+import { useWebSocket, type IndexJobProgress } from '@/hooks/useWebSocket';
 import './index.scss';
 
 
@@ -40,8 +41,8 @@ const { Dragger } = Upload;
 const { Option } = Select;
 const { TabPane } = Tabs;
 
-/** /gateway/v1/indexer 为同步长任务（解析、切分、Embedding 等），需长于全局 axios 默认超时 */
-const INDEXER_REQUEST_TIMEOUT_MS = 15 * 60 * 1000;
+/** 索引任务状态，与后端 knowledge_index_jobs.status 一致 */
+const JOB_STATUS = { QUEUED: 0, RUNNING: 1, SUCCEEDED: 2, FAILED: 3 } as const;
 
 /**
  * 处理信息接口
@@ -74,6 +75,8 @@ const Indexer: React.FC = () => {
   const [activeTab, setActiveTab] = useState<string>('file');
   const [urlForm] = Form.useForm();
   const [urlValue, setUrlValue] = useState<string>('');
+  /** 当前跟踪的索引任务（/gateway/v1/indexer 立即返回 job_id，进度经 WebSocket 推送） */
+  const currentJobRef = useRef<{ jobId: string; fileName: string } | null>(null);
   
   // 知识库列表相关状态
   const [knowledgeList, setKnowledgeList] = useState<KnowledgeBase[]>([]);
@@ -104,6 +107,71 @@ const Indexer: React.FC = () => {
     fetchKnowledgeList();
   }, []);
 
+  /**
+   * 索引任务进度推送：只处理当前页面提交的任务
+   */
+  const handleJobProgress = (p: IndexJobProgress) => {
+    const job = currentJobRef.current;
+    if (!job || p.job_id !== job.jobId) {
+      return;
+    }
+    const stage = t(`indexer.stage.${p.stage || 'queued'}`);
+    switch (p.status) {
+      case JOB_STATUS.QUEUED:
+        setProcessingInfo({
+          title: t('indexer.processInfo.queued'),
+          type: p.error ? 'warning' : 'info',
+          description: p.error
+            ? t('indexer.processInfo.retryDesc', { attempts: p.attempts, error: p.error })
+            : t('indexer.processInfo.queuedDesc', { fileName: job.fileName }),
+        });
+        break;
+      case JOB_STATUS.RUNNING:
+        setProcessingInfo({
+          title: t('indexer.processInfo.processing'),
+          type: 'info',
+          description: p.total > 0
+            ? t('indexer.processInfo.stageProgress', { stage, done: p.done, total: p.total })
+            : t('indexer.processInfo.stageDesc', { stage }),
+        });
+        break;
+      case JOB_STATUS.SUCCEEDED:
+        currentJobRef.current = null;
+        setProcessingInfo({
+          title: t('indexer.processInfo.success'),
+          type: 'success',
+          description: t('indexer.processInfo.successDesc'),
+        });
+        setIndexResult({ chunks: p.chunk_count, status: 'success', fileName: job.fileName });
+        message.success(t('indexer.validation.indexSuccess'));
+        break;
+      case JOB_STATUS.FAILED:
+        currentJobRef.current = null;
+        setProcessingInfo({
+          title: t('indexer.processInfo.fail'),
+          type: 'error',
+          description: `${t('common.error')}: ${p.error}`,
+        });
+        setIndexResult({ chunks: 0, status: 'error', fileName: job.fileName });
+        break;
+    }
+  };
+
+  useWebSocket({ onIndexJobProgress: handleJobProgress });
+
+  /**
+   * 任务提交成功：记录 job_id 并展示排队状态，后续由 WebSocket 推送更新
+   */
+  const trackJob = (jobId: string, fileName: string) => {
+    currentJobRef.current = { jobId, fileName };
+    setIndexResult(null);
+    setProcessingInfo({
+      title: t('indexer.processInfo.queued'),
+      type: 'info',
+      description: t('indexer.processInfo.queuedDesc', { fileName }),
+    });
+  };
+
   /**
    * 文件上传前的检查
    */
@@ -118,6 +186,8 @@ const Indexer: React.FC = () => {
       'application/vnd.openxmlformats-officedocument.wordprocessingml.document',
       'application/vnd.ms-excel',
       'application/vnd.openxmlformats-officedocument.spreadsheetml.sheet',
+      'application/vnd.openxmlformats-officedocument.presentationml.presentation',
+      'application/epub+zip',
     ];
     const lower = file.name.toLowerCase();
     const allowedByExt =
@@ -127,7 +197,9 @@ const Indexer: React.FC = () => {
       lower.endsWith('.pdf') ||
       lower.endsWith('.doc') ||
       lower.endsWith('.docx') ||
-      lower.endsWith('.xlsx');
+      lower.endsWith('.xlsx') ||
+      lower.endsWith('.pptx') ||
+      lower.endsWith('.epub');
 
     const isAllowed = allowedTypes.includes(file.type) || allowedByExt;
 
@@ -190,23 +262,9 @@ const Indexer: React.FC = () => {
       formData.append('file', fileList[0].originFileObj as File);
       formData.append('knowledge_name', selectedKnowledge);
 
-      const result = await ApiClient.post('/gateway/v1/indexer', formData, {
-        timeout: INDEXER_REQUEST_TIMEOUT_MS,
-      });
-
-      setProcessingInfo({
-        title: t('indexer.processInfo.success'),
-        type: 'success',
-        description: t('indexer.processInfo.successDesc')
-      });
-
-      setIndexResult({
-        chunks: result.doc_ids?.length || 0,
-        status: 'success',
-        fileName: fileList[0]?.name
-      });
-
-      message.success(t('indexer.validation.indexSuccess'));
+      const result = await ApiClient.post('/gateway/v1/indexer', formData);
+      trackJob(result.job_id, fileList[0]?.name);
+      message.success(t('indexer.validation.jobSubmitted'));
       setFileList([]);
       
     } catch (error) {
@@ -271,23 +329,9 @@ const Indexer: React.FC = () => {
       formData.append('url', urlValue);
       formData.append('knowledge_name', selectedKnowledge);
 
-      const result = await ApiClient.post('/gateway/v1/indexer', formData, {
-        timeout: INDEXER_REQUEST_TIMEOUT_MS,
-      });
-      setProcessingInfo({
-        title: t('indexer.processInfo.urlProcessing'),
-        type: 'success',
-        description: t('indexer.processInfo.urlSuccessDesc')
-      });
-
-      setIndexResult({
-        chunks: result.doc_ids?.length || 0,
-        status: 'success',
-        fileName: urlValue
-      });
-      
-      
-      message.success(t('indexer.validation.urlSuccess'));
+      const result = await ApiClient.post('/gateway/v1/indexer', formData);
+      trackJob(result.job_id, urlValue);
+      message.success(t('indexer.validation.jobSubmitted'));
       setUrlValue('');
       urlForm.resetFields();
       
@@ -20,6 +20,7 @@ import {
   Skeleton,
   Space,
   Select,
+  Switch,
   message,
 } from 'antd';
 import {
@@ -28,7 +29,7 @@ import {
 } from '@ant-design/icons';
 import { useTranslation } from 'react-i18next';
 import { KnowledgeBaseService, type KnowledgeBase, KBStatus } from '../../../services/knowledgeBase';
-import { RetrieverService, type RetrievalDocument } from '../../../services/retriever';
+import { RetrieverService, type RetrievalDocument, type RetrieveSource } from '../../../services/retriever';
 import './index.scss';
 
 const { Panel } = Collapse;
@@ -42,6 +43,7 @@ interface SearchForm {
   top_k: number;
   score: number;
   knowledge_name: string;
+  corrective: boolean;
 }
 
 // 使用从服务中导入的接口类型
@@ -57,6 +59,7 @@ const Retriever: React.FC = () => {
   const [searchResults, setSearchResults] = useState<RetrievalDocument[]>([]);
   const [activeKeys, setActiveKeys] = useState<string[]>(['0']);
   const [searched, setSearched] = useState(false);
+  const [source, setSource] = useState<RetrieveSource | undefined>();
   const [knowledgeOptions, setKnowledgeOptions] = useState<Array<{id: string; name: string}>>([]);
   const [knowledgeLoading, setKnowledgeLoading] = useState(false);
 
@@ -131,11 +134,13 @@ const Retriever: React.FC = () => {
         question: values.question,
         top_k: values.top_k || 5,
         score: values.score || 0.2,
-        knowledge_name: values.knowledge_name
+        knowledge_name: values.knowledge_name,
+        mode: values.corrective ? 'corrective' : 'standard'
       });
 
       const results = response.document || [];
       setSearchResults(results);
+      setSource(response.source);
       setActiveKeys(results.length > 0 ? ['0'] : []);
 
       if (results.length === 0) {
@@ -189,6 +194,7 @@ const Retriever: React.FC = () => {
               question: '',
               top_k: 5,
               score: 0.2,
+              corrective: false,
               knowledge_name: undefined
             }}
           >
@@ -253,6 +259,15 @@ const Retriever: React.FC = () => {
                 </Form.Item>
               </Col>
             </Row>
+
+            <Form.Item
+              label={t('retriever.corrective')}
+              name="corrective"
+              valuePropName="checked"
+              tooltip={t('retriever.correctiveTip')}
+            >
+              <Switch />
+            </Form.Item>
           </Form>
         </div>
 
@@ -268,6 +283,7 @@ const Retriever: React.FC = () => {
               <Space>
                 <FileTextOutlined />
                 <span>{t('retriever.result')}</span>
+                {source && <Tag color={source === 'web' ? 'orange' : 'blue'}>{t(`retriever.source.${source}`)}</Tag>}
               </Space>
             </Divider>
 
@@ -285,10 +301,10 @@ const Retriever: React.FC = () => {
                           {t('retriever.fragment')} #{index + 1}
                         </span>
                         <Tag color="blue">
-                          {t('retriever.similarity')}: {formatScore(result.meta_data._score)}
+                          {t('retriever.similarity')}: {formatScore(result.meta_data?._score ?? 0)}
                         </Tag>
                         <Tag color="green">
-                          {result.meta_data.ext._file_name || t('retriever.unknownSource')}
+                          {result.meta_data?.ext?._file_name || t('retriever.unknownSource')}
                         </Tag>
                       </Space>
                     </div>
@@ -298,7 +314,7 @@ const Retriever: React.FC = () => {
                   <Card className="content-card" size="small">
                     <div className="source-info">
                       <Tag  color="processing">
-                        {result.meta_data.ext._file_name || t('retriever.unknownSource')}
+                        {result.meta_data?.ext?._file_name || t('retriever.unknownSource')}
                       </Tag>
                     </div>
                     <div 
@@ -15,6 +15,9 @@ import {
   Spin,
   Popconfirm,
   Drawer,
+  Collapse,
+  InputNumber,
+  Select,
 } from 'antd';
 import {
   FolderOutlined,
@@ -26,7 +29,7 @@ import {
 import type { ColumnsType } from 'antd/es/table';
 import { useTranslation } from 'react-i18next';
 import { useBreakpoints } from '@/hooks/useMediaQuery';
-import { KnowledgeBaseService, type KnowledgeBase, KBStatus } from '../../services/knowledgeBase';
+import { KnowledgeBaseService, type KnowledgeBase, type RetrievalProfile, KBStatus, parseRetrievalProfile } from '../../services/knowledgeBase';
 import './index.scss';
 import Documents from './Documents';
 
@@ -109,7 +112,7 @@ const KnowledgeBase: React.FC = () => {
     setIsEdit(true);
     resetForm();
     setKbForm({ ...record });
-    form.setFieldsValue(record);
+    form.setFieldsValue({ ...record, retrieval_profile: parseRetrievalProfile(record.retrievalProfile) });
     setDialogVisible(true);
   };
 
@@ -135,6 +138,12 @@ const KnowledgeBase: React.FC = () => {
         values.category = t('kb.noCategory');
       }
 
+      // 去掉未填写的检索配置项，后端按默认值处理
+      const profile: RetrievalProfile = Object.fromEntries(
+        Object.entries(values.retrieval_profile || {}).filter(([, v]) => v !== undefined && v !== null),
+      );
+      values.retrieval_profile = profile;
+
       setSubmitting(true);
 
       if (isEdit) {
@@ -372,6 +381,52 @@ const KnowledgeBase: React.FC = () => {
             <Input placeholder={t('kb.placeholder.category')} />
           </Form.Item>
 
+          <Collapse
+            ghost
+            size="small"
+            items={[{
+              key: 'retrieval',
+              label: t('kb.retrieval.title'),
+              forceRender: true,
+              children: (
+                <>
+                  <Form.Item label={t('kb.retrieval.rewriteRounds')} name={['retrieval_profile', 'rewrite_rounds']} tooltip={t('kb.retrieval.rewriteRoundsTip')}>
+                    <InputNumber min={0} max={5} style={{ width: '100%' }} placeholder={t('kb.retrieval.default', { value: 3 })} />
+                  </Form.Item>
+                  <Form.Item label={t('kb.retrieval.fields')} name={['retrieval_profile', 'fields']}>
+                    <Select allowClear placeholder={t('kb.retrieval.default', { value: t('kb.retrieval.fieldsBoth') })}>
+                      <Select.Option value="both">{t('kb.retrieval.fieldsBoth')}</Select.Option>
+                      <Select.Option value="content">{t('kb.retrieval.fieldsContent')}</Select.Option>
+                      <Select.Option value="qa">{t('kb.retrieval.fieldsQa')}</Select.Option>
+                    </Select>
+                  </Form.Item>
+                  <Form.Item label={t('kb.retrieval.rerank')} name={['retrieval_profile', 'rerank']}>
+                    <Select allowClear placeholder={t('kb.retrieval.default', { value: t('kb.enabled') })}>
+                      <Select.Option value={true}>{t('kb.enabled')}</Select.Option>
+                      <Select.Option value={false}>{t('kb.disabled')}</Select.Option>
+                    </Select>
+                  </Form.Item>
+                  <Form.Item label={t('kb.retrieval.candidateSize')} name={['retrieval_profile', 'candidate_size']}>
+                    <InputNumber min={1} max={500} style={{ width: '100%' }} placeholder={t('kb.retrieval.default', { value: 50 })} />
+                  </Form.Item>
+                  <Form.Item label={t('kb.retrieval.scoreNormalize')} name={['retrieval_profile', 'score_normalize']} tooltip={t('kb.retrieval.scoreNormalizeTip')}>
+                    <Select allowClear placeholder={t('kb.retrieval.default', { value: 'shift' })}>
+                      <Select.Option value="shift">shift</Select.Option>
+                      <Select.Option value="minmax">minmax</Select.Option>
+                      <Select.Option value="none">none</Select.Option>
+                    </Select>
+                  </Form.Item>
+                  <Form.Item label={t('kb.retrieval.hybrid')} name={['retrieval_profile', 'hybrid']} tooltip={t('kb.retrieval.hybridTip')}>
+                    <Select allowClear placeholder={t('kb.retrieval.default', { value: t('kb.disabled') })}>
+                      <Select.Option value={true}>{t('kb.enabled')}</Select.Option>
+                      <Select.Option value={false}>{t('kb.disabled')}</Select.Option>
+                    </Select>
+                  </Form.Item>
+                </>
+              ),
+            }]}
+          />
+
           {isEdit && (
             <Form.Item
               label={t('kb.status')}
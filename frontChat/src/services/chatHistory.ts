@@ -1,4 +1,5 @@
 import { ApiClient } from '../utils/axios';
+import type { Citation } from '@/types/chat';
 
 const BASE_PATH = '/gateway/chat';
 
@@ -9,6 +10,9 @@ export interface Message {
   isUser: boolean;
   timestamp: string;
   reasoningContent?: string;
+  parent_msg_id?: string;
+  siblings?: string[];
+  citations?: Citation[];
 }
 
 export interface ChatSession {
@@ -25,7 +29,8 @@ export interface ChatSessionDetail extends ChatSession {
 export interface SaveSessionReq {
   id: string;
   title?: string;
-  messages: Message[];
+  /** 仅兼容旧版本地会话合并，消息通常由后端在回复结束时写入 */
+  messages?: Message[];
 }
 
 export interface SaveSessionRes {
@@ -39,12 +44,23 @@ export interface GetHistoryRes {
   page_size: number;
 }
 
-export interface GetSessionRes extends ChatSessionDetail {}
+export interface GetSessionRes extends ChatSessionDetail {
+  /** 当前分支末尾消息 ID */
+  active_msg_id?: string;
+}
 
 export interface DeleteSessionRes {
   id: string;
 }
 
+export interface CancelTurnRes {
+  cancelled: number;
+}
+
+export interface SwitchBranchRes {
+  active_msg_id: string;
+}
+
 export interface UploadChatFileRes {
   file_names: string[];
 }
@@ -93,6 +109,20 @@ export const ChatHistoryService = {
   deleteSession: async (id: string): Promise<DeleteSessionRes> => {
     return ApiClient.delete<DeleteSessionRes>(`${BASE_PATH}/session/${id}`);
   },
+
+  /**
+   * 停止进行中的生成，turn_id 为空时停止该会话全部生成
+   */
+  cancelTurn: async (id: string, turn_id = ''): Promise<CancelTurnRes> => {
+    return ApiClient.post<CancelTurnRes>(`${BASE_PATH}/cancel`, { id, turn_id });
+  },
+
+  /**
+   * 切换到某条消息所在的分支（消息版本）
+   */
+  switchBranch: async (id: string, msg_id: string): Promise<SwitchBranchRes> => {
+    return ApiClient.post<SwitchBranchRes>(`${BASE_PATH}/session/branch`, { id, msg_id });
+  },
 };
 
 export default ChatHistoryService;
@@ -6,6 +6,13 @@ export enum ChunkStatus {
   ACTIVE = 1,
 }
 
+// 单个切片的索引状态（与后端 knowledge_chunks.index_status、文档状态取值一致）
+export enum ChunkIndexStatus {
+  INDEXING = 1,
+  ACTIVE = 2,
+  FAILED = 3,
+}
+
 // 知识块数据类型
 export interface KnowledgeChunk {
   id: number;
@@ -14,6 +21,7 @@ export interface KnowledgeChunk {
   content: string;
   ext: string;
   status: ChunkStatus;
+  indexStatus: ChunkIndexStatus;
   createdAt: string;
   updatedAt: string;
 }
@@ -6,6 +6,27 @@ export enum KBStatus {
   DISABLED = 2
 }
 
+/** 检索配置：未设置的字段使用默认值（重写 3 轮、正文 + QA、开启重排、候选 50、shift 归一化、关闭混合检索），可被单次检索/对话覆盖 */
+export interface RetrievalProfile {
+  rewrite_rounds?: number;
+  fields?: 'content' | 'qa' | 'both';
+  rerank?: boolean;
+  candidate_size?: number;
+  score_normalize?: 'none' | 'shift' | 'minmax';
+  /** 混合检索：向量 + 关键词（BM25）按 RRF 融合 */
+  hybrid?: boolean;
+}
+
+/** 解析知识库上保存的检索配置 JSON */
+export const parseRetrievalProfile = (raw?: string): RetrievalProfile | undefined => {
+  if (!raw) return undefined;
+  try {
+    return JSON.parse(raw) as RetrievalProfile;
+  } catch {
+    return undefined;
+  }
+};
+
 // 知识库数据类型
 export interface KnowledgeBase {
   id: number;
@@ -13,6 +34,8 @@ export interface KnowledgeBase {
   description: string;
   category: string;
   status: KBStatus;
+  /** 检索配置 JSON 字符串，见 RetrievalProfile */
+  retrievalProfile?: string;
   createdAt?: string;
   updatedAt?: string;
 }
@@ -22,6 +45,7 @@ export interface KBCreateReq {
   name: string;
   description: string;
   category?: string;
+  retrieval_profile?: RetrievalProfile;
 }
 
 export interface KBCreateRes {
@@ -34,6 +58,7 @@ export interface KBUpdateReq {
   description?: string;
   category?: string;
   status?: KBStatus;
+  retrieval_profile?: RetrievalProfile;
 }
 
 export interface KBGetListReq {
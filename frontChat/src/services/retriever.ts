@@ -15,14 +15,23 @@ export interface RetrieverReq {
   top_k?: number;
   score?: number;
   knowledge_name: string;
+  /** 检索模式：standard（默认）/ corrective（纠错式 RAG） */
+  mode?: RetrieveMode;
 }
 
+export type RetrieveMode = 'standard' | 'corrective';
+
+/** 上下文来源：knowledge 知识库 / rewrite 重写查询后的知识库 / web 网络搜索 / none 无 */
+export type RetrieveSource = 'knowledge' | 'rewrite' | 'web' | 'none';
+
 /**
  * 文档元数据接口
  */
 export interface DocumentMetadata {
-  _score: number;
-  ext: {
+  _score?: number;
+  /** 网络搜索兜底结果为 web */
+  _source?: string;
+  ext?: {
     _file_name: string;
   };
 }
@@ -42,6 +51,7 @@ export interface RetrievalDocument {
  */
 export interface RetrieverRes {
   document: RetrievalDocument[];
+  source?: RetrieveSource;
 }
 
 /**
@@ -58,7 +68,8 @@ export const RetrieverService = {
       question: params.question,
       top_k: params.top_k || 5,
       score: params.score || 0.2,
-      knowledge_name: params.knowledge_name
+      knowledge_name: params.knowledge_name,
+      mode: params.mode || 'standard'
     };
 
     return ApiClient.post('/gateway/v1/retriever', requestData);
@@ -13,6 +13,18 @@ export interface MessagePart {
   mime_type?: string;
 }
 
+/**
+ * 引用来源：回答中的 [index] 标记对应的知识库切片
+ */
+export interface Citation {
+  index: number;
+  chunk_id: string;
+  document_name: string;
+  knowledge_name?: string;
+  /** web 表示网络搜索结果 */
+  source?: string;
+}
+
 /**
  * 消息接口 - 与后端API交互的消息格式
  */
@@ -27,6 +39,12 @@ export interface Message {
   reasoningContent?: string;
   /** 用户消息附带的附件（图片预览或文件），用于在气泡中展示 */
   attachments?: { type: 'image' | 'file'; url: string; name?: string }[];
+  /** 父消息 ID，同一父消息下的消息互为版本 */
+  parent_msg_id?: string;
+  /** 同级版本 msg_id（按创建顺序），仅有多个版本时存在 */
+  siblings?: string[];
+  /** AI 回答中引用标记对应的来源 */
+  citations?: Citation[];
 }
 
 /**
@@ -52,6 +70,7 @@ export interface UseChatSessionsReturn {
   // 操作方法
   createNewSession: () => void;
   loadSession: (sessionId: string) => void;
+  reloadSession: (sessionId: string) => Promise<void>;
   deleteSession: (sessionId: string) => void;
   updateCurrentSession: (newMessages: Message[]) => void;
   setMessages: React.Dispatch<React.SetStateAction<Message[]>>;
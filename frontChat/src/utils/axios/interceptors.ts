@@ -4,6 +4,7 @@ import type { ApiResponse, ApiError } from './types';
 import i18n from '../../i18n';
 import { showTokenExpiredNotification } from './tokenExpiredNotification';
 import { isTokenExpired } from '../token/tokenValidator';
+import { ANONYMOUS_TOKEN_HEADER, getAnonymousToken } from '../token/anonymousToken';
 
 /** 清除所有认证相关存储（token、userInfo、localStorage、sessionStorage） */
 export const clearAuthStorage = () => {
@@ -34,6 +35,10 @@ export const requestInterceptor = {
     if (token && config.headers) {
       config.headers.Authorization = `Bearer ${token}`;
     }
+    // 匿名令牌：未登录时标识会话归属，登录时用于转移匿名会话
+    if (config.headers) {
+      config.headers[ANONYMOUS_TOKEN_HEADER] = getAnonymousToken();
+    }
 
     // 处理 FormData 请求，删除 Content-Type 让浏览器自动设置
     if (config.data instanceof FormData && config.headers) {
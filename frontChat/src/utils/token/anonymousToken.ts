@@ -0,0 +1,26 @@
+/**
+ * 匿名令牌：未登录用户的会话归属凭证，首次使用时生成并持久化
+ * 后端只保存其哈希，登录时据此将匿名会话转移到账号下
+ */
+
+const STORAGE_KEY = 'anonymous_token';
+export const ANONYMOUS_TOKEN_HEADER = 'X-Anonymous-Token';
+
+export const getAnonymousToken = (): string => {
+  let token = localStorage.getItem(STORAGE_KEY);
+  if (!token) {
+    token = crypto.randomUUID();
+    localStorage.setItem(STORAGE_KEY, token);
+  }
+  return token;
+};
+
+/** 聊天请求的身份头：已登录带 Authorization，始终带匿名令牌 */
+export const chatAuthHeaders = (): Record<string, string> => {
+  const headers: Record<string, string> = { [ANONYMOUS_TOKEN_HEADER]: getAnonymousToken() };
+  const token = localStorage.getItem('access_token');
+  if (token) {
+    headers['Authorization'] = `Bearer ${token}`;
+  }
+  return headers;
+};